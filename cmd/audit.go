@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	logger "github.com/PolarWolf314/kanuka/internal/logging"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	auditVerbose bool
+	auditDebug   bool
+	AuditLogger  logger.Logger
+
+	// AuditCmd is the top-level audit command.
+	AuditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "Work with the project's audit log",
+		Long: `Provides commands for working with the project's audit log.
+
+Kānuka records its own operations (encrypt, register, revoke, ...) in
+.kanuka/audit.jsonl. These commands let external tooling add to that log,
+so it becomes a shared timeline of everything that touches your secrets,
+and let reviewers search it.
+
+To view the whole log, use 'kanuka secrets log'.
+
+Examples:
+  # Record a deployment in the audit log
+  kanuka audit record deploy-prod --detail env=production --detail sha=abc123
+
+  # Find everything alice did in the last week
+  kanuka audit query --user alice@example.com --since 7d
+
+  # Export the log as CSV for a SIEM
+  kanuka audit export --format csv -o audit.csv
+
+  # Counts per operation, user, and file for the last month
+  kanuka audit summary --since 30d`,
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			AuditLogger = logger.Logger{
+				Verbose: auditVerbose,
+				Debug:   auditDebug,
+			}
+			AuditLogger.Debugf("Initializing audit command with verbose=%t, debug=%t", auditVerbose, auditDebug)
+			migrateUserConfig(AuditLogger)
+		},
+	}
+)
+
+func init() {
+	AuditCmd.PersistentFlags().BoolVarP(&auditVerbose, "verbose", "v", false, "enable verbose output")
+	AuditCmd.PersistentFlags().BoolVarP(&auditDebug, "debug", "d", false, "enable debug output")
+}
+
+// GetAuditCmd returns the AuditCmd for testing.
+func GetAuditCmd() *cobra.Command {
+	return AuditCmd
+}
+
+// ResetAuditState resets all audit command global variables to their default values for testing.
+func ResetAuditState() {
+	auditVerbose = false
+	auditDebug = false
+	resetAuditRecordState()
+	resetAuditQueryState()
+	resetAuditExportState()
+	resetAuditSummaryState()
+	resetAuditCobraFlagState()
+}
+
+// resetAuditCobraFlagState resets the flag state for all audit commands to prevent test pollution.
+func resetAuditCobraFlagState() {
+	for _, c := range append([]*cobra.Command{AuditCmd}, AuditCmd.Commands()...) {
+		c.Flags().VisitAll(func(flag *pflag.Flag) {
+			flag.Changed = false
+		})
+		// Undo the silencing set by reportCommandError.
+		c.SilenceErrors = false
+		c.SilenceUsage = false
+	}
+}
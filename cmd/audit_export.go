@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditExportFormat     string
+	auditExportOutputPath string
+)
+
+func init() {
+	auditExportCmd.Flags().StringVar(&auditExportFormat, "format", "csv", "export format: csv or json")
+	auditExportCmd.Flags().StringVarP(&auditExportOutputPath, "output", "o", "", "write the export to this file instead of stdout")
+	AuditCmd.AddCommand(auditExportCmd)
+}
+
+func resetAuditExportState() {
+	auditExportFormat = "csv"
+	auditExportOutputPath = ""
+}
+
+var auditExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the audit log as CSV or JSON",
+	Long: `Writes every entry in the project's audit log, oldest first, in a format
+other tools such as a SIEM can ingest.
+
+--format csv (the default) writes a header row and one row per entry with
+the columns timestamp, user, uuid, operation, device, target_user, and
+files. An entry's files are joined with ';' into the files column. Fields
+containing commas or quotes are quoted as usual for CSV.
+
+--format json writes a single JSON array of entries, rather than the one
+object per line of .kanuka/audit.jsonl.
+
+The export goes to stdout unless -o/--output names a file.
+
+Examples:
+  # Export the log as CSV for a SIEM
+  kanuka audit export --format csv -o audit.csv
+
+  # Pipe the log as a JSON array into jq
+  kanuka audit export --format json | jq '.[] | select(.op == "revoke")'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts := workflows.ExportAuditOptions{
+			Format:     auditExportFormat,
+			OutputPath: auditExportOutputPath,
+		}
+
+		// Must run before the spinner starts, so that it and the deferred final
+		// message also go to stderr.
+		if auditExportOutputPath == "" || auditExportOutputPath == exportToStdout {
+			stdout, restore := reserveStdout()
+			defer restore()
+			opts.Output = stdout
+		}
+
+		AuditLogger.Infof("Starting audit export command")
+		spinner, cleanup := startSpinnerWithFlags("Exporting audit log...", auditVerbose, auditDebug)
+		defer cleanup()
+
+		result, err := workflows.ExportAudit(context.Background(), opts)
+		if err != nil {
+			AuditLogger.Errorf("Audit export workflow failed: %v", err)
+			return reportCommandError(cmd, spinner, err, formatAuditExportError(err))
+		}
+
+		AuditLogger.Infof("Exported %d entries as %s", result.EntryCount, result.Format)
+		if result.OutputPath == "" {
+			spinner.FinalMSG = ui.Success.Sprint("✓") + fmt.Sprintf(" Exported %d audit entries as %s", result.EntryCount, result.Format)
+		} else {
+			spinner.FinalMSG = ui.Success.Sprint("✓") + fmt.Sprintf(" Exported %d audit entries as %s to ", result.EntryCount, result.Format) +
+				ui.Path.Sprint(result.OutputPath)
+		}
+		return nil
+	},
+}
+
+// formatAuditExportError formats workflow errors into user-friendly messages.
+func formatAuditExportError(err error) string {
+	switch {
+	case errors.Is(err, kerrors.ErrProjectNotInitialized):
+		return ui.Error.Sprint("✗") + " Kānuka has not been initialized\n" +
+			ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets init") + " first"
+
+	case errors.Is(err, kerrors.ErrInvalidArguments):
+		return ui.Error.Sprint("✗") + " " + err.Error() + "\n" +
+			ui.Info.Sprint("→") + " Use " + ui.Flag.Sprint("--format csv") + " or " + ui.Flag.Sprint("--format json")
+
+	default:
+		return ui.Error.Sprint("✗") + " Failed to export audit log\n" +
+			ui.Error.Sprint("Error: ") + err.Error()
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditQueryUser      string
+	auditQueryOperation string
+	auditQuerySince     string
+	auditQueryUntil     string
+)
+
+func init() {
+	auditQueryCmd.Flags().StringVar(&auditQueryUser, "user", "", "filter by user email")
+	auditQueryCmd.Flags().StringVar(&auditQueryOperation, "operation", "", "filter by operation type (comma-separated)")
+	auditQueryCmd.Flags().StringVar(&auditQuerySince, "since", "", "show entries at or after this time (RFC3339, YYYY-MM-DD, or a duration like 7d)")
+	auditQueryCmd.Flags().StringVar(&auditQueryUntil, "until", "", "show entries at or before this time (RFC3339, YYYY-MM-DD, or a duration like 24h)")
+	AuditCmd.AddCommand(auditQueryCmd)
+}
+
+func resetAuditQueryState() {
+	auditQueryUser = ""
+	auditQueryOperation = ""
+	auditQuerySince = ""
+	auditQueryUntil = ""
+}
+
+var auditQueryCmd = &cobra.Command{
+	Use:   "query",
+	Short: "Search the audit log",
+	Long: `Searches the project's audit log and prints the matching entries as a table.
+
+Filters can be combined; an entry must match all of them:
+  --user       the user's email (case-insensitive)
+  --operation  one or more operations, comma-separated (encrypt,decrypt,revoke,...)
+  --since      entries at or after a time
+  --until      entries at or before a time
+
+Times can be RFC3339 timestamps (2024-01-15T09:00:00Z), dates (2024-01-15),
+or durations before now (24h, 7d, 2w). A date given to --until includes the
+whole day.
+
+Examples:
+  # Everything alice did in the last week
+  kanuka audit query --user alice@example.com --since 7d
+
+  # All access changes in January
+  kanuka audit query --operation register,revoke --since 2024-01-01 --until 2024-01-31
+
+  # Decryptions in the last 24 hours
+  kanuka audit query --operation decrypt --since 24h`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		AuditLogger.Infof("Starting audit query command")
+		spinner, cleanup := startSpinnerWithFlags("Searching audit log...", auditVerbose, auditDebug)
+		defer cleanup()
+
+		result, err := workflows.QueryAudit(context.Background(), workflows.QueryAuditOptions{
+			User:       auditQueryUser,
+			Operations: auditQueryOperation,
+			Since:      auditQuerySince,
+			Until:      auditQueryUntil,
+		})
+		if err != nil {
+			AuditLogger.Errorf("Audit query workflow failed: %v", err)
+			return reportCommandError(cmd, spinner, err, formatAuditQueryError(err))
+		}
+
+		AuditLogger.Debugf("Matched %d of %d entries", len(result.Entries), result.TotalEntries)
+
+		if len(result.Entries) == 0 {
+			if result.TotalEntries == 0 {
+				spinner.FinalMSG = ui.Info.Sprint("ℹ") + " No audit log entries found."
+			} else {
+				spinner.FinalMSG = ui.Info.Sprint("ℹ") + " No matching entries."
+			}
+			return nil
+		}
+
+		spinner.FinalMSG = ""
+		spinner.Stop()
+		printAuditQueryTable(result.Entries)
+		fmt.Println()
+		fmt.Printf("%d of %d entries matched\n", len(result.Entries), result.TotalEntries)
+		return nil
+	},
+}
+
+// formatAuditQueryError formats workflow errors into user-friendly messages.
+func formatAuditQueryError(err error) string {
+	switch {
+	case errors.Is(err, kerrors.ErrProjectNotInitialized):
+		return ui.Error.Sprint("✗") + " Kānuka has not been initialized\n" +
+			ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets init") + " first"
+
+	case errors.Is(err, kerrors.ErrInvalidDateFormat):
+		return ui.Error.Sprint("✗") + " " + err.Error()
+
+	default:
+		return ui.Error.Sprint("✗") + " Failed to read audit log\n" +
+			ui.Error.Sprint("Error: ") + err.Error()
+	}
+}
+
+// printAuditQueryTable prints entries in aligned TIME, USER, OPERATION, DETAILS columns.
+func printAuditQueryTable(entries []audit.Entry) {
+	userWidth, opWidth := len("USER"), len("OPERATION")
+	for _, e := range entries {
+		userWidth = max(userWidth, len(e.User))
+		opWidth = max(opWidth, len(e.Operation))
+	}
+
+	fmt.Printf("%-19s  %-*s  %-*s  %s\n", "TIME", userWidth, "USER", opWidth, "OPERATION", "DETAILS")
+	for _, e := range entries {
+		details := workflows.FormatDetails(e)
+		if e.Note != "" {
+			details = strings.TrimSpace(details + " " + ui.Muted.Sprint(e.Note))
+		}
+		// Pad before coloring so ANSI codes don't break the alignment.
+		operation := ui.Info.Sprint(fmt.Sprintf("%-*s", opWidth, e.Operation))
+		fmt.Printf("%-19s  %-*s  %s  %s\n", workflows.FormatDateTime(e.Timestamp), userWidth, e.User, operation, details)
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+
+	"github.com/spf13/cobra"
+)
+
+var auditRecordDetails []string
+
+func init() {
+	auditRecordCmd.Flags().StringArrayVar(&auditRecordDetails, "detail", nil, "attach a key=value detail to the entry (repeatable)")
+	AuditCmd.AddCommand(auditRecordCmd)
+}
+
+func resetAuditRecordState() {
+	auditRecordDetails = nil
+}
+
+var auditRecordCmd = &cobra.Command{
+	Use:   "record <operation>",
+	Short: "Record a custom event in the audit log",
+	Long: `Appends a custom entry to the project's audit log.
+
+The entry is attributed to the current user and timestamped like Kānuka's own
+entries, so scripts and CI jobs can mark their own milestones (deployments,
+approvals, key ceremonies) in the same timeline.
+
+Operation names must be lowercase letters, digits, '.', '_', ':', or '-', and
+can't be one of Kānuka's built-in operations (encrypt, register, ...).
+
+Attach extra context with --detail key=value, repeated as needed. Details are
+shown by 'kanuka secrets log' and included in its JSON output.
+
+Examples:
+  # Record a deployment
+  kanuka audit record deploy-prod --detail env=production --detail sha=$GITHUB_SHA
+
+  # Record a manual approval
+  kanuka audit record approval --detail ticket=SEC-123`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		AuditLogger.Infof("Starting audit record command")
+		spinner, cleanup := startSpinnerWithFlags("Recording audit entry...", auditVerbose, auditDebug)
+		defer cleanup()
+
+		details, err := parseAuditDetails(auditRecordDetails)
+		if err != nil {
+			return reportCommandError(cmd, spinner, err, formatAuditRecordError(err))
+		}
+
+		result, err := workflows.RecordAudit(context.Background(), workflows.RecordAuditOptions{
+			Operation: args[0],
+			Details:   details,
+		})
+		if err != nil {
+			AuditLogger.Errorf("Audit record workflow failed: %v", err)
+			return reportCommandError(cmd, spinner, err, formatAuditRecordError(err))
+		}
+
+		msg := ui.Success.Sprint("✓") + " Recorded " + ui.Highlight.Sprint(result.Entry.Operation) + " in the audit log"
+		if result.Entry.User != "" {
+			msg += " as " + ui.Highlight.Sprint(result.Entry.User)
+		}
+		spinner.FinalMSG = msg
+		return nil
+	},
+}
+
+// parseAuditDetails parses key=value pairs into a map.
+// Later values for the same key replace earlier ones.
+func parseAuditDetails(pairs []string) (map[string]string, error) {
+	details := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w: %q must be in key=value form", kerrors.ErrInvalidAuditDetail, pair)
+		}
+		details[key] = value
+	}
+	return details, nil
+}
+
+// formatAuditRecordError formats workflow errors into user-friendly messages.
+func formatAuditRecordError(err error) string {
+	switch {
+	case errors.Is(err, kerrors.ErrProjectNotInitialized):
+		return ui.Error.Sprint("✗") + " Kānuka has not been initialized\n" +
+			ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets init") + " first"
+
+	case errors.Is(err, kerrors.ErrInvalidAuditOperation), errors.Is(err, kerrors.ErrInvalidAuditDetail):
+		return ui.Error.Sprint("✗") + " " + err.Error()
+
+	default:
+		return ui.Error.Sprint("✗") + " Failed to record audit entry\n" +
+			ui.Error.Sprint("Error: ") + err.Error()
+	}
+}
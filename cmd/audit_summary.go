@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+
+	"github.com/spf13/cobra"
+)
+
+// auditSummaryTopFiles is how many files the text summary lists.
+const auditSummaryTopFiles = 10
+
+var (
+	auditSummarySince string
+	auditSummaryUntil string
+	auditSummaryJSON  bool
+)
+
+func init() {
+	auditSummaryCmd.Flags().StringVar(&auditSummarySince, "since", "", "summarize entries at or after this time (RFC3339, YYYY-MM-DD, or a duration like 30d)")
+	auditSummaryCmd.Flags().StringVar(&auditSummaryUntil, "until", "", "summarize entries at or before this time (RFC3339, YYYY-MM-DD, or a duration like 24h)")
+	auditSummaryCmd.Flags().BoolVar(&auditSummaryJSON, "json", false, "output in JSON format")
+	AuditCmd.AddCommand(auditSummaryCmd)
+}
+
+func resetAuditSummaryState() {
+	auditSummarySince = ""
+	auditSummaryUntil = ""
+	auditSummaryJSON = false
+}
+
+var auditSummaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Summarize the audit log",
+	Long: `Shows an overview of the project's audit log: how many times each
+operation ran, how active each user was, which files were encrypted or
+decrypted most, and the time span the entries cover.
+
+--since and --until limit the summary to a time range, in the same formats
+as 'kanuka audit query'. --json prints the full counts as JSON, including
+every file rather than the top ` + fmt.Sprint(auditSummaryTopFiles) + `.
+
+Examples:
+  # Monthly report
+  kanuka audit summary --since 30d
+
+  # Summary for January as JSON
+  kanuka audit summary --since 2024-01-01 --until 2024-01-31 --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		AuditLogger.Infof("Starting audit summary command")
+		spinner, cleanup := startReportSpinnerWithFlags("Summarizing audit log...", auditVerbose, auditDebug)
+		defer cleanup()
+
+		stats, err := workflows.SummarizeAudit(context.Background(), workflows.SummarizeAuditOptions{
+			Since: auditSummarySince,
+			Until: auditSummaryUntil,
+		})
+		if err != nil {
+			AuditLogger.Errorf("Audit summary workflow failed: %v", err)
+			return reportCommandError(cmd, spinner, err, formatAuditQueryError(err))
+		}
+
+		AuditLogger.Debugf("Summarized %d entries", stats.TotalEntries)
+
+		spinner.FinalMSG = ""
+		spinner.Stop()
+
+		if auditSummaryJSON {
+			return printJSONResult(stats)
+		}
+		if stats.TotalEntries == 0 {
+			fmt.Println(ui.Info.Sprint("ℹ") + " No audit log entries found.")
+			return nil
+		}
+		printAuditSummary(stats)
+		return nil
+	},
+}
+
+// printAuditSummary prints stats as a text report.
+func printAuditSummary(stats *audit.Stats) {
+	fmt.Printf("Audit summary: %d entries\n", stats.TotalEntries)
+	if !stats.First.IsZero() {
+		fmt.Printf("Period: %s to %s (%s)\n",
+			stats.First.Format("2006-01-02 15:04:05"), stats.Last.Format("2006-01-02 15:04:05"), formatAuditSpan(stats.Span()))
+	}
+
+	printAuditCounts("Operations", stats.Operations, len(stats.Operations))
+	if len(stats.Users) > 0 {
+		printAuditCounts("Users", stats.Users, len(stats.Users))
+	}
+	if len(stats.Files) > 0 {
+		printAuditCounts("Most accessed files", stats.Files, auditSummaryTopFiles)
+	}
+}
+
+// printAuditCounts prints a heading and up to limit counts in aligned columns.
+func printAuditCounts(heading string, counts []audit.Count, limit int) {
+	shown := counts[:min(limit, len(counts))]
+	nameWidth := 0
+	for _, c := range shown {
+		nameWidth = max(nameWidth, len(c.Name))
+	}
+
+	fmt.Println()
+	fmt.Println(heading + ":")
+	for _, c := range shown {
+		fmt.Printf("  %-*s  %d\n", nameWidth, c.Name, c.Count)
+	}
+	if rest := len(counts) - len(shown); rest > 0 {
+		fmt.Println(ui.Muted.Sprintf("  ... and %d more", rest))
+	}
+}
+
+// formatAuditSpan formats the time between the first and last entries in
+// whole days, or whole hours when it is under a day.
+func formatAuditSpan(span time.Duration) string {
+	if days := int(span.Hours() / 24); days >= 1 {
+		return pluralize(days, "day")
+	}
+	if hours := int(span.Hours()); hours >= 1 {
+		return pluralize(hours, "hour")
+	}
+	return "under an hour"
+}
+
+func pluralize(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"github.com/PolarWolf314/kanuka/internal/ui"
+
+	"github.com/spf13/cobra"
+)
+
+// colorFlag is the value of the global --color flag. Setting it applies the
+// mode to the ui package straight away, so it takes effect before any
+// command's hooks run.
+type colorFlag struct {
+	mode ui.ColorMode
+}
+
+// String returns the current mode.
+func (f *colorFlag) String() string {
+	if f.mode == "" {
+		return string(ui.ColorAuto)
+	}
+	return string(f.mode)
+}
+
+// Set parses and applies a mode.
+func (f *colorFlag) Set(value string) error {
+	mode, err := ui.ParseColorMode(value)
+	if err != nil {
+		return err
+	}
+	f.mode = mode
+	ui.SetColorMode(mode)
+	return nil
+}
+
+// Type returns the flag's type name for help output.
+func (f *colorFlag) Type() string {
+	return "string"
+}
+
+var colorMode colorFlag
+
+// AddColorFlag adds the global --color flag to root.
+func AddColorFlag(root *cobra.Command) {
+	root.PersistentFlags().Var(&colorMode, "color", "when to use color: auto, always, or never")
+}
+
+// resetColorState resets --color to auto for testing.
+func resetColorState() {
+	colorMode = colorFlag{}
+	ui.SetColorMode(ui.ColorAuto)
+}
@@ -23,6 +23,8 @@ Use these commands to:
   - Set your default device name for new projects
   - Set your device name for an existing project
   - List all devices in the project
+  - Read and update project settings (config get, config set)
+  - Check the project and user config for problems (config validate)
 
 Examples:
   # Initialize your user configuration
@@ -35,13 +37,17 @@ Examples:
   kanuka config set-default-device my-laptop
 
   # Set your device name for the current project
-  kanuka config set-project-device my-laptop`,
+  kanuka config set-project-device my-laptop
+
+  # Rename the project
+  kanuka config set project.name my-service`,
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
 			ConfigLogger = logger.Logger{
 				Verbose: configVerbose,
 				Debug:   configDebug,
 			}
 			ConfigLogger.Debugf("Initializing config command with verbose=%t, debug=%t", configVerbose, configDebug)
+			migrateUserConfig(ConfigLogger)
 
 			// Update key metadata access time if in a project.
 			updateConfigProjectAccessTime()
@@ -67,6 +73,7 @@ func ResetConfigState() {
 	resetConfigShowState()
 	resetSetProjectDeviceState()
 	resetListDevicesState()
+	resetConfigValidateState()
 	resetConfigCobraFlagState()
 }
 
@@ -77,6 +84,14 @@ func resetConfigCobraFlagState() {
 			flag.Changed = false
 		})
 	}
+
+	// Undo the silencing set by reportCommandError.
+	if ConfigCmd != nil {
+		for _, c := range ConfigCmd.Commands() {
+			c.SilenceErrors = false
+			c.SilenceUsage = false
+		}
+	}
 }
 
 // updateConfigProjectAccessTime updates the key metadata access time if running inside a project.
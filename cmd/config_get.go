@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	ConfigCmd.AddCommand(configGetCmd)
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a project configuration value",
+	Long: `Prints the value of a key in the project's .kanuka/config.toml.
+
+Keys are dotted paths into the config. Valid keys:
+  project.name               the project's display name
+  project.uuid               the project's UUID
+  devices.<uuid>.email       a device's user email
+  devices.<uuid>.name        a device's name
+  devices.<uuid>.created_at  when a device was registered
+
+The value is printed on its own, so it can be used in scripts.
+
+Examples:
+  # Print the project name
+  kanuka config get project.name
+
+  # Use the project UUID in a script
+  PROJECT_UUID=$(kanuka config get project.uuid)`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key := args[0]
+		ConfigLogger.Infof("Starting config get command")
+		ConfigLogger.Debugf("Key: %s", key)
+
+		if message := initProjectForConfigKey(); message != "" {
+			return reportPrintedError(cmd, kerrors.ErrProjectNotInitialized, message)
+		}
+
+		value, err := configs.GetProjectConfigValue(key)
+		if err != nil {
+			ConfigLogger.Infof("Failed to get %s: %v", key, err)
+			return reportPrintedError(cmd, err, formatConfigKeyError(key, err))
+		}
+
+		ConfigLogger.Infof("Config get completed")
+		fmt.Println(value)
+		return nil
+	},
+}
+
+// initProjectForConfigKey initializes project settings for config get and
+// set. It returns a message to show if the current directory is not a project.
+func initProjectForConfigKey() string {
+	if err := configs.InitProjectSettings(); err != nil || configs.ProjectKanukaSettings.ProjectPath == "" {
+		return ui.Error.Sprint("✗") + " Not in a Kānuka project directory\n" +
+			ui.Info.Sprint("→") + " Run this command from within a Kānuka project"
+	}
+	return ""
+}
+
+// formatConfigKeyError formats errors from getting or setting a config key.
+func formatConfigKeyError(key string, err error) string {
+	switch {
+	case errors.Is(err, kerrors.ErrUnknownConfigKey):
+		return ui.Error.Sprint("✗") + " Unknown config key: " + ui.Highlight.Sprint(key) + "\n" +
+			ui.Info.Sprint("→") + " Valid keys: " + strings.Join(configs.ProjectConfigKeys, ", ")
+
+	case errors.Is(err, kerrors.ErrReadOnlyConfigKey):
+		return ui.Error.Sprint("✗") + " " + ui.Highlight.Sprint(key) + " can't be set\n" +
+			ui.Info.Sprint("→") + " " + strings.TrimPrefix(err.Error(), kerrors.ErrReadOnlyConfigKey.Error()+": ")
+
+	case errors.Is(err, kerrors.ErrInvalidConfigValue):
+		return ui.Error.Sprint("✗") + " Invalid value for " + ui.Highlight.Sprint(key) + "\n" +
+			ui.Info.Sprint("→") + " " + strings.TrimPrefix(err.Error(), kerrors.ErrInvalidConfigValue.Error()+": ")
+
+	case errors.Is(err, kerrors.ErrDeviceNotFound):
+		return ui.Error.Sprint("✗") + " No device found for " + ui.Highlight.Sprint(key) + "\n" +
+			ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka config list-devices") + " to see device UUIDs"
+
+	case strings.Contains(err.Error(), "toml:"):
+		return ui.Error.Sprint("✗") + " Failed to load project configuration.\n\n" +
+			ui.Info.Sprint("→") + " The .kanuka/config.toml file is not valid TOML.\n\n" +
+			"   To fix this issue:\n" +
+			"   1. Restore the file from git: " + ui.Code.Sprint("git checkout .kanuka/config.toml") + "\n" +
+			"   2. Or contact your project administrator for assistance"
+
+	default:
+		return ui.Error.Sprint("✗") + " Failed to access " + ui.Highlight.Sprint(key) + "\n" +
+			ui.Error.Sprint("Error: ") + err.Error()
+	}
+}
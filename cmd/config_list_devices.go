@@ -3,8 +3,10 @@ package cmd
 import (
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -41,23 +43,23 @@ Examples:
 		ConfigLogger.Infof("Starting list-devices command")
 		ConfigLogger.Debugf("Flags: user=%s", listDevicesUserEmail)
 
-		spinner, cleanup := startSpinnerWithFlags("Loading devices...", configVerbose, configDebug)
+		spinner, cleanup := startReportSpinnerWithFlags("Loading devices...", configVerbose, configDebug)
 		defer cleanup()
 
 		// Initialize project settings.
 		ConfigLogger.Debugf("Initializing project settings")
 		if err := configs.InitProjectSettings(); err != nil {
 			ConfigLogger.Infof("Failed to initialize project settings: %v", err)
-			spinner.FinalMSG = ui.Error.Sprint("✗") + " Failed to initialize project settings\n"
 			fmt.Println(ui.Info.Sprint("→") + " Make sure you're in a Kānuka project directory")
-			return nil
+			finalMessage := ui.Error.Sprint("✗") + " Failed to initialize project settings\n"
+			return reportCommandError(cmd, spinner, err, finalMessage)
 		}
 
 		if configs.ProjectKanukaSettings.ProjectPath == "" {
 			ConfigLogger.Infof("Not in a Kanuka project directory")
-			spinner.FinalMSG = ui.Error.Sprint("✗") + " Not in a Kānuka project directory\n"
 			fmt.Println(ui.Info.Sprint("→") + " Run this command from within a Kānuka project")
-			return nil
+			finalMessage := ui.Error.Sprint("✗") + " Not in a Kānuka project directory\n"
+			return reportCommandError(cmd, spinner, kerrors.ErrProjectNotInitialized, finalMessage)
 		}
 
 		ConfigLogger.Debugf("Project path: %s", configs.ProjectKanukaSettings.ProjectPath)
@@ -77,6 +79,7 @@ Examples:
 		}
 
 		// Group devices by email.
+		now := time.Now()
 		devicesByEmail := make(map[string][]deviceInfo)
 		for uuid, device := range projectConfig.Devices {
 			info := deviceInfo{
@@ -84,6 +87,10 @@ Examples:
 				Name:      device.Name,
 				CreatedAt: device.CreatedAt.Format("Jan 2, 2006"),
 			}
+			if !device.ExpiresAt.IsZero() {
+				info.ExpiresAt = device.ExpiresAt.Format("Jan 2, 2006")
+				info.Expired = device.IsExpired(now)
+			}
 			devicesByEmail[device.Email] = append(devicesByEmail[device.Email], info)
 		}
 
@@ -94,8 +101,8 @@ Examples:
 			ConfigLogger.Infof("Filtering devices by user: %s", listDevicesUserEmail)
 			devices, exists := devicesByEmail[listDevicesUserEmail]
 			if !exists {
-				spinner.FinalMSG = ui.Error.Sprint("✗") + " User " + ui.Highlight.Sprint(listDevicesUserEmail) + " not found in this project\n"
-				return nil
+				finalMessage := ui.Error.Sprint("✗") + " User " + ui.Highlight.Sprint(listDevicesUserEmail) + " not found in this project\n"
+				return reportCommandError(cmd, spinner, fmt.Errorf("%w: %s", kerrors.ErrUserNotFound, listDevicesUserEmail), finalMessage)
 			}
 			ConfigLogger.Debugf("Found %d devices for user %s", len(devices), listDevicesUserEmail)
 			devicesByEmail = map[string][]deviceInfo{listDevicesUserEmail: devices}
@@ -131,10 +138,18 @@ Examples:
 				if len(shortUUID) > 8 {
 					shortUUID = shortUUID[:8] + "..."
 				}
-				fmt.Printf("    - %s (UUID: %s) - created: %s\n",
+				expiry := ""
+				switch {
+				case device.Expired:
+					expiry = " - " + ui.Warning.Sprint("expired: "+device.ExpiresAt)
+				case device.ExpiresAt != "":
+					expiry = " - expires: " + device.ExpiresAt
+				}
+				fmt.Printf("    - %s (UUID: %s) - created: %s%s\n",
 					ui.Highlight.Sprint(device.Name),
 					ui.Muted.Sprint(shortUUID),
-					device.CreatedAt)
+					device.CreatedAt,
+					expiry)
 			}
 			fmt.Println()
 		}
@@ -148,4 +163,6 @@ type deviceInfo struct {
 	UUID      string
 	Name      string
 	CreatedAt string
+	ExpiresAt string
+	Expired   bool
 }
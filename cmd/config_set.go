@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	ConfigCmd.AddCommand(configSetCmd)
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a project configuration value",
+	Long: `Sets the value of a key in the project's .kanuka/config.toml.
+
+Only project.name can be set. The project UUID identifies the project and
+never changes, and device entries are managed by register, revoke, and
+set-project-device. Run 'kanuka config get --help' for all keys.
+
+Examples:
+  # Rename the project
+  kanuka config set project.name my-service`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, value := args[0], args[1]
+		ConfigLogger.Infof("Starting config set command")
+		ConfigLogger.Debugf("Key: %s, value: %s", key, value)
+
+		spinner, cleanup := startSpinnerWithFlags("Updating project configuration...", configVerbose, configDebug)
+		defer cleanup()
+
+		if message := initProjectForConfigKey(); message != "" {
+			return reportCommandError(cmd, spinner, kerrors.ErrProjectNotInitialized, message)
+		}
+
+		if err := configs.SetProjectConfigValue(key, value); err != nil {
+			ConfigLogger.Infof("Failed to set %s: %v", key, err)
+			return reportCommandError(cmd, spinner, err, formatConfigKeyError(key, err))
+		}
+
+		ConfigLogger.Infof("Config set completed")
+		spinner.FinalMSG = ui.Success.Sprint("✓") + " Set " + ui.Highlight.Sprint(key) + " to " + ui.Highlight.Sprint(value)
+		return nil
+	},
+}
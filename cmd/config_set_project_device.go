@@ -152,6 +152,12 @@ Examples:
 
 		if configs.ProjectKanukaSettings.ProjectPath != "" {
 			ConfigLogger.Debugf("Updating project config")
+			lock, err := configs.LockProject()
+			if err != nil {
+				return ConfigLogger.ErrorfAndReturn("Failed to lock project: %v", err)
+			}
+			defer lock.Unlock()
+
 			projectConfig, err := configs.LoadProjectConfig()
 			if err != nil {
 				if strings.Contains(err.Error(), "toml:") {
@@ -3,9 +3,12 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/internal/secrets"
 	"github.com/PolarWolf314/kanuka/internal/ui"
 	"github.com/spf13/cobra"
@@ -13,11 +16,13 @@ import (
 
 var (
 	configShowProject bool
+	configShowMerged  bool
 	configShowJSON    bool
 )
 
 func init() {
 	configShowCmd.Flags().BoolVarP(&configShowProject, "project", "p", false, "show project configuration instead of user configuration")
+	configShowCmd.Flags().BoolVar(&configShowMerged, "merged", false, "show user configuration and, inside a project, project configuration together")
 	configShowCmd.Flags().BoolVar(&configShowJSON, "json", false, "output in JSON format")
 	ConfigCmd.AddCommand(configShowCmd)
 }
@@ -25,6 +30,7 @@ func init() {
 // resetConfigShowState resets the config show command's global state for testing.
 func resetConfigShowState() {
 	configShowProject = false
+	configShowMerged = false
 	configShowJSON = false
 }
 
@@ -35,6 +41,10 @@ var configShowCmd = &cobra.Command{
 
 By default, shows user configuration from ~/.config/kanuka/config.toml.
 Use --project to show project configuration from .kanuka/config.toml.
+Use --merged to show your effective configuration in one view: your identity
+and registered projects, followed by the current project's users and devices
+when run inside a project. Outside a project, only the user configuration is
+shown.
 
 Examples:
   # Show user configuration
@@ -43,16 +53,27 @@ Examples:
   # Show project configuration (must be in a project directory)
   kanuka config show --project
 
+  # Show user and project configuration together
+  kanuka config show --merged
+
   # Output in JSON format
   kanuka config show --json
-  kanuka config show --project --json`,
+  kanuka config show --project --json
+  kanuka config show --merged --json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ConfigLogger.Infof("Starting config show command")
-		ConfigLogger.Debugf("Flags: project=%t, json=%t", configShowProject, configShowJSON)
+		ConfigLogger.Debugf("Flags: project=%t, merged=%t, json=%t", configShowProject, configShowMerged, configShowJSON)
 
+		if configShowProject && configShowMerged {
+			return fmt.Errorf("--project and --merged cannot be used together")
+		}
+		if configShowMerged {
+			ConfigLogger.Infof("Showing merged configuration")
+			return showMergedConfig()
+		}
 		if configShowProject {
 			ConfigLogger.Infof("Showing project configuration")
-			return showProjectConfig()
+			return showProjectConfig(cmd)
 		}
 		ConfigLogger.Infof("Showing user configuration")
 		return showUserConfig()
@@ -61,7 +82,7 @@ Examples:
 
 // showUserConfig displays the user configuration.
 func showUserConfig() error {
-	spinner, cleanup := startSpinnerWithFlags("Loading user configuration...", configVerbose, configDebug)
+	spinner, cleanup := startReportSpinnerWithFlags("Loading user configuration...", configVerbose, configDebug)
 	defer cleanup()
 
 	// Ensure user settings are initialized.
@@ -125,6 +146,15 @@ func outputUserConfigJSON(config *configs.UserConfig) error {
 // outputUserConfigText outputs user config in human-readable format.
 func outputUserConfigText(config *configs.UserConfig) error {
 	fmt.Println(ui.Info.Sprint("User Configuration") + " (~/.config/kanuka/config.toml):")
+	printUserConfigDetails(config)
+
+	// Note: spinner final message is set in showUserConfig
+	return nil
+}
+
+// printUserConfigDetails prints the identity and registered projects in a
+// user config.
+func printUserConfigDetails(config *configs.UserConfig) {
 	fmt.Println()
 	fmt.Printf("  %-14s %s\n", "Email:", ui.Highlight.Sprint(config.User.Email))
 	if config.User.Name != "" {
@@ -160,14 +190,11 @@ func outputUserConfigText(config *configs.UserConfig) error {
 			}
 		}
 	}
-
-	// Note: spinner final message is set in showUserConfig
-	return nil
 }
 
 // showProjectConfig displays the project configuration.
-func showProjectConfig() error {
-	spinner, cleanup := startSpinnerWithFlags("Loading project configuration...", configVerbose, configDebug)
+func showProjectConfig(cmd *cobra.Command) error {
+	spinner, cleanup := startReportSpinnerWithFlags("Loading project configuration...", configVerbose, configDebug)
 	defer cleanup()
 
 	// Check if we're in a project directory.
@@ -181,13 +208,12 @@ func showProjectConfig() error {
 	if !exists {
 		ConfigLogger.Infof("Not in a Kanuka project directory")
 		if configShowJSON {
-			fmt.Println("{\"error\": \"not in a project directory\"}")
-			return nil
+			return reportJSONError(cmd, spinner, kerrors.ErrProjectNotInitialized)
 		}
-		spinner.FinalMSG = ui.Error.Sprint("✗") + " Not in a Kanuka project directory\n"
 		fmt.Println()
 		fmt.Println(ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets init") + " to initialize a project")
-		return nil
+		finalMessage := ui.Error.Sprint("✗") + " Not in a Kanuka project directory\n"
+		return reportCommandError(cmd, spinner, kerrors.ErrProjectNotInitialized, finalMessage)
 	}
 
 	// Initialize project settings.
@@ -238,9 +264,22 @@ func outputProjectConfigJSON(config *configs.ProjectConfig) error {
 // outputProjectConfigText outputs project config in human-readable format.
 func outputProjectConfigText(config *configs.ProjectConfig) error {
 	fmt.Println(ui.Info.Sprint("Project Configuration") + " (.kanuka/config.toml):")
+	printProjectConfigDetails(config, "")
+	return nil
+}
+
+// printProjectConfigDetails prints the project details and the users and
+// devices in a project config. deviceName, if set, is this machine's device.
+func printProjectConfigDetails(config *configs.ProjectConfig, deviceName string) {
 	fmt.Println()
 	fmt.Printf("  %-14s %s\n", "Project ID:", ui.Highlight.Sprint(config.Project.UUID))
 	fmt.Printf("  %-14s %s\n", "Project Name:", ui.Highlight.Sprint(config.Project.Name))
+	if len(config.Project.Owners) > 0 {
+		fmt.Printf("  %-14s %s\n", "Owners:", ui.Highlight.Sprint(strings.Join(config.Project.Owners, ", ")))
+	}
+	if deviceName != "" {
+		fmt.Printf("  %-14s %s\n", "This Device:", ui.Highlight.Sprint(deviceName))
+	}
 
 	if len(config.Devices) > 0 {
 		fmt.Println()
@@ -297,6 +336,95 @@ func outputProjectConfigText(config *configs.ProjectConfig) error {
 			}
 		}
 	}
+}
+
+// mergedConfigJSON is the JSON form of config show --merged. The project
+// fields are left out outside a project, and User is left out if there is no
+// user configuration yet.
+type mergedConfigJSON struct {
+	UserConfigPath    string                 `json:"user_config_path"`
+	User              *configs.UserConfig    `json:"user,omitempty"`
+	ProjectConfigPath string                 `json:"project_config_path,omitempty"`
+	Project           *configs.ProjectConfig `json:"project,omitempty"`
+	// DeviceName is this machine's device name in the project, from the
+	// user config.
+	DeviceName string `json:"device_name,omitempty"`
+}
+
+// showMergedConfig displays the user configuration and, inside a project,
+// the project configuration.
+func showMergedConfig() error {
+	spinner, cleanup := startReportSpinnerWithFlags("Loading configuration...", configVerbose, configDebug)
+	defer cleanup()
+
+	if err := secrets.EnsureUserSettings(); err != nil {
+		spinner.FinalMSG = ui.Error.Sprint("✗") + " Failed to initialize user settings\n"
+		return ConfigLogger.ErrorfAndReturn("Failed to initialize user settings: %v", err)
+	}
+
+	result := mergedConfigJSON{
+		UserConfigPath: filepath.Join(configs.UserKanukaSettings.UserConfigsPath, "config.toml"),
+	}
+
+	ConfigLogger.Debugf("Loading user config from %s", result.UserConfigPath)
+	userConfig, err := configs.LoadUserConfig()
+	if err != nil {
+		spinner.FinalMSG = ui.Error.Sprint("✗") + " Failed to load user configuration\n"
+		return ConfigLogger.ErrorfAndReturn("Failed to load user config: %v", err)
+	}
+	if userConfig.User.Email != "" || userConfig.User.UUID != "" {
+		result.User = userConfig
+	}
+
+	exists, err := secrets.DoesProjectKanukaSettingsExist()
+	if err != nil {
+		spinner.FinalMSG = ui.Error.Sprint("✗") + " Failed to check project settings\n"
+		return ConfigLogger.ErrorfAndReturn("Failed to check project settings: %v", err)
+	}
+	if exists {
+		if err := configs.InitProjectSettings(); err != nil {
+			spinner.FinalMSG = ui.Error.Sprint("✗") + " Failed to initialize project settings\n"
+			return ConfigLogger.ErrorfAndReturn("Failed to initialize project settings: %v", err)
+		}
+		result.ProjectConfigPath = filepath.Join(configs.ProjectKanukaSettings.ProjectPath, ".kanuka", "config.toml")
+
+		ConfigLogger.Debugf("Loading project config from %s", result.ProjectConfigPath)
+		projectConfig, err := configs.LoadProjectConfig()
+		if err != nil {
+			spinner.FinalMSG = ui.Error.Sprint("✗") + " Failed to load project configuration\n"
+			return ConfigLogger.ErrorfAndReturn("Failed to load project config: %v", err)
+		}
+		result.Project = projectConfig
+		result.DeviceName = userConfig.Projects[projectConfig.Project.UUID].DeviceName
+	} else {
+		ConfigLogger.Infof("Not in a Kanuka project directory, showing user configuration only")
+	}
+
+	if configShowJSON {
+		output, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			spinner.FinalMSG = ui.Error.Sprint("✗") + " Failed to output configuration\n"
+			return ConfigLogger.ErrorfAndReturn("Failed to marshal config to JSON: %v", err)
+		}
+		fmt.Println(string(output))
+		spinner.FinalMSG = ui.Success.Sprint("✓") + " Configuration displayed\n"
+		return nil
+	}
+
+	fmt.Println(ui.Info.Sprint("User Configuration") + " (" + ui.Path.Sprint(result.UserConfigPath) + "):")
+	if result.User != nil {
+		printUserConfigDetails(result.User)
+	} else {
+		fmt.Println()
+		fmt.Println("  No user configuration found. Run " + ui.Code.Sprint("kanuka config init") + " to set up your identity")
+	}
+
+	if result.Project != nil {
+		fmt.Println()
+		fmt.Println(ui.Info.Sprint("Project Configuration") + " (" + ui.Path.Sprint(result.ProjectConfigPath) + "):")
+		printProjectConfigDetails(result.Project, result.DeviceName)
+	}
 
+	spinner.FinalMSG = ui.Success.Sprint("✓") + " Configuration displayed\n"
 	return nil
 }
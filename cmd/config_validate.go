@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configValidateJSON bool
+
+	// configValidateExitFunc is the function called to exit with a specific code.
+	// It can be overridden in tests to capture exit codes.
+	configValidateExitFunc = os.Exit
+)
+
+func init() {
+	configValidateCmd.Flags().BoolVar(&configValidateJSON, "json", false, "output in JSON format")
+	ConfigCmd.AddCommand(configValidateCmd)
+}
+
+// resetConfigValidateState resets the validate command's global state for testing.
+func resetConfigValidateState() {
+	configValidateJSON = false
+	configValidateExitFunc = os.Exit
+}
+
+// SetConfigValidateExitFunc sets the exit function for testing purposes.
+func SetConfigValidateExitFunc(f func(int)) {
+	configValidateExitFunc = f
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the project and user config for problems",
+	Long: `Loads the project and user configuration and checks that they agree with
+each other and with the key files in .kanuka.
+
+The validate command reports:
+  - Devices with no matching [users] entry, and users with no device
+  - UUIDs whose email differs between [users] and [devices]
+  - Public keys and encrypted keys that belong to no known UUID
+  - A malformed email in your user config
+
+The config is checked as written, so users in a config from before devices
+existed are reported even though other commands give them a default device.
+
+This is useful after hand-editing config.toml or resolving a merge conflict.
+Exits non-zero if any problem is found, so CI can gate on it.
+
+Examples:
+  # Check the current project's config
+  kanuka config validate
+
+  # Get the report as JSON
+  kanuka config validate --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ConfigLogger.Infof("Starting config validate command")
+
+		if message := initProjectForConfigKey(); message != "" {
+			fmt.Println(message)
+			configValidateExitFunc(kerrors.ExitNotInitialized)
+			return nil
+		}
+
+		report, err := configs.Validate()
+		if err != nil {
+			ConfigLogger.Infof("Failed to validate config: %v", err)
+			fmt.Println(formatConfigValidateError(err))
+			configValidateExitFunc(kerrors.ExitCode(err))
+			return nil
+		}
+
+		ConfigLogger.Infof("Config validate completed: %d issues", len(report.Issues))
+
+		if configValidateJSON {
+			output, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return ConfigLogger.ErrorfAndReturn("Failed to marshal report: %v", err)
+			}
+			fmt.Println(string(output))
+		} else {
+			printConfigValidateReport(report)
+		}
+
+		if !report.OK() {
+			configValidateExitFunc(kerrors.ExitGeneral)
+		}
+		return nil
+	},
+}
+
+// printConfigValidateReport prints each issue followed by a summary line.
+func printConfigValidateReport(report *configs.ValidationReport) {
+	fmt.Printf("Checked %d users, %d devices, %d public keys, and %d encrypted keys\n",
+		report.Users, report.Devices, report.PublicKeys, report.SecretKeys)
+	fmt.Println()
+
+	if report.OK() {
+		fmt.Println(ui.Success.Sprint("✓") + " Config is valid")
+		return
+	}
+
+	for _, issue := range report.Issues {
+		fmt.Println(ui.Error.Sprint("✗") + " " + issue.Message)
+		fmt.Println("    " + ui.Highlight.Sprint(issue.Subject))
+	}
+
+	fmt.Println()
+	fmt.Println(ui.Error.Sprint("✗") + fmt.Sprintf(" Found %d problem(s) in the config", len(report.Issues)))
+	fmt.Println(ui.Info.Sprint("→") + " Fix the entries above by hand, or run " + ui.Code.Sprint("kanuka secrets revoke") +
+		" to remove a user cleanly")
+}
+
+// formatConfigValidateError formats errors that stopped the config from being checked.
+func formatConfigValidateError(err error) string {
+	switch {
+	case errors.Is(err, kerrors.ErrProjectNotInitialized):
+		return ui.Error.Sprint("✗") + " Kānuka has not been initialized\n" +
+			ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets init") + " first"
+
+	case errors.Is(err, kerrors.ErrInvalidProjectConfig):
+		return ui.Error.Sprint("✗") + " Failed to load project configuration.\n\n" +
+			ui.Info.Sprint("→") + " The .kanuka/config.toml file is not valid TOML.\n" +
+			"   " + ui.Code.Sprint(err.Error()) + "\n\n" +
+			"   To fix this issue:\n" +
+			"   1. Restore the file from git: " + ui.Code.Sprint("git checkout .kanuka/config.toml") + "\n" +
+			"   2. Or contact your project administrator for assistance"
+
+	default:
+		return ui.Error.Sprint("✗") + " Failed to validate config\n" +
+			ui.Error.Sprint("Error: ") + err.Error()
+	}
+}
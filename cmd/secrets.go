@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
@@ -12,30 +13,70 @@ import (
 )
 
 var (
-	verbose bool
-	debug   bool
-	Logger  logger.Logger
+	verbose      bool
+	debug        bool
+	outputFormat = outputFormatText
+	strictPerms  bool
+	stopAtRepo   bool
+	maxDepth     int
+	Logger       logger.Logger
 
 	SecretsCmd = &cobra.Command{
 		Use:   "secrets",
 		Short: "Manage secrets stored in the repository",
 		Long:  `	Provides encryption, decryption, registration, revocation, and initialization of secrets.`,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			Logger = logger.Logger{
-				Verbose: verbose,
-				Debug:   debug,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// The project's settings.toml is found using the search flags
+			// from the command line, and may then change them.
+			if err := applyProjectRootSearchFlags(); err != nil {
+				return err
+			}
+			settingsWarnings, err := applyProjectFlagDefaults(cmd)
+			if err != nil {
+				return err
+			}
+			if err := applyProjectRootSearchFlags(); err != nil {
+				return err
+			}
+
+			Logger = logger.New(verbose, debug)
+			Logger.Debugf("Initializing secrets command with verbose=%t, debug=%t, output=%s", verbose, debug, outputFormat)
+			for _, warning := range settingsWarnings {
+				Logger.WarnfUser("%s", warning)
+			}
+			migrateUserConfig(Logger)
+
+			configs.UserKanukaSettings.StrictKeyPermissions = strictPerms
+
+			if err := validateOutputFormat(); err != nil {
+				return err
+			}
+			if jsonOutput() {
+				// Errors are written as JSON instead of cobra's plain text and usage.
+				cmd.SilenceErrors = true
+				cmd.SilenceUsage = true
 			}
-			Logger.Debugf("Initializing secrets command with verbose=%t, debug=%t", verbose, debug)
 
 			// Update key metadata access time if in a project.
 			updateProjectAccessTime()
+			return nil
 		},
 	}
 )
 
 func init() {
+	// Print collected user warnings once the command has finished, whether
+	// or not it succeeded.
+	cobra.OnFinalize(func() {
+		Logger.FlushWarnings()
+	})
+
 	SecretsCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
 	SecretsCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "enable debug output")
+	SecretsCmd.PersistentFlags().BoolVar(&strictPerms, "strict-perms", false, "refuse to use a private key that is readable by its group or others")
+	SecretsCmd.PersistentFlags().BoolVar(&stopAtRepo, "stop-at-repo", false, "don't look for .kanuka above the nearest directory containing .git or package.json")
+	SecretsCmd.PersistentFlags().IntVar(&maxDepth, "max-depth", 0, "how many parent directories to search for .kanuka (0 for no limit)")
+	SecretsCmd.PersistentFlags().StringVar(&outputFormat, "output", outputFormatText, "output format: text or json (json is supported by init, encrypt, decrypt, register, and revoke)")
 
 	SecretsCmd.AddCommand(encryptCmd)
 	SecretsCmd.AddCommand(decryptCmd)
@@ -44,13 +85,37 @@ func init() {
 	SecretsCmd.AddCommand(revokeCmd)
 	SecretsCmd.AddCommand(initCmd)
 	SecretsCmd.AddCommand(syncCmd)
+	SecretsCmd.AddCommand(rekeyCmd)
+	SecretsCmd.AddCommand(rekeyAllCmd)
+	SecretsCmd.AddCommand(transferOwnershipCmd)
 	SecretsCmd.AddCommand(accessCmd)
+	SecretsCmd.AddCommand(listCmd)
 	SecretsCmd.AddCommand(cleanCmd)
+	SecretsCmd.AddCommand(pruneCmd)
 	SecretsCmd.AddCommand(statusCmd)
 	SecretsCmd.AddCommand(doctorCmd)
 	SecretsCmd.AddCommand(rotateCmd)
 	SecretsCmd.AddCommand(exportCmd)
 	SecretsCmd.AddCommand(importCmd)
+	SecretsCmd.AddCommand(filesCmd)
+	SecretsCmd.AddCommand(mergeConfigCmd)
+	SecretsCmd.AddCommand(passphraseCmd)
+	SecretsCmd.AddCommand(diffCmd)
+	SecretsCmd.AddCommand(verifyCmd)
+	SecretsCmd.AddCommand(whoamiCmd)
+}
+
+// applyProjectRootSearchFlags sets how far up the project root search looks
+// from --stop-at-repo and --max-depth.
+func applyProjectRootSearchFlags() error {
+	if maxDepth < 0 {
+		return fmt.Errorf("invalid --max-depth %d: must be zero or positive", maxDepth)
+	}
+	utils.ProjectRootSearch = utils.ProjectRootOptions{MaxDepth: maxDepth}
+	if stopAtRepo {
+		utils.ProjectRootSearch.StopMarkers = utils.DefaultRootStopMarkers
+	}
+	return nil
 }
 
 // Helper functions for testing
@@ -64,6 +129,14 @@ func GetSecretsCmd() *cobra.Command {
 func ResetGlobalState() {
 	verbose = false
 	debug = false
+	outputFormat = outputFormatText
+	jsonErrorReported = false
+	strictPerms = false
+	stopAtRepo = false
+	maxDepth = 0
+	utils.ProjectRootSearch = utils.ProjectRootOptions{}
+	resetColorState()
+	resetUserDirState()
 	// Reset the force flag from secrets_create.go
 	resetCreateCommandState()
 	// Reset the register command flags
@@ -78,10 +151,20 @@ func ResetGlobalState() {
 	resetDecryptCommandState()
 	// Reset the sync command flags
 	resetSyncCommandState()
+	// Reset the rekey command flags
+	resetRekeyCommandState()
+	// Reset the rekey-all command flags
+	resetRekeyAllCommandState()
+	// Reset the transfer-ownership command flags
+	resetTransferOwnershipCommandState()
 	// Reset the access command flags
 	resetAccessCommandState()
+	// Reset the list command flags
+	resetListCommandState()
 	// Reset the clean command flags
 	resetCleanCommandState()
+	// Reset the prune command flags
+	resetPruneCommandState()
 	// Reset the status command flags
 	resetStatusCommandState()
 	// Reset the doctor command flags
@@ -96,6 +179,18 @@ func ResetGlobalState() {
 	resetLogCommandState()
 	// Reset the ci-init command flags
 	resetCIInitCommandState()
+	// Reset the files command flags
+	resetFilesCommandState()
+	// Reset the merge-config command flags
+	resetMergeConfigCommandState()
+	// Reset the passphrase command flags
+	resetPassphraseCommandState()
+	// Reset the diff command flags
+	resetDiffCommandState()
+	// Reset the verify command flags
+	resetVerifyCommandState()
+	// Reset the whoami command flags
+	resetWhoamiCommandState()
 	// Reset Cobra flag state to prevent pollution between tests
 	resetCobraFlagState()
 }
@@ -144,6 +239,55 @@ func resetCobraFlagState() {
 		})
 	}
 
+	// Reset the rekey command flags specifically
+	if rekeyCmd != nil && rekeyCmd.Flags() != nil {
+		rekeyCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			flag.Changed = false
+		})
+	}
+
+	// Reset the rekey-all command flags specifically
+	if rekeyAllCmd != nil && rekeyAllCmd.Flags() != nil {
+		rekeyAllCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			flag.Changed = false
+		})
+	}
+
+	// Reset the transfer-ownership command flags specifically
+	if transferOwnershipCmd != nil && transferOwnershipCmd.Flags() != nil {
+		transferOwnershipCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			flag.Changed = false
+		})
+	}
+
+	// Reset the passphrase command flags specifically
+	if passphraseCmd != nil && passphraseCmd.Flags() != nil {
+		passphraseCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			flag.Changed = false
+		})
+	}
+
+	// Reset the diff command flags specifically
+	if diffCmd != nil && diffCmd.Flags() != nil {
+		diffCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			flag.Changed = false
+		})
+	}
+
+	// Reset the verify command flags specifically
+	if verifyCmd != nil && verifyCmd.Flags() != nil {
+		verifyCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			flag.Changed = false
+		})
+	}
+
+	// Reset the whoami command flags specifically
+	if whoamiCmd != nil && whoamiCmd.Flags() != nil {
+		whoamiCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			flag.Changed = false
+		})
+	}
+
 	// Reset the access command flags specifically
 	if accessCmd != nil && accessCmd.Flags() != nil {
 		accessCmd.Flags().VisitAll(func(flag *pflag.Flag) {
@@ -151,6 +295,13 @@ func resetCobraFlagState() {
 		})
 	}
 
+	// Reset the list command flags specifically
+	if listCmd != nil && listCmd.Flags() != nil {
+		listCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			flag.Changed = false
+		})
+	}
+
 	// Reset the clean command flags specifically
 	if cleanCmd != nil && cleanCmd.Flags() != nil {
 		cleanCmd.Flags().VisitAll(func(flag *pflag.Flag) {
@@ -158,6 +309,13 @@ func resetCobraFlagState() {
 		})
 	}
 
+	// Reset the prune command flags specifically
+	if pruneCmd != nil && pruneCmd.Flags() != nil {
+		pruneCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			flag.Changed = false
+		})
+	}
+
 	// Reset the status command flags specifically
 	if statusCmd != nil && statusCmd.Flags() != nil {
 		statusCmd.Flags().VisitAll(func(flag *pflag.Flag) {
@@ -200,12 +358,34 @@ func resetCobraFlagState() {
 		})
 	}
 
+	// Reset the files command flags specifically
+	if filesCmd != nil && filesCmd.Flags() != nil {
+		filesCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			flag.Changed = false
+		})
+	}
+
+	// Reset the merge-config command flags specifically
+	if mergeConfigCmd != nil && mergeConfigCmd.Flags() != nil {
+		mergeConfigCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+			flag.Changed = false
+		})
+	}
+
 	// Reset the main secrets command flags
 	if SecretsCmd != nil && SecretsCmd.Flags() != nil {
 		SecretsCmd.Flags().VisitAll(func(flag *pflag.Flag) {
 			flag.Changed = false
 		})
 	}
+
+	// Undo the silencing set by reportCommandError and --output json
+	if SecretsCmd != nil {
+		for _, c := range SecretsCmd.Commands() {
+			c.SilenceErrors = false
+			c.SilenceUsage = false
+		}
+	}
 }
 
 // SetVerbose sets the verbose flag for testing.
@@ -224,10 +404,10 @@ func SetLogger(l logger.Logger) {
 }
 
 // updateProjectAccessTime updates the key metadata access time if running inside a project.
-// This is called from PersistentPreRun to track when the project was last accessed.
+// This is called from PersistentPreRunE to track when the project was last accessed.
 // Errors are silently ignored as this is a non-critical operation.
 // Important: This function avoids calling InitProjectSettings to prevent triggering
-// legacy project migration during PersistentPreRun.
+// legacy project migration during PersistentPreRunE.
 func updateProjectAccessTime() {
 	// Find project root without initializing settings (which could trigger migration).
 	projectPath, err := utils.FindProjectKanukaRoot()
@@ -59,20 +59,15 @@ Use --json for machine-readable output.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		Logger.Infof("Starting access command")
 
-		spinner, cleanup := startSpinner("Discovering users with access...", verbose)
+		spinner, cleanup := startReportSpinner("Discovering users with access...", verbose)
 		defer cleanup()
 
 		result, err := workflows.Access(context.Background(), workflows.AccessOptions{})
 		if err != nil {
 			if accessJSONOutput {
-				fmt.Printf(`{"error": "%s"}`+"\n", formatAccessErrorJSON(err))
-				return nil
+				return reportJSONError(cmd, spinner, err)
 			}
-			spinner.FinalMSG = formatAccessError(err)
-			if isAccessUnexpectedError(err) {
-				return err
-			}
-			return nil
+			return reportCommandError(cmd, spinner, err, formatAccessError(err))
 		}
 
 		// Output results.
@@ -110,35 +105,6 @@ func formatAccessError(err error) string {
 	}
 }
 
-// formatAccessErrorJSON formats errors for JSON output.
-func formatAccessErrorJSON(err error) string {
-	switch {
-	case errors.Is(err, kerrors.ErrProjectNotInitialized):
-		return "Kanuka has not been initialized"
-
-	case errors.Is(err, kerrors.ErrInvalidProjectConfig):
-		return "Failed to load project configuration: config.toml is not valid TOML"
-
-	default:
-		return err.Error()
-	}
-}
-
-// isAccessUnexpectedError returns true if the error is unexpected and should cause a non-zero exit.
-func isAccessUnexpectedError(err error) bool {
-	expectedErrors := []error{
-		kerrors.ErrProjectNotInitialized,
-		kerrors.ErrInvalidProjectConfig,
-	}
-
-	for _, expected := range expectedErrors {
-		if errors.Is(err, expected) {
-			return false
-		}
-	}
-	return true
-}
-
 // outputAccessJSON outputs the result as JSON.
 func outputAccessJSON(result *workflows.AccessResult) error {
 	// Convert to JSON-serializable format.
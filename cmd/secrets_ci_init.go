@@ -58,16 +58,7 @@ func runCIInit(cmd *cobra.Command, args []string) error {
 
 	result, err := workflows.CIInit(ctx, opts)
 	if err != nil {
-		spinner.FinalMSG = formatCIInitError(err)
-
-		// Return nil for expected errors (they've been displayed via defer cleanup).
-		if errors.Is(err, kerrors.ErrProjectNotInitialized) ||
-			errors.Is(err, kerrors.ErrCIAlreadyConfigured) ||
-			errors.Is(err, kerrors.ErrTTYRequired) ||
-			errors.Is(err, kerrors.ErrNoAccess) {
-			return nil
-		}
-		return err
+		return reportCommandError(cmd, spinner, err, formatCIInitError(err))
 	}
 
 	// Stop spinner before TTY output. Clear FinalMSG since we handle output manually.
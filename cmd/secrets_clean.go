@@ -58,11 +58,7 @@ Use --force to skip the confirmation prompt.`,
 
 		previewResult, err := workflows.Clean(context.Background(), previewOpts)
 		if err != nil {
-			spinner.FinalMSG = formatCleanError(err)
-			if isCleanUnexpectedError(err) {
-				return err
-			}
-			return nil
+			return reportCommandError(cmd, spinner, err, formatCleanError(err))
 		}
 
 		if len(previewResult.Orphans) == 0 {
@@ -110,8 +106,7 @@ Use --force to skip the confirmation prompt.`,
 
 		result, err := workflows.Clean(context.Background(), cleanOpts)
 		if err != nil {
-			spinner.FinalMSG = formatCleanError(err)
-			return err
+			return reportCommandError(cmd, spinner, err, formatCleanError(err))
 		}
 
 		spinner.FinalMSG = ui.Success.Sprint("✓") + fmt.Sprintf(" Removed %d orphaned file(s)", result.RemovedCount)
@@ -132,20 +127,6 @@ func formatCleanError(err error) string {
 	}
 }
 
-// isCleanUnexpectedError returns true if the error is unexpected and should cause a non-zero exit.
-func isCleanUnexpectedError(err error) bool {
-	expectedErrors := []error{
-		kerrors.ErrProjectNotInitialized,
-	}
-
-	for _, expected := range expectedErrors {
-		if errors.Is(err, expected) {
-			return false
-		}
-	}
-	return true
-}
-
 // printOrphanTable prints a formatted table of orphaned entries.
 func printOrphanTable(orphans []workflows.OrphanEntry) {
 	// Calculate column widths.
@@ -8,30 +8,46 @@ import (
 	"os"
 	"strings"
 
+	"github.com/PolarWolf314/kanuka/internal/configs"
 	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
 	"github.com/PolarWolf314/kanuka/internal/ui"
 	"github.com/PolarWolf314/kanuka/internal/workflows"
 
+	"github.com/briandowns/spinner"
 	"github.com/spf13/cobra"
 )
 
 var (
 	force         bool
+	createYes     bool
 	createEmail   string
 	createDevName string
+	createKeyType string
+
+	createNoRegister bool
+	createOutPath    string
 )
 
 func init() {
-	createCmd.Flags().BoolVarP(&force, "force", "f", false, "force key creation")
+	createCmd.Flags().BoolVarP(&force, "force", "f", false, "delete your existing key for this project and create a new one")
+	createCmd.Flags().BoolVarP(&createYes, "yes", "y", false, "skip the confirmation prompt for --force (for automation)")
 	createCmd.Flags().StringVarP(&createEmail, "email", "e", "", "your email address for identification")
 	createCmd.Flags().StringVar(&createDevName, "device-name", "", "custom device name (auto-generated from hostname if not specified)")
+	createCmd.Flags().StringVar(&createKeyType, "key-type", "", "key pair type: rsa2048 (default), rsa4096, or ed25519")
+	createCmd.Flags().BoolVar(&createNoRegister, "no-register", false, "generate a key pair outside any project and print its public key")
+	createCmd.Flags().StringVarP(&createOutPath, "out", "o", "", "with --no-register, write the public key to this file instead of stdout")
 }
 
 // resetCreateCommandState resets the create command's global state for testing.
 func resetCreateCommandState() {
 	force = false
+	createYes = false
 	createEmail = ""
 	createDevName = ""
+	createKeyType = ""
+	createNoRegister = false
+	createOutPath = ""
 }
 
 // promptForEmail prompts the user for their email address.
@@ -44,23 +60,64 @@ func promptForEmail(reader *bufio.Reader) (string, error) {
 	return strings.TrimSpace(email), nil
 }
 
+// confirmCreateForce warns that --force deletes the user's existing key for
+// this project and, unless skipPrompt is set, asks the user to confirm.
+// Returns true if creation should proceed.
+func confirmCreateForce(s *spinner.Spinner, keyPaths []string, skipPrompt bool) bool {
+	s.Stop()
+	defer s.Restart()
+
+	fmt.Printf("\n%s Warning: --force will destroy your current key for this project:\n", ui.Warning.Sprint("⚠"))
+	for _, path := range keyPaths {
+		fmt.Println("  - " + ui.Path.Sprint(path))
+	}
+	fmt.Println("  You will lose access to this project's secrets until someone registers your new key.")
+	fmt.Println()
+
+	if skipPrompt {
+		return true
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Print("Do you want to continue? [y/N]: ")
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		Logger.Errorf("Failed to read response: %v", err)
+		return false
+	}
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes"
+}
+
 var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Creates and adds your public key, and gives instructions on how to gain access",
-	Long: `Creates a new RSA key pair for accessing the project's encrypted secrets.
+	Long: `Creates a new key pair for accessing the project's encrypted secrets.
 
 This command generates a unique cryptographic identity for you on this device,
 identified by your email address. Each device you use gets its own key pair.
 
 The command will:
-  1. Generate an RSA key pair (stored locally in ~/.local/share/kanuka/keys/)
+  1. Generate a key pair (stored locally in ~/.local/share/kanuka/keys/)
   2. Copy your public key to the project's .kanuka/public_keys/ directory
   3. Register your device in the project configuration
 
+Use --key-type to choose the key pair: rsa2048 (the default) is quick to
+generate, rsa4096 is stronger but can take a few seconds on slow machines, and
+ed25519 is small and fast.
+
 After running this command, you need to:
   1. Commit the new .kanuka/public_keys/<uuid>.pub file
   2. Ask someone with access to run: kanuka secrets register --user <your-email>
 
+With --no-register, no project is needed: the key pair is saved under
+~/.local/share/kanuka/keys/pending/ and the public key is printed to stdout,
+or written to the file given with -o/--out. Hand it to an admin, who registers
+it with 'kanuka secrets register --file <your-uuid>.pub --user <your-email>'.
+Once you have the project, run 'kanuka secrets register' in it with no flags
+to make the pending key your key for the project. --force replaces an
+existing pending key.
+
 Examples:
   # Create keys with email prompt
   kanuka secrets create
@@ -71,21 +128,40 @@ Examples:
   # Create keys with custom device name
   kanuka secrets create --email alice@example.com --device-name macbook-pro
 
-  # Force recreate keys (overwrites existing)
-  kanuka secrets create --force`,
+  # Create an Ed25519 key pair
+  kanuka secrets create --email alice@example.com --key-type ed25519
+
+  # Generate a key pair before you can reach the project, for an admin to register
+  kanuka secrets create --no-register -o my-key.pub
+
+  # Delete your existing key and create a new one (prompts for confirmation)
+  kanuka secrets create --force
+
+  # Same, without the confirmation prompt
+  kanuka secrets create --force --yes`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if createNoRegister {
+			return runCreateNoRegister(cmd)
+		}
+
 		Logger.Infof("Starting create command")
 		spinner, cleanup := startSpinner("Creating Kānuka file...", verbose)
 		defer cleanup()
 
+		if createOutPath != "" {
+			finalMessage := ui.Error.Sprint("✗") + " " + ui.Flag.Sprint("--out") + " can only be used with " + ui.Flag.Sprint("--no-register")
+			return reportCommandError(cmd, spinner, fmt.Errorf("%w: --out can only be used with --no-register", kerrors.ErrInvalidArguments), finalMessage)
+		}
+
+		if _, err := secrets.ParseKeyAlgorithm(createKeyType); err != nil {
+			err = fmt.Errorf("%w: %v", kerrors.ErrInvalidArguments, err)
+			return reportCommandError(cmd, spinner, err, formatCreateError(err, ""))
+		}
+
 		// Pre-check to determine if we need to prompt for email.
 		preCheck, err := workflows.CreatePreCheck(context.Background())
 		if err != nil {
-			spinner.FinalMSG = formatCreateError(err, "")
-			if isCreateUnexpectedError(err) {
-				return err
-			}
-			return nil
+			return reportCommandError(cmd, spinner, err, formatCreateError(err, ""))
 		}
 
 		// Handle email: use flag, existing config, or prompt.
@@ -106,27 +182,25 @@ Examples:
 			spinner.Restart()
 		}
 
-		// Warn if using force flag.
-		if force {
-			Logger.Infof("Force flag set, will override existing keys if present")
-			spinner.Stop()
-			Logger.WarnfUser("Using --force flag will overwrite existing keys - ensure you have backups")
-			spinner.Restart()
+		// Confirm before deleting an existing key (interactive - must stay in cmd layer).
+		if force && len(preCheck.ExistingKeyPaths) > 0 {
+			Logger.Infof("Force flag set, will delete existing keys: %v", preCheck.ExistingKeyPaths)
+			if !confirmCreateForce(spinner, preCheck.ExistingKeyPaths, createYes) {
+				spinner.FinalMSG = ui.Warning.Sprint("⚠") + " Key creation cancelled. Your existing key was not changed."
+				return nil
+			}
 		}
 
 		opts := workflows.CreateOptions{
 			Email:      userEmail,
 			DeviceName: createDevName,
 			Force:      force,
+			KeyType:    createKeyType,
 		}
 
 		result, err := workflows.Create(context.Background(), opts)
 		if err != nil {
-			spinner.FinalMSG = formatCreateError(err, userEmail)
-			if isCreateUnexpectedError(err) {
-				return err
-			}
-			return nil
+			return reportCommandError(cmd, spinner, err, formatCreateError(err, userEmail))
 		}
 
 		deletedMessage := ""
@@ -136,8 +210,14 @@ Examples:
 
 		Logger.Infof("Create command completed successfully for user: %s (%s)", result.Email, result.UserUUID)
 
+		publicKeyAction := "created"
+		if result.PublicKeyReplaced {
+			publicKeyAction = "replaced"
+		}
+
 		finalMessage := ui.Success.Sprint("✓") + " Keys created for " + ui.Highlight.Sprint(result.Email) + " (device: " + ui.Highlight.Sprint(result.DeviceName) + ")" +
-			"\n    created: " + ui.Path.Sprint(result.PublicKeyPath) + "\n" + deletedMessage +
+			"\n    " + publicKeyAction + ": " + ui.Path.Sprint(result.PublicKeyPath) +
+			"\n    key type: " + string(result.KeyType) + "\n" + deletedMessage +
 			ui.Info.Sprint("To gain access to secrets in this project:") +
 			"\n  1. Commit your " + ui.Path.Sprint(".kanuka/public_keys/"+result.UserUUID+".pub") + " file to your version control system" +
 			"\n  2. Ask someone with permissions to grant you access with:" +
@@ -148,6 +228,67 @@ Examples:
 	},
 }
 
+// runCreateNoRegister generates a pending key pair and writes its public key
+// to stdout or --out.
+func runCreateNoRegister(cmd *cobra.Command) error {
+	var stdout *os.File
+	if createOutPath == "" {
+		// Must run before the spinner starts, so that it and the deferred
+		// final message go to stderr.
+		var restore func()
+		stdout, restore = reserveStdout()
+		defer restore()
+	}
+
+	Logger.Infof("Starting create --no-register command")
+	spinner, cleanup := startSpinner("Generating pending key pair...", verbose)
+	defer cleanup()
+
+	result, err := workflows.CreatePendingKey(context.Background(), workflows.CreatePendingKeyOptions{
+		KeyType: createKeyType,
+		Force:   force,
+	})
+	if err != nil {
+		if errors.Is(err, kerrors.ErrPublicKeyExists) {
+			finalMessage := ui.Error.Sprint("✗") + " You already have a pending key at " + ui.Path.Sprint(configs.GetPendingKeyDirPath()) +
+				"\n" + ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets register") + " in the project to use it, or " +
+				ui.Flag.Sprint("--force") + " to replace it"
+			return reportCommandError(cmd, spinner, err, finalMessage)
+		}
+		return reportCommandError(cmd, spinner, err, formatCreateError(err, ""))
+	}
+
+	if createOutPath == "" {
+		if _, err := stdout.Write(result.PublicKeyPEM); err != nil {
+			return Logger.ErrorfAndReturn("Failed to write public key: %v", err)
+		}
+	} else if err := os.WriteFile(createOutPath, result.PublicKeyPEM, 0644); err != nil {
+		finalMessage := ui.Error.Sprint("✗") + " Failed to write the public key to " + ui.Path.Sprint(createOutPath) +
+			"\n" + ui.Error.Sprint("Error: ") + err.Error()
+		return reportCommandError(cmd, spinner, err, finalMessage)
+	}
+
+	Logger.Infof("Pending %s key pair created for user %s", result.KeyType, result.UserUUID)
+
+	email := result.Email
+	if email == "" {
+		email = "<your-email>"
+	}
+	finalMessage := ui.Success.Sprint("✓") + " Pending key pair created" +
+		"\n    private key: " + ui.Path.Sprint(result.PrivateKeyPath) +
+		"\n    key type: " + string(result.KeyType) +
+		"\n    fingerprint: " + ui.Highlight.Sprint(result.Fingerprint)
+	if createOutPath != "" {
+		finalMessage += "\n    public key: " + ui.Path.Sprint(createOutPath)
+	}
+	finalMessage += "\n" + ui.Info.Sprint("To gain access to a project:") +
+		"\n  1. Send your public key, saved as " + ui.Path.Sprint(result.UserUUID+".pub") + ", to someone with access, who runs:" +
+		"\n     " + ui.Code.Sprint("kanuka secrets register --file "+result.UserUUID+".pub --user "+email) +
+		"\n  2. Once you have the project, run " + ui.Code.Sprint("kanuka secrets register") + " in it to start using this key"
+	spinner.FinalMSG = finalMessage
+	return nil
+}
+
 // formatCreateError formats workflow errors into user-friendly messages.
 func formatCreateError(err error, email string) string {
 	switch {
@@ -176,26 +317,12 @@ func formatCreateError(err error, email string) string {
 		return ui.Error.Sprint("✗ ") + "Public key already exists" +
 			"\nTo override, run: " + ui.Code.Sprint("kanuka secrets create --force")
 
+	case errors.Is(err, kerrors.ErrInvalidArguments):
+		return ui.Error.Sprint("✗") + " Unsupported " + ui.Flag.Sprint("--key-type") + ": " + ui.Highlight.Sprint(createKeyType) +
+			"\n" + ui.Info.Sprint("→") + " Choose one of " + ui.Code.Sprint("rsa2048") + ", " + ui.Code.Sprint("rsa4096") + ", or " + ui.Code.Sprint("ed25519")
+
 	default:
 		return ui.Error.Sprint("✗") + " Failed to create keys\n" +
 			ui.Error.Sprint("Error: ") + err.Error()
 	}
 }
-
-// isCreateUnexpectedError returns true if the error is unexpected and should cause a non-zero exit.
-func isCreateUnexpectedError(err error) bool {
-	expectedErrors := []error{
-		kerrors.ErrProjectNotInitialized,
-		kerrors.ErrInvalidProjectConfig,
-		kerrors.ErrInvalidEmail,
-		kerrors.ErrDeviceNameTaken,
-		kerrors.ErrPublicKeyExists,
-	}
-
-	for _, expected := range expectedErrors {
-		if errors.Is(err, expected) {
-			return false
-		}
-	}
-	return true
-}
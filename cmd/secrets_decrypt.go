@@ -18,15 +18,37 @@ import (
 
 var decryptDryRun bool
 var decryptPrivateKeyStdin bool
+var decryptReportPath string
+var decryptStdout bool
+var decryptCheck bool
+var decryptOnlyChanged bool
+
+// decryptExitFunc is the function called to exit with a specific code.
+// It can be overridden in tests to capture exit codes.
+var decryptExitFunc = os.Exit
 
 func init() {
 	decryptCmd.Flags().BoolVar(&decryptDryRun, "dry-run", false, "preview decryption without making changes")
 	decryptCmd.Flags().BoolVar(&decryptPrivateKeyStdin, "private-key-stdin", false, "read private key from stdin instead of from disk")
+	decryptCmd.Flags().StringVar(&decryptReportPath, "report", "", "also write a JSON summary of the result to this file")
+	decryptCmd.Flags().BoolVar(&decryptStdout, "stdout", false, "print the plaintext to stdout instead of writing .env files")
+	decryptCmd.Flags().BoolVar(&decryptCheck, "check", false, "exit non-zero if any .env file differs from its .kanuka file, without writing anything")
+	decryptCmd.Flags().BoolVar(&decryptOnlyChanged, "only-changed", false, "skip .kanuka files that haven't changed since you last decrypted them")
 }
 
 func resetDecryptCommandState() {
 	decryptDryRun = false
 	decryptPrivateKeyStdin = false
+	decryptReportPath = ""
+	decryptStdout = false
+	decryptCheck = false
+	decryptOnlyChanged = false
+	decryptExitFunc = os.Exit
+}
+
+// SetDecryptExitFunc sets the exit function for testing purposes.
+func SetDecryptExitFunc(f func(int)) {
+	decryptExitFunc = f
 }
 
 var decryptCmd = &cobra.Command{
@@ -43,6 +65,10 @@ You can specify individual files, directories, or glob patterns:
   kanuka secrets decrypt .env.kanuka              # Single file
   kanuka secrets decrypt "services/*/.env.kanuka" # Glob pattern
   kanuka secrets decrypt services/api/            # Directory
+  kanuka secrets decrypt secrets.json.kanuka      # Any file named explicitly
+
+Directories and glob patterns only match .env files, so encrypted files with
+other names, such as secrets.json.kanuka, must be named explicitly.
 
 Use --dry-run to preview which files would be decrypted and detect any existing
 files that would be overwritten.
@@ -50,6 +76,28 @@ files that would be overwritten.
 Use --private-key-stdin to read your private key from stdin instead of from disk.
 This is useful for piping keys from secret managers (e.g., HashiCorp Vault, 1Password).
 
+Use --report to also write a JSON summary (created/updated files, timing,
+errors) to a file, for example to archive as a CI build artifact.
+
+Use --stdout to print the plaintext to stdout instead of writing .env files,
+so it can be piped into another tool without touching disk. Everything else
+the command prints goes to stderr. If several files match, their plaintexts
+are printed one after another. --stdout can't be combined with --dry-run or
+--output json.
+
+Use --check to compare each decrypted file with the .env file next to it
+without writing anything, for example to catch a .env that was edited but not
+re-encrypted in CI. Files with no .env are skipped. The command exits non-zero
+if any file differs or can't be decrypted. --check can't be combined with
+--stdout or --dry-run.
+
+Use --only-changed to skip .kanuka files whose contents haven't changed since
+you last decrypted them, which saves time in large projects and keeps local
+edits to those .env files. Kānuka records what it decrypted in
+.kanuka/.decrypt-state, which is local to your checkout and ignored by git.
+A file is always decrypted if its .env file is missing. --only-changed can't
+be combined with --stdout or --check.
+
 Examples:
   # Decrypt all .kanuka files
   kanuka secrets decrypt
@@ -64,18 +112,75 @@ Examples:
   kanuka secrets decrypt --dry-run
 
   # Decrypt using a key piped from a secret manager
-  vault read -field=private_key secret/kanuka | kanuka secrets decrypt --private-key-stdin`,
+  vault read -field=private_key secret/kanuka | kanuka secrets decrypt --private-key-stdin
+
+  # Pipe a decrypted file into another tool without writing .env
+  kanuka secrets decrypt .env.kanuka --stdout | docker run --env-file /dev/stdin app
+
+  # Fail CI if any .env file differs from its .kanuka file
+  kanuka secrets decrypt --check
+
+  # Only decrypt files that changed since the last decrypt, e.g. after git pull
+  kanuka secrets decrypt --only-changed
+
+  # Decrypt and save a JSON report for CI
+  kanuka secrets decrypt --report decrypt-report.json`,
 	RunE: runDecrypt,
 }
 
 func runDecrypt(cmd *cobra.Command, args []string) error {
+	opts := workflows.DecryptOptions{
+		FilePatterns: args,
+		DryRun:       decryptDryRun,
+		Check:        decryptCheck,
+		OnlyChanged:  decryptOnlyChanged,
+	}
+
+	// Registered first so it runs after the spinner and report are finished.
+	exitCode := 0
+	defer func() {
+		if exitCode != 0 {
+			decryptExitFunc(exitCode)
+		}
+	}()
+
+	// Must run before the spinner starts, so that it and the deferred final
+	// message also go to stderr.
+	if decryptStdout {
+		stdout, restore := reserveStdout()
+		defer restore()
+		opts.Output = stdout
+	}
+
 	Logger.Infof("Starting decrypt command")
 	spinner, cleanup := startSpinner("Decrypting environment files...", verbose)
 	defer cleanup()
 
-	opts := workflows.DecryptOptions{
-		FilePatterns: args,
-		DryRun:       decryptDryRun,
+	report := newCommandReport("decrypt")
+	defer writeCommandReport(decryptReportPath, report, spinner)
+
+	if decryptStdout && (decryptDryRun || jsonOutput()) {
+		err := fmt.Errorf("%w: --stdout can't be used with --dry-run or --output json", kerrors.ErrInvalidArguments)
+		Logger.Errorf("Invalid decrypt flags: %v", err)
+		report.fail(err)
+		return reportCommandError(cmd, spinner, err, ui.Error.Sprint("✗")+" "+ui.Flag.Sprint("--stdout")+" can't be used with "+
+			ui.Flag.Sprint("--dry-run")+" or "+ui.Flag.Sprint("--output json"))
+	}
+
+	if decryptCheck && (decryptStdout || decryptDryRun) {
+		err := fmt.Errorf("%w: --check can't be used with --stdout or --dry-run", kerrors.ErrInvalidArguments)
+		Logger.Errorf("Invalid decrypt flags: %v", err)
+		report.fail(err)
+		return reportCommandError(cmd, spinner, err, ui.Error.Sprint("✗")+" "+ui.Flag.Sprint("--check")+" can't be used with "+
+			ui.Flag.Sprint("--stdout")+" or "+ui.Flag.Sprint("--dry-run"))
+	}
+
+	if decryptOnlyChanged && (decryptStdout || decryptCheck) {
+		err := fmt.Errorf("%w: --only-changed can't be used with --stdout or --check", kerrors.ErrInvalidArguments)
+		Logger.Errorf("Invalid decrypt flags: %v", err)
+		report.fail(err)
+		return reportCommandError(cmd, spinner, err, ui.Error.Sprint("✗")+" "+ui.Flag.Sprint("--only-changed")+" can't be used with "+
+			ui.Flag.Sprint("--stdout")+" or "+ui.Flag.Sprint("--check"))
 	}
 
 	if decryptPrivateKeyStdin {
@@ -83,8 +188,8 @@ func runDecrypt(cmd *cobra.Command, args []string) error {
 		keyData, err := utils.ReadStdin()
 		if err != nil {
 			Logger.Errorf("Failed to read private key from stdin: %v", err)
-			spinner.FinalMSG = ui.Error.Sprint("✗") + " Failed to read private key from stdin: " + err.Error()
-			return nil
+			report.fail(err)
+			return reportCommandError(cmd, spinner, err, ui.Error.Sprint("✗")+" Failed to read private key from stdin: "+err.Error())
 		}
 		opts.PrivateKeyData = keyData
 	}
@@ -92,26 +197,70 @@ func runDecrypt(cmd *cobra.Command, args []string) error {
 	result, err := workflows.Decrypt(cmd.Context(), opts)
 	if err != nil {
 		Logger.Errorf("Decrypt workflow failed: %v", err)
-		spinner.FinalMSG = formatDecryptError(err, decryptPrivateKeyStdin)
+		report.fail(err)
+		err = reportCommandError(cmd, spinner, err, formatDecryptError(err, decryptPrivateKeyStdin))
 		spinner.Stop()
+		return err
+	}
+
+	for _, ignored := range result.IgnoredFiles {
+		Logger.Infof("Skipped %s: %s", ignored.Path, ignored.Reason)
+	}
+	for _, skipped := range result.SkippedFiles {
+		Logger.Infof("Skipped %s: unchanged since it was last decrypted", skipped)
+	}
+
+	report.Success = true
+	report.DryRun = result.DryRun
+	report.ProjectPath = result.ProjectPath
+	report.addFiles(result.Created, result.Updated)
+
+	if decryptCheck {
+		if result.Drifted > 0 {
+			report.fail(fmt.Errorf("%d file(s) differ from their .kanuka files", result.Drifted))
+			exitCode = kerrors.ExitGeneral
+		}
+		if jsonOutput() {
+			return printJSONResult(result)
+		}
+		printDecryptCheck(spinner, result)
 		return nil
 	}
 
+	if jsonOutput() {
+		return printJSONResult(result)
+	}
+
 	if result.DryRun {
-		return printDecryptDryRun(spinner, result.SourceFiles, result.ProjectPath)
+		return printDecryptDryRun(spinner, result.SourceFiles, len(result.SkippedFiles), result.ProjectPath)
+	}
+
+	if decryptStdout {
+		Logger.Infof("Decrypt command completed successfully. Printed %d files to stdout", len(result.SourceFiles))
+		spinner.FinalMSG = ""
+		return nil
+	}
+
+	if len(result.SourceFiles) == 0 {
+		Logger.Infof("Decrypt command completed successfully. All %d files were unchanged", len(result.SkippedFiles))
+		spinner.FinalMSG = ui.Success.Sprint("✓") + fmt.Sprintf(" All %d file(s) are unchanged since you last decrypted them", len(result.SkippedFiles))
+		return nil
 	}
 
-	formattedListOfFiles := utils.FormatPaths(result.DecryptedFiles)
-	Logger.Infof("Decrypt command completed successfully. Created %d environment files", len(result.DecryptedFiles))
+	Logger.Infof("Decrypt command completed successfully. Created %d and updated %d environment files", len(result.Created), len(result.Updated))
 
 	spinner.Stop()
 	Logger.WarnfUser("Decrypted .env files contain sensitive data - ensure they're in your .gitignore")
 	spinner.Restart()
 
 	spinner.FinalMSG = ui.Success.Sprint("✓") + " Environment files decrypted successfully!" +
-		"\nThe following files were created:" + formattedListOfFiles +
+		formatCreatedUpdated(result.Created, result.Updated) +
 		"\n" + ui.Info.Sprint("→") + " Your environment files are now ready to use"
 
+	if len(result.SkippedFiles) > 0 {
+		spinner.FinalMSG += "\n" + fmt.Sprintf("Skipped %d unchanged file(s)", len(result.SkippedFiles))
+	}
+
 	return nil
 }
 
@@ -131,6 +280,9 @@ func formatDecryptError(err error, fromStdin bool) string {
 			"\n\n" + ui.Info.Sprint("→") + " You don't have access to this project. Ask someone with access to run:" +
 			"\n   " + ui.Code.Sprint("kanuka secrets register --user <your-email>")
 
+	case errors.Is(err, kerrors.ErrInsecureKeyPermissions):
+		return formatInsecureKeyPermissionsError(err)
+
 	case errors.Is(err, kerrors.ErrPrivateKeyNotFound):
 		return ui.Error.Sprint("✗") + " Failed to get your private key file. Are you sure you have access?" +
 			"\n" + err.Error() +
@@ -138,12 +290,7 @@ func formatDecryptError(err error, fromStdin bool) string {
 			"\n   " + ui.Code.Sprint("kanuka secrets register --user <your-email>")
 
 	case errors.Is(err, kerrors.ErrInvalidPrivateKey):
-		if fromStdin {
-			return ui.Error.Sprint("✗") + " Failed to parse private key from stdin" +
-				"\n" + ui.Info.Sprint("→") + " Ensure your private key is in valid format (PEM or OpenSSH)"
-		}
-		return ui.Error.Sprint("✗") + " Failed to parse private key" +
-			"\n" + ui.Info.Sprint("→") + " Ensure your private key is in valid format (PEM or OpenSSH)"
+		return formatInvalidPrivateKeyError(err, fromStdin)
 
 	case errors.Is(err, kerrors.ErrKeyDecryptFailed):
 		return ui.Error.Sprint("✗") + " Failed to decrypt your " +
@@ -151,6 +298,10 @@ func formatDecryptError(err error, fromStdin bool) string {
 			"\n\n" + ui.Info.Sprint("→") + " Your encrypted key file appears to be corrupted." +
 			"\n   Try asking the project administrator to revoke and re-register your access."
 
+	case errors.Is(err, kerrors.ErrGPGNotFound):
+		return ui.Error.Sprint("✗") + " Your access to this project uses a GPG key, but " + ui.Code.Sprint("gpg") + " was not found" +
+			"\n" + ui.Info.Sprint("→") + " Install GnuPG and make sure " + ui.Code.Sprint("gpg") + " is on your PATH"
+
 	case errors.Is(err, kerrors.ErrDecryptFailed):
 		return ui.Error.Sprint("✗") + " Failed to decrypt the project's " +
 			ui.Path.Sprint(".kanuka") + " files." +
@@ -161,7 +312,7 @@ func formatDecryptError(err error, fromStdin bool) string {
 	}
 }
 
-func printDecryptDryRun(s *spinner.Spinner, kanukaFiles []string, projectPath string) error {
+func printDecryptDryRun(s *spinner.Spinner, kanukaFiles []string, skipped int, projectPath string) error {
 	s.Stop()
 
 	fmt.Println()
@@ -195,12 +346,50 @@ func printDecryptDryRun(s *spinner.Spinner, kanukaFiles []string, projectPath st
 		fmt.Println()
 	}
 
+	if skipped > 0 {
+		fmt.Printf("%d unchanged file(s) would be skipped.\n", skipped)
+		fmt.Println()
+	}
+
 	fmt.Println(ui.Info.Sprint("No changes made.") + " Run without --dry-run to execute.")
 
 	s.FinalMSG = ""
 	return nil
 }
 
+// printDecryptCheck lists how each .env file compares with its .kanuka file.
+func printDecryptCheck(s *spinner.Spinner, result *workflows.DecryptResult) {
+	s.Stop()
+
+	skipped := 0
+	for _, check := range result.Checks {
+		relPath, err := filepath.Rel(result.ProjectPath, check.Path)
+		if err != nil {
+			relPath = check.Path
+		}
+
+		switch check.Status {
+		case workflows.DriftInSync:
+			fmt.Printf("  %s %s\n", ui.Success.Sprint("✓"), ui.Path.Sprint(relPath))
+		case workflows.DriftChanged:
+			fmt.Printf("  %s %s (differs from %s)\n", ui.Error.Sprint("✗"), ui.Path.Sprint(relPath), relPath+".kanuka")
+		case workflows.DriftNoPlaintext:
+			fmt.Printf("  %s %s (no plaintext file, skipped)\n", ui.Muted.Sprint("-"), ui.Path.Sprint(relPath))
+			skipped++
+		}
+	}
+	fmt.Println()
+
+	Logger.Infof("Decrypt check completed: %d of %d files drifted", result.Drifted, len(result.Checks))
+	if result.Drifted > 0 {
+		s.FinalMSG = ui.Error.Sprint("✗") + fmt.Sprintf(" %d file(s) differ from their encrypted version", result.Drifted) +
+			"\n" + ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets encrypt") + " to update the .kanuka files, or " +
+			ui.Code.Sprint("kanuka secrets decrypt") + " to discard the local changes"
+		return
+	}
+	s.FinalMSG = ui.Success.Sprint("✓") + fmt.Sprintf(" %d file(s) match their encrypted version", len(result.Checks)-skipped)
+}
+
 // GetDecryptCmd returns the decrypt command for testing.
 func GetDecryptCmd() *cobra.Command {
 	return decryptCmd
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/utils"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffShowValues      bool
+	diffPrivateKeyStdin bool
+)
+
+func init() {
+	diffCmd.Flags().BoolVar(&diffShowValues, "show-values", false, "show secret values instead of masking them")
+	diffCmd.Flags().BoolVar(&diffPrivateKeyStdin, "private-key-stdin", false, "read private key from stdin instead of from disk")
+}
+
+func resetDiffCommandState() {
+	diffShowValues = false
+	diffPrivateKeyStdin = false
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [files...]",
+	Short: "Show which secret keys changed since the last encrypt",
+	Long: `Compares each .env file with its encrypted .kanuka file and lists the keys
+that running encrypt would add, remove, or change.
+
+The .kanuka files are decrypted in memory only; nothing is written to disk.
+Values are masked unless --show-values is given.
+
+Accepts the same files, directories, and glob patterns as encrypt.
+
+Examples:
+  # Show what encrypt would change
+  kanuka secrets diff
+
+  # Compare a single file and reveal the values
+  kanuka secrets diff .env --show-values
+
+  # Compare using a key piped from a secret manager
+  vault read -field=private_key secret/kanuka | kanuka secrets diff --private-key-stdin`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		Logger.Infof("Starting diff command")
+		spinner, cleanup := startSpinner("Comparing environment files...", verbose)
+		defer cleanup()
+
+		opts := workflows.DiffOptions{FilePatterns: args}
+
+		if diffPrivateKeyStdin {
+			Logger.Debugf("Reading private key from stdin")
+			keyData, err := utils.ReadStdin()
+			if err != nil {
+				Logger.Errorf("Failed to read private key from stdin: %v", err)
+				return reportCommandError(cmd, spinner, err, ui.Error.Sprint("✗")+" Failed to read private key from stdin: "+err.Error())
+			}
+			opts.PrivateKeyData = keyData
+		}
+
+		result, err := workflows.Diff(cmd.Context(), opts)
+		if err != nil {
+			Logger.Errorf("Diff workflow failed: %v", err)
+			return reportCommandError(cmd, spinner, err, formatDiffError(err, diffPrivateKeyStdin))
+		}
+
+		changed := 0
+		for _, f := range result.Files {
+			if !f.Changes.Empty() {
+				changed++
+			}
+		}
+		Logger.Infof("Diff command completed: %d of %d files changed", changed, len(result.Files))
+
+		if changed == 0 {
+			spinner.FinalMSG = ui.Success.Sprint("✓") + " No changes. Encrypted files are up to date"
+			return nil
+		}
+
+		spinner.FinalMSG = ""
+		spinner.Stop()
+		printDiffResult(result)
+		return nil
+	},
+}
+
+// formatDiffError formats workflow errors into user-friendly messages.
+func formatDiffError(err error, fromStdin bool) string {
+	if errors.Is(err, kerrors.ErrNoFilesFound) {
+		return ui.Error.Sprint("✗") + " No environment files found"
+	}
+	return formatDecryptError(err, fromStdin)
+}
+
+// printDiffResult prints the changed keys of each file that differs.
+func printDiffResult(result *workflows.DiffResult) {
+	var added, removed, modified int
+
+	for _, f := range result.Files {
+		if f.Changes.Empty() {
+			continue
+		}
+
+		header := ui.Path.Sprint(f.Path)
+		if !f.Encrypted {
+			header += " " + ui.Muted.Sprint("(not yet encrypted)")
+		}
+		fmt.Println(header)
+
+		for _, c := range f.Changes.Added {
+			line := "+ " + c.Key
+			if diffShowValues {
+				line += "=" + c.NewValue
+			}
+			fmt.Println("  " + ui.Success.Sprint(line))
+		}
+		for _, c := range f.Changes.Removed {
+			line := "- " + c.Key
+			if diffShowValues {
+				line += "=" + c.OldValue
+			}
+			fmt.Println("  " + ui.Error.Sprint(line))
+		}
+		for _, c := range f.Changes.Modified {
+			line := "~ " + c.Key
+			if diffShowValues {
+				line += "=" + c.OldValue + " → " + c.NewValue
+			}
+			fmt.Println("  " + ui.Warning.Sprint(line))
+		}
+		fmt.Println()
+
+		added += len(f.Changes.Added)
+		removed += len(f.Changes.Removed)
+		modified += len(f.Changes.Modified)
+	}
+
+	fmt.Printf("%d added, %d removed, %d changed\n", added, removed, modified)
+	fmt.Println(ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets encrypt") + " to apply these changes")
+}
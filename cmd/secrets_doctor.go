@@ -45,6 +45,7 @@ The doctor command checks:
   - Public key and encrypted symmetric key consistency
   - Gitignore configuration for .env files
   - Unencrypted .env files
+  - Device and audit timestamps dated in the future (clock skew)
 
 Exit codes:
   0 - All checks passed
@@ -58,13 +59,13 @@ Use --json for machine-readable output.`,
 func runDoctor(cmd *cobra.Command, args []string) error {
 	Logger.Infof("Starting doctor command")
 
-	spinner, cleanup := startSpinner("Running health checks...", verbose)
+	spinner, cleanup := startReportSpinner("Running health checks...", verbose)
 	defer cleanup()
 
 	result, err := workflows.Doctor(context.Background(), workflows.DoctorOptions{})
 	if err != nil {
-		spinner.FinalMSG = ui.Error.Sprint("✗") + " Failed to run health checks: " + err.Error()
-		return err
+		finalMessage := ui.Error.Sprint("✗") + " Failed to run health checks: " + err.Error()
+		return reportCommandError(cmd, spinner, err, finalMessage)
 	}
 
 	for _, check := range result.Checks {
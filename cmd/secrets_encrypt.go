@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 
 	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
 	"github.com/PolarWolf314/kanuka/internal/ui"
 	"github.com/PolarWolf314/kanuka/internal/utils"
 	"github.com/PolarWolf314/kanuka/internal/workflows"
@@ -17,16 +18,43 @@ import (
 var (
 	encryptDryRun          bool
 	encryptPrivateKeyStdin bool
+	encryptReportPath      string
+	encryptGitAdd          bool
+	encryptStdin           bool
+	encryptName            string
+	encryptJobs            int
+	encryptBackup          bool
+	encryptRecursive       bool
+	encryptNameFilter      string
+	encryptDeletePlaintext bool
 )
 
 func init() {
 	encryptCmd.Flags().BoolVar(&encryptDryRun, "dry-run", false, "preview encryption without making changes")
 	encryptCmd.Flags().BoolVar(&encryptPrivateKeyStdin, "private-key-stdin", false, "read private key from stdin instead of from disk")
+	encryptCmd.Flags().StringVar(&encryptReportPath, "report", "", "also write a JSON summary of the result to this file")
+	encryptCmd.Flags().BoolVar(&encryptGitAdd, "git-add", false, "stage the encrypted files with git add after encrypting")
+	encryptCmd.Flags().BoolVar(&encryptStdin, "stdin", false, "read plaintext secrets from stdin instead of from a file (requires --name)")
+	encryptCmd.Flags().StringVar(&encryptName, "name", "", "the .env file name to encrypt stdin as, e.g. .env produces .env.kanuka")
+	encryptCmd.Flags().IntVar(&encryptJobs, "jobs", 0, "number of files to encrypt at once (default: number of CPUs)")
+	encryptCmd.Flags().BoolVar(&encryptBackup, "backup", false, "keep the previous .kanuka file as .kanuka.bak before overwriting it")
+	encryptCmd.Flags().BoolVar(&encryptRecursive, "recursive", true, "search subdirectories for .env files; --recursive=false searches only the project root")
+	encryptCmd.Flags().StringVar(&encryptNameFilter, "name-filter", "", "only encrypt .env files whose name matches this glob, e.g. '.env.prod*'")
+	encryptCmd.Flags().BoolVar(&encryptDeletePlaintext, "delete-plaintext", false, "delete each .env file after checking its .kanuka file decrypts back to it")
 }
 
 func resetEncryptCommandState() {
 	encryptDryRun = false
 	encryptPrivateKeyStdin = false
+	encryptReportPath = ""
+	encryptGitAdd = false
+	encryptStdin = false
+	encryptName = ""
+	encryptJobs = 0
+	encryptBackup = false
+	encryptRecursive = true
+	encryptNameFilter = ""
+	encryptDeletePlaintext = false
 }
 
 var encryptCmd = &cobra.Command{
@@ -43,13 +71,40 @@ You can specify individual files, directories, or glob patterns:
   kanuka secrets encrypt .env                 # Single file
   kanuka secrets encrypt .env .env.local      # Multiple files
   kanuka secrets encrypt "services/*/.env"    # Glob pattern
+
+  # Only the production files in the project root
+  kanuka secrets encrypt --recursive=false --name-filter '.env.prod*'
+
+  # Keep the previous ciphertext as .env.kanuka.bak
+  kanuka secrets encrypt --backup
   kanuka secrets encrypt services/api/        # Directory
+  kanuka secrets encrypt secrets.json         # Any file named explicitly
+
+Directories and glob patterns only match .env files, but a file named
+explicitly can have any name; it is encrypted to the same name with .kanuka
+appended. Naming a file that already ends in .kanuka is an error.
 
 Use --dry-run to preview which files would be encrypted without making changes.
 
 Use --private-key-stdin to read your private key from stdin instead of from disk.
 This is useful for piping keys from secret managers (e.g., HashiCorp Vault, 1Password).
 
+Use --stdin with --name to encrypt secrets piped on stdin, so they never touch
+disk in plaintext. --name is the .env file the secrets belong to, relative to
+the project root; the encrypted output is that name with .kanuka appended.
+--stdin can't be combined with file arguments or --private-key-stdin.
+
+Files are encrypted in parallel, one per CPU by default. Use --jobs to change
+how many are encrypted at once. If some files fail, the rest are still
+encrypted and every failure is reported.
+
+Use --git-add to stage the created and updated .kanuka files with git add, so
+the repository is ready to commit. Kānuka never commits for you. Outside a git
+repository the flag does nothing.
+
+Use --report to also write a JSON summary (created/updated files, timing,
+errors) to a file, for example to archive as a CI build artifact.
+
 Examples:
   # Encrypt all .env files
   kanuka secrets encrypt
@@ -64,7 +119,19 @@ Examples:
   kanuka secrets encrypt --dry-run
 
   # Encrypt using a key piped from a secret manager
-  vault read -field=private_key secret/kanuka | kanuka secrets encrypt --private-key-stdin`,
+  vault read -field=private_key secret/kanuka | kanuka secrets encrypt --private-key-stdin
+
+  # Encrypt secrets piped from another tool into .env.kanuka
+  generate-secrets | kanuka secrets encrypt --stdin --name .env
+
+  # Encrypt and stage the .kanuka files, ready to commit
+  kanuka secrets encrypt --git-add
+
+  # Encrypt and save a JSON report for CI
+  kanuka secrets encrypt --report encrypt-report.json
+
+  # Encrypt, then delete each .env file once its .kanuka file is verified
+  kanuka secrets encrypt --delete-plaintext`,
 	RunE: runEncrypt,
 }
 
@@ -73,9 +140,52 @@ func runEncrypt(cmd *cobra.Command, args []string) error {
 	spinner, cleanup := startSpinner("Encrypting environment files...", verbose)
 	defer cleanup()
 
+	report := newCommandReport("encrypt")
+	defer writeCommandReport(encryptReportPath, report, spinner)
+
 	opts := workflows.EncryptOptions{
-		FilePatterns: args,
-		DryRun:       encryptDryRun,
+		FilePatterns:    args,
+		DryRun:          encryptDryRun,
+		Jobs:            encryptJobs,
+		Backup:          encryptBackup,
+		DeletePlaintext: encryptDeletePlaintext,
+		Discover: secrets.DiscoverOptions{
+			NoRecurse:  !encryptRecursive,
+			NameFilter: encryptNameFilter,
+		},
+	}
+
+	problem := validateEncryptStdinFlags(args)
+	if problem == "" {
+		problem = validateEncryptDiscoverFlags(args)
+	}
+	if problem == "" && encryptJobs < 0 {
+		problem = fmt.Sprintf("--jobs must be at least 1, got %d", encryptJobs)
+	}
+	if problem != "" {
+		err := fmt.Errorf("%w: %s", kerrors.ErrInvalidArguments, problem)
+		Logger.Errorf("Invalid encrypt flags: %v", err)
+		report.fail(err)
+		return reportCommandError(cmd, spinner, err, ui.Error.Sprint("✗")+" "+problem)
+	}
+
+	if encryptStdin {
+		if utils.IsTerminal() {
+			err := fmt.Errorf("%w: --stdin requires secrets piped on stdin", kerrors.ErrInvalidArguments)
+			Logger.Errorf("Stdin is a terminal: %v", err)
+			report.fail(err)
+			return reportCommandError(cmd, spinner, err, ui.Error.Sprint("✗")+" No secrets piped on stdin"+
+				"\n"+ui.Info.Sprint("→")+" Pipe them in, e.g. "+ui.Code.Sprint("cat secrets | kanuka secrets encrypt --stdin --name .env"))
+		}
+		Logger.Debugf("Reading plaintext from stdin")
+		plaintext, err := utils.ReadStdin()
+		if err != nil {
+			Logger.Errorf("Failed to read plaintext from stdin: %v", err)
+			report.fail(err)
+			return reportCommandError(cmd, spinner, err, ui.Error.Sprint("✗")+" Failed to read secrets from stdin: "+err.Error())
+		}
+		opts.Plaintext = plaintext
+		opts.PlaintextName = encryptName
 	}
 
 	if encryptPrivateKeyStdin {
@@ -83,8 +193,8 @@ func runEncrypt(cmd *cobra.Command, args []string) error {
 		keyData, err := utils.ReadStdin()
 		if err != nil {
 			Logger.Errorf("Failed to read private key from stdin: %v", err)
-			spinner.FinalMSG = ui.Error.Sprint("✗") + " Failed to read private key from stdin: " + err.Error()
-			return nil
+			report.fail(err)
+			return reportCommandError(cmd, spinner, err, ui.Error.Sprint("✗")+" Failed to read private key from stdin: "+err.Error())
 		}
 		opts.PrivateKeyData = keyData
 	}
@@ -92,27 +202,120 @@ func runEncrypt(cmd *cobra.Command, args []string) error {
 	result, err := workflows.Encrypt(cmd.Context(), opts)
 	if err != nil {
 		Logger.Errorf("Encrypt workflow failed: %v", err)
-		spinner.FinalMSG = formatEncryptError(err, encryptPrivateKeyStdin)
+		report.fail(err)
+		err = reportCommandError(cmd, spinner, err, formatEncryptError(err, encryptPrivateKeyStdin))
 		spinner.Stop()
-		return nil
+		return err
+	}
+
+	for _, ignored := range result.IgnoredFiles {
+		Logger.Infof("Skipped %s: %s", ignored.Path, ignored.Reason)
+	}
+
+	report.Success = true
+	report.DryRun = result.DryRun
+	report.ProjectPath = result.ProjectPath
+	report.addFiles(result.Created, result.Updated)
+
+	if jsonOutput() {
+		if encryptGitAdd && !result.DryRun {
+			stageEncryptedFiles(cmd, result)
+		}
+		return printJSONResult(result)
 	}
 
 	if result.DryRun {
-		return printEncryptDryRun(spinner, result.SourceFiles, result.ProjectPath)
+		return printEncryptDryRun(spinner, result.SourceFiles, result.BackupFiles, result.DeletedFiles, result.ProjectPath)
 	}
 
-	formattedListOfFiles := utils.FormatPaths(result.EncryptedFiles)
-	Logger.Infof("Encrypt command completed successfully. Created %d .kanuka files", len(result.EncryptedFiles))
+	for _, encrypted := range result.EncryptedFiles {
+		Logger.Infof("Encrypted %s", encrypted)
+	}
+	Logger.Infof("Encrypt command completed successfully. Created %d and updated %d .kanuka files", len(result.Created), len(result.Updated))
 
 	spinner.FinalMSG = ui.Success.Sprint("✓") + " Environment files encrypted successfully!" +
-		"\nThe following files were created: " + formattedListOfFiles +
-		"\n" + ui.Info.Sprint("→") + " You can now safely commit all " + ui.Path.Sprint(".kanuka") + " files to version control" +
-		"\n\n" + ui.Info.Sprint("Note:") + " Encryption is non-deterministic for security reasons." +
+		formatCreatedUpdated(result.Created, result.Updated)
+
+	if len(result.BackupFiles) > 0 {
+		spinner.FinalMSG += "\nPrevious versions were backed up to: " + utils.FormatPaths(result.BackupFiles)
+	}
+
+	if len(result.DeletedFiles) > 0 {
+		spinner.FinalMSG += "\nVerified and deleted the plaintext files: " + utils.FormatPaths(result.DeletedFiles)
+	}
+
+	if encryptGitAdd {
+		spinner.FinalMSG += "\n" + stageEncryptedFiles(cmd, result)
+	} else {
+		spinner.FinalMSG += "\n" + ui.Info.Sprint("→") + " You can now safely commit all " + ui.Path.Sprint(".kanuka") + " files to version control"
+	}
+
+	spinner.FinalMSG += "\n\n" + ui.Info.Sprint("Note:") + " Encryption is non-deterministic for security reasons." +
 		"\n       Re-encrypting unchanged files will produce different output."
 
 	return nil
 }
 
+// validateEncryptStdinFlags checks that --stdin and --name are used together
+// and not with file arguments or --private-key-stdin. It returns a description
+// of the problem, or "" if the flags are valid.
+func validateEncryptStdinFlags(args []string) string {
+	switch {
+	case encryptName != "" && !encryptStdin:
+		return "--name can only be used with --stdin"
+	case !encryptStdin:
+		return ""
+	case encryptName == "":
+		return "--stdin requires --name to name the encrypted file, e.g. --name .env"
+	case len(args) > 0:
+		return "--stdin can't be combined with file arguments"
+	case encryptPrivateKeyStdin:
+		return "--stdin and --private-key-stdin can't both read stdin"
+	case encryptDeletePlaintext:
+		return "--delete-plaintext can't be combined with --stdin, which has no file to delete"
+	}
+	return ""
+}
+
+// validateEncryptDiscoverFlags checks that --recursive=false and --name-filter
+// are only used when encrypt discovers the files itself, and that the filter
+// is a valid glob. It returns a description of the problem, or "" if the flags
+// are valid.
+func validateEncryptDiscoverFlags(args []string) string {
+	if encryptRecursive && encryptNameFilter == "" {
+		return ""
+	}
+	switch {
+	case len(args) > 0:
+		return "--recursive=false and --name-filter can't be combined with file arguments"
+	case encryptStdin:
+		return "--recursive=false and --name-filter can't be combined with --stdin"
+	}
+	if encryptNameFilter != "" {
+		if err := secrets.ValidateNameFilter(encryptNameFilter); err != nil {
+			return fmt.Sprintf("--name-filter %q is not a valid glob", encryptNameFilter)
+		}
+	}
+	return ""
+}
+
+// stageEncryptedFiles stages the encrypted files with git and returns a
+// message describing the outcome. Staging failures are reported as warnings,
+// since the files were already encrypted successfully.
+func stageEncryptedFiles(cmd *cobra.Command, result *workflows.EncryptResult) string {
+	staged, err := workflows.StageFiles(cmd.Context(), result.ProjectPath, result.EncryptedFiles)
+	if err != nil {
+		Logger.Warnf("Failed to stage encrypted files: %v", err)
+		return ui.Warning.Sprint("⚠") + " Failed to stage the " + ui.Path.Sprint(".kanuka") + " files: " + err.Error()
+	}
+	if staged.NotARepository {
+		return ui.Warning.Sprint("⚠") + " Not a git repository, so nothing was staged"
+	}
+	Logger.Infof("Staged %d encrypted files", len(staged.Staged))
+	return ui.Success.Sprint("✓") + fmt.Sprintf(" Staged %d ", len(staged.Staged)) + ui.Path.Sprint(".kanuka") +
+		" file(s) with git. Review with " + ui.Code.Sprint("git status") + " and commit when ready"
+}
+
 func formatEncryptError(err error, fromStdin bool) string {
 	switch {
 	case errors.Is(err, kerrors.ErrProjectNotInitialized):
@@ -128,18 +331,16 @@ func formatEncryptError(err error, fromStdin bool) string {
 			"\n\n" + ui.Info.Sprint("→") + " You don't have access to this project. Ask someone with access to run:" +
 			"\n   " + ui.Code.Sprint("kanuka secrets register --user <your-email>")
 
+	case errors.Is(err, kerrors.ErrInsecureKeyPermissions):
+		return formatInsecureKeyPermissionsError(err)
+
 	case errors.Is(err, kerrors.ErrPrivateKeyNotFound):
 		return ui.Error.Sprint("✗") + " Failed to get your private key file. Are you sure you have access?" +
 			"\n\n" + ui.Info.Sprint("→") + " You don't have access to this project. Ask someone with access to run:" +
 			"\n   " + ui.Code.Sprint("kanuka secrets register --user <your-email>")
 
 	case errors.Is(err, kerrors.ErrInvalidPrivateKey):
-		if fromStdin {
-			return ui.Error.Sprint("✗") + " Failed to parse private key from stdin" +
-				"\n" + ui.Info.Sprint("→") + " Ensure your private key is in valid format (PEM or OpenSSH)"
-		}
-		return ui.Error.Sprint("✗") + " Failed to parse private key" +
-			"\n" + ui.Info.Sprint("→") + " Ensure your private key is in valid format (PEM or OpenSSH)"
+		return formatInvalidPrivateKeyError(err, fromStdin)
 
 	case errors.Is(err, kerrors.ErrKeyDecryptFailed):
 		return ui.Error.Sprint("✗") + " Failed to decrypt your " +
@@ -157,7 +358,7 @@ func formatEncryptError(err error, fromStdin bool) string {
 	}
 }
 
-func printEncryptDryRun(spinner *spinner.Spinner, envFiles []string, projectPath string) error {
+func printEncryptDryRun(spinner *spinner.Spinner, envFiles, backupFiles, deletedFiles []string, projectPath string) error {
 	spinner.Stop()
 
 	fmt.Println()
@@ -175,6 +376,30 @@ func printEncryptDryRun(spinner *spinner.Spinner, envFiles []string, projectPath
 	}
 	fmt.Println()
 
+	if len(backupFiles) > 0 {
+		fmt.Println("Backups that would be written:")
+		for _, backupFile := range backupFiles {
+			relPath, err := filepath.Rel(projectPath, backupFile)
+			if err != nil {
+				relPath = backupFile
+			}
+			fmt.Printf("  %s\n", ui.Path.Sprint(relPath))
+		}
+		fmt.Println()
+	}
+
+	if len(deletedFiles) > 0 {
+		fmt.Println("Plaintext files that would be deleted once verified:")
+		for _, deletedFile := range deletedFiles {
+			relPath, err := filepath.Rel(projectPath, deletedFile)
+			if err != nil {
+				relPath = deletedFile
+			}
+			fmt.Printf("  %s\n", ui.Path.Sprint(relPath))
+		}
+		fmt.Println()
+	}
+
 	fmt.Println(ui.Info.Sprint("No changes made.") + " Run without --dry-run to execute.")
 
 	spinner.FinalMSG = ""
@@ -1,26 +1,35 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"strings"
 
 	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/utils"
 	"github.com/PolarWolf314/kanuka/internal/workflows"
 
 	"github.com/spf13/cobra"
 )
 
-var exportOutputPath string
+var (
+	exportOutputPath     string
+	exportEncryptArchive bool
+)
 
 func init() {
-	exportCmd.Flags().StringVarP(&exportOutputPath, "output", "o", "", "output path for the archive (default: kanuka-secrets-YYYY-MM-DD.tar.gz)")
+	exportCmd.Flags().StringVarP(&exportOutputPath, "output", "o", "", "output path for the archive, or - for stdout (default: kanuka-secrets-YYYY-MM-DD.tar.gz)")
+	exportCmd.Flags().BoolVar(&exportEncryptArchive, "encrypt-archive", false, "encrypt the whole archive with a passphrase")
 }
 
 // resetExportCommandState resets the export command's global state for testing.
 func resetExportCommandState() {
 	exportOutputPath = ""
+	exportEncryptArchive = false
 }
 
 var exportCmd = &cobra.Command{
@@ -41,6 +50,18 @@ The archive does NOT include:
 Use -o/--output to specify a custom output path.
 Default filename includes today's date: kanuka-secrets-YYYY-MM-DD.tar.gz
 
+Use -o - to write the archive to stdout, for example to upload it to S3 with
+the AWS CLI without a local copy. Everything else the command prints goes to
+stderr. Kānuka doesn't upload to s3:// URLs itself; an s3:// output is an
+error.
+
+The config, public keys, and audit log are stored in plaintext inside the
+archive. Use --encrypt-archive to encrypt the whole archive with a passphrase
+before it leaves a trusted environment. You will be prompted for the
+passphrase, or it can be set with KANUKA_ARCHIVE_PASSPHRASE. The default
+filename then ends in .tar.gz.enc. Import detects encrypted archives and asks
+for the passphrase.
+
 Examples:
   # Export to default filename
   kanuka secrets export
@@ -48,34 +69,78 @@ Examples:
   # Export to custom path
   kanuka secrets export -o /backups/project-secrets.tar.gz
 
+  # Export a passphrase-protected archive for offsite storage
+  kanuka secrets export --encrypt-archive
+
+  # Stream the archive to S3 with server-side encryption
+  kanuka secrets export -o - | aws s3 cp - s3://backups/project.tar.gz --sse AES256
+
   # Export with verbose output
   kanuka secrets export --verbose`,
 	RunE: runExport,
 }
 
 func runExport(cmd *cobra.Command, args []string) error {
+	// Checked before the passphrase prompt, so nothing is asked for an
+	// export that can't run.
+	if strings.HasPrefix(exportOutputPath, "s3://") {
+		err := fmt.Errorf("%w: can't upload to %s directly", kerrors.ErrInvalidArguments, exportOutputPath)
+		Logger.Errorf("Unsupported export destination: %v", err)
+		finalMessage := ui.Error.Sprint("✗") + " Kānuka can't upload to " + ui.Path.Sprint(exportOutputPath) + " directly" +
+			"\n" + ui.Info.Sprint("→") + " Stream the archive to the AWS CLI instead:" +
+			"\n   " + ui.Code.Sprint("kanuka secrets export -o - | aws s3 cp - "+exportOutputPath)
+		return reportPrintedError(cmd, err, finalMessage)
+	}
+
+	opts := workflows.ExportOptions{
+		OutputPath: exportOutputPath,
+	}
+
+	// Must run before the spinner starts, so that it and the deferred final
+	// message also go to stderr.
+	if exportOutputPath == exportToStdout {
+		stdout, restore := reserveStdout()
+		defer restore()
+		opts.Output = stdout
+	}
+
 	Logger.Infof("Starting export command")
+
+	// Prompt before the spinner starts so it doesn't draw over the prompt.
+	var passphrase []byte
+	var passphraseErr error
+	if exportEncryptArchive {
+		passphrase, passphraseErr = readArchivePassphrase(true)
+	}
+
 	spinner, cleanup := startSpinner("Exporting secrets...", verbose)
 	defer cleanup()
 
-	opts := workflows.ExportOptions{
-		OutputPath: exportOutputPath,
+	if passphraseErr != nil {
+		Logger.Errorf("Failed to read archive passphrase: %v", passphraseErr)
+		return reportCommandError(cmd, spinner, passphraseErr, formatArchivePassphraseError(passphraseErr))
 	}
 
+	opts.Passphrase = passphrase
+
 	result, err := workflows.Export(context.Background(), opts)
 	if err != nil {
-		spinner.FinalMSG = formatExportError(err)
-		if isExportUnexpectedError(err) {
-			return err
-		}
-		return nil
+		return reportCommandError(cmd, spinner, err, formatExportError(err))
 	}
 
-	Logger.Infof("Archive created successfully at %s", result.OutputPath)
+	if opts.Output != nil {
+		Logger.Infof("Archive written to stdout")
+		result.OutputPath = "stdout"
+	} else {
+		Logger.Infof("Archive created successfully at %s", result.OutputPath)
+	}
 	spinner.FinalMSG = formatExportSuccess(result)
 	return nil
 }
 
+// exportToStdout is the --output value that writes the archive to stdout.
+const exportToStdout = "-"
+
 // formatExportError formats an export error for display to the user.
 func formatExportError(err error) string {
 	switch {
@@ -98,18 +163,6 @@ func formatExportError(err error) string {
 	}
 }
 
-// isExportUnexpectedError returns true if the error is unexpected and should cause a non-zero exit.
-func isExportUnexpectedError(err error) bool {
-	switch {
-	case errors.Is(err, kerrors.ErrProjectNotInitialized),
-		errors.Is(err, kerrors.ErrInvalidProjectConfig),
-		errors.Is(err, kerrors.ErrNoFilesFound):
-		return false
-	default:
-		return true
-	}
-}
-
 // formatExportSuccess formats a successful export result for display to the user.
 func formatExportSuccess(result *workflows.ExportResult) string {
 	message := ui.Success.Sprint("✓") + " Exported secrets to " + ui.Path.Sprint(result.OutputPath) +
@@ -128,8 +181,57 @@ func formatExportSuccess(result *workflows.ExportResult) string {
 		message += fmt.Sprintf("\n  %d encrypted secret file(s)", result.SecretFileCount)
 	}
 
-	message += "\n\n" + ui.Info.Sprint("Note:") + " This archive contains encrypted data only." +
-		"\n      Private keys are NOT included."
+	if result.Encrypted {
+		message += "\n\n" + ui.Info.Sprint("Note:") + " The whole archive is encrypted with your passphrase." +
+			"\n      Private keys are NOT included. Keep the passphrase safe;" +
+			"\n      the archive cannot be imported without it."
+	} else {
+		message += "\n\n" + ui.Info.Sprint("Note:") + " This archive contains encrypted data only." +
+			"\n      Private keys are NOT included."
+	}
 
 	return message
 }
+
+// readArchivePassphrase returns the passphrase for an encrypted archive.
+// KANUKA_ARCHIVE_PASSPHRASE is used if set; otherwise the user is prompted,
+// twice if confirm is true.
+func readArchivePassphrase(confirm bool) ([]byte, error) {
+	if env := os.Getenv(utils.ArchivePassphraseEnvVar); env != "" {
+		Logger.Debugf("Using archive passphrase from %s", utils.ArchivePassphraseEnvVar)
+		return []byte(env), nil
+	}
+
+	if !utils.IsTerminal() {
+		return nil, kerrors.ErrTTYRequired
+	}
+
+	passphrase, err := utils.ReadPassphrase("Enter archive passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+
+	if confirm {
+		again, err := utils.ReadPassphrase("Confirm archive passphrase: ")
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(passphrase, again) {
+			return nil, fmt.Errorf("passphrases do not match")
+		}
+	}
+
+	return passphrase, nil
+}
+
+// formatArchivePassphraseError formats a failure to read the archive passphrase.
+func formatArchivePassphraseError(err error) string {
+	if errors.Is(err, kerrors.ErrTTYRequired) {
+		return ui.Error.Sprint("✗") + " Cannot prompt for the archive passphrase: no terminal available" +
+			"\n" + ui.Info.Sprint("→") + " Set " + ui.Code.Sprint(utils.ArchivePassphraseEnvVar) + " to provide it non-interactively"
+	}
+	return ui.Error.Sprint("✗") + " Failed to read archive passphrase: " + err.Error()
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/utils"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	filesEncrypted  bool
+	filesJSONOutput bool
+	filesRecursive  bool
+	filesNameFilter string
+)
+
+func init() {
+	filesCmd.Flags().BoolVar(&filesEncrypted, "encrypted", false, "list the .kanuka files decrypt would act on")
+	filesCmd.Flags().BoolVar(&filesJSONOutput, "json", false, "output in JSON format")
+	filesCmd.Flags().BoolVar(&filesRecursive, "recursive", true, "search subdirectories for .env files, as encrypt --recursive does")
+	filesCmd.Flags().StringVar(&filesNameFilter, "name-filter", "", "only list .env files whose name matches this glob, as encrypt --name-filter does")
+}
+
+func resetFilesCommandState() {
+	filesEncrypted = false
+	filesJSONOutput = false
+	filesRecursive = true
+	filesNameFilter = ""
+}
+
+// filesJSONResult holds the JSON-serializable files result.
+type filesJSONResult struct {
+	Matched  []filesJSONEntry `json:"matched"`
+	Excluded []filesJSONEntry `json:"excluded"`
+}
+
+type filesJSONEntry struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+var filesCmd = &cobra.Command{
+	Use:   "files [files...]",
+	Short: "List the files encrypt or decrypt would act on",
+	Long: `Lists exactly the files that encrypt would act on, and explains why.
+
+Each matched file is annotated with the rule or pattern that matched it.
+Candidate files and directories that were skipped are listed separately
+with the reason they were excluded.
+
+Accepts the same files, directories, and glob patterns as encrypt. Use
+--encrypted to list the .kanuka files that decrypt would act on instead.
+
+Without file arguments, --recursive=false and --name-filter narrow the search
+exactly as they do for encrypt. If they aren't given, the values set for
+encrypt in .kanuka/settings.toml are used, so the list matches what a plain
+kanuka secrets encrypt would pick up.
+
+This command is read-only and does not require access to the project's secrets.
+
+Examples:
+  # Show which .env files encrypt would pick up
+  kanuka secrets files
+
+  # Show which .kanuka files decrypt would pick up
+  kanuka secrets files --encrypted
+
+  # Check what a glob pattern matches
+  kanuka secrets files "services/*/.env*"
+
+  # Check what encrypt would pick up in the project root only
+  kanuka secrets files --recursive=false --name-filter '.env.prod*'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		Logger.Infof("Starting files command")
+
+		spinner, cleanup := startReportSpinner("Discovering files...", verbose)
+		defer cleanup()
+
+		if len(args) == 0 && !filesEncrypted {
+			if err := applyEncryptDiscoverDefaults(cmd); err != nil {
+				if filesJSONOutput {
+					return reportJSONError(cmd, spinner, err)
+				}
+				return reportCommandError(cmd, spinner, err, formatFilesError(err))
+			}
+		}
+		if problem := validateFilesDiscoverFlags(args); problem != "" {
+			err := fmt.Errorf("%w: %s", kerrors.ErrInvalidArguments, problem)
+			if filesJSONOutput {
+				return reportJSONError(cmd, spinner, err)
+			}
+			return reportCommandError(cmd, spinner, err, ui.Error.Sprint("✗")+" "+problem)
+		}
+
+		result, err := workflows.Files(context.Background(), workflows.FilesOptions{
+			FilePatterns: args,
+			Encrypted:    filesEncrypted,
+			Discover: secrets.DiscoverOptions{
+				NoRecurse:  !filesRecursive,
+				NameFilter: filesNameFilter,
+			},
+		})
+		if err != nil {
+			if filesJSONOutput {
+				return reportJSONError(cmd, spinner, err)
+			}
+			return reportCommandError(cmd, spinner, err, formatFilesError(err))
+		}
+
+		spinner.FinalMSG = ""
+		if filesJSONOutput {
+			return outputFilesJSON(result)
+		}
+
+		printFilesResult(result)
+		return nil
+	},
+}
+
+// applyEncryptDiscoverDefaults sets --recursive and --name-filter, unless
+// they were given, from the [encrypt] table of the project's settings.toml,
+// since files lists what encrypt would find.
+func applyEncryptDiscoverDefaults(cmd *cobra.Command) error {
+	projectPath, err := utils.FindProjectKanukaRoot()
+	if err != nil || projectPath == "" {
+		// Not in a project; Files reports that.
+		return nil
+	}
+
+	defaults, err := configs.LoadFlagDefaults(projectPath)
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{"recursive", "name-filter"} {
+		values, ok := defaults.Commands["encrypt"][name]
+		if !ok {
+			continue
+		}
+		if err := setFlagDefault(cmd.Flags().Lookup(name), values); err != nil {
+			return fmt.Errorf("%w: %v", kerrors.ErrInvalidArguments, err)
+		}
+	}
+	return nil
+}
+
+// validateFilesDiscoverFlags checks that --recursive=false and --name-filter
+// are only used when listing the .env files encrypt would discover itself,
+// and that the filter is a valid glob. It returns a description of the
+// problem, or "" if the flags are valid.
+func validateFilesDiscoverFlags(args []string) string {
+	if filesRecursive && filesNameFilter == "" {
+		return ""
+	}
+	switch {
+	case len(args) > 0:
+		return "--recursive=false and --name-filter can't be combined with file arguments"
+	case filesEncrypted:
+		return "--recursive=false and --name-filter can't be combined with --encrypted"
+	}
+	if filesNameFilter != "" {
+		if err := secrets.ValidateNameFilter(filesNameFilter); err != nil {
+			return fmt.Sprintf("--name-filter %q is not a valid glob", filesNameFilter)
+		}
+	}
+	return ""
+}
+
+// formatFilesError formats workflow errors into user-friendly messages.
+func formatFilesError(err error) string {
+	switch {
+	case errors.Is(err, kerrors.ErrProjectNotInitialized):
+		return ui.Error.Sprint("✗") + " Kānuka has not been initialized\n" +
+			ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets init") + " first"
+
+	default:
+		return ui.Error.Sprint("✗") + " Failed to discover files\n" +
+			ui.Error.Sprint("Error: ") + err.Error()
+	}
+}
+
+// outputFilesJSON outputs the result as JSON.
+func outputFilesJSON(result *workflows.FilesResult) error {
+	jsonResult := filesJSONResult{
+		Matched:  make([]filesJSONEntry, 0, len(result.Matched)),
+		Excluded: make([]filesJSONEntry, 0, len(result.Excluded)),
+	}
+	for _, m := range result.Matched {
+		jsonResult.Matched = append(jsonResult.Matched, filesJSONEntry{Path: m.Path, Reason: m.Reason})
+	}
+	for _, m := range result.Excluded {
+		jsonResult.Excluded = append(jsonResult.Excluded, filesJSONEntry{Path: m.Path, Reason: m.Reason})
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(jsonResult)
+}
+
+// printFilesResult prints matched and excluded files in a human-readable format.
+func printFilesResult(result *workflows.FilesResult) {
+	action := "encrypt"
+	if filesEncrypted {
+		action = "decrypt"
+	}
+
+	if len(result.Matched) == 0 {
+		fmt.Println(ui.Warning.Sprint("⚠") + " No files would be acted on by " + ui.Code.Sprint("kanuka secrets "+action))
+	} else {
+		fmt.Printf("Files %s would act on (%d):\n", ui.Code.Sprint(action), len(result.Matched))
+		for _, m := range result.Matched {
+			fmt.Printf("  %s %s %s\n", ui.Success.Sprint("✓"), ui.Path.Sprint(m.Path), ui.Muted.Sprint(m.Reason))
+		}
+	}
+
+	if len(result.Excluded) > 0 {
+		fmt.Println()
+		fmt.Printf("Excluded (%d):\n", len(result.Excluded))
+		for _, m := range result.Excluded {
+			fmt.Printf("  %s %s %s\n", ui.Muted.Sprint("-"), ui.Path.Sprint(m.Path), ui.Muted.Sprint(m.Reason))
+		}
+	}
+}
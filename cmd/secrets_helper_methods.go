@@ -5,9 +5,12 @@ import (
 	"io"
 	"log"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/PolarWolf314/kanuka/internal/secrets"
 	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/utils"
 	"github.com/briandowns/spinner"
 )
 
@@ -18,10 +21,33 @@ import (
 // IMPORTANT: spinner.FinalMSG values do NOT need trailing newlines. The cleanup function
 // automatically calls ui.EnsureNewline() on the final message before printing it.
 // This ensures consistent output formatting across all commands.
+//
+// Under --output json the spinner never draws and its final message is
+// discarded, so stdout holds only the command's JSON result. When stdout
+// isn't a terminal the spinner doesn't animate either; see runSpinner.
 func startSpinner(message string, verbose bool) (*spinner.Spinner, func()) {
+	return startSpinnerWithProgress(message, message, verbose)
+}
+
+// startReportSpinner is startSpinner for commands whose output is a report
+// or JSON that may be parsed, such as status and log. When stdout isn't a
+// terminal it prints no progress line, so the output holds only the report.
+func startReportSpinner(message string, verbose bool) (*spinner.Spinner, func()) {
+	return startSpinnerWithProgress(message, "", verbose)
+}
+
+// startSpinnerWithProgress starts a spinner showing message. When stdout
+// isn't a terminal, progress is printed in its place unless it is empty.
+func startSpinnerWithProgress(message, progress string, verbose bool) (*spinner.Spinner, func()) {
 	Logger.Debugf("Starting spinner with message: %s", message)
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	if jsonOutput() {
+		// Writing to io.Discard keeps later Restart calls silent too.
+		s.Writer = io.Discard
+		return s, func() { s.Stop() }
+	}
 	s.Suffix = " " + message
+	fitSpinnerToTerminal(s, message)
 
 	err := s.Color("cyan")
 	if err != nil {
@@ -31,7 +57,7 @@ func startSpinner(message string, verbose bool) (*spinner.Spinner, func()) {
 
 	if !verbose && !debug {
 		Logger.Debugf("Starting spinner in non-verbose mode")
-		s.Start()
+		runSpinner(s, progress)
 		// Ensure log output is discarded unless in verbose mode.
 		log.SetOutput(io.Discard)
 	} else {
@@ -75,14 +101,29 @@ func startSpinner(message string, verbose bool) (*spinner.Spinner, func()) {
 // automatically calls ui.EnsureNewline() on the final message before printing it.
 // This ensures consistent output formatting across all commands.
 func startSpinnerWithFlags(message string, verbose, debugFlag bool) (*spinner.Spinner, func()) {
+	return startSpinnerWithFlagsAndProgress(message, message, verbose, debugFlag)
+}
+
+// startReportSpinnerWithFlags is startSpinnerWithFlags for commands whose
+// output is a report or JSON that may be parsed, such as config show. See
+// startReportSpinner.
+func startReportSpinnerWithFlags(message string, verbose, debugFlag bool) (*spinner.Spinner, func()) {
+	return startSpinnerWithFlagsAndProgress(message, "", verbose, debugFlag)
+}
+
+// startSpinnerWithFlagsAndProgress starts a spinner showing message. When
+// stdout isn't a terminal, progress is printed in its place unless it is
+// empty.
+func startSpinnerWithFlagsAndProgress(message, progress string, verbose, debugFlag bool) (*spinner.Spinner, func()) {
 	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
 	s.Suffix = " " + message
+	fitSpinnerToTerminal(s, message)
 
 	// Ignore color errors - continue without colored spinner if it fails.
 	_ = s.Color("cyan")
 
 	if !verbose && !debugFlag {
-		s.Start()
+		runSpinner(s, progress)
 		// Ensure log output is discarded unless in verbose mode.
 		log.SetOutput(io.Discard)
 	}
@@ -114,3 +155,69 @@ func startSpinnerWithFlags(message string, verbose, debugFlag bool) (*spinner.Sp
 
 	return s, cleanup
 }
+
+// runSpinner starts s when stdout is a terminal. Anywhere else, such as a CI
+// log or a pipe, every frame would leave a carriage return behind, so it
+// prints progress once to stderr instead, if given, and disables s, which
+// also keeps a later Restart from drawing. The final message is printed as
+// usual.
+func runSpinner(s *spinner.Spinner, progress string) {
+	if utils.IsStdoutTerminal() {
+		s.Start()
+		return
+	}
+	s.Disable()
+	if progress != "" {
+		fmt.Fprintln(os.Stderr, progress)
+	}
+}
+
+// fitSpinnerToTerminal truncates the spinner message to the terminal width
+// before every frame. The width is re-read each frame, so resizing the
+// terminal while a command runs re-flows the line instead of wrapping it,
+// which would leave stale copies behind when the spinner redraws.
+func fitSpinnerToTerminal(s *spinner.Spinner, message string) {
+	full := " " + message
+	s.PreUpdate = func(s *spinner.Spinner) {
+		// One column for the spinner character and one spare so the cursor
+		// never lands past the last column.
+		s.Suffix = ui.Truncate(full, ui.Width()-2)
+	}
+}
+
+// formatInsecureKeyPermissionsError explains a private key rejected by --strict-perms.
+func formatInsecureKeyPermissionsError(err error) string {
+	return ui.Error.Sprint("✗") + " Your private key file is readable by other users" +
+		"\n" + err.Error() +
+		"\n\n" + ui.Info.Sprint("→") + " Strict key permissions are enabled by " + ui.Flag.Sprint("--strict-perms") +
+		" or " + ui.Code.Sprint("strict_key_permissions") + " in your user config"
+}
+
+// formatCreatedUpdated lists created and updated files under separate
+// headings, leaving out a heading with no files.
+func formatCreatedUpdated(created, updated []string) string {
+	var msg string
+	if len(created) > 0 {
+		msg += "\nCreated:" + utils.FormatPaths(created)
+	}
+	if len(updated) > 0 {
+		msg += "\nUpdated:" + utils.FormatPaths(updated)
+	}
+	return msg
+}
+
+// formatInvalidPrivateKeyError explains why a private key couldn't be
+// loaded. A passphrase-protected key piped on stdin needs a terminal to
+// prompt for the passphrase on, which CI jobs usually don't have.
+func formatInvalidPrivateKeyError(err error, fromStdin bool) string {
+	message := ui.Error.Sprint("✗") + " Failed to parse private key"
+	if fromStdin {
+		message += " from stdin"
+	}
+	message += "\n" + ui.Error.Sprint("Error: ") + err.Error()
+
+	if strings.Contains(err.Error(), secrets.ErrPassphraseRequired.Error()) {
+		return message + "\n" + ui.Info.Sprint("→") + " Run from a terminal to enter the passphrase, or pipe a key without one"
+	}
+	return message + "\n" + ui.Info.Sprint("→") + " Ensure your private key is in valid format (PEM or OpenSSH)"
+}
@@ -10,6 +10,7 @@ import (
 
 	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/utils"
 	"github.com/PolarWolf314/kanuka/internal/workflows"
 
 	"github.com/spf13/cobra"
@@ -19,12 +20,16 @@ var (
 	importMergeFlag   bool
 	importReplaceFlag bool
 	importDryRunFlag  bool
+	importOnly        []string
+	importExclude     []string
 )
 
 func init() {
 	importCmd.Flags().BoolVar(&importMergeFlag, "merge", false, "merge with existing files (add new, keep existing)")
 	importCmd.Flags().BoolVar(&importReplaceFlag, "replace", false, "replace existing .kanuka directory with backup")
 	importCmd.Flags().BoolVar(&importDryRunFlag, "dry-run", false, "show what would be imported without making changes")
+	importCmd.Flags().StringArrayVar(&importOnly, "only", nil, "only import secret files matching this glob (repeatable)")
+	importCmd.Flags().StringArrayVar(&importExclude, "exclude", nil, "skip secret files matching this glob (repeatable)")
 }
 
 // resetImportCommandState resets the import command's global state for testing.
@@ -32,6 +37,8 @@ func resetImportCommandState() {
 	importMergeFlag = false
 	importReplaceFlag = false
 	importDryRunFlag = false
+	importOnly = nil
+	importExclude = nil
 }
 
 var importCmd = &cobra.Command{
@@ -46,6 +53,14 @@ Import modes:
 If neither --merge nor --replace is specified and a .kanuka directory
 already exists, you will be prompted to choose.
 
+Use --only and --exclude to import some secret files and not others. Patterns
+are globs matched against the paths stored in the archive, and a pattern that
+matches a directory covers everything below it. Both can be repeated, and a
+file matching both is skipped. The .kanuka directory is always imported.
+
+Archives created with export --encrypt-archive are detected automatically
+and you are prompted for the passphrase (or set KANUKA_ARCHIVE_PASSPHRASE).
+
 The archive should contain:
   - .kanuka/config.toml (project configuration)
   - .kanuka/public_keys/*.pub (user public keys)
@@ -63,32 +78,42 @@ Examples:
   kanuka secrets import backup.tar.gz --replace
 
   # Preview what would happen
-  kanuka secrets import backup.tar.gz --dry-run`,
+  kanuka secrets import backup.tar.gz --dry-run
+
+  # Import only the api service's secrets, except its test file
+  kanuka secrets import backup.tar.gz --merge --only "services/api/**" --exclude "**/.env.test.kanuka"`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		Logger.Infof("Starting import command")
 		archivePath := args[0]
 
+		// Ask for the passphrase of encrypted archives before the spinner starts.
+		// Errors opening the archive are reported by the pre-check below.
+		var passphrase []byte
+		var passphraseErr error
+		if encrypted, err := workflows.IsEncryptedArchive(archivePath); err == nil && encrypted {
+			Logger.Debugf("Archive %s is encrypted", archivePath)
+			passphrase, passphraseErr = readArchivePassphrase(false)
+		}
+
 		spinner, cleanup := startSpinner("Importing secrets...", verbose)
 		defer cleanup()
 
+		if passphraseErr != nil {
+			Logger.Errorf("Failed to read archive passphrase: %v", passphraseErr)
+			return reportCommandError(cmd, spinner, passphraseErr, formatArchivePassphraseError(passphraseErr))
+		}
+
 		// Validate flags - can't use both merge and replace.
 		if importMergeFlag && importReplaceFlag {
-			spinner.FinalMSG = ui.Error.Sprint("✗") + " Cannot use both --merge and --replace flags." +
-				"\n\n" + ui.Info.Sprint("→") + " Use --merge to add new files while keeping existing files," +
-				"\n   or use --replace to delete existing files and use only backup."
-			return nil
+			return reportCommandError(cmd, spinner, kerrors.ErrArchiveConflictingFlags, formatImportError(kerrors.ErrArchiveConflictingFlags, archivePath))
 		}
 		defer cleanup()
 
 		// Pre-check the archive.
-		preCheck, err := workflows.ImportPreCheck(context.Background(), archivePath)
+		preCheck, err := workflows.ImportPreCheck(context.Background(), archivePath, passphrase)
 		if err != nil {
-			spinner.FinalMSG = formatImportError(err, archivePath)
-			if isImportUnexpectedError(err) {
-				return err
-			}
-			return nil
+			return reportCommandError(cmd, spinner, err, formatImportError(err, archivePath))
 		}
 
 		// Determine import mode.
@@ -122,12 +147,14 @@ Examples:
 			ProjectPath: preCheck.ProjectPath,
 			Mode:        mode,
 			DryRun:      importDryRunFlag,
+			Passphrase:  passphrase,
+			Only:        importOnly,
+			Exclude:     importExclude,
 		}
 
 		result, err := workflows.Import(context.Background(), opts)
 		if err != nil {
-			spinner.FinalMSG = formatImportError(err, archivePath)
-			return err
+			return reportCommandError(cmd, spinner, err, formatImportError(err, archivePath))
 		}
 
 		// Build summary message.
@@ -152,6 +179,16 @@ Examples:
 		} else {
 			finalMessage += fmt.Sprintf("  Extracted: %d", result.FilesReplaced) + "\n"
 		}
+		if len(importOnly) > 0 || len(importExclude) > 0 {
+			finalMessage += fmt.Sprintf("  Filtered out: %d", result.FilesFiltered) + "\n"
+		}
+
+		if result.DryRun && len(result.Files) > 0 {
+			finalMessage += "\nWould import:\n"
+			for _, f := range result.Files {
+				finalMessage += "  " + ui.Path.Sprint(f) + "\n"
+			}
+		}
 
 		if !result.DryRun {
 			finalMessage += "\n" + ui.Info.Sprint("Note:") + " You may need to run " + ui.Code.Sprint("kanuka secrets decrypt") + " to decrypt secrets."
@@ -173,7 +210,37 @@ func formatImportError(err error, archivePath string) string {
 			"\n\n" + ui.Info.Sprint("→") + " The file is not a valid gzip archive. Ensure it was created with:" +
 			"\n   " + ui.Code.Sprint("kanuka secrets export")
 
-	case errors.Is(err, kerrors.ErrInvalidArchive):
+	case errors.Is(err, kerrors.ErrArchivePassphraseRequired):
+		return ui.Error.Sprint("✗") + " Archive is encrypted: " + ui.Path.Sprint(archivePath) +
+			"\n" + ui.Info.Sprint("→") + " Set " + ui.Code.Sprint(utils.ArchivePassphraseEnvVar) + " or run interactively to enter the passphrase"
+
+	case errors.Is(err, kerrors.ErrArchiveDecryptFailed):
+		return ui.Error.Sprint("✗") + " Failed to decrypt archive: " + ui.Path.Sprint(archivePath) +
+			"\n" + ui.Info.Sprint("→") + " Check the passphrase and that the archive is not corrupted"
+
+	case errors.Is(err, kerrors.ErrInvalidArguments):
+		return ui.Error.Sprint("✗") + " Invalid " + ui.Flag.Sprint("--only") + " or " + ui.Flag.Sprint("--exclude") + " pattern" +
+			"\n" + ui.Error.Sprint("Error: ") + err.Error()
+
+	case errors.Is(err, kerrors.ErrArchiveConflictingFlags):
+		return ui.Error.Sprint("✗") + " Cannot use both --merge and --replace flags." +
+			"\n\n" + ui.Info.Sprint("→") + " Use --merge to add new files while keeping existing files," +
+			"\n   or use --replace to delete existing files and use only backup."
+
+	case errors.Is(err, kerrors.ErrArchiveMissingConfig):
+		return ui.Error.Sprint("✗") + " Invalid archive: " + ui.Path.Sprint(archivePath) + " has no " + ui.Path.Sprint(".kanuka/config.toml") +
+			"\n" + ui.Info.Sprint("→") + " Ensure it was created with " + ui.Code.Sprint("kanuka secrets export")
+
+	case errors.Is(err, kerrors.ErrArchiveEmptyConfig):
+		return ui.Error.Sprint("✗") + " Invalid archive: " + ui.Path.Sprint(".kanuka/config.toml") + " is empty" +
+			"\n" + ui.Info.Sprint("→") + " Nothing was imported"
+
+	case errors.Is(err, kerrors.ErrInvalidProjectConfig):
+		return ui.Error.Sprint("✗") + " Invalid archive: " + ui.Path.Sprint(".kanuka/config.toml") + " is invalid" +
+			"\n" + ui.Error.Sprint("Error: ") + err.Error() +
+			"\n" + ui.Info.Sprint("→") + " Nothing was imported"
+
+	case errors.Is(err, kerrors.ErrArchiveInvalid):
 		return ui.Error.Sprint("✗") + " Invalid archive structure" +
 			"\n" + ui.Error.Sprint("Error: ") + err.Error()
 
@@ -183,22 +250,6 @@ func formatImportError(err error, archivePath string) string {
 	}
 }
 
-// isImportUnexpectedError returns true if the error is unexpected and should cause a non-zero exit.
-func isImportUnexpectedError(err error) bool {
-	expectedErrors := []error{
-		kerrors.ErrFileNotFound,
-		kerrors.ErrInvalidFileType,
-		kerrors.ErrInvalidArchive,
-	}
-
-	for _, expected := range expectedErrors {
-		if errors.Is(err, expected) {
-			return false
-		}
-	}
-	return true
-}
-
 // promptForImportMode asks the user how to handle existing .kanuka directory.
 func promptForImportMode() (workflows.ImportMode, bool) {
 	reader := bufio.NewReader(os.Stdin)
@@ -13,29 +13,49 @@ import (
 	"github.com/PolarWolf314/kanuka/internal/ui"
 	"github.com/PolarWolf314/kanuka/internal/workflows"
 
+	"github.com/briandowns/spinner"
 	"github.com/spf13/cobra"
 )
 
 var (
 	initYes         bool
 	initProjectName string
+	initBare        bool
 )
 
 func init() {
 	initCmd.Flags().BoolVarP(&initYes, "yes", "y", false, "non-interactive mode (fail if user config is incomplete)")
 	initCmd.Flags().StringVarP(&initProjectName, "name", "n", "", "project name (defaults to directory name)")
+	initCmd.Flags().BoolVar(&initBare, "bare", false, "create the project without generating or registering a key for you")
 }
 
 // resetInitCommandState resets the init command's global state for testing.
 func resetInitCommandState() {
 	initYes = false
 	initProjectName = ""
+	initBare = false
 }
 
 var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initializes the secrets store",
-	RunE:  runInit,
+	Long: `Initializes the secrets store in the current directory, generating your
+key pair and the project's symmetric key and registering you as its first
+user.
+
+Use --bare to create only the .kanuka structure and project config, for
+example when provisioning a project in CI. No key is generated for you and
+your user config isn't needed. The project has no symmetric key until its
+first user runs 'kanuka secrets create' and then registers themselves with
+'kanuka secrets register --user <their email>'.
+
+Examples:
+  # Initialize a project and become its first user
+  kanuka secrets init
+
+  # Provision a project without any user keys
+  kanuka secrets init --bare --name payments`,
+	RunE: runInit,
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
@@ -49,8 +69,11 @@ func runInit(cmd *cobra.Command, args []string) error {
 		return Logger.ErrorfAndReturn("Failed to check if project kanuka settings exists: %v", err)
 	}
 	if kanukaExists {
-		spinner.FinalMSG = formatInitError(kerrors.ErrProjectAlreadyInitialized)
-		return nil
+		return reportCommandError(cmd, spinner, kerrors.ErrProjectAlreadyInitialized, formatInitError(kerrors.ErrProjectAlreadyInitialized))
+	}
+
+	if initBare {
+		return runBareInit(cmd, spinner)
 	}
 
 	Logger.Debugf("Ensuring user settings")
@@ -68,10 +91,10 @@ func runInit(cmd *cobra.Command, args []string) error {
 	if !isComplete {
 		Logger.Infof("User config is incomplete, need to run setup")
 
-		if initYes {
-			spinner.FinalMSG = ui.Error.Sprint("✗") + " User configuration is incomplete" +
-				"\n" + ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka config init") + " first to set up your identity"
-			return fmt.Errorf("user configuration required: run 'kanuka config init' first")
+		if initYes || jsonOutput() {
+			err := fmt.Errorf("user configuration required: run 'kanuka config init' first")
+			return reportCommandError(cmd, spinner, err, ui.Error.Sprint("✗")+" User configuration is incomplete"+
+				"\n"+ui.Info.Sprint("→")+" Run "+ui.Code.Sprint("kanuka config init")+" first to set up your identity")
 		}
 
 		spinner.Stop()
@@ -105,18 +128,17 @@ func runInit(cmd *cobra.Command, args []string) error {
 	result, err := workflows.Init(cmd.Context(), opts)
 	if err != nil {
 		Logger.Errorf("Init workflow failed: %v", err)
-		spinner.FinalMSG = formatInitError(err)
+		err = reportCommandError(cmd, spinner, err, formatInitError(err))
 		spinner.Stop()
-		// Return error for unexpected failures so the command exits with non-zero status.
-		// Only ErrProjectAlreadyInitialized is an "expected" error that shouldn't fail.
-		if !errors.Is(err, kerrors.ErrProjectAlreadyInitialized) {
-			return err
-		}
-		return nil
+		return err
 	}
 
 	Logger.Infof("Init command completed successfully")
 
+	if jsonOutput() {
+		return printJSONResult(result)
+	}
+
 	spinner.Stop()
 	Logger.WarnfUser("Remember to never commit .env files to version control - only commit .kanuka files")
 	spinner.Restart()
@@ -129,7 +151,37 @@ func runInit(cmd *cobra.Command, args []string) error {
 		"\n  2. Initialize separate .kanuka stores in each service:" +
 		"\n     " + ui.Code.Sprint("cd services/api && kanuka secrets init")
 
-	_ = result // result contains useful info for future enhancements
+	return nil
+}
+
+// runBareInit creates the project without a key for the current user, so
+// it needs no user config.
+func runBareInit(cmd *cobra.Command, s *spinner.Spinner) error {
+	projectName, err := resolveProjectName(s)
+	if err != nil {
+		return err
+	}
+
+	result, err := workflows.Init(cmd.Context(), workflows.InitOptions{
+		ProjectName: projectName,
+		Bare:        true,
+		Verbose:     verbose,
+	})
+	if err != nil {
+		Logger.Errorf("Init workflow failed: %v", err)
+		return reportCommandError(cmd, s, err, formatInitError(err))
+	}
+
+	Logger.Infof("Bare init command completed successfully")
+
+	if jsonOutput() {
+		return printJSONResult(result)
+	}
+
+	s.FinalMSG = ui.Success.Sprint("✓") + " Kānuka initialized without any user keys" +
+		"\n\n" + ui.Info.Sprint("→") + " The first user should run " + ui.Code.Sprint("kanuka secrets create") +
+		" and then " + ui.Code.Sprint("kanuka secrets register --user <their email>") +
+		"\n  to generate the project's encryption key"
 	return nil
 }
 
@@ -151,7 +203,7 @@ func resolveProjectName(spinner interface {
 		return projectName, nil
 	}
 
-	if initYes {
+	if initYes || jsonOutput() {
 		Logger.Debugf("Using default project name (non-interactive): %s", defaultProjectName)
 		return defaultProjectName, nil
 	}
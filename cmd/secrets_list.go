@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+
+	"github.com/spf13/cobra"
+)
+
+var listJSONOutput bool
+
+func init() {
+	listCmd.Flags().BoolVar(&listJSONOutput, "json", false, "output in JSON format (same as --output json)")
+}
+
+func resetListCommandState() {
+	listJSONOutput = false
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List registered users and their devices",
+	Long: `Lists the users registered in this project, grouped by email, with each
+of their devices, when it was registered, and the fingerprint of its public
+key. Compare fingerprints with their owners to check that nobody's public key
+has been swapped.
+
+This only reads .kanuka/config.toml and the public keys, so it works without
+access to the project's secrets. To see which users can actually decrypt, use
+'kanuka secrets access'.
+
+Use --json for machine-readable output.
+
+Examples:
+  # List registered users and devices
+  kanuka secrets list
+
+  # List as JSON
+  kanuka secrets list --json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if listJSONOutput {
+			outputFormat = outputFormatJSON
+		}
+
+		Logger.Infof("Starting list command")
+		spinner, cleanup := startReportSpinner("Loading registered users...", verbose)
+		defer cleanup()
+
+		result, err := workflows.ListUsers(cmd.Context())
+		if err != nil {
+			Logger.Errorf("List workflow failed: %v", err)
+			return reportCommandError(cmd, spinner, err, formatListError(err))
+		}
+
+		Logger.Infof("List command completed: %d users", len(result.Users))
+
+		if jsonOutput() {
+			return printJSONResult(result)
+		}
+
+		if len(result.Users) == 0 {
+			spinner.FinalMSG = ui.Warning.Sprint("⚠") + " No users registered in this project"
+			return nil
+		}
+
+		spinner.FinalMSG = ""
+		spinner.Stop()
+		printUserList(result)
+		return nil
+	},
+}
+
+// formatListError formats workflow errors into user-friendly messages.
+func formatListError(err error) string {
+	switch {
+	case errors.Is(err, kerrors.ErrProjectNotInitialized):
+		return ui.Error.Sprint("✗") + " Kānuka has not been initialized\n" +
+			ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets init") + " first"
+
+	case errors.Is(err, kerrors.ErrInvalidProjectConfig):
+		return ui.Error.Sprint("✗") + " Failed to load project configuration.\n\n" +
+			ui.Info.Sprint("→") + " The .kanuka/config.toml file is not valid TOML.\n\n" +
+			"   To fix this issue:\n" +
+			"   1. Restore the file from git: " + ui.Code.Sprint("git checkout .kanuka/config.toml") + "\n" +
+			"   2. Or contact your project administrator for assistance"
+
+	default:
+		return ui.Error.Sprint("✗") + " Failed to list users\n" +
+			ui.Error.Sprint("Error: ") + err.Error()
+	}
+}
+
+// printUserList prints users and their devices as a table, one row per device.
+func printUserList(result *workflows.ListUsersResult) {
+	fmt.Printf("Project: %s\n\n", ui.Highlight.Sprint(result.ProjectName))
+
+	emailWidth := len("EMAIL")
+	deviceWidth := len("DEVICE")
+	// Registration times are always formatted to the same width.
+	registeredWidth := len("2006-01-02 15:04")
+	deviceCount := 0
+	for _, user := range result.Users {
+		emailWidth = max(emailWidth, len(user.Email))
+		for _, device := range user.Devices {
+			deviceWidth = max(deviceWidth, len(deviceDisplayName(device)))
+			deviceCount++
+		}
+	}
+
+	fmt.Printf("  %-*s  %-*s  %-*s  %s\n", emailWidth, "EMAIL", deviceWidth, "DEVICE", registeredWidth, "REGISTERED", "FINGERPRINT")
+
+	for _, user := range result.Users {
+		for i, device := range user.Devices {
+			// Color codes would throw off %-*s, so pad the plain text instead.
+			email := strings.Repeat(" ", emailWidth)
+			if i == 0 {
+				email = ui.Highlight.Sprint(user.Email) + strings.Repeat(" ", emailWidth-len(user.Email))
+			}
+
+			name := deviceDisplayName(device)
+			padding := strings.Repeat(" ", deviceWidth-len(name))
+			if device.Name == "" {
+				name = ui.Muted.Sprint(name)
+			}
+
+			registered := "unknown"
+			if !device.CreatedAt.IsZero() {
+				registered = device.CreatedAt.Format("2006-01-02 15:04")
+			}
+			registered += strings.Repeat(" ", registeredWidth-len(registered))
+
+			fingerprint := ui.Muted.Sprint("-")
+			if device.Fingerprint != "" {
+				fingerprint = device.Fingerprint
+			}
+
+			fmt.Printf("  %s  %s%s  %s  %s\n", email, name, padding, ui.Muted.Sprint(registered), fingerprint)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Total: %d user(s), %d device(s)\n", len(result.Users), deviceCount)
+}
+
+// deviceDisplayName returns the device name, or a placeholder for unnamed devices.
+func deviceDisplayName(device workflows.ListedDevice) string {
+	if device.Name == "" {
+		return "(unnamed)"
+	}
+	return device.Name
+}
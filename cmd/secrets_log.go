@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/PolarWolf314/kanuka/internal/audit"
 	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
@@ -71,7 +72,7 @@ Examples:
 func runLog(cmd *cobra.Command, args []string) error {
 	Logger.Infof("Starting log command")
 
-	spinner, cleanup := startSpinner("Loading audit log...", verbose)
+	spinner, cleanup := startReportSpinner("Loading audit log...", verbose)
 	defer cleanup()
 
 	opts := workflows.LogOptions{
@@ -84,13 +85,13 @@ func runLog(cmd *cobra.Command, args []string) error {
 	}
 
 	result, err := workflows.Log(context.Background(), opts)
-	if err != nil {
-		spinner.FinalMSG = formatLogError(err)
-		if isLogUnexpectedError(err) {
-			return err
-		}
+	if errors.Is(err, kerrors.ErrNoFilesFound) {
+		spinner.FinalMSG = ui.Info.Sprint("ℹ") + " No audit log found. Operations will be logged after running any secrets command.\n"
 		return nil
 	}
+	if err != nil {
+		return reportCommandError(cmd, spinner, err, formatLogError(err))
+	}
 
 	Logger.Debugf("Parsed %d entries from audit log", result.TotalEntriesBeforeFilter)
 	Logger.Debugf("After filtering: %d entries", len(result.Entries))
@@ -115,10 +116,14 @@ func runLog(cmd *cobra.Command, args []string) error {
 
 	if logOneline {
 		outputLogOneline(result.Entries)
-		return nil
+	} else {
+		outputLogDefault(result.Entries)
 	}
 
-	outputLogDefault(result.Entries)
+	if result.FutureDatedEntries > 0 {
+		fmt.Println()
+		fmt.Println(ui.Warning.Sprint("⚠") + fmt.Sprintf(" %d entries are dated in the future and were likely written by a machine with a wrong clock", result.FutureDatedEntries))
+	}
 	return nil
 }
 
@@ -129,9 +134,6 @@ func formatLogError(err error) string {
 		return ui.Error.Sprint("✗") + " Kānuka has not been initialized\n" +
 			ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets init") + " first"
 
-	case errors.Is(err, kerrors.ErrNoFilesFound):
-		return ui.Info.Sprint("ℹ") + " No audit log found. Operations will be logged after running any secrets command.\n"
-
 	case errors.Is(err, kerrors.ErrInvalidDateFormat):
 		return ui.Error.Sprint("✗") + " " + err.Error()
 
@@ -140,18 +142,6 @@ func formatLogError(err error) string {
 	}
 }
 
-// isLogUnexpectedError returns true if the error is unexpected and should cause a non-zero exit.
-func isLogUnexpectedError(err error) bool {
-	switch {
-	case errors.Is(err, kerrors.ErrProjectNotInitialized),
-		errors.Is(err, kerrors.ErrNoFilesFound),
-		errors.Is(err, kerrors.ErrInvalidDateFormat):
-		return false
-	default:
-		return true
-	}
-}
-
 func outputLogJSON(entries []audit.Entry) error {
 	data, err := json.MarshalIndent(entries, "", "  ")
 	if err != nil {
@@ -173,6 +163,9 @@ func outputLogDefault(entries []audit.Entry) {
 	for _, e := range entries {
 		datetime := workflows.FormatDateTime(e.Timestamp)
 		details := workflows.FormatDetails(e)
+		if e.Note != "" {
+			details = strings.TrimSpace(details + " " + ui.Muted.Sprint(e.Note))
+		}
 		fmt.Printf("%-19s  %-25s  %-10s  %s\n", datetime, e.User, e.Operation, details)
 	}
 }
@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	mergeConfigBase    string
+	mergeConfigOurs    string
+	mergeConfigTheirs  string
+	mergeConfigOutput  string
+	mergeConfigInstall bool
+
+	// mergeConfigExitFunc is the function called to exit with a specific code.
+	// Can be overridden for testing.
+	mergeConfigExitFunc = os.Exit
+)
+
+func init() {
+	mergeConfigCmd.Flags().StringVar(&mergeConfigBase, "base", "", "common ancestor config (enables three-way merge)")
+	mergeConfigCmd.Flags().StringVar(&mergeConfigOurs, "ours", "", "our version of the config")
+	mergeConfigCmd.Flags().StringVar(&mergeConfigTheirs, "theirs", "", "their version of the config")
+	mergeConfigCmd.Flags().StringVarP(&mergeConfigOutput, "output", "o", "", "write the merged config here (default: the --ours file)")
+	mergeConfigCmd.Flags().BoolVar(&mergeConfigInstall, "install", false, "register merge-config as the git merge driver for .kanuka/config.toml")
+}
+
+func resetMergeConfigCommandState() {
+	mergeConfigBase = ""
+	mergeConfigOurs = ""
+	mergeConfigTheirs = ""
+	mergeConfigOutput = ""
+	mergeConfigInstall = false
+	mergeConfigExitFunc = os.Exit
+}
+
+// SetMergeConfigExitFunc sets the exit function for testing purposes.
+func SetMergeConfigExitFunc(f func(int)) {
+	mergeConfigExitFunc = f
+}
+
+var mergeConfigCmd = &cobra.Command{
+	Use:   "merge-config",
+	Short: "Merge two versions of .kanuka/config.toml without losing access",
+	Long: `Merges two versions of the project config structurally.
+
+When two branches both register or revoke users, git produces conflicts in
+.kanuka/config.toml that are easy to resolve incorrectly, silently dropping
+someone's access. This command merges the users and devices tables per UUID:
+
+  - Users and devices added on either side are kept
+  - Users and devices removed on one side stay removed, unless the other
+    side changed them
+  - The same UUID changed differently on both sides is reported as a
+    conflict, and our version is kept
+
+The merged config is written to the --ours file (or --output). If conflicts
+are found, they are listed and the command exits with code 1.
+
+Use --install to register this command as a git merge driver, so git runs
+it automatically whenever .kanuka/config.toml needs merging.
+
+Examples:
+  # Register the git merge driver for this repository
+  kanuka secrets merge-config --install
+
+  # Merge two configs manually using their common ancestor
+  kanuka secrets merge-config --base base.toml --ours ours.toml --theirs theirs.toml
+
+  # Union two configs without an ancestor, writing to a new file
+  kanuka secrets merge-config --ours a.toml --theirs b.toml --output merged.toml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		Logger.Infof("Starting merge-config command")
+
+		if mergeConfigInstall {
+			return runInstallMergeDriver(cmd)
+		}
+
+		if mergeConfigOurs == "" || mergeConfigTheirs == "" {
+			finalMessage := ui.Error.Sprint("✗") + " Both " + ui.Flag.Sprint("--ours") + " and " + ui.Flag.Sprint("--theirs") + " are required"
+			return reportPrintedError(cmd, fmt.Errorf("%w: --ours and --theirs are required", kerrors.ErrInvalidArguments), finalMessage)
+		}
+
+		result, err := workflows.MergeConfig(context.Background(), workflows.MergeConfigOptions{
+			BasePath:   mergeConfigBase,
+			OursPath:   mergeConfigOurs,
+			TheirsPath: mergeConfigTheirs,
+			OutputPath: mergeConfigOutput,
+		})
+		if err != nil {
+			Logger.Errorf("Merge-config workflow failed: %v", err)
+			fmt.Println(formatMergeConfigError(err))
+			mergeConfigExitFunc(2)
+			return nil
+		}
+
+		if len(result.Conflicts) > 0 {
+			fmt.Println(ui.Error.Sprint("✗") + fmt.Sprintf(" Found %d conflict(s) while merging config", len(result.Conflicts)))
+			for _, c := range result.Conflicts {
+				fmt.Println("    - " + c.String())
+			}
+			fmt.Println(ui.Info.Sprint("→") + " Our version was kept for each conflict in " + ui.Path.Sprint(result.OutputPath) +
+				"\n   Review it, fix the entries above, and commit the result")
+			mergeConfigExitFunc(1)
+			return nil
+		}
+
+		fmt.Println(ui.Success.Sprint("✓") + " Merged config written to " + ui.Path.Sprint(result.OutputPath) +
+			fmt.Sprintf(" (%d users, %d devices)", result.UsersCount, result.DevicesCount))
+		return nil
+	},
+}
+
+// runInstallMergeDriver registers the git merge driver for the project config.
+func runInstallMergeDriver(cmd *cobra.Command) error {
+	spinner, cleanup := startSpinner("Installing git merge driver...", verbose)
+	defer cleanup()
+
+	result, err := workflows.InstallMergeDriver(context.Background())
+	if err != nil {
+		return reportCommandError(cmd, spinner, err, formatMergeConfigError(err))
+	}
+
+	msg := ui.Success.Sprint("✓") + " Registered " + ui.Highlight.Sprint(workflows.MergeDriverName) + " merge driver in your local git config"
+	if result.GitattributesUpdated {
+		msg += "\n" + ui.Info.Sprint("→") + " Added the merge attribute to " + ui.Path.Sprint(result.GitattributesPath) +
+			"\n   Commit this file so your team uses the driver too"
+	}
+	msg += "\n" + ui.Info.Sprint("→") + " Each clone must run " + ui.Code.Sprint("kanuka secrets merge-config --install") + " once"
+	spinner.FinalMSG = msg
+	return nil
+}
+
+// formatMergeConfigError formats workflow errors into user-friendly messages.
+func formatMergeConfigError(err error) string {
+	switch {
+	case errors.Is(err, kerrors.ErrProjectNotInitialized):
+		return ui.Error.Sprint("✗") + " Kānuka has not been initialized\n" +
+			ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets init") + " first"
+
+	case errors.Is(err, kerrors.ErrFileNotFound):
+		return ui.Error.Sprint("✗") + " Config file not found\n" +
+			ui.Error.Sprint("Error: ") + err.Error()
+
+	case errors.Is(err, kerrors.ErrUnsupportedConfigVersion):
+		return ui.Error.Sprint("✗") + " Config file was written by a newer version of Kānuka\n" +
+			ui.Info.Sprint("→") + " Upgrade Kānuka to merge it\n" +
+			ui.Error.Sprint("Error: ") + err.Error()
+
+	case errors.Is(err, kerrors.ErrInvalidProjectConfig):
+		return ui.Error.Sprint("✗") + " Config file is not valid TOML\n" +
+			ui.Info.Sprint("→") + " If it contains conflict markers, the merge driver is not installed. Run " +
+			ui.Code.Sprint("kanuka secrets merge-config --install") + "\n" +
+			ui.Error.Sprint("Error: ") + err.Error()
+
+	default:
+		return ui.Error.Sprint("✗") + " Failed to merge config\n" +
+			ui.Error.Sprint("Error: ") + err.Error()
+	}
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+
+	"github.com/briandowns/spinner"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// Output formats accepted by --output.
+const (
+	outputFormatText = "text"
+	outputFormatJSON = "json"
+)
+
+// jsonErrorReported is set once a command has written its error as JSON, so
+// PrintError doesn't write it a second time.
+var jsonErrorReported bool
+
+// jsonErrorResult is written to stderr when a command fails under --output json.
+type jsonErrorResult struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+}
+
+// validateOutputFormat returns an error if --output is not a supported format.
+func validateOutputFormat() error {
+	switch outputFormat {
+	case outputFormatText, outputFormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("%w: --output must be %q or %q, got %q",
+			kerrors.ErrInvalidArguments, outputFormatText, outputFormatJSON, outputFormat)
+	}
+}
+
+// jsonOutput reports whether --output json is set. Commands that support it
+// print only the JSON result and never prompt.
+func jsonOutput() bool {
+	return outputFormat == outputFormatJSON
+}
+
+// printJSONResult writes result to stdout as indented JSON.
+func printJSONResult(result any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// reportedError is a command failure that has already been shown to the
+// user. It wraps the original error so main can still map it to an exit code.
+type reportedError struct {
+	err error
+}
+
+func (e *reportedError) Error() string { return e.err.Error() }
+
+func (e *reportedError) Unwrap() error { return e.err }
+
+// reportCommandError shows a failed command's error and returns it for the
+// command to return, so the process exits with the error's exit code. Under
+// --output json it writes {"error": ..., "code": ...} to stderr; otherwise
+// message becomes the spinner's final message. Cobra's own error and usage
+// output are silenced, since the error has already been shown.
+func reportCommandError(c *cobra.Command, s *spinner.Spinner, err error, message string) error {
+	if jsonOutput() {
+		return reportJSONError(c, s, err)
+	}
+
+	c.SilenceErrors = true
+	c.SilenceUsage = true
+	s.FinalMSG = message
+	return &reportedError{err: err}
+}
+
+// reportJSONError is reportCommandError under --output json, for commands
+// that also take their own --json flag.
+func reportJSONError(c *cobra.Command, s *spinner.Spinner, err error) error {
+	c.SilenceErrors = true
+	c.SilenceUsage = true
+
+	s.FinalMSG = ""
+	printJSONError(err)
+	return &reportedError{err: err}
+}
+
+// reportPrintedError is reportCommandError for errors found before the
+// command starts its spinner, so message is printed directly.
+func reportPrintedError(c *cobra.Command, err error, message string) error {
+	c.SilenceErrors = true
+	c.SilenceUsage = true
+
+	if jsonOutput() {
+		printJSONError(err)
+	} else {
+		fmt.Println(message)
+	}
+	return &reportedError{err: err}
+}
+
+// printJSONError writes err to stderr as {"error": ..., "code": ...}.
+func printJSONError(err error) {
+	jsonErrorReported = true
+	data, marshalErr := json.Marshal(jsonErrorResult{Error: err.Error(), Code: kerrors.Code(err)})
+	if marshalErr != nil {
+		Logger.Errorf("Failed to marshal error: %v", marshalErr)
+		return
+	}
+	fmt.Fprintln(os.Stderr, string(data))
+}
+
+// PrintError prints an error returned from command execution, unless the
+// command already reported it. Under --output json it is written to stderr as
+// JSON; otherwise it is printed as plain text.
+func PrintError(err error) {
+	var reported *reportedError
+	if errors.As(err, &reported) {
+		return
+	}
+	if !jsonOutput() {
+		fmt.Println(err)
+		return
+	}
+	if !jsonErrorReported {
+		printJSONError(err)
+	}
+}
+
+// reserveStdout sends everything the command prints, including spinners, to
+// stderr, so stdout carries only data that can be piped to another tool. It
+// returns the real stdout and a function that restores it.
+func reserveStdout() (*os.File, func()) {
+	stdout, colorOutput := os.Stdout, color.Output
+	os.Stdout, color.Output = os.Stderr, color.Error
+	return stdout, func() {
+		os.Stdout, color.Output = stdout, colorOutput
+	}
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/utils"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+
+	"github.com/spf13/cobra"
+)
+
+var passphraseRemove bool
+
+func init() {
+	passphraseCmd.Flags().BoolVar(&passphraseRemove, "remove", false, "remove the passphrase from your private key")
+}
+
+// resetPassphraseCommandState resets the passphrase command's global state for testing.
+func resetPassphraseCommandState() {
+	passphraseRemove = false
+}
+
+var passphraseCmd = &cobra.Command{
+	Use:   "passphrase",
+	Short: "Add, change, or remove the passphrase on your private key",
+	Long: `Adds, changes, or removes the passphrase protecting your private key for
+this project.
+
+If the key already has a passphrase you are asked for it first. You are then
+asked for the new passphrase twice; leaving it empty removes the passphrase.
+Use --remove to strip the passphrase without being asked for a new one.
+
+The key is rewritten in OpenSSH format with 0600 permissions. It is written
+to a temporary file and renamed into place, so an interrupted run can't leave
+a corrupted key behind.
+
+This command needs an interactive terminal.
+
+Examples:
+  # Add or change the passphrase
+  kanuka secrets passphrase
+
+  # Remove the passphrase
+  kanuka secrets passphrase --remove`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		Logger.Infof("Starting passphrase command")
+
+		status, err := workflows.PassphraseStatus(context.Background())
+		if err != nil {
+			Logger.Errorf("Failed to read private key: %v", err)
+			return reportPrintedError(cmd, err, formatPassphraseError(err))
+		}
+		Logger.Debugf("Private key at %s, encrypted: %t", status.PrivateKeyPath, status.Encrypted)
+
+		if passphraseRemove && !status.Encrypted {
+			fmt.Println(ui.Info.Sprint("ℹ") + " Your private key has no passphrase")
+			return nil
+		}
+
+		if !utils.IsTerminal() {
+			return reportPrintedError(cmd, kerrors.ErrTTYRequired, formatPassphraseError(kerrors.ErrTTYRequired))
+		}
+
+		// Prompt before the spinner starts so it doesn't draw over the prompts.
+		var opts workflows.ChangePassphraseOptions
+		if status.Encrypted {
+			opts.CurrentPassphrase, err = utils.ReadPassphrase("Enter current passphrase: ")
+			if err != nil {
+				return reportPrintedError(cmd, err, formatPassphraseError(err))
+			}
+		}
+		if !passphraseRemove {
+			opts.NewPassphrase, err = readNewKeyPassphrase()
+			if err != nil {
+				return reportPrintedError(cmd, err, formatPassphraseError(err))
+			}
+			if len(opts.NewPassphrase) == 0 && !status.Encrypted {
+				fmt.Println(ui.Info.Sprint("ℹ") + " No passphrase entered; your private key is unchanged")
+				return nil
+			}
+		}
+
+		spinner, cleanup := startSpinner("Updating private key...", verbose)
+		defer cleanup()
+
+		result, err := workflows.ChangePassphrase(context.Background(), opts)
+		if err != nil {
+			Logger.Errorf("Failed to change passphrase: %v", err)
+			return reportCommandError(cmd, spinner, err, formatPassphraseError(err))
+		}
+
+		switch {
+		case result.Removed:
+			spinner.FinalMSG = ui.Success.Sprint("✓") + " Passphrase removed from " + ui.Path.Sprint(result.PrivateKeyPath)
+		case status.Encrypted:
+			spinner.FinalMSG = ui.Success.Sprint("✓") + " Passphrase changed for " + ui.Path.Sprint(result.PrivateKeyPath)
+		default:
+			spinner.FinalMSG = ui.Success.Sprint("✓") + " Passphrase added to " + ui.Path.Sprint(result.PrivateKeyPath)
+		}
+		return nil
+	},
+}
+
+// readNewKeyPassphrase prompts twice for a new passphrase. Empty means no passphrase.
+func readNewKeyPassphrase() ([]byte, error) {
+	passphrase, err := utils.ReadPassphrase("Enter new passphrase (empty for none): ")
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, nil
+	}
+
+	again, err := utils.ReadPassphrase("Confirm new passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(passphrase, again) {
+		return nil, fmt.Errorf("passphrases do not match")
+	}
+	return passphrase, nil
+}
+
+// formatPassphraseError formats workflow errors into user-friendly messages.
+func formatPassphraseError(err error) string {
+	switch {
+	case errors.Is(err, kerrors.ErrProjectNotInitialized):
+		return ui.Error.Sprint("✗") + " Kānuka has not been initialized\n" +
+			ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets init") + " first"
+
+	case errors.Is(err, kerrors.ErrPrivateKeyNotFound):
+		return ui.Error.Sprint("✗") + " Couldn't find your private key for this project\n" +
+			ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets create") + " to generate one"
+
+	case errors.Is(err, kerrors.ErrIncorrectPassphrase):
+		return ui.Error.Sprint("✗") + " Incorrect passphrase; your private key is unchanged"
+
+	case errors.Is(err, kerrors.ErrInvalidPrivateKey):
+		return ui.Error.Sprint("✗") + " Your private key could not be read\n" +
+			ui.Error.Sprint("Error: ") + err.Error()
+
+	case errors.Is(err, kerrors.ErrTTYRequired):
+		return ui.Error.Sprint("✗") + " Cannot prompt for a passphrase: no terminal available"
+
+	default:
+		return ui.Error.Sprint("✗") + " Failed to update passphrase: " + err.Error()
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneForce  bool
+	pruneDryRun bool
+)
+
+func init() {
+	pruneCmd.Flags().BoolVar(&pruneForce, "force", false, "skip confirmation prompt")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "show what would be removed without making changes")
+}
+
+func resetPruneCommandState() {
+	pruneForce = false
+	pruneDryRun = false
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove key files for users no longer in the project config",
+	Long: `Removes public keys and encrypted keys whose UUID is no longer in the
+project config.
+
+A file in .kanuka/public_keys/ or .kanuka/secrets/ is orphaned if its UUID
+has no entry in [users] or [devices]. This can happen if a revoke updated
+the config but failed to delete the user's files. Files for UUIDs that are
+still in the config are never removed.
+
+Unlike 'kanuka secrets clean', which removes encrypted keys that have no
+public key, prune compares the key files with the config.
+
+Use --dry-run to preview what would be removed.
+Use --force to skip the confirmation prompt.
+
+Examples:
+  # Preview which files would be removed
+  kanuka secrets prune --dry-run
+
+  # Remove orphaned files without prompting
+  kanuka secrets prune --force`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		Logger.Infof("Starting prune command")
+
+		spinner, cleanup := startSpinner("Scanning for orphaned key files...", verbose)
+		defer cleanup()
+
+		// Find orphans first so they can be shown before confirming.
+		previewResult, err := workflows.Prune(context.Background(), workflows.PruneOptions{DryRun: true})
+		if err != nil {
+			return reportCommandError(cmd, spinner, err, formatPruneError(err))
+		}
+
+		if len(previewResult.Orphans) == 0 {
+			spinner.FinalMSG = ui.Success.Sprint("✓") + " No orphaned key files found. Nothing to prune."
+			return nil
+		}
+
+		spinner.Stop()
+		if pruneDryRun {
+			fmt.Printf("[dry-run] Would remove %d orphaned file(s):\n", len(previewResult.Orphans))
+		} else {
+			fmt.Printf("Found %d orphaned file(s):\n\n", len(previewResult.Orphans))
+		}
+
+		printOrphanTable(previewResult.Orphans)
+
+		if pruneDryRun {
+			fmt.Println("\nNo changes made.")
+			spinner.FinalMSG = ""
+			return nil
+		}
+
+		if !pruneForce {
+			fmt.Println("\nThis will permanently delete the orphaned files listed above.")
+			fmt.Println("These files cannot be recovered.")
+			fmt.Println()
+
+			if !confirmCleanAction() {
+				fmt.Println("Aborted.")
+				spinner.FinalMSG = ""
+				return nil
+			}
+		}
+
+		spinner.Restart()
+
+		result, err := workflows.Prune(context.Background(), workflows.PruneOptions{})
+		if err != nil {
+			return reportCommandError(cmd, spinner, err, formatPruneError(err))
+		}
+
+		Logger.Infof("Prune completed: removed %d files", result.RemovedCount)
+		spinner.FinalMSG = ui.Success.Sprint("✓") + fmt.Sprintf(" Removed %d orphaned file(s)", result.RemovedCount)
+		return nil
+	},
+}
+
+// formatPruneError formats workflow errors into user-friendly messages.
+func formatPruneError(err error) string {
+	switch {
+	case errors.Is(err, kerrors.ErrProjectNotInitialized):
+		return ui.Error.Sprint("✗") + " Kanuka has not been initialized.\n" +
+			ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets init") + " first"
+
+	case errors.Is(err, kerrors.ErrInvalidProjectConfig):
+		return ui.Error.Sprint("✗") + " Failed to load project configuration.\n\n" +
+			ui.Info.Sprint("→") + " The .kanuka/config.toml file is not valid TOML.\n\n" +
+			"   To fix this issue:\n" +
+			"   1. Restore the file from git: " + ui.Code.Sprint("git checkout .kanuka/config.toml") + "\n" +
+			"   2. Or contact your project administrator for assistance"
+
+	default:
+		return ui.Error.Sprint("✗") + " Failed to prune\n" +
+			ui.Error.Sprint("Error: ") + err.Error()
+	}
+}
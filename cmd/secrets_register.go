@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
 	"github.com/PolarWolf314/kanuka/internal/ui"
 	"github.com/PolarWolf314/kanuka/internal/utils"
 	"github.com/PolarWolf314/kanuka/internal/workflows"
@@ -20,9 +21,15 @@ var (
 	registerUserEmail       string
 	customFilePath          string
 	publicKeyText           string
+	registerGPGKeyID        string
+	registerPublicKeyPath   string
+	registerDeviceName      string
 	registerDryRun          bool
 	registerPrivateKeyStdin bool
 	registerForce           bool
+	registerAllPending      bool
+	registerKeyType         string
+	registerExpiry          string
 	registerPrivateKeyData  []byte
 )
 
@@ -31,9 +38,15 @@ func resetRegisterCommandState() {
 	registerUserEmail = ""
 	customFilePath = ""
 	publicKeyText = ""
+	registerGPGKeyID = ""
+	registerPublicKeyPath = ""
+	registerDeviceName = ""
 	registerDryRun = false
 	registerPrivateKeyStdin = false
 	registerForce = false
+	registerAllPending = false
+	registerKeyType = ""
+	registerExpiry = ""
 	registerPrivateKeyData = nil
 }
 
@@ -41,9 +54,15 @@ func init() {
 	RegisterCmd.Flags().StringVarP(&registerUserEmail, "user", "u", "", "user email to register for access")
 	RegisterCmd.Flags().StringVarP(&customFilePath, "file", "f", "", "the path to a custom public key — will add public key to the project")
 	RegisterCmd.Flags().StringVar(&publicKeyText, "pubkey", "", "OpenSSH or PEM public key content to be saved with the specified user email")
+	RegisterCmd.Flags().StringVar(&registerPublicKeyPath, "public-key", "", "path to a PEM or OpenSSH public key file to register for the specified user email")
+	RegisterCmd.Flags().StringVar(&registerGPGKeyID, "gpg-key", "", "GPG key ID or fingerprint to export from your keyring and register for the specified user email")
+	RegisterCmd.Flags().StringVar(&registerDeviceName, "device", "", "register this machine as a new device of yours, using another device's key from --private-key-stdin")
 	RegisterCmd.Flags().BoolVar(&registerDryRun, "dry-run", false, "preview registration without making changes")
 	RegisterCmd.Flags().BoolVar(&registerPrivateKeyStdin, "private-key-stdin", false, "read private key from stdin instead of from disk")
-	RegisterCmd.Flags().BoolVar(&registerForce, "force", false, "skip confirmation when updating existing user's access")
+	RegisterCmd.Flags().BoolVar(&registerForce, "force", false, "skip confirmation when updating existing user's access, or replace an existing key with --public-key")
+	RegisterCmd.Flags().BoolVar(&registerAllPending, "all-pending", false, "grant access to every public key in the project that has no encrypted key yet")
+	RegisterCmd.Flags().StringVar(&registerKeyType, "key-type", "", "key pair type to generate with --device: rsa2048 (default), rsa4096, or ed25519")
+	RegisterCmd.Flags().StringVar(&registerExpiry, "expiry", "", "last day (YYYY-MM-DD, UTC) the registered device should have access")
 }
 
 // RegisterCmd is the register command.
@@ -60,10 +79,40 @@ Methods to register a user:
   1. By email: --user <email> (user must have run 'secrets create' first)
   2. By public key file: --file <path-to-.pub-file>
   3. By public key text: --pubkey <key-content> --user <email>
+  4. By public key file with any name: --public-key <path> --user <email>
+  5. By GPG key: --gpg-key <key-id> --user <email> (requires gpg on PATH)
+  6. This machine as a new device of yours: --device <name> --private-key-stdin
+  7. Your pending key from 'secrets create --no-register': no flags
+
+With --public-key, the key file is read locally, so no network access is
+needed; this suits onboarding air-gapped machines. It refuses to replace a
+public key the user already has unless --force is given.
+
+With --gpg-key, the key is exported from your local gpg keyring and stored as
+.kanuka/public_keys/<uuid>.gpg. The user then decrypts with their gpg agent
+instead of a Kānuka private key.
+
+With no flags, a key pair made earlier with 'kanuka secrets create
+--no-register' becomes your key for this project. Its public key is added to
+the project unless an admin already registered it for you.
+
+With --device, run on a new machine, a key pair is generated for it and added
+under your email (from your user config, or --user) as a new device. No one
+else needs to grant access, but you must pipe in the private key of one of
+your already-registered devices with --private-key-stdin. Device names must be
+unique per email. --key-type chooses the new key pair: rsa2048 (the default)
+is quick to generate, rsa4096 is stronger but slower, and ed25519 is small and
+fast.
 
 After running this command, the user will immediately have access to decrypt
 secrets once they pull the latest changes from the repository.
 
+Use --expiry to time-box access, for example for a contractor. The date is
+recorded on the device in .kanuka/config.toml. Expiry is advisory: Kānuka
+doesn't stop an expired device from decrypting, but 'kanuka secrets doctor'
+warns about expired devices and 'kanuka secrets revoke --expired' revokes
+them. Secrets they already pulled stay in their git history.
+
 Use --dry-run to preview what would be created without making changes.
 
 Use --private-key-stdin to read your private key from stdin instead of from disk.
@@ -79,6 +128,24 @@ Examples:
   # Register a user with public key text (useful for automation)
   kanuka secrets register --user alice@example.com --pubkey "ssh-rsa AAAA..."
 
+  # Register a user with an Ed25519 SSH key
+  kanuka secrets register --user alice@example.com --pubkey "ssh-ed25519 AAAA..."
+
+  # Register a user from a key file copied off an air-gapped machine
+  kanuka secrets register --user alice@example.com --public-key /media/usb/alice.pub
+
+  # Register a user with a GPG key from your keyring
+  kanuka secrets register --user alice@example.com --gpg-key 0xA1B2C3D4E5F60718
+
+  # Register this new laptop using the key from your existing desktop
+  ssh desktop cat ~/.local/share/kanuka/keys/<project-uuid>/privkey | kanuka secrets register --device laptop --private-key-stdin
+
+  # Give a contractor access until the end of the year
+  kanuka secrets register --user contractor@example.com --expiry 2025-12-31
+
+  # Grant access to everyone who has run 'kanuka secrets create' since
+  kanuka secrets register --all-pending
+
   # Preview registration without making changes
   kanuka secrets register --user alice@example.com --dry-run
 
@@ -92,36 +159,88 @@ func runRegister(cmd *cobra.Command, args []string) error {
 	spinner, cleanup := startSpinner("Registering user for access...", verbose)
 	defer cleanup()
 
+	// With no flags, a key pair made with create --no-register is promoted
+	// into this project.
+	noKeyFlags := registerUserEmail == "" && customFilePath == "" && publicKeyText == "" && registerGPGKeyID == "" && registerPublicKeyPath == "" && registerDeviceName == "" && !registerAllPending
+	promotePending := noKeyFlags && workflows.HasPendingKey()
+
 	// Check for required flags.
-	if registerUserEmail == "" && customFilePath == "" && publicKeyText == "" {
-		finalMessage := ui.Error.Sprint("✗") + " Either " + ui.Flag.Sprint("--user") + ", " + ui.Flag.Sprint("--file") + ", or " + ui.Flag.Sprint("--pubkey") + " must be specified." +
+	if noKeyFlags && !promotePending {
+		finalMessage := ui.Error.Sprint("✗") + " Either " + ui.Flag.Sprint("--user") + ", " + ui.Flag.Sprint("--file") + ", " + ui.Flag.Sprint("--pubkey") + ", " + ui.Flag.Sprint("--public-key") + ", " + ui.Flag.Sprint("--gpg-key") + ", or " + ui.Flag.Sprint("--device") + " must be specified." +
 			"\nRun " + ui.Code.Sprint("kanuka secrets register --help") + " to see the available commands"
-		spinner.FinalMSG = finalMessage
-		return nil
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: either --user, --file, --pubkey, --public-key, --gpg-key, or --device must be specified", kerrors.ErrInvalidArguments), finalMessage)
+	}
+
+	// --all-pending grants many keys at once, so an expiry can't be given.
+	if registerAllPending && registerExpiry != "" {
+		finalMessage := ui.Error.Sprint("✗") + " " + ui.Flag.Sprint("--expiry") + " cannot be used with " + ui.Flag.Sprint("--all-pending")
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: --expiry cannot be used with --all-pending", kerrors.ErrInvalidArguments), finalMessage)
+	}
+
+	// --all-pending finds its own keys, so no key or user may be given.
+	if registerAllPending && (registerUserEmail != "" || publicKeyText != "" || customFilePath != "" || registerGPGKeyID != "" || registerPublicKeyPath != "" || registerDeviceName != "") {
+		finalMessage := ui.Error.Sprint("✗") + " " + ui.Flag.Sprint("--all-pending") + " cannot be used with " + ui.Flag.Sprint("--user") + ", " + ui.Flag.Sprint("--file") + ", " + ui.Flag.Sprint("--pubkey") + ", " + ui.Flag.Sprint("--public-key") + ", " + ui.Flag.Sprint("--gpg-key") + ", or " + ui.Flag.Sprint("--device")
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: --all-pending cannot be used with --user, --file, --pubkey, --public-key, --gpg-key, or --device", kerrors.ErrInvalidArguments), finalMessage)
+	}
+
+	// --device registers this machine, so no other key may be given.
+	if registerDeviceName != "" && (publicKeyText != "" || customFilePath != "" || registerGPGKeyID != "" || registerPublicKeyPath != "") {
+		finalMessage := ui.Error.Sprint("✗") + " " + ui.Flag.Sprint("--device") + " cannot be used with " + ui.Flag.Sprint("--pubkey") + ", " + ui.Flag.Sprint("--file") + ", " + ui.Flag.Sprint("--public-key") + ", or " + ui.Flag.Sprint("--gpg-key")
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: --device cannot be used with --pubkey, --file, --public-key, or --gpg-key", kerrors.ErrInvalidArguments), finalMessage)
+	}
+
+	// --key-type picks the key pair generated for this machine, so it needs --device.
+	if registerKeyType != "" && registerDeviceName == "" {
+		finalMessage := ui.Error.Sprint("✗") + " " + ui.Flag.Sprint("--key-type") + " can only be used with " + ui.Flag.Sprint("--device")
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: --key-type requires --device", kerrors.ErrInvalidArguments), finalMessage)
+	}
+	if _, err := secrets.ParseKeyAlgorithm(registerKeyType); err != nil {
+		finalMessage := ui.Error.Sprint("✗") + " Unsupported " + ui.Flag.Sprint("--key-type") + ": " + ui.Highlight.Sprint(registerKeyType) +
+			"\n" + ui.Info.Sprint("→") + " Choose one of " + ui.Code.Sprint("rsa2048") + ", " + ui.Code.Sprint("rsa4096") + ", or " + ui.Code.Sprint("ed25519")
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: %v", kerrors.ErrInvalidArguments, err), finalMessage)
+	}
+
+	// When using --public-key, user email is required and no other key may be given.
+	if registerPublicKeyPath != "" && registerUserEmail == "" {
+		finalMessage := ui.Error.Sprint("✗") + " When using " + ui.Flag.Sprint("--public-key") + ", the " + ui.Flag.Sprint("--user") + " flag is required." +
+			"\nSpecify a user email with " + ui.Flag.Sprint("--user")
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: --public-key requires --user", kerrors.ErrInvalidArguments), finalMessage)
+	}
+	if registerPublicKeyPath != "" && (publicKeyText != "" || customFilePath != "" || registerGPGKeyID != "") {
+		finalMessage := ui.Error.Sprint("✗") + " " + ui.Flag.Sprint("--public-key") + " cannot be used with " + ui.Flag.Sprint("--pubkey") + ", " + ui.Flag.Sprint("--file") + ", or " + ui.Flag.Sprint("--gpg-key")
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: --public-key cannot be used with --pubkey, --file, or --gpg-key", kerrors.ErrInvalidArguments), finalMessage)
 	}
 
 	// When using --pubkey, user email is required.
 	if publicKeyText != "" && registerUserEmail == "" {
 		finalMessage := ui.Error.Sprint("✗") + " When using " + ui.Flag.Sprint("--pubkey") + ", the " + ui.Flag.Sprint("--user") + " flag is required." +
 			"\nSpecify a user email with " + ui.Flag.Sprint("--user")
-		spinner.FinalMSG = finalMessage
-		return nil
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: --pubkey requires --user", kerrors.ErrInvalidArguments), finalMessage)
+	}
+
+	// When using --gpg-key, user email is required and no other key may be given.
+	if registerGPGKeyID != "" && registerUserEmail == "" {
+		finalMessage := ui.Error.Sprint("✗") + " When using " + ui.Flag.Sprint("--gpg-key") + ", the " + ui.Flag.Sprint("--user") + " flag is required." +
+			"\nSpecify a user email with " + ui.Flag.Sprint("--user")
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: --gpg-key requires --user", kerrors.ErrInvalidArguments), finalMessage)
+	}
+	if registerGPGKeyID != "" && (publicKeyText != "" || customFilePath != "") {
+		finalMessage := ui.Error.Sprint("✗") + " " + ui.Flag.Sprint("--gpg-key") + " cannot be used with " + ui.Flag.Sprint("--pubkey") + " or " + ui.Flag.Sprint("--file")
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: --gpg-key cannot be used with --pubkey or --file", kerrors.ErrInvalidArguments), finalMessage)
 	}
 
 	// Validate email format if provided.
 	if registerUserEmail != "" && !utils.IsValidEmail(registerUserEmail) {
 		finalMessage := ui.Error.Sprint("✗") + " Invalid email format: " + ui.Highlight.Sprint(registerUserEmail) +
 			"\n" + ui.Info.Sprint("→") + " Please provide a valid email address"
-		spinner.FinalMSG = finalMessage
-		return nil
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: %s", kerrors.ErrInvalidEmail, registerUserEmail), finalMessage)
 	}
 
 	// Check if pubkey flag was explicitly used but with empty content.
 	if publicKeyText == "" && cmd.Flags().Changed("pubkey") {
 		finalMessage := ui.Error.Sprint("✗") + " Invalid public key format provided" +
 			"\n" + ui.Error.Sprint("Error: ") + "public key text cannot be empty"
-		spinner.FinalMSG = finalMessage
-		return nil
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: public key text cannot be empty", kerrors.ErrInvalidArguments), finalMessage)
 	}
 
 	// Read private key from stdin early.
@@ -132,16 +251,27 @@ func runRegister(cmd *cobra.Command, args []string) error {
 			Logger.Errorf("Failed to read private key from stdin: %v", err)
 			finalMessage := ui.Error.Sprint("✗") + " Failed to read private key from stdin" +
 				"\n" + ui.Error.Sprint("Error: ") + err.Error()
-			spinner.FinalMSG = finalMessage
-			return nil
+			return reportCommandError(cmd, spinner, err, finalMessage)
 		}
 		registerPrivateKeyData = keyData
 		Logger.Infof("Private key data read from stdin (%d bytes)", len(keyData))
 	}
 
+	if registerAllPending {
+		return runRegisterAllPending(cmd, spinner)
+	}
+
 	// Determine registration mode.
 	var mode workflows.RegisterMode
 	switch {
+	case promotePending:
+		mode = workflows.RegisterModePending
+	case registerDeviceName != "":
+		mode = workflows.RegisterModeDevice
+	case registerGPGKeyID != "":
+		mode = workflows.RegisterModeGPG
+	case registerPublicKeyPath != "":
+		mode = workflows.RegisterModePublicKeyFile
 	case publicKeyText != "":
 		mode = workflows.RegisterModePubkeyText
 	case customFilePath != "":
@@ -151,9 +281,15 @@ func runRegister(cmd *cobra.Command, args []string) error {
 	}
 
 	// Handle overwrite confirmation for existing users (interactive - must stay in cmd layer).
-	if !registerForce && !registerDryRun {
+	// --public-key never prompts; the workflow refuses to replace a key without --force.
+	// --device and pending keys only ever add this machine.
+	if !registerForce && !registerDryRun && mode != workflows.RegisterModePublicKeyFile && mode != workflows.RegisterModeDevice && mode != workflows.RegisterModePending {
 		_, alreadyHasAccess, err := workflows.CheckUserExistsForRegistration(registerUserEmail)
 		if err == nil && alreadyHasAccess {
+			if jsonOutput() {
+				err := fmt.Errorf("%w: %s already has access; use --force to replace their key", kerrors.ErrConfirmationRequired, registerUserEmail)
+				return reportCommandError(cmd, spinner, err, "")
+			}
 			if !confirmRegisterOverwrite(spinner, registerUserEmail) {
 				spinner.FinalMSG = ui.Warning.Sprint("⚠") + " Registration cancelled."
 				return nil
@@ -167,6 +303,11 @@ func runRegister(cmd *cobra.Command, args []string) error {
 		UserEmail:      registerUserEmail,
 		PublicKeyText:  publicKeyText,
 		FilePath:       customFilePath,
+		PublicKeyPath:  registerPublicKeyPath,
+		GPGKeyID:       registerGPGKeyID,
+		DeviceName:     registerDeviceName,
+		KeyType:        registerKeyType,
+		Expiry:         registerExpiry,
 		DryRun:         registerDryRun,
 		PrivateKeyData: registerPrivateKeyData,
 		Force:          registerForce,
@@ -176,19 +317,17 @@ func runRegister(cmd *cobra.Command, args []string) error {
 
 	result, err := workflows.Register(ctx, opts)
 	if err != nil {
-		spinner.FinalMSG = formatRegisterError(err, registerUserEmail, customFilePath)
-		// Return nil for expected errors, return error for unexpected ones.
-		if errors.Is(err, kerrors.ErrProjectNotInitialized) ||
-			errors.Is(err, kerrors.ErrUserNotFound) ||
-			errors.Is(err, kerrors.ErrNoAccess) ||
-			errors.Is(err, kerrors.ErrPublicKeyNotFound) ||
-			errors.Is(err, kerrors.ErrInvalidFileType) ||
-			errors.Is(err, kerrors.ErrKeyDecryptFailed) ||
-			strings.Contains(err.Error(), "invalid public key format") ||
-			strings.Contains(err.Error(), "permission denied") {
-			return nil
+		message := formatRegisterError(err, registerUserEmail, customFilePath)
+		if mode == workflows.RegisterModePending {
+			if pendingMessage, ok := formatRegisterPendingError(err); ok {
+				message = pendingMessage
+			}
 		}
-		return err
+		return reportCommandError(cmd, spinner, err, message)
+	}
+
+	if jsonOutput() {
+		return printJSONResult(result)
 	}
 
 	if result.DryRun {
@@ -202,7 +341,78 @@ func runRegister(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runRegisterAllPending grants access to every public key that has no
+// encrypted key yet and reports the outcome for each one.
+func runRegisterAllPending(cmd *cobra.Command, spinner *spinner.Spinner) error {
+	opts := workflows.RegisterAllPendingOptions{
+		DryRun:         registerDryRun,
+		PrivateKeyData: registerPrivateKeyData,
+	}
+
+	result, err := workflows.RegisterAllPending(context.Background(), opts)
+	if err != nil {
+		return reportCommandError(cmd, spinner, err, formatRegisterError(err, "", ""))
+	}
+
+	Logger.Infof("Register --all-pending: %d pending, %d failed", len(result.Grants), result.Failed())
+
+	if jsonOutput() {
+		return printJSONResult(result)
+	}
+
+	spinner.FinalMSG = formatRegisterAllPendingResult(result)
+	return nil
+}
+
+// formatRegisterAllPendingResult lists each pending key with whether it was
+// granted access.
+func formatRegisterAllPendingResult(result *workflows.RegisterAllPendingResult) string {
+	if len(result.Grants) == 0 {
+		return ui.Success.Sprint("✓") + " No pending public keys; everyone in the project already has access"
+	}
+
+	var message string
+	if result.DryRun {
+		message = ui.Warning.Sprint("[dry-run]") + fmt.Sprintf(" Would grant access to %d pending public key(s):\n", len(result.Grants))
+	} else {
+		message = fmt.Sprintf("Granting access to %d pending public key(s):\n", len(result.Grants))
+	}
+
+	for _, grant := range result.Grants {
+		name := grant.UserEmail
+		if name == "" {
+			name = "unknown user"
+		}
+		label := ui.Highlight.Sprint(name) + " (" + grant.UUID + ")"
+		switch {
+		case grant.Error != "":
+			message += "  " + ui.Error.Sprint("✗") + " " + label + ": " + grant.Error + "\n"
+		case grant.Granted:
+			message += "  " + ui.Success.Sprint("✓") + " " + label + "\n"
+		default:
+			message += "  - " + label + "\n"
+		}
+	}
+
+	failed := result.Failed()
+	switch {
+	case result.DryRun:
+		message += "\n" + ui.Info.Sprint("No changes made.") + " Run without --dry-run to execute."
+	case failed > 0:
+		message += "\n" + ui.Warning.Sprint("⚠") + fmt.Sprintf(" %d of %d pending key(s) could not be granted access", failed, len(result.Grants))
+	default:
+		message += "\n" + ui.Success.Sprint("✓") + fmt.Sprintf(" Granted access to %d pending key(s)", len(result.Grants))
+	}
+	return message
+}
+
 func formatRegisterError(err error, userEmail, filePath string) string {
+	if registerDeviceName != "" {
+		if message, ok := formatRegisterDeviceError(err); ok {
+			return message
+		}
+	}
+
 	switch {
 	case errors.Is(err, kerrors.ErrProjectNotInitialized):
 		return ui.Error.Sprint("✗") + " Kānuka has not been initialized\n" +
@@ -220,10 +430,33 @@ func formatRegisterError(err error, userEmail, filePath string) string {
 		return ui.Error.Sprint("✗") + " You don't have access to this project\n" +
 			ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets create") + " to generate your keys"
 
+	case errors.Is(err, kerrors.ErrInvalidEmail):
+		return ui.Error.Sprint("✗") + " Invalid email format: " + ui.Highlight.Sprint(userEmail) + "\n" +
+			ui.Info.Sprint("→") + " Please provide a valid email address"
+
+	case errors.Is(err, kerrors.ErrInvalidDateFormat), errors.Is(err, kerrors.ErrInvalidArguments) && registerExpiry != "":
+		return ui.Error.Sprint("✗") + " Invalid " + ui.Flag.Sprint("--expiry") + ": " + ui.Highlight.Sprint(registerExpiry) + "\n" +
+			ui.Info.Sprint("→") + " " + err.Error()
+
 	case errors.Is(err, kerrors.ErrKeyDecryptFailed):
 		return ui.Error.Sprint("✗") + " Failed to decrypt your Kānuka key\n" +
 			ui.Info.Sprint("→") + " " + err.Error()
 
+	case errors.Is(err, kerrors.ErrGPGNotFound):
+		return ui.Error.Sprint("✗") + " " + ui.Code.Sprint("gpg") + " was not found on your PATH\n" +
+			ui.Info.Sprint("→") + " Install GnuPG to register or decrypt with GPG keys"
+
+	case errors.Is(err, kerrors.ErrFileNotFound):
+		return ui.Error.Sprint("✗") + " Public key file " + ui.Path.Sprint(registerPublicKeyPath) + " not found"
+
+	case errors.Is(err, kerrors.ErrPublicKeyExists):
+		return ui.Error.Sprint("✗") + " " + ui.Highlight.Sprint(userEmail) + " already has a public key in this project\n" +
+			ui.Info.Sprint("→") + " Use " + ui.Flag.Sprint("--force") + " to replace it"
+
+	case errors.Is(err, kerrors.ErrPublicKeyNotFound) && registerGPGKeyID != "":
+		return ui.Error.Sprint("✗") + " Couldn't export GPG key " + ui.Highlight.Sprint(registerGPGKeyID) + "\n" +
+			ui.Error.Sprint("Error: ") + err.Error()
+
 	case errors.Is(err, kerrors.ErrPublicKeyNotFound):
 		if userEmail != "" {
 			return ui.Error.Sprint("✗") + " Public key for user " + ui.Highlight.Sprint(userEmail) + " not found" +
@@ -266,7 +499,65 @@ func formatRegisterError(err error, userEmail, filePath string) string {
 	}
 }
 
+// formatRegisterDeviceError formats errors specific to registering this
+// machine with --device. It returns false for errors formatted the same way
+// as other registrations.
+func formatRegisterDeviceError(err error) (string, bool) {
+	switch {
+	case errors.Is(err, kerrors.ErrDeviceNameTaken):
+		return ui.Error.Sprint("✗") + " You already have a device named " + ui.Highlight.Sprint(registerDeviceName) + " in this project\n" +
+			ui.Info.Sprint("→") + " Choose a different name with " + ui.Flag.Sprint("--device"), true
+
+	case errors.Is(err, kerrors.ErrInvalidEmail):
+		return ui.Error.Sprint("✗") + " No valid email to register this device under\n" +
+			ui.Info.Sprint("→") + " Pass the email your other devices use with " + ui.Flag.Sprint("--user"), true
+
+	case errors.Is(err, kerrors.ErrUserNotFound):
+		return ui.Error.Sprint("✗") + " You have no registered devices in this project\n" +
+			ui.Error.Sprint("Error: ") + err.Error() + "\n" +
+			ui.Info.Sprint("→") + " " + ui.Flag.Sprint("--device") + " adds a machine for someone who already has access. Run " +
+			ui.Code.Sprint("kanuka secrets create") + " and ask someone with access to register you", true
+
+	case errors.Is(err, kerrors.ErrPublicKeyExists):
+		return ui.Error.Sprint("✗") + " This machine is already registered in this project\n" +
+			ui.Info.Sprint("→") + " " + err.Error(), true
+
+	case errors.Is(err, kerrors.ErrInvalidPrivateKey):
+		return ui.Error.Sprint("✗") + " Failed to parse the private key from stdin\n" +
+			ui.Info.Sprint("→") + " Ensure your private key is in valid format (PEM or OpenSSH)", true
+
+	case errors.Is(err, kerrors.ErrNoAccess):
+		return ui.Error.Sprint("✗") + " Couldn't unlock the project with another of your devices\n" +
+			ui.Error.Sprint("Error: ") + err.Error() + "\n" +
+			ui.Info.Sprint("→") + " Pipe the private key of a registered device with " + ui.Flag.Sprint("--private-key-stdin"), true
+	}
+	return "", false
+}
+
+// formatRegisterPendingError formats errors specific to promoting a key
+// made with create --no-register. It returns false for errors formatted the
+// same way as other registrations.
+func formatRegisterPendingError(err error) (string, bool) {
+	switch {
+	case errors.Is(err, kerrors.ErrPublicKeyExists):
+		return ui.Error.Sprint("✗") + " Couldn't use your pending key in this project\n" +
+			ui.Error.Sprint("Error: ") + err.Error(), true
+
+	case errors.Is(err, kerrors.ErrInvalidEmail):
+		return ui.Error.Sprint("✗") + " No valid email to register your pending key under\n" +
+			ui.Info.Sprint("→") + " Set one with " + ui.Code.Sprint("kanuka config set user.email <email>"), true
+
+	case errors.Is(err, kerrors.ErrDeviceNameTaken):
+		return ui.Error.Sprint("✗") + " You already have a device with that name in this project", true
+	}
+	return "", false
+}
+
 func formatRegisterSuccess(result *workflows.RegisterResult) string {
+	if result.Mode == workflows.RegisterModePending {
+		return formatRegisterPendingSuccess(result)
+	}
+
 	var successVerb string
 	if len(result.FilesUpdated) > 0 {
 		successVerb = "access has been updated"
@@ -300,24 +591,84 @@ func formatRegisterSuccess(result *workflows.RegisterResult) string {
 		finalMessage += "\n"
 	}
 
+	if result.ExpiresAt != nil {
+		finalMessage += "Access expires: " + ui.Highlight.Sprint(result.ExpiresAt.Format("Jan 2, 2006")) + "\n\n"
+	}
+
+	if result.Fingerprint != "" {
+		finalMessage += "Key fingerprint: " + ui.Highlight.Sprint(result.Fingerprint) + "\n"
+		if result.Mode != workflows.RegisterModeDevice {
+			finalMessage += ui.Info.Sprint("→") + " Confirm with " + ui.Highlight.Sprint(result.DisplayName) + " that their key has this fingerprint\n"
+		}
+		finalMessage += "\n"
+	}
+
+	if result.Mode == workflows.RegisterModeDevice {
+		finalMessage += ui.Info.Sprint("→") + " This device now has access. Commit the " + ui.Path.Sprint(".kanuka") + " changes so your other devices see it"
+		return finalMessage
+	}
 	finalMessage += ui.Info.Sprint("→") + " They now have access to decrypt the repository's secrets"
 	return finalMessage
 }
 
+// formatRegisterPendingSuccess describes a pending key promoted into the
+// project, and whether the user still needs someone to grant access.
+func formatRegisterPendingSuccess(result *workflows.RegisterResult) string {
+	finalMessage := ui.Success.Sprint("✓") + " Your pending key is now your key for this project\n"
+	for _, f := range result.FilesCreated {
+		finalMessage += "    created: " + ui.Path.Sprint(f.Path) + "\n"
+	}
+	finalMessage += "    fingerprint: " + ui.Highlight.Sprint(result.Fingerprint) + "\n"
+
+	if result.UserAlreadyHadAccess {
+		return finalMessage + ui.Info.Sprint("→") + " You have access to this project's secrets. Commit the " + ui.Path.Sprint(".kanuka") + " changes"
+	}
+	return finalMessage + ui.Info.Sprint("→") + " Commit the " + ui.Path.Sprint(".kanuka") + " changes and ask someone with access to run " +
+		ui.Code.Sprint("kanuka secrets register --user "+result.DisplayName)
+}
+
 func printRegisterDryRun(result *workflows.RegisterResult) {
+	if result.Mode == workflows.RegisterModePending {
+		fmt.Println(ui.Warning.Sprint("[dry-run]") + " Would make your pending key your key for this project")
+		fmt.Println()
+		fmt.Println("Key fingerprint: " + ui.Highlight.Sprint(result.Fingerprint))
+		fmt.Println()
+		fmt.Println(ui.Info.Sprint("No changes made.") + " Run without --dry-run to execute.")
+		return
+	}
+
 	fmt.Println(ui.Warning.Sprint("[dry-run]") + " Would register " + ui.Highlight.Sprint(result.DisplayName))
 	fmt.Println()
 
 	fmt.Println("Files that would be created:")
-	if result.Mode == workflows.RegisterModePubkeyText {
+	if result.Mode == workflows.RegisterModePubkeyText || result.Mode == workflows.RegisterModePublicKeyFile || result.Mode == workflows.RegisterModeDevice {
 		fmt.Println("  - " + ui.Success.Sprint(result.PubKeyPath))
 	}
 	fmt.Println("  - " + ui.Success.Sprint(result.KanukaFilePath))
 	fmt.Println()
 
+	if result.Fingerprint != "" {
+		fmt.Println("Key fingerprint: " + ui.Highlight.Sprint(result.Fingerprint))
+		fmt.Println()
+	}
+
+	if result.ExpiresAt != nil {
+		fmt.Println("Access would expire: " + ui.Highlight.Sprint(result.ExpiresAt.Format("Jan 2, 2006")))
+		fmt.Println()
+	}
+
+	if result.Mode == workflows.RegisterModeDevice {
+		fmt.Println("Prerequisites verified:")
+		fmt.Println("  " + ui.Success.Sprint("✓") + " Device name is not taken")
+		fmt.Println("  " + ui.Success.Sprint("✓") + " Private key decrypts one of your devices' symmetric keys")
+		fmt.Println()
+		fmt.Println(ui.Info.Sprint("No changes made.") + " Run without --dry-run to execute.")
+		return
+	}
+
 	fmt.Println("Prerequisites verified:")
 	fmt.Println("  " + ui.Success.Sprint("✓") + " User exists in project config")
-	if result.Mode == workflows.RegisterModeFile {
+	if result.Mode == workflows.RegisterModeFile || result.Mode == workflows.RegisterModePublicKeyFile {
 		fmt.Println("  " + ui.Success.Sprint("✓") + " Public key loaded from file")
 	} else {
 		fmt.Println("  " + ui.Success.Sprint("✓") + " Public key found at " + result.PubKeyPath)
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/utils"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	rekeyUserEmail       string
+	rekeyPrivateKeyStdin bool
+)
+
+func init() {
+	rekeyCmd.Flags().StringVarP(&rekeyUserEmail, "user", "u", "", "email of the user whose encrypted key should be rewritten")
+	rekeyCmd.Flags().BoolVar(&rekeyPrivateKeyStdin, "private-key-stdin", false, "read private key from stdin instead of from disk")
+}
+
+// resetRekeyCommandState resets the rekey command's global state for testing.
+func resetRekeyCommandState() {
+	rekeyUserEmail = ""
+	rekeyPrivateKeyStdin = false
+}
+
+var rekeyCmd = &cobra.Command{
+	Use:   "rekey",
+	Short: "Rewrite a user's encrypted key from their existing public key",
+	Long: `Re-wraps the project's symmetric key for a user who is already in the
+project, writing .kanuka/secrets/<uuid>.kanuka for each of their devices
+that has a public key.
+
+The symmetric key is not rotated and secret files are not touched. Use this
+to repair a user whose .kanuka file was deleted or corrupted while their
+.pub file is still in place. To add a new user, use register instead; to
+rotate the key for everyone, use sync.
+
+Examples:
+  # Restore alice's access after her .kanuka file went missing
+  kanuka secrets rekey --user alice@example.com
+
+  # Use a key piped from a secret manager
+  vault read -field=private_key secret/kanuka | kanuka secrets rekey --user alice@example.com --private-key-stdin`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		Logger.Infof("Starting rekey command")
+		spinner, cleanup := startSpinner("Rekeying user...", verbose)
+		defer cleanup()
+
+		if rekeyUserEmail == "" {
+			finalMessage := ui.Error.Sprint("✗") + " The " + ui.Flag.Sprint("--user") + " flag is required" +
+				"\n" + ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets rekey --user <email>")
+			return reportCommandError(cmd, spinner, fmt.Errorf("%w: --user is required", kerrors.ErrInvalidArguments), finalMessage)
+		}
+
+		opts := workflows.RekeyOptions{UserEmail: rekeyUserEmail}
+
+		if rekeyPrivateKeyStdin {
+			Logger.Debugf("Reading private key from stdin")
+			keyData, err := utils.ReadStdin()
+			if err != nil {
+				Logger.Errorf("Failed to read private key from stdin: %v", err)
+				finalMessage := ui.Error.Sprint("✗") + " Failed to read private key from stdin" +
+					"\n" + ui.Error.Sprint("Error: ") + err.Error()
+				return reportCommandError(cmd, spinner, err, finalMessage)
+			}
+			opts.PrivateKeyData = keyData
+		}
+
+		result, err := workflows.Rekey(context.Background(), opts)
+		if err != nil {
+			Logger.Errorf("Rekey failed: %v", err)
+			return reportCommandError(cmd, spinner, err, formatRekeyError(err, rekeyUserEmail))
+		}
+
+		Logger.Infof("Rekeyed %s: %d created, %d updated", result.UserEmail, len(result.FilesCreated), len(result.FilesUpdated))
+		spinner.FinalMSG = formatRekeySuccess(result)
+		return nil
+	},
+}
+
+// formatRekeySuccess describes the key files rekey wrote.
+func formatRekeySuccess(result *workflows.RekeyResult) string {
+	message := ui.Success.Sprint("✓") + " Rewrote the encrypted key for " + ui.Highlight.Sprint(result.UserEmail)
+
+	projectPath := configs.ProjectKanukaSettings.ProjectPath
+	relative := func(path string) string {
+		if rel, err := filepath.Rel(projectPath, path); err == nil {
+			return rel
+		}
+		return path
+	}
+	for _, path := range result.FilesCreated {
+		message += "\n  created: " + ui.Path.Sprint(relative(path))
+	}
+	for _, path := range result.FilesUpdated {
+		message += "\n  updated: " + ui.Path.Sprint(relative(path))
+	}
+	if len(result.SkippedUUIDs) > 0 {
+		message += "\n" + ui.Warning.Sprint("⚠") + fmt.Sprintf(" Skipped %d device(s) with no public key", len(result.SkippedUUIDs))
+	}
+	return message
+}
+
+// formatRekeyError formats workflow errors into user-friendly messages.
+func formatRekeyError(err error, userEmail string) string {
+	switch {
+	case errors.Is(err, kerrors.ErrProjectNotInitialized):
+		return ui.Error.Sprint("✗") + " Kānuka has not been initialized" +
+			"\n" + ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets init") + " first"
+
+	case errors.Is(err, kerrors.ErrInvalidEmail):
+		return ui.Error.Sprint("✗") + " Invalid email format: " + ui.Highlight.Sprint(userEmail) +
+			"\n" + ui.Info.Sprint("→") + " Please provide a valid email address"
+
+	case errors.Is(err, kerrors.ErrUserNotFound):
+		return ui.Error.Sprint("✗") + " User " + ui.Highlight.Sprint(userEmail) + " not found in project" +
+			"\n" + ui.Info.Sprint("→") + " To add a new user, use " + ui.Code.Sprint("kanuka secrets register")
+
+	case errors.Is(err, kerrors.ErrPublicKeyNotFound):
+		return ui.Error.Sprint("✗") + " No public key found for " + ui.Highlight.Sprint(userEmail) +
+			"\n" + ui.Info.Sprint("→") + " Register their public key with " + ui.Code.Sprint("kanuka secrets register --user "+userEmail+" --pubkey ...")
+
+	case errors.Is(err, kerrors.ErrNoAccess):
+		return ui.Error.Sprint("✗") + " You don't have access to this project" +
+			"\n" + ui.Info.Sprint("→") + " Ask someone with access to run this command instead"
+
+	case errors.Is(err, kerrors.ErrKeyDecryptFailed):
+		return ui.Error.Sprint("✗") + " Failed to decrypt your Kānuka key" +
+			"\n" + ui.Error.Sprint("Error: ") + err.Error()
+
+	case errors.Is(err, kerrors.ErrGPGNotFound):
+		return ui.Error.Sprint("✗") + " " + ui.Code.Sprint("gpg") + " was not found on your PATH" +
+			"\n" + ui.Info.Sprint("→") + " Install GnuPG to use a GPG-wrapped key"
+
+	default:
+		return ui.Error.Sprint("✗") + " Failed to rekey user" +
+			"\n" + ui.Error.Sprint("Error: ") + err.Error()
+	}
+}
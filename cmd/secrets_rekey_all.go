@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+
+	"github.com/spf13/cobra"
+)
+
+var rekeyAllDryRun bool
+
+func init() {
+	rekeyAllCmd.Flags().BoolVar(&rekeyAllDryRun, "dry-run", false, "show the migration plan without making changes")
+}
+
+// resetRekeyAllCommandState resets the rekey-all command's global state for testing.
+func resetRekeyAllCommandState() {
+	rekeyAllDryRun = false
+}
+
+var rekeyAllCmd = &cobra.Command{
+	Use:   "rekey-all",
+	Short: "Migrate every user and secret file to the current key format",
+	Long: `Re-wraps every user's symmetric key and re-encrypts every secret file
+under the current key format and cipher, in one controlled operation.
+
+Use this to upgrade a project after the encryption format changes. It differs
+from sync, which is for routine key rotation: rekey-all verifies the result
+and rolls back on failure.
+
+After writing, a verification pass checks that:
+  - every user has a well-formed key
+  - your key unwraps the new symmetric key
+  - every secret file decrypts to its original contents
+
+If anything fails, every file is restored to its previous state.
+
+Use --dry-run to see the plan without making changes.
+
+Examples:
+  # Preview the migration
+  kanuka secrets rekey-all --dry-run
+
+  # Migrate the project
+  kanuka secrets rekey-all`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		Logger.Infof("Starting rekey-all command")
+		spinner, cleanup := startSpinner("Rekeying secrets...", verbose)
+		defer cleanup()
+
+		result, err := workflows.RekeyAll(context.Background(), workflows.RekeyAllOptions{
+			DryRun: rekeyAllDryRun,
+		})
+		if err != nil {
+			Logger.Errorf("Rekey-all failed: %v", err)
+			return reportCommandError(cmd, spinner, err, formatRekeyAllError(err))
+		}
+
+		if result.DryRun {
+			spinner.FinalMSG = formatRekeyAllPlan(result)
+			return nil
+		}
+
+		Logger.Infof("Rekeyed %d file(s) for %d user(s)", len(result.SecretFiles), len(result.UserUUIDs))
+		spinner.FinalMSG = ui.Success.Sprint("✓") + " All secrets rekeyed and verified" +
+			fmt.Sprintf("\n  Re-wrapped the key for %d user(s).", len(result.UserUUIDs)) +
+			fmt.Sprintf("\n  Re-encrypted %d secret file(s).", len(result.SecretFiles))
+		return nil
+	},
+}
+
+// formatRekeyAllPlan describes what rekey-all would do.
+func formatRekeyAllPlan(result *workflows.RekeyAllResult) string {
+	emails := map[string]string{}
+	if projectConfig, err := configs.LoadProjectConfig(); err == nil {
+		emails = projectConfig.Users
+	}
+
+	message := ui.Warning.Sprint("[dry-run]") + " Would rekey the project:" +
+		"\n\n" + fmt.Sprintf("Re-wrap the symmetric key for %d user(s):", len(result.UserUUIDs))
+	for _, uuid := range result.UserUUIDs {
+		name := uuid
+		if email := emails[uuid]; email != "" {
+			name = email + " " + ui.Muted.Sprint("("+uuid+")")
+		}
+		message += "\n  - " + name
+	}
+
+	message += "\n\n" + fmt.Sprintf("Re-encrypt %d secret file(s):", len(result.SecretFiles))
+	for _, f := range result.SecretFiles {
+		rel, err := filepath.Rel(result.ProjectPath, f)
+		if err != nil {
+			rel = f
+		}
+		message += "\n  - " + ui.Path.Sprint(rel)
+	}
+
+	message += "\n\nThen verify every user's key and every file, rolling back on failure." +
+		"\n\n" + ui.Info.Sprint("No changes made.") + " Run without --dry-run to execute."
+	return message
+}
+
+// formatRekeyAllError formats workflow errors into user-friendly messages.
+func formatRekeyAllError(err error) string {
+	switch {
+	case errors.Is(err, kerrors.ErrProjectNotInitialized):
+		return ui.Error.Sprint("✗") + " Kanuka has not been initialized" +
+			"\n" + ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets init") + " first"
+
+	case errors.Is(err, kerrors.ErrPrivateKeyNotFound), errors.Is(err, kerrors.ErrInvalidPrivateKey):
+		return ui.Error.Sprint("✗") + " Failed to load your private key. Are you sure you have access?" +
+			"\n" + ui.Error.Sprint("Error: ") + err.Error()
+
+	case errors.Is(err, kerrors.ErrNoAccess):
+		return ui.Error.Sprint("✗") + " You don't have access to this project" +
+			"\n" + ui.Info.Sprint("→") + " Ask someone with access to register you first"
+
+	case errors.Is(err, kerrors.ErrKeyDecryptFailed):
+		return ui.Error.Sprint("✗") + " Failed to decrypt the symmetric key" +
+			"\n" + ui.Error.Sprint("Error: ") + err.Error()
+
+	case errors.Is(err, kerrors.ErrDecryptFailed):
+		return ui.Error.Sprint("✗") + " A secret file could not be decrypted, so nothing was changed" +
+			"\n" + ui.Error.Sprint("Error: ") + err.Error()
+
+	case errors.Is(err, kerrors.ErrRekeyFailed):
+		return ui.Error.Sprint("✗") + " Rekey failed; all changes were rolled back" +
+			"\n" + ui.Error.Sprint("Error: ") + err.Error()
+
+	default:
+		return ui.Error.Sprint("✗") + " Failed to rekey secrets" +
+			"\n" + ui.Error.Sprint("Error: ") + err.Error()
+	}
+}
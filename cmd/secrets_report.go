@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/PolarWolf314/kanuka/internal/ui"
+
+	"github.com/briandowns/spinner"
+)
+
+// commandReport is the machine-readable summary written by --report.
+// It is written in addition to the normal human-readable output, so CI
+// pipelines can show progress and archive an artifact of what happened.
+type commandReport struct {
+	Command       string   `json:"command"`
+	Success       bool     `json:"success"`
+	DryRun        bool     `json:"dry_run"`
+	StartedAt     string   `json:"started_at"`
+	DurationMs    int64    `json:"duration_ms"`
+	ProjectPath   string   `json:"project_path,omitempty"`
+	Created       []string `json:"created"`
+	Updated       []string `json:"updated"`
+	Skipped       []string `json:"skipped"`
+	Deleted       []string `json:"deleted"`
+	AffectedUsers []string `json:"affected_users"`
+	Error         string   `json:"error,omitempty"`
+
+	started time.Time
+}
+
+// newCommandReport starts timing a report for the given command.
+func newCommandReport(command string) *commandReport {
+	now := time.Now()
+	return &commandReport{
+		Command:       command,
+		StartedAt:     now.UTC().Format(time.RFC3339),
+		Created:       []string{},
+		Updated:       []string{},
+		Skipped:       []string{},
+		Deleted:       []string{},
+		AffectedUsers: []string{},
+		started:       now,
+	}
+}
+
+// addFiles records created and updated files, made relative to the project.
+func (r *commandReport) addFiles(created, updated []string) {
+	for _, f := range created {
+		r.Created = append(r.Created, r.relPath(f))
+	}
+	for _, f := range updated {
+		r.Updated = append(r.Updated, r.relPath(f))
+	}
+}
+
+// fail records err as the reason the command did not succeed.
+func (r *commandReport) fail(err error) {
+	r.Success = false
+	r.Error = fmt.Sprint(err)
+}
+
+// relPath returns path relative to the report's project path when possible.
+func (r *commandReport) relPath(path string) string {
+	if r.ProjectPath == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	rel, err := filepath.Rel(r.ProjectPath, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// writeCommandReport finalizes the report and writes it to path as JSON.
+// It does nothing if path is empty. A failure to write the report never fails
+// the command; instead a warning is appended to the spinner's final message.
+func writeCommandReport(path string, report *commandReport, s *spinner.Spinner) {
+	if path == "" {
+		return
+	}
+
+	report.DurationMs = time.Since(report.started).Milliseconds()
+	if !report.Success && report.Error == "" {
+		report.Error = "command did not complete"
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err == nil {
+		// #nosec G306 -- Reports contain no secret values and are meant to be archived
+		err = os.WriteFile(path, append(data, '\n'), 0644)
+	}
+	if err != nil {
+		Logger.Warnf("Failed to write report to %s: %v", path, err)
+		msg := ui.Warning.Sprint("⚠") + " Failed to write report to " + ui.Path.Sprint(path) + ": " + err.Error()
+		if s.FinalMSG != "" {
+			msg = ui.EnsureNewline(s.FinalMSG) + msg
+		}
+		s.FinalMSG = msg
+		return
+	}
+	Logger.Infof("Wrote %s report to %s", report.Command, path)
+}
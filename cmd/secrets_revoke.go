@@ -23,6 +23,9 @@ var (
 	revokeDryRun          bool
 	revokePrivateKeyStdin bool
 	revokePrivateKeyData  []byte
+	revokeReportPath      string
+	revokeAllExcept       []string
+	revokeExpired         bool
 )
 
 // resetRevokeCommandState resets all revoke command global variables to their default values for testing.
@@ -34,6 +37,9 @@ func resetRevokeCommandState() {
 	revokeDryRun = false
 	revokePrivateKeyStdin = false
 	revokePrivateKeyData = nil
+	revokeReportPath = ""
+	revokeAllExcept = nil
+	revokeExpired = false
 }
 
 func init() {
@@ -43,6 +49,9 @@ func init() {
 	revokeCmd.Flags().BoolVarP(&revokeYes, "yes", "y", false, "skip confirmation prompts (for automation)")
 	revokeCmd.Flags().BoolVar(&revokeDryRun, "dry-run", false, "preview revocation without making changes")
 	revokeCmd.Flags().BoolVar(&revokePrivateKeyStdin, "private-key-stdin", false, "read private key from stdin instead of from disk")
+	revokeCmd.Flags().StringVar(&revokeReportPath, "report", "", "also write a JSON summary of the result to this file")
+	revokeCmd.Flags().StringSliceVar(&revokeAllExcept, "all-except", nil, "revoke every user except these emails, rotating the key once (repeatable or comma-separated)")
+	revokeCmd.Flags().BoolVar(&revokeExpired, "expired", false, "revoke every device whose registration expiry has passed, rotating the key once")
 }
 
 var revokeCmd = &cobra.Command{
@@ -59,13 +68,25 @@ You can revoke access by:
   1. User email: --user <email> (revokes all devices for that user)
   2. Specific device: --user <email> --device <device-name>
   3. File path: --file <path-to-.kanuka-file>
+  4. Everyone else: --all-except <email>,<email>... (revokes every user not
+     listed, then rotates the key once)
+  5. Expired devices: --expired (revokes every device registered with
+     --expiry whose date has passed, then rotates the key once)
 
 When revoking a user with multiple devices, you will be prompted to confirm
 unless --yes is specified. Use --device to revoke only a specific device.
 
+--all-except always lists the users it would revoke and asks to confirm
+unless --yes is specified. The list must include your own email, and every
+email in it must belong to the project. --expired asks to confirm the
+same way, and never revokes your own device.
+
 Use --dry-run to preview what would be revoked without making any changes.
 This shows which files would be deleted, config changes, and key rotation impact.
 
+Use --report to also write a JSON summary (deleted files, revoked users,
+timing, errors) to a file, for example to archive as a CI build artifact.
+
 Warning: After revocation, the revoked user may still have access to old
 secret values from their local git history. Consider rotating your actual
 secret values after this revocation if the user was compromised.
@@ -92,6 +113,15 @@ Examples:
   # Preview revocation without making changes
   kanuka secrets revoke --user alice@example.com --dry-run
 
+  # Revoke in CI and save a JSON report
+  kanuka secrets revoke --user alice@example.com --yes --report revoke-report.json
+
+  # Revoke everyone except the two remaining maintainers
+  kanuka secrets revoke --all-except alice@example.com,bob@example.com
+
+  # Revoke every device whose access has expired
+  kanuka secrets revoke --expired
+
   # Revoke by file path
   kanuka secrets revoke --file .kanuka/secrets/abc123.kanuka
 
@@ -108,34 +138,54 @@ func runRevoke(cmd *cobra.Command, args []string) error {
 	spinner, cleanup := startSpinner("Revoking access...", verbose)
 	defer cleanup()
 
+	report := newCommandReport("revoke")
+	defer writeCommandReport(revokeReportPath, report, spinner)
+
 	// Validate flags early.
 	if revokeDevice != "" && revokeUserEmail == "" {
 		finalMessage := ui.Error.Sprint("✗") + " The " + ui.Flag.Sprint("--device") + " flag requires " + ui.Flag.Sprint("--user") + " flag." +
 			"\nRun " + ui.Code.Sprint("kanuka secrets revoke --help") + " to see the available commands."
-		spinner.FinalMSG = finalMessage
-		return nil
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: --device requires --user", kerrors.ErrInvalidArguments), finalMessage)
 	}
 
-	if revokeUserEmail == "" && revokeFilePath == "" {
-		finalMessage := ui.Error.Sprint("✗") + " Either " + ui.Flag.Sprint("--user") + " or " + ui.Flag.Sprint("--file") + " flag is required." +
+	if len(revokeAllExcept) > 0 && (revokeUserEmail != "" || revokeFilePath != "" || revokeDevice != "") {
+		finalMessage := ui.Error.Sprint("✗") + " The " + ui.Flag.Sprint("--all-except") + " flag can't be combined with " +
+			ui.Flag.Sprint("--user") + ", " + ui.Flag.Sprint("--file") + ", or " + ui.Flag.Sprint("--device") + "." +
 			"\nRun " + ui.Code.Sprint("kanuka secrets revoke --help") + " to see the available commands."
-		spinner.FinalMSG = finalMessage
-		return nil
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: --all-except can't be combined with --user, --file, or --device", kerrors.ErrInvalidArguments), finalMessage)
+	}
+
+	if revokeExpired && (revokeUserEmail != "" || revokeFilePath != "" || revokeDevice != "" || len(revokeAllExcept) > 0) {
+		finalMessage := ui.Error.Sprint("✗") + " The " + ui.Flag.Sprint("--expired") + " flag can't be combined with " +
+			ui.Flag.Sprint("--user") + ", " + ui.Flag.Sprint("--file") + ", " + ui.Flag.Sprint("--device") + ", or " + ui.Flag.Sprint("--all-except") + "." +
+			"\nRun " + ui.Code.Sprint("kanuka secrets revoke --help") + " to see the available commands."
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: --expired can't be combined with --user, --file, --device, or --all-except", kerrors.ErrInvalidArguments), finalMessage)
+	}
+
+	if revokeUserEmail == "" && revokeFilePath == "" && len(revokeAllExcept) == 0 && !revokeExpired {
+		finalMessage := ui.Error.Sprint("✗") + " Either " + ui.Flag.Sprint("--user") + ", " + ui.Flag.Sprint("--file") + ", " + ui.Flag.Sprint("--all-except") + ", or " + ui.Flag.Sprint("--expired") + " flag is required." +
+			"\nRun " + ui.Code.Sprint("kanuka secrets revoke --help") + " to see the available commands."
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: either --user, --file, --all-except, or --expired is required", kerrors.ErrInvalidArguments), finalMessage)
 	}
 
 	if revokeUserEmail != "" && revokeFilePath != "" {
 		finalMessage := ui.Error.Sprint("✗") + " Cannot specify both " + ui.Flag.Sprint("--user") + " and " + ui.Flag.Sprint("--file") + " flags.\n" +
 			"Run " + ui.Code.Sprint("kanuka secrets revoke --help") + " to see the available commands.\n"
-		spinner.FinalMSG = finalMessage
-		return nil
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: cannot specify both --user and --file", kerrors.ErrInvalidArguments), finalMessage)
 	}
 
 	// Validate email format if provided.
 	if revokeUserEmail != "" && !utils.IsValidEmail(revokeUserEmail) {
 		finalMessage := ui.Error.Sprint("✗") + " Invalid email format: " + ui.Highlight.Sprint(revokeUserEmail) +
 			"\n" + ui.Info.Sprint("→") + " Please provide a valid email address"
-		spinner.FinalMSG = finalMessage
-		return nil
+		return reportCommandError(cmd, spinner, fmt.Errorf("%w: %s", kerrors.ErrInvalidEmail, revokeUserEmail), finalMessage)
+	}
+	for _, email := range revokeAllExcept {
+		if !utils.IsValidEmail(email) {
+			finalMessage := ui.Error.Sprint("✗") + " Invalid email format in " + ui.Flag.Sprint("--all-except") + ": " + ui.Highlight.Sprint(email) +
+				"\n" + ui.Info.Sprint("→") + " Please provide valid email addresses"
+			return reportCommandError(cmd, spinner, fmt.Errorf("%w: %s", kerrors.ErrInvalidEmail, email), finalMessage)
+		}
 	}
 
 	// Read private key from stdin early, before any other code can consume stdin.
@@ -152,6 +202,12 @@ func runRevoke(cmd *cobra.Command, args []string) error {
 	// Handle multi-device confirmation prompt (interactive - must stay in cmd layer).
 	if revokeUserEmail != "" && revokeDevice == "" && !revokeYes && !revokeDryRun {
 		devices, err := workflows.GetDevicesForUser(revokeUserEmail)
+		if err == nil && len(devices) > 1 && jsonOutput() {
+			err := fmt.Errorf("%w: %s has %d devices; use --yes to revoke all of them or --device to pick one",
+				kerrors.ErrConfirmationRequired, revokeUserEmail, len(devices))
+			report.fail(err)
+			return reportCommandError(cmd, spinner, err, "")
+		}
 		if err == nil && len(devices) > 1 {
 			spinner.Stop()
 
@@ -169,6 +225,7 @@ func runRevoke(cmd *cobra.Command, args []string) error {
 			}
 			response = strings.TrimSpace(strings.ToLower(response))
 			if response != "y" && response != "yes" {
+				report.fail(errors.New("revocation cancelled"))
 				finalMessage := ui.Warning.Sprint("⚠") + " Revocation cancelled."
 				spinner.FinalMSG = finalMessage
 				return nil
@@ -183,24 +240,92 @@ func runRevoke(cmd *cobra.Command, args []string) error {
 		UserEmail:      revokeUserEmail,
 		FilePath:       revokeFilePath,
 		DeviceName:     revokeDevice,
+		AllExcept:      revokeAllExcept,
+		Expired:        revokeExpired,
 		DryRun:         revokeDryRun,
 		PrivateKeyData: revokePrivateKeyData,
 		Verbose:        verbose,
 		Debug:          debug,
 	}
 
+	// Show everyone --all-except or --expired would revoke before doing it.
+	// If the preview fails, the real run below reports the same error.
+	if (len(revokeAllExcept) > 0 || revokeExpired) && !revokeYes && !revokeDryRun {
+		previewOpts := opts
+		previewOpts.DryRun = true
+		if preview, err := workflows.Revoke(ctx, previewOpts); err == nil {
+			if jsonOutput() {
+				flagName := "--all-except"
+				if revokeExpired {
+					flagName = "--expired"
+				}
+				err := fmt.Errorf("%w: %s would revoke %d user(s); use --yes to confirm",
+					kerrors.ErrConfirmationRequired, flagName, len(preview.RevokedUsers))
+				report.fail(err)
+				return reportCommandError(cmd, spinner, err, "")
+			}
+
+			spinner.Stop()
+			fmt.Printf("\n%s Warning: this will revoke access for %d user(s):\n", ui.Warning.Sprint("⚠"), len(preview.RevokedUsers))
+			for _, email := range preview.RevokedUsers {
+				fmt.Printf("  - %s\n", email)
+			}
+			if len(revokeAllExcept) > 0 {
+				fmt.Println("\nOnly " + strings.Join(revokeAllExcept, ", ") + " will keep access.")
+			} else {
+				fmt.Println("\nOnly their expired devices will be revoked.")
+			}
+
+			reader := bufio.NewReader(os.Stdin)
+			fmt.Print("Proceed? [y/N]: ")
+			response, err := reader.ReadString('\n')
+			if err != nil {
+				return Logger.ErrorfAndReturn("Failed to read response: %v", err)
+			}
+			response = strings.TrimSpace(strings.ToLower(response))
+			if response != "y" && response != "yes" {
+				report.fail(errors.New("revocation cancelled"))
+				finalMessage := ui.Warning.Sprint("⚠") + " Revocation cancelled."
+				spinner.FinalMSG = finalMessage
+				return nil
+			}
+
+			spinner.Restart()
+		}
+	}
+
 	result, err := workflows.Revoke(ctx, opts)
-	if err != nil {
-		spinner.FinalMSG = formatRevokeError(err)
-		// Return nil for expected errors, return error for unexpected ones.
-		if errors.Is(err, kerrors.ErrProjectNotInitialized) ||
-			errors.Is(err, kerrors.ErrUserNotFound) ||
-			errors.Is(err, kerrors.ErrDeviceNotFound) ||
-			errors.Is(err, kerrors.ErrFileNotFound) ||
-			errors.Is(err, kerrors.ErrInvalidFileType) {
-			return nil
+	if result != nil {
+		report.Success = true
+		report.DryRun = result.DryRun
+		report.AffectedUsers = append(report.AffectedUsers, result.UUIDsRevoked...)
+		if result.DryRun {
+			for _, file := range result.FilesToDelete {
+				report.Deleted = append(report.Deleted, file.Path)
+			}
+		} else {
+			report.Deleted = append(report.Deleted, result.RevokedFiles...)
 		}
-		return err
+	}
+	if errors.Is(err, kerrors.ErrDeviceNotFound) && revokeExpired {
+		// Nothing has expired, which a scheduled cleanup shouldn't treat as
+		// a failure.
+		report.Success = true
+		if jsonOutput() {
+			return printJSONResult(&workflows.RevokeResult{})
+		}
+		spinner.FinalMSG = formatRevokeError(err)
+		return nil
+	}
+	if err != nil && !errors.Is(err, kerrors.ErrSelfRevoke) {
+		report.fail(err)
+	}
+	if err != nil {
+		return reportCommandError(cmd, spinner, err, formatRevokeError(err))
+	}
+
+	if jsonOutput() {
+		return printJSONResult(result)
 	}
 
 	// Handle self-revoke warning (returned as result + error).
@@ -239,6 +364,10 @@ func formatRevokeError(err error) string {
 		}
 		return msg + "\n" + ui.Info.Sprint("→") + " No devices found for this user"
 
+	case errors.Is(err, kerrors.ErrDeviceNotFound) && revokeExpired:
+		return ui.Info.Sprint("ℹ") + " No devices have expired" +
+			"\n" + ui.Info.Sprint("→") + " Nothing was revoked"
+
 	case errors.Is(err, kerrors.ErrDeviceNotFound):
 		return ui.Error.Sprint("✗") + " Device not found" +
 			"\n" + ui.Info.Sprint("→") + " " + err.Error()
@@ -251,6 +380,10 @@ func formatRevokeError(err error) string {
 		return ui.Error.Sprint("✗") + " Invalid file type" +
 			"\n" + ui.Info.Sprint("→") + " " + err.Error()
 
+	case errors.Is(err, kerrors.ErrInvalidArguments):
+		return ui.Error.Sprint("✗") + " Nothing was revoked" +
+			"\n" + ui.Info.Sprint("→") + " " + err.Error()
+
 	case strings.Contains(err.Error(), "toml:"):
 		return ui.Error.Sprint("✗") + " Failed to load project configuration." +
 			"\n\n" + ui.Info.Sprint("→") + " The .kanuka/config.toml file is not valid TOML." +
@@ -275,6 +408,10 @@ func formatRevokeSuccess(result *workflows.RevokeResult) string {
 		finalMessage += ui.Highlight.Sprint(file)
 	}
 
+	if len(result.RevokedUsers) > 0 {
+		finalMessage += "\n" + ui.Info.Sprint("→") + " Revoked users: " + ui.Highlight.Sprint(strings.Join(result.RevokedUsers, ", "))
+	}
+
 	if result.RemainingUsers > 0 {
 		finalMessage += "\n" + ui.Info.Sprint("→") + " All secrets have been re-encrypted with a new key"
 	}
@@ -290,6 +427,14 @@ func printRevokeDryRunResult(result *workflows.RevokeResult) {
 	fmt.Println(ui.Warning.Sprint("[dry-run]") + " Would revoke access for " + ui.Highlight.Sprint(result.DisplayName))
 	fmt.Println()
 
+	if len(result.RevokedUsers) > 0 {
+		fmt.Println("Users that would be revoked:")
+		for _, email := range result.RevokedUsers {
+			fmt.Println("  - " + ui.Highlight.Sprint(email))
+		}
+		fmt.Println()
+	}
+
 	// List files that would be deleted.
 	fmt.Println("Files that would be deleted:")
 	for _, file := range result.FilesToDelete {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/internal/ui"
@@ -17,16 +18,40 @@ import (
 )
 
 var (
-	rotateForce bool
+	rotateForce        bool
+	rotateReportPath   string
+	rotateCheck        bool
+	rotateScheduleDays int
+	rotateDryRun       bool
+	rotateReencrypt    bool
+	// rotateExitFunc is the function called to exit with a specific code.
+	// Can be overridden for testing.
+	rotateExitFunc = os.Exit
 )
 
 func init() {
 	rotateCmd.Flags().BoolVar(&rotateForce, "force", false, "skip confirmation prompt")
+	rotateCmd.Flags().StringVar(&rotateReportPath, "report", "", "also write a JSON summary of the result to this file")
+	rotateCmd.Flags().BoolVar(&rotateCheck, "check", false, "exit non-zero if your keypair is due for rotation")
+	rotateCmd.Flags().IntVar(&rotateScheduleDays, "schedule", 0, "set how many days between rotations (0 clears the schedule)")
+	rotateCmd.Flags().BoolVar(&rotateDryRun, "dry-run", false, "preview the rotation without making changes")
+	rotateCmd.Flags().BoolVar(&rotateReencrypt, "reencrypt-only", false, "re-encrypt every .kanuka file with fresh nonces, keeping the same symmetric key and keypair")
 }
 
 // resetRotateCommandState resets the rotate command's global state for testing.
 func resetRotateCommandState() {
 	rotateForce = false
+	rotateReportPath = ""
+	rotateCheck = false
+	rotateScheduleDays = 0
+	rotateDryRun = false
+	rotateReencrypt = false
+	rotateExitFunc = os.Exit
+}
+
+// SetRotateExitFunc sets the exit function for testing purposes.
+func SetRotateExitFunc(f func(int)) {
+	rotateExitFunc = f
 }
 
 // confirmRotate prompts the user to confirm the keypair rotation.
@@ -72,38 +97,97 @@ After running this command:
   - Other users do NOT need to take any action
   - You should commit the updated .kanuka/public_keys/<uuid>.pub file
 
+Use --dry-run to check that your key can be rotated and see which files would
+change, without changing anything.
+
+Use --report to also write a JSON summary of the rotation to a file.
+
+Use --reencrypt-only to re-encrypt every .kanuka file with fresh nonces
+instead, for example after a suspicious file was committed. Your keypair, the
+project's symmetric key, and everyone's wrapped keys are left unchanged, so
+nobody's access changes. It doesn't prompt for confirmation.
+
+Use --schedule to record how many days should pass between rotations, and
+--check to test whether that interval has elapsed. --check only reads your key
+metadata, so it works without decrypt access and exits with code 1 when a
+rotation is due, which makes it suitable as a CI reminder.
+
 Examples:
   # Rotate your keypair (with confirmation prompt)
   kanuka secrets rotate
 
   # Rotate without confirmation prompt
-  kanuka secrets rotate --force`,
+  kanuka secrets rotate --force
+
+  # Preview the rotation without making changes
+  kanuka secrets rotate --dry-run
+
+  # Re-encrypt all secrets with fresh nonces, keeping the same key
+  kanuka secrets rotate --reencrypt-only
+
+  # Rotate in CI and save a JSON report
+  kanuka secrets rotate --force --report rotate-report.json
+
+  # Rotate every 90 days, and fail CI once that has elapsed
+  kanuka secrets rotate --schedule 90
+  kanuka secrets rotate --check`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if rotateCheck && cmd.Flags().Changed("schedule") {
+			return fmt.Errorf("%w: --check and --schedule cannot be used together", kerrors.ErrInvalidArguments)
+		}
+		if rotateReencrypt && (rotateCheck || cmd.Flags().Changed("schedule")) {
+			return fmt.Errorf("%w: --reencrypt-only cannot be used with --check or --schedule", kerrors.ErrInvalidArguments)
+		}
+		if rotateCheck {
+			return runRotateCheck(cmd)
+		}
+		if rotateReencrypt {
+			return runRotateReencrypt(cmd)
+		}
+		if cmd.Flags().Changed("schedule") {
+			return runRotateSchedule(cmd)
+		}
+
 		Logger.Infof("Starting rotate command")
 		spinner, cleanup := startSpinner("Rotating keypair...", verbose)
 		defer cleanup()
 
-		// Confirmation prompt (unless --force) - must happen before workflow.
-		if !rotateForce {
+		report := newCommandReport("rotate")
+		report.DryRun = rotateDryRun
+		defer writeCommandReport(rotateReportPath, report, spinner)
+
+		// Confirmation prompt (unless --force or --dry-run) - must happen before workflow.
+		if !rotateForce && !rotateDryRun {
 			if !confirmRotate(spinner) {
+				report.fail(errors.New("rotation cancelled"))
 				spinner.FinalMSG = ui.Warning.Sprint("⚠") + " Keypair rotation cancelled."
 				return nil
 			}
 		}
 
 		opts := workflows.RotateOptions{
-			Force: rotateForce,
+			Force:  rotateForce,
+			DryRun: rotateDryRun,
 		}
 
 		result, err := workflows.Rotate(context.Background(), opts)
 		if err != nil {
-			spinner.FinalMSG = formatRotateError(err)
-			if isUnexpectedError(err) {
-				return err
-			}
+			report.fail(err)
+			return reportCommandError(cmd, spinner, err, formatRotateError(err))
+		}
+
+		report.Success = true
+		report.AffectedUsers = append(report.AffectedUsers, result.UserUUID)
+
+		if result.DryRun {
+			spinner.FinalMSG = ""
+			spinner.Stop()
+			printRotateDryRun(result)
 			return nil
 		}
 
+		report.Updated = append(report.Updated, result.PrivateKeyPath, result.PublicKeyPath, result.ProjectPublicKeyPath)
+
 		finalMessage := ui.Success.Sprint("✓") + " Keypair rotated successfully\n\n" +
 			"Your new public key has been added to the project.\n" +
 			"Other users do not need to take any action.\n\n" +
@@ -113,6 +197,143 @@ Examples:
 	},
 }
 
+// printRotateDryRun shows what rotating the keypair would change.
+func printRotateDryRun(result *workflows.RotateResult) {
+	fmt.Println(ui.Warning.Sprint("[dry-run]") + " Would rotate your keypair for this project")
+	fmt.Println()
+
+	fmt.Println("Actions:")
+	fmt.Println("  - Generate a new RSA keypair")
+	fmt.Println("  - Re-encrypt the symmetric key for " + ui.Highlight.Sprint(result.UserUUID) + " with the new public key")
+	fmt.Println()
+
+	fmt.Println("Files that would be updated:")
+	for _, path := range []string{result.PrivateKeyPath, result.PublicKeyPath, result.ProjectPublicKeyPath, result.KanukaKeyPath} {
+		fmt.Println("  - " + ui.Path.Sprint(path))
+	}
+	fmt.Println()
+
+	fmt.Println("Unaffected:")
+	fmt.Printf("  - %d other user(s) keep their access\n", len(result.OtherUsers))
+	for _, user := range result.OtherUsers {
+		fmt.Println("      " + ui.Highlight.Sprint(user))
+	}
+	fmt.Printf("  - %d secret file(s) are not re-encrypted\n", result.KanukaFilesCount)
+	fmt.Println()
+
+	fmt.Println("Prerequisites verified:")
+	fmt.Println("  " + ui.Success.Sprint("✓") + " Your current private key decrypts the symmetric key")
+	fmt.Println()
+
+	fmt.Println(ui.Info.Sprint("No changes made.") + " Run without --dry-run to execute.")
+}
+
+// runRotateReencrypt re-encrypts every .kanuka file with the current
+// symmetric key, without rotating anything.
+func runRotateReencrypt(cmd *cobra.Command) error {
+	Logger.Infof("Re-encrypting secrets with the current symmetric key")
+	spinner, cleanup := startSpinner("Re-encrypting secret files...", verbose)
+	defer cleanup()
+
+	report := newCommandReport("rotate")
+	report.DryRun = rotateDryRun
+	defer writeCommandReport(rotateReportPath, report, spinner)
+
+	result, err := workflows.Reencrypt(cmd.Context(), workflows.ReencryptOptions{
+		DryRun: rotateDryRun,
+	})
+	if err != nil {
+		report.fail(err)
+		return reportCommandError(cmd, spinner, err, formatRotateError(err))
+	}
+
+	report.Success = true
+	if result.DryRun {
+		spinner.FinalMSG = ""
+		spinner.Stop()
+		fmt.Println(ui.Warning.Sprint("[dry-run]") + fmt.Sprintf(" Would re-encrypt %d secret file(s) with fresh nonces", len(result.Files)))
+		fmt.Println()
+		fmt.Println("Files that would be re-encrypted:")
+		for _, path := range result.Files {
+			fmt.Println("  - " + ui.Path.Sprint(path))
+		}
+		fmt.Println()
+		fmt.Println("Unaffected:")
+		fmt.Println("  - The symmetric key, your keypair, and every user's access")
+		fmt.Println()
+		fmt.Println(ui.Info.Sprint("No changes made.") + " Run without --dry-run to execute.")
+		return nil
+	}
+
+	report.Updated = append(report.Updated, result.Files...)
+	spinner.FinalMSG = ui.Success.Sprint("✓") + fmt.Sprintf(" Re-encrypted %d secret file(s) with fresh nonces\n\n", len(result.Files)) +
+		"The symmetric key and everyone's access are unchanged.\n\n" +
+		ui.Info.Sprint("→") + " Commit the updated " + ui.Path.Sprint(".kanuka") + " files"
+	return nil
+}
+
+// runRotateSchedule records the rotation interval without rotating the keypair.
+func runRotateSchedule(cmd *cobra.Command) error {
+	Logger.Infof("Setting rotation schedule to %d days", rotateScheduleDays)
+	spinner, cleanup := startSpinner("Saving rotation schedule...", verbose)
+	defer cleanup()
+
+	status, err := workflows.SetRotationSchedule(cmd.Context(), workflows.RotationScheduleOptions{
+		IntervalDays: rotateScheduleDays,
+	})
+	if err != nil {
+		Logger.Errorf("Failed to set rotation schedule: %v", err)
+		return reportCommandError(cmd, spinner, err, formatRotateError(err))
+	}
+
+	if status.IntervalDays == 0 {
+		spinner.FinalMSG = ui.Success.Sprint("✓") + " Rotation schedule cleared"
+		return nil
+	}
+
+	spinner.FinalMSG = ui.Success.Sprint("✓") + fmt.Sprintf(" Keypair rotation scheduled every %d days\n", status.IntervalDays) +
+		ui.Info.Sprint("→") + " Next rotation due " + ui.Highlight.Sprint(status.DueAt.Format("2006-01-02"))
+	return nil
+}
+
+// runRotateCheck reports whether the keypair is due for rotation and exits
+// non-zero if it is.
+func runRotateCheck(cmd *cobra.Command) error {
+	Logger.Infof("Checking rotation schedule")
+	spinner, cleanup := startSpinner("Checking rotation schedule...", verbose)
+	defer cleanup()
+
+	status, err := workflows.CheckRotation(cmd.Context())
+	if err != nil {
+		Logger.Errorf("Failed to check rotation schedule: %v", err)
+		return reportCommandError(cmd, spinner, err, formatRotateError(err))
+	}
+
+	lastRotated := status.LastRotatedAt.Format("2006-01-02")
+
+	if status.IntervalDays == 0 {
+		spinner.FinalMSG = ui.Info.Sprint("ℹ") + " No rotation schedule set (last rotated " + lastRotated + ")\n" +
+			ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets rotate --schedule <days>") + " to set one"
+		return nil
+	}
+
+	if !status.Due {
+		daysLeft := int(time.Until(status.DueAt).Hours() / 24)
+		spinner.FinalMSG = ui.Success.Sprint("✓") + fmt.Sprintf(" Keypair rotation not due for %d days", daysLeft) +
+			" (last rotated " + lastRotated + ")"
+		return nil
+	}
+
+	Logger.Infof("Keypair rotation overdue since %s", status.DueAt)
+	// Print before exiting, since the deferred cleanup won't run.
+	spinner.FinalMSG = ""
+	spinner.Stop()
+	fmt.Println(ui.Warning.Sprint("⚠") + fmt.Sprintf(" Keypair rotation is overdue: last rotated %s, interval is %d days\n", lastRotated, status.IntervalDays) +
+		ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets rotate") + " to rotate your keypair")
+	rotateExitFunc(1)
+	return nil
+}
+
 // formatRotateError formats workflow errors into user-friendly messages.
 func formatRotateError(err error) string {
 	switch {
@@ -132,26 +353,19 @@ func formatRotateError(err error) string {
 		return ui.Error.Sprint("✗") + " Failed to decrypt your Kanuka key\n" +
 			ui.Error.Sprint("Error: ") + err.Error()
 
+	case errors.Is(err, kerrors.ErrInvalidArguments):
+		return ui.Error.Sprint("✗") + " " + err.Error()
+
+	case errors.Is(err, kerrors.ErrNoFilesFound):
+		return ui.Error.Sprint("✗") + " No encrypted environment (" + ui.Path.Sprint(".kanuka") + ") files found\n" +
+			ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets encrypt") + " first"
+
+	case errors.Is(err, kerrors.ErrDecryptFailed):
+		return ui.Error.Sprint("✗") + " Failed to re-encrypt secret files\n" +
+			ui.Error.Sprint("Error: ") + err.Error()
+
 	default:
 		return ui.Error.Sprint("✗") + " Failed to rotate keypair\n" +
 			ui.Error.Sprint("Error: ") + err.Error()
 	}
 }
-
-// isUnexpectedError returns true if the error is unexpected and should cause a non-zero exit.
-func isUnexpectedError(err error) bool {
-	// Expected errors (user-facing issues) that should exit cleanly.
-	expectedErrors := []error{
-		kerrors.ErrProjectNotInitialized,
-		kerrors.ErrNoAccess,
-		kerrors.ErrPrivateKeyNotFound,
-		kerrors.ErrKeyDecryptFailed,
-	}
-
-	for _, expected := range expectedErrors {
-		if errors.Is(err, expected) {
-			return false
-		}
-	}
-	return true
-}
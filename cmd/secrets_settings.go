@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/internal/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// applyProjectFlagDefaults sets the flags of cmd that weren't given on the
+// command line from the project's .kanuka/settings.toml: the top-level keys
+// for every command, and the keys in the table named after cmd's secrets
+// subcommand. Flags given explicitly always win.
+//
+// It returns a warning for each key that doesn't name a flag, since a typo
+// would otherwise be silently ignored. It returns an error if the file is
+// invalid or a value isn't valid for its flag.
+func applyProjectFlagDefaults(cmd *cobra.Command) ([]string, error) {
+	projectPath, err := utils.FindProjectKanukaRoot()
+	if err != nil || projectPath == "" {
+		// Not in a project, so there are no project settings.
+		return nil, nil
+	}
+
+	defaults, err := configs.LoadFlagDefaults(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := secretsCommandOf(cmd)
+	var warnings []string
+	for _, name := range sortedSettingKeys(defaults.Global) {
+		if secrets.PersistentFlags().Lookup(name) == nil {
+			warnings = append(warnings, fmt.Sprintf("Unknown setting %q in %s", name, configs.FlagDefaultsFileName))
+			continue
+		}
+		if err := setFlagDefault(cmd.Flags().Lookup(name), defaults.Global[name]); err != nil {
+			return warnings, err
+		}
+	}
+
+	current := secretsSubcommandName(secrets, cmd)
+	for _, commandName := range sortedSettingKeys(defaults.Commands) {
+		sub := findSubcommand(secrets, commandName)
+		if sub == nil {
+			warnings = append(warnings, fmt.Sprintf("Unknown command [%s] in %s", commandName, configs.FlagDefaultsFileName))
+			continue
+		}
+		flags := defaults.Commands[commandName]
+		for _, name := range sortedSettingKeys(flags) {
+			if !commandHasFlag(secrets, sub, name) {
+				warnings = append(warnings, fmt.Sprintf("Unknown setting %q for [%s] in %s", name, commandName, configs.FlagDefaultsFileName))
+				continue
+			}
+			if commandName != current {
+				continue
+			}
+			if err := setFlagDefault(cmd.Flags().Lookup(name), flags[name]); err != nil {
+				return warnings, err
+			}
+		}
+	}
+
+	return warnings, nil
+}
+
+// setFlagDefault sets flag to values unless it was given on the command line.
+// The flag is not marked as changed, so commands still treat it as a default.
+func setFlagDefault(flag *pflag.Flag, values []string) error {
+	if flag == nil || flag.Changed {
+		return nil
+	}
+	for _, value := range values {
+		if err := flag.Value.Set(value); err != nil {
+			return fmt.Errorf("invalid value %q for %s in %s: %w", value, flag.Name, configs.FlagDefaultsFileName, err)
+		}
+	}
+	return nil
+}
+
+// secretsCommandOf returns the secrets command that cmd is nested under: the
+// ancestor just below the root command. SecretsCmd can't be referenced here,
+// since its PersistentPreRunE calls applyProjectFlagDefaults.
+func secretsCommandOf(cmd *cobra.Command) *cobra.Command {
+	c := cmd
+	for c.HasParent() && c.Parent().HasParent() {
+		c = c.Parent()
+	}
+	return c
+}
+
+// secretsSubcommandName returns the name of the direct child of secrets that
+// cmd is or is nested under, or "" for secrets itself.
+func secretsSubcommandName(secrets, cmd *cobra.Command) string {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Parent() == secrets {
+			return c.Name()
+		}
+	}
+	return ""
+}
+
+// findSubcommand returns the direct child of parent called name.
+func findSubcommand(parent *cobra.Command, name string) *cobra.Command {
+	for _, sub := range parent.Commands() {
+		if sub.Name() == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+// commandHasFlag reports whether cmd or any of its subcommands has a flag
+// called name, including the persistent flags of secrets.
+func commandHasFlag(secrets, cmd *cobra.Command, name string) bool {
+	if cmd.Flags().Lookup(name) != nil || cmd.PersistentFlags().Lookup(name) != nil ||
+		secrets.PersistentFlags().Lookup(name) != nil {
+		return true
+	}
+	for _, sub := range cmd.Commands() {
+		if commandHasFlag(secrets, sub, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedSettingKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -27,6 +27,7 @@ func resetStatusCommandState() {
 // statusJSONResult holds the JSON-serializable status result.
 type statusJSONResult struct {
 	ProjectName string            `json:"project"`
+	Access      string            `json:"access"`
 	Files       []statusJSONFile  `json:"files"`
 	Summary     statusJSONSummary `json:"summary"`
 }
@@ -56,24 +57,21 @@ Each file can have one of four statuses:
   - unencrypted:    Plaintext exists with no encrypted version (security risk)
   - encrypted_only: Encrypted exists with no plaintext (normal after cleanup)
 
+It also shows whether your private key can decrypt the project's secrets.
+
 Use --json for machine-readable output.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		Logger.Infof("Starting status command")
 
-		spinner, cleanup := startSpinner("Checking file statuses...", verbose)
+		spinner, cleanup := startReportSpinner("Checking file statuses...", verbose)
 		defer cleanup()
 
 		result, err := workflows.Status(context.Background(), workflows.StatusOptions{})
 		if err != nil {
 			if statusJSONOutput {
-				fmt.Printf(`{"error": "%s"}`+"\n", formatStatusErrorJSON(err))
-				return nil
-			}
-			spinner.FinalMSG = formatStatusError(err)
-			if isStatusUnexpectedError(err) {
-				return err
+				return reportJSONError(cmd, spinner, err)
 			}
-			return nil
+			return reportCommandError(cmd, spinner, err, formatStatusError(err))
 		}
 
 		// Output results.
@@ -110,40 +108,12 @@ func formatStatusError(err error) string {
 	}
 }
 
-// formatStatusErrorJSON formats errors for JSON output.
-func formatStatusErrorJSON(err error) string {
-	switch {
-	case errors.Is(err, kerrors.ErrProjectNotInitialized):
-		return "Kanuka has not been initialized"
-
-	case errors.Is(err, kerrors.ErrInvalidProjectConfig):
-		return "Failed to load project configuration: config.toml is not valid TOML"
-
-	default:
-		return err.Error()
-	}
-}
-
-// isStatusUnexpectedError returns true if the error is unexpected and should cause a non-zero exit.
-func isStatusUnexpectedError(err error) bool {
-	expectedErrors := []error{
-		kerrors.ErrProjectNotInitialized,
-		kerrors.ErrInvalidProjectConfig,
-	}
-
-	for _, expected := range expectedErrors {
-		if errors.Is(err, expected) {
-			return false
-		}
-	}
-	return true
-}
-
 // outputStatusJSON outputs the result as JSON.
 func outputStatusJSON(result *workflows.StatusResult) error {
 	// Convert to JSON-serializable format.
 	jsonResult := statusJSONResult{
 		ProjectName: result.ProjectName,
+		Access:      string(result.Access),
 		Files:       make([]statusJSONFile, len(result.Files)),
 		Summary: statusJSONSummary{
 			Current:       result.Summary.Current,
@@ -167,9 +137,34 @@ func outputStatusJSON(result *workflows.StatusResult) error {
 	return encoder.Encode(jsonResult)
 }
 
+// formatStatusAccess describes the user's access, with a hint when they lack it.
+func formatStatusAccess(result *workflows.StatusResult) string {
+	switch result.Access {
+	case workflows.AccessGranted:
+		return ui.Success.Sprint("✓") + " your private key can decrypt this project"
+	case workflows.AccessUnchecked:
+		return ui.Muted.Sprint("◌") + " not checked (private key is passphrase-protected or GPG-wrapped)"
+	}
+
+	switch {
+	case errors.Is(result.AccessError, kerrors.ErrNoAccess):
+		return ui.Error.Sprint("✗") + " you don't have access to this project" +
+			"\n         " + ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets create") +
+			", then ask someone with access to run " + ui.Code.Sprint("kanuka secrets register")
+	case errors.Is(result.AccessError, kerrors.ErrPrivateKeyNotFound):
+		return ui.Error.Sprint("✗") + " your private key for this project was not found"
+	case errors.Is(result.AccessError, kerrors.ErrKeyDecryptFailed):
+		return ui.Error.Sprint("✗") + " your private key does not match your registered key" +
+			"\n         " + ui.Info.Sprint("→") + " Ask someone with access to run " + ui.Code.Sprint("kanuka secrets register") + " again"
+	default:
+		return ui.Warning.Sprint("⚠") + " unknown"
+	}
+}
+
 // printStatusTable prints a formatted table of file statuses.
 func printStatusTable(result *workflows.StatusResult) {
 	fmt.Printf("Project: %s\n", ui.Highlight.Sprint(result.ProjectName))
+	fmt.Println("Access:  " + formatStatusAccess(result))
 	fmt.Println()
 
 	if len(result.Files) == 0 {
@@ -47,11 +47,7 @@ Use --dry-run to preview what would happen without making changes.`,
 
 		result, err := workflows.Sync(context.Background(), opts)
 		if err != nil {
-			spinner.FinalMSG = formatSyncError(err)
-			if isSyncUnexpectedError(err) {
-				return err
-			}
-			return nil
+			return reportCommandError(cmd, spinner, err, formatSyncError(err))
 		}
 
 		// Display results.
@@ -97,22 +93,6 @@ func formatSyncError(err error) string {
 	}
 }
 
-// isSyncUnexpectedError returns true if the error is unexpected and should cause a non-zero exit.
-func isSyncUnexpectedError(err error) bool {
-	expectedErrors := []error{
-		kerrors.ErrProjectNotInitialized,
-		kerrors.ErrPrivateKeyNotFound,
-		kerrors.ErrKeyDecryptFailed,
-	}
-
-	for _, expected := range expectedErrors {
-		if errors.Is(err, expected) {
-			return false
-		}
-	}
-	return true
-}
-
 // printSyncDryRun displays what would happen during a sync operation.
 func printSyncDryRun(result *workflows.SyncResult) {
 	fmt.Println()
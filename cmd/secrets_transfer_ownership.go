@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/utils"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	transferOwnershipUserEmail       string
+	transferOwnershipStepDown        bool
+	transferOwnershipPrivateKeyStdin bool
+)
+
+func init() {
+	transferOwnershipCmd.Flags().StringVarP(&transferOwnershipUserEmail, "user", "u", "", "email of the user who becomes an owner")
+	transferOwnershipCmd.Flags().BoolVar(&transferOwnershipStepDown, "step-down", false, "remove yourself from the owners after the transfer")
+	transferOwnershipCmd.Flags().BoolVar(&transferOwnershipPrivateKeyStdin, "private-key-stdin", false, "read private key from stdin instead of from disk")
+}
+
+// resetTransferOwnershipCommandState resets the transfer-ownership command's
+// global state for testing.
+func resetTransferOwnershipCommandState() {
+	transferOwnershipUserEmail = ""
+	transferOwnershipStepDown = false
+	transferOwnershipPrivateKeyStdin = false
+}
+
+var transferOwnershipCmd = &cobra.Command{
+	Use:   "transfer-ownership",
+	Short: "Make another user an owner of the project",
+	Long: `Adds a user to the project's owners (project.owners in
+.kanuka/config.toml) and re-wraps the symmetric key for each of their
+devices, so they can manage the project after you leave.
+
+You need access to the project, and the user must already be registered.
+Once a project has owners, only an owner can run transfer-ownership. The
+first transfer records you as an owner too, unless you pass --step-down.
+
+Examples:
+  # Make bob an owner alongside you
+  kanuka secrets transfer-ownership --user bob@example.com
+
+  # Hand the project over to bob and give up your own ownership
+  kanuka secrets transfer-ownership --user bob@example.com --step-down`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		Logger.Infof("Starting transfer-ownership command")
+		spinner, cleanup := startSpinner("Transferring ownership...", verbose)
+		defer cleanup()
+
+		if transferOwnershipUserEmail == "" {
+			finalMessage := ui.Error.Sprint("✗") + " The " + ui.Flag.Sprint("--user") + " flag is required" +
+				"\n" + ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka secrets transfer-ownership --user <email>")
+			return reportCommandError(cmd, spinner, fmt.Errorf("%w: --user is required", kerrors.ErrInvalidArguments), finalMessage)
+		}
+
+		opts := workflows.TransferOwnershipOptions{
+			UserEmail: transferOwnershipUserEmail,
+			StepDown:  transferOwnershipStepDown,
+		}
+
+		if transferOwnershipPrivateKeyStdin {
+			Logger.Debugf("Reading private key from stdin")
+			keyData, err := utils.ReadStdin()
+			if err != nil {
+				Logger.Errorf("Failed to read private key from stdin: %v", err)
+				finalMessage := ui.Error.Sprint("✗") + " Failed to read private key from stdin" +
+					"\n" + ui.Error.Sprint("Error: ") + err.Error()
+				return reportCommandError(cmd, spinner, err, finalMessage)
+			}
+			opts.PrivateKeyData = keyData
+		}
+
+		result, err := workflows.TransferOwnership(context.Background(), opts)
+		if err != nil {
+			Logger.Errorf("Transfer ownership failed: %v", err)
+			return reportCommandError(cmd, spinner, err, formatTransferOwnershipError(err, transferOwnershipUserEmail))
+		}
+
+		Logger.Infof("Transferred ownership to %s, owners are now %v", result.UserEmail, result.Owners)
+		spinner.FinalMSG = formatTransferOwnershipSuccess(result)
+		return nil
+	},
+}
+
+// formatTransferOwnershipSuccess describes the new owners and the key files
+// written for the new owner.
+func formatTransferOwnershipSuccess(result *workflows.TransferOwnershipResult) string {
+	message := ui.Success.Sprint("✓") + " " + ui.Highlight.Sprint(result.UserEmail) + " is now an owner of this project" +
+		"\n  owners: " + strings.Join(result.Owners, ", ")
+
+	projectPath := configs.ProjectKanukaSettings.ProjectPath
+	relative := func(path string) string {
+		if rel, err := filepath.Rel(projectPath, path); err == nil {
+			return rel
+		}
+		return path
+	}
+	for _, path := range result.FilesCreated {
+		message += "\n  created: " + ui.Path.Sprint(relative(path))
+	}
+	for _, path := range result.FilesUpdated {
+		message += "\n  updated: " + ui.Path.Sprint(relative(path))
+	}
+	if len(result.SkippedUUIDs) > 0 {
+		message += "\n" + ui.Warning.Sprint("⚠") + fmt.Sprintf(" Skipped %d device(s) with no public key", len(result.SkippedUUIDs))
+	}
+	return message
+}
+
+// formatTransferOwnershipError formats workflow errors into user-friendly
+// messages.
+func formatTransferOwnershipError(err error, userEmail string) string {
+	switch {
+	case errors.Is(err, kerrors.ErrNotProjectOwner):
+		return ui.Error.Sprint("✗") + " Only a project owner can transfer ownership" +
+			"\n" + ui.Info.Sprint("→") + " Ask one of the owners in " + ui.Code.Sprint("kanuka config get project.owners") + " to run this command"
+
+	default:
+		return formatRekeyError(err, userEmail)
+	}
+}
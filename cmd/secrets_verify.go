@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/utils"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyPrivateKeyStdin bool
+
+	// verifyExitFunc is the function called to exit with a specific code.
+	// It can be overridden in tests to capture exit codes.
+	verifyExitFunc = os.Exit
+)
+
+func init() {
+	verifyCmd.Flags().BoolVar(&verifyPrivateKeyStdin, "private-key-stdin", false, "read private key from stdin instead of from disk")
+}
+
+func resetVerifyCommandState() {
+	verifyPrivateKeyStdin = false
+	verifyExitFunc = os.Exit
+}
+
+// SetVerifyExitFunc sets the exit function for testing purposes.
+func SetVerifyExitFunc(f func(int)) {
+	verifyExitFunc = f
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that every encrypted file and key in the project is valid",
+	Long: `Checks that the project's encrypted files and keys are valid, without
+writing any plaintext to disk.
+
+Using your private key, this decrypts your copy of the project's symmetric
+key and then every .kanuka file, reporting which ones fail. Files listed in
+.kanuka/ignore are checked too.
+
+Other users' wrapped keys can only be decrypted with their private keys, so
+they are checked for the shape expected for their public key and reported as
+unchecked. A wrapped key with no matching public key fails.
+
+Exits non-zero if anything fails, so CI can gate on it.
+
+Examples:
+  # Verify all encrypted files and keys
+  kanuka secrets verify
+
+  # Verify in CI using a key from a secret manager
+  echo "$KANUKA_KEY" | kanuka secrets verify --private-key-stdin`,
+	RunE: runVerify,
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	Logger.Infof("Starting verify command")
+	spinner, cleanup := startSpinner("Verifying encrypted files...", verbose)
+	defer cleanup()
+
+	opts := workflows.VerifyOptions{}
+	if verifyPrivateKeyStdin {
+		Logger.Debugf("Reading private key from stdin")
+		keyData, err := utils.ReadStdin()
+		if err != nil {
+			Logger.Errorf("Failed to read private key from stdin: %v", err)
+			return reportCommandError(cmd, spinner, err, ui.Error.Sprint("✗")+" Failed to read private key from stdin: "+err.Error())
+		}
+		opts.PrivateKeyData = keyData
+	}
+
+	result, err := workflows.Verify(cmd.Context(), opts)
+	if err != nil {
+		Logger.Errorf("Verify workflow failed: %v", err)
+		// Nothing could be verified, which must not pass a CI gate.
+		return reportCommandError(cmd, spinner, err, formatVerifyError(err, verifyPrivateKeyStdin))
+	}
+
+	Logger.Infof("Verify command completed: %d files, %d keys, %d failed", len(result.Files), len(result.Keys), result.Failed)
+
+	spinner.FinalMSG = ""
+	spinner.Stop()
+	if jsonOutput() {
+		if err := printJSONResult(result); err != nil {
+			return err
+		}
+	} else {
+		printVerifyResult(result)
+	}
+
+	if result.Failed > 0 {
+		verifyExitFunc(kerrors.ExitCrypto)
+	}
+	return nil
+}
+
+// printVerifyResult prints a checklist of files and keys with a final count.
+func printVerifyResult(result *workflows.VerifyResult) {
+	fmt.Println("Encrypted files:")
+	for _, file := range result.Files {
+		printVerifyLine(file.Status, ui.Path.Sprint(file.Path), file.Error)
+	}
+
+	fmt.Println()
+	fmt.Println("Wrapped keys:")
+	for _, key := range result.Keys {
+		name := key.Email
+		if name == "" {
+			name = key.UUID
+		}
+		if key.Current {
+			name += " (you)"
+		}
+		printVerifyLine(key.Status, ui.Highlight.Sprint(name), key.Error)
+	}
+
+	fmt.Println()
+	checked := len(result.Files) + len(result.Keys)
+	if result.Failed > 0 {
+		fmt.Println(ui.Error.Sprint("✗") + fmt.Sprintf(" %d of %d checks failed", result.Failed, checked))
+		return
+	}
+	fmt.Println(ui.Success.Sprint("✓") + fmt.Sprintf(" All %d checks passed", checked))
+}
+
+// printVerifyLine prints one checklist entry.
+func printVerifyLine(status workflows.VerifyStatus, name, reason string) {
+	switch status {
+	case workflows.VerifyOK:
+		fmt.Printf("  %s %s\n", ui.Success.Sprint("✓"), name)
+	case workflows.VerifyUnchecked:
+		fmt.Printf("  %s %s %s\n", ui.Muted.Sprint("-"), name, ui.Muted.Sprint("(needs their private key to decrypt)"))
+	default:
+		fmt.Printf("  %s %s %s\n", ui.Error.Sprint("✗"), name, ui.Error.Sprint(reason))
+	}
+}
+
+// formatVerifyError formats workflow errors into user-friendly messages.
+func formatVerifyError(err error, fromStdin bool) string {
+	switch {
+	case errors.Is(err, kerrors.ErrNoFilesFound):
+		return ui.Error.Sprint("✗") + " No encrypted environment (.kanuka) files found"
+
+	case errors.Is(err, kerrors.ErrInvalidKeyLength):
+		return ui.Error.Sprint("✗") + " Your copy of the project's symmetric key is invalid" +
+			"\n" + ui.Error.Sprint("Error: ") + err.Error() +
+			"\n\n" + ui.Info.Sprint("→") + " Ask someone with access to run " + ui.Code.Sprint("kanuka secrets sync")
+
+	default:
+		return formatDecryptError(err, fromStdin)
+	}
+}
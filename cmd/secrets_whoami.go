@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+
+	"github.com/spf13/cobra"
+)
+
+var whoamiJSONOutput bool
+
+func init() {
+	whoamiCmd.Flags().BoolVar(&whoamiJSONOutput, "json", false, "output in JSON format")
+}
+
+func resetWhoamiCommandState() {
+	whoamiJSONOutput = false
+}
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show which identity you are using and whether it has access",
+	Long: `Shows the email, user UUID, and default device from your user config.
+
+Inside a project, it also shows the device name your identity is registered
+under and whether you have access to decrypt the project's secrets. Access
+means the project has an encrypted key for your UUID; your private key isn't
+read, so you won't be prompted for a passphrase.
+
+Use --json for machine-readable output.
+
+Examples:
+  # Show your identity
+  kanuka secrets whoami
+
+  # Get your UUID in a script
+  kanuka secrets whoami --json | jq -r .uuid`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		Logger.Infof("Starting whoami command")
+
+		result, err := workflows.Whoami(context.Background(), workflows.WhoamiOptions{})
+		if err != nil {
+			Logger.Errorf("Whoami workflow failed: %v", err)
+			if errors.Is(err, kerrors.ErrInvalidProjectConfig) {
+				return reportPrintedError(cmd, err, formatAccessError(err))
+			}
+			return err
+		}
+
+		if whoamiJSONOutput {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(result)
+		}
+
+		printWhoami(result)
+		return nil
+	},
+}
+
+// printWhoami prints the identity and, inside a project, its access.
+func printWhoami(result *workflows.WhoamiResult) {
+	notSet := ui.Muted.Sprint("not set")
+
+	email := notSet
+	if result.Email != "" {
+		email = ui.Highlight.Sprint(result.Email)
+	}
+	defaultDevice := notSet
+	if result.DefaultDevice != "" {
+		defaultDevice = ui.Highlight.Sprint(result.DefaultDevice)
+	}
+
+	fmt.Printf("  %-16s %s\n", "Email:", email)
+	fmt.Printf("  %-16s %s\n", "User ID:", ui.Highlight.Sprint(result.UUID))
+	fmt.Printf("  %-16s %s\n", "Default Device:", defaultDevice)
+
+	if result.Email == "" {
+		fmt.Println()
+		fmt.Println(ui.Info.Sprint("→") + " Run " + ui.Code.Sprint("kanuka config init") + " to set your email")
+	}
+
+	fmt.Println()
+	if result.Project == nil {
+		fmt.Println(ui.Muted.Sprint("Not in a Kānuka project."))
+		return
+	}
+
+	project := result.Project
+	device := ui.Muted.Sprint("not registered")
+	if project.DeviceName != "" {
+		device = ui.Highlight.Sprint(project.DeviceName)
+	}
+	access := ui.Error.Sprint("✗") + " no access"
+	if project.HasAccess {
+		access = ui.Success.Sprint("✓") + " can decrypt"
+	}
+
+	fmt.Printf("  %-16s %s\n", "Project:", ui.Highlight.Sprint(project.Name))
+	fmt.Printf("  %-16s %s\n", "Device:", device)
+	fmt.Printf("  %-16s %s\n", "Access:", access)
+
+	if !project.HasAccess {
+		fmt.Println()
+		fmt.Println(ui.Info.Sprint("→") + " Ask someone with access to run " +
+			ui.Code.Sprint("kanuka secrets register --user <your-email>"))
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	logger "github.com/PolarWolf314/kanuka/internal/logging"
+
+	"github.com/spf13/cobra"
+)
+
+// userDirFlag is the value of --config-dir or --keys-dir. Like --color,
+// setting it applies the directory straight away, so every command's hooks
+// and workflows see the override.
+type userDirFlag struct {
+	dir   string
+	apply func(dir string) error
+}
+
+// String returns the directory, or "" if the flag wasn't given.
+func (f *userDirFlag) String() string {
+	return f.dir
+}
+
+// Set applies dir as the user directory.
+func (f *userDirFlag) Set(value string) error {
+	if err := f.apply(value); err != nil {
+		return err
+	}
+	f.dir = value
+	return nil
+}
+
+// Type returns the flag's type name for help output.
+func (f *userDirFlag) Type() string {
+	return "string"
+}
+
+var (
+	configDirFlag = userDirFlag{apply: configs.SetUserConfigsDir}
+	keysDirFlag   = userDirFlag{apply: configs.SetUserKeysDir}
+)
+
+// AddUserDirFlags adds the global --config-dir and --keys-dir flags to root.
+func AddUserDirFlags(root *cobra.Command) {
+	root.PersistentFlags().Var(&configDirFlag, "config-dir", "directory holding your user config.toml (overrides "+configs.ConfigDirEnvVar+")")
+	root.PersistentFlags().Var(&keysDirFlag, "keys-dir", "directory holding your private keys (overrides "+configs.KeysDirEnvVar+")")
+}
+
+// migrateUserConfig moves a config and keys left in ~/.kanuka by older
+// releases into the XDG directories. It runs from each command group's
+// pre-run hook, after --config-dir and --keys-dir have been applied, and warns
+// through log if the move fails.
+func migrateUserConfig(log logger.Logger) {
+	if err := configs.MigrateLegacyUserConfig(); err != nil {
+		log.WarnfUser("Could not move your user config and keys out of ~/.kanuka: %v", err)
+	}
+}
+
+// resetUserDirState clears --config-dir and --keys-dir for testing. It
+// doesn't restore the user settings, which tests set up themselves.
+func resetUserDirState() {
+	configDirFlag.dir = ""
+	keysDirFlag.dir = ""
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/ui"
+	"github.com/PolarWolf314/kanuka/internal/utils"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	// buildVersion is the version of the running binary, set via SetVersion.
+	buildVersion = "dev"
+
+	versionCheck          bool
+	versionFailIfOutdated bool
+	versionNoCache        bool
+
+	// versionReleasesURL overrides the releases API endpoint.
+	// Can be overridden for testing.
+	versionReleasesURL string
+
+	// versionExitFunc is the function called to exit with a specific code.
+	// Can be overridden for testing.
+	versionExitFunc = os.Exit
+)
+
+func init() {
+	VersionCmd.Flags().BoolVar(&versionCheck, "check", false, "check whether a newer release is available")
+	VersionCmd.Flags().BoolVar(&versionFailIfOutdated, "fail-if-outdated", false, "exit with code 1 if a newer release is available (implies --check)")
+	VersionCmd.Flags().BoolVar(&versionNoCache, "no-cache", false, "ignore any cached update check result")
+}
+
+// SetVersion sets the version reported by the version command.
+func SetVersion(v string) {
+	buildVersion = v
+}
+
+// SetVersionExitFunc sets the exit function for testing purposes.
+func SetVersionExitFunc(f func(int)) {
+	versionExitFunc = f
+}
+
+// SetVersionReleasesURL sets the releases API endpoint for testing purposes.
+func SetVersionReleasesURL(url string) {
+	versionReleasesURL = url
+}
+
+// ResetVersionState resets all version command global variables to their default values for testing.
+func ResetVersionState() {
+	versionCheck = false
+	versionFailIfOutdated = false
+	versionNoCache = false
+	versionReleasesURL = ""
+	versionExitFunc = os.Exit
+	VersionCmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		flag.Changed = false
+	})
+}
+
+// VersionCmd prints the running version and optionally checks for newer releases.
+var VersionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the Kānuka version",
+	Long: `Prints the version of Kānuka you are running.
+
+Use --check to query GitHub for the latest release and report whether a newer
+version is available. The check is best-effort with a short timeout, and the
+result is cached for an hour to stay within GitHub's API rate limits.
+
+Use --fail-if-outdated in CI to exit with code 1 when a newer release exists.
+
+Set KANUKA_NO_UPDATE_CHECK to any value to skip the network check entirely.
+
+Examples:
+  # Print the current version
+  kanuka version
+
+  # Check whether a newer release is available
+  kanuka version --check
+
+  # Fail a CI job if Kānuka is out of date
+  kanuka version --fail-if-outdated`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fmt.Printf("kanuka %s\n", buildVersion)
+
+		if !versionCheck && !versionFailIfOutdated {
+			return nil
+		}
+
+		result, err := workflows.CheckVersion(context.Background(), workflows.VersionCheckOptions{
+			CurrentVersion: buildVersion,
+			ReleasesURL:    versionReleasesURL,
+			NoCache:        versionNoCache,
+		})
+		if err != nil {
+			// The update check is best-effort and never fails the command.
+			fmt.Println(formatVersionError(err))
+			return nil
+		}
+
+		fmt.Println(formatVersionCheckResult(result))
+
+		if versionFailIfOutdated && result.UpdateAvailable {
+			versionExitFunc(1)
+		}
+		return nil
+	},
+}
+
+// formatVersionCheckResult formats the outcome of an update check.
+func formatVersionCheckResult(result *workflows.VersionCheckResult) string {
+	if result.Skipped {
+		return ui.Info.Sprint("ℹ") + " Update check skipped (" + ui.Code.Sprint(workflows.NoUpdateCheckEnvVar) + " is set)"
+	}
+
+	if result.UpdateAvailable {
+		msg := ui.Warning.Sprint("⚠") + " A newer version of Kānuka is available: " +
+			ui.Highlight.Sprint(result.CurrentVersion) + " → " + ui.Highlight.Sprint(result.LatestVersion)
+		if result.ReleaseURL != "" {
+			msg += "\n" + ui.Info.Sprint("→") + " " + ui.Path.Sprint(result.ReleaseURL)
+		}
+		return msg
+	}
+
+	if !utils.IsReleaseVersion(result.CurrentVersion) {
+		return ui.Info.Sprint("ℹ") + " Development build; latest release is " + ui.Highlight.Sprint(result.LatestVersion)
+	}
+
+	return ui.Success.Sprint("✓") + " You are running the latest version (" + ui.Highlight.Sprint(result.LatestVersion) + ")"
+}
+
+// formatVersionError formats update check errors into user-friendly messages.
+func formatVersionError(err error) string {
+	switch {
+	case errors.Is(err, kerrors.ErrUpdateCheckFailed):
+		return ui.Warning.Sprint("⚠") + " Could not check for updates\n" +
+			"  " + ui.Muted.Sprint(err.Error())
+
+	default:
+		return ui.Error.Sprint("✗") + " Failed to check for updates\n" +
+			ui.Error.Sprint("Error: ") + err.Error()
+	}
+}
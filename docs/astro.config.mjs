@@ -45,17 +45,21 @@ export default defineConfig({
             "guides/register",
             "guides/revoke",
             "guides/sync",
+            "guides/merge-config",
             "guides/rotate",
             "guides/access",
             "guides/status",
+            "guides/files",
             "guides/clean",
             "guides/doctor",
+            "guides/verify",
             "guides/export",
             "guides/import",
             "guides/audit-log",
             "guides/log",
             "guides/monorepo",
             "guides/ci-init",
+            "guides/headless",
             "guides/github-action",
           ],
         },
@@ -1,9 +1,15 @@
 package audit
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
@@ -26,46 +32,156 @@ type Entry struct {
 	RemovedCount int      `json:"removed_count,omitempty"` // For clean.
 	Mode         string   `json:"mode,omitempty"`          // For import (merge/replace).
 	OutputPath   string   `json:"output_path,omitempty"`   // For export.
+	Encrypted    bool     `json:"encrypted,omitempty"`     // For export/import of encrypted archives.
 	ProjectName  string   `json:"project_name,omitempty"`  // For init.
 	ProjectUUID  string   `json:"project_uuid,omitempty"`  // For init.
-	DeviceName   string   `json:"device_name,omitempty"`   // For create.
+	DeviceName   string   `json:"device_name,omitempty"`   // For create and device register.
+
+	// Details holds arbitrary key/value pairs for custom operations.
+	Details map[string]string `json:"details,omitempty"`
+
+	// Note records anomalies observed when the entry was written, such as clock skew.
+	Note string `json:"note,omitempty"`
+
+	// PrevHash is the SHA-256 of the previous entry's canonical JSON, chaining
+	// entries together so edits to history can be detected. The first entry
+	// of a log has ChainStart instead, and entries written before the chain
+	// was introduced have none.
+	PrevHash string `json:"prev_hash,omitempty"`
+}
+
+// ChainStart is the PrevHash of the first entry in a log, marking where the
+// hash chain begins.
+const ChainStart = "chain-start"
+
+// TimestampFormat is the layout used for entry timestamps.
+const TimestampFormat = "2006-01-02T15:04:05.000000Z"
+
+// ParseTimestamp parses an entry timestamp, accepting both TimestampFormat and RFC3339.
+func ParseTimestamp(ts string) (time.Time, error) {
+	t, err := time.Parse(TimestampFormat, ts)
+	if err != nil {
+		t, err = time.Parse(time.RFC3339, ts)
+	}
+	return t, err
+}
+
+// IsFutureDated reports whether the entry's timestamp is implausibly far ahead of now.
+// Entries with unparseable timestamps are not considered future-dated.
+func (e Entry) IsFutureDated(now time.Time) bool {
+	t, err := ParseTimestamp(e.Timestamp)
+	if err != nil {
+		return false
+	}
+	return configs.IsFutureDated(t, now)
+}
+
+// BuiltinOperations lists the operation names recorded by Kānuka itself.
+// Custom entries may not use these names, so they can't be mistaken for real operations.
+var BuiltinOperations = []string{
+	"ci-init", "clean", "create", "decrypt", "encrypt", "export",
+	"import", "init", "prune", "reencrypt", "register", "rekey", "rekey-all", "revoke", "rotate", "sync",
+	"transfer-ownership",
+}
+
+// IsBuiltinOperation reports whether op is an operation recorded by Kānuka itself.
+func IsBuiltinOperation(op string) bool {
+	for _, builtin := range BuiltinOperations {
+		if op == builtin {
+			return true
+		}
+	}
+	return false
 }
 
 // Log appends an entry to the audit log.
 // If logging fails, it logs a warning but does not return an error.
 // Operations should not fail just because audit logging failed.
 func Log(entry Entry) {
-	// Set timestamp if not already set.
-	if entry.Timestamp == "" {
-		entry.Timestamp = time.Now().UTC().Format("2006-01-02T15:04:05.000000Z")
-	}
+	_ = Write(entry)
+}
 
-	// Get project path.
-	projectPath := configs.ProjectKanukaSettings.ProjectPath
-	if projectPath == "" {
-		// Project not initialized, skip logging.
-		return
+// Write appends an entry to the audit log, returning any error.
+// Use Log instead when a failure to record should not affect the caller.
+func Write(entry Entry) error {
+	batch := NewBatch()
+	batch.Add(entry)
+	return batch.Close()
+}
+
+// lastEntry returns the last entry in the log f. The file is read backwards
+// from the end, so this stays cheap for large logs.
+func lastEntry(f *os.File) (Entry, bool) {
+	info, err := f.Stat()
+	if err != nil || info.Size() == 0 {
+		return Entry{}, false
 	}
 
-	logPath := filepath.Join(projectPath, ".kanuka", "audit.jsonl")
+	// Read increasingly large tails until one holds the whole last line.
+	const tailSize = 4096
+	for size := int64(tailSize); ; size *= 2 {
+		offset := info.Size() - size
+		if offset < 0 {
+			offset = 0
+		}
 
-	// Open file for appending (create if doesn't exist).
-	// #nosec G306 -- audit log should be readable by team members.
-	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		// Log warning but don't fail the operation.
-		return
+		buf := make([]byte, info.Size()-offset)
+		if _, err := f.ReadAt(buf, offset); err != nil && err != io.EOF {
+			return Entry{}, false
+		}
+
+		buf = bytes.TrimRight(buf, "\n")
+		start := bytes.LastIndexByte(buf, '\n')
+		if start < 0 && offset > 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(buf[start+1:], &entry); err != nil {
+			return Entry{}, false
+		}
+		return entry, true
 	}
-	defer f.Close()
+}
 
-	// Marshal entry to JSON.
+// HashEntry returns the hex-encoded SHA-256 of an entry's canonical JSON,
+// which is how it is encoded in the log.
+func HashEntry(entry Entry) (string, error) {
 	data, err := json.Marshal(entry)
 	if err != nil {
-		return
+		return "", fmt.Errorf("encoding audit entry: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// SortByTime orders entries chronologically by their parsed timestamps.
+// The sort is stable, so entries written in the same instant keep their log
+// order. Entries with unparseable timestamps stay next to the entry that
+// preceded them in the log.
+func SortByTime(entries []Entry) {
+	keys := make([]time.Time, len(entries))
+	var prev time.Time
+	for i, e := range entries {
+		if t, err := ParseTimestamp(e.Timestamp); err == nil {
+			prev = t
+		}
+		keys[i] = prev
 	}
 
-	// Write entry with newline.
-	_, _ = f.Write(append(data, '\n'))
+	indices := make([]int, len(entries))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(a, b int) bool {
+		return keys[indices[a]].Before(keys[indices[b]])
+	})
+
+	sorted := make([]Entry, len(entries))
+	for i, idx := range indices {
+		sorted[i] = entries[idx]
+	}
+	copy(entries, sorted)
 }
 
 // LogWithUser is a convenience function that populates user fields from config.
@@ -93,39 +209,69 @@ func LogPath() string {
 	return filepath.Join(projectPath, ".kanuka", "audit.jsonl")
 }
 
+// ReadStats describes what was skipped while reading the audit log.
+type ReadStats struct {
+	// MalformedLines lists the 1-based line numbers that couldn't be parsed
+	// as an entry, in order.
+	MalformedLines []int
+}
+
+// Malformed returns the number of lines that couldn't be parsed.
+func (s ReadStats) Malformed() int {
+	return len(s.MalformedLines)
+}
+
 // ReadEntries reads all entries from the audit log.
 // Returns an empty slice if the log doesn't exist.
 func ReadEntries() ([]Entry, error) {
+	entries, _, err := ReadEntriesWithStats()
+	return entries, err
+}
+
+// ReadEntriesWithStats reads all entries from the audit log like ReadEntries,
+// and also reports which lines were skipped because they were malformed.
+func ReadEntriesWithStats() ([]Entry, ReadStats, error) {
 	logPath := LogPath()
 	if logPath == "" {
-		return nil, nil
+		return nil, ReadStats{}, nil
 	}
 
 	data, err := os.ReadFile(logPath)
 	if os.IsNotExist(err) {
-		return nil, nil
+		return nil, ReadStats{}, nil
 	}
 	if err != nil {
-		return nil, err
+		return nil, ReadStats{}, err
 	}
 
-	return ParseEntries(data)
+	entries, stats := ParseEntriesWithStats(data)
+	return entries, stats, nil
 }
 
 // ParseEntries parses JSON Lines data into audit entries.
 // Malformed lines are silently skipped.
 func ParseEntries(data []byte) ([]Entry, error) {
+	entries, _ := ParseEntriesWithStats(data)
+	return entries, nil
+}
+
+// ParseEntriesWithStats parses JSON Lines data into audit entries, recording
+// the line number of each malformed line it skips. Blank lines are ignored.
+func ParseEntriesWithStats(data []byte) ([]Entry, ReadStats) {
+	var stats ReadStats
 	if len(data) == 0 {
-		return nil, nil
+		return nil, stats
 	}
 
 	var entries []Entry
 	start := 0
+	lineNumber := 0
 
 	for i := 0; i <= len(data); i++ {
 		if i == len(data) || data[i] == '\n' {
 			line := data[start:i]
 			start = i + 1
+			lineNumber++
 
 			if len(line) == 0 {
 				continue
@@ -133,12 +279,12 @@ func ParseEntries(data []byte) ([]Entry, error) {
 
 			var entry Entry
 			if err := json.Unmarshal(line, &entry); err != nil {
-				// Skip malformed entries.
+				stats.MalformedLines = append(stats.MalformedLines, lineNumber)
 				continue
 			}
 			entries = append(entries, entry)
 		}
 	}
 
-	return entries, nil
+	return entries, stats
 }
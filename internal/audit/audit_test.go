@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
 )
@@ -307,6 +308,78 @@ this is not valid json
 	}
 }
 
+func TestParseEntriesWithStats_ReportsMalformedLines(t *testing.T) {
+	data := []byte(`{"ts":"2024-01-15T10:30:00.123456Z","user":"alice@example.com","op":"encrypt"}
+not json
+
+{"ts":"2024-01-15T10:35:00.456789Z","user":"bob@example.com","op":"decrypt"}
+{"ts":"2024-01-15T10:40:00.000000Z","user":
+{"ts":"2024-01-15T10:45:00.000000Z","user":"carol@example.com","op":"sync"}
+}`)
+
+	entries, stats := ParseEntriesWithStats(data)
+
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 valid entries, got %d", len(entries))
+	}
+	if entries[2].User != "carol@example.com" {
+		t.Errorf("Expected entries after a malformed line to be kept, got %s", entries[2].User)
+	}
+
+	want := []int{2, 5, 7}
+	if stats.Malformed() != len(want) {
+		t.Fatalf("Expected %d malformed lines, got %v", len(want), stats.MalformedLines)
+	}
+	for i, line := range want {
+		if stats.MalformedLines[i] != line {
+			t.Errorf("Expected malformed lines %v, got %v", want, stats.MalformedLines)
+			break
+		}
+	}
+}
+
+func TestReadEntriesWithStats(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tempDir, ".kanuka"), 0755); err != nil {
+		t.Fatalf("Failed to create .kanuka dir: %v", err)
+	}
+
+	originalSettings := configs.ProjectKanukaSettings
+	configs.ProjectKanukaSettings = &configs.ProjectSettings{
+		ProjectPath: tempDir,
+	}
+	defer func() {
+		configs.ProjectKanukaSettings = originalSettings
+	}()
+
+	data := `{"ts":"2024-01-15T10:30:00.123456Z","user":"alice@example.com","op":"encrypt"}
+garbage
+{"ts":"2024-01-15T10:35:00.456789Z","user":"bob@example.com","op":"decrypt"}
+`
+	if err := os.WriteFile(LogPath(), []byte(data), 0600); err != nil {
+		t.Fatalf("Failed to write audit log: %v", err)
+	}
+
+	entries, stats, err := ReadEntriesWithStats()
+	if err != nil {
+		t.Fatalf("ReadEntriesWithStats failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("Expected 2 entries, got %d", len(entries))
+	}
+	if stats.Malformed() != 1 || stats.MalformedLines[0] != 2 {
+		t.Errorf("Expected line 2 to be reported as malformed, got %v", stats.MalformedLines)
+	}
+
+	plain, err := ReadEntries()
+	if err != nil {
+		t.Fatalf("ReadEntries failed: %v", err)
+	}
+	if len(plain) != len(entries) {
+		t.Errorf("Expected ReadEntries to return the same %d entries, got %d", len(entries), len(plain))
+	}
+}
+
 func TestParseEntries_EmptyData(t *testing.T) {
 	entries, err := ParseEntries([]byte{})
 	if err != nil {
@@ -348,3 +421,94 @@ func TestLogPath_NoProject(t *testing.T) {
 		t.Errorf("Expected empty path, got %s", path)
 	}
 }
+
+func TestLog_NotesClockSkew(t *testing.T) {
+	tempDir := t.TempDir()
+	kanukaDir := filepath.Join(tempDir, ".kanuka")
+	if err := os.MkdirAll(kanukaDir, 0755); err != nil {
+		t.Fatalf("Failed to create .kanuka dir: %v", err)
+	}
+
+	originalSettings := configs.ProjectKanukaSettings
+	configs.ProjectKanukaSettings = &configs.ProjectSettings{
+		ProjectPath: tempDir,
+	}
+	defer func() {
+		configs.ProjectKanukaSettings = originalSettings
+	}()
+
+	// An entry written by a machine whose clock is a year ahead.
+	future := time.Now().UTC().AddDate(1, 0, 0).Format(TimestampFormat)
+	Log(Entry{Timestamp: future, User: "skewed@example.com", Operation: "encrypt"})
+	Log(Entry{User: "test@example.com", Operation: "decrypt"})
+
+	data, err := os.ReadFile(filepath.Join(kanukaDir, "audit.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	entries, _ := ParseEntries(data)
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+
+	if entries[0].Note != "" {
+		t.Errorf("Expected no note on first entry, got %q", entries[0].Note)
+	}
+	if !strings.Contains(entries[1].Note, "clock skew") {
+		t.Errorf("Expected clock skew note on second entry, got %q", entries[1].Note)
+	}
+
+	// The local time is still recorded as-is.
+	ts, err := ParseTimestamp(entries[1].Timestamp)
+	if err != nil {
+		t.Fatalf("Failed to parse timestamp: %v", err)
+	}
+	if time.Since(ts) > time.Minute {
+		t.Errorf("Expected local timestamp to be recorded, got %s", entries[1].Timestamp)
+	}
+}
+
+func TestEntry_IsFutureDated(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		ts       string
+		expected bool
+	}{
+		{"Past", "2024-01-14T10:00:00.000000Z", false},
+		{"SlightlyAhead", "2024-01-15T12:00:00.000000Z", false},
+		{"FarFuture", "2025-01-15T10:00:00.000000Z", true},
+		{"RFC3339FarFuture", "2025-01-15T10:00:00Z", true},
+		{"Unparseable", "not-a-time", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := (Entry{Timestamp: tc.ts}).IsFutureDated(now); got != tc.expected {
+				t.Errorf("IsFutureDated(%q) = %t, expected %t", tc.ts, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestSortByTime(t *testing.T) {
+	entries := []Entry{
+		{Timestamp: "2024-01-15T10:00:00.000000Z", Operation: "c"},
+		{Timestamp: "2024-01-14T10:00:00.000000Z", Operation: "a"},
+		{Timestamp: "garbage", Operation: "a2"},
+		{Timestamp: "2024-01-14T12:00:00Z", Operation: "b"},
+		{Timestamp: "2024-01-15T10:00:00.000000Z", Operation: "d"},
+	}
+
+	SortByTime(entries)
+
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Operation)
+	}
+	expected := "a,a2,b,c,d"
+	if strings.Join(got, ",") != expected {
+		t.Errorf("Expected order %s, got %s", expected, strings.Join(got, ","))
+	}
+}
@@ -0,0 +1,158 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+)
+
+// Batch buffers audit entries and appends them to the log in a single write
+// when closed. Use it instead of Log when recording many entries at once, so
+// the log is read and opened once rather than once per entry, which is slow
+// on network filesystems.
+//
+// Entries are timestamped as they are added and hash-chained when the batch
+// is closed, exactly as if each had been written with Write. A Batch is not
+// safe for concurrent use.
+type Batch struct {
+	logPath string
+	entries []batchEntry
+	closed  bool
+	err     error
+}
+
+// batchEntry is an entry waiting to be written, with the time it was added.
+type batchEntry struct {
+	entry   Entry
+	addedAt time.Time
+
+	// stamped is set when Add filled in the timestamp, so the entry is
+	// checked for clock skew against the entry before it.
+	stamped bool
+}
+
+// NewBatch starts a batch of entries for the current project's audit log.
+// If the project is not initialized, entries are discarded and Close returns
+// an error.
+func NewBatch() *Batch {
+	b := &Batch{logPath: LogPath()}
+	if b.logPath == "" {
+		b.err = fmt.Errorf("project not initialized")
+	}
+	return b
+}
+
+// Add buffers an entry. It is written when the batch is closed.
+func (b *Batch) Add(entry Entry) {
+	if b.closed || b.logPath == "" {
+		return
+	}
+
+	now := time.Now().UTC()
+	stamped := entry.Timestamp == ""
+	if stamped {
+		entry.Timestamp = now.Format(TimestampFormat)
+	}
+	b.entries = append(b.entries, batchEntry{entry: entry, addedAt: now, stamped: stamped})
+}
+
+// Close appends the buffered entries to the audit log and returns the first
+// error encountered. An entry that can't be encoded is skipped. Like Log,
+// callers whose operation should not fail because auditing failed can ignore
+// the error. Closing twice does nothing.
+//
+// The log is locked from reading its last entry until the entries are
+// appended, so commands writing at the same time can't chain to the same
+// previous entry.
+func (b *Batch) Close() error {
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+
+	if len(b.entries) == 0 {
+		return b.err
+	}
+
+	// Open file for reading and appending (create if doesn't exist).
+	// #nosec G306 -- audit log should be readable by team members.
+	f, err := os.OpenFile(b.logPath, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("locking audit log: %w", err)
+	}
+
+	data := b.encode(f)
+	_, writeErr := f.Write(data)
+	unlockErr := unlockFile(f)
+	closeErr := f.Close()
+	b.entries = nil
+	switch {
+	case writeErr != nil:
+		return fmt.Errorf("writing audit log: %w", writeErr)
+	case unlockErr != nil:
+		return fmt.Errorf("unlocking audit log: %w", unlockErr)
+	case closeErr != nil:
+		return fmt.Errorf("closing audit log: %w", closeErr)
+	}
+	return b.err
+}
+
+// encode chains the buffered entries to the last entry in the locked log f
+// and returns them as JSON Lines.
+func (b *Batch) encode(f *os.File) []byte {
+	// An empty log starts a new chain. A log whose last entry can't be read
+	// has no entry to chain to; the entry is written unchained and Verify
+	// reports the gap.
+	info, err := f.Stat()
+	startsChain := err == nil && info.Size() == 0
+	last, hasLast := lastEntry(f)
+
+	var buf bytes.Buffer
+	for _, pending := range b.entries {
+		entry := pending.entry
+
+		// The local clock is the only time source, so it is always recorded as-is.
+		// If the previous entry is dated well after our clock, one of the two
+		// machines has a wrong clock; note it so readers can tell.
+		if pending.stamped && entry.Note == "" && hasLast {
+			if lastTime, err := ParseTimestamp(last.Timestamp); err == nil && configs.IsFutureDated(lastTime, pending.addedAt) {
+				entry.Note = fmt.Sprintf("clock skew: previous entry is dated %s, after this machine's clock",
+					lastTime.UTC().Format(TimestampFormat))
+			}
+		}
+
+		// Chain to the previous entry. This is best-effort: if the previous entry
+		// can't be hashed, the entry is still written, and Verify reports the gap.
+		if entry.PrevHash == "" {
+			switch {
+			case hasLast:
+				if hash, err := HashEntry(last); err == nil {
+					entry.PrevHash = hash
+				}
+			case startsChain:
+				entry.PrevHash = ChainStart
+			}
+		}
+
+		data, err := json.Marshal(entry)
+		if err != nil {
+			if b.err == nil {
+				b.err = fmt.Errorf("encoding audit entry: %w", err)
+			}
+			continue
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+
+		last, hasLast = entry, true
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+)
+
+func TestBatch_WritesChainedEntries(t *testing.T) {
+	setupVerifyProject(t)
+
+	Log(Entry{User: "alice@example.com", Operation: "init"})
+
+	batch := NewBatch()
+	batch.Add(Entry{User: "alice@example.com", Operation: "encrypt"})
+	batch.Add(Entry{User: "bob@example.com", Operation: "decrypt"})
+
+	// Nothing is written until the batch is closed.
+	entries, err := ReadEntries()
+	if err != nil {
+		t.Fatalf("ReadEntries failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry before Close, got %d", len(entries))
+	}
+
+	if err := batch.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	entries, err = ReadEntries()
+	if err != nil {
+		t.Fatalf("ReadEntries failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries after Close, got %d", len(entries))
+	}
+	for i, op := range []string{"init", "encrypt", "decrypt"} {
+		if entries[i].Operation != op {
+			t.Errorf("Entry %d: expected op %q, got %q", i, op, entries[i].Operation)
+		}
+		if entries[i].Timestamp == "" {
+			t.Errorf("Entry %d: expected a timestamp", i)
+		}
+	}
+
+	if chainBreak := VerifyEntries(entries); chainBreak != nil {
+		t.Errorf("Expected an intact hash chain, got break at %+v", chainBreak)
+	}
+
+	// Closing again must not write the entries twice.
+	if err := batch.Close(); err != nil {
+		t.Errorf("Second Close failed: %v", err)
+	}
+	entries, _ = ReadEntries()
+	if len(entries) != 3 {
+		t.Errorf("Expected 3 entries after second Close, got %d", len(entries))
+	}
+}
+
+func TestBatch_EmptyBatchCreatesNoLog(t *testing.T) {
+	logPath := setupVerifyProject(t)
+
+	if err := NewBatch().Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if _, err := os.Stat(logPath); !os.IsNotExist(err) {
+		t.Errorf("Expected no audit log for an empty batch")
+	}
+}
+
+func TestBatch_ProjectNotInitialized(t *testing.T) {
+	originalSettings := configs.ProjectKanukaSettings
+	configs.ProjectKanukaSettings = &configs.ProjectSettings{}
+	defer func() { configs.ProjectKanukaSettings = originalSettings }()
+
+	batch := NewBatch()
+	batch.Add(Entry{Operation: "encrypt"})
+	if err := batch.Close(); err == nil {
+		t.Error("Expected an error when the project is not initialized")
+	}
+}
+
+func TestBatch_UnwritableLogIsReported(t *testing.T) {
+	logPath := setupVerifyProject(t)
+
+	// A directory in place of the log file makes the append fail.
+	if err := os.MkdirAll(logPath, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	batch := NewBatch()
+	batch.Add(Entry{Operation: "encrypt"})
+	if err := batch.Close(); err == nil {
+		t.Error("Expected Close to report the write failure")
+	}
+}
+
+// benchmarkEntries is the number of entries written per benchmark iteration,
+// as when auditing a batch encryption of many files.
+const benchmarkEntries = 100
+
+func setupBenchmarkProject(b *testing.B) {
+	b.Helper()
+	tempDir := b.TempDir()
+	if err := os.MkdirAll(filepath.Join(tempDir, ".kanuka"), 0755); err != nil {
+		b.Fatalf("Failed to create .kanuka dir: %v", err)
+	}
+
+	originalSettings := configs.ProjectKanukaSettings
+	configs.ProjectKanukaSettings = &configs.ProjectSettings{ProjectPath: tempDir}
+	b.Cleanup(func() { configs.ProjectKanukaSettings = originalSettings })
+}
+
+// BenchmarkLog_PerEntry opens, reads, and appends to the log once per entry:
+// two file opens and a write for each of benchmarkEntries.
+func BenchmarkLog_PerEntry(b *testing.B) {
+	setupBenchmarkProject(b)
+
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < benchmarkEntries; j++ {
+			Log(Entry{User: "alice@example.com", Operation: "encrypt", Files: []string{fmt.Sprintf(".env.%d", j)}})
+		}
+	}
+}
+
+// BenchmarkBatch opens, reads, and appends to the log once per batch of
+// benchmarkEntries.
+func BenchmarkBatch(b *testing.B) {
+	setupBenchmarkProject(b)
+
+	for i := 0; i < b.N; i++ {
+		batch := NewBatch()
+		for j := 0; j < benchmarkEntries; j++ {
+			batch.Add(Entry{User: "alice@example.com", Operation: "encrypt", Files: []string{fmt.Sprintf(".env.%d", j)}})
+		}
+		if err := batch.Close(); err != nil {
+			b.Fatalf("Close failed: %v", err)
+		}
+	}
+}
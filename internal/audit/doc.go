@@ -15,6 +15,19 @@
 //   - User email and UUID
 //   - Operation name
 //   - Operation-specific details (files, target users, etc.)
+//   - The SHA-256 of the previous entry (prev_hash)
+//
+// # Integrity
+//
+// Each entry records the hash of the entry before it, forming a hash chain
+// that starts with a chain-start marker on the log's first entry. Writers
+// lock the log while they read its last entry and append, so concurrent
+// commands can't fork the chain. Verify() walks the log and reports the
+// first entry whose prev_hash doesn't match, which means history before it
+// was edited, removed, or reordered.
+// The chain has no secret, so it catches casual edits rather than someone
+// willing to rewrite every later entry. Logs merged from diverging branches
+// also break the chain at the merge point.
 //
 // # Usage
 //
@@ -24,14 +37,29 @@
 //	entry.Files = encryptedFiles
 //	audit.Log(entry)
 //
+// To record many entries at once, buffer them in a Batch so the log is read
+// and opened once instead of once per entry:
+//
+//	batch := audit.NewBatch()
+//	for _, file := range files {
+//		entry := audit.LogWithUser("encrypt")
+//		entry.Files = []string{file}
+//		batch.Add(entry)
+//	}
+//	_ = batch.Close()
+//
 // # Failure Handling
 //
 // Audit logging is best-effort. If logging fails (permissions, disk full,
 // etc.), the operation continues without error. Operations should never
-// fail just because audit logging failed.
+// fail just because audit logging failed. Batch.Close returns its error for
+// callers that want to warn about it, but it is safe to ignore.
 //
 // # Reading Logs
 //
 // Use ReadEntries() to parse the audit log for display or analysis.
-// Malformed entries are silently skipped to handle partial writes.
+// Malformed entries are silently skipped to handle partial writes; use
+// ReadEntriesWithStats() to learn which lines were skipped. Export() writes
+// entries as CSV or a JSON array for tools that can't read JSON Lines.
+// Summary() aggregates entries into counts per operation, user, and file.
 package audit
@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ExportFormat is a format the audit log can be exported in.
+type ExportFormat string
+
+const (
+	// ExportCSV writes one row per entry under CSVHeader.
+	ExportCSV ExportFormat = "csv"
+
+	// ExportJSON writes a single JSON array of entries.
+	ExportJSON ExportFormat = "json"
+)
+
+// ExportFormats lists the supported export formats.
+var ExportFormats = []ExportFormat{ExportCSV, ExportJSON}
+
+// CSVHeader is the header row written by WriteCSV.
+var CSVHeader = []string{"timestamp", "user", "uuid", "operation", "device", "target_user", "files"}
+
+// CSVFilesSeparator joins an entry's files into the single files column.
+const CSVFilesSeparator = ";"
+
+// ParseExportFormat returns the ExportFormat named by s.
+func ParseExportFormat(s string) (ExportFormat, error) {
+	for _, format := range ExportFormats {
+		if string(format) == s {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown export format %q (expected csv or json)", s)
+}
+
+// Export writes entries to w in the given format.
+func Export(w io.Writer, entries []Entry, format ExportFormat) error {
+	switch format {
+	case ExportCSV:
+		return WriteCSV(w, entries)
+	case ExportJSON:
+		return WriteJSON(w, entries)
+	default:
+		return fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+// WriteCSV writes entries as CSV under CSVHeader. The device column holds
+// the revoked device for device revokes, and the device name otherwise.
+// Fields containing commas, quotes, or newlines are quoted.
+func WriteCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(CSVHeader); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		device := e.Device
+		if device == "" {
+			device = e.DeviceName
+		}
+		record := []string{
+			e.Timestamp,
+			e.User,
+			e.UserUUID,
+			e.Operation,
+			device,
+			e.TargetUser,
+			strings.Join(e.Files, CSVFilesSeparator),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteJSON writes entries as a single indented JSON array. An empty log
+// is written as [].
+func WriteJSON(w io.Writer, entries []Entry) error {
+	if entries == nil {
+		entries = []Entry{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
@@ -0,0 +1,92 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteCSV_EscapesFields(t *testing.T) {
+	entries := []Entry{
+		{
+			Timestamp: "2024-01-15T10:00:00.000000Z",
+			User:      "alice@example.com",
+			UserUUID:  "uuid-1",
+			Operation: "encrypt",
+			Files:     []string{"config/a,b.env", `say "hi".env`},
+		},
+		{
+			Timestamp:  "2024-01-16T10:00:00.000000Z",
+			User:       "bob@example.com",
+			UserUUID:   "uuid-2",
+			Operation:  "revoke",
+			TargetUser: "carol@example.com",
+			Device:     "laptop",
+		},
+		{Timestamp: "2024-01-17T10:00:00.000000Z", Operation: "create", DeviceName: "desktop"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, entries); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"config/a,b.env;say ""hi"".env"`) {
+		t.Errorf("Expected the files column to be quoted, got:\n%s", buf.String())
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not valid CSV: %v", err)
+	}
+	if len(records) != 4 {
+		t.Fatalf("Expected a header and 3 rows, got %d", len(records))
+	}
+	if strings.Join(records[0], ",") != strings.Join(CSVHeader, ",") {
+		t.Errorf("Unexpected header: %v", records[0])
+	}
+	if got := strings.Split(records[1][6], CSVFilesSeparator); len(got) != 2 || got[0] != "config/a,b.env" || got[1] != `say "hi".env` {
+		t.Errorf("Files did not round-trip, got %q", records[1][6])
+	}
+	if records[2][4] != "laptop" || records[2][5] != "carol@example.com" {
+		t.Errorf("Expected device and target user columns, got %v", records[2])
+	}
+	if records[3][4] != "desktop" {
+		t.Errorf("Expected the device name as a fallback, got %q", records[3][4])
+	}
+}
+
+func TestWriteJSON_WritesArray(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, nil); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("Expected an empty array, got %q", buf.String())
+	}
+
+	buf.Reset()
+	entries := []Entry{{Operation: "encrypt"}, {Operation: "decrypt"}}
+	if err := WriteJSON(&buf, entries); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var decoded []Entry
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not a JSON array: %v", err)
+	}
+	if len(decoded) != 2 || decoded[1].Operation != "decrypt" {
+		t.Errorf("Unexpected entries: %+v", decoded)
+	}
+}
+
+func TestParseExportFormat(t *testing.T) {
+	for _, s := range []string{"csv", "json"} {
+		if _, err := ParseExportFormat(s); err != nil {
+			t.Errorf("Expected %q to be valid: %v", s, err)
+		}
+	}
+	if _, err := ParseExportFormat("jsonl"); err == nil {
+		t.Error("Expected jsonl to be rejected")
+	}
+}
@@ -0,0 +1,120 @@
+package audit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QueryFilter selects audit log entries. Zero-valued fields match everything.
+type QueryFilter struct {
+	// User matches the entry's user email, case-insensitively.
+	User string
+
+	// Operations matches any of the listed operation names, case-insensitively.
+	Operations []string
+
+	// Since matches entries at or after this time.
+	Since time.Time
+
+	// Until matches entries at or before this time.
+	Until time.Time
+}
+
+// Query reads the audit log and returns the entries matching filter, oldest first.
+// Entries with unparseable timestamps never match a Since or Until bound.
+// Returns an empty slice if the log doesn't exist.
+func Query(filter QueryFilter) ([]Entry, error) {
+	entries, err := ReadEntries()
+	if err != nil {
+		return nil, err
+	}
+	return FilterEntries(entries, filter), nil
+}
+
+// FilterEntries returns the entries matching filter, oldest first.
+func FilterEntries(entries []Entry, filter QueryFilter) []Entry {
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	SortByTime(sorted)
+
+	ops := make(map[string]bool, len(filter.Operations))
+	for _, op := range filter.Operations {
+		if op = strings.TrimSpace(op); op != "" {
+			ops[strings.ToLower(op)] = true
+		}
+	}
+
+	var matched []Entry
+	for _, e := range sorted {
+		if filter.User != "" && !strings.EqualFold(e.User, filter.User) {
+			continue
+		}
+		if len(ops) > 0 && !ops[strings.ToLower(e.Operation)] {
+			continue
+		}
+		if !filter.Since.IsZero() || !filter.Until.IsZero() {
+			t, err := ParseTimestamp(e.Timestamp)
+			if err != nil {
+				continue
+			}
+			if !filter.Since.IsZero() && t.Before(filter.Since) {
+				continue
+			}
+			if !filter.Until.IsZero() && t.After(filter.Until) {
+				continue
+			}
+		}
+		matched = append(matched, e)
+	}
+	return matched
+}
+
+// ParseQueryTime parses a --since or --until value. It accepts an RFC3339
+// timestamp, a YYYY-MM-DD date (midnight UTC), or a duration before now such
+// as "24h", "90m", "7d", or "2w".
+func ParseQueryTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if d, ok := parseRelativeDuration(value); ok {
+		return now.Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("%q is not an RFC3339 timestamp, YYYY-MM-DD date, or duration like 24h or 7d", value)
+}
+
+// parseRelativeDuration parses Go durations plus the day ("d") and week ("w")
+// units that time.ParseDuration lacks.
+func parseRelativeDuration(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	var unit time.Duration
+	switch value[len(value)-1] {
+	case 'd':
+		unit = 24 * time.Hour
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	}
+	if unit != 0 {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		return time.Duration(n) * unit, true
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, false
+	}
+	return d, true
+}
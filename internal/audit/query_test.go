@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func queryTestEntries() []Entry {
+	return []Entry{
+		{Timestamp: "2024-01-15T10:00:00.000000Z", User: "alice@example.com", Operation: "encrypt"},
+		{Timestamp: "2024-01-10T10:00:00.000000Z", User: "bob@example.com", Operation: "register"},
+		{Timestamp: "2024-01-20T10:00:00.000000Z", User: "Alice@Example.com", Operation: "revoke"},
+		{Timestamp: "garbage", User: "alice@example.com", Operation: "decrypt"},
+	}
+}
+
+func operations(entries []Entry) string {
+	ops := make([]string, len(entries))
+	for i, e := range entries {
+		ops[i] = e.Operation
+	}
+	return strings.Join(ops, ",")
+}
+
+func TestFilterEntries(t *testing.T) {
+	since := time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		filter   QueryFilter
+		expected string
+	}{
+		{"NoFilter", QueryFilter{}, "register,encrypt,revoke,decrypt"},
+		{"UserCaseInsensitive", QueryFilter{User: "ALICE@example.com"}, "encrypt,revoke,decrypt"},
+		{"Operations", QueryFilter{Operations: []string{"Register", " revoke "}}, "register,revoke"},
+		{"Since", QueryFilter{Since: since}, "encrypt,revoke"},
+		{"Until", QueryFilter{Until: until}, "register,encrypt"},
+		{"Combined", QueryFilter{User: "alice@example.com", Since: since, Until: until}, "encrypt"},
+		{"NoMatch", QueryFilter{User: "carol@example.com"}, ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := operations(FilterEntries(queryTestEntries(), tc.filter))
+			if got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestFilterEntries_DoesNotReorderInput(t *testing.T) {
+	entries := queryTestEntries()
+	FilterEntries(entries, QueryFilter{})
+	if entries[0].Operation != "encrypt" {
+		t.Errorf("FilterEntries modified its input, first entry is now %q", entries[0].Operation)
+	}
+}
+
+func TestParseQueryTime(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"2024-01-10T08:30:00Z", time.Date(2024, 1, 10, 8, 30, 0, 0, time.UTC)},
+		{"2024-01-10", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)},
+		{"24h", now.Add(-24 * time.Hour)},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"7d", now.Add(-7 * 24 * time.Hour)},
+		{"2w", now.Add(-14 * 24 * time.Hour)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := ParseQueryTime(tc.input, now)
+			if err != nil {
+				t.Fatalf("ParseQueryTime(%q) failed: %v", tc.input, err)
+			}
+			if !got.Equal(tc.expected) {
+				t.Errorf("ParseQueryTime(%q) = %v, expected %v", tc.input, got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestParseQueryTime_Invalid(t *testing.T) {
+	now := time.Now()
+	for _, input := range []string{"", "yesterday", "7x", "-3d", "d", "2024-13-01"} {
+		if _, err := ParseQueryTime(input, now); err == nil {
+			t.Errorf("ParseQueryTime(%q) should fail", input)
+		}
+	}
+}
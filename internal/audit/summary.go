@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"sort"
+	"time"
+)
+
+// Stats summarizes a set of audit log entries.
+type Stats struct {
+	// TotalEntries is the number of entries summarized.
+	TotalEntries int `json:"total_entries"`
+
+	// First and Last are the earliest and latest entry timestamps. They are
+	// zero if no entry has a parseable timestamp.
+	First time.Time `json:"first"`
+	Last  time.Time `json:"last"`
+
+	// Operations counts entries per operation, most frequent first.
+	Operations []Count `json:"operations"`
+
+	// Users counts entries per user email, most active first. Entries
+	// without an email are counted under their UUID.
+	Users []Count `json:"users"`
+
+	// Files counts how many entries name each file, most accessed first.
+	Files []Count `json:"files"`
+}
+
+// Count is the number of entries for one operation, user, or file.
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Span returns the time between the first and last entries.
+func (s *Stats) Span() time.Duration {
+	return s.Last.Sub(s.First)
+}
+
+// Summary aggregates entries into counts per operation, user, and file, and
+// the time span they cover. Entries with unparseable timestamps are counted
+// but don't affect the span.
+func Summary(entries []Entry) *Stats {
+	stats := &Stats{TotalEntries: len(entries)}
+	operations := make(map[string]int)
+	users := make(map[string]int)
+	files := make(map[string]int)
+
+	for _, e := range entries {
+		operations[e.Operation]++
+
+		user := e.User
+		if user == "" {
+			user = e.UserUUID
+		}
+		if user != "" {
+			users[user]++
+		}
+
+		for _, file := range e.Files {
+			files[file]++
+		}
+
+		t, err := ParseTimestamp(e.Timestamp)
+		if err != nil {
+			continue
+		}
+		if stats.First.IsZero() || t.Before(stats.First) {
+			stats.First = t
+		}
+		if t.After(stats.Last) {
+			stats.Last = t
+		}
+	}
+
+	stats.Operations = sortedCounts(operations)
+	stats.Users = sortedCounts(users)
+	stats.Files = sortedCounts(files)
+	return stats
+}
+
+// sortedCounts returns counts ordered by count, highest first, then by name.
+func sortedCounts(counts map[string]int) []Count {
+	sorted := make([]Count, 0, len(counts))
+	for name, count := range counts {
+		sorted = append(sorted, Count{Name: name, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
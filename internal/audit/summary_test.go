@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func formatCounts(counts []Count) string {
+	parts := make([]string, len(counts))
+	for i, c := range counts {
+		parts[i] = fmt.Sprintf("%s=%d", c.Name, c.Count)
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestSummary(t *testing.T) {
+	entries := []Entry{
+		{Timestamp: "2024-01-15T10:00:00.000000Z", User: "alice@example.com", Operation: "encrypt", Files: []string{".env", "api/.env"}},
+		{Timestamp: "2024-01-10T09:00:00.000000Z", User: "bob@example.com", Operation: "decrypt", Files: []string{".env"}},
+		{Timestamp: "2024-01-20T10:00:00Z", User: "alice@example.com", Operation: "decrypt", Files: []string{".env"}},
+		{Timestamp: "2024-01-12T10:00:00.000000Z", UserUUID: "ci-uuid", Operation: "decrypt", Files: []string{"api/.env"}},
+		{Timestamp: "garbage", User: "alice@example.com", Operation: "revoke", TargetUser: "bob@example.com"},
+	}
+
+	stats := Summary(entries)
+
+	if stats.TotalEntries != 5 {
+		t.Errorf("Expected 5 entries, got %d", stats.TotalEntries)
+	}
+	if got := formatCounts(stats.Operations); got != "decrypt=3,encrypt=1,revoke=1" {
+		t.Errorf("Unexpected operation counts: %s", got)
+	}
+	if got := formatCounts(stats.Users); got != "alice@example.com=3,bob@example.com=1,ci-uuid=1" {
+		t.Errorf("Unexpected user counts: %s", got)
+	}
+	if got := formatCounts(stats.Files); got != ".env=3,api/.env=2" {
+		t.Errorf("Unexpected file counts: %s", got)
+	}
+
+	wantFirst := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	wantLast := time.Date(2024, 1, 20, 10, 0, 0, 0, time.UTC)
+	if !stats.First.Equal(wantFirst) || !stats.Last.Equal(wantLast) {
+		t.Errorf("Expected span %v to %v, got %v to %v", wantFirst, wantLast, stats.First, stats.Last)
+	}
+	if stats.Span() != wantLast.Sub(wantFirst) {
+		t.Errorf("Expected span of %v, got %v", wantLast.Sub(wantFirst), stats.Span())
+	}
+}
+
+func TestSummary_Empty(t *testing.T) {
+	stats := Summary(nil)
+	if stats.TotalEntries != 0 || len(stats.Operations) != 0 || len(stats.Users) != 0 || len(stats.Files) != 0 {
+		t.Errorf("Expected empty stats, got %+v", stats)
+	}
+	if !stats.First.IsZero() || !stats.Last.IsZero() {
+		t.Errorf("Expected no time span, got %v to %v", stats.First, stats.Last)
+	}
+}
@@ -0,0 +1,57 @@
+package audit
+
+// ChainBreak describes the first entry whose PrevHash doesn't match the
+// entry before it.
+type ChainBreak struct {
+	// Index is the position of the entry among the parsed log entries.
+	Index int
+
+	// Entry is the entry whose PrevHash doesn't match.
+	Entry Entry
+
+	// Expected is the hash of the entry before it, or ChainStart for the
+	// first entry.
+	Expected string
+}
+
+// Verify walks the audit log and reports the first entry where the hash
+// chain breaks, which means an earlier entry was edited, removed, or
+// reordered. It returns nil if the chain is intact or the log is empty.
+//
+// The first entry of a log carries the ChainStart marker. Entries written
+// before the chain was introduced have no PrevHash; a leading run of them is
+// tolerated, but once an entry carries a PrevHash every later entry must
+// chain to the one before it. A marker anywhere but the first entry, or a
+// first entry chained to something else, means entries were removed from
+// the start of the log or the chain was restarted.
+func Verify() (*ChainBreak, error) {
+	entries, err := ReadEntries()
+	if err != nil {
+		return nil, err
+	}
+	return VerifyEntries(entries), nil
+}
+
+// VerifyEntries checks the hash chain of entries in log order. See Verify.
+func VerifyEntries(entries []Entry) *ChainBreak {
+	chained := false
+	for i, entry := range entries {
+		if entry.PrevHash == "" && !chained {
+			continue
+		}
+		chained = true
+
+		expected := ChainStart
+		if i > 0 {
+			hash, err := HashEntry(entries[i-1])
+			if err != nil {
+				return &ChainBreak{Index: i, Entry: entry}
+			}
+			expected = hash
+		}
+		if entry.PrevHash != expected {
+			return &ChainBreak{Index: i, Entry: entry, Expected: expected}
+		}
+	}
+	return nil
+}
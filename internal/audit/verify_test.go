@@ -0,0 +1,239 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+)
+
+// setupVerifyProject points the project settings at a temp project with a
+// .kanuka directory and returns the audit log path.
+func setupVerifyProject(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tempDir, ".kanuka"), 0755); err != nil {
+		t.Fatalf("Failed to create .kanuka dir: %v", err)
+	}
+
+	originalSettings := configs.ProjectKanukaSettings
+	configs.ProjectKanukaSettings = &configs.ProjectSettings{ProjectPath: tempDir}
+	t.Cleanup(func() { configs.ProjectKanukaSettings = originalSettings })
+
+	return filepath.Join(tempDir, ".kanuka", "audit.jsonl")
+}
+
+func TestLog_ChainsEntries(t *testing.T) {
+	setupVerifyProject(t)
+
+	Log(Entry{User: "alice@example.com", Operation: "encrypt"})
+	Log(Entry{User: "bob@example.com", Operation: "decrypt"})
+	Log(Entry{User: "charlie@example.com", Operation: "register"})
+
+	entries, err := ReadEntries()
+	if err != nil {
+		t.Fatalf("ReadEntries failed: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(entries))
+	}
+
+	if entries[0].PrevHash != ChainStart {
+		t.Errorf("Expected first entry to start the chain, got PrevHash %q", entries[0].PrevHash)
+	}
+	for i := 1; i < len(entries); i++ {
+		want, err := HashEntry(entries[i-1])
+		if err != nil {
+			t.Fatalf("HashEntry failed: %v", err)
+		}
+		if entries[i].PrevHash != want {
+			t.Errorf("Entry %d: expected PrevHash %q, got %q", i, want, entries[i].PrevHash)
+		}
+	}
+
+	chainBreak, err := Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if chainBreak != nil {
+		t.Errorf("Expected intact chain, got break at entry %d", chainBreak.Index)
+	}
+}
+
+func TestVerify_DetectsEditedEntry(t *testing.T) {
+	logPath := setupVerifyProject(t)
+
+	Log(Entry{User: "alice@example.com", Operation: "encrypt"})
+	Log(Entry{User: "bob@example.com", Operation: "decrypt"})
+	Log(Entry{User: "charlie@example.com", Operation: "register"})
+	Log(Entry{User: "alice@example.com", Operation: "sync"})
+
+	// Rewrite the second entry as if someone edited history.
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	var edited Entry
+	if err := json.Unmarshal(lines[1], &edited); err != nil {
+		t.Fatalf("Failed to parse entry: %v", err)
+	}
+	edited.User = "mallory@example.com"
+	lines[1], err = json.Marshal(edited)
+	if err != nil {
+		t.Fatalf("Failed to encode entry: %v", err)
+	}
+	if err := os.WriteFile(logPath, append(bytes.Join(lines, []byte("\n")), '\n'), 0644); err != nil {
+		t.Fatalf("Failed to write audit log: %v", err)
+	}
+
+	chainBreak, err := Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if chainBreak == nil {
+		t.Fatal("Expected Verify to detect the edited entry")
+	}
+	// The edited entry still links to its predecessor; the entry after it doesn't.
+	if chainBreak.Index != 2 {
+		t.Errorf("Expected break at entry 2, got %d", chainBreak.Index)
+	}
+	if chainBreak.Entry.User != "charlie@example.com" {
+		t.Errorf("Expected break at charlie's entry, got %s", chainBreak.Entry.User)
+	}
+}
+
+func TestVerify_DetectsRemovedEntry(t *testing.T) {
+	logPath := setupVerifyProject(t)
+
+	Log(Entry{User: "alice@example.com", Operation: "encrypt"})
+	Log(Entry{User: "bob@example.com", Operation: "decrypt"})
+	Log(Entry{User: "charlie@example.com", Operation: "register"})
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	kept := lines[0] + "\n" + lines[2] + "\n"
+	if err := os.WriteFile(logPath, []byte(kept), 0644); err != nil {
+		t.Fatalf("Failed to write audit log: %v", err)
+	}
+
+	chainBreak, err := Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if chainBreak == nil || chainBreak.Index != 1 {
+		t.Fatalf("Expected break at entry 1, got %+v", chainBreak)
+	}
+}
+
+func TestVerifyEntries_ToleratesUnchainedPrefix(t *testing.T) {
+	legacy := []Entry{
+		{User: "alice@example.com", Operation: "init"},
+		{User: "alice@example.com", Operation: "encrypt"},
+	}
+	hash, err := HashEntry(legacy[1])
+	if err != nil {
+		t.Fatalf("HashEntry failed: %v", err)
+	}
+	entries := append(legacy, Entry{User: "bob@example.com", Operation: "decrypt", PrevHash: hash})
+
+	if chainBreak := VerifyEntries(entries); chainBreak != nil {
+		t.Errorf("Expected entries written before chaining to be tolerated, got break at %d", chainBreak.Index)
+	}
+
+	// Once the chain has started, a missing PrevHash is a break.
+	entries = append(entries, Entry{User: "bob@example.com", Operation: "sync"})
+	chainBreak := VerifyEntries(entries)
+	if chainBreak == nil || chainBreak.Index != 3 {
+		t.Errorf("Expected break at entry 3, got %+v", chainBreak)
+	}
+}
+
+func TestVerifyEntries_DetectsRemovedFirstEntry(t *testing.T) {
+	setupVerifyProject(t)
+
+	Log(Entry{User: "alice@example.com", Operation: "init"})
+	Log(Entry{User: "alice@example.com", Operation: "encrypt"})
+
+	entries, err := ReadEntries()
+	if err != nil {
+		t.Fatalf("ReadEntries failed: %v", err)
+	}
+
+	chainBreak := VerifyEntries(entries[1:])
+	if chainBreak == nil || chainBreak.Index != 0 || chainBreak.Expected != ChainStart {
+		t.Errorf("Expected break at entry 0, got %+v", chainBreak)
+	}
+}
+
+func TestVerifyEntries_DetectsRestartedChain(t *testing.T) {
+	entries := []Entry{
+		{User: "alice@example.com", Operation: "init", PrevHash: ChainStart},
+		{User: "bob@example.com", Operation: "decrypt", PrevHash: ChainStart},
+	}
+
+	chainBreak := VerifyEntries(entries)
+	if chainBreak == nil || chainBreak.Index != 1 {
+		t.Errorf("Expected break at entry 1, got %+v", chainBreak)
+	}
+}
+
+func TestBatch_ConcurrentWritersKeepChain(t *testing.T) {
+	setupVerifyProject(t)
+
+	const writers, perWriter = 20, 50
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			batch := NewBatch()
+			for j := 0; j < perWriter; j++ {
+				batch.Add(Entry{User: fmt.Sprintf("user%d@example.com", i), Operation: "decrypt"})
+			}
+			if err := batch.Close(); err != nil {
+				t.Errorf("Close failed: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	entries, err := ReadEntries()
+	if err != nil {
+		t.Fatalf("ReadEntries failed: %v", err)
+	}
+	if len(entries) != writers*perWriter {
+		t.Fatalf("Expected %d entries, got %d", writers*perWriter, len(entries))
+	}
+	if chainBreak := VerifyEntries(entries); chainBreak != nil {
+		t.Errorf("Expected concurrent writes to keep the chain intact, got break at entry %d", chainBreak.Index)
+	}
+}
+
+func TestLog_ChainsAfterLongEntry(t *testing.T) {
+	setupVerifyProject(t)
+
+	files := make([]string, 500)
+	for i := range files {
+		files[i] = filepath.Join("services", strings.Repeat("x", 10), ".env")
+	}
+	Log(Entry{User: "alice@example.com", Operation: "encrypt", Files: files})
+	Log(Entry{User: "bob@example.com", Operation: "decrypt"})
+
+	chainBreak, err := Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if chainBreak != nil {
+		t.Errorf("Expected intact chain after an entry longer than the tail buffer, got break at %d", chainBreak.Index)
+	}
+}
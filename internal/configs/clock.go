@@ -0,0 +1,25 @@
+package configs
+
+import "time"
+
+// MaxClockSkew is how far ahead of the current time a timestamp may be before it
+// is considered implausible, i.e. written by a machine with a badly-wrong clock.
+const MaxClockSkew = 24 * time.Hour
+
+// IsFutureDated reports whether t is implausibly far ahead of now.
+func IsFutureDated(t, now time.Time) bool {
+	return t.After(now.Add(MaxClockSkew))
+}
+
+// FutureDatedDevices returns the UUIDs of devices whose CreatedAt is implausibly
+// far ahead of now, which usually means they were registered from a machine
+// with a wrong clock.
+func (pc *ProjectConfig) FutureDatedDevices(now time.Time) []string {
+	var uuids []string
+	for uuid, device := range pc.Devices {
+		if IsFutureDated(device.CreatedAt, now) {
+			uuids = append(uuids, uuid)
+		}
+	}
+	return uuids
+}
@@ -4,8 +4,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+
 	"github.com/google/uuid"
 )
 
@@ -25,23 +30,43 @@ type User struct {
 	Name              string `toml:"name,omitempty"`
 	UUID              string `toml:"user_uuid"`
 	DefaultDeviceName string `toml:"default_device_name,omitempty"`
+
+	// StrictKeyPermissions always rejects private keys that are readable by
+	// their group or others, as if --strict-perms were given.
+	StrictKeyPermissions bool `toml:"strict_key_permissions,omitempty"`
 }
 
 type ProjectConfig struct {
-	Project Project                 `toml:"project"`
-	Users   map[string]string       `toml:"users"`
-	Devices map[string]DeviceConfig `toml:"devices"`
+	// ConfigVersion is the schema version the config was written with. Configs
+	// written before versioning have no version and load as 0.
+	ConfigVersion int                     `toml:"config_version"`
+	Project       Project                 `toml:"project"`
+	Users         map[string]string       `toml:"users"`
+	Devices       map[string]DeviceConfig `toml:"devices"`
 }
 
 type Project struct {
 	UUID string `toml:"project_uuid"`
 	Name string `toml:"name"`
+	// Owners lists the emails of the users who may run owner-only commands.
+	// When empty, ownership isn't enforced.
+	Owners []string `toml:"owners,omitempty"`
+}
+
+// Equal reports whether two project entries are equivalent.
+func (p Project) Equal(other Project) bool {
+	return p.UUID == other.UUID && p.Name == other.Name && slices.Equal(p.Owners, other.Owners)
 }
 
 type DeviceConfig struct {
 	Email     string    `toml:"email"`
 	Name      string    `toml:"name"`
 	CreatedAt time.Time `toml:"created_at"`
+
+	// ExpiresAt is when the device's access is meant to end, set with
+	// register --expiry. Zero means it never expires. Expiry is advisory:
+	// Kanuka warns about expired devices and revokes them on request.
+	ExpiresAt time.Time `toml:"expires_at,omitempty"`
 }
 
 // KeyMetadata stores metadata about a project's keys in the user's key directory.
@@ -50,6 +75,27 @@ type KeyMetadata struct {
 	ProjectPath    string    `toml:"project_path"`
 	CreatedAt      time.Time `toml:"created_at"`
 	LastAccessedAt time.Time `toml:"last_accessed_at"`
+	LastRotatedAt  time.Time `toml:"last_rotated_at,omitempty"`
+	// RotationIntervalDays is how often the keypair should be rotated. Zero means no schedule.
+	RotationIntervalDays int `toml:"rotation_interval_days,omitempty"`
+}
+
+// LastRotation returns when the keypair was last rotated, or when it was
+// created if it has never been rotated.
+func (m *KeyMetadata) LastRotation() time.Time {
+	if !m.LastRotatedAt.IsZero() {
+		return m.LastRotatedAt
+	}
+	return m.CreatedAt
+}
+
+// RotationDueAt returns when the next scheduled rotation is due, or the zero
+// time if no schedule is set.
+func (m *KeyMetadata) RotationDueAt() time.Time {
+	if m.RotationIntervalDays <= 0 {
+		return time.Time{}
+	}
+	return m.LastRotation().AddDate(0, 0, m.RotationIntervalDays)
 }
 
 var (
@@ -58,6 +104,7 @@ var (
 )
 
 // LoadUserConfig loads the user configuration from the config file.
+// The user's identity may be overridden by KANUKA_USER_UUID or KANUKA_USER_EMAIL.
 func LoadUserConfig() (*UserConfig, error) {
 	configPath := filepath.Join(UserKanukaSettings.UserConfigsPath, "config.toml")
 
@@ -65,17 +112,30 @@ func LoadUserConfig() (*UserConfig, error) {
 		Projects: make(map[string]UserProjectEntry),
 	}
 
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return config, nil
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		if err := LoadTOML(configPath, config); err != nil {
+			return nil, fmt.Errorf("failed to load user config: %w", err)
+		}
 	}
 
-	if err := LoadTOML(configPath, config); err != nil {
-		return nil, fmt.Errorf("failed to load user config: %w", err)
+	if err := applyUserEnvOverrides(config); err != nil {
+		return nil, err
 	}
 
 	return config, nil
 }
 
+// StrictKeyPermissionsEnabled reports whether private keys with group or
+// world permissions must be rejected, either because --strict-perms was given
+// or because the user config sets strict_key_permissions.
+func StrictKeyPermissionsEnabled() bool {
+	if UserKanukaSettings.StrictKeyPermissions {
+		return true
+	}
+	config, err := LoadUserConfig()
+	return err == nil && config.User.StrictKeyPermissions
+}
+
 // SaveUserConfig saves the user configuration to the config file.
 func SaveUserConfig(config *UserConfig) error {
 	configPath := filepath.Join(UserKanukaSettings.UserConfigsPath, "config.toml")
@@ -101,6 +161,11 @@ func EnsureUserConfig() (*UserConfig, error) {
 
 	if config.User.UUID == "" {
 		config.User.UUID = GenerateUserUUID()
+		// In headless mode the identity comes from the environment, so
+		// don't persist it to the user's config file.
+		if HasUserIdentityFromEnv() {
+			return config, nil
+		}
 		if err := SaveUserConfig(config); err != nil {
 			return nil, fmt.Errorf("failed to save user config: %w", err)
 		}
@@ -110,8 +175,25 @@ func EnsureUserConfig() (*UserConfig, error) {
 }
 
 // LoadProjectConfig loads the project configuration from the config file.
+// Configs written with an older schema are upgraded in memory by MigrateConfig.
 // Note: Caller should ensure InitProjectSettings is called before calling this function.
 func LoadProjectConfig() (*ProjectConfig, error) {
+	config, err := readProjectConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := MigrateConfig(config); err != nil {
+		return nil, fmt.Errorf("failed to load project config: %w", err)
+	}
+
+	return config, nil
+}
+
+// readProjectConfig loads the project configuration exactly as written,
+// without migrating it. A missing config file is an empty config at
+// CurrentConfigVersion.
+func readProjectConfig() (*ProjectConfig, error) {
 	configPath := filepath.Join(ProjectKanukaSettings.ProjectPath, ".kanuka", "config.toml")
 
 	config := &ProjectConfig{
@@ -120,6 +202,7 @@ func LoadProjectConfig() (*ProjectConfig, error) {
 	}
 
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		config.ConfigVersion = CurrentConfigVersion
 		return config, nil
 	}
 
@@ -130,10 +213,12 @@ func LoadProjectConfig() (*ProjectConfig, error) {
 	return config, nil
 }
 
-// SaveProjectConfig saves the project configuration to the config file.
+// SaveProjectConfig saves the project configuration to the config file,
+// stamped with CurrentConfigVersion.
 // Note: Caller should ensure InitProjectSettings is called before calling this function.
 func SaveProjectConfig(config *ProjectConfig) error {
 	configPath := filepath.Join(ProjectKanukaSettings.ProjectPath, ".kanuka", "config.toml")
+	config.ConfigVersion = CurrentConfigVersion
 
 	if err := SaveTOML(configPath, config); err != nil {
 		return fmt.Errorf("failed to save project config: %w", err)
@@ -162,6 +247,26 @@ func GetPublicKeyPath(projectUUID string) string {
 	return filepath.Join(GetKeyDirPath(projectUUID), "pubkey.pub")
 }
 
+// PendingKeyDirName is the directory under the user's keys directory that
+// holds a key pair generated with create --no-register, before it belongs to
+// any project.
+const PendingKeyDirName = "pending"
+
+// GetPendingKeyDirPath returns the path to the pending key directory.
+func GetPendingKeyDirPath() string {
+	return filepath.Join(UserKanukaSettings.UserKeysPath, PendingKeyDirName)
+}
+
+// GetPendingPrivateKeyPath returns the path to the pending private key.
+func GetPendingPrivateKeyPath() string {
+	return filepath.Join(GetPendingKeyDirPath(), "privkey")
+}
+
+// GetPendingPublicKeyPath returns the path to the pending public key.
+func GetPendingPublicKeyPath() string {
+	return filepath.Join(GetPendingKeyDirPath(), "pubkey.pub")
+}
+
 // GetKeyMetadataPath returns the path to the metadata file for a given project UUID.
 func GetKeyMetadataPath(projectUUID string) string {
 	return filepath.Join(GetKeyDirPath(projectUUID), "metadata.toml")
@@ -225,6 +330,92 @@ func UpdateKeyMetadataAccessTime(projectUUID string) error {
 	return nil
 }
 
+// FindProjectUUIDByPath returns the UUID of the project containing path, by
+// scanning the key metadata in the user's keys directory. It is useful when
+// the project UUID is needed before the project config can be read.
+//
+// path may be the project root or any directory inside it; the project whose
+// recorded path is the closest ancestor wins. Key directories without
+// readable metadata are skipped.
+//
+// Returns ErrProjectNotFound if no metadata records a project containing
+// path. Returns ErrAmbiguousProjectPath if several projects record the same
+// closest path, e.g. after a project was re-initialized in place.
+func FindProjectUUIDByPath(path string) (string, error) {
+	target, err := normalizeProjectPath(path)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", path, err)
+	}
+
+	entries, err := os.ReadDir(UserKanukaSettings.UserKeysPath)
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("reading keys directory: %w", err)
+	}
+
+	var matches []string
+	bestPath := ""
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		metadata, err := LoadKeyMetadata(entry.Name())
+		if err != nil || metadata.ProjectPath == "" {
+			continue
+		}
+		projectPath, err := normalizeProjectPath(metadata.ProjectPath)
+		if err != nil || !pathWithin(target, projectPath) {
+			continue
+		}
+
+		switch {
+		case len(projectPath) > len(bestPath):
+			bestPath = projectPath
+			matches = []string{entry.Name()}
+		case projectPath == bestPath:
+			matches = append(matches, entry.Name())
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%w: %s", kerrors.ErrProjectNotFound, target)
+	case 1:
+		return matches[0], nil
+	default:
+		sort.Strings(matches)
+		return "", fmt.Errorf("%w: %s is recorded by %s", kerrors.ErrAmbiguousProjectPath, bestPath, strings.Join(matches, ", "))
+	}
+}
+
+// normalizeProjectPath returns path as a clean absolute path with symlinks
+// resolved where possible, so that equivalent paths compare equal.
+func normalizeProjectPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved, nil
+	}
+	return abs, nil
+}
+
+// pathWithin reports whether path is dir or a directory beneath it.
+func pathWithin(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// HasUUID reports whether uuid has an entry in either [users] or [devices].
+func (pc *ProjectConfig) HasUUID(uuid string) bool {
+	_, inUsers := pc.Users[uuid]
+	_, inDevices := pc.Devices[uuid]
+	return inUsers || inDevices
+}
+
 // GetUserUUIDByEmail looks up a user UUID by their email in the project config.
 // Returns the UUID and true if found, empty string and false if not found.
 func (pc *ProjectConfig) GetUserUUIDByEmail(email string) (string, bool) {
@@ -313,6 +504,34 @@ func (pc *ProjectConfig) RemoveDevicesByEmail(email string) []string {
 	return removedUUIDs
 }
 
+// IsOwner reports whether email may run owner-only commands. Every user is an
+// owner until the project records its first owner.
+func (pc *ProjectConfig) IsOwner(email string) bool {
+	return len(pc.Project.Owners) == 0 || slices.Contains(pc.Project.Owners, email)
+}
+
+// AddOwner records email as a project owner. Returns false if it already was
+// one.
+func (pc *ProjectConfig) AddOwner(email string) bool {
+	if slices.Contains(pc.Project.Owners, email) {
+		return false
+	}
+	pc.Project.Owners = append(pc.Project.Owners, email)
+	sort.Strings(pc.Project.Owners)
+	return true
+}
+
+// RemoveOwner removes email from the project owners. Returns false if it
+// wasn't one.
+func (pc *ProjectConfig) RemoveOwner(email string) bool {
+	i := slices.Index(pc.Project.Owners, email)
+	if i < 0 {
+		return false
+	}
+	pc.Project.Owners = slices.Delete(pc.Project.Owners, i, i+1)
+	return true
+}
+
 // HasOtherDevicesForEmail checks if an email has other devices besides the given UUID.
 func (pc *ProjectConfig) HasOtherDevicesForEmail(email, excludeUUID string) bool {
 	for uuid, device := range pc.Devices {
@@ -1,10 +1,13 @@
 package configs
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 )
 
 func TestGenerateUserUUID(t *testing.T) {
@@ -481,3 +484,185 @@ func TestHasOtherDevicesForEmail(t *testing.T) {
 		}
 	})
 }
+
+func TestProjectOwners(t *testing.T) {
+	config := &ProjectConfig{}
+
+	t.Run("EveryoneIsOwnerUntilOneIsRecorded", func(t *testing.T) {
+		if !config.IsOwner("alice@example.com") {
+			t.Fatal("Expected ownership not to be enforced without owners")
+		}
+	})
+
+	t.Run("OnlyRecordedOwnersOnceEnforced", func(t *testing.T) {
+		if !config.AddOwner("bob@example.com") {
+			t.Fatal("Expected bob to be added")
+		}
+		if config.AddOwner("bob@example.com") {
+			t.Fatal("Expected adding bob twice to be a no-op")
+		}
+		if config.IsOwner("alice@example.com") {
+			t.Fatal("Expected alice not to be an owner")
+		}
+		if !config.IsOwner("bob@example.com") {
+			t.Fatal("Expected bob to be an owner")
+		}
+	})
+
+	t.Run("OwnersStaySorted", func(t *testing.T) {
+		config.AddOwner("alice@example.com")
+		if len(config.Project.Owners) != 2 || config.Project.Owners[0] != "alice@example.com" {
+			t.Fatalf("Expected sorted owners, got %v", config.Project.Owners)
+		}
+	})
+
+	t.Run("RemoveOwner", func(t *testing.T) {
+		if !config.RemoveOwner("alice@example.com") {
+			t.Fatal("Expected alice to be removed")
+		}
+		if config.RemoveOwner("alice@example.com") {
+			t.Fatal("Expected removing alice twice to be a no-op")
+		}
+		if len(config.Project.Owners) != 1 || config.Project.Owners[0] != "bob@example.com" {
+			t.Fatalf("Expected only bob to remain, got %v", config.Project.Owners)
+		}
+	})
+}
+
+func TestFutureDatedDevices(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	pc := &ProjectConfig{
+		Devices: map[string]DeviceConfig{
+			"uuid-past":   {Email: "a@example.com", Name: "past", CreatedAt: now.Add(-48 * time.Hour)},
+			"uuid-near":   {Email: "b@example.com", Name: "near", CreatedAt: now.Add(time.Hour)},
+			"uuid-future": {Email: "c@example.com", Name: "future", CreatedAt: now.AddDate(1, 0, 0)},
+		},
+	}
+
+	got := pc.FutureDatedDevices(now)
+	if len(got) != 1 || got[0] != "uuid-future" {
+		t.Errorf("Expected only uuid-future to be future-dated, got %v", got)
+	}
+}
+
+func TestExpiredDevices(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	pc := &ProjectConfig{
+		Devices: map[string]DeviceConfig{
+			"uuid-none":    {Email: "a@example.com", Name: "none"},
+			"uuid-expired": {Email: "b@example.com", Name: "expired", ExpiresAt: now.Add(-time.Second)},
+			"uuid-later":   {Email: "c@example.com", Name: "later", ExpiresAt: now.Add(time.Hour)},
+		},
+	}
+
+	got := pc.ExpiredDevices(now)
+	if len(got) != 1 || got[0] != "uuid-expired" {
+		t.Errorf("Expected only uuid-expired to be expired, got %v", got)
+	}
+}
+
+func TestKeyMetadataRotationDueAt(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rotated := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		metadata KeyMetadata
+		want     time.Time
+	}{
+		{"no schedule", KeyMetadata{CreatedAt: created, LastRotatedAt: rotated}, time.Time{}},
+		{"never rotated", KeyMetadata{CreatedAt: created, RotationIntervalDays: 30}, created.AddDate(0, 0, 30)},
+		{"rotated", KeyMetadata{CreatedAt: created, LastRotatedAt: rotated, RotationIntervalDays: 30}, rotated.AddDate(0, 0, 30)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.metadata.RotationDueAt(); !got.Equal(tt.want) {
+				t.Errorf("RotationDueAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindProjectUUIDByPath(t *testing.T) {
+	keysDir := t.TempDir()
+	oldKeysPath := UserKanukaSettings.UserKeysPath
+	UserKanukaSettings.UserKeysPath = keysDir
+	defer func() {
+		UserKanukaSettings.UserKeysPath = oldKeysPath
+	}()
+
+	projectsDir := t.TempDir()
+	apiPath := filepath.Join(projectsDir, "api")
+	webPath := filepath.Join(projectsDir, "web")
+	nestedPath := filepath.Join(webPath, "packages", "admin")
+	clonePath := filepath.Join(projectsDir, "clone")
+	for _, dir := range []string{apiPath, nestedPath, clonePath} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+
+	projects := map[string]string{
+		"11111111-1111-1111-1111-111111111111": apiPath,
+		"22222222-2222-2222-2222-222222222222": webPath,
+		"33333333-3333-3333-3333-333333333333": nestedPath,
+		"44444444-4444-4444-4444-444444444444": clonePath,
+		"55555555-5555-5555-5555-555555555555": clonePath,
+	}
+	for uuid, path := range projects {
+		if err := SaveKeyMetadata(uuid, &KeyMetadata{ProjectName: filepath.Base(path), ProjectPath: path}); err != nil {
+			t.Fatalf("Failed to save metadata for %s: %v", uuid, err)
+		}
+	}
+	// A key directory without metadata is ignored.
+	if err := os.MkdirAll(filepath.Join(keysDir, "66666666-6666-6666-6666-666666666666"), 0700); err != nil {
+		t.Fatalf("Failed to create key directory: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr error
+	}{
+		{"project root", apiPath, "11111111-1111-1111-1111-111111111111", nil},
+		{"other project", webPath, "22222222-2222-2222-2222-222222222222", nil},
+		{"subdirectory", filepath.Join(apiPath, "cmd", "server"), "11111111-1111-1111-1111-111111111111", nil},
+		{"nested project wins", filepath.Join(nestedPath, "src"), "33333333-3333-3333-3333-333333333333", nil},
+		{"unclean path", apiPath + string(filepath.Separator) + ".", "11111111-1111-1111-1111-111111111111", nil},
+		{"unknown path", filepath.Join(projectsDir, "other"), "", kerrors.ErrProjectNotFound},
+		{"sibling with shared prefix", apiPath + "-v2", "", kerrors.ErrProjectNotFound},
+		{"duplicate path", clonePath, "", kerrors.ErrAmbiguousProjectPath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindProjectUUIDByPath(tt.path)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("FindProjectUUIDByPath(%s) error = %v, want %v", tt.path, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FindProjectUUIDByPath(%s) failed: %v", tt.path, err)
+			}
+			if got != tt.want {
+				t.Errorf("FindProjectUUIDByPath(%s) = %s, want %s", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindProjectUUIDByPath_NoKeysDirectory(t *testing.T) {
+	oldKeysPath := UserKanukaSettings.UserKeysPath
+	UserKanukaSettings.UserKeysPath = filepath.Join(t.TempDir(), "missing")
+	defer func() {
+		UserKanukaSettings.UserKeysPath = oldKeysPath
+	}()
+
+	if _, err := FindProjectUUIDByPath(t.TempDir()); !errors.Is(err, kerrors.ErrProjectNotFound) {
+		t.Errorf("Expected ErrProjectNotFound, got %v", err)
+	}
+}
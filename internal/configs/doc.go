@@ -2,7 +2,7 @@
 //
 // Configuration is stored in TOML format at two levels:
 //
-//   - User config: ~/.kanuka/config.toml (user identity, registered projects)
+//   - User config: $XDG_CONFIG_HOME/kanuka/config.toml (user identity, registered projects)
 //   - Project config: .kanuka/config.toml (project settings, registered users)
 //
 // # User Configuration
@@ -25,12 +25,30 @@
 // Device metadata includes the user's email, device name, and registration
 // timestamp. A single email can have multiple devices (e.g., laptop, desktop).
 //
+// # Flag Defaults
+//
+// A project may also have a .kanuka/settings.toml, read by LoadFlagDefaults,
+// which sets default values for the secrets command's flags. Top-level keys
+// apply to every command and each table to the command it is named after.
+//
 // # Key Metadata
 //
-// Each project's keys are stored in ~/.kanuka/keys/<project-uuid>/ with
+// Each project's keys are stored in $XDG_DATA_HOME/kanuka/keys/<project-uuid>/ with
 // a metadata.toml file tracking:
-//   - Project name and path (for display purposes)
-//   - Creation and last access timestamps
+//   - Project name and path
+//   - Creation, last access, and last rotation timestamps
+//   - An optional rotation interval, checked by "kanuka secrets rotate --check"
+//
+// FindProjectUUIDByPath uses the recorded paths to find a project's UUID from
+// a working directory, without reading the project config.
+//
+// # User Directories
+//
+// XDG_CONFIG_HOME defaults to the platform config directory and
+// XDG_DATA_HOME to ~/.local/share. Older releases kept both in ~/.kanuka,
+// which is still used when neither variable is set and only it has a
+// config.toml. When an XDG variable is set, a ~/.kanuka/config.toml is moved
+// to the XDG location once at startup.
 //
 // # Settings
 //
@@ -0,0 +1,25 @@
+package configs
+
+import (
+	"sort"
+	"time"
+)
+
+// IsExpired reports whether the device's access has expired at now. Devices
+// without an expiry never expire.
+func (d DeviceConfig) IsExpired(now time.Time) bool {
+	return !d.ExpiresAt.IsZero() && now.After(d.ExpiresAt)
+}
+
+// ExpiredDevices returns the sorted UUIDs of devices whose access has
+// expired at now.
+func (pc *ProjectConfig) ExpiredDevices(now time.Time) []string {
+	var uuids []string
+	for uuid, device := range pc.Devices {
+		if device.IsExpired(now) {
+			uuids = append(uuids, uuid)
+		}
+	}
+	sort.Strings(uuids)
+	return uuids
+}
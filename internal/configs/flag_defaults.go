@@ -0,0 +1,115 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// FlagDefaultsFileName is the file in a project's .kanuka directory that sets
+// default flag values for the team.
+const FlagDefaultsFileName = "settings.toml"
+
+// FlagDefaults holds the default flag values from .kanuka/settings.toml.
+// Keys are flag names without the leading dashes, and each value is the
+// text passed to the flag, once per element for arrays.
+//
+//	verbose = true
+//	strict-perms = true
+//
+//	[create]
+//	key-type = "ed25519"
+type FlagDefaults struct {
+	// Global holds the top-level keys, which apply to every secrets command.
+	Global map[string][]string
+
+	// Commands maps a secrets subcommand name, such as "encrypt", to the
+	// defaults for its flags.
+	Commands map[string]map[string][]string
+}
+
+// GetFlagDefaultsPath returns the path of the settings file in the project
+// at projectPath.
+func GetFlagDefaultsPath(projectPath string) string {
+	return filepath.Join(projectPath, ".kanuka", FlagDefaultsFileName)
+}
+
+// LoadFlagDefaults reads .kanuka/settings.toml from the project at
+// projectPath. It returns an empty FlagDefaults if the file doesn't exist,
+// and an error if it isn't valid TOML or holds a value that can't be a flag
+// value, such as a table nested inside a command's table.
+func LoadFlagDefaults(projectPath string) (*FlagDefaults, error) {
+	defaults := &FlagDefaults{
+		Global:   make(map[string][]string),
+		Commands: make(map[string]map[string][]string),
+	}
+
+	path := GetFlagDefaultsPath(projectPath)
+	var raw map[string]any
+	if _, err := toml.DecodeFile(path, &raw); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return defaults, nil
+		}
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for key, value := range raw {
+		table, ok := value.(map[string]any)
+		if !ok {
+			values, err := flagValueStrings(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s in %s: %w", key, path, err)
+			}
+			defaults.Global[key] = values
+			continue
+		}
+
+		commandDefaults := make(map[string][]string, len(table))
+		for flagName, flagValue := range table {
+			values, err := flagValueStrings(flagValue)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value for %s.%s in %s: %w", key, flagName, path, err)
+			}
+			commandDefaults[flagName] = values
+		}
+		defaults.Commands[key] = commandDefaults
+	}
+
+	return defaults, nil
+}
+
+// flagValueStrings converts a decoded TOML value to the text a flag parses.
+func flagValueStrings(value any) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case bool:
+		return []string{strconv.FormatBool(v)}, nil
+	case int64:
+		return []string{strconv.FormatInt(v, 10)}, nil
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}, nil
+	case time.Time:
+		return []string{v.Format(time.RFC3339)}, nil
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, element := range v {
+			if _, nested := element.([]any); nested {
+				return nil, fmt.Errorf("arrays may only hold strings, numbers, or booleans")
+			}
+			elementValues, err := flagValueStrings(element)
+			if err != nil {
+				return nil, fmt.Errorf("arrays may only hold strings, numbers, or booleans")
+			}
+			values = append(values, elementValues...)
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("expected a string, number, boolean, or array, got %T", value)
+	}
+}
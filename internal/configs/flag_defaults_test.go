@@ -0,0 +1,75 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFlagDefaults(t *testing.T, content string) string {
+	t.Helper()
+	projectPath := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(projectPath, ".kanuka"), 0700); err != nil {
+		t.Fatalf("Failed to create .kanuka: %v", err)
+	}
+	if err := os.WriteFile(GetFlagDefaultsPath(projectPath), []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+	return projectPath
+}
+
+func TestLoadFlagDefaults(t *testing.T) {
+	projectPath := writeFlagDefaults(t, `verbose = true
+max-depth = 3
+
+[encrypt]
+name-filter = ".env.prod*"
+jobs = 2
+
+[export]
+exclude = ["a", "b"]
+`)
+
+	defaults, err := LoadFlagDefaults(projectPath)
+	if err != nil {
+		t.Fatalf("LoadFlagDefaults failed: %v", err)
+	}
+
+	wantGlobal := map[string][]string{"verbose": {"true"}, "max-depth": {"3"}}
+	if !reflect.DeepEqual(defaults.Global, wantGlobal) {
+		t.Errorf("Expected global defaults %v, got %v", wantGlobal, defaults.Global)
+	}
+	wantCommands := map[string]map[string][]string{
+		"encrypt": {"name-filter": {".env.prod*"}, "jobs": {"2"}},
+		"export":  {"exclude": {"a", "b"}},
+	}
+	if !reflect.DeepEqual(defaults.Commands, wantCommands) {
+		t.Errorf("Expected command defaults %v, got %v", wantCommands, defaults.Commands)
+	}
+}
+
+func TestLoadFlagDefaults_MissingFile(t *testing.T) {
+	defaults, err := LoadFlagDefaults(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error for a missing file, got: %v", err)
+	}
+	if len(defaults.Global) != 0 || len(defaults.Commands) != 0 {
+		t.Errorf("Expected empty defaults, got %+v", defaults)
+	}
+}
+
+func TestLoadFlagDefaults_InvalidValues(t *testing.T) {
+	tests := map[string]string{
+		"nested table": "[encrypt.backup]\nenabled = true\n",
+		"nested array": "exclude = [[\"a\"]]\n",
+		"invalid TOML": "verbose = \n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadFlagDefaults(writeFlagDefaults(t, content)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package configs
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/PolarWolf314/kanuka/internal/utils"
+)
+
+// HasUserIdentityFromEnv reports whether the acting user is specified through
+// KANUKA_USER_UUID or KANUKA_USER_EMAIL rather than the user's config file.
+func HasUserIdentityFromEnv() bool {
+	return os.Getenv(utils.UserUUIDEnvVar) != "" || os.Getenv(utils.UserEmailEnvVar) != ""
+}
+
+// applyUserEnvOverrides overrides the user's identity from the environment.
+//
+// KANUKA_USER_UUID is used as-is. Otherwise KANUKA_USER_EMAIL is looked up in
+// the current project's config; if it maps to exactly one device, that
+// device's UUID is used. An email that is not registered leaves the UUID
+// untouched, so the command fails with the usual "no access" error. An email
+// with several devices is ambiguous and returns an error.
+func applyUserEnvOverrides(config *UserConfig) error {
+	email := os.Getenv(utils.UserEmailEnvVar)
+	if email != "" {
+		config.User.Email = email
+	}
+
+	if uuid := os.Getenv(utils.UserUUIDEnvVar); uuid != "" {
+		config.User.UUID = uuid
+		return nil
+	}
+
+	if email == "" || ProjectKanukaSettings == nil || ProjectKanukaSettings.ProjectPath == "" {
+		return nil
+	}
+
+	projectConfig, err := LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", utils.UserEmailEnvVar, err)
+	}
+
+	uuids := projectConfig.GetAllUserUUIDsByEmail(email)
+	switch len(uuids) {
+	case 0:
+		return nil
+	case 1:
+		config.User.UUID = uuids[0]
+		return nil
+	default:
+		return fmt.Errorf("%s %q matches %d devices in this project; set %s to choose one",
+			utils.UserEmailEnvVar, email, len(uuids), utils.UserUUIDEnvVar)
+	}
+}
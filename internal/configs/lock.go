@@ -0,0 +1,121 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+)
+
+// ProjectLockTimeout is how long LockProject waits for another operation to
+// release the project lock before giving up.
+var ProjectLockTimeout = 10 * time.Second
+
+// projectLockRetryInterval is how often LockProject retries a held lock.
+const projectLockRetryInterval = 50 * time.Millisecond
+
+// ProjectLockFileName is the lock file in .kanuka, which git ignores.
+const ProjectLockFileName = ".lock"
+
+// errLockHeld is returned by tryLockFile when another process holds the lock.
+var errLockHeld = errors.New("lock is held")
+
+// ProjectLock is an exclusive advisory lock on the project's .kanuka directory.
+type ProjectLock struct {
+	file *os.File
+}
+
+// LockProject takes the project's advisory lock, .kanuka/.lock, so that only
+// one operation at a time can load, modify, and save the project config.
+// Workflows that mutate the project take it before loading the config and
+// release it with Unlock once they are done; read-only commands don't.
+//
+// The lock is held with flock on Unix and LockFileEx on Windows, so it is
+// released by the OS if the process dies. The lock file is added to
+// .kanuka/.gitignore so it is never committed.
+//
+// Note: Caller should ensure InitProjectSettings is called before calling this function.
+//
+// Returns ErrProjectNotInitialized if there is no project.
+// Returns ErrProjectLocked if the lock is still held after ProjectLockTimeout.
+func LockProject() (*ProjectLock, error) {
+	if ProjectKanukaSettings.ProjectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+	return LockProjectAt(ProjectKanukaSettings.ProjectPath)
+}
+
+// LockProjectAt takes the advisory lock of the project at projectPath, for
+// workflows that write to a project other than the current one. It works
+// like LockProject, and projectPath/.kanuka must exist.
+func LockProjectAt(projectPath string) (*ProjectLock, error) {
+	lockPath := filepath.Join(projectPath, ".kanuka", ProjectLockFileName)
+	file, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("opening project lock: %w", err)
+	}
+	if err := IgnoreInKanukaDir(projectPath, ProjectLockFileName); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	deadline := time.Now().Add(ProjectLockTimeout)
+	for {
+		err := tryLockFile(file)
+		if err == nil {
+			return &ProjectLock{file: file}, nil
+		}
+		if !errors.Is(err, errLockHeld) {
+			file.Close()
+			return nil, fmt.Errorf("acquiring project lock: %w", err)
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("%w: timed out after %s waiting for %s", kerrors.ErrProjectLocked, ProjectLockTimeout, lockPath)
+		}
+		time.Sleep(projectLockRetryInterval)
+	}
+}
+
+// Unlock releases the lock. It is safe to call on a nil lock.
+func (l *ProjectLock) Unlock() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	err := unlockFile(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	return err
+}
+
+// IgnoreInKanukaDir adds name to .kanuka/.gitignore, creating it if needed,
+// so a local-only file in .kanuka is never committed.
+func IgnoreInKanukaDir(projectPath, name string) error {
+	gitignorePath := filepath.Join(projectPath, ".kanuka", ".gitignore")
+	// #nosec G304 -- the path is inside the project's .kanuka directory.
+	content, err := os.ReadFile(gitignorePath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading %s: %w", gitignorePath, err)
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == name {
+			return nil
+		}
+	}
+
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		content = append(content, '\n')
+	}
+	content = append(content, name+"\n"...)
+	// #nosec G306 -- .gitignore is committed and holds no secrets.
+	if err := os.WriteFile(gitignorePath, content, 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", gitignorePath, err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+)
+
+// setupLockProject points ProjectKanukaSettings at a new project with an
+// empty config.
+func setupLockProject(t *testing.T) {
+	t.Helper()
+	tempDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tempDir, ".kanuka"), 0755); err != nil {
+		t.Fatalf("Failed to create .kanuka dir: %v", err)
+	}
+
+	originalSettings := ProjectKanukaSettings
+	ProjectKanukaSettings = &ProjectSettings{ProjectPath: tempDir}
+	t.Cleanup(func() { ProjectKanukaSettings = originalSettings })
+
+	config := &ProjectConfig{
+		Project: Project{UUID: "project-uuid", Name: "lock-test"},
+		Users:   map[string]string{},
+		Devices: map[string]DeviceConfig{},
+	}
+	if err := SaveProjectConfig(config); err != nil {
+		t.Fatalf("Failed to save project config: %v", err)
+	}
+}
+
+func TestLockProject_ConcurrentSavesDontLoseUpdates(t *testing.T) {
+	setupLockProject(t)
+
+	const workers = 2
+	const perWorker = 20
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*perWorker)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				errs <- addUserLocked(fmt.Sprintf("uuid-%d-%d", w, i), fmt.Sprintf("user%d-%d@example.com", w, i))
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Locked update failed: %v", err)
+		}
+	}
+
+	config, err := LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Config is corrupt after concurrent saves: %v", err)
+	}
+	if len(config.Users) != workers*perWorker {
+		t.Errorf("Expected %d users after concurrent saves, got %d", workers*perWorker, len(config.Users))
+	}
+}
+
+// addUserLocked adds a user to the project config under the project lock.
+func addUserLocked(uuid, email string) error {
+	lock, err := LockProject()
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	config, err := LoadProjectConfig()
+	if err != nil {
+		return err
+	}
+	config.Users[uuid] = email
+	config.Devices[uuid] = DeviceConfig{Email: email, Name: "device"}
+	return SaveProjectConfig(config)
+}
+
+func TestLockProject_TimesOutWhileHeld(t *testing.T) {
+	setupLockProject(t)
+
+	originalTimeout := ProjectLockTimeout
+	ProjectLockTimeout = 100 * time.Millisecond
+	defer func() { ProjectLockTimeout = originalTimeout }()
+
+	lock, err := LockProject()
+	if err != nil {
+		t.Fatalf("LockProject failed: %v", err)
+	}
+
+	if _, err := LockProject(); !errors.Is(err, kerrors.ErrProjectLocked) {
+		t.Fatalf("Expected ErrProjectLocked while the lock is held, got %v", err)
+	}
+
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock failed: %v", err)
+	}
+	second, err := LockProject()
+	if err != nil {
+		t.Fatalf("Expected the lock to be free after Unlock, got %v", err)
+	}
+	second.Unlock()
+}
+
+func TestLockProject_NoProject(t *testing.T) {
+	originalSettings := ProjectKanukaSettings
+	ProjectKanukaSettings = &ProjectSettings{}
+	defer func() { ProjectKanukaSettings = originalSettings }()
+
+	if _, err := LockProject(); !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Errorf("Expected ErrProjectNotInitialized, got %v", err)
+	}
+}
+
+func TestLockProject_IgnoresLockFile(t *testing.T) {
+	setupLockProject(t)
+	gitignorePath := filepath.Join(ProjectKanukaSettings.ProjectPath, ".kanuka", ".gitignore")
+	if err := os.WriteFile(gitignorePath, []byte(".decrypt-state"), 0644); err != nil {
+		t.Fatalf("Failed to write .gitignore: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		lock, err := LockProject()
+		if err != nil {
+			t.Fatalf("LockProject failed: %v", err)
+		}
+		lock.Unlock()
+	}
+
+	data, err := os.ReadFile(gitignorePath)
+	if err != nil {
+		t.Fatalf("Failed to read .gitignore: %v", err)
+	}
+	if want := ".decrypt-state\n.lock\n"; string(data) != want {
+		t.Errorf(".gitignore = %q, want %q", data, want)
+	}
+}
@@ -0,0 +1,160 @@
+package configs
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MergeConflict describes a key that was changed incompatibly on both sides of a merge.
+type MergeConflict struct {
+	// Section is the config section containing the conflict ("project", "users", or "devices").
+	Section string
+
+	// Key is the conflicting key within the section (a UUID for users and devices).
+	Key string
+
+	// Ours is a description of our side's value, or empty if ours removed it.
+	Ours string
+
+	// Theirs is a description of their side's value, or empty if theirs removed it.
+	Theirs string
+}
+
+// String returns a human-readable description of the conflict.
+func (c MergeConflict) String() string {
+	describe := func(v string) string {
+		if v == "" {
+			return "<removed>"
+		}
+		return v
+	}
+	return fmt.Sprintf("%s.%s: ours=%s theirs=%s", c.Section, c.Key, describe(c.Ours), describe(c.Theirs))
+}
+
+// MergeProjectConfigs performs a structural three-way merge of project configs.
+//
+// Users and devices are merged per UUID. Additions from either side are kept,
+// and a removal on one side is honored if the other side left the entry
+// unchanged, so revocations are never silently undone. If base is nil the merge
+// is a plain union of ours and theirs.
+//
+// When both sides changed the same key differently, the key is reported as a
+// conflict and our value is kept in the merged config. The returned conflicts
+// are ordered by section (project, users, devices) and then by key.
+//
+// The configs must already be migrated with MigrateConfig. The merged config
+// is stamped with CurrentConfigVersion.
+func MergeProjectConfigs(base, ours, theirs *ProjectConfig) (*ProjectConfig, []MergeConflict) {
+	if base == nil {
+		base = &ProjectConfig{}
+	}
+
+	merged := &ProjectConfig{
+		ConfigVersion: CurrentConfigVersion,
+		Users:         make(map[string]string),
+		Devices:       make(map[string]DeviceConfig),
+	}
+	var conflicts []MergeConflict
+
+	// Project identity.
+	switch {
+	case ours.Project.Equal(theirs.Project), theirs.Project.Equal(base.Project):
+		merged.Project = ours.Project
+	case ours.Project.Equal(base.Project):
+		merged.Project = theirs.Project
+	default:
+		merged.Project = ours.Project
+		conflicts = append(conflicts, MergeConflict{
+			Section: "project",
+			Key:     "project",
+			Ours:    fmt.Sprintf("%s (%s)", ours.Project.Name, ours.Project.UUID),
+			Theirs:  fmt.Sprintf("%s (%s)", theirs.Project.Name, theirs.Project.UUID),
+		})
+	}
+
+	for _, key := range unionKeys(base.Users, ours.Users, theirs.Users) {
+		value, keep, conflict := mergeValue(base.Users, ours.Users, theirs.Users, key, func(a, b string) bool { return a == b })
+		if conflict {
+			conflicts = append(conflicts, MergeConflict{
+				Section: "users",
+				Key:     key,
+				Ours:    ours.Users[key],
+				Theirs:  theirs.Users[key],
+			})
+		}
+		if keep {
+			merged.Users[key] = value
+		}
+	}
+
+	for _, key := range unionKeys(base.Devices, ours.Devices, theirs.Devices) {
+		value, keep, conflict := mergeValue(base.Devices, ours.Devices, theirs.Devices, key, devicesEqual)
+		if conflict {
+			conflicts = append(conflicts, MergeConflict{
+				Section: "devices",
+				Key:     key,
+				Ours:    describeDevice(ours.Devices, key),
+				Theirs:  describeDevice(theirs.Devices, key),
+			})
+		}
+		if keep {
+			merged.Devices[key] = value
+		}
+	}
+
+	return merged, conflicts
+}
+
+// mergeValue resolves a single key in a three-way merge.
+// It returns the merged value, whether the key should be present, and whether
+// the two sides conflict (in which case our side wins).
+func mergeValue[V any](base, ours, theirs map[string]V, key string, equal func(a, b V) bool) (V, bool, bool) {
+	baseVal, inBase := base[key]
+	oursVal, inOurs := ours[key]
+	theirsVal, inTheirs := theirs[key]
+
+	oursChanged := inOurs != inBase || !equal(oursVal, baseVal)
+	theirsChanged := inTheirs != inBase || !equal(theirsVal, baseVal)
+
+	switch {
+	case !theirsChanged:
+		return oursVal, inOurs, false
+	case !oursChanged:
+		return theirsVal, inTheirs, false
+	case inOurs == inTheirs && equal(oursVal, theirsVal):
+		// Both sides made the same change.
+		return oursVal, inOurs, false
+	default:
+		return oursVal, inOurs, true
+	}
+}
+
+// devicesEqual reports whether two device entries are equivalent.
+func devicesEqual(a, b DeviceConfig) bool {
+	return a.Email == b.Email && a.Name == b.Name && a.CreatedAt.Equal(b.CreatedAt) && a.ExpiresAt.Equal(b.ExpiresAt)
+}
+
+// unionKeys returns the sorted union of keys across the given maps.
+func unionKeys[V any](maps ...map[string]V) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// describeDevice returns a short description of a device for conflict reports.
+func describeDevice(devices map[string]DeviceConfig, key string) string {
+	device, ok := devices[key]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", device.Email, device.Name)
+}
@@ -0,0 +1,145 @@
+package configs
+
+import (
+	"testing"
+	"time"
+)
+
+func newMergeTestConfig(users map[string]string) *ProjectConfig {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	pc := &ProjectConfig{
+		Project: Project{UUID: "project-uuid", Name: "test-project"},
+		Users:   make(map[string]string),
+		Devices: make(map[string]DeviceConfig),
+	}
+	for uuid, email := range users {
+		pc.Users[uuid] = email
+		pc.Devices[uuid] = DeviceConfig{Email: email, Name: "device-" + uuid, CreatedAt: created}
+	}
+	return pc
+}
+
+func TestMergeProjectConfigs_UnionOfAdditions(t *testing.T) {
+	base := newMergeTestConfig(map[string]string{"u1": "alice@example.com"})
+	ours := newMergeTestConfig(map[string]string{"u1": "alice@example.com", "u2": "bob@example.com"})
+	theirs := newMergeTestConfig(map[string]string{"u1": "alice@example.com", "u3": "carol@example.com"})
+
+	merged, conflicts := MergeProjectConfigs(base, ours, theirs)
+
+	if len(conflicts) != 0 {
+		t.Fatalf("Expected no conflicts, got %v", conflicts)
+	}
+	for _, uuid := range []string{"u1", "u2", "u3"} {
+		if _, ok := merged.Users[uuid]; !ok {
+			t.Errorf("Expected user %s in merged config", uuid)
+		}
+		if _, ok := merged.Devices[uuid]; !ok {
+			t.Errorf("Expected device %s in merged config", uuid)
+		}
+	}
+	if !merged.Project.Equal(ours.Project) {
+		t.Errorf("Expected project to be preserved, got %+v", merged.Project)
+	}
+}
+
+func TestMergeProjectConfigs_HonorsRevocation(t *testing.T) {
+	base := newMergeTestConfig(map[string]string{"u1": "alice@example.com", "u2": "bob@example.com"})
+	// Ours revoked bob.
+	ours := newMergeTestConfig(map[string]string{"u1": "alice@example.com"})
+	// Theirs added carol but left bob untouched.
+	theirs := newMergeTestConfig(map[string]string{"u1": "alice@example.com", "u2": "bob@example.com", "u3": "carol@example.com"})
+
+	merged, conflicts := MergeProjectConfigs(base, ours, theirs)
+
+	if len(conflicts) != 0 {
+		t.Fatalf("Expected no conflicts, got %v", conflicts)
+	}
+	if _, ok := merged.Users["u2"]; ok {
+		t.Errorf("Expected revoked user u2 to stay removed")
+	}
+	if _, ok := merged.Devices["u2"]; ok {
+		t.Errorf("Expected revoked device u2 to stay removed")
+	}
+	if _, ok := merged.Users["u3"]; !ok {
+		t.Errorf("Expected added user u3 to be kept")
+	}
+}
+
+func TestMergeProjectConfigs_ConflictingEmail(t *testing.T) {
+	base := newMergeTestConfig(map[string]string{"u1": "alice@example.com"})
+	ours := newMergeTestConfig(map[string]string{"u1": "alice@example.com", "u2": "bob@example.com"})
+	theirs := newMergeTestConfig(map[string]string{"u1": "alice@example.com", "u2": "mallory@example.com"})
+
+	merged, conflicts := MergeProjectConfigs(base, ours, theirs)
+
+	if len(conflicts) != 2 {
+		t.Fatalf("Expected user and device conflicts, got %v", conflicts)
+	}
+	if conflicts[0].Section != "users" || conflicts[0].Key != "u2" {
+		t.Errorf("Expected users.u2 conflict first, got %+v", conflicts[0])
+	}
+	if conflicts[1].Section != "devices" || conflicts[1].Key != "u2" {
+		t.Errorf("Expected devices.u2 conflict second, got %+v", conflicts[1])
+	}
+	if merged.Users["u2"] != "bob@example.com" {
+		t.Errorf("Expected our value to be kept on conflict, got %s", merged.Users["u2"])
+	}
+}
+
+func TestMergeProjectConfigs_RemovedVersusModified(t *testing.T) {
+	base := newMergeTestConfig(map[string]string{"u1": "alice@example.com"})
+	ours := newMergeTestConfig(map[string]string{})
+	theirs := newMergeTestConfig(map[string]string{"u1": "alice@example.com"})
+	theirs.Devices["u1"] = DeviceConfig{Email: "alice@example.com", Name: "renamed"}
+
+	_, conflicts := MergeProjectConfigs(base, ours, theirs)
+
+	if len(conflicts) != 1 || conflicts[0].Section != "devices" {
+		t.Fatalf("Expected a device conflict, got %v", conflicts)
+	}
+	if conflicts[0].Ours != "" {
+		t.Errorf("Expected ours to be reported as removed, got %q", conflicts[0].Ours)
+	}
+}
+
+func TestMergeProjectConfigs_NoBaseIsUnion(t *testing.T) {
+	ours := newMergeTestConfig(map[string]string{"u1": "alice@example.com"})
+	theirs := newMergeTestConfig(map[string]string{"u2": "bob@example.com"})
+
+	merged, conflicts := MergeProjectConfigs(nil, ours, theirs)
+
+	if len(conflicts) != 0 {
+		t.Fatalf("Expected no conflicts, got %v", conflicts)
+	}
+	if len(merged.Users) != 2 || len(merged.Devices) != 2 {
+		t.Errorf("Expected union of 2 users and 2 devices, got %d users, %d devices", len(merged.Users), len(merged.Devices))
+	}
+}
+
+func TestMergeProjectConfigs_ProjectConflict(t *testing.T) {
+	base := newMergeTestConfig(nil)
+	ours := newMergeTestConfig(nil)
+	ours.Project.Name = "ours-name"
+	theirs := newMergeTestConfig(nil)
+	theirs.Project.Name = "theirs-name"
+
+	merged, conflicts := MergeProjectConfigs(base, ours, theirs)
+
+	if len(conflicts) != 1 || conflicts[0].Section != "project" {
+		t.Fatalf("Expected a project conflict, got %v", conflicts)
+	}
+	if merged.Project.Name != "ours-name" {
+		t.Errorf("Expected our project name to be kept, got %s", merged.Project.Name)
+	}
+}
+
+func TestMergeProjectConfigs_StampsCurrentVersion(t *testing.T) {
+	ours := newMergeTestConfig(map[string]string{"u1": "alice@example.com"})
+	theirs := newMergeTestConfig(map[string]string{"u2": "bob@example.com"})
+
+	merged, _ := MergeProjectConfigs(nil, ours, theirs)
+
+	if merged.ConfigVersion != CurrentConfigVersion {
+		t.Errorf("Expected config_version %d, got %d", CurrentConfigVersion, merged.ConfigVersion)
+	}
+}
@@ -0,0 +1,154 @@
+package configs
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+)
+
+// ProjectConfigKeys lists the dotted keys accepted by GetProjectConfigValue
+// and SetProjectConfigValue. <uuid> stands for a device's user UUID.
+var ProjectConfigKeys = []string{
+	"project.name",
+	"project.uuid",
+	"project.owners",
+	"devices.<uuid>.email",
+	"devices.<uuid>.name",
+	"devices.<uuid>.created_at",
+	"devices.<uuid>.expires_at",
+}
+
+// Get returns the value of a dotted key such as "project.name".
+//
+// Returns ErrUnknownConfigKey if key is not one of ProjectConfigKeys, or
+// ErrDeviceNotFound if a devices key names a UUID not in the config.
+func (pc *ProjectConfig) Get(key string) (string, error) {
+	switch key {
+	case "project.name":
+		return pc.Project.Name, nil
+	case "project.uuid":
+		return pc.Project.UUID, nil
+	case "project.owners":
+		return strings.Join(pc.Project.Owners, ","), nil
+	}
+
+	uuid, field, ok := splitDeviceKey(key)
+	if !ok {
+		return "", unknownConfigKeyError(key)
+	}
+	device, exists := pc.Devices[uuid]
+	if !exists {
+		return "", fmt.Errorf("%w: %s", kerrors.ErrDeviceNotFound, uuid)
+	}
+
+	switch field {
+	case "email":
+		return device.Email, nil
+	case "name":
+		return device.Name, nil
+	case "expires_at":
+		if device.ExpiresAt.IsZero() {
+			return "", nil
+		}
+		return device.ExpiresAt.Format(time.RFC3339), nil
+	default:
+		if device.CreatedAt.IsZero() {
+			return "", nil
+		}
+		return device.CreatedAt.Format(time.RFC3339), nil
+	}
+}
+
+// Set validates value and assigns it to a dotted key. Only project.name can
+// be set; the project UUID, owners, and device entries are managed by Kanuka.
+//
+// Returns ErrUnknownConfigKey if key is not one of ProjectConfigKeys,
+// ErrReadOnlyConfigKey if key can't be set, or ErrInvalidConfigValue if
+// value is not valid for key.
+func (pc *ProjectConfig) Set(key, value string) error {
+	switch key {
+	case "project.name":
+		if err := validateProjectName(value); err != nil {
+			return err
+		}
+		pc.Project.Name = value
+		return nil
+	case "project.uuid":
+		return fmt.Errorf("%w: %s identifies the project and can't be changed", kerrors.ErrReadOnlyConfigKey, key)
+	case "project.owners":
+		return fmt.Errorf("%w: %s is managed by transfer-ownership", kerrors.ErrReadOnlyConfigKey, key)
+	}
+
+	if _, _, ok := splitDeviceKey(key); ok {
+		return fmt.Errorf("%w: %s is managed by register, revoke, and set-project-device", kerrors.ErrReadOnlyConfigKey, key)
+	}
+	return unknownConfigKeyError(key)
+}
+
+// GetProjectConfigValue loads the project config and returns the value of a
+// dotted key. See (*ProjectConfig).Get.
+// Note: Caller should ensure InitProjectSettings is called before calling this function.
+func GetProjectConfigValue(key string) (string, error) {
+	config, err := LoadProjectConfig()
+	if err != nil {
+		return "", err
+	}
+	return config.Get(key)
+}
+
+// SetProjectConfigValue loads the project config, sets a dotted key, and
+// saves it while holding the project lock. See (*ProjectConfig).Set.
+// Note: Caller should ensure InitProjectSettings is called before calling this function.
+func SetProjectConfigValue(key, value string) error {
+	lock, err := LockProject()
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	config, err := LoadProjectConfig()
+	if err != nil {
+		return err
+	}
+	if err := config.Set(key, value); err != nil {
+		return err
+	}
+	return SaveProjectConfig(config)
+}
+
+// splitDeviceKey splits "devices.<uuid>.<field>" into its UUID and field.
+func splitDeviceKey(key string) (uuid, field string, ok bool) {
+	rest, found := strings.CutPrefix(key, "devices.")
+	if !found {
+		return "", "", false
+	}
+	i := strings.LastIndex(rest, ".")
+	if i <= 0 {
+		return "", "", false
+	}
+	uuid, field = rest[:i], rest[i+1:]
+	switch field {
+	case "email", "name", "created_at", "expires_at":
+		return uuid, field, true
+	}
+	return "", "", false
+}
+
+// validateProjectName checks that a project name is non-empty and fits on one line.
+func validateProjectName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%w: project.name can't be empty", kerrors.ErrInvalidConfigValue)
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return fmt.Errorf("%w: project.name can't contain control characters", kerrors.ErrInvalidConfigValue)
+	}
+	return nil
+}
+
+// unknownConfigKeyError returns ErrUnknownConfigKey with the valid keys listed.
+func unknownConfigKeyError(key string) error {
+	return fmt.Errorf("%w: %q (valid keys: %s)", kerrors.ErrUnknownConfigKey, key, strings.Join(ProjectConfigKeys, ", "))
+}
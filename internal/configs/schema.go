@@ -0,0 +1,73 @@
+package configs
+
+import (
+	"fmt"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+)
+
+// MigratedDeviceName is the name given to the device synthesized for a user
+// whose config entry predates devices.
+const MigratedDeviceName = "default"
+
+// configMigrations upgrades a project config by one schema version each. The
+// migration at index i takes a config from version i to version i+1.
+var configMigrations = []func(*ProjectConfig){
+	migrateConfigV0ToV1,
+}
+
+// CurrentConfigVersion is the project config schema version this build writes.
+// It must equal len(configMigrations).
+const CurrentConfigVersion = 1
+
+// MigrateConfig upgrades a project config loaded from an older schema to
+// CurrentConfigVersion in memory. Nothing is written to disk; the upgraded
+// shape is persisted the next time the config is saved.
+//
+// Returns ErrUnsupportedConfigVersion if the config was written by a newer
+// version of Kanuka, since its shape can't be safely interpreted.
+func MigrateConfig(config *ProjectConfig) error {
+	if err := checkConfigVersion(config); err != nil {
+		return err
+	}
+
+	if config.Users == nil {
+		config.Users = make(map[string]string)
+	}
+	if config.Devices == nil {
+		config.Devices = make(map[string]DeviceConfig)
+	}
+
+	for config.ConfigVersion < CurrentConfigVersion {
+		configMigrations[config.ConfigVersion](config)
+		config.ConfigVersion++
+	}
+	return nil
+}
+
+// checkConfigVersion returns ErrUnsupportedConfigVersion if config's schema
+// version can't be read by this build.
+func checkConfigVersion(config *ProjectConfig) error {
+	if config.ConfigVersion > CurrentConfigVersion {
+		return fmt.Errorf("%w: config version %d is newer than %d, upgrade Kanuka to read it",
+			kerrors.ErrUnsupportedConfigVersion, config.ConfigVersion, CurrentConfigVersion)
+	}
+	if config.ConfigVersion < 0 {
+		return fmt.Errorf("%w: invalid config version %d", kerrors.ErrUnsupportedConfigVersion, config.ConfigVersion)
+	}
+	return nil
+}
+
+// migrateConfigV0ToV1 gives every user without a device a single default
+// device. Configs from before the device model only had a [users] entry.
+func migrateConfigV0ToV1(config *ProjectConfig) {
+	for uuid, email := range config.Users {
+		if _, ok := config.Devices[uuid]; ok {
+			continue
+		}
+		config.Devices[uuid] = DeviceConfig{
+			Email: email,
+			Name:  MigratedDeviceName,
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package configs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+)
+
+func TestCurrentConfigVersionMatchesMigrations(t *testing.T) {
+	if CurrentConfigVersion != len(configMigrations) {
+		t.Fatalf("CurrentConfigVersion is %d but there are %d migrations", CurrentConfigVersion, len(configMigrations))
+	}
+}
+
+func TestMigrateConfig(t *testing.T) {
+	t.Run("V0WithoutDevicesGetsDefaultDevices", func(t *testing.T) {
+		config := &ProjectConfig{
+			Project: Project{UUID: "project-uuid-123", Name: "test-project"},
+			Users: map[string]string{
+				"user-uuid-1": "alice@example.com",
+				"user-uuid-2": "bob@example.com",
+			},
+		}
+
+		if err := MigrateConfig(config); err != nil {
+			t.Fatalf("MigrateConfig failed: %v", err)
+		}
+
+		if config.ConfigVersion != CurrentConfigVersion {
+			t.Errorf("Expected version %d, got %d", CurrentConfigVersion, config.ConfigVersion)
+		}
+		for uuid, email := range config.Users {
+			device, ok := config.Devices[uuid]
+			if !ok {
+				t.Errorf("Expected a device for %s", uuid)
+				continue
+			}
+			if device.Email != email || device.Name != MigratedDeviceName {
+				t.Errorf("Expected device %s for %s, got %+v", MigratedDeviceName, email, device)
+			}
+		}
+	})
+
+	t.Run("V0KeepsExistingDevices", func(t *testing.T) {
+		config := &ProjectConfig{
+			Users:   map[string]string{"user-uuid-1": "alice@example.com"},
+			Devices: map[string]DeviceConfig{"user-uuid-1": {Email: "alice@example.com", Name: "macbook"}},
+		}
+
+		if err := MigrateConfig(config); err != nil {
+			t.Fatalf("MigrateConfig failed: %v", err)
+		}
+
+		if got := config.Devices["user-uuid-1"].Name; got != "macbook" {
+			t.Errorf("Expected existing device to be kept, got name %q", got)
+		}
+	})
+
+	t.Run("CurrentVersionIsUnchanged", func(t *testing.T) {
+		config := &ProjectConfig{
+			ConfigVersion: CurrentConfigVersion,
+			Users:         map[string]string{"user-uuid-1": "alice@example.com"},
+		}
+
+		if err := MigrateConfig(config); err != nil {
+			t.Fatalf("MigrateConfig failed: %v", err)
+		}
+
+		if len(config.Devices) != 0 {
+			t.Errorf("Expected a current config not to be migrated, got devices %v", config.Devices)
+		}
+	})
+
+	t.Run("NewerVersionIsRejected", func(t *testing.T) {
+		config := &ProjectConfig{ConfigVersion: CurrentConfigVersion + 1}
+
+		err := MigrateConfig(config)
+		if !errors.Is(err, kerrors.ErrUnsupportedConfigVersion) {
+			t.Fatalf("Expected ErrUnsupportedConfigVersion, got %v", err)
+		}
+	})
+}
+
+func TestLoadProjectConfigMigratesV0(t *testing.T) {
+	tempDir := t.TempDir()
+	oldProjectPath := ProjectKanukaSettings.ProjectPath
+	ProjectKanukaSettings.ProjectPath = tempDir
+	defer func() {
+		ProjectKanukaSettings.ProjectPath = oldProjectPath
+	}()
+
+	kanukaDir := filepath.Join(tempDir, ".kanuka")
+	if err := os.MkdirAll(kanukaDir, 0700); err != nil {
+		t.Fatalf("Failed to create .kanuka directory: %v", err)
+	}
+
+	// A config from before devices and versioning.
+	v0 := `[project]
+project_uuid = "project-uuid-123"
+name = "test-project"
+
+[users]
+user-uuid-1 = "alice@example.com"
+`
+	configPath := filepath.Join(kanukaDir, "config.toml")
+	if err := os.WriteFile(configPath, []byte(v0), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config, err := LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("LoadProjectConfig failed: %v", err)
+	}
+	if config.ConfigVersion != CurrentConfigVersion {
+		t.Errorf("Expected version %d, got %d", CurrentConfigVersion, config.ConfigVersion)
+	}
+	if device, ok := config.Devices["user-uuid-1"]; !ok || device.Email != "alice@example.com" {
+		t.Errorf("Expected a default device for alice, got %v", config.Devices)
+	}
+
+	// Loading doesn't rewrite the file; saving stamps the current version.
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if string(data) != v0 {
+		t.Errorf("Expected loading not to rewrite the config, got:\n%s", data)
+	}
+
+	if err := SaveProjectConfig(config); err != nil {
+		t.Fatalf("SaveProjectConfig failed: %v", err)
+	}
+	data, err = os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if !strings.Contains(string(data), "config_version = 1") || !strings.Contains(string(data), "[devices.user-uuid-1]") {
+		t.Errorf("Expected the saved config to be versioned and have devices, got:\n%s", data)
+	}
+}
+
+func TestLoadProjectConfigRejectsNewerVersion(t *testing.T) {
+	tempDir := t.TempDir()
+	oldProjectPath := ProjectKanukaSettings.ProjectPath
+	ProjectKanukaSettings.ProjectPath = tempDir
+	defer func() {
+		ProjectKanukaSettings.ProjectPath = oldProjectPath
+	}()
+
+	kanukaDir := filepath.Join(tempDir, ".kanuka")
+	if err := os.MkdirAll(kanukaDir, 0700); err != nil {
+		t.Fatalf("Failed to create .kanuka directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(kanukaDir, "config.toml"), []byte("config_version = 99\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if _, err := LoadProjectConfig(); !errors.Is(err, kerrors.ErrUnsupportedConfigVersion) {
+		t.Fatalf("Expected ErrUnsupportedConfigVersion, got %v", err)
+	}
+}
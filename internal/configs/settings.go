@@ -13,6 +13,10 @@ type UserSettings struct {
 	UserKeysPath    string
 	UserConfigsPath string
 	Username        string
+
+	// StrictKeyPermissions rejects private keys that are readable by their
+	// group or others, and is set by --strict-perms.
+	StrictKeyPermissions bool
 }
 
 type ProjectSettings struct {
@@ -26,6 +30,12 @@ type ProjectSettings struct {
 var (
 	UserKanukaSettings    *UserSettings
 	ProjectKanukaSettings *ProjectSettings
+
+	// userHomeDir and the default user directories are resolved at startup
+	// for MigrateLegacyUserConfig, which runs later as an explicit step.
+	userHomeDir            string
+	defaultUserConfigsPath string
+	defaultUserKeysPath    string
 )
 
 func init() {
@@ -39,10 +49,13 @@ func init() {
 		log.Fatalf("error getting config directory: %s", err)
 	}
 
-	dataDir := os.Getenv("XDG_DATA_HOME")
-
-	if dataDir == "" {
-		dataDir = filepath.Join(homeDir, ".local", "share")
+	configsPath, keysPath := resolveUserPaths(homeDir, configDir)
+	userHomeDir = homeDir
+	defaultUserConfigsPath = configsPath
+	defaultUserKeysPath = keysPath
+	configsPath, keysPath, err = userDirsFromEnv(configsPath, keysPath)
+	if err != nil {
+		log.Fatalf("error resolving user directories: %s", err)
 	}
 
 	username, err := utils.GetUsername()
@@ -52,8 +65,8 @@ func init() {
 
 	// This is independent of what repo you are in, so it is ok to init here
 	UserKanukaSettings = &UserSettings{
-		UserKeysPath:    filepath.Join(dataDir, "kanuka", "keys"),
-		UserConfigsPath: filepath.Join(configDir, "kanuka"),
+		UserKeysPath:    keysPath,
+		UserConfigsPath: configsPath,
 		Username:        username,
 	}
 	ProjectKanukaSettings = &ProjectSettings{
@@ -0,0 +1,73 @@
+package configs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Environment variables that override where the user config and private keys
+// live, for tests and for keeping several identities on one machine. They
+// are read once at startup; --config-dir and --keys-dir take precedence.
+const (
+	// ConfigDirEnvVar is the directory holding the user's config.toml.
+	ConfigDirEnvVar = "KANUKA_CONFIG_DIR"
+
+	// KeysDirEnvVar is the directory holding the user's private keys, one
+	// subdirectory per project.
+	KeysDirEnvVar = "KANUKA_KEYS_DIR"
+)
+
+// SetUserConfigsDir makes dir the user config directory in place of the one
+// resolved at startup. A relative dir is taken from the working directory.
+func SetUserConfigsDir(dir string) error {
+	absDir, err := absUserDir(dir)
+	if err != nil {
+		return err
+	}
+	UserKanukaSettings.UserConfigsPath = absDir
+	GlobalUserConfig = nil
+	return nil
+}
+
+// SetUserKeysDir makes dir the user key directory in place of the one
+// resolved at startup. A relative dir is taken from the working directory.
+func SetUserKeysDir(dir string) error {
+	absDir, err := absUserDir(dir)
+	if err != nil {
+		return err
+	}
+	UserKanukaSettings.UserKeysPath = absDir
+	return nil
+}
+
+// userDirsFromEnv returns configsPath and keysPath with any overrides from
+// KANUKA_CONFIG_DIR and KANUKA_KEYS_DIR applied.
+func userDirsFromEnv(configsPath, keysPath string) (string, string, error) {
+	if dir := os.Getenv(ConfigDirEnvVar); dir != "" {
+		absDir, err := absUserDir(dir)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid %s: %w", ConfigDirEnvVar, err)
+		}
+		configsPath = absDir
+	}
+	if dir := os.Getenv(KeysDirEnvVar); dir != "" {
+		absDir, err := absUserDir(dir)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid %s: %w", KeysDirEnvVar, err)
+		}
+		keysPath = absDir
+	}
+	return configsPath, keysPath, nil
+}
+
+func absUserDir(dir string) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("directory must not be empty")
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("resolving %s: %w", dir, err)
+	}
+	return absDir, nil
+}
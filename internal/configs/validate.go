@@ -0,0 +1,214 @@
+package configs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/utils"
+)
+
+// ValidationIssueKind identifies the kind of problem a ValidationIssue reports.
+type ValidationIssueKind string
+
+const (
+	// IssueMissingProjectUUID means the project config has no project UUID.
+	IssueMissingProjectUUID ValidationIssueKind = "missing_project_uuid"
+
+	// IssueDeviceWithoutUser means a device UUID has no entry in [users].
+	IssueDeviceWithoutUser ValidationIssueKind = "device_without_user"
+
+	// IssueUserWithoutDevice means a user UUID has no entry in [devices].
+	IssueUserWithoutDevice ValidationIssueKind = "user_without_device"
+
+	// IssueEmailMismatch means a UUID has different emails in [users] and [devices].
+	IssueEmailMismatch ValidationIssueKind = "email_mismatch"
+
+	// IssueOrphanPublicKey means a public key file belongs to no known UUID.
+	IssueOrphanPublicKey ValidationIssueKind = "orphan_public_key"
+
+	// IssueOrphanSecretKey means an encrypted key file belongs to no known UUID.
+	IssueOrphanSecretKey ValidationIssueKind = "orphan_secret_key"
+
+	// IssueInvalidUserEmail means the user config's email is malformed.
+	IssueInvalidUserEmail ValidationIssueKind = "invalid_user_email"
+)
+
+// ValidationIssue describes one problem found in the project or user config.
+type ValidationIssue struct {
+	// Kind identifies the problem.
+	Kind ValidationIssueKind `json:"kind"`
+
+	// Subject is the UUID, file path, or value the problem is about.
+	Subject string `json:"subject"`
+
+	// Message is a human-readable description of the problem.
+	Message string `json:"message"`
+}
+
+// ValidationReport is the result of checking the project and user configs.
+type ValidationReport struct {
+	// Users is the number of entries in the project's [users] section.
+	Users int `json:"users"`
+
+	// Devices is the number of entries in the project's [devices] section.
+	Devices int `json:"devices"`
+
+	// PublicKeys is the number of public key files checked.
+	PublicKeys int `json:"public_keys"`
+
+	// SecretKeys is the number of encrypted key files checked.
+	SecretKeys int `json:"secret_keys"`
+
+	// Issues lists every problem found, ordered by kind and then subject.
+	Issues []ValidationIssue `json:"issues"`
+}
+
+// OK reports whether no issues were found.
+func (r *ValidationReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// Validate loads the project and user configs and checks that they are
+// consistent with each other and with the key files in .kanuka.
+//
+// Every device UUID must have a user entry and vice versa, with the same
+// email, and every file in .kanuka/public_keys and .kanuka/secrets must belong
+// to a known UUID. Problems are returned in the report rather than as errors.
+//
+// Note: Caller should ensure InitProjectSettings is called before calling this function.
+//
+// Returns ErrProjectNotInitialized if there is no project.
+// Returns ErrInvalidProjectConfig if the project config can't be parsed.
+// Returns ErrUnsupportedConfigVersion if it was written by a newer version.
+func Validate() (*ValidationReport, error) {
+	if ProjectKanukaSettings.ProjectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+
+	// The config is checked as written, before migration, which would give
+	// users without a device a default one and hide the problem.
+	projectConfig, err := readProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", kerrors.ErrInvalidProjectConfig, err)
+	}
+	if err := checkConfigVersion(projectConfig); err != nil {
+		return nil, err
+	}
+	userConfig, err := LoadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ValidationReport{
+		Users:   len(projectConfig.Users),
+		Devices: len(projectConfig.Devices),
+	}
+	addIssue := func(kind ValidationIssueKind, subject, format string, args ...any) {
+		report.Issues = append(report.Issues, ValidationIssue{
+			Kind:    kind,
+			Subject: subject,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if projectConfig.Project.UUID == "" {
+		addIssue(IssueMissingProjectUUID, "project.project_uuid", "project config has no project UUID")
+	}
+
+	for uuid, device := range projectConfig.Devices {
+		email, ok := projectConfig.Users[uuid]
+		switch {
+		case !ok:
+			addIssue(IssueDeviceWithoutUser, uuid, "device %q (%s) has no entry in [users]", device.Name, device.Email)
+		case email != device.Email:
+			addIssue(IssueEmailMismatch, uuid, "[users] has %s but [devices] has %s", email, device.Email)
+		}
+	}
+	for uuid, email := range projectConfig.Users {
+		if _, ok := projectConfig.Devices[uuid]; !ok {
+			addIssue(IssueUserWithoutDevice, uuid, "user %s has no entry in [devices]", email)
+		}
+	}
+
+	publicKeys, err := PublicKeyFileUUIDs()
+	if err != nil {
+		return nil, fmt.Errorf("reading public keys: %w", err)
+	}
+	report.PublicKeys = len(publicKeys)
+	for name, uuid := range publicKeys {
+		if !projectConfig.HasUUID(uuid) {
+			addIssue(IssueOrphanPublicKey, filepath.Join(".kanuka", "public_keys", name), "public key belongs to no user or device in the config")
+		}
+	}
+
+	secretKeys, err := SecretKeyFileUUIDs()
+	if err != nil {
+		return nil, fmt.Errorf("reading encrypted keys: %w", err)
+	}
+	report.SecretKeys = len(secretKeys)
+	for name, uuid := range secretKeys {
+		if !projectConfig.HasUUID(uuid) {
+			addIssue(IssueOrphanSecretKey, filepath.Join(".kanuka", "secrets", name), "encrypted key belongs to no user or device in the config")
+		}
+	}
+
+	if userConfig.User.Email != "" && !utils.IsValidEmail(userConfig.User.Email) {
+		addIssue(IssueInvalidUserEmail, userConfig.User.Email, "user config email is not a valid email address")
+	}
+
+	sort.Slice(report.Issues, func(i, j int) bool {
+		a, b := report.Issues[i], report.Issues[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Subject < b.Subject
+	})
+
+	return report, nil
+}
+
+// PublicKeyFileUUIDs maps the names of the public key files in the project's
+// .kanuka/public_keys directory to the UUID each is named after.
+func PublicKeyFileUUIDs() (map[string]string, error) {
+	return keyFileUUIDs(ProjectKanukaSettings.ProjectPublicKeyPath, ".pub", ".gpg")
+}
+
+// SecretKeyFileUUIDs maps the names of the encrypted key files in the
+// project's .kanuka/secrets directory to the UUID each is named after.
+func SecretKeyFileUUIDs() (map[string]string, error) {
+	return keyFileUUIDs(ProjectKanukaSettings.ProjectSecretsPath, ".kanuka")
+}
+
+// keyFileUUIDs maps the names of files in dir with one of the given
+// extensions to the UUID they are named after. A missing dir has no files.
+func keyFileUUIDs(dir string, extensions ...string) (map[string]string, error) {
+	files := make(map[string]string)
+	if dir == "" {
+		return files, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return files, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		for _, ext := range extensions {
+			if strings.HasSuffix(entry.Name(), ext) {
+				files[entry.Name()] = strings.TrimSuffix(entry.Name(), ext)
+				break
+			}
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,146 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+)
+
+// setupValidateProject writes config as the project config in a temporary
+// project, points the settings at it, and returns the project dir.
+func setupValidateProject(t *testing.T, config *ProjectConfig) string {
+	t.Helper()
+	projectDir := t.TempDir()
+	publicKeysDir := filepath.Join(projectDir, ".kanuka", "public_keys")
+	secretsDir := filepath.Join(projectDir, ".kanuka", "secrets")
+	for _, dir := range []string{publicKeysDir, secretsDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+
+	oldProjectSettings := ProjectKanukaSettings
+	oldUserConfigsPath := UserKanukaSettings.UserConfigsPath
+	ProjectKanukaSettings = &ProjectSettings{
+		ProjectPath:          projectDir,
+		ProjectPublicKeyPath: publicKeysDir,
+		ProjectSecretsPath:   secretsDir,
+	}
+	UserKanukaSettings.UserConfigsPath = t.TempDir()
+	t.Cleanup(func() {
+		ProjectKanukaSettings = oldProjectSettings
+		UserKanukaSettings.UserConfigsPath = oldUserConfigsPath
+	})
+
+	if err := SaveProjectConfig(config); err != nil {
+		t.Fatalf("SaveProjectConfig failed: %v", err)
+	}
+	return projectDir
+}
+
+func writeKeyFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("key"), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestValidate_ConsistentConfig(t *testing.T) {
+	config := newMergeTestConfig(map[string]string{"u1": "alice@example.com", "u2": "bob@example.com"})
+	projectDir := setupValidateProject(t, config)
+	for _, uuid := range []string{"u1", "u2"} {
+		writeKeyFile(t, filepath.Join(projectDir, ".kanuka", "public_keys", uuid+".pub"))
+		writeKeyFile(t, filepath.Join(projectDir, ".kanuka", "secrets", uuid+".kanuka"))
+	}
+
+	report, err := Validate()
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("Expected no issues, got %v", report.Issues)
+	}
+	if report.Users != 2 || report.Devices != 2 || report.PublicKeys != 2 || report.SecretKeys != 2 {
+		t.Errorf("Unexpected counts: %+v", report)
+	}
+}
+
+func TestValidate_ReportsIssues(t *testing.T) {
+	config := newMergeTestConfig(map[string]string{"u1": "alice@example.com", "u2": "bob@example.com"})
+	delete(config.Users, "u1")
+	config.Users["u2"] = "robert@example.com"
+	config.Users["u3"] = "carol@example.com"
+	projectDir := setupValidateProject(t, config)
+	writeKeyFile(t, filepath.Join(projectDir, ".kanuka", "public_keys", "gone.pub"))
+	writeKeyFile(t, filepath.Join(projectDir, ".kanuka", "secrets", "gone.kanuka"))
+
+	report, err := Validate()
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	expected := []struct {
+		kind    ValidationIssueKind
+		subject string
+	}{
+		{IssueDeviceWithoutUser, "u1"},
+		{IssueEmailMismatch, "u2"},
+		{IssueOrphanPublicKey, filepath.Join(".kanuka", "public_keys", "gone.pub")},
+		{IssueOrphanSecretKey, filepath.Join(".kanuka", "secrets", "gone.kanuka")},
+		{IssueUserWithoutDevice, "u3"},
+	}
+	if len(report.Issues) != len(expected) {
+		t.Fatalf("Expected %d issues, got %v", len(expected), report.Issues)
+	}
+	for i, want := range expected {
+		got := report.Issues[i]
+		if got.Kind != want.kind || got.Subject != want.subject {
+			t.Errorf("Issue %d: expected %s %s, got %s %s", i, want.kind, want.subject, got.Kind, got.Subject)
+		}
+	}
+}
+
+func TestValidate_InvalidProjectConfig(t *testing.T) {
+	projectDir := setupValidateProject(t, newMergeTestConfig(nil))
+	if err := os.WriteFile(filepath.Join(projectDir, ".kanuka", "config.toml"), []byte("[project\n"), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := Validate()
+	if !errors.Is(err, kerrors.ErrInvalidProjectConfig) {
+		t.Errorf("Expected ErrInvalidProjectConfig, got %v", err)
+	}
+}
+
+func TestValidate_ReportsUserWithoutDeviceInV0Config(t *testing.T) {
+	projectDir := setupValidateProject(t, newMergeTestConfig(nil))
+	v0Config := "[project]\nproject_uuid = \"p1\"\n\n[users]\nu1 = \"alice@example.com\"\n"
+	if err := os.WriteFile(filepath.Join(projectDir, ".kanuka", "config.toml"), []byte(v0Config), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	report, err := Validate()
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if len(report.Issues) != 1 || report.Issues[0].Kind != IssueUserWithoutDevice || report.Issues[0].Subject != "u1" {
+		t.Errorf("Expected u1 to be reported as having no device, got %v", report.Issues)
+	}
+}
+
+func TestValidate_NewerConfigVersion(t *testing.T) {
+	projectDir := setupValidateProject(t, newMergeTestConfig(nil))
+	newer := fmt.Sprintf("config_version = %d\n\n[project]\nproject_uuid = \"p1\"\n", CurrentConfigVersion+1)
+	if err := os.WriteFile(filepath.Join(projectDir, ".kanuka", "config.toml"), []byte(newer), 0600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	_, err := Validate()
+	if !errors.Is(err, kerrors.ErrUnsupportedConfigVersion) {
+		t.Errorf("Expected ErrUnsupportedConfigVersion, got %v", err)
+	}
+}
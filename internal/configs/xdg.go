@@ -0,0 +1,167 @@
+package configs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// legacyUserDir is where older releases kept the user config and keys,
+// before Kānuka followed the XDG base directory spec.
+func legacyUserDir(homeDir string) string {
+	return filepath.Join(homeDir, ".kanuka")
+}
+
+// xdgDirsSet reports whether either XDG_CONFIG_HOME or XDG_DATA_HOME is set.
+func xdgDirsSet() bool {
+	return os.Getenv("XDG_CONFIG_HOME") != "" || os.Getenv("XDG_DATA_HOME") != ""
+}
+
+// resolveUserPaths returns the directories for the user config and private
+// keys.
+//
+// The config lives in $XDG_CONFIG_HOME/kanuka and the keys in
+// $XDG_DATA_HOME/kanuka/keys. An unset XDG_CONFIG_HOME falls back to the
+// platform's config directory, and an unset XDG_DATA_HOME to
+// ~/.local/share. When neither variable is set and only ~/.kanuka has a
+// config.toml, ~/.kanuka is used for both so older setups keep working.
+func resolveUserPaths(homeDir, platformConfigDir string) (configsPath, keysPath string) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = platformConfigDir
+	}
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(homeDir, ".local", "share")
+	}
+	configsPath = filepath.Join(configHome, "kanuka")
+	keysPath = filepath.Join(dataHome, "kanuka", "keys")
+
+	if !xdgDirsSet() {
+		legacy := legacyUserDir(homeDir)
+		if fileExists(filepath.Join(legacy, "config.toml")) && !fileExists(filepath.Join(configsPath, "config.toml")) {
+			return legacy, filepath.Join(legacy, "keys")
+		}
+	}
+	return configsPath, keysPath
+}
+
+// migrateLegacyUserConfig moves ~/.kanuka/config.toml into configsPath when
+// an XDG variable is set. It does nothing if there is no legacy config or
+// configsPath already has one, and reports whether the file was moved.
+func migrateLegacyUserConfig(homeDir, configsPath string) (bool, error) {
+	if !xdgDirsSet() {
+		return false, nil
+	}
+
+	legacyPath := filepath.Join(legacyUserDir(homeDir), "config.toml")
+	targetPath := filepath.Join(configsPath, "config.toml")
+	if !fileExists(legacyPath) || fileExists(targetPath) {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(legacyPath)
+	if err != nil {
+		return false, fmt.Errorf("reading legacy user config: %w", err)
+	}
+	if err := os.MkdirAll(configsPath, 0700); err != nil {
+		return false, fmt.Errorf("creating user config directory: %w", err)
+	}
+	// Copy then remove rather than rename, since the XDG directory may be on
+	// another filesystem.
+	if err := os.WriteFile(targetPath, data, 0600); err != nil {
+		return false, fmt.Errorf("writing user config: %w", err)
+	}
+	if err := os.Remove(legacyPath); err != nil {
+		return false, fmt.Errorf("removing legacy user config: %w", err)
+	}
+	return true, nil
+}
+
+// migrateLegacyUserKeys moves the contents of ~/.kanuka/keys into keysPath
+// when an XDG variable is set, and reports whether anything was moved.
+// Entries keysPath already has are left where they are. Everything is copied
+// before anything is removed, so if the move reports false with an error the
+// legacy directory is still complete.
+func migrateLegacyUserKeys(homeDir, keysPath string) (bool, error) {
+	if !xdgDirsSet() {
+		return false, nil
+	}
+
+	legacyKeysPath := filepath.Join(legacyUserDir(homeDir), "keys")
+	entries, err := os.ReadDir(legacyKeysPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reading legacy keys directory: %w", err)
+	}
+	if err := os.MkdirAll(keysPath, 0700); err != nil {
+		return false, fmt.Errorf("creating keys directory: %w", err)
+	}
+
+	var copied []string
+	for _, entry := range entries {
+		src := filepath.Join(legacyKeysPath, entry.Name())
+		dst := filepath.Join(keysPath, entry.Name())
+		if _, err := os.Lstat(dst); err == nil {
+			continue
+		}
+
+		copyEntry := copyFile
+		if entry.IsDir() {
+			copyEntry = copyDir
+		}
+		if err := copyEntry(src, dst); err != nil {
+			_ = os.RemoveAll(dst)
+			return false, fmt.Errorf("copying %s: %w", src, err)
+		}
+		copied = append(copied, src)
+	}
+
+	for _, src := range copied {
+		if err := os.RemoveAll(src); err != nil {
+			return true, fmt.Errorf("removing legacy key %s: %w", src, err)
+		}
+	}
+	// Only succeeds once nothing is left behind.
+	_ = os.Remove(legacyKeysPath)
+	return len(copied) > 0, nil
+}
+
+// MigrateLegacyUserConfig moves ~/.kanuka/config.toml and ~/.kanuka/keys
+// into the XDG directories, as migrateLegacyUserConfig and
+// migrateLegacyUserKeys do. Commands call it before doing any work rather
+// than it running at startup. Each directory is skipped when it was set by
+// KANUKA_CONFIG_DIR, KANUKA_KEYS_DIR or their flags. If a move fails, the
+// legacy directory keeps being used rather than losing the user's identity or
+// keys, and the error is returned. Failing to clean up the legacy keys once
+// they have all been copied doesn't stop the new directory being used.
+func MigrateLegacyUserConfig() error {
+	if userHomeDir == "" {
+		return nil
+	}
+	if UserKanukaSettings.UserConfigsPath == defaultUserConfigsPath {
+		if _, err := migrateLegacyUserConfig(userHomeDir, defaultUserConfigsPath); err != nil {
+			UserKanukaSettings.UserConfigsPath = legacyUserDir(userHomeDir)
+			GlobalUserConfig = nil
+			return err
+		}
+	}
+	if UserKanukaSettings.UserKeysPath == defaultUserKeysPath {
+		moved, err := migrateLegacyUserKeys(userHomeDir, defaultUserKeysPath)
+		if err != nil && !moved {
+			UserKanukaSettings.UserKeysPath = filepath.Join(legacyUserDir(userHomeDir), "keys")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileExists reports whether path exists and is a regular file.
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
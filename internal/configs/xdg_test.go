@@ -0,0 +1,398 @@
+package configs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatalf("Failed to create %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestResolveUserPaths(t *testing.T) {
+	homeDir := t.TempDir()
+	platformConfigDir := filepath.Join(homeDir, "platform-config")
+	xdgConfig := filepath.Join(homeDir, "xdg-config")
+	xdgData := filepath.Join(homeDir, "xdg-data")
+	legacyConfig := filepath.Join(homeDir, ".kanuka", "config.toml")
+
+	tests := []struct {
+		name        string
+		configHome  string
+		dataHome    string
+		legacy      bool
+		platform    bool
+		wantConfigs string
+		wantKeys    string
+	}{
+		{
+			name:        "defaults without XDG variables",
+			wantConfigs: filepath.Join(platformConfigDir, "kanuka"),
+			wantKeys:    filepath.Join(homeDir, ".local", "share", "kanuka", "keys"),
+		},
+		{
+			name:        "both XDG variables set",
+			configHome:  xdgConfig,
+			dataHome:    xdgData,
+			legacy:      true,
+			wantConfigs: filepath.Join(xdgConfig, "kanuka"),
+			wantKeys:    filepath.Join(xdgData, "kanuka", "keys"),
+		},
+		{
+			name:        "only XDG_CONFIG_HOME set",
+			configHome:  xdgConfig,
+			legacy:      true,
+			wantConfigs: filepath.Join(xdgConfig, "kanuka"),
+			wantKeys:    filepath.Join(homeDir, ".local", "share", "kanuka", "keys"),
+		},
+		{
+			name:        "only XDG_DATA_HOME set",
+			dataHome:    xdgData,
+			legacy:      true,
+			wantConfigs: filepath.Join(platformConfigDir, "kanuka"),
+			wantKeys:    filepath.Join(xdgData, "kanuka", "keys"),
+		},
+		{
+			name:        "legacy directory without XDG variables",
+			legacy:      true,
+			wantConfigs: filepath.Join(homeDir, ".kanuka"),
+			wantKeys:    filepath.Join(homeDir, ".kanuka", "keys"),
+		},
+		{
+			name:        "platform config wins over legacy directory",
+			legacy:      true,
+			platform:    true,
+			wantConfigs: filepath.Join(platformConfigDir, "kanuka"),
+			wantKeys:    filepath.Join(homeDir, ".local", "share", "kanuka", "keys"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("XDG_CONFIG_HOME", tt.configHome)
+			t.Setenv("XDG_DATA_HOME", tt.dataHome)
+			os.RemoveAll(filepath.Dir(legacyConfig))
+			os.RemoveAll(platformConfigDir)
+			if tt.legacy {
+				writeTestFile(t, legacyConfig, "[user]\n")
+			}
+			if tt.platform {
+				writeTestFile(t, filepath.Join(platformConfigDir, "kanuka", "config.toml"), "[user]\n")
+			}
+
+			configsPath, keysPath := resolveUserPaths(homeDir, platformConfigDir)
+			if configsPath != tt.wantConfigs {
+				t.Errorf("configs path = %s, want %s", configsPath, tt.wantConfigs)
+			}
+			if keysPath != tt.wantKeys {
+				t.Errorf("keys path = %s, want %s", keysPath, tt.wantKeys)
+			}
+		})
+	}
+}
+
+func TestMigrateLegacyUserConfig(t *testing.T) {
+	const content = "[user]\nemail = \"alice@example.com\"\n"
+
+	t.Run("moves config when XDG is set", func(t *testing.T) {
+		homeDir := t.TempDir()
+		configsPath := filepath.Join(homeDir, "xdg-config", "kanuka")
+		t.Setenv("XDG_CONFIG_HOME", filepath.Dir(configsPath))
+		t.Setenv("XDG_DATA_HOME", "")
+		legacyConfig := filepath.Join(homeDir, ".kanuka", "config.toml")
+		writeTestFile(t, legacyConfig, content)
+
+		moved, err := migrateLegacyUserConfig(homeDir, configsPath)
+		if err != nil {
+			t.Fatalf("migrateLegacyUserConfig failed: %v", err)
+		}
+		if !moved {
+			t.Fatal("Expected the legacy config to be moved")
+		}
+
+		data, err := os.ReadFile(filepath.Join(configsPath, "config.toml"))
+		if err != nil {
+			t.Fatalf("Expected config at the XDG location: %v", err)
+		}
+		if string(data) != content {
+			t.Errorf("Migrated config = %q, want %q", data, content)
+		}
+		if _, err := os.Stat(legacyConfig); !os.IsNotExist(err) {
+			t.Error("Expected the legacy config to be removed")
+		}
+
+		moved, err = migrateLegacyUserConfig(homeDir, configsPath)
+		if err != nil || moved {
+			t.Errorf("Expected a second migration to do nothing, got moved=%v err=%v", moved, err)
+		}
+	})
+
+	t.Run("leaves config without XDG variables", func(t *testing.T) {
+		homeDir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", "")
+		t.Setenv("XDG_DATA_HOME", "")
+		legacyConfig := filepath.Join(homeDir, ".kanuka", "config.toml")
+		writeTestFile(t, legacyConfig, content)
+
+		moved, err := migrateLegacyUserConfig(homeDir, filepath.Join(homeDir, "config", "kanuka"))
+		if err != nil || moved {
+			t.Errorf("Expected no migration, got moved=%v err=%v", moved, err)
+		}
+		if _, err := os.Stat(legacyConfig); err != nil {
+			t.Errorf("Expected the legacy config to stay: %v", err)
+		}
+	})
+
+	t.Run("does not overwrite an existing XDG config", func(t *testing.T) {
+		homeDir := t.TempDir()
+		configsPath := filepath.Join(homeDir, "xdg-config", "kanuka")
+		t.Setenv("XDG_CONFIG_HOME", filepath.Dir(configsPath))
+		t.Setenv("XDG_DATA_HOME", "")
+		writeTestFile(t, filepath.Join(homeDir, ".kanuka", "config.toml"), content)
+		writeTestFile(t, filepath.Join(configsPath, "config.toml"), "[user]\n")
+
+		moved, err := migrateLegacyUserConfig(homeDir, configsPath)
+		if err != nil || moved {
+			t.Errorf("Expected no migration, got moved=%v err=%v", moved, err)
+		}
+		data, err := os.ReadFile(filepath.Join(configsPath, "config.toml"))
+		if err != nil || string(data) != "[user]\n" {
+			t.Errorf("Expected the existing XDG config to be kept, got %q (%v)", data, err)
+		}
+	})
+}
+
+func TestMigrateLegacyUserKeys(t *testing.T) {
+	// setup points XDG_DATA_HOME at a temporary home with a legacy key
+	// directory for one project and a pending key.
+	setup := func(t *testing.T) (string, string) {
+		homeDir := t.TempDir()
+		keysPath := filepath.Join(homeDir, "xdg-data", "kanuka", "keys")
+		t.Setenv("XDG_CONFIG_HOME", "")
+		t.Setenv("XDG_DATA_HOME", filepath.Join(homeDir, "xdg-data"))
+		legacyKeys := filepath.Join(homeDir, ".kanuka", "keys")
+		writeTestFile(t, filepath.Join(legacyKeys, "project-uuid", "privkey"), "private")
+		writeTestFile(t, filepath.Join(legacyKeys, "project-uuid", "pubkey.pub"), "public")
+		writeTestFile(t, filepath.Join(legacyKeys, PendingKeyDirName, "privkey"), "pending")
+		return homeDir, keysPath
+	}
+
+	t.Run("moves keys when XDG_DATA_HOME is set", func(t *testing.T) {
+		homeDir, keysPath := setup(t)
+
+		moved, err := migrateLegacyUserKeys(homeDir, keysPath)
+		if err != nil {
+			t.Fatalf("migrateLegacyUserKeys failed: %v", err)
+		}
+		if !moved {
+			t.Fatal("Expected the legacy keys to be moved")
+		}
+
+		for file, want := range map[string]string{
+			filepath.Join("project-uuid", "privkey"):    "private",
+			filepath.Join("project-uuid", "pubkey.pub"): "public",
+			filepath.Join(PendingKeyDirName, "privkey"): "pending",
+		} {
+			data, err := os.ReadFile(filepath.Join(keysPath, file))
+			if err != nil || string(data) != want {
+				t.Errorf("Expected %s to be moved with %q, got %q (%v)", file, want, data, err)
+			}
+		}
+		info, err := os.Stat(filepath.Join(keysPath, "project-uuid", "privkey"))
+		if err != nil || info.Mode().Perm() != 0600 {
+			t.Errorf("Expected the private key to keep mode 0600, got %v (%v)", info.Mode().Perm(), err)
+		}
+		if _, err := os.Stat(filepath.Join(homeDir, ".kanuka", "keys")); !os.IsNotExist(err) {
+			t.Error("Expected the legacy keys directory to be removed")
+		}
+	})
+
+	t.Run("leaves keys keysPath already has", func(t *testing.T) {
+		homeDir, keysPath := setup(t)
+		writeTestFile(t, filepath.Join(keysPath, "project-uuid", "privkey"), "newer")
+
+		moved, err := migrateLegacyUserKeys(homeDir, keysPath)
+		if err != nil {
+			t.Fatalf("migrateLegacyUserKeys failed: %v", err)
+		}
+		if !moved {
+			t.Error("Expected the pending key to be moved")
+		}
+		data, err := os.ReadFile(filepath.Join(keysPath, "project-uuid", "privkey"))
+		if err != nil || string(data) != "newer" {
+			t.Errorf("Expected the existing key to be kept, got %q (%v)", data, err)
+		}
+		if _, err := os.Stat(filepath.Join(homeDir, ".kanuka", "keys", "project-uuid", "privkey")); err != nil {
+			t.Errorf("Expected the conflicting legacy key to stay: %v", err)
+		}
+	})
+
+	t.Run("leaves keys without XDG variables", func(t *testing.T) {
+		homeDir, keysPath := setup(t)
+		t.Setenv("XDG_DATA_HOME", "")
+
+		moved, err := migrateLegacyUserKeys(homeDir, keysPath)
+		if err != nil || moved {
+			t.Errorf("Expected no migration, got moved=%v err=%v", moved, err)
+		}
+		if _, err := os.Stat(filepath.Join(homeDir, ".kanuka", "keys", "project-uuid", "privkey")); err != nil {
+			t.Errorf("Expected the legacy keys to stay: %v", err)
+		}
+	})
+}
+
+func TestUserDirsFromEnv(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(originalWd); err != nil {
+			t.Fatalf("Failed to restore working directory: %v", err)
+		}
+	})
+
+	t.Run("unset keeps the resolved paths", func(t *testing.T) {
+		t.Setenv(ConfigDirEnvVar, "")
+		t.Setenv(KeysDirEnvVar, "")
+		configsPath, keysPath, err := userDirsFromEnv("/resolved/config", "/resolved/keys")
+		if err != nil {
+			t.Fatalf("userDirsFromEnv failed: %v", err)
+		}
+		if configsPath != "/resolved/config" || keysPath != "/resolved/keys" {
+			t.Errorf("Expected the resolved paths, got %s and %s", configsPath, keysPath)
+		}
+	})
+
+	t.Run("relative overrides resolve from the working directory", func(t *testing.T) {
+		t.Setenv(ConfigDirEnvVar, "profile/config")
+		t.Setenv(KeysDirEnvVar, "profile/keys")
+		configsPath, keysPath, err := userDirsFromEnv("/resolved/config", "/resolved/keys")
+		if err != nil {
+			t.Fatalf("userDirsFromEnv failed: %v", err)
+		}
+		// t.TempDir may sit behind a symlink, so compare against the
+		// working directory as the process sees it.
+		wd, err := os.Getwd()
+		if err != nil {
+			t.Fatalf("Failed to get working directory: %v", err)
+		}
+		if want := filepath.Join(wd, "profile", "config"); configsPath != want {
+			t.Errorf("Expected config path %s, got %s", want, configsPath)
+		}
+		if want := filepath.Join(wd, "profile", "keys"); keysPath != want {
+			t.Errorf("Expected keys path %s, got %s", want, keysPath)
+		}
+	})
+}
+
+func TestMigrateLegacyUserConfigStep(t *testing.T) {
+	const content = "[user]\nemail = \"alice@example.com\"\n"
+
+	// setup points the startup paths at a temporary home with a legacy
+	// config, and restores them when the test ends.
+	setup := func(t *testing.T) (string, string) {
+		homeDir := t.TempDir()
+		configsPath := filepath.Join(homeDir, "xdg-config", "kanuka")
+		t.Setenv("XDG_CONFIG_HOME", filepath.Dir(configsPath))
+		t.Setenv("XDG_DATA_HOME", "")
+		writeTestFile(t, filepath.Join(homeDir, ".kanuka", "config.toml"), content)
+
+		oldHome, oldConfigs, oldKeys, oldSettings := userHomeDir, defaultUserConfigsPath, defaultUserKeysPath, UserKanukaSettings
+		t.Cleanup(func() {
+			userHomeDir, defaultUserConfigsPath, defaultUserKeysPath, UserKanukaSettings = oldHome, oldConfigs, oldKeys, oldSettings
+		})
+		keysPath := filepath.Join(homeDir, ".local", "share", "kanuka", "keys")
+		userHomeDir = homeDir
+		defaultUserConfigsPath = configsPath
+		defaultUserKeysPath = keysPath
+		UserKanukaSettings = &UserSettings{UserConfigsPath: configsPath, UserKeysPath: keysPath}
+		return homeDir, configsPath
+	}
+
+	t.Run("moves config into the default directory", func(t *testing.T) {
+		_, configsPath := setup(t)
+
+		if err := MigrateLegacyUserConfig(); err != nil {
+			t.Fatalf("MigrateLegacyUserConfig failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(configsPath, "config.toml")); err != nil {
+			t.Errorf("Expected config at the XDG location: %v", err)
+		}
+	})
+
+	t.Run("moves keys into the default directory", func(t *testing.T) {
+		homeDir, _ := setup(t)
+		t.Setenv("XDG_DATA_HOME", filepath.Join(homeDir, "xdg-data"))
+		keysPath := filepath.Join(homeDir, "xdg-data", "kanuka", "keys")
+		defaultUserKeysPath = keysPath
+		UserKanukaSettings.UserKeysPath = keysPath
+		writeTestFile(t, filepath.Join(homeDir, ".kanuka", "keys", "project-uuid", "privkey"), "private")
+
+		if err := MigrateLegacyUserConfig(); err != nil {
+			t.Fatalf("MigrateLegacyUserConfig failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(keysPath, "project-uuid", "privkey")); err != nil {
+			t.Errorf("Expected the key at the XDG location: %v", err)
+		}
+		if UserKanukaSettings.UserKeysPath != keysPath {
+			t.Errorf("UserKeysPath = %s, want %s", UserKanukaSettings.UserKeysPath, keysPath)
+		}
+	})
+
+	t.Run("falls back to the legacy keys directory on failure", func(t *testing.T) {
+		homeDir, _ := setup(t)
+		t.Setenv("XDG_DATA_HOME", filepath.Join(homeDir, "xdg-data"))
+		keysPath := filepath.Join(homeDir, "xdg-data", "kanuka", "keys")
+		defaultUserKeysPath = keysPath
+		UserKanukaSettings.UserKeysPath = keysPath
+		writeTestFile(t, filepath.Join(homeDir, ".kanuka", "keys", "project-uuid", "privkey"), "private")
+		// A file where the keys directory should be makes the move fail.
+		writeTestFile(t, keysPath, "")
+
+		if err := MigrateLegacyUserConfig(); err == nil {
+			t.Fatal("Expected an error when the keys directory can't be created")
+		}
+		if want := filepath.Join(homeDir, ".kanuka", "keys"); UserKanukaSettings.UserKeysPath != want {
+			t.Errorf("UserKeysPath = %s, want %s", UserKanukaSettings.UserKeysPath, want)
+		}
+	})
+
+	t.Run("skips an overridden config directory", func(t *testing.T) {
+		homeDir, configsPath := setup(t)
+		UserKanukaSettings.UserConfigsPath = filepath.Join(homeDir, "override")
+
+		if err := MigrateLegacyUserConfig(); err != nil {
+			t.Fatalf("MigrateLegacyUserConfig failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(configsPath, "config.toml")); !os.IsNotExist(err) {
+			t.Error("Expected no config to be written to the XDG location")
+		}
+		if _, err := os.Stat(filepath.Join(homeDir, ".kanuka", "config.toml")); err != nil {
+			t.Errorf("Expected the legacy config to stay: %v", err)
+		}
+	})
+
+	t.Run("falls back to the legacy directory on failure", func(t *testing.T) {
+		homeDir, configsPath := setup(t)
+		// A file where the config directory should be makes the move fail.
+		writeTestFile(t, configsPath, "")
+
+		if err := MigrateLegacyUserConfig(); err == nil {
+			t.Fatal("Expected an error when the config directory can't be created")
+		}
+		if want := legacyUserDir(homeDir); UserKanukaSettings.UserConfigsPath != want {
+			t.Errorf("UserConfigsPath = %s, want %s", UserKanukaSettings.UserConfigsPath, want)
+		}
+	})
+}
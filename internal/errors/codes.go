@@ -0,0 +1,139 @@
+package errors
+
+import "errors"
+
+// CodeUnknown is the code for errors that don't wrap a known sentinel.
+const CodeUnknown = "unknown"
+
+// errorCodes maps each sentinel error to a stable, machine-readable code.
+// Codes are part of the --output json contract; never change an existing one.
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrNoAccess, "no_access"},
+	{ErrKeyNotFound, "key_not_found"},
+	{ErrPrivateKeyNotFound, "private_key_not_found"},
+	{ErrPublicKeyNotFound, "public_key_not_found"},
+	{ErrInsecureKeyPermissions, "insecure_key_permissions"},
+	{ErrNotProjectOwner, "not_project_owner"},
+
+	{ErrProjectNotInitialized, "project_not_initialized"},
+	{ErrProjectAlreadyInitialized, "project_already_initialized"},
+	// Listed before ErrInvalidProjectConfig, which callers often wrap it in.
+	{ErrUnsupportedConfigVersion, "unsupported_config_version"},
+	{ErrInvalidProjectConfig, "invalid_project_config"},
+	{ErrProjectLocked, "project_locked"},
+	{ErrUserNotRegistered, "user_not_registered"},
+	{ErrProjectNotFound, "project_not_found"},
+	{ErrAmbiguousProjectPath, "ambiguous_project_path"},
+	{ErrUnknownConfigKey, "unknown_config_key"},
+	{ErrReadOnlyConfigKey, "read_only_config_key"},
+	{ErrInvalidConfigValue, "invalid_config_value"},
+
+	{ErrKeyDecryptFailed, "key_decrypt_failed"},
+	{ErrEncryptFailed, "encrypt_failed"},
+	{ErrDecryptFailed, "decrypt_failed"},
+	{ErrInvalidKeyLength, "invalid_key_length"},
+	{ErrInvalidPrivateKey, "invalid_private_key"},
+	{ErrIncorrectPassphrase, "incorrect_passphrase"},
+	{ErrRekeyFailed, "rekey_failed"},
+	{ErrArchivePassphraseRequired, "archive_passphrase_required"},
+	{ErrArchiveDecryptFailed, "archive_decrypt_failed"},
+	{ErrGPGNotFound, "gpg_not_found"},
+
+	{ErrNoFilesFound, "no_files_found"},
+	{ErrFileNotFound, "file_not_found"},
+	{ErrInvalidFileType, "invalid_file_type"},
+	{ErrArchiveInvalid, "invalid_archive"},
+	{ErrArchiveMissingConfig, "archive_missing_config"},
+	{ErrArchiveEmptyConfig, "archive_empty_config"},
+	{ErrArchiveConflictingFlags, "archive_conflicting_flags"},
+
+	{ErrInvalidDateFormat, "invalid_date_format"},
+	{ErrInvalidAuditOperation, "invalid_audit_operation"},
+	{ErrInvalidAuditDetail, "invalid_audit_detail"},
+	{ErrInvalidArguments, "invalid_arguments"},
+	{ErrConfirmationRequired, "confirmation_required"},
+
+	{ErrUserNotFound, "user_not_found"},
+	{ErrDeviceNotFound, "device_not_found"},
+	{ErrSelfRevoke, "self_revoke"},
+	{ErrInvalidEmail, "invalid_email"},
+	{ErrDeviceNameTaken, "device_name_taken"},
+	{ErrPublicKeyExists, "public_key_exists"},
+
+	{ErrCIAlreadyConfigured, "ci_already_configured"},
+	{ErrTTYRequired, "tty_required"},
+
+	{ErrUpdateCheckFailed, "update_check_failed"},
+}
+
+// Code returns the machine-readable code for the first sentinel error that
+// err wraps, or CodeUnknown if it wraps none.
+func Code(err error) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return CodeUnknown
+}
+
+// Process exit codes for failed commands, so scripts can branch on the kind
+// of failure. Like the error codes, these are part of the CLI contract.
+const (
+	// ExitGeneral is used for errors that don't map to a more specific code.
+	ExitGeneral = 1
+
+	// ExitNotInitialized means the project has no .kanuka directory.
+	ExitNotInitialized = 2
+
+	// ExitNoAccess means the user has no access to the project's secrets.
+	ExitNoAccess = 3
+
+	// ExitKeyNotFound means a required key could not be found.
+	ExitKeyNotFound = 4
+
+	// ExitCrypto means an encryption or decryption operation failed.
+	ExitCrypto = 5
+)
+
+// exitCodes maps sentinel errors to process exit codes. Errors not listed
+// here exit with ExitGeneral.
+var exitCodes = []struct {
+	err  error
+	code int
+}{
+	{ErrProjectNotInitialized, ExitNotInitialized},
+
+	{ErrNoAccess, ExitNoAccess},
+	{ErrNotProjectOwner, ExitNoAccess},
+
+	{ErrKeyNotFound, ExitKeyNotFound},
+	{ErrPrivateKeyNotFound, ExitKeyNotFound},
+	{ErrPublicKeyNotFound, ExitKeyNotFound},
+
+	{ErrKeyDecryptFailed, ExitCrypto},
+	{ErrEncryptFailed, ExitCrypto},
+	{ErrDecryptFailed, ExitCrypto},
+	{ErrInvalidKeyLength, ExitCrypto},
+	{ErrInvalidPrivateKey, ExitCrypto},
+	{ErrIncorrectPassphrase, ExitCrypto},
+	{ErrRekeyFailed, ExitCrypto},
+	{ErrArchiveDecryptFailed, ExitCrypto},
+}
+
+// ExitCode returns the process exit code for err: 0 if err is nil, the code
+// for the first sentinel error that err wraps, or ExitGeneral otherwise.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	for _, c := range exitCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return ExitGeneral
+}
@@ -0,0 +1,71 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"sentinel", ErrProjectNotInitialized, "project_not_initialized"},
+		{"wrapped", fmt.Errorf("loading key: %w", ErrNoAccess), "no_access"},
+		{"wrapped twice", fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", ErrKeyDecryptFailed)), "key_decrypt_failed"},
+		{"unknown", errors.New("something else"), CodeUnknown},
+		{"nil", nil, CodeUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Code(tt.err); got != tt.want {
+				t.Errorf("Code(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCode_EverySentinelHasUniqueCode(t *testing.T) {
+	seen := make(map[string]error)
+	for _, c := range errorCodes {
+		if c.code == "" || c.code == CodeUnknown {
+			t.Errorf("%v has invalid code %q", c.err, c.code)
+		}
+		if other, ok := seen[c.code]; ok {
+			t.Errorf("Code %q is used by both %v and %v", c.code, other, c.err)
+		}
+		seen[c.code] = c.err
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, 0},
+		{"not initialized", ErrProjectNotInitialized, ExitNotInitialized},
+		{"no access", ErrNoAccess, ExitNoAccess},
+		{"key not found", ErrKeyNotFound, ExitKeyNotFound},
+		{"private key not found", ErrPrivateKeyNotFound, ExitKeyNotFound},
+		{"public key not found", ErrPublicKeyNotFound, ExitKeyNotFound},
+		{"decrypt failed", ErrDecryptFailed, ExitCrypto},
+		{"incorrect passphrase", ErrIncorrectPassphrase, ExitCrypto},
+		{"wrapped", fmt.Errorf("loading key: %w", ErrNoAccess), ExitNoAccess},
+		{"wrapped crypto", fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", ErrKeyDecryptFailed)), ExitCrypto},
+		{"known without exit code", ErrInvalidArguments, ExitGeneral},
+		{"unknown", errors.New("something else"), ExitGeneral},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
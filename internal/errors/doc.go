@@ -11,7 +11,13 @@
 //   - Access errors: User lacks permission or keys (ErrNoAccess, ErrKeyNotFound)
 //   - Project errors: Project state issues (ErrProjectNotInitialized)
 //   - Crypto errors: Encryption/decryption failures (ErrKeyDecryptFailed)
-//   - File errors: File system issues (ErrNoFilesFound, ErrFileNotFound)
+//   - File errors: File system and archive issues (ErrNoFilesFound, ErrFileNotFound, ErrArchiveInvalid)
+//
+// # Codes
+//
+// Code maps an error to a stable string code for --output json, and ExitCode
+// maps it to a process exit code (2 not initialized, 3 no access, 4 key not
+// found, 5 crypto failure, 1 otherwise).
 //
 // # Usage
 //
@@ -15,6 +15,14 @@ var (
 
 	// ErrPublicKeyNotFound indicates a public key could not be located.
 	ErrPublicKeyNotFound = errors.New("public key not found")
+
+	// ErrInsecureKeyPermissions indicates a private key file is readable by
+	// its group or others and strict permissions are enabled.
+	ErrInsecureKeyPermissions = errors.New("private key file permissions are too permissive")
+
+	// ErrNotProjectOwner indicates an owner-only operation was attempted by a
+	// user who isn't one of the project's owners.
+	ErrNotProjectOwner = errors.New("user is not a project owner")
 )
 
 // Project state errors indicate issues with project configuration or initialization.
@@ -28,8 +36,32 @@ var (
 	// ErrInvalidProjectConfig indicates the project configuration is malformed or corrupt.
 	ErrInvalidProjectConfig = errors.New("project configuration is invalid")
 
+	// ErrUnsupportedConfigVersion indicates the project configuration was written
+	// by a newer version of Kanuka than the one reading it.
+	ErrUnsupportedConfigVersion = errors.New("project configuration version is not supported")
+
+	// ErrProjectLocked indicates another Kanuka operation holds the project lock.
+	ErrProjectLocked = errors.New("another Kanuka operation is in progress")
+
 	// ErrUserNotRegistered indicates the user is not registered with this project.
 	ErrUserNotRegistered = errors.New("user is not registered with this project")
+
+	// ErrProjectNotFound indicates no local key metadata records the given
+	// project path.
+	ErrProjectNotFound = errors.New("no project found for path")
+
+	// ErrAmbiguousProjectPath indicates more than one project's key metadata
+	// records the same project path.
+	ErrAmbiguousProjectPath = errors.New("multiple projects found for path")
+
+	// ErrUnknownConfigKey indicates a config key does not exist.
+	ErrUnknownConfigKey = errors.New("unknown config key")
+
+	// ErrReadOnlyConfigKey indicates a config key can be read but not set.
+	ErrReadOnlyConfigKey = errors.New("config key is read-only")
+
+	// ErrInvalidConfigValue indicates a value is not valid for its config key.
+	ErrInvalidConfigValue = errors.New("invalid config value")
 )
 
 // Cryptographic errors indicate failures during encryption or decryption operations.
@@ -48,6 +80,21 @@ var (
 
 	// ErrInvalidPrivateKey indicates the private key is malformed or unsupported.
 	ErrInvalidPrivateKey = errors.New("invalid or unsupported private key format")
+
+	// ErrIncorrectPassphrase indicates the passphrase for a private key was wrong.
+	ErrIncorrectPassphrase = errors.New("incorrect passphrase for private key")
+
+	// ErrRekeyFailed indicates rekey-all failed or could not be verified; all changes were rolled back.
+	ErrRekeyFailed = errors.New("rekey failed and was rolled back")
+
+	// ErrArchivePassphraseRequired indicates an encrypted archive was given without a passphrase.
+	ErrArchivePassphraseRequired = errors.New("archive is encrypted and requires a passphrase")
+
+	// ErrArchiveDecryptFailed indicates an encrypted archive could not be decrypted.
+	ErrArchiveDecryptFailed = errors.New("failed to decrypt archive: wrong passphrase or corrupted archive")
+
+	// ErrGPGNotFound indicates a GPG key is involved but gpg is not installed or not on PATH.
+	ErrGPGNotFound = errors.New("gpg not found on PATH")
 )
 
 // File errors indicate issues with file discovery or access.
@@ -61,14 +108,35 @@ var (
 	// ErrInvalidFileType indicates the file is not of the expected type.
 	ErrInvalidFileType = errors.New("invalid file type")
 
-	// ErrInvalidArchive indicates the archive structure is invalid.
-	ErrInvalidArchive = errors.New("invalid archive structure")
+	// ErrArchiveInvalid indicates the archive structure is invalid.
+	ErrArchiveInvalid = errors.New("invalid archive structure")
+
+	// ErrArchiveMissingConfig indicates an archive has no .kanuka/config.toml.
+	ErrArchiveMissingConfig = errors.New("archive is missing .kanuka/config.toml")
+
+	// ErrArchiveEmptyConfig indicates an archive's .kanuka/config.toml is empty.
+	ErrArchiveEmptyConfig = errors.New("archive has an empty .kanuka/config.toml")
+
+	// ErrArchiveConflictingFlags indicates an import was asked to both merge and replace.
+	ErrArchiveConflictingFlags = errors.New("cannot use both --merge and --replace")
 )
 
 // Input validation errors indicate issues with user-provided values.
 var (
 	// ErrInvalidDateFormat indicates the date format is invalid.
 	ErrInvalidDateFormat = errors.New("invalid date format")
+
+	// ErrInvalidAuditOperation indicates a custom audit operation name is invalid or reserved.
+	ErrInvalidAuditOperation = errors.New("invalid audit operation")
+
+	// ErrInvalidAuditDetail indicates a custom audit detail is not in key=value form.
+	ErrInvalidAuditDetail = errors.New("invalid audit detail")
+
+	// ErrInvalidArguments indicates a command was given a missing or conflicting combination of flags.
+	ErrInvalidArguments = errors.New("invalid arguments")
+
+	// ErrConfirmationRequired indicates a prompt was needed but could not be shown.
+	ErrConfirmationRequired = errors.New("confirmation required")
 )
 
 // User errors indicate issues with user-related operations.
@@ -100,3 +168,9 @@ var (
 	// ErrTTYRequired is returned when a command requires TTY but none is available.
 	ErrTTYRequired = errors.New("this command requires an interactive terminal")
 )
+
+// Update check errors indicate issues checking for newer releases.
+var (
+	// ErrUpdateCheckFailed indicates the latest release could not be determined.
+	ErrUpdateCheckFailed = errors.New("failed to check for updates")
+)
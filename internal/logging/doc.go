@@ -27,9 +27,20 @@
 //
 // Create a logger with the desired verbosity:
 //
-//	log := Logger{Verbose: verbose, Debug: debug}
+//	log := New(verbose, debug)
 //	log.Infof("Processing %d files", count)
+//	defer log.FlushWarnings()
 //
 // Commands typically create a logger in their PersistentPreRun and
 // pass it to internal functions.
+//
+// # Repeated Warnings
+//
+// A logger made with New collects WarnfUser messages instead of printing
+// them, and FlushWarnings prints each distinct message once when the command
+// finishes, noting how often it repeated:
+//
+//	Warning: file.env is readable by others (repeated 3 times)
+//
+// WarnfAlways messages are critical and are always printed immediately.
 package logger
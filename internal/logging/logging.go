@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sync"
 
 	"github.com/PolarWolf314/kanuka/internal/ui"
 )
@@ -11,6 +12,29 @@ import (
 type Logger struct {
 	Verbose bool
 	Debug   bool
+
+	// warnings holds WarnfUser messages until FlushWarnings. It is nil for a
+	// Logger not made by New, which prints each warning immediately.
+	warnings *warningLog
+}
+
+// warningLog collects user warnings in the order they were first emitted,
+// counting exact repeats.
+type warningLog struct {
+	mu     sync.Mutex
+	order  []string
+	counts map[string]int
+}
+
+// New returns a Logger that collects WarnfUser messages so that identical
+// warnings within one command run are shown once. Call FlushWarnings when
+// the command finishes to print them.
+func New(verbose, debug bool) Logger {
+	return Logger{
+		Verbose:  verbose,
+		Debug:    debug,
+		warnings: &warningLog{counts: make(map[string]int)},
+	}
 }
 
 func (l Logger) Infof(msg string, args ...any) {
@@ -38,11 +62,46 @@ func (l Logger) WarnfAlways(msg string, args ...any) {
 }
 
 func (l Logger) WarnfUser(msg string, args ...any) {
+	text := fmt.Sprintf(msg, args...)
+	if l.warnings == nil {
+		l.printUserWarning(text, 1)
+		return
+	}
+
+	l.warnings.mu.Lock()
+	defer l.warnings.mu.Unlock()
+	if l.warnings.counts[text] == 0 {
+		l.warnings.order = append(l.warnings.order, text)
+	}
+	l.warnings.counts[text]++
+}
+
+// FlushWarnings prints the collected WarnfUser messages, each once, with
+// "(repeated N times)" after any that were emitted more than once. Critical
+// WarnfAlways messages are printed as they happen and are never collected.
+func (l Logger) FlushWarnings() {
+	if l.warnings == nil {
+		return
+	}
+
+	l.warnings.mu.Lock()
+	defer l.warnings.mu.Unlock()
+	for _, text := range l.warnings.order {
+		l.printUserWarning(text, l.warnings.counts[text])
+	}
+	l.warnings.order = nil
+	l.warnings.counts = make(map[string]int)
+}
+
+func (l Logger) printUserWarning(text string, count int) {
+	if count > 1 {
+		text += fmt.Sprintf(" (repeated %d times)", count)
+	}
 	// Show user-facing warnings (not just debug info)
 	if !l.Debug { // Don't duplicate with debug logs
-		fmt.Fprintf(os.Stderr, ui.Warning.Sprint("Warning: ")+msg+"\n", args...)
+		fmt.Fprint(os.Stderr, ui.Warning.Sprint("Warning: ")+text+"\n")
 	} else {
-		fmt.Fprintf(os.Stderr, ui.Warning.Sprint("[warn] ")+msg+"\n", args...)
+		fmt.Fprint(os.Stderr, ui.Warning.Sprint("[warn] ")+text+"\n")
 	}
 }
 
@@ -0,0 +1,89 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+// captureStderr returns what fn writes to stderr.
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	original := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = original }()
+
+	fn()
+	w.Close()
+
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Failed to read stderr: %v", err)
+	}
+	return string(out)
+}
+
+func TestWarnfUser_DeduplicatesRepeats(t *testing.T) {
+	l := New(false, false)
+
+	output := captureStderr(t, func() {
+		l.WarnfUser("Never commit %s files", ".env")
+		l.WarnfUser("Permissions on %s are too open", "a.env")
+		l.WarnfUser("Never commit %s files", ".env")
+	})
+	if output != "" {
+		t.Errorf("Expected nothing printed before flushing, got %q", output)
+	}
+
+	output = captureStderr(t, l.FlushWarnings)
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 warnings, got %d: %q", len(lines), output)
+	}
+	if !strings.HasSuffix(lines[0], "Never commit .env files (repeated 2 times)") {
+		t.Errorf("Expected the repeated warning once with a count, got %q", lines[0])
+	}
+	if !strings.HasSuffix(lines[1], "Permissions on a.env are too open") {
+		t.Errorf("Expected the single warning without a count, got %q", lines[1])
+	}
+
+	if output := captureStderr(t, l.FlushWarnings); output != "" {
+		t.Errorf("Expected a second flush to print nothing, got %q", output)
+	}
+}
+
+func TestWarnfUser_PrintsImmediatelyWithoutNew(t *testing.T) {
+	l := Logger{}
+
+	output := captureStderr(t, func() {
+		l.WarnfUser("Never commit .env files")
+		l.WarnfUser("Never commit .env files")
+	})
+
+	if count := strings.Count(output, "Never commit .env files"); count != 2 {
+		t.Errorf("Expected both warnings to be printed immediately, got %q", output)
+	}
+}
+
+func TestWarnfAlways_NeverDeduplicated(t *testing.T) {
+	l := New(false, false)
+
+	output := captureStderr(t, func() {
+		l.WarnfAlways("Private key is world readable")
+		l.WarnfAlways("Private key is world readable")
+		l.FlushWarnings()
+	})
+
+	if count := strings.Count(output, "Private key is world readable"); count != 2 {
+		t.Errorf("Expected both critical warnings to be printed, got %q", output)
+	}
+	if strings.Contains(output, "repeated") {
+		t.Errorf("Expected no repeat count on critical warnings, got %q", output)
+	}
+}
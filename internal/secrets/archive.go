@@ -0,0 +1,108 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+// encryptedArchiveMagic identifies a passphrase-encrypted export archive.
+// It is followed by the scrypt salt, the secretbox nonce, and the sealed archive.
+var encryptedArchiveMagic = []byte("KANUKA-ARCHIVE-V1\n")
+
+const (
+	archiveSaltSize  = 16
+	archiveNonceSize = 24
+
+	// scrypt parameters for deriving the archive key from a passphrase.
+	// N=2^15 costs roughly 100ms and 32MB per derivation.
+	archiveScryptN = 1 << 15
+	archiveScryptR = 8
+	archiveScryptP = 1
+)
+
+// errArchiveDecrypt is returned when an encrypted archive cannot be opened,
+// either because the passphrase is wrong or the archive is corrupted.
+var errArchiveDecrypt = errors.New("wrong passphrase or corrupted archive")
+
+// IsEncryptedArchive reports whether data starts with the encrypted archive header.
+func IsEncryptedArchive(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedArchiveMagic)
+}
+
+// EncryptArchive wraps an archive in a passphrase-derived outer encryption
+// layer. The key is derived with scrypt from the passphrase and a random salt,
+// and the archive is sealed with NaCl secretbox.
+func EncryptArchive(archive, passphrase []byte) ([]byte, error) {
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+
+	header := make([]byte, len(encryptedArchiveMagic)+archiveSaltSize+archiveNonceSize)
+	copy(header, encryptedArchiveMagic)
+	salt := header[len(encryptedArchiveMagic) : len(encryptedArchiveMagic)+archiveSaltSize]
+	nonceBytes := header[len(encryptedArchiveMagic)+archiveSaltSize:]
+
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	key, err := deriveArchiveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	var nonce [archiveNonceSize]byte
+	copy(nonce[:], nonceBytes)
+
+	out := make([]byte, len(header), len(header)+len(archive)+secretbox.Overhead)
+	copy(out, header)
+	return secretbox.Seal(out, archive, &nonce, key), nil
+}
+
+// DecryptArchive removes the outer encryption layer added by EncryptArchive.
+// Returns an error if the passphrase is wrong or the data is corrupted.
+func DecryptArchive(data, passphrase []byte) ([]byte, error) {
+	if !IsEncryptedArchive(data) {
+		return nil, fmt.Errorf("not an encrypted archive")
+	}
+
+	rest := data[len(encryptedArchiveMagic):]
+	if len(rest) < archiveSaltSize+archiveNonceSize+secretbox.Overhead {
+		return nil, errArchiveDecrypt
+	}
+
+	salt := rest[:archiveSaltSize]
+	var nonce [archiveNonceSize]byte
+	copy(nonce[:], rest[archiveSaltSize:archiveSaltSize+archiveNonceSize])
+	sealed := rest[archiveSaltSize+archiveNonceSize:]
+
+	key, err := deriveArchiveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, ok := secretbox.Open(nil, sealed, &nonce, key)
+	if !ok {
+		return nil, errArchiveDecrypt
+	}
+	return archive, nil
+}
+
+// deriveArchiveKey derives a secretbox key from a passphrase and salt.
+func deriveArchiveKey(passphrase, salt []byte) (*[32]byte, error) {
+	derived, err := scrypt.Key(passphrase, salt, archiveScryptN, archiveScryptR, archiveScryptP, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive archive key: %w", err)
+	}
+	var key [32]byte
+	copy(key[:], derived)
+	return &key, nil
+}
@@ -0,0 +1,66 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncryptDecryptArchive_RoundTrip(t *testing.T) {
+	archive := []byte("\x1f\x8bfake gzip archive contents")
+	passphrase := []byte("correct horse battery staple")
+
+	encrypted, err := EncryptArchive(archive, passphrase)
+	if err != nil {
+		t.Fatalf("EncryptArchive failed: %v", err)
+	}
+	if !IsEncryptedArchive(encrypted) {
+		t.Fatal("Expected encrypted archive to be detected")
+	}
+	if bytes.Contains(encrypted, archive) {
+		t.Fatal("Encrypted archive contains the plaintext archive")
+	}
+
+	decrypted, err := DecryptArchive(encrypted, passphrase)
+	if err != nil {
+		t.Fatalf("DecryptArchive failed: %v", err)
+	}
+	if !bytes.Equal(decrypted, archive) {
+		t.Errorf("Round trip mismatch: got %q, want %q", decrypted, archive)
+	}
+}
+
+func TestDecryptArchive_WrongPassphrase(t *testing.T) {
+	encrypted, err := EncryptArchive([]byte("archive"), []byte("right"))
+	if err != nil {
+		t.Fatalf("EncryptArchive failed: %v", err)
+	}
+
+	if _, err := DecryptArchive(encrypted, []byte("wrong")); !errors.Is(err, errArchiveDecrypt) {
+		t.Errorf("Expected errArchiveDecrypt, got %v", err)
+	}
+}
+
+func TestDecryptArchive_Truncated(t *testing.T) {
+	encrypted, err := EncryptArchive([]byte("archive"), []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("EncryptArchive failed: %v", err)
+	}
+
+	truncated := encrypted[:len(encryptedArchiveMagic)+4]
+	if _, err := DecryptArchive(truncated, []byte("passphrase")); !errors.Is(err, errArchiveDecrypt) {
+		t.Errorf("Expected errArchiveDecrypt, got %v", err)
+	}
+}
+
+func TestEncryptArchive_EmptyPassphrase(t *testing.T) {
+	if _, err := EncryptArchive([]byte("archive"), nil); err == nil {
+		t.Error("Expected error for empty passphrase")
+	}
+}
+
+func TestIsEncryptedArchive_PlainGzip(t *testing.T) {
+	if IsEncryptedArchive([]byte{0x1f, 0x8b, 0x08, 0x00}) {
+		t.Error("Plain gzip data should not be detected as encrypted")
+	}
+}
@@ -1,26 +1,55 @@
 package secrets
 
 import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
 	"crypto/rand"
 	"crypto/rsa"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
-
-	"golang.org/x/crypto/nacl/secretbox"
+	"github.com/PolarWolf314/kanuka/internal/utils"
 )
 
-// DecryptWithPrivateKey decrypts data using an RSA private key.
-func DecryptWithPrivateKey(ciphertext []byte, privateKey *rsa.PrivateKey) ([]byte, error) {
-	return rsa.DecryptPKCS1v15(rand.Reader, privateKey, ciphertext)
+// DecryptWithPrivateKey decrypts a wrapped symmetric key using an RSA or Ed25519 private key.
+func DecryptWithPrivateKey(ciphertext []byte, privateKey PrivateKey) ([]byte, error) {
+	switch k := privateKey.(type) {
+	case *rsa.PrivateKey:
+		if isX25519Wrapped(ciphertext) {
+			return nil, errors.New("symmetric key was wrapped for an Ed25519 key, not an RSA key")
+		}
+		if IsGPGWrapped(ciphertext) {
+			return nil, errors.New("symmetric key was wrapped for a GPG key, not an RSA key")
+		}
+		return rsa.DecryptPKCS1v15(rand.Reader, k, ciphertext)
+	case ed25519.PrivateKey:
+		return openWithEd25519(ciphertext, k)
+	default:
+		return nil, fmt.Errorf("%w, got %T", ErrUnsupportedKeyType, privateKey)
+	}
 }
 
-func EncryptWithPublicKey(ciphertext []byte, publicKey *rsa.PublicKey) ([]byte, error) {
-	return rsa.EncryptPKCS1v15(rand.Reader, publicKey, ciphertext)
+// EncryptWithPublicKey wraps a symmetric key for an RSA or Ed25519 public key.
+// RSA keys use PKCS#1 v1.5 with no header, as they always have; Ed25519 keys
+// use a sealed box prefixed with a scheme header byte.
+func EncryptWithPublicKey(plaintext []byte, publicKey crypto.PublicKey) ([]byte, error) {
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		return rsa.EncryptPKCS1v15(rand.Reader, k, plaintext)
+	case ed25519.PublicKey:
+		return sealForEd25519(plaintext, k)
+	default:
+		return nil, fmt.Errorf("%w, got %T", ErrUnsupportedKeyType, publicKey)
+	}
 }
 
 // CreateSymmetricKey generates a new random symmetric key.
@@ -86,7 +115,8 @@ func CreateAndSaveEncryptedSymmetricKey(verbose bool) error {
 	return nil
 }
 
-// EncryptFiles encrypts files using a symmetric key.
+// EncryptFiles encrypts files using a symmetric key. Files are encrypted a
+// chunk at a time, so large files don't need to fit in memory.
 func EncryptFiles(symKey []byte, inputPaths []string, verbose bool) error {
 	if len(symKey) != 32 {
 		return fmt.Errorf("invalid symmetric key length: expected 32 bytes, got %d bytes", len(symKey))
@@ -96,29 +126,107 @@ func EncryptFiles(symKey []byte, inputPaths []string, verbose bool) error {
 	copy(key[:], symKey)
 
 	for _, inputPath := range inputPaths {
-		plaintext, err := os.ReadFile(inputPath)
-		if err != nil {
-			return fmt.Errorf("failed to read .env file at %s: %w", inputPath, err)
+		if err := encryptFile(&key, inputPath, inputPath+".kanuka"); err != nil {
+			return err
 		}
+	}
 
-		var nonce [24]byte
-		if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
-			return fmt.Errorf("failed on ReadFull method: %w", err)
-		}
+	return nil
+}
 
-		ciphertext := secretbox.Seal(nonce[:], plaintext, &nonce, &key)
+// EncryptFilesConcurrently encrypts files like EncryptFiles, but with up to
+// jobs files in flight at once. If jobs is less than 1, runtime.GOMAXPROCS(0)
+// is used.
+//
+// A file that fails doesn't stop the others. The returned slice has one entry
+// per input path, in the same order: nil if the file was encrypted, otherwise
+// its error. The error return is only for an invalid key.
+func EncryptFilesConcurrently(symKey []byte, inputPaths []string, jobs int) ([]error, error) {
+	if len(symKey) != 32 {
+		return nil, fmt.Errorf("invalid symmetric key length: expected 32 bytes, got %d bytes", len(symKey))
+	}
 
-		outputPath := inputPath + ".kanuka"
+	var key [32]byte
+	copy(key[:], symKey)
 
-		if err := os.WriteFile(outputPath, ciphertext, 0600); err != nil {
-			return fmt.Errorf("failed to write to %s: %w", outputPath, err)
-		}
+	if jobs < 1 {
+		jobs = runtime.GOMAXPROCS(0)
+	}
+	jobs = min(jobs, len(inputPaths))
+
+	// Each worker writes only its own indexes, so no locking is needed and
+	// the results come back in input order however the work was scheduled.
+	errs := make([]error, len(inputPaths))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = encryptFile(&key, inputPaths[i], inputPaths[i]+".kanuka")
+			}
+		}()
+	}
+	for i := range inputPaths {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return errs, nil
+}
+
+// EncryptReader encrypts plaintext read from src into outputPath, so
+// plaintext that never touches disk, such as secrets piped on stdin, can be
+// encrypted. There is no source file, so no mode is recorded and the file
+// decrypts with the default permissions.
+func EncryptReader(symKey []byte, src io.Reader, outputPath string) error {
+	if len(symKey) != 32 {
+		return fmt.Errorf("invalid symmetric key length: expected 32 bytes, got %d bytes", len(symKey))
+	}
+
+	var key [32]byte
+	copy(key[:], symKey)
+	return writeEncrypted(&key, src, outputPath, 0)
+}
+
+// encryptFile encrypts inputPath into outputPath, recording inputPath's
+// permissions so decryption can restore them.
+func encryptFile(key *[32]byte, inputPath, outputPath string) error {
+	input, err := os.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read .env file at %s: %w", inputPath, err)
 	}
+	defer input.Close()
 
+	info, err := input.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat .env file at %s: %w", inputPath, err)
+	}
+	return writeEncrypted(key, input, outputPath, info.Mode().Perm())
+}
+
+// writeEncrypted encrypts plaintext read from src into outputPath, recording
+// mode as the source's permissions.
+func writeEncrypted(key *[32]byte, src io.Reader, outputPath string, mode os.FileMode) error {
+	// Write atomically so a crash mid-write can't leave a truncated
+	// .kanuka file that nobody can decrypt.
+	err := utils.WriteFileAtomicFunc(outputPath, 0600, func(w io.Writer) error {
+		return encryptStream(key, w, src, mode)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write to %s: %w", outputPath, err)
+	}
 	return nil
 }
 
-// DecryptFiles decrypts files using a symmetric key.
+// DefaultDecryptedFileMode is the mode of a newly decrypted file whose
+// .kanuka file has no recorded permissions.
+const DefaultDecryptedFileMode os.FileMode = 0600
+
+// DecryptFiles decrypts files using a symmetric key. Files are decrypted a
+// chunk at a time, so large files don't need to fit in memory.
 func DecryptFiles(symKey []byte, inputPaths []string, verbose bool) error {
 	if len(symKey) != 32 {
 		return fmt.Errorf("failed to decrypt files: symmetric key length must be exactly 32 bytes for secretbox")
@@ -126,41 +234,187 @@ func DecryptFiles(symKey []byte, inputPaths []string, verbose bool) error {
 	var key [32]byte
 	copy(key[:], symKey)
 	for _, inputPath := range inputPaths {
-		ciphertext, err := os.ReadFile(inputPath)
-		if err != nil {
-			return fmt.Errorf("failed to read .kanuka file at %s: %w", inputPath, err)
+		if err := decryptFile(&key, inputPath, strings.TrimSuffix(inputPath, ".kanuka")); err != nil {
+			return err
 		}
+	}
 
-		// Extract the nonce from the beginning of the ciphertext
-		var decryptNonce [24]byte
-		copy(decryptNonce[:], ciphertext[:24])
-
-		// Decrypt using the extracted nonce and the rest of the ciphertext
-		plaintext, ok := secretbox.Open(nil, ciphertext[24:], &decryptNonce, &key)
-		if !ok {
-			return fmt.Errorf("failed to decrypt ciphertext with secretbox")
-		}
+	return nil
+}
 
-		outputPath := strings.TrimSuffix(inputPath, ".kanuka")
-		// #nosec G306 -- We want the decrypted .env file to be editable by the user
-		if err := os.WriteFile(outputPath, plaintext, 0644); err != nil {
-			return fmt.Errorf("failed to write to %s: %w", outputPath, err)
+// decryptFile decrypts inputPath into outputPath. The output is only replaced
+// once the whole file has decrypted. It gets the permissions recorded when
+// the file was encrypted; if none were recorded, an existing output keeps its
+// permissions and a new one is created with DefaultDecryptedFileMode.
+func decryptFile(key *[32]byte, inputPath, outputPath string) error {
+	input, err := os.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read .kanuka file at %s: %w", inputPath, err)
+	}
+	defer input.Close()
+
+	// The header's mode is only authenticated once the file opens, but the
+	// output is discarded if it doesn't, so it is safe to create it with.
+	reader := bufio.NewReader(input)
+	header, _ := reader.Peek(streamHeaderSize)
+	perm := recordedMode(header)
+	if perm == 0 {
+		perm = DefaultDecryptedFileMode
+		if info, err := os.Stat(outputPath); err == nil {
+			perm = info.Mode().Perm()
 		}
 	}
 
+	var decryptErr error
+	err = utils.WriteFileAtomicFunc(outputPath, perm, func(w io.Writer) error {
+		_, decryptErr = decryptStream(key, w, reader)
+		return decryptErr
+	})
+	if decryptErr != nil {
+		return decryptErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write to %s: %w", outputPath, err)
+	}
 	return nil
 }
 
+// ReadEncryptedFile decrypts a .kanuka file and returns its plaintext
+// without writing anything to disk.
+func ReadEncryptedFile(symKey []byte, inputPath string) ([]byte, error) {
+	if len(symKey) != 32 {
+		return nil, fmt.Errorf("failed to decrypt files: symmetric key length must be exactly 32 bytes for secretbox")
+	}
+	var key [32]byte
+	copy(key[:], symKey)
+	return readEncryptedFile(&key, inputPath)
+}
+
+// VerifyEncryptedFile checks that a .kanuka file decrypts with symKey. The
+// plaintext is discarded a chunk at a time, so nothing is written to disk and
+// large files are not held in memory.
+func VerifyEncryptedFile(symKey []byte, inputPath string) error {
+	if len(symKey) != 32 {
+		return fmt.Errorf("failed to decrypt files: symmetric key length must be exactly 32 bytes for secretbox")
+	}
+	var key [32]byte
+	copy(key[:], symKey)
+
+	input, err := os.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to read .kanuka file at %s: %w", inputPath, err)
+	}
+	defer input.Close()
+	_, err = decryptStream(&key, io.Discard, input)
+	return err
+}
+
+// readEncryptedFile reads a .kanuka file and opens it with key.
+func readEncryptedFile(key *[32]byte, inputPath string) ([]byte, error) {
+	ciphertext, err := os.ReadFile(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read .kanuka file at %s: %w", inputPath, err)
+	}
+	return openBytes(key, ciphertext)
+}
+
+// ReencryptFile re-encrypts the .kanuka file at path with symKey and fresh
+// nonces, keeping the permissions it records. The whole file is decrypted
+// before anything is written, so a file that doesn't open with symKey is left
+// as it was.
+func ReencryptFile(symKey []byte, path string) error {
+	key, err := symmetricKeyArray(symKey)
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read .kanuka file at %s: %w", path, err)
+	}
+	var plaintext bytes.Buffer
+	mode, err := decryptStream(key, &plaintext, bytes.NewReader(ciphertext))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s: %w", path, err)
+	}
+	return writeEncrypted(key, &plaintext, path, mode)
+}
+
+// EncryptBytes encrypts plaintext with the project's symmetric key, without
+// touching disk. The ciphertext is in the same format as a .kanuka file (see
+// stream.go), with no file permissions recorded, so it can be written out as
+// a .kanuka file or read back with DecryptBytes. Encrypting the same
+// plaintext twice gives different ciphertexts.
+//
+// symKey must be 32 bytes, such as the key returned by LoadSymmetricKey.
+func EncryptBytes(symKey, plaintext []byte) ([]byte, error) {
+	key, err := symmetricKeyArray(symKey)
+	if err != nil {
+		return nil, err
+	}
+	return sealBytes(key, plaintext, 0)
+}
+
+// DecryptBytes decrypts ciphertext made by EncryptBytes, or the contents of
+// a .kanuka file, with the project's symmetric key. Both the current framed
+// format and the older format, a single secretbox with its 24-byte nonce
+// prepended, are accepted. It returns an error if the ciphertext was made
+// with a different key or has been modified.
+func DecryptBytes(symKey, ciphertext []byte) ([]byte, error) {
+	key, err := symmetricKeyArray(symKey)
+	if err != nil {
+		return nil, err
+	}
+	return openBytes(key, ciphertext)
+}
+
+// LoadSymmetricKey returns the project's symmetric key by unwrapping the
+// current user's copy, .kanuka/secrets/<user-uuid>.kanuka, with privateKey.
+// The project is found from the working directory, and the user from the
+// user config. Keys wrapped for a GPG key can't be unwrapped here; use
+// DecryptWithGPG instead.
+func LoadSymmetricKey(privateKey PrivateKey) ([]byte, error) {
+	userConfig, err := configs.EnsureUserConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load user config: %w", err)
+	}
+
+	encryptedSymKey, err := GetProjectKanukaKey(userConfig.User.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if IsGPGWrapped(encryptedSymKey) {
+		return nil, fmt.Errorf("failed to unwrap symmetric key: it is wrapped for a GPG key")
+	}
+
+	symKey, err := DecryptWithPrivateKey(encryptedSymKey, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap symmetric key: %w", err)
+	}
+	return symKey, nil
+}
+
+// symmetricKeyArray checks that symKey is the right length for secretbox and
+// copies it into an array.
+func symmetricKeyArray(symKey []byte) (*[32]byte, error) {
+	if len(symKey) != 32 {
+		return nil, fmt.Errorf("symmetric key length must be exactly 32 bytes for secretbox, got %d", len(symKey))
+	}
+	var key [32]byte
+	copy(key[:], symKey)
+	return &key, nil
+}
+
 // RotateSymmetricKey rotates the symmetric key for all users in the project.
 // It generates a new symmetric key, encrypts it for all users, and re-encrypts all files.
-// currentUserUUID is the UUID of the user performing the rotation.
-func RotateSymmetricKey(currentUserUUID string, privateKey *rsa.PrivateKey, verbose bool) error {
+// currentUserUUID is the UUID of the user performing the rotation. If their
+// key was wrapped for a GPG key it is unwrapped with gpg, and privateKey may be nil.
+func RotateSymmetricKey(currentUserUUID string, privateKey PrivateKey, verbose bool) error {
 	if err := configs.InitProjectSettings(); err != nil {
 		return fmt.Errorf("failed to init project settings: %w", err)
 	}
 
 	projectPath := configs.ProjectKanukaSettings.ProjectPath
-	projectPublicKeyPath := configs.ProjectKanukaSettings.ProjectPublicKeyPath
 
 	// Get all user UUIDs in the project
 	userUUIDs, err := GetAllUsersInProject()
@@ -179,13 +433,15 @@ func RotateSymmetricKey(currentUserUUID string, privateKey *rsa.PrivateKey, verb
 	}
 
 	// Decrypt current symmetric key
-	currentSymKey, err := DecryptWithPrivateKey(currentEncryptedSymKey, privateKey)
+	currentSymKey, err := UnwrapSymmetricKey(currentEncryptedSymKey, func() (PrivateKey, error) {
+		return privateKey, nil
+	})
 	if err != nil {
 		return fmt.Errorf("failed to decrypt current symmetric key: %w", err)
 	}
 
 	// Decrypt all .kanuka files to get plaintext
-	kanukaFiles, err := FindEnvOrKanukaFiles(projectPath, []string{}, true)
+	kanukaFiles, err := FindEnvOrKanukaFiles(projectPath, []string{}, nil, true)
 	if err != nil {
 		return fmt.Errorf("failed to find .kanuka files: %w", err)
 	}
@@ -196,21 +452,18 @@ func RotateSymmetricKey(currentUserUUID string, privateKey *rsa.PrivateKey, verb
 		NewPath string
 	}
 
-	for _, kanukaFile := range kanukaFiles {
-		var key [32]byte
-		copy(key[:], currentSymKey)
+	var key [32]byte
+	copy(key[:], currentSymKey)
 
+	for _, kanukaFile := range kanukaFiles {
 		ciphertext, err := os.ReadFile(kanukaFile)
 		if err != nil {
 			return fmt.Errorf("failed to read .kanuka file %s: %w", kanukaFile, err)
 		}
 
-		var decryptNonce [24]byte
-		copy(decryptNonce[:], ciphertext[:24])
-
-		plaintext, ok := secretbox.Open(nil, ciphertext[24:], &decryptNonce, &key)
-		if !ok {
-			return fmt.Errorf("failed to decrypt file %s", kanukaFile)
+		plaintext, err := openBytes(&key, ciphertext)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt file %s: %w", kanukaFile, err)
 		}
 
 		plaintexts = append(plaintexts, struct {
@@ -232,13 +485,7 @@ func RotateSymmetricKey(currentUserUUID string, privateKey *rsa.PrivateKey, verb
 
 	// Encrypt new symmetric key for each user UUID
 	for _, userUUID := range userUUIDs {
-		publicKeyPath := filepath.Join(projectPublicKeyPath, userUUID+".pub")
-		publicKey, err := LoadPublicKey(publicKeyPath)
-		if err != nil {
-			return fmt.Errorf("failed to load public key for user %s: %w", userUUID, err)
-		}
-
-		encryptedSymKey, err := EncryptWithPublicKey(newSymKey, publicKey)
+		encryptedSymKey, err := WrapKeyForUser(newSymKey, userUUID)
 		if err != nil {
 			return fmt.Errorf("failed to encrypt symmetric key for user %s: %w", userUUID, err)
 		}
@@ -248,12 +495,13 @@ func RotateSymmetricKey(currentUserUUID string, privateKey *rsa.PrivateKey, verb
 		}
 	}
 
-	// Re-encrypt all files with new symmetric key
-	for _, fileData := range plaintexts {
-		inputPaths := []string{fileData.NewPath}
-		if err := EncryptFiles(newSymKey, inputPaths, verbose); err != nil {
-			return fmt.Errorf("failed to re-encrypt file %s: %w", fileData.NewPath, err)
-		}
+	// Re-encrypt all files with new symmetric key in a single pass.
+	newPaths := make([]string, len(plaintexts))
+	for i, fileData := range plaintexts {
+		newPaths[i] = fileData.NewPath
+	}
+	if err := EncryptFiles(newSymKey, newPaths, verbose); err != nil {
+		return fmt.Errorf("failed to re-encrypt files: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,261 @@
+package secrets
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// benchFileCount approximates a monorepo with many small .env files.
+const benchFileCount = 200
+
+// createBenchEnvFiles writes count small .env files into dir and returns their paths.
+func createBenchEnvFiles(tb testing.TB, dir string, count int) []string {
+	tb.Helper()
+	paths := make([]string, count)
+	for i := 0; i < count; i++ {
+		serviceDir := filepath.Join(dir, fmt.Sprintf("service-%03d", i))
+		if err := os.MkdirAll(serviceDir, 0755); err != nil {
+			tb.Fatalf("Failed to create directory: %v", err)
+		}
+		paths[i] = filepath.Join(serviceDir, ".env")
+		content := fmt.Sprintf("API_KEY=key-%d\nDATABASE_URL=postgres://localhost/db%d\n", i, i)
+		if err := os.WriteFile(paths[i], []byte(content), 0600); err != nil {
+			tb.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	return paths
+}
+
+// BenchmarkEncryptFiles measures per-file overhead when encrypting many small files
+// with an already-unwrapped symmetric key.
+func BenchmarkEncryptFiles(b *testing.B) {
+	dir := b.TempDir()
+	paths := createBenchEnvFiles(b, dir, benchFileCount)
+
+	symKey, err := CreateSymmetricKey()
+	if err != nil {
+		b.Fatalf("Failed to create symmetric key: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := EncryptFiles(symKey, paths, false); err != nil {
+			b.Fatalf("EncryptFiles failed: %v", err)
+		}
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*benchFileCount), "ns/file")
+}
+
+// concurrentBenchFileCount is the number of files for comparing serial and
+// concurrent encryption.
+const concurrentBenchFileCount = 100
+
+// BenchmarkEncryptFilesSerial is the baseline for BenchmarkEncryptFilesConcurrently.
+func BenchmarkEncryptFilesSerial(b *testing.B) {
+	paths := createBenchEnvFiles(b, b.TempDir(), concurrentBenchFileCount)
+
+	symKey, err := CreateSymmetricKey()
+	if err != nil {
+		b.Fatalf("Failed to create symmetric key: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := EncryptFiles(symKey, paths, false); err != nil {
+			b.Fatalf("EncryptFiles failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkEncryptFilesConcurrently measures encrypting many small files with
+// the default worker pool of GOMAXPROCS workers.
+func BenchmarkEncryptFilesConcurrently(b *testing.B) {
+	paths := createBenchEnvFiles(b, b.TempDir(), concurrentBenchFileCount)
+
+	symKey, err := CreateSymmetricKey()
+	if err != nil {
+		b.Fatalf("Failed to create symmetric key: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		errs, err := EncryptFilesConcurrently(symKey, paths, 0)
+		if err != nil {
+			b.Fatalf("EncryptFilesConcurrently failed: %v", err)
+		}
+		for _, fileErr := range errs {
+			if fileErr != nil {
+				b.Fatalf("EncryptFilesConcurrently failed: %v", fileErr)
+			}
+		}
+	}
+}
+
+// BenchmarkDecryptFiles measures per-file overhead when decrypting many small files
+// with an already-unwrapped symmetric key.
+func BenchmarkDecryptFiles(b *testing.B) {
+	dir := b.TempDir()
+	paths := createBenchEnvFiles(b, dir, benchFileCount)
+
+	symKey, err := CreateSymmetricKey()
+	if err != nil {
+		b.Fatalf("Failed to create symmetric key: %v", err)
+	}
+	if err := EncryptFiles(symKey, paths, false); err != nil {
+		b.Fatalf("EncryptFiles failed: %v", err)
+	}
+
+	kanukaPaths := make([]string, len(paths))
+	for i, p := range paths {
+		kanukaPaths[i] = p + ".kanuka"
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := DecryptFiles(symKey, kanukaPaths, false); err != nil {
+			b.Fatalf("DecryptFiles failed: %v", err)
+		}
+	}
+	b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*benchFileCount), "ns/file")
+}
+
+// BenchmarkSymmetricKeyUnwrap measures a single RSA unwrap of the symmetric key.
+// Compare against the ns/file metrics above: commands must unwrap once per
+// invocation, never once per file, or this cost is multiplied by the file count.
+func BenchmarkSymmetricKeyUnwrap(b *testing.B) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		b.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	symKey, err := CreateSymmetricKey()
+	if err != nil {
+		b.Fatalf("Failed to create symmetric key: %v", err)
+	}
+
+	encryptedSymKey, err := EncryptWithPublicKey(symKey, &privateKey.PublicKey)
+	if err != nil {
+		b.Fatalf("Failed to encrypt symmetric key: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecryptWithPrivateKey(encryptedSymKey, privateKey); err != nil {
+			b.Fatalf("DecryptWithPrivateKey failed: %v", err)
+		}
+	}
+}
+
+func TestEncryptFilesConcurrently_ReportsEachFailureInOrder(t *testing.T) {
+	dir := t.TempDir()
+	paths := createBenchEnvFiles(t, dir, 10)
+	missing := map[int]bool{2: true, 7: true}
+	for i := range missing {
+		paths[i] = filepath.Join(dir, fmt.Sprintf("missing-%d", i), ".env")
+	}
+
+	symKey, err := CreateSymmetricKey()
+	if err != nil {
+		t.Fatalf("Failed to create symmetric key: %v", err)
+	}
+
+	errs, err := EncryptFilesConcurrently(symKey, paths, 3)
+	if err != nil {
+		t.Fatalf("EncryptFilesConcurrently failed: %v", err)
+	}
+	if len(errs) != len(paths) {
+		t.Fatalf("Expected %d results, got %d", len(paths), len(errs))
+	}
+
+	for i, path := range paths {
+		_, statErr := os.Stat(path + ".kanuka")
+		if missing[i] {
+			if errs[i] == nil {
+				t.Errorf("Expected an error for missing file %d", i)
+			}
+			continue
+		}
+		if errs[i] != nil {
+			t.Errorf("Expected file %d to be encrypted despite other failures, got: %v", i, errs[i])
+		}
+		if statErr != nil {
+			t.Errorf("Expected %s.kanuka to be written: %v", path, statErr)
+		}
+	}
+}
+
+func TestEncryptFilesConcurrently_InvalidKey(t *testing.T) {
+	if _, err := EncryptFilesConcurrently(make([]byte, 16), nil, 0); err == nil {
+		t.Error("Expected error for a 16-byte key, got nil")
+	}
+}
+
+func TestDecryptFiles_TooShort(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env.kanuka")
+	if err := os.WriteFile(path, []byte("short"), 0600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	symKey, err := CreateSymmetricKey()
+	if err != nil {
+		t.Fatalf("Failed to create symmetric key: %v", err)
+	}
+
+	if err := DecryptFiles(symKey, []string{path}, false); err == nil {
+		t.Errorf("Expected error for truncated .kanuka file, got nil")
+	}
+}
+
+func TestEncryptDecryptFiles_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	paths := createBenchEnvFiles(t, dir, 3)
+
+	originals := make([][]byte, len(paths))
+	for i, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("Failed to read test file: %v", err)
+		}
+		originals[i] = data
+	}
+
+	symKey, err := CreateSymmetricKey()
+	if err != nil {
+		t.Fatalf("Failed to create symmetric key: %v", err)
+	}
+	if err := EncryptFiles(symKey, paths, false); err != nil {
+		t.Fatalf("EncryptFiles failed: %v", err)
+	}
+
+	kanukaPaths := make([]string, len(paths))
+	for i, p := range paths {
+		kanukaPaths[i] = p + ".kanuka"
+		if err := os.Remove(p); err != nil {
+			t.Fatalf("Failed to remove plaintext: %v", err)
+		}
+	}
+
+	if err := DecryptFiles(symKey, kanukaPaths, false); err != nil {
+		t.Fatalf("DecryptFiles failed: %v", err)
+	}
+
+	for i, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatalf("Failed to read decrypted file: %v", err)
+		}
+		if string(data) != string(originals[i]) {
+			t.Errorf("Round trip mismatch for %s: got %q, want %q", p, data, originals[i])
+		}
+	}
+}
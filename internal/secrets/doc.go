@@ -9,7 +9,7 @@
 // Kanuka uses a hybrid encryption scheme:
 //
 //  1. A random 256-bit symmetric key encrypts the actual secret files
-//  2. Each user's RSA public key encrypts a copy of the symmetric key
+//  2. Each user's RSA or Ed25519 public key encrypts a copy of the symmetric key
 //  3. Users decrypt the symmetric key with their private key, then decrypt files
 //
 // This allows multiple users to access the same encrypted files without
@@ -24,7 +24,17 @@
 //
 // The symmetric key is stored encrypted for each user:
 //   - Location: .kanuka/secrets/<user-uuid>.kanuka
-//   - Encrypted with the user's RSA public key
+//   - Encrypted with the user's public key
+//
+// RSA public keys wrap the symmetric key with PKCS#1 v1.5. Ed25519 public keys
+// are converted to X25519 and wrap it in a NaCl sealed box, prefixed with a
+// scheme header byte so decryption can tell the two formats apart. RSA-wrapped
+// keys have no header, so projects created before Ed25519 support still work.
+//
+// Users registered with a GPG key have their exported key stored as
+// .kanuka/public_keys/<uuid>.gpg. Their symmetric key is wrapped by shelling
+// out to gpg, prefixed with its own header, and unwrapped with gpg --decrypt
+// through the user's gpg agent.
 //
 // # File Operations
 //
@@ -32,15 +42,27 @@
 //   - Original: .env
 //   - Encrypted: .env.kanuka
 //
-// Encryption uses NaCl secretbox with a random 24-byte nonce prepended
-// to the ciphertext. This means re-encrypting the same file produces
-// different output (non-deterministic encryption).
+// Encryption uses NaCl secretbox over fixed-size chunks, so large files are
+// encrypted and decrypted without reading them into memory. Each file gets
+// a random nonce prefix, so re-encrypting the same file produces different
+// output (non-deterministic encryption). The source file's permissions are
+// recorded in the header and restored on decryption, defaulting to 0600 when
+// none were recorded. Files written by older versions, a single secretbox
+// blob with its nonce prepended, are still decrypted; see stream.go for the
+// formats.
+//
+// To encrypt or decrypt values in memory without going through files, get
+// the project's symmetric key with LoadSymmetricKey and pass it to
+// EncryptBytes and DecryptBytes. Their ciphertext is interchangeable with the
+// contents of a .kanuka file.
 //
 // # Security Considerations
 //
-// Private keys should have 0600 permissions. The package warns when
-// permissions are too permissive but does not enforce this to avoid
-// breaking workflows.
+// Private keys should have 0600 permissions. This isn't enforced by default
+// to avoid breaking workflows; `kanuka secrets doctor` warns about it instead.
+// With --strict-perms, or strict_key_permissions in the user config,
+// LoadPrivateKey rejects keys readable by their group or others with
+// ErrInsecureKeyPermissions.
 //
 // Symmetric keys are 32 bytes (256 bits) for AES-256 equivalent security.
 // RSA keys are 4096 bits by default.
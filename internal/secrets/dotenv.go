@@ -0,0 +1,176 @@
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseDotenv parses the contents of a .env file into key/value pairs.
+//
+// Supported syntax:
+//   - KEY=value, with surrounding whitespace trimmed
+//   - an optional "export " prefix
+//   - blank lines and lines starting with #
+//   - inline comments after unquoted values (" #")
+//   - single-quoted values, taken literally
+//   - double-quoted values, with \n, \r, \t, \" and \\ escapes
+//
+// Later assignments of the same key override earlier ones, as in a shell.
+// Returns an error naming the line if a line is not a valid assignment.
+func ParseDotenv(data []byte) (map[string]string, error) {
+	values := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if lineNum == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimPrefix(line, "export ")
+
+		key, rawValue, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !isValidDotenvKey(key) {
+			return nil, fmt.Errorf("line %d: expected KEY=value", lineNum)
+		}
+
+		value, err := parseDotenvValue(strings.TrimSpace(rawValue))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read dotenv data: %w", err)
+	}
+
+	return values, nil
+}
+
+// DotenvChange is a single key that differs between two parsed .env files.
+type DotenvChange struct {
+	// Key is the variable name.
+	Key string
+
+	// OldValue is the value before the change. Empty for added keys.
+	OldValue string
+
+	// NewValue is the value after the change. Empty for removed keys.
+	NewValue string
+}
+
+// DotenvDiff lists the keys that differ between two parsed .env files.
+type DotenvDiff struct {
+	Added    []DotenvChange
+	Removed  []DotenvChange
+	Modified []DotenvChange
+}
+
+// Empty reports whether the two files held the same keys and values.
+func (d DotenvDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// DiffDotenv compares two sets of key/value pairs, as returned by ParseDotenv.
+// Each list is sorted by key.
+func DiffDotenv(oldValues, newValues map[string]string) DotenvDiff {
+	var diff DotenvDiff
+
+	for key, newValue := range newValues {
+		oldValue, ok := oldValues[key]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, DotenvChange{Key: key, NewValue: newValue})
+		case oldValue != newValue:
+			diff.Modified = append(diff.Modified, DotenvChange{Key: key, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	for key, oldValue := range oldValues {
+		if _, ok := newValues[key]; !ok {
+			diff.Removed = append(diff.Removed, DotenvChange{Key: key, OldValue: oldValue})
+		}
+	}
+
+	for _, changes := range [][]DotenvChange{diff.Added, diff.Removed, diff.Modified} {
+		sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	}
+
+	return diff
+}
+
+// isValidDotenvKey reports whether key is a valid environment variable name.
+func isValidDotenvKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		case r == '.' || r == '-':
+			// Tolerated by most dotenv loaders, e.g. spring.datasource.url.
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseDotenvValue unquotes a value and strips inline comments.
+func parseDotenvValue(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+
+	switch raw[0] {
+	case '\'':
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single-quoted value")
+		}
+		return raw[1 : end+1], nil
+
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(raw); i++ {
+			c := raw[i]
+			if c == '"' {
+				return b.String(), nil
+			}
+			if c == '\\' && i+1 < len(raw) {
+				i++
+				switch raw[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 'r':
+					b.WriteByte('\r')
+				case 't':
+					b.WriteByte('\t')
+				case '"', '\\':
+					b.WriteByte(raw[i])
+				default:
+					b.WriteByte('\\')
+					b.WriteByte(raw[i])
+				}
+				continue
+			}
+			b.WriteByte(c)
+		}
+		return "", fmt.Errorf("unterminated double-quoted value")
+
+	default:
+		if idx := strings.Index(raw, " #"); idx >= 0 {
+			raw = raw[:idx]
+		}
+		return strings.TrimSpace(raw), nil
+	}
+}
@@ -0,0 +1,148 @@
+package secrets
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]string
+	}{
+		{
+			name:  "simple",
+			input: "API_KEY=abc123\nDATABASE_URL=postgres://localhost/db\n",
+			want:  map[string]string{"API_KEY": "abc123", "DATABASE_URL": "postgres://localhost/db"},
+		},
+		{
+			name:  "comments and blank lines",
+			input: "# comment\n\nKEY=value\n  # indented comment\n",
+			want:  map[string]string{"KEY": "value"},
+		},
+		{
+			name:  "export prefix",
+			input: "export KEY=value\n",
+			want:  map[string]string{"KEY": "value"},
+		},
+		{
+			name:  "whitespace around separator",
+			input: "KEY = value \n",
+			want:  map[string]string{"KEY": "value"},
+		},
+		{
+			name:  "inline comment",
+			input: "KEY=value # trailing comment\nHASH=abc#def\n",
+			want:  map[string]string{"KEY": "value", "HASH": "abc#def"},
+		},
+		{
+			name:  "single quotes are literal",
+			input: `KEY='a \n # b'` + "\n",
+			want:  map[string]string{"KEY": `a \n # b`},
+		},
+		{
+			name:  "double quotes with escapes",
+			input: `KEY="line1\nline2 \"quoted\" \\ # not a comment"` + "\n",
+			want:  map[string]string{"KEY": "line1\nline2 \"quoted\" \\ # not a comment"},
+		},
+		{
+			name:  "empty value",
+			input: "EMPTY=\nQUOTED_EMPTY=\"\"\n",
+			want:  map[string]string{"EMPTY": "", "QUOTED_EMPTY": ""},
+		},
+		{
+			name:  "value containing equals",
+			input: "URL=https://example.com/?a=b&c=d\n",
+			want:  map[string]string{"URL": "https://example.com/?a=b&c=d"},
+		},
+		{
+			name:  "later assignment wins",
+			input: "KEY=first\nKEY=second\n",
+			want:  map[string]string{"KEY": "second"},
+		},
+		{
+			name:  "CRLF line endings",
+			input: "A=1\r\nB=2\r\n",
+			want:  map[string]string{"A": "1", "B": "2"},
+		},
+		{
+			name:  "byte order mark",
+			input: "\ufeffKEY=value\n",
+			want:  map[string]string{"KEY": "value"},
+		},
+		{
+			name:  "empty input",
+			input: "",
+			want:  map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDotenv([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("ParseDotenv returned error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDotenv() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDotenv_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"missing equals", "KEY\n"},
+		{"empty key", "=value\n"},
+		{"key starting with digit", "1KEY=value\n"},
+		{"key with space", "MY KEY=value\n"},
+		{"unterminated double quote", "KEY=\"value\n"},
+		{"unterminated single quote", "KEY='value\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseDotenv([]byte(tt.input)); err == nil {
+				t.Errorf("Expected error for %q", tt.input)
+			}
+		})
+	}
+}
+
+func TestDiffDotenv(t *testing.T) {
+	oldValues := map[string]string{"KEEP": "same", "CHANGE": "old", "DROP": "gone", "B_DROP": "x"}
+	newValues := map[string]string{"KEEP": "same", "CHANGE": "new", "ADD": "fresh", "A_ADD": "y"}
+
+	diff := DiffDotenv(oldValues, newValues)
+
+	wantAdded := []DotenvChange{{Key: "ADD", NewValue: "fresh"}, {Key: "A_ADD", NewValue: "y"}}
+	wantRemoved := []DotenvChange{{Key: "B_DROP", OldValue: "x"}, {Key: "DROP", OldValue: "gone"}}
+	wantModified := []DotenvChange{{Key: "CHANGE", OldValue: "old", NewValue: "new"}}
+
+	if !reflect.DeepEqual(diff.Added, wantAdded) {
+		t.Errorf("Added = %+v, want %+v", diff.Added, wantAdded)
+	}
+	if !reflect.DeepEqual(diff.Removed, wantRemoved) {
+		t.Errorf("Removed = %+v, want %+v", diff.Removed, wantRemoved)
+	}
+	if !reflect.DeepEqual(diff.Modified, wantModified) {
+		t.Errorf("Modified = %+v, want %+v", diff.Modified, wantModified)
+	}
+	if diff.Empty() {
+		t.Error("Expected diff to be non-empty")
+	}
+}
+
+func TestDiffDotenv_Identical(t *testing.T) {
+	values := map[string]string{"KEY": "value"}
+	if diff := DiffDotenv(values, values); !diff.Empty() {
+		t.Errorf("Expected empty diff, got %+v", diff)
+	}
+	if diff := DiffDotenv(nil, nil); !diff.Empty() {
+		t.Errorf("Expected empty diff for nil maps, got %+v", diff)
+	}
+}
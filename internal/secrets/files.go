@@ -1,17 +1,23 @@
 package secrets
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/PolarWolf314/kanuka/internal/utils"
+
 	"github.com/bmatcuk/doublestar/v4"
 )
 
 // ResolveFiles takes user-provided paths/globs and returns matching files.
 // If patterns is empty, returns nil (caller should use default behavior).
-// forEncryption=true finds .env* files, forEncryption=false finds *.kanuka files.
+// forEncryption=true finds .env* files, forEncryption=false finds .env*.kanuka
+// files. A file named explicitly, rather than matched by a directory or glob,
+// may have any name: for encryption it must not end in .kanuka, and for
+// decryption it must.
 func ResolveFiles(patterns []string, projectPath string, forEncryption bool) ([]string, error) {
 	if len(patterns) == 0 {
 		// No patterns provided, caller should use default behavior.
@@ -65,11 +71,15 @@ func resolvePattern(pattern string, projectPath string, forEncryption bool) ([]s
 		return nil, fmt.Errorf("file not found: %s", pattern)
 	}
 
-	// Validate that the file matches the expected type.
-	if forEncryption && !isEnvFile(absPattern) {
-		return nil, fmt.Errorf("file is not a .env file: %s", pattern)
+	// Files named explicitly don't need to be .env files, so that files
+	// such as secrets.json can be encrypted too.
+	if isInKanukaDir(absPattern) {
+		return nil, fmt.Errorf("file is inside the .kanuka directory: %s", pattern)
+	}
+	if forEncryption && strings.HasSuffix(absPattern, ".kanuka") {
+		return nil, fmt.Errorf("file is already encrypted: %s", pattern)
 	}
-	if !forEncryption && !isKanukaFile(absPattern) {
+	if !forEncryption && !strings.HasSuffix(absPattern, ".kanuka") {
 		return nil, fmt.Errorf("file is not a .kanuka file: %s", pattern)
 	}
 
@@ -147,7 +157,82 @@ func findFilesInDir(dir string, forEncryption bool) ([]string, error) {
 
 func isEnvFile(path string) bool {
 	base := filepath.Base(path)
-	return strings.Contains(base, ".env") && !strings.HasSuffix(base, ".kanuka")
+	return strings.Contains(base, ".env") && !strings.HasSuffix(base, ".kanuka") && !IsBackupFile(base) &&
+		!utils.IsAtomicTempFile(base)
+}
+
+// BackupSuffix is appended to a .kanuka file's name for the copy of its
+// previous contents kept by encrypt --backup.
+const BackupSuffix = ".bak"
+
+// IsBackupFile reports whether path is a backup of a .kanuka file.
+func IsBackupFile(path string) bool {
+	return strings.HasSuffix(filepath.Base(path), ".kanuka"+BackupSuffix)
+}
+
+// BackupEncryptedFile copies the .kanuka file at path to path+BackupSuffix,
+// replacing any earlier backup, and returns the backup's path.
+func BackupEncryptedFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	backupPath := path + BackupSuffix
+	if err := utils.WriteFileAtomic(backupPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	return backupPath, nil
+}
+
+// readVerifyCiphertext decrypts the .kanuka file RemoveVerifiedPlaintext
+// checks. It is a variable so tests can simulate a file that doesn't verify.
+var readVerifyCiphertext = ReadEncryptedFile
+
+// RemoveVerifiedPlaintext deletes the .env file at plaintextPath, but only
+// once the .kanuka file at encryptedPath decrypts with symKey to exactly its
+// current contents. If verification fails for any reason the plaintext is
+// left untouched.
+//
+// The plaintext is overwritten with zeros and synced before it is removed.
+// This is best effort: SSDs, copy-on-write filesystems, and backups may still
+// hold the old contents.
+func RemoveVerifiedPlaintext(symKey []byte, plaintextPath, encryptedPath string) error {
+	plaintext, err := os.ReadFile(plaintextPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", plaintextPath, err)
+	}
+	decrypted, err := readVerifyCiphertext(symKey, encryptedPath)
+	if err != nil {
+		return fmt.Errorf("failed to verify %s: %w", encryptedPath, err)
+	}
+	if !bytes.Equal(plaintext, decrypted) {
+		return fmt.Errorf("failed to verify %s: it does not decrypt to the contents of %s", encryptedPath, plaintextPath)
+	}
+	return shredFile(plaintextPath, int64(len(plaintext)))
+}
+
+// shredFile overwrites the first size bytes of path with zeros, syncs it, and
+// removes it.
+func shredFile(path string, size int64) error {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	if _, err := f.Write(make([]byte, size)); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to overwrite %s: %w", path, err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", path, err)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return nil
 }
 
 func isKanukaFile(path string) bool {
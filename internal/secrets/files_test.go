@@ -3,6 +3,7 @@ package secrets
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -266,6 +267,54 @@ func TestResolveFiles_WrongFileType(t *testing.T) {
 	if err == nil {
 		t.Fatal("Expected error when using .kanuka file for encryption")
 	}
+	if !strings.Contains(err.Error(), "already encrypted") {
+		t.Errorf("Expected an already encrypted error, got: %v", err)
+	}
+}
+
+func TestResolveFiles_ArbitraryFileNamedExplicitly(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	jsonFile := filepath.Join(tmpDir, "secrets.json")
+	writeTestFile(t, jsonFile, `{"key": "value"}`)
+	writeTestFile(t, jsonFile+".kanuka", "encrypted")
+
+	files, err := ResolveFiles([]string{"secrets.json"}, tmpDir, true)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(files) != 1 || files[0] != jsonFile {
+		t.Errorf("Expected [%s], got: %v", jsonFile, files)
+	}
+
+	files, err = ResolveFiles([]string{"secrets.json.kanuka"}, tmpDir, false)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(files) != 1 || files[0] != jsonFile+".kanuka" {
+		t.Errorf("Expected [%s.kanuka], got: %v", jsonFile, files)
+	}
+
+	// Directories and globs still only match .env files.
+	files, err = ResolveFiles([]string{"*.json"}, tmpDir, true)
+	if err == nil {
+		t.Errorf("Expected a glob not to match secrets.json, got: %v", files)
+	}
+}
+
+func TestResolveFiles_FileInKanukaDir(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	kanukaDir := filepath.Join(tmpDir, ".kanuka")
+	if err := os.MkdirAll(kanukaDir, 0755); err != nil {
+		t.Fatalf("Failed to create .kanuka dir: %v", err)
+	}
+	writeTestFile(t, filepath.Join(kanukaDir, "config.toml"), "[project]")
+
+	_, err := ResolveFiles([]string{".kanuka/config.toml"}, tmpDir, true)
+	if err == nil || !strings.Contains(err.Error(), "inside the .kanuka directory") {
+		t.Errorf("Expected a .kanuka directory error, got: %v", err)
+	}
 }
 
 func TestIsEnvFile(t *testing.T) {
@@ -279,6 +328,7 @@ func TestIsEnvFile(t *testing.T) {
 		{"path/to/.env", true},
 		{".env.kanuka", false},
 		{".env.local.kanuka", false},
+		{".env.kanuka.bak", false},
 		{"config.toml", false},
 		{"README.md", false},
 	}
@@ -339,3 +389,65 @@ func TestIsInKanukaDir(t *testing.T) {
 		})
 	}
 }
+
+func TestRemoveVerifiedPlaintext(t *testing.T) {
+	symKey := make([]byte, 32)
+	for i := range symKey {
+		symKey[i] = byte(i)
+	}
+
+	encryptTestFile := func(t *testing.T) (string, string) {
+		t.Helper()
+		envPath := filepath.Join(t.TempDir(), ".env")
+		writeTestFile(t, envPath, "API_KEY=secret\n")
+		if err := EncryptFiles(symKey, []string{envPath}, false); err != nil {
+			t.Fatalf("Failed to encrypt %s: %v", envPath, err)
+		}
+		return envPath, envPath + ".kanuka"
+	}
+
+	t.Run("removes plaintext that verifies", func(t *testing.T) {
+		envPath, kanukaPath := encryptTestFile(t)
+
+		if err := RemoveVerifiedPlaintext(symKey, envPath, kanukaPath); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if _, err := os.Stat(envPath); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", envPath)
+		}
+		plaintext, err := ReadEncryptedFile(symKey, kanukaPath)
+		if err != nil || string(plaintext) != "API_KEY=secret\n" {
+			t.Errorf("Expected the .kanuka file to still decrypt, got %q: %v", plaintext, err)
+		}
+	})
+
+	t.Run("keeps plaintext when the ciphertext does not decrypt", func(t *testing.T) {
+		envPath, kanukaPath := encryptTestFile(t)
+
+		original := readVerifyCiphertext
+		defer func() { readVerifyCiphertext = original }()
+		readVerifyCiphertext = func([]byte, string) ([]byte, error) {
+			return nil, os.ErrInvalid
+		}
+
+		if err := RemoveVerifiedPlaintext(symKey, envPath, kanukaPath); err == nil {
+			t.Fatal("Expected a verification error")
+		}
+		if data, err := os.ReadFile(envPath); err != nil || string(data) != "API_KEY=secret\n" {
+			t.Errorf("Expected %s to be kept unchanged, got %q: %v", envPath, data, err)
+		}
+	})
+
+	t.Run("keeps plaintext that differs from the ciphertext", func(t *testing.T) {
+		envPath, kanukaPath := encryptTestFile(t)
+		writeTestFile(t, envPath, "API_KEY=changed\n")
+
+		err := RemoveVerifiedPlaintext(symKey, envPath, kanukaPath)
+		if err == nil || !strings.Contains(err.Error(), "does not decrypt") {
+			t.Fatalf("Expected a mismatch error, got: %v", err)
+		}
+		if _, err := os.Stat(envPath); err != nil {
+			t.Errorf("Expected %s to be kept: %v", envPath, err)
+		}
+	})
+}
@@ -8,6 +8,9 @@ import (
 	"strings"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/internal/utils"
+
+	"github.com/bmatcuk/doublestar/v4"
 )
 
 // EnsureUserSettings ensures that the user's Kanuka data and config directory exists.
@@ -81,18 +84,72 @@ func EnsureKanukaSettings() error {
 	return nil
 }
 
+// FileMatch describes why a path was included in or excluded from file discovery.
+type FileMatch struct {
+	// Path is the absolute path of the file or directory.
+	Path string
+
+	// Matched is true if the file would be acted on.
+	Matched bool
+
+	// Reason explains why the path was matched or excluded.
+	Reason string
+
+	// IgnorePattern is the .kanuka/ignore pattern that excluded the path, if any.
+	IgnorePattern string
+}
+
+// DiscoverOptions narrows which files ExplainEnvOrKanukaFiles considers. The
+// zero value searches every subdirectory and accepts any .env file name.
+type DiscoverOptions struct {
+	// NoRecurse searches only rootDir, not its subdirectories.
+	NoRecurse bool
+
+	// NameFilter is a glob that a file's base name must match, such as
+	// ".env.prod*". A .kanuka file is matched by the name of the .env file
+	// it encrypts. Empty matches every name.
+	NameFilter string
+}
+
+// ValidateNameFilter reports an error if pattern is not a valid
+// DiscoverOptions.NameFilter.
+func ValidateNameFilter(pattern string) error {
+	if strings.ContainsRune(pattern, '/') || !doublestar.ValidatePattern(pattern) {
+		return fmt.Errorf("invalid name filter %q", pattern)
+	}
+	return nil
+}
+
 // FindEnvOrKanukaFiles finds .env or .kanuka files in the project directory.
-func FindEnvOrKanukaFiles(rootDir string, ignoreDirs []string, isKanuka bool) ([]string, error) {
+// Files and directories matched by rules are skipped; a .kanuka file is
+// matched by the path of the .env file it encrypts. Pass nil rules to find
+// every file.
+func FindEnvOrKanukaFiles(rootDir string, ignoreDirs []string, rules *IgnoreRules, isKanuka bool) ([]string, error) {
+	matches, err := ExplainEnvOrKanukaFiles(rootDir, ignoreDirs, rules, isKanuka, DiscoverOptions{})
+
 	var result []string
+	for _, m := range matches {
+		if m.Matched {
+			result = append(result, m.Path)
+		}
+	}
+
+	return result, err
+}
+
+// ExplainEnvOrKanukaFiles walks the project directory like FindEnvOrKanukaFiles,
+// but also reports candidate files and directories that were excluded, with the
+// reason for each decision. Files whose name does not contain ".env" are not
+// candidates and are omitted entirely. opts can limit the search further;
+// an invalid opts.NameFilter matches no files.
+func ExplainEnvOrKanukaFiles(rootDir string, ignoreDirs []string, rules *IgnoreRules, isKanuka bool, opts DiscoverOptions) ([]FileMatch, error) {
+	var result []FileMatch
 
 	ignoreMap := make(map[string]bool)
 	for _, dir := range ignoreDirs {
 		ignoreMap[dir] = true
 	}
 
-	// Always ignore searching for .env files in .kanuka/
-	ignoreMap[".kanuka"] = true
-
 	err := filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return fmt.Errorf("failed while walking directory: %w", err)
@@ -100,30 +157,85 @@ func FindEnvOrKanukaFiles(rootDir string, ignoreDirs []string, isKanuka bool) ([
 
 		// Skip ignored directories
 		if d.IsDir() {
+			// Always ignore searching for .env files in .kanuka/
+			if filepath.Base(path) == ".kanuka" {
+				result = append(result, FileMatch{Path: path, Reason: "Kānuka metadata directory is never searched"})
+				return filepath.SkipDir
+			}
 			if ignoreMap[filepath.Base(path)] {
+				result = append(result, FileMatch{Path: path, Reason: "directory is in the ignore list"})
+				return filepath.SkipDir
+			}
+			if pattern, ignored := rules.Match(relativePath(rootDir, path), true); ignored && path != rootDir {
+				result = append(result, FileMatch{Path: path, Reason: ignoreReason(pattern), IgnorePattern: pattern})
+				return filepath.SkipDir
+			}
+			if opts.NoRecurse && path != rootDir {
+				result = append(result, FileMatch{Path: path, Reason: "subdirectory not searched (recursion disabled)"})
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
+		base := filepath.Base(path)
+		if !strings.Contains(base, ".env") {
+			return nil
+		}
+
 		// Skip irregular files such as sockets, pipes, devices, etc
 		if !d.Type().IsRegular() {
+			result = append(result, FileMatch{Path: path, Reason: "not a regular file (symlink, socket, pipe, or device)"})
 			return nil
 		}
 
-		if isKanuka {
-			if strings.Contains(filepath.Base(path), ".env") && strings.Contains(path, ".kanuka") {
-				result = append(result, path)
-			}
-		} else {
-			// Check if the filename contains ".env"
-			if strings.Contains(filepath.Base(path), ".env") && !strings.Contains(path, ".kanuka") {
-				result = append(result, path)
+		if opts.NameFilter != "" {
+			if matched, _ := doublestar.Match(opts.NameFilter, strings.TrimSuffix(base, ".kanuka")); !matched {
+				result = append(result, FileMatch{Path: path, Reason: fmt.Sprintf("name does not match filter %q", opts.NameFilter)})
+				return nil
 			}
 		}
 
+		hasKanuka := strings.Contains(path, ".kanuka")
+		if pattern, ignored := rules.Match(relativePath(rootDir, strings.TrimSuffix(path, ".kanuka")), false); ignored && hasKanuka == isKanuka {
+			result = append(result, FileMatch{Path: path, Reason: ignoreReason(pattern), IgnorePattern: pattern})
+			return nil
+		}
+
+		switch {
+		// Backups and interrupted writes are never encrypted or decrypted,
+		// so decrypt can't write plaintext over them.
+		case IsBackupFile(base):
+			result = append(result, FileMatch{Path: path, Reason: "backup of a .kanuka file"})
+		case utils.IsAtomicTempFile(base):
+			result = append(result, FileMatch{Path: path, Reason: "temporary file left by an interrupted write"})
+		case isKanuka && hasKanuka:
+			result = append(result, FileMatch{Path: path, Matched: true, Reason: `name contains ".env" and path contains ".kanuka"`})
+		case isKanuka:
+			result = append(result, FileMatch{Path: path, Reason: "plaintext file, not encrypted"})
+		case hasKanuka && strings.HasSuffix(base, ".kanuka"):
+			result = append(result, FileMatch{Path: path, Reason: "already encrypted (.kanuka file)"})
+		case hasKanuka:
+			result = append(result, FileMatch{Path: path, Reason: `path contains ".kanuka"`})
+		default:
+			result = append(result, FileMatch{Path: path, Matched: true, Reason: `name contains ".env"`})
+		}
+
 		return nil
 	})
 
 	return result, err
 }
+
+// relativePath returns path relative to rootDir, or path unchanged if that fails.
+func relativePath(rootDir, path string) string {
+	rel, err := filepath.Rel(rootDir, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// ignoreReason explains that a path was skipped by a .kanuka/ignore pattern.
+func ignoreReason(pattern string) string {
+	return fmt.Sprintf("matches .kanuka/ignore pattern %q", pattern)
+}
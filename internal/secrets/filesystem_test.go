@@ -0,0 +1,83 @@
+package secrets
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestExplainEnvOrKanukaFiles_DiscoverOptions(t *testing.T) {
+	root := t.TempDir()
+	writeIgnoreTestFiles(t, root,
+		".env",
+		".env.production",
+		".env.production.kanuka",
+		"api/.env",
+		"api/.env.prod-eu",
+		"api/.env.prod-eu.kanuka",
+		"api/worker/.env.production",
+	)
+
+	tests := []struct {
+		name     string
+		opts     DiscoverOptions
+		isKanuka bool
+		want     []string
+	}{
+		{
+			name: "default is recursive",
+			want: []string{".env", ".env.production", "api/.env", "api/.env.prod-eu", "api/worker/.env.production"},
+		},
+		{
+			name: "without recursion",
+			opts: DiscoverOptions{NoRecurse: true},
+			want: []string{".env", ".env.production"},
+		},
+		{
+			name: "name filter",
+			opts: DiscoverOptions{NameFilter: ".env.prod*"},
+			want: []string{".env.production", "api/.env.prod-eu", "api/worker/.env.production"},
+		},
+		{
+			name: "name filter without recursion",
+			opts: DiscoverOptions{NoRecurse: true, NameFilter: ".env.prod*"},
+			want: []string{".env.production"},
+		},
+		{
+			name:     "name filter matches the .env name of .kanuka files",
+			opts:     DiscoverOptions{NameFilter: ".env.prod*"},
+			isKanuka: true,
+			want:     []string{".env.production.kanuka", "api/.env.prod-eu.kanuka"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := ExplainEnvOrKanukaFiles(root, []string{}, nil, tt.isKanuka, tt.opts)
+			if err != nil {
+				t.Fatalf("ExplainEnvOrKanukaFiles failed: %v", err)
+			}
+			var found []string
+			for _, m := range matches {
+				if m.Matched {
+					found = append(found, m.Path)
+				}
+			}
+			if got := relativeFiles(t, root, found); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateNameFilter(t *testing.T) {
+	for _, pattern := range []string{".env", ".env.prod*", ".env.{staging,production}"} {
+		if err := ValidateNameFilter(pattern); err != nil {
+			t.Errorf("ValidateNameFilter(%q) = %v, want nil", pattern, err)
+		}
+	}
+	for _, pattern := range []string{".env.prod*[", "api/.env"} {
+		if err := ValidateNameFilter(pattern); err == nil {
+			t.Errorf("ValidateNameFilter(%q) = nil, want an error", pattern)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package secrets
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+)
+
+// fingerprintSize is how many bytes of the SHA-256 digest a fingerprint keeps.
+// 128 bits is short enough to read aloud and long enough that a swapped key
+// can't be made to match.
+const fingerprintSize = 16
+
+// PublicKeyFingerprint returns a short fingerprint for comparing a public key
+// out of band: "SHA256:" followed by the unpadded base64 of the first 16 bytes
+// of the SHA-256 of the key's PKIX encoding. The same key always has the same
+// fingerprint, whichever format it was read from.
+func PublicKeyFingerprint(publicKey crypto.PublicKey) (string, error) {
+	if _, err := PublicKeyType(publicKey); err != nil {
+		return "", err
+	}
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode public key: %w", err)
+	}
+	digest := sha256.Sum256(der)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(digest[:fingerprintSize]), nil
+}
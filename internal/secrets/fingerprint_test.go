@@ -0,0 +1,58 @@
+package secrets
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+)
+
+func TestPublicKeyFingerprint(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	otherRSAKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	edKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+
+	first, err := PublicKeyFingerprint(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("PublicKeyFingerprint failed: %v", err)
+	}
+	if !strings.HasPrefix(first, "SHA256:") {
+		t.Errorf("Expected a SHA256: prefix, got %q", first)
+	}
+
+	// A copy of the key, as if parsed again from its file.
+	copied := rsa.PublicKey{N: rsaKey.N, E: rsaKey.E}
+	again, err := PublicKeyFingerprint(&copied)
+	if err != nil {
+		t.Fatalf("PublicKeyFingerprint failed: %v", err)
+	}
+	if again != first {
+		t.Errorf("Expected the same key to have the same fingerprint, got %q and %q", first, again)
+	}
+
+	other, err := PublicKeyFingerprint(&otherRSAKey.PublicKey)
+	if err != nil {
+		t.Fatalf("PublicKeyFingerprint failed: %v", err)
+	}
+	ed, err := PublicKeyFingerprint(edKey)
+	if err != nil {
+		t.Fatalf("PublicKeyFingerprint failed: %v", err)
+	}
+	if other == first || ed == first || ed == other {
+		t.Errorf("Expected different keys to have different fingerprints, got %q, %q, %q", first, other, ed)
+	}
+
+	if _, err := PublicKeyFingerprint("not a key"); err == nil {
+		t.Error("Expected an error for an unsupported key type")
+	}
+}
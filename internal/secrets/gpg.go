@@ -0,0 +1,143 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+)
+
+// wrapSchemeGPG is the header byte of symmetric keys wrapped for a GPG key.
+const wrapSchemeGPG byte = 0x02
+
+// gpgWrapHeader prefixes symmetric keys wrapped for a GPG key. The header is
+// longer than the X25519 one because GPG output has no fixed size to check.
+var gpgWrapHeader = []byte{wrapSchemeGPG, 'G', 'P', 'G'}
+
+// gpgCommand is the gpg binary looked up on PATH.
+const gpgCommand = "gpg"
+
+// GPGAvailable reports whether gpg is installed and on PATH.
+func GPGAvailable() bool {
+	_, err := exec.LookPath(gpgCommand)
+	return err == nil
+}
+
+// IsGPGWrapped reports whether a wrapped symmetric key was wrapped for a GPG key.
+func IsGPGWrapped(wrapped []byte) bool {
+	return bytes.HasPrefix(wrapped, gpgWrapHeader)
+}
+
+// GPGPublicKeyPath returns where a user's exported GPG public key is stored in the project.
+func GPGPublicKeyPath(userUUID string) string {
+	return filepath.Join(configs.ProjectKanukaSettings.ProjectPublicKeyPath, userUUID+".gpg")
+}
+
+// ExportGPGPublicKey exports the ASCII-armored public key for keyID from the
+// local gpg keyring.
+func ExportGPGPublicKey(keyID string) ([]byte, error) {
+	out, err := runGPG(nil, "--armor", "--export", keyID)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil, fmt.Errorf("no GPG public key found for %q", keyID)
+	}
+	return out, nil
+}
+
+// EncryptWithGPG wraps a symmetric key for the GPG public key stored at
+// keyPath and prefixes it with the GPG scheme header.
+func EncryptWithGPG(plaintext []byte, keyPath string) ([]byte, error) {
+	out, err := runGPG(plaintext, "--batch", "--yes", "--recipient-file", keyPath, "--encrypt")
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, gpgWrapHeader...), out...), nil
+}
+
+// DecryptWithGPG unwraps a symmetric key created by EncryptWithGPG using the
+// local gpg agent, which prompts for the key's passphrase if needed.
+func DecryptWithGPG(wrapped []byte) ([]byte, error) {
+	if !IsGPGWrapped(wrapped) {
+		return nil, errors.New("symmetric key was not wrapped for a GPG key")
+	}
+	return runGPG(wrapped[len(gpgWrapHeader):], "--batch", "--quiet", "--decrypt")
+}
+
+// UnwrapSymmetricKey decrypts a user's wrapped symmetric key. Keys wrapped
+// for a GPG key are decrypted with gpg; all others with the private key from
+// loadKey, which is only called for them, so GPG users need no private key.
+//
+// Returns ErrGPGNotFound if the key was wrapped for a GPG key and gpg isn't
+// installed, and ErrKeyDecryptFailed if the key can't be decrypted. Errors
+// from loadKey are returned unchanged.
+func UnwrapSymmetricKey(wrapped []byte, loadKey func() (PrivateKey, error)) ([]byte, error) {
+	if IsGPGWrapped(wrapped) {
+		if !GPGAvailable() {
+			return nil, kerrors.ErrGPGNotFound
+		}
+		symKey, err := DecryptWithGPG(wrapped)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", kerrors.ErrKeyDecryptFailed, err)
+		}
+		return symKey, nil
+	}
+
+	privateKey, err := loadKey()
+	if err != nil {
+		return nil, err
+	}
+	symKey, err := DecryptWithPrivateKey(wrapped, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrKeyDecryptFailed, err)
+	}
+	return symKey, nil
+}
+
+// WrapKeyForUser wraps a symmetric key for a project user. Users registered
+// with a GPG key are wrapped with gpg; everyone else with their RSA or Ed25519
+// public key.
+func WrapKeyForUser(symKey []byte, userUUID string) ([]byte, error) {
+	gpgKeyPath := GPGPublicKeyPath(userUUID)
+	if _, err := os.Stat(gpgKeyPath); err == nil {
+		return EncryptWithGPG(symKey, gpgKeyPath)
+	}
+
+	publicKeyPath := filepath.Join(configs.ProjectKanukaSettings.ProjectPublicKeyPath, userUUID+".pub")
+	publicKey, err := LoadPublicKey(publicKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load public key: %w", err)
+	}
+	return EncryptWithPublicKey(symKey, publicKey)
+}
+
+// runGPG runs gpg with args, feeding it stdin, and returns its output.
+func runGPG(stdin []byte, args ...string) ([]byte, error) {
+	if !GPGAvailable() {
+		return nil, errors.New("gpg was not found on PATH")
+	}
+
+	cmd := exec.Command(gpgCommand, args...)
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("gpg failed: %s", msg)
+	}
+	return stdout.Bytes(), nil
+}
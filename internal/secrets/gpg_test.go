@@ -0,0 +1,175 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+)
+
+func TestIsGPGWrapped(t *testing.T) {
+	if !IsGPGWrapped(append(append([]byte{}, gpgWrapHeader...), 0x85, 0x01)) {
+		t.Error("expected key with GPG header to be detected")
+	}
+	if IsGPGWrapped([]byte{wrapSchemeGPG}) {
+		t.Error("expected truncated header not to be detected")
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	wrapped, err := EncryptWithPublicKey(make([]byte, 32), &privateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to wrap key: %v", err)
+	}
+	if IsGPGWrapped(wrapped) {
+		t.Error("expected RSA-wrapped key not to be detected as GPG-wrapped")
+	}
+}
+
+func TestDecryptWithPrivateKey_RejectsGPGWrappedKey(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+
+	wrapped := append(append([]byte{}, gpgWrapHeader...), make([]byte, 64)...)
+	if _, err := DecryptWithPrivateKey(wrapped, privateKey); err == nil {
+		t.Error("expected an error decrypting a GPG-wrapped key with an RSA key")
+	}
+}
+
+// generateGPGKey creates a passphraseless GPG key in a temporary keyring and
+// writes its exported public key to keyPath. The test is skipped if gpg isn't
+// installed.
+func generateGPGKey(t *testing.T, keyPath string) {
+	t.Helper()
+	if !GPGAvailable() {
+		t.Skip("gpg not on PATH")
+	}
+
+	gpgHome := t.TempDir()
+	t.Setenv("GNUPGHOME", gpgHome)
+	t.Cleanup(func() {
+		_ = exec.Command("gpgconf", "--kill", "gpg-agent").Run()
+	})
+
+	if _, err := runGPG(nil, "--batch", "--passphrase", "", "--quick-gen-key", "gpg-test@example.com", "default", "default", "never"); err != nil {
+		t.Fatalf("failed to generate GPG key: %v", err)
+	}
+
+	exported, err := ExportGPGPublicKey("gpg-test@example.com")
+	if err != nil {
+		t.Fatalf("ExportGPGPublicKey failed: %v", err)
+	}
+	if err := os.WriteFile(keyPath, exported, 0644); err != nil {
+		t.Fatalf("failed to write exported key: %v", err)
+	}
+}
+
+func TestGPGRoundTrip(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "key.gpg")
+	generateGPGKey(t, keyPath)
+
+	symKey, err := CreateSymmetricKey()
+	if err != nil {
+		t.Fatalf("failed to create symmetric key: %v", err)
+	}
+
+	wrapped, err := EncryptWithGPG(symKey, keyPath)
+	if err != nil {
+		t.Fatalf("EncryptWithGPG failed: %v", err)
+	}
+	if !IsGPGWrapped(wrapped) {
+		t.Fatal("expected wrapped key to carry the GPG header")
+	}
+
+	unwrapped, err := DecryptWithGPG(wrapped)
+	if err != nil {
+		t.Fatalf("DecryptWithGPG failed: %v", err)
+	}
+	if !bytes.Equal(unwrapped, symKey) {
+		t.Error("unwrapped key does not match the original")
+	}
+
+	if _, err := ExportGPGPublicKey("nobody@example.com"); err == nil {
+		t.Error("expected an error exporting an unknown key")
+	}
+}
+
+func TestUnwrapSymmetricKey(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	symKey, err := CreateSymmetricKey()
+	if err != nil {
+		t.Fatalf("failed to create symmetric key: %v", err)
+	}
+	wrapped, err := EncryptWithPublicKey(symKey, &privateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to wrap key: %v", err)
+	}
+
+	t.Run("private key", func(t *testing.T) {
+		unwrapped, err := UnwrapSymmetricKey(wrapped, func() (PrivateKey, error) {
+			return privateKey, nil
+		})
+		if err != nil {
+			t.Fatalf("UnwrapSymmetricKey failed: %v", err)
+		}
+		if !bytes.Equal(unwrapped, symKey) {
+			t.Error("unwrapped key does not match the original")
+		}
+	})
+
+	t.Run("loader error is returned unchanged", func(t *testing.T) {
+		loadErr := errors.New("no key")
+		_, err := UnwrapSymmetricKey(wrapped, func() (PrivateKey, error) {
+			return nil, loadErr
+		})
+		if !errors.Is(err, loadErr) {
+			t.Errorf("expected the loader's error, got %v", err)
+		}
+	})
+
+	t.Run("wrong private key", func(t *testing.T) {
+		wrongKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("failed to generate RSA key: %v", err)
+		}
+		_, err = UnwrapSymmetricKey(wrapped, func() (PrivateKey, error) {
+			return wrongKey, nil
+		})
+		if !errors.Is(err, kerrors.ErrKeyDecryptFailed) {
+			t.Errorf("expected ErrKeyDecryptFailed, got %v", err)
+		}
+	})
+
+	t.Run("GPG key does not load the private key", func(t *testing.T) {
+		keyPath := filepath.Join(t.TempDir(), "key.gpg")
+		generateGPGKey(t, keyPath)
+		gpgWrapped, err := EncryptWithGPG(symKey, keyPath)
+		if err != nil {
+			t.Fatalf("EncryptWithGPG failed: %v", err)
+		}
+
+		unwrapped, err := UnwrapSymmetricKey(gpgWrapped, func() (PrivateKey, error) {
+			t.Error("expected the private key not to be loaded")
+			return nil, errors.New("no key")
+		})
+		if err != nil {
+			t.Fatalf("UnwrapSymmetricKey failed: %v", err)
+		}
+		if !bytes.Equal(unwrapped, symKey) {
+			t.Error("unwrapped key does not match the original")
+		}
+	})
+}
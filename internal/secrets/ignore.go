@@ -0,0 +1,125 @@
+package secrets
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// IgnoreFileName is the name of the ignore file inside a project's .kanuka
+// directory.
+const IgnoreFileName = "ignore"
+
+// IgnoreRules holds the patterns from a project's .kanuka/ignore file.
+//
+// Patterns use gitignore syntax:
+//   - Blank lines and lines starting with # are skipped
+//   - A pattern without a slash matches a name at any depth, e.g. *.example
+//   - A pattern with a slash is relative to the project root, e.g. config/*.local
+//   - A trailing slash only matches directories, e.g. fixtures/
+//   - ** matches any number of directories, e.g. **/testdata/*.env
+//   - A leading ! re-includes a path excluded by an earlier pattern
+//
+// The last matching pattern wins. A nil *IgnoreRules matches nothing.
+type IgnoreRules struct {
+	patterns []ignorePattern
+}
+
+type ignorePattern struct {
+	// raw is the pattern as written in the ignore file.
+	raw string
+
+	// glob is the doublestar pattern matched against slash-separated paths
+	// relative to the project root.
+	glob string
+
+	negate  bool
+	dirOnly bool
+}
+
+// LoadIgnoreRules reads .kanuka/ignore from the project directory. It returns
+// nil rules, which match nothing, if the file does not exist.
+func LoadIgnoreRules(projectPath string) (*IgnoreRules, error) {
+	ignorePath := filepath.Join(projectPath, ".kanuka", IgnoreFileName)
+	file, err := os.Open(ignorePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", ignorePath, err)
+	}
+	defer file.Close()
+
+	rules := &IgnoreRules{}
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		pattern, ok, err := parseIgnorePattern(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", ignorePath, lineNumber, err)
+		}
+		if ok {
+			rules.patterns = append(rules.patterns, pattern)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ignorePath, err)
+	}
+
+	return rules, nil
+}
+
+// Match reports whether relPath, relative to the project root, is ignored,
+// and returns the pattern that decided it.
+func (r *IgnoreRules) Match(relPath string, isDir bool) (pattern string, ignored bool) {
+	if r == nil {
+		return "", false
+	}
+
+	relPath = filepath.ToSlash(relPath)
+	for _, p := range r.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if matched, _ := doublestar.Match(p.glob, relPath); matched {
+			pattern, ignored = p.raw, !p.negate
+		}
+	}
+	return pattern, ignored
+}
+
+// parseIgnorePattern parses one line of an ignore file. ok is false for blank
+// lines and comments.
+func parseIgnorePattern(line string) (pattern ignorePattern, ok bool, err error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false, nil
+	}
+
+	pattern.raw = line
+	glob := line
+	if rest, found := strings.CutPrefix(glob, "!"); found {
+		pattern.negate = true
+		glob = rest
+	}
+	if rest, found := strings.CutSuffix(glob, "/"); found {
+		pattern.dirOnly = true
+		glob = rest
+	}
+
+	// A slash anywhere but the end anchors the pattern to the project root.
+	if rest, found := strings.CutPrefix(glob, "/"); found {
+		glob = rest
+	} else if !strings.Contains(glob, "/") {
+		glob = "**/" + glob
+	}
+
+	if glob == "" || !doublestar.ValidatePattern(glob) {
+		return ignorePattern{}, false, fmt.Errorf("invalid ignore pattern %q", line)
+	}
+	pattern.glob = glob
+	return pattern, true, nil
+}
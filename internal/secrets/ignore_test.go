@@ -0,0 +1,162 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeIgnoreTestFiles(t *testing.T, root string, files ...string) {
+	t.Helper()
+	for _, f := range files {
+		path := filepath.Join(root, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", f, err)
+		}
+		if err := os.WriteFile(path, []byte("KEY=value\n"), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", f, err)
+		}
+	}
+}
+
+func relativeFiles(t *testing.T, root string, files []string) []string {
+	t.Helper()
+	var rel []string
+	for _, f := range files {
+		r, err := filepath.Rel(root, f)
+		if err != nil {
+			t.Fatalf("Failed to make %s relative: %v", f, err)
+		}
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	slices.Sort(rel)
+	return rel
+}
+
+func TestFindEnvOrKanukaFiles_IgnoreFile(t *testing.T) {
+	root := t.TempDir()
+	writeIgnoreTestFiles(t, root,
+		".env",
+		".env.example",
+		"api/.env",
+		"api/.env.example",
+		"config/.env.local",
+		"config/.env",
+		"other/config/.env.local",
+		"fixtures/.env",
+		".env.kanuka",
+		".env.example.kanuka",
+		"config/.env.local.kanuka",
+	)
+	ignore := "# Templates are committed in plaintext\n*.example\n\nconfig/*.local\nfixtures/\n"
+	writeIgnoreTestFiles(t, root, ".kanuka/secrets/.keep")
+	if err := os.WriteFile(filepath.Join(root, ".kanuka", IgnoreFileName), []byte(ignore), 0600); err != nil {
+		t.Fatalf("Failed to write ignore file: %v", err)
+	}
+
+	rules, err := LoadIgnoreRules(root)
+	if err != nil {
+		t.Fatalf("LoadIgnoreRules failed: %v", err)
+	}
+
+	envFiles, err := FindEnvOrKanukaFiles(root, []string{}, rules, false)
+	if err != nil {
+		t.Fatalf("FindEnvOrKanukaFiles failed: %v", err)
+	}
+	wantEnv := []string{".env", "api/.env", "config/.env", "other/config/.env.local"}
+	if got := relativeFiles(t, root, envFiles); !slices.Equal(got, wantEnv) {
+		t.Errorf("Expected .env files %v, got %v", wantEnv, got)
+	}
+
+	kanukaFiles, err := FindEnvOrKanukaFiles(root, []string{}, rules, true)
+	if err != nil {
+		t.Fatalf("FindEnvOrKanukaFiles failed: %v", err)
+	}
+	wantKanuka := []string{".env.kanuka"}
+	if got := relativeFiles(t, root, kanukaFiles); !slices.Equal(got, wantKanuka) {
+		t.Errorf("Expected .kanuka files %v, got %v", wantKanuka, got)
+	}
+
+	matches, err := ExplainEnvOrKanukaFiles(root, []string{}, rules, false, DiscoverOptions{})
+	if err != nil {
+		t.Fatalf("ExplainEnvOrKanukaFiles failed: %v", err)
+	}
+	patterns := make(map[string]string)
+	for _, m := range matches {
+		if m.IgnorePattern != "" {
+			patterns[relativeFiles(t, root, []string{m.Path})[0]] = m.IgnorePattern
+		}
+	}
+	wantPatterns := map[string]string{
+		".env.example":      "*.example",
+		"api/.env.example":  "*.example",
+		"config/.env.local": "config/*.local",
+		"fixtures":          "fixtures/",
+	}
+	for path, want := range wantPatterns {
+		if patterns[path] != want {
+			t.Errorf("Expected %s to be ignored by %q, got %q", path, want, patterns[path])
+		}
+	}
+}
+
+func TestLoadIgnoreRules_MissingFile(t *testing.T) {
+	root := t.TempDir()
+	writeIgnoreTestFiles(t, root, ".env", ".env.example")
+
+	rules, err := LoadIgnoreRules(root)
+	if err != nil {
+		t.Fatalf("LoadIgnoreRules failed: %v", err)
+	}
+	if rules != nil {
+		t.Errorf("Expected nil rules without an ignore file, got %+v", rules)
+	}
+
+	envFiles, err := FindEnvOrKanukaFiles(root, []string{}, rules, false)
+	if err != nil {
+		t.Fatalf("FindEnvOrKanukaFiles failed: %v", err)
+	}
+	want := []string{".env", ".env.example"}
+	if got := relativeFiles(t, root, envFiles); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestIgnoreRules_Match(t *testing.T) {
+	rules := &IgnoreRules{}
+	for _, line := range []string{"*.example", "!keep/.env.example", "/.env.ci", "**/testdata/*"} {
+		pattern, ok, err := parseIgnorePattern(line)
+		if err != nil || !ok {
+			t.Fatalf("parseIgnorePattern(%q) = %v, %v", line, ok, err)
+		}
+		rules.patterns = append(rules.patterns, pattern)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{".env.example", true},
+		{"deep/nested/.env.example", true},
+		{"keep/.env.example", false},
+		{".env.ci", true},
+		{"api/.env.ci", false},
+		{"a/b/testdata/.env", true},
+		{".env", false},
+	}
+	for _, tt := range tests {
+		if _, got := rules.Match(tt.path, false); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	var nilRules *IgnoreRules
+	if _, got := nilRules.Match(".env", false); got {
+		t.Error("Expected nil rules to match nothing")
+	}
+
+	if _, _, err := parseIgnorePattern("[unclosed"); err == nil {
+		t.Error("Expected an invalid pattern to fail to parse")
+	}
+}
@@ -1,6 +1,7 @@
 package secrets
 
 import (
+	"crypto"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -12,10 +13,12 @@ import (
 	"math/big"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"time"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/internal/ui"
 	"github.com/PolarWolf314/kanuka/internal/utils"
 
@@ -26,10 +29,10 @@ import (
 // but no passphrase was provided.
 var ErrPassphraseRequired = errors.New("private key is passphrase-protected")
 
-// parseOpenSSHPrivateKey parses an OpenSSH format private key and returns an RSA private key.
+// parseOpenSSHPrivateKey parses an OpenSSH format private key and returns an RSA or Ed25519 private key.
 // If the key is passphrase-protected and no passphrase is provided, it returns ErrPassphraseRequired.
-// Only RSA keys are supported; other key types (Ed25519, ECDSA) will return an error.
-func parseOpenSSHPrivateKey(data []byte, passphrase []byte) (*rsa.PrivateKey, error) {
+// Other key types (such as ECDSA) return an error.
+func parseOpenSSHPrivateKey(data []byte, passphrase []byte) (PrivateKey, error) {
 	var (
 		rawKey interface{}
 		err    error
@@ -54,19 +57,18 @@ func parseOpenSSHPrivateKey(data []byte, passphrase []byte) (*rsa.PrivateKey, er
 		return nil, fmt.Errorf("failed to parse OpenSSH private key: %w", err)
 	}
 
-	// Check if the key is an RSA key
-	rsaKey, ok := rawKey.(*rsa.PrivateKey)
-	if !ok {
-		return nil, fmt.Errorf("unsupported key type: only RSA keys are supported, got %T", rawKey)
-	}
-
-	return rsaKey, nil
+	return normalizePrivateKey(rawKey)
 }
 
-// LoadPrivateKey loads an RSA private key from disk.
+// LoadPrivateKey loads an RSA or Ed25519 private key from disk.
 // If the key is passphrase-protected, prompts the user for the passphrase.
 // Supports PEM (PKCS#1, PKCS#8) and OpenSSH formats.
-func LoadPrivateKey(path string) (*rsa.PrivateKey, error) {
+// Returns ErrInsecureKeyPermissions if strict key permissions are enabled and
+// the file is readable by its group or others.
+func LoadPrivateKey(path string) (PrivateKey, error) {
+	if err := CheckPrivateKeyPermissions(path, configs.StrictKeyPermissionsEnabled()); err != nil {
+		return nil, err
+	}
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -74,10 +76,29 @@ func LoadPrivateKey(path string) (*rsa.PrivateKey, error) {
 	return LoadPrivateKeyFromBytesWithPrompt(data)
 }
 
+// CheckPrivateKeyPermissions returns ErrInsecureKeyPermissions if strict is
+// true and the private key at path has any group or world permission bits.
+// It does nothing on Windows, where Unix permission bits aren't meaningful,
+// or if the file can't be stat'd, leaving that error to the caller's read.
+func CheckPrivateKeyPermissions(path string, strict bool) error {
+	if !strict || runtime.GOOS == "windows" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	if mode := info.Mode().Perm(); mode&0077 != 0 {
+		return fmt.Errorf("%w: %s has mode %04o, run 'chmod 600 %s' to fix it",
+			kerrors.ErrInsecureKeyPermissions, path, mode, path)
+	}
+	return nil
+}
+
 // LoadPrivateKeyFromBytesWithPrompt parses a private key from bytes, prompting for passphrase if needed.
 // If the key is passphrase-protected and stdin is a terminal, prompts up to 3 times for the passphrase.
 // Returns an error if the key is encrypted but stdin is not a terminal.
-func LoadPrivateKeyFromBytesWithPrompt(data []byte) (*rsa.PrivateKey, error) {
+func LoadPrivateKeyFromBytesWithPrompt(data []byte) (PrivateKey, error) {
 	// First attempt without passphrase
 	key, err := ParsePrivateKeyBytes(data)
 	if err == nil {
@@ -126,7 +147,7 @@ func LoadPrivateKeyFromBytesWithPrompt(data []byte) (*rsa.PrivateKey, error) {
 // This is used when stdin contains the private key data (e.g., piped from a secret manager),
 // so passphrase prompting must happen via /dev/tty instead of stdin.
 // Returns an error if the key is encrypted but TTY is not available.
-func LoadPrivateKeyFromBytesWithTTYPrompt(data []byte) (*rsa.PrivateKey, error) {
+func LoadPrivateKeyFromBytesWithTTYPrompt(data []byte) (PrivateKey, error) {
 	// First attempt without passphrase
 	key, err := ParsePrivateKeyBytes(data)
 	if err == nil {
@@ -171,17 +192,17 @@ func LoadPrivateKeyFromBytesWithTTYPrompt(data []byte) (*rsa.PrivateKey, error)
 	return nil, fmt.Errorf("failed to decrypt private key after %d attempts", maxAttempts)
 }
 
-// ParsePrivateKeyBytes parses an RSA private key from bytes.
+// ParsePrivateKeyBytes parses an RSA or Ed25519 private key from bytes.
 // Supports PEM (PKCS#1, PKCS#8) and OpenSSH formats.
 // For passphrase-protected keys, use ParsePrivateKeyBytesWithPassphrase.
-func ParsePrivateKeyBytes(data []byte) (*rsa.PrivateKey, error) {
+func ParsePrivateKeyBytes(data []byte) (PrivateKey, error) {
 	return ParsePrivateKeyBytesWithPassphrase(data, nil)
 }
 
-// ParsePrivateKeyBytesWithPassphrase parses an RSA private key from bytes with an optional passphrase.
+// ParsePrivateKeyBytesWithPassphrase parses an RSA or Ed25519 private key from bytes with an optional passphrase.
 // Supports PEM (PKCS#1, PKCS#8) and OpenSSH formats.
 // If passphrase is nil and the key is encrypted, returns ErrPassphraseRequired.
-func ParsePrivateKeyBytesWithPassphrase(data []byte, passphrase []byte) (*rsa.PrivateKey, error) {
+func ParsePrivateKeyBytesWithPassphrase(data []byte, passphrase []byte) (PrivateKey, error) {
 	// Try to decode as PEM
 	block, _ := pem.Decode(data)
 	if block == nil {
@@ -211,11 +232,7 @@ func ParsePrivateKeyBytesWithPassphrase(data []byte, passphrase []byte) (*rsa.Pr
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse PKCS#8 private key: %w", err)
 		}
-		rsaKey, ok := key.(*rsa.PrivateKey)
-		if !ok {
-			return nil, fmt.Errorf("PKCS#8 key is not an RSA key, got %T", key)
-		}
-		return rsaKey, nil
+		return normalizePrivateKey(key)
 
 	case "ENCRYPTED PRIVATE KEY":
 		// PKCS#8 encrypted format - not commonly used, return helpful error
@@ -230,9 +247,27 @@ func ParsePrivateKeyBytesWithPassphrase(data []byte, passphrase []byte) (*rsa.Pr
 	}
 }
 
+// MarshalPrivateKeyOpenSSH encodes an RSA or Ed25519 private key in OpenSSH
+// format, encrypted with passphrase unless it is empty.
+func MarshalPrivateKeyOpenSSH(privateKey PrivateKey, passphrase []byte) ([]byte, error) {
+	var (
+		block *pem.Block
+		err   error
+	)
+	if len(passphrase) > 0 {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(privateKey, "", passphrase)
+	} else {
+		block, err = ssh.MarshalPrivateKey(privateKey, "")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal private key: %w", err)
+	}
+	return pem.EncodeToMemory(block), nil
+}
+
 // ParsePrivateKeyText parses a PEM-encoded or OpenSSH format private key string
-// and returns an RSA private key.
-func ParsePrivateKeyText(privateKeyText string) (*rsa.PrivateKey, error) {
+// and returns an RSA or Ed25519 private key.
+func ParsePrivateKeyText(privateKeyText string) (PrivateKey, error) {
 	// Ensure the text is trimmed of whitespace
 	privateKeyText = strings.TrimSpace(privateKeyText)
 
@@ -248,8 +283,8 @@ func ParsePrivateKeyText(privateKeyText string) (*rsa.PrivateKey, error) {
 	return ParsePrivateKeyBytes([]byte(privateKeyText))
 }
 
-// LoadPublicKey loads the user's public key from the project directory.
-func LoadPublicKey(path string) (*rsa.PublicKey, error) {
+// LoadPublicKey loads the user's RSA or Ed25519 public key from the project directory.
+func LoadPublicKey(path string) (crypto.PublicKey, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -262,18 +297,30 @@ func LoadPublicKey(path string) (*rsa.PublicKey, error) {
 	if err != nil {
 		return nil, err
 	}
-	rsaPub, ok := pub.(*rsa.PublicKey)
-	if !ok {
-		return nil, fmt.Errorf("not an RSA public key")
-	}
-	return rsaPub, nil
+	return normalizePublicKey(pub)
 }
 
 // GenerateRSAKeyPair creates a new RSA key pair and saves them to disk.
 func GenerateRSAKeyPair(privatePath string, publicPath string) error {
-	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	return GenerateKeyPair(privatePath, publicPath, KeyAlgorithmRSA2048)
+}
+
+// GenerateKeyPair creates a new key pair with the given algorithm and saves
+// them to disk. RSA private keys are written as PKCS#1 and Ed25519 private
+// keys as PKCS#8; public keys are always written as PKIX.
+func GenerateKeyPair(privatePath string, publicPath string, algorithm KeyAlgorithm) error {
+	privateKey, err := GeneratePrivateKey(algorithm)
+	if err != nil {
+		return fmt.Errorf("failed to generate %s key pair: %w", algorithm, err)
+	}
+
+	privPem, err := encodePrivateKeyPEM(privateKey)
 	if err != nil {
-		return fmt.Errorf("failed to generate RSA key pair: %w", err)
+		return err
+	}
+	pubPem, err := GetPublicKeyPEM(privateKey)
+	if err != nil {
+		return err
 	}
 
 	// Create directories if they don't exist
@@ -286,50 +333,34 @@ func GenerateRSAKeyPair(privatePath string, publicPath string) error {
 		return fmt.Errorf("failed to create directory for public key at %s: %w", publicDir, err)
 	}
 
-	// Save private key
-	privFile, err := os.OpenFile(privatePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
-	if err != nil {
-		return fmt.Errorf("failed to create private key file at %s: %w", privatePath, err)
-	}
-	defer func() {
-		if closeErr := privFile.Close(); closeErr != nil && err == nil {
-			err = fmt.Errorf("failed to close private key file: %w", closeErr)
-		}
-	}()
-
-	privBytes := x509.MarshalPKCS1PrivateKey(privateKey)
-	privPem := &pem.Block{
-		Type:  "RSA PRIVATE KEY",
-		Bytes: privBytes,
+	if err := os.WriteFile(privatePath, privPem, 0600); err != nil {
+		return fmt.Errorf("failed to write private key file at %s: %w", privatePath, err)
 	}
-	if err := pem.Encode(privFile, privPem); err != nil {
-		return fmt.Errorf("failed to PEM encode private key: %w", err)
+	if err := os.WriteFile(publicPath, pubPem, 0644); err != nil {
+		return fmt.Errorf("failed to write public key file at %s: %w", publicPath, err)
 	}
 
-	// Save public key
-	pubFile, err := os.OpenFile(publicPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to create public key file at %s: %w", publicPath, err)
+	return nil
+}
+
+// encodePrivateKeyPEM returns privateKey PEM encoded: PKCS#1 for RSA keys,
+// which older releases expect, and PKCS#8 for Ed25519 keys.
+func encodePrivateKeyPEM(privateKey PrivateKey) ([]byte, error) {
+	if rsaKey, ok := privateKey.(*rsa.PrivateKey); ok {
+		return pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(rsaKey),
+		}), nil
 	}
-	defer func() {
-		if closeErr := pubFile.Close(); closeErr != nil && err == nil {
-			err = fmt.Errorf("failed to close public key file: %w", closeErr)
-		}
-	}()
 
-	pubASN1, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	privBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
 	if err != nil {
-		return fmt.Errorf("failed to marshal public key: %w", err)
-	}
-	pubPem := &pem.Block{
-		Type:  "PUBLIC KEY",
-		Bytes: pubASN1,
-	}
-	if err := pem.Encode(pubFile, pubPem); err != nil {
-		return fmt.Errorf("failed to PEM encode public key: %w", err)
+		return nil, fmt.Errorf("failed to marshal private key: %w", err)
 	}
-
-	return nil
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: privBytes,
+	}), nil
 }
 
 // GenerateRSAKeyPairInMemory generates a new RSA key pair and returns them without saving to disk.
@@ -351,9 +382,9 @@ func GenerateRSAKeyPairInMemory() (*rsa.PrivateKey, []byte, error) {
 	return privateKey, privateKeyPEM, nil
 }
 
-// GetPublicKeyPEM returns the PEM-encoded public key from an RSA private key.
-func GetPublicKeyPEM(privateKey *rsa.PrivateKey) ([]byte, error) {
-	pubASN1, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+// GetPublicKeyPEM returns the PEM-encoded public key from an RSA or Ed25519 private key.
+func GetPublicKeyPEM(privateKey PrivateKey) ([]byte, error) {
+	pubASN1, err := x509.MarshalPKIXPublicKey(privateKey.Public())
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal public key: %w", err)
 	}
@@ -368,6 +399,12 @@ func GetPublicKeyPEM(privateKey *rsa.PrivateKey) ([]byte, error) {
 // It uses the project UUID from the project config to create a subdirectory for the key files.
 // The new structure is: ~/.local/share/kanuka/keys/{project_uuid}/privkey, pubkey.pub, metadata.toml.
 func CreateAndSaveRSAKeyPair(verbose bool) error {
+	return CreateAndSaveKeyPair(verbose, DefaultKeyAlgorithm)
+}
+
+// CreateAndSaveKeyPair is like CreateAndSaveRSAKeyPair, but generates a key
+// pair with the given algorithm.
+func CreateAndSaveKeyPair(verbose bool, algorithm KeyAlgorithm) error {
 	if err := configs.InitProjectSettings(); err != nil {
 		return fmt.Errorf("failed to init project settings: %w", err)
 	}
@@ -393,8 +430,8 @@ func CreateAndSaveRSAKeyPair(verbose bool) error {
 	privateKeyPath := configs.GetPrivateKeyPath(projectUUID)
 	publicKeyPath := configs.GetPublicKeyPath(projectUUID)
 
-	if err := GenerateRSAKeyPair(privateKeyPath, publicKeyPath); err != nil {
-		return fmt.Errorf("failed to generate or save RSA key pair for project %s: %w", projectUUID, err)
+	if err := GenerateKeyPair(privateKeyPath, publicKeyPath, algorithm); err != nil {
+		return fmt.Errorf("failed to generate or save key pair for project %s: %w", projectUUID, err)
 	}
 
 	// Create metadata.toml with project information
@@ -519,8 +556,32 @@ func GetProjectKanukaKey(userUUID string) ([]byte, error) {
 	return encryptedSymmetricKey, nil
 }
 
-// ParsePublicKeyText parses a PEM-encoded or SSH format public key string and returns an RSA public key.
-func ParsePublicKeyText(publicKeyText string) (*rsa.PublicKey, error) {
+// ProjectHasWrappedKeys reports whether any user has an encrypted symmetric
+// key in the project. A project created with init --bare has none until its
+// first user registers.
+func ProjectHasWrappedKeys() (bool, error) {
+	if err := configs.InitProjectSettings(); err != nil {
+		return false, fmt.Errorf("failed to init project settings: %w", err)
+	}
+
+	entries, err := os.ReadDir(configs.ProjectKanukaSettings.ProjectSecretsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read secrets directory: %w", err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".kanuka" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ParsePublicKeyText parses a PEM-encoded or SSH format public key string and
+// returns an RSA or Ed25519 public key.
+func ParsePublicKeyText(publicKeyText string) (crypto.PublicKey, error) {
 	// Ensure the text is trimmed of whitespace
 	publicKeyText = strings.TrimSpace(publicKeyText)
 
@@ -529,6 +590,11 @@ func ParsePublicKeyText(publicKeyText string) (*rsa.PublicKey, error) {
 		return parseSSHPublicKey(publicKeyText)
 	}
 
+	// Ed25519 SSH keys (ssh-ed25519) are parsed by the ssh package.
+	if strings.HasPrefix(publicKeyText, ssh.KeyAlgoED25519) {
+		return parseSSHEd25519PublicKey(publicKeyText)
+	}
+
 	// If not SSH format, try PEM format
 	if !strings.HasPrefix(publicKeyText, "-----BEGIN") {
 		return nil, errors.New("public key text does not appear to be in PEM or SSH format")
@@ -561,13 +627,22 @@ func ParsePublicKeyText(publicKeyText string) (*rsa.PublicKey, error) {
 		return nil, err
 	}
 
-	// Convert to RSA public key
-	rsaPublicKey, ok := publicKey.(*rsa.PublicKey)
-	if !ok {
-		return nil, errors.New("not an RSA public key")
+	return normalizePublicKey(publicKey)
+}
+
+// parseSSHEd25519PublicKey parses an SSH format Ed25519 public key.
+// Format: ssh-ed25519 BASE64DATA comment.
+func parseSSHEd25519PublicKey(sshPublicKey string) (crypto.PublicKey, error) {
+	parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(sshPublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH key: %w", err)
 	}
 
-	return rsaPublicKey, nil
+	cryptoKey, ok := parsed.(ssh.CryptoPublicKey)
+	if !ok {
+		return nil, errors.New("invalid SSH public key format")
+	}
+	return normalizePublicKey(cryptoKey.CryptoPublicKey())
 }
 
 // parseSSHPublicKey parses an SSH format RSA public key.
@@ -637,8 +712,8 @@ func parseSSHPublicKey(sshPublicKey string) (*rsa.PublicKey, error) {
 	}, nil
 }
 
-// SavePublicKeyToFile saves an RSA public key to a file in PEM format.
-func SavePublicKeyToFile(publicKey *rsa.PublicKey, filePath string) error {
+// SavePublicKeyToFile saves an RSA or Ed25519 public key to a file in PEM format.
+func SavePublicKeyToFile(publicKey crypto.PublicKey, filePath string) error {
 	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
@@ -661,7 +736,8 @@ func SavePublicKeyToFile(publicKey *rsa.PublicKey, filePath string) error {
 }
 
 // GetAllUsersInProject returns a list of all user UUIDs with access to the project.
-// Files in the public_keys directory are named with user UUIDs.
+// Files in the public_keys directory are named with user UUIDs: <uuid>.pub for
+// RSA and Ed25519 keys, and <uuid>.gpg for users registered with a GPG key.
 func GetAllUsersInProject() ([]string, error) {
 	if err := configs.InitProjectSettings(); err != nil {
 		return nil, fmt.Errorf("failed to init project settings: %w", err)
@@ -675,10 +751,16 @@ func GetAllUsersInProject() ([]string, error) {
 	}
 
 	var userUUIDs []string
+	seen := make(map[string]bool)
 	for _, entry := range entries {
-		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".pub" {
-			// Extract UUID from filename (e.g., "uuid.pub" -> "uuid")
-			userUUID := entry.Name()[:len(entry.Name())-len(".pub")]
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".pub" && ext != ".gpg") {
+			continue
+		}
+		// Extract UUID from filename (e.g., "uuid.pub" -> "uuid")
+		userUUID := strings.TrimSuffix(entry.Name(), ext)
+		if !seen[userUUID] {
+			seen[userUUID] = true
 			userUUIDs = append(userUUIDs, userUUID)
 		}
 	}
@@ -0,0 +1,239 @@
+package secrets
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// PrivateKey is a user's private key: an *rsa.PrivateKey or an ed25519.PrivateKey.
+type PrivateKey interface {
+	Public() crypto.PublicKey
+}
+
+// KeyType identifies the algorithm of a user's key pair.
+type KeyType string
+
+const (
+	// KeyTypeRSA is an RSA key pair. Symmetric keys are wrapped with RSA PKCS#1 v1.5.
+	KeyTypeRSA KeyType = "rsa"
+	// KeyTypeEd25519 is an Ed25519 key pair. Symmetric keys are wrapped in a NaCl
+	// sealed box addressed to the X25519 form of the key.
+	KeyTypeEd25519 KeyType = "ed25519"
+)
+
+// wrapSchemeX25519 is the header byte of symmetric keys wrapped for an Ed25519
+// key. RSA-wrapped keys have no header so existing projects stay readable.
+const wrapSchemeX25519 byte = 0x01
+
+// x25519WrappedKeySize is the size of a 32-byte symmetric key wrapped for an
+// Ed25519 key: header byte, ephemeral public key, and box overhead.
+const x25519WrappedKeySize = 1 + box.AnonymousOverhead + 32
+
+// KeyAlgorithm selects the algorithm and size of a newly generated key pair.
+type KeyAlgorithm string
+
+const (
+	// KeyAlgorithmRSA2048 is a 2048-bit RSA key pair: fast to generate, but
+	// weaker than RSA 4096.
+	KeyAlgorithmRSA2048 KeyAlgorithm = "rsa2048"
+	// KeyAlgorithmRSA4096 is a 4096-bit RSA key pair. It can take a second
+	// or more to generate on slow machines.
+	KeyAlgorithmRSA4096 KeyAlgorithm = "rsa4096"
+	// KeyAlgorithmEd25519 is an Ed25519 key pair, which is small and fast to
+	// generate.
+	KeyAlgorithmEd25519 KeyAlgorithm = "ed25519"
+)
+
+// DefaultKeyAlgorithm is used when no key algorithm is chosen.
+const DefaultKeyAlgorithm = KeyAlgorithmRSA2048
+
+// KeyAlgorithms lists the algorithms accepted by ParseKeyAlgorithm.
+var KeyAlgorithms = []KeyAlgorithm{KeyAlgorithmRSA2048, KeyAlgorithmRSA4096, KeyAlgorithmEd25519}
+
+// ParseKeyAlgorithm returns the KeyAlgorithm named by s, or
+// DefaultKeyAlgorithm if s is empty. Unknown names return an error wrapping
+// ErrUnsupportedKeyType that lists the supported algorithms.
+func ParseKeyAlgorithm(s string) (KeyAlgorithm, error) {
+	if s == "" {
+		return DefaultKeyAlgorithm, nil
+	}
+	names := make([]string, len(KeyAlgorithms))
+	for i, algorithm := range KeyAlgorithms {
+		if string(algorithm) == s {
+			return algorithm, nil
+		}
+		names[i] = string(algorithm)
+	}
+	return "", fmt.Errorf("%w: %q, expected one of %s", ErrUnsupportedKeyType, s, strings.Join(names, ", "))
+}
+
+// GeneratePrivateKey generates a new private key with the given algorithm.
+func GeneratePrivateKey(algorithm KeyAlgorithm) (PrivateKey, error) {
+	switch algorithm {
+	case KeyAlgorithmRSA2048:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case KeyAlgorithmRSA4096:
+		return rsa.GenerateKey(rand.Reader, 4096)
+	case KeyAlgorithmEd25519:
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		return privateKey, err
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedKeyType, algorithm)
+	}
+}
+
+// ErrUnsupportedKeyType is returned for keys that are neither RSA nor Ed25519.
+var ErrUnsupportedKeyType = errors.New("unsupported key type: only RSA and Ed25519 keys are supported")
+
+// PublicKeyType returns the key type of a public key.
+func PublicKeyType(publicKey crypto.PublicKey) (KeyType, error) {
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		return KeyTypeRSA, nil
+	case ed25519.PublicKey:
+		return KeyTypeEd25519, nil
+	default:
+		return "", fmt.Errorf("%w, got %T", ErrUnsupportedKeyType, publicKey)
+	}
+}
+
+// normalizePrivateKey converts parsed private keys to the forms PrivateKey
+// documents, rejecting unsupported key types.
+func normalizePrivateKey(key interface{}) (PrivateKey, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return k, nil
+	case ed25519.PrivateKey:
+		return k, nil
+	case *ed25519.PrivateKey:
+		// golang.org/x/crypto/ssh returns Ed25519 keys by pointer.
+		return *k, nil
+	default:
+		return nil, fmt.Errorf("%w, got %T", ErrUnsupportedKeyType, key)
+	}
+}
+
+// normalizePublicKey converts parsed public keys to *rsa.PublicKey or
+// ed25519.PublicKey, rejecting unsupported key types.
+func normalizePublicKey(key interface{}) (crypto.PublicKey, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		return k, nil
+	case ed25519.PublicKey:
+		return k, nil
+	case *ed25519.PublicKey:
+		return *k, nil
+	default:
+		return nil, fmt.Errorf("%w, got %T", ErrUnsupportedKeyType, key)
+	}
+}
+
+// WrappedKeyMatches reports whether wrapped has the shape of a symmetric key
+// wrapped for publicKey. It cannot prove the wrapped key decrypts, since that
+// needs the private key, but it catches keys wrapped for the wrong key type.
+func WrappedKeyMatches(wrapped []byte, publicKey crypto.PublicKey) bool {
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		return len(wrapped) == k.Size()
+	case ed25519.PublicKey:
+		return len(wrapped) == x25519WrappedKeySize && wrapped[0] == wrapSchemeX25519
+	default:
+		return false
+	}
+}
+
+// isX25519Wrapped reports whether a wrapped symmetric key uses the X25519 scheme.
+func isX25519Wrapped(wrapped []byte) bool {
+	return len(wrapped) == x25519WrappedKeySize && wrapped[0] == wrapSchemeX25519
+}
+
+// sealForEd25519 wraps plaintext in a sealed box for the X25519 form of publicKey.
+func sealForEd25519(plaintext []byte, publicKey ed25519.PublicKey) ([]byte, error) {
+	recipient, err := ed25519PublicKeyToX25519(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 1, 1+box.AnonymousOverhead+len(plaintext))
+	out[0] = wrapSchemeX25519
+	return box.SealAnonymous(out, plaintext, recipient, rand.Reader)
+}
+
+// openWithEd25519 opens a sealed box created by sealForEd25519.
+func openWithEd25519(wrapped []byte, privateKey ed25519.PrivateKey) ([]byte, error) {
+	if len(wrapped) < 1+box.AnonymousOverhead || wrapped[0] != wrapSchemeX25519 {
+		return nil, errors.New("symmetric key was not wrapped for an Ed25519 key")
+	}
+
+	publicKey, err := ed25519PublicKeyToX25519(privateKey.Public().(ed25519.PublicKey))
+	if err != nil {
+		return nil, err
+	}
+	secretKey := ed25519PrivateKeyToX25519(privateKey)
+
+	plaintext, ok := box.OpenAnonymous(nil, wrapped[1:], publicKey, secretKey)
+	if !ok {
+		return nil, errors.New("failed to open sealed box with Ed25519 key")
+	}
+	return plaintext, nil
+}
+
+// ed25519PrivateKeyToX25519 derives the X25519 scalar of an Ed25519 private
+// key, as in RFC 8032 section 5.1.5. Clamping is applied by curve25519.
+func ed25519PrivateKeyToX25519(privateKey ed25519.PrivateKey) *[32]byte {
+	h := sha512.Sum512(privateKey.Seed())
+	var out [32]byte
+	copy(out[:], h[:32])
+	return &out
+}
+
+// curve25519P is the field prime 2^255 - 19.
+var curve25519P = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+
+// ed25519PublicKeyToX25519 maps an Ed25519 public key to its X25519 form with
+// the birational map u = (1 + y) / (1 - y) from RFC 7748.
+func ed25519PublicKeyToX25519(publicKey ed25519.PublicKey) (*[32]byte, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid Ed25519 public key length: %d", len(publicKey))
+	}
+
+	// The encoding is y in little-endian with the sign of x in the top bit.
+	le := make([]byte, 32)
+	copy(le, publicKey)
+	le[31] &= 0x7f
+	y := new(big.Int).SetBytes(reverseBytes(le))
+
+	one := big.NewInt(1)
+	num := new(big.Int).Add(one, y)
+	den := new(big.Int).Sub(one, y)
+	den.Mod(den, curve25519P)
+	if den.Sign() == 0 {
+		return nil, errors.New("invalid Ed25519 public key: point has no X25519 form")
+	}
+	den.ModInverse(den, curve25519P)
+	u := num.Mul(num, den)
+	u.Mod(u, curve25519P)
+
+	var out [32]byte
+	u.FillBytes(out[:])
+	copy(out[:], reverseBytes(out[:]))
+	return &out, nil
+}
+
+// reverseBytes returns a reversed copy of b.
+func reverseBytes(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[len(b)-1-i] = b[i]
+	}
+	return out
+}
@@ -0,0 +1,317 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestEd25519ToX25519_MatchesScalarBaseMult(t *testing.T) {
+	for i := 0; i < 16; i++ {
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatalf("failed to generate Ed25519 key: %v", err)
+		}
+
+		converted, err := ed25519PublicKeyToX25519(publicKey)
+		if err != nil {
+			t.Fatalf("failed to convert public key: %v", err)
+		}
+
+		scalar := ed25519PrivateKeyToX25519(privateKey)
+		derived, err := curve25519.X25519(scalar[:], curve25519.Basepoint)
+		if err != nil {
+			t.Fatalf("failed to derive X25519 public key: %v", err)
+		}
+
+		if !bytes.Equal(converted[:], derived) {
+			t.Fatalf("converted public key does not match key derived from private scalar")
+		}
+	}
+}
+
+func TestWrapSymmetricKey_Ed25519RoundTrip(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+	symKey, err := CreateSymmetricKey()
+	if err != nil {
+		t.Fatalf("failed to create symmetric key: %v", err)
+	}
+
+	wrapped, err := EncryptWithPublicKey(symKey, publicKey)
+	if err != nil {
+		t.Fatalf("EncryptWithPublicKey failed: %v", err)
+	}
+	if wrapped[0] != wrapSchemeX25519 {
+		t.Errorf("expected header byte %#x, got %#x", wrapSchemeX25519, wrapped[0])
+	}
+	if !WrappedKeyMatches(wrapped, publicKey) {
+		t.Error("wrapped key should match its Ed25519 public key")
+	}
+
+	unwrapped, err := DecryptWithPrivateKey(wrapped, privateKey)
+	if err != nil {
+		t.Fatalf("DecryptWithPrivateKey failed: %v", err)
+	}
+	if !bytes.Equal(unwrapped, symKey) {
+		t.Error("unwrapped key does not match original")
+	}
+
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+	if _, err := DecryptWithPrivateKey(wrapped, otherKey); err == nil {
+		t.Error("expected error when unwrapping with a different Ed25519 key")
+	}
+}
+
+func TestWrapSymmetricKey_RSAUnchanged(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	symKey, err := CreateSymmetricKey()
+	if err != nil {
+		t.Fatalf("failed to create symmetric key: %v", err)
+	}
+
+	wrapped, err := EncryptWithPublicKey(symKey, &privateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("EncryptWithPublicKey failed: %v", err)
+	}
+
+	// RSA-wrapped keys stay headerless PKCS#1 v1.5 so older versions can read them.
+	legacy, err := rsa.DecryptPKCS1v15(rand.Reader, privateKey, wrapped)
+	if err != nil {
+		t.Fatalf("RSA-wrapped key is not plain PKCS#1 v1.5: %v", err)
+	}
+	if !bytes.Equal(legacy, symKey) {
+		t.Error("unwrapped key does not match original")
+	}
+	if !WrappedKeyMatches(wrapped, &privateKey.PublicKey) {
+		t.Error("wrapped key should match its RSA public key")
+	}
+}
+
+func TestWrapSymmetricKey_MismatchedKeyTypes(t *testing.T) {
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	symKey := make([]byte, 32)
+
+	edWrapped, err := EncryptWithPublicKey(symKey, edPublic)
+	if err != nil {
+		t.Fatalf("EncryptWithPublicKey failed: %v", err)
+	}
+	if _, err := DecryptWithPrivateKey(edWrapped, rsaKey); err == nil {
+		t.Error("expected error unwrapping an Ed25519-wrapped key with an RSA key")
+	}
+	if WrappedKeyMatches(edWrapped, &rsaKey.PublicKey) {
+		t.Error("Ed25519-wrapped key should not match an RSA public key")
+	}
+
+	rsaWrapped, err := EncryptWithPublicKey(symKey, &rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("EncryptWithPublicKey failed: %v", err)
+	}
+	if _, err := DecryptWithPrivateKey(rsaWrapped, edPrivate); err == nil {
+		t.Error("expected error unwrapping an RSA-wrapped key with an Ed25519 key")
+	}
+	if WrappedKeyMatches(rsaWrapped, edPublic) {
+		t.Error("RSA-wrapped key should not match an Ed25519 public key")
+	}
+}
+
+func TestParsePublicKeyText_Ed25519(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+
+	sshKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		t.Fatalf("failed to create SSH public key: %v", err)
+	}
+	authorized := string(ssh.MarshalAuthorizedKey(sshKey))
+
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	pemText := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	for name, text := range map[string]string{"OpenSSH": authorized, "PEM": pemText} {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := ParsePublicKeyText(text)
+			if err != nil {
+				t.Fatalf("ParsePublicKeyText failed: %v", err)
+			}
+			keyType, err := PublicKeyType(parsed)
+			if err != nil {
+				t.Fatalf("PublicKeyType failed: %v", err)
+			}
+			if keyType != KeyTypeEd25519 {
+				t.Errorf("expected key type %q, got %q", KeyTypeEd25519, keyType)
+			}
+			if !publicKey.Equal(parsed) {
+				t.Error("parsed key does not match original")
+			}
+		})
+	}
+}
+
+func TestLoadKeys_Ed25519(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+	dir := t.TempDir()
+
+	pubPath := filepath.Join(dir, "key.pub")
+	if err := SavePublicKeyToFile(publicKey, pubPath); err != nil {
+		t.Fatalf("SavePublicKeyToFile failed: %v", err)
+	}
+	loadedPublic, err := LoadPublicKey(pubPath)
+	if err != nil {
+		t.Fatalf("LoadPublicKey failed: %v", err)
+	}
+	if !publicKey.Equal(loadedPublic) {
+		t.Error("loaded public key does not match original")
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("failed to marshal private key: %v", err)
+	}
+	pkcs8 := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	openSSHBlock, err := ssh.MarshalPrivateKey(privateKey, "")
+	if err != nil {
+		t.Fatalf("failed to marshal OpenSSH private key: %v", err)
+	}
+	openSSH := pem.EncodeToMemory(openSSHBlock)
+
+	for name, data := range map[string][]byte{"PKCS8": pkcs8, "OpenSSH": openSSH} {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := ParsePrivateKeyBytes(data)
+			if err != nil {
+				t.Fatalf("ParsePrivateKeyBytes failed: %v", err)
+			}
+			edKey, ok := parsed.(ed25519.PrivateKey)
+			if !ok {
+				t.Fatalf("expected ed25519.PrivateKey, got %T", parsed)
+			}
+			if !privateKey.Equal(edKey) {
+				t.Error("parsed private key does not match original")
+			}
+		})
+	}
+}
+
+func TestPublicKeyType_Unsupported(t *testing.T) {
+	_, err := PublicKeyType("not a key")
+	if !errors.Is(err, ErrUnsupportedKeyType) {
+		t.Errorf("expected ErrUnsupportedKeyType, got %v", err)
+	}
+}
+
+func TestParseKeyAlgorithm(t *testing.T) {
+	tests := []struct {
+		input string
+		want  KeyAlgorithm
+	}{
+		{"", DefaultKeyAlgorithm},
+		{"rsa2048", KeyAlgorithmRSA2048},
+		{"rsa4096", KeyAlgorithmRSA4096},
+		{"ed25519", KeyAlgorithmEd25519},
+	}
+	for _, tt := range tests {
+		got, err := ParseKeyAlgorithm(tt.input)
+		if err != nil {
+			t.Errorf("ParseKeyAlgorithm(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseKeyAlgorithm(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	if _, err := ParseKeyAlgorithm("dsa"); !errors.Is(err, ErrUnsupportedKeyType) {
+		t.Errorf("expected ErrUnsupportedKeyType for an unknown algorithm, got %v", err)
+	}
+}
+
+func TestGenerateKeyPair_RoundTrip(t *testing.T) {
+	tests := []struct {
+		algorithm KeyAlgorithm
+		keyType   KeyType
+		rsaBits   int
+	}{
+		{KeyAlgorithmRSA2048, KeyTypeRSA, 2048},
+		{KeyAlgorithmRSA4096, KeyTypeRSA, 4096},
+		{KeyAlgorithmEd25519, KeyTypeEd25519, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.algorithm), func(t *testing.T) {
+			dir := t.TempDir()
+			privatePath := filepath.Join(dir, "privkey")
+			publicPath := filepath.Join(dir, "pubkey.pub")
+			if err := GenerateKeyPair(privatePath, publicPath, tt.algorithm); err != nil {
+				t.Fatalf("GenerateKeyPair failed: %v", err)
+			}
+
+			privateKey, err := LoadPrivateKey(privatePath)
+			if err != nil {
+				t.Fatalf("LoadPrivateKey failed: %v", err)
+			}
+			publicKey, err := LoadPublicKey(publicPath)
+			if err != nil {
+				t.Fatalf("LoadPublicKey failed: %v", err)
+			}
+
+			keyType, err := PublicKeyType(publicKey)
+			if err != nil || keyType != tt.keyType {
+				t.Fatalf("expected a %s public key, got %q (%v)", tt.keyType, keyType, err)
+			}
+			if tt.rsaBits > 0 {
+				if bits := publicKey.(*rsa.PublicKey).N.BitLen(); bits != tt.rsaBits {
+					t.Errorf("expected a %d-bit RSA key, got %d bits", tt.rsaBits, bits)
+				}
+			}
+
+			symKey, err := CreateSymmetricKey()
+			if err != nil {
+				t.Fatalf("failed to create symmetric key: %v", err)
+			}
+			wrapped, err := EncryptWithPublicKey(symKey, publicKey)
+			if err != nil {
+				t.Fatalf("EncryptWithPublicKey failed: %v", err)
+			}
+			unwrapped, err := DecryptWithPrivateKey(wrapped, privateKey)
+			if err != nil {
+				t.Fatalf("DecryptWithPrivateKey failed: %v", err)
+			}
+			if !bytes.Equal(unwrapped, symKey) {
+				t.Error("unwrapped key does not match original")
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// Framed .kanuka files start with streamMagic and a version byte, followed by
+// the source file's permission bits, a random nonce prefix, and a sequence of
+// sealed chunks:
+//
+//	"KNKS" | version (1) | mode (2) | nonce prefix (16) | chunk | chunk | ...
+//
+// Each chunk seals up to streamChunkSize bytes of plaintext, so encrypting
+// and decrypting only hold one chunk in memory. A chunk's nonce is the prefix
+// followed by the chunk's index and a flag marking the final chunk, so chunks
+// can't be reordered, dropped, or truncated without failing to open. The mode
+// is mixed into the nonce prefix, so it can't be changed either. A mode of
+// zero means the source's permissions are unknown.
+//
+// Version 1 files have no mode field and are read as having an unknown mode.
+//
+// Legacy files are a single secretbox blob with its 24-byte nonce prepended.
+// They are still read, and are rewritten in the framed format on the next
+// encrypt.
+var streamMagic = []byte("KNKS")
+
+const (
+	// streamVersion is the framed format version written by encryptStream.
+	streamVersion byte = 2
+
+	// streamVersionNoMode is the framed format version without a mode field.
+	streamVersionNoMode byte = 1
+
+	// streamModeSize is the size of the mode field in streamVersion headers.
+	streamModeSize = 2
+
+	// streamChunkSize is the plaintext size of every chunk except the last.
+	streamChunkSize = 64 * 1024
+
+	// streamNoncePrefixSize is the size of the random per-file nonce prefix.
+	streamNoncePrefixSize = 16
+
+	// streamHeaderSize is the size of the magic, version, mode, and nonce prefix.
+	streamHeaderSize = 4 + 1 + streamModeSize + streamNoncePrefixSize
+
+	// streamHeaderSizeNoMode is the header size of streamVersionNoMode files.
+	streamHeaderSizeNoMode = 4 + 1 + streamNoncePrefixSize
+)
+
+// errTruncatedStream is returned when a framed file ends before its final chunk.
+var errTruncatedStream = errors.New("encrypted file is truncated")
+
+// encryptStream reads plaintext from src and writes it to dst in the framed
+// format, one chunk at a time. mode records the source's permission bits, or
+// zero if they are unknown.
+func encryptStream(key *[32]byte, dst io.Writer, src io.Reader, mode os.FileMode) error {
+	mode = mode.Perm()
+	header := make([]byte, streamHeaderSize)
+	copy(header, streamMagic)
+	header[len(streamMagic)] = streamVersion
+	binary.BigEndian.PutUint16(header[len(streamMagic)+1:], uint16(mode))
+	if _, err := io.ReadFull(rand.Reader, header[len(streamMagic)+1+streamModeSize:]); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	if _, err := dst.Write(header); err != nil {
+		return err
+	}
+	noncePrefix := bindMode(header[len(streamMagic)+1+streamModeSize:], mode)
+
+	reader := bufio.NewReaderSize(src, streamChunkSize)
+	chunk := make([]byte, streamChunkSize)
+	sealed := make([]byte, 0, streamChunkSize+secretbox.Overhead)
+	for index := uint64(0); ; index++ {
+		n, err := io.ReadFull(reader, chunk)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return err
+		}
+
+		// The chunk is final if the input ran out, or if it filled the chunk
+		// exactly and nothing follows.
+		final := err != nil
+		if !final {
+			if _, peekErr := reader.Peek(1); peekErr == io.EOF {
+				final = true
+			} else if peekErr != nil {
+				return peekErr
+			}
+		}
+
+		nonce := streamNonce(noncePrefix, index, final)
+		sealed = secretbox.Seal(sealed[:0], chunk[:n], &nonce, key)
+		if _, err := dst.Write(sealed); err != nil {
+			return err
+		}
+		if final {
+			return nil
+		}
+	}
+}
+
+// decryptStream reads a .kanuka file from src and writes its plaintext to
+// dst, returning the recorded mode of the source file, or zero if unknown.
+// Framed files are opened one chunk at a time; legacy files are read whole.
+func decryptStream(key *[32]byte, dst io.Writer, src io.Reader) (os.FileMode, error) {
+	reader := bufio.NewReaderSize(src, streamChunkSize+secretbox.Overhead)
+
+	header, err := reader.Peek(streamHeaderSize)
+	if err != nil && err != io.EOF {
+		return 0, err
+	}
+	noncePrefix, mode, headerSize, ok := parseStreamHeader(header)
+	if !ok {
+		ciphertext, err := io.ReadAll(reader)
+		if err != nil {
+			return 0, err
+		}
+		plaintext, err := openLegacy(key, ciphertext)
+		if err != nil {
+			return 0, err
+		}
+		_, err = dst.Write(plaintext)
+		return 0, err
+	}
+
+	noncePrefix = bindMode(noncePrefix, mode)
+	if _, err := reader.Discard(headerSize); err != nil {
+		return 0, err
+	}
+
+	sealed := make([]byte, streamChunkSize+secretbox.Overhead)
+	plaintext := make([]byte, 0, streamChunkSize)
+	for index := uint64(0); ; index++ {
+		n, err := io.ReadFull(reader, sealed)
+		if err == io.EOF {
+			return 0, errTruncatedStream
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return 0, err
+		}
+
+		final := err == io.ErrUnexpectedEOF
+		if !final {
+			if _, peekErr := reader.Peek(1); peekErr == io.EOF {
+				final = true
+			} else if peekErr != nil {
+				return 0, peekErr
+			}
+		}
+
+		nonce := streamNonce(noncePrefix, index, final)
+		var ok bool
+		plaintext, ok = secretbox.Open(plaintext[:0], sealed[:n], &nonce, key)
+		if !ok {
+			return 0, fmt.Errorf("failed to decrypt ciphertext with secretbox")
+		}
+		if _, err := dst.Write(plaintext); err != nil {
+			return 0, err
+		}
+		if final {
+			return mode, nil
+		}
+	}
+}
+
+// sealBytes encrypts plaintext in the framed format, recording mode as the
+// source's permission bits.
+func sealBytes(key *[32]byte, plaintext []byte, mode os.FileMode) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(streamHeaderSize + len(plaintext) + (len(plaintext)/streamChunkSize+1)*secretbox.Overhead)
+	if err := encryptStream(key, &buf, bytes.NewReader(plaintext), mode); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// openBytes decrypts a .kanuka file's contents in either format.
+func openBytes(key *[32]byte, ciphertext []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := decryptStream(key, &buf, bytes.NewReader(ciphertext)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parseStreamHeader splits a framed format header into its nonce prefix and
+// recorded mode, and returns the header's size. ok is false if data doesn't
+// start with a framed header this version understands.
+func parseStreamHeader(data []byte) (noncePrefix []byte, mode os.FileMode, size int, ok bool) {
+	if len(data) <= len(streamMagic) || !bytes.HasPrefix(data, streamMagic) {
+		return nil, 0, 0, false
+	}
+	fields := data[len(streamMagic)+1:]
+	switch data[len(streamMagic)] {
+	case streamVersion:
+		if len(data) < streamHeaderSize {
+			return nil, 0, 0, false
+		}
+		mode = os.FileMode(binary.BigEndian.Uint16(fields)).Perm()
+		return append([]byte(nil), fields[streamModeSize:streamModeSize+streamNoncePrefixSize]...), mode, streamHeaderSize, true
+	case streamVersionNoMode:
+		if len(data) < streamHeaderSizeNoMode {
+			return nil, 0, 0, false
+		}
+		return append([]byte(nil), fields[:streamNoncePrefixSize]...), 0, streamHeaderSizeNoMode, true
+	}
+	return nil, 0, 0, false
+}
+
+// recordedMode returns the source file mode recorded in a .kanuka file's
+// header, or zero if it is unknown. It doesn't authenticate the mode; only
+// trust it once the file has opened.
+func recordedMode(data []byte) os.FileMode {
+	_, mode, _, _ := parseStreamHeader(data)
+	return mode
+}
+
+// bindMode mixes mode into a copy of the nonce prefix, so a file whose
+// recorded mode has been changed fails to open. A zero mode leaves the prefix
+// as is, which keeps version 1 files readable.
+func bindMode(prefix []byte, mode os.FileMode) []byte {
+	bound := append([]byte(nil), prefix...)
+	bound[0] ^= byte(mode >> 8)
+	bound[1] ^= byte(mode)
+	return bound
+}
+
+// openLegacy opens a single-blob .kanuka file with its nonce prepended.
+func openLegacy(key *[32]byte, ciphertext []byte) ([]byte, error) {
+	var nonce [24]byte
+	if len(ciphertext) < len(nonce)+secretbox.Overhead {
+		return nil, fmt.Errorf("failed to decrypt ciphertext with secretbox: ciphertext is too short")
+	}
+	copy(nonce[:], ciphertext[:len(nonce)])
+
+	out := make([]byte, 0, len(ciphertext)-len(nonce)-secretbox.Overhead)
+	plaintext, ok := secretbox.Open(out, ciphertext[len(nonce):], &nonce, key)
+	if !ok {
+		return nil, fmt.Errorf("failed to decrypt ciphertext with secretbox")
+	}
+	return plaintext, nil
+}
+
+// streamNonce builds a chunk's nonce from the file's nonce prefix, the chunk
+// index, and whether it is the final chunk.
+func streamNonce(prefix []byte, index uint64, final bool) [24]byte {
+	var nonce [24]byte
+	copy(nonce[:], prefix)
+	binary.BigEndian.PutUint64(nonce[streamNoncePrefixSize:], index<<1)
+	if final {
+		nonce[23] |= 1
+	}
+	return nonce
+}
@@ -0,0 +1,425 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+func newStreamTestKey(t *testing.T) *[32]byte {
+	t.Helper()
+	var key [32]byte
+	if _, err := io.ReadFull(rand.Reader, key[:]); err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	return &key
+}
+
+func randomPlaintext(t *testing.T, size int) []byte {
+	t.Helper()
+	data := make([]byte, size)
+	if _, err := io.ReadFull(rand.Reader, data); err != nil {
+		t.Fatalf("Failed to generate plaintext: %v", err)
+	}
+	return data
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	key := newStreamTestKey(t)
+
+	sizes := map[string]int{
+		"empty":                 0,
+		"small":                 42,
+		"one byte under chunk":  streamChunkSize - 1,
+		"exactly one chunk":     streamChunkSize,
+		"one byte over chunk":   streamChunkSize + 1,
+		"exactly three chunks":  3 * streamChunkSize,
+		"chunks plus remainder": 3*streamChunkSize + 17,
+	}
+
+	for name, size := range sizes {
+		t.Run(name, func(t *testing.T) {
+			plaintext := randomPlaintext(t, size)
+
+			ciphertext, err := sealBytes(key, plaintext, 0600)
+			if err != nil {
+				t.Fatalf("sealBytes failed: %v", err)
+			}
+			if _, _, _, ok := parseStreamHeader(ciphertext); !ok {
+				t.Fatal("Expected ciphertext to use the framed format")
+			}
+
+			chunks := size/streamChunkSize + 1
+			if size > 0 && size%streamChunkSize == 0 {
+				chunks--
+			}
+			wantLen := streamHeaderSize + size + chunks*secretbox.Overhead
+			if len(ciphertext) != wantLen {
+				t.Errorf("Expected %d bytes of ciphertext, got %d", wantLen, len(ciphertext))
+			}
+
+			got, err := openBytes(key, ciphertext)
+			if err != nil {
+				t.Fatalf("openBytes failed: %v", err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Error("Decrypted plaintext does not match original")
+			}
+		})
+	}
+}
+
+func TestStreamOpensLegacyFormat(t *testing.T) {
+	key := newStreamTestKey(t)
+	plaintext := []byte("API_KEY=secret\n")
+
+	var nonce [24]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		t.Fatalf("Failed to generate nonce: %v", err)
+	}
+	legacy := secretbox.Seal(nonce[:], plaintext, &nonce, key)
+
+	got, err := openBytes(key, legacy)
+	if err != nil {
+		t.Fatalf("openBytes failed on legacy ciphertext: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("Expected %q, got %q", plaintext, got)
+	}
+}
+
+func TestStreamOpensVersionWithoutMode(t *testing.T) {
+	key := newStreamTestKey(t)
+	plaintext := []byte("API_KEY=secret\n")
+
+	// A version 1 file is a version 2 file with a zero mode and no mode field.
+	ciphertext, err := sealBytes(key, plaintext, 0)
+	if err != nil {
+		t.Fatalf("sealBytes failed: %v", err)
+	}
+	v1 := append([]byte(nil), ciphertext[:len(streamMagic)]...)
+	v1 = append(v1, streamVersionNoMode)
+	v1 = append(v1, ciphertext[len(streamMagic)+1+streamModeSize:]...)
+
+	got, err := openBytes(key, v1)
+	if err != nil {
+		t.Fatalf("openBytes failed on a version 1 file: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("Expected %q, got %q", plaintext, got)
+	}
+	if mode := recordedMode(v1); mode != 0 {
+		t.Errorf("Expected no recorded mode for a version 1 file, got %o", mode)
+	}
+}
+
+func TestStreamRejectsTampering(t *testing.T) {
+	key := newStreamTestKey(t)
+	plaintext := randomPlaintext(t, 2*streamChunkSize+100)
+
+	ciphertext, err := sealBytes(key, plaintext, 0600)
+	if err != nil {
+		t.Fatalf("sealBytes failed: %v", err)
+	}
+	sealedChunk := streamChunkSize + secretbox.Overhead
+
+	truncated := ciphertext[:streamHeaderSize+2*sealedChunk]
+	headerOnly := ciphertext[:streamHeaderSize]
+
+	swapped := append([]byte(nil), ciphertext...)
+	first := streamHeaderSize
+	second := first + sealedChunk
+	copy(swapped[first:second], ciphertext[second:second+sealedChunk])
+	copy(swapped[second:second+sealedChunk], ciphertext[first:second])
+
+	flipped := append([]byte(nil), ciphertext...)
+	flipped[len(flipped)-1] ^= 0xff
+
+	// 0600 is 0x0180, so this makes the recorded mode 0644.
+	widenedMode := append([]byte(nil), ciphertext...)
+	widenedMode[len(streamMagic)+2] ^= 0x80 ^ 0xa4
+
+	cases := map[string][]byte{
+		"dropped final chunk": truncated,
+		"header only":         headerOnly,
+		"reordered chunks":    swapped,
+		"flipped bit":         flipped,
+		"changed mode":        widenedMode,
+	}
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := openBytes(key, data); err == nil {
+				t.Error("Expected decryption to fail")
+			}
+		})
+	}
+
+	if _, err := openBytes(newStreamTestKey(t), ciphertext); err == nil {
+		t.Error("Expected decryption with the wrong key to fail")
+	}
+}
+
+func TestEncryptDecryptFiles_MultiChunk(t *testing.T) {
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, ".env")
+	plaintext := randomPlaintext(t, 5*streamChunkSize+123)
+	if err := os.WriteFile(envPath, plaintext, 0600); err != nil {
+		t.Fatalf("Failed to write .env: %v", err)
+	}
+
+	symKey, err := CreateSymmetricKey()
+	if err != nil {
+		t.Fatalf("Failed to create symmetric key: %v", err)
+	}
+
+	if err := EncryptFiles(symKey, []string{envPath}, false); err != nil {
+		t.Fatalf("EncryptFiles failed: %v", err)
+	}
+	if err := os.Remove(envPath); err != nil {
+		t.Fatalf("Failed to remove .env: %v", err)
+	}
+	if err := DecryptFiles(symKey, []string{envPath + ".kanuka"}, false); err != nil {
+		t.Fatalf("DecryptFiles failed: %v", err)
+	}
+
+	got, err := os.ReadFile(envPath)
+	if err != nil {
+		t.Fatalf("Failed to read decrypted .env: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Error("Decrypted file does not match original")
+	}
+}
+
+func TestDecryptFiles_FailureLeavesExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envPath, randomPlaintext(t, 2*streamChunkSize), 0600); err != nil {
+		t.Fatalf("Failed to write .env: %v", err)
+	}
+
+	symKey, err := CreateSymmetricKey()
+	if err != nil {
+		t.Fatalf("Failed to create symmetric key: %v", err)
+	}
+	if err := EncryptFiles(symKey, []string{envPath}, false); err != nil {
+		t.Fatalf("EncryptFiles failed: %v", err)
+	}
+
+	existing := []byte("LOCAL=edits\n")
+	if err := os.WriteFile(envPath, existing, 0600); err != nil {
+		t.Fatalf("Failed to write .env: %v", err)
+	}
+
+	wrongKey, err := CreateSymmetricKey()
+	if err != nil {
+		t.Fatalf("Failed to create symmetric key: %v", err)
+	}
+	if err := DecryptFiles(wrongKey, []string{envPath + ".kanuka"}, false); err == nil {
+		t.Fatal("Expected DecryptFiles with the wrong key to fail")
+	}
+
+	got, err := os.ReadFile(envPath)
+	if err != nil {
+		t.Fatalf("Failed to read .env: %v", err)
+	}
+	if !bytes.Equal(got, existing) {
+		t.Errorf("Expected existing .env to be untouched, got %q", got)
+	}
+}
+
+func TestDecryptFiles_RestoresRecordedMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permissions are not supported on Windows")
+	}
+
+	symKey, err := CreateSymmetricKey()
+	if err != nil {
+		t.Fatalf("Failed to create symmetric key: %v", err)
+	}
+
+	for _, mode := range []os.FileMode{0600, 0640} {
+		t.Run(mode.String(), func(t *testing.T) {
+			envPath := filepath.Join(t.TempDir(), ".env")
+			if err := os.WriteFile(envPath, []byte("API_KEY=secret\n"), 0600); err != nil {
+				t.Fatalf("Failed to write .env: %v", err)
+			}
+			if err := os.Chmod(envPath, mode); err != nil {
+				t.Fatalf("Failed to chmod .env: %v", err)
+			}
+
+			if err := EncryptFiles(symKey, []string{envPath}, false); err != nil {
+				t.Fatalf("EncryptFiles failed: %v", err)
+			}
+			if err := os.Remove(envPath); err != nil {
+				t.Fatalf("Failed to remove .env: %v", err)
+			}
+			if err := DecryptFiles(symKey, []string{envPath + ".kanuka"}, false); err != nil {
+				t.Fatalf("DecryptFiles failed: %v", err)
+			}
+
+			info, err := os.Stat(envPath)
+			if err != nil {
+				t.Fatalf("Failed to stat decrypted .env: %v", err)
+			}
+			if info.Mode().Perm() != mode {
+				t.Errorf("Expected decrypted .env to have mode %o, got %o", mode, info.Mode().Perm())
+			}
+		})
+	}
+}
+
+func TestDecryptFiles_DefaultsToPrivateModeWhenUnknown(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix permissions are not supported on Windows")
+	}
+
+	symKey, err := CreateSymmetricKey()
+	if err != nil {
+		t.Fatalf("Failed to create symmetric key: %v", err)
+	}
+	envPath := filepath.Join(t.TempDir(), ".env")
+	if err := EncryptReader(symKey, bytes.NewReader([]byte("API_KEY=secret\n")), envPath+".kanuka"); err != nil {
+		t.Fatalf("EncryptReader failed: %v", err)
+	}
+	if err := DecryptFiles(symKey, []string{envPath + ".kanuka"}, false); err != nil {
+		t.Fatalf("DecryptFiles failed: %v", err)
+	}
+
+	info, err := os.Stat(envPath)
+	if err != nil {
+		t.Fatalf("Failed to stat decrypted .env: %v", err)
+	}
+	if info.Mode().Perm() != DefaultDecryptedFileMode {
+		t.Errorf("Expected decrypted .env to have mode %o, got %o", DefaultDecryptedFileMode, info.Mode().Perm())
+	}
+}
+
+func TestEncryptDecryptBytes(t *testing.T) {
+	symKey := newStreamTestKey(t)[:]
+	plaintext := randomPlaintext(t, streamChunkSize+100)
+
+	ciphertext, err := EncryptBytes(symKey, plaintext)
+	if err != nil {
+		t.Fatalf("EncryptBytes failed: %v", err)
+	}
+	again, err := EncryptBytes(symKey, plaintext)
+	if err != nil {
+		t.Fatalf("EncryptBytes failed: %v", err)
+	}
+	if bytes.Equal(ciphertext, again) {
+		t.Error("Expected encrypting twice to give different ciphertexts")
+	}
+
+	got, err := DecryptBytes(symKey, ciphertext)
+	if err != nil {
+		t.Fatalf("DecryptBytes failed: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Error("Expected DecryptBytes to return the original plaintext")
+	}
+
+	// The ciphertext is a valid .kanuka file.
+	path := filepath.Join(t.TempDir(), ".env.kanuka")
+	if err := os.WriteFile(path, ciphertext, 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+	fromFile, err := ReadEncryptedFile(symKey, path)
+	if err != nil || !bytes.Equal(fromFile, plaintext) {
+		t.Errorf("Expected the ciphertext to read back as a .kanuka file: %v", err)
+	}
+}
+
+func TestDecryptBytes_LegacyFormat(t *testing.T) {
+	key := newStreamTestKey(t)
+	plaintext := []byte("API_KEY=secret\n")
+
+	var nonce [24]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		t.Fatalf("Failed to generate nonce: %v", err)
+	}
+	legacy := secretbox.Seal(nonce[:], plaintext, &nonce, key)
+
+	got, err := DecryptBytes(key[:], legacy)
+	if err != nil {
+		t.Fatalf("DecryptBytes failed on legacy ciphertext: %v", err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("Expected %q, got %q", plaintext, got)
+	}
+}
+
+func TestEncryptDecryptBytes_Errors(t *testing.T) {
+	symKey := newStreamTestKey(t)[:]
+	ciphertext, err := EncryptBytes(symKey, []byte("API_KEY=secret\n"))
+	if err != nil {
+		t.Fatalf("EncryptBytes failed: %v", err)
+	}
+
+	if _, err := EncryptBytes(symKey[:16], []byte("x")); err == nil {
+		t.Error("Expected EncryptBytes to reject a short key")
+	}
+	if _, err := DecryptBytes(symKey[:16], ciphertext); err == nil {
+		t.Error("Expected DecryptBytes to reject a short key")
+	}
+	if _, err := DecryptBytes(newStreamTestKey(t)[:], ciphertext); err == nil {
+		t.Error("Expected DecryptBytes to fail with the wrong key")
+	}
+
+	tampered := append([]byte(nil), ciphertext...)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := DecryptBytes(symKey, tampered); err == nil {
+		t.Error("Expected DecryptBytes to fail on modified ciphertext")
+	}
+}
+
+func TestReencryptFile(t *testing.T) {
+	symKey := newStreamTestKey(t)[:]
+	envPath := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envPath, []byte("API_KEY=secret\n"), 0640); err != nil {
+		t.Fatalf("Failed to write .env: %v", err)
+	}
+	if err := EncryptFiles(symKey, []string{envPath}, false); err != nil {
+		t.Fatalf("EncryptFiles failed: %v", err)
+	}
+	kanukaPath := envPath + ".kanuka"
+	before, err := os.ReadFile(kanukaPath)
+	if err != nil {
+		t.Fatalf("Failed to read .kanuka file: %v", err)
+	}
+
+	if err := ReencryptFile(symKey, kanukaPath); err != nil {
+		t.Fatalf("ReencryptFile failed: %v", err)
+	}
+	after, err := os.ReadFile(kanukaPath)
+	if err != nil {
+		t.Fatalf("Failed to read .kanuka file: %v", err)
+	}
+	if bytes.Equal(before, after) {
+		t.Error("Expected the ciphertext to change")
+	}
+	if recordedMode(after) != recordedMode(before) {
+		t.Errorf("Expected the recorded mode %o to be kept, got %o", recordedMode(before), recordedMode(after))
+	}
+	if got, err := DecryptBytes(symKey, after); err != nil || string(got) != "API_KEY=secret\n" {
+		t.Errorf("Expected the re-encrypted file to decrypt to the same plaintext, got %q: %v", got, err)
+	}
+
+	// A file that doesn't open with the key is left as it was.
+	if err := ReencryptFile(newStreamTestKey(t)[:], kanukaPath); err == nil {
+		t.Error("Expected ReencryptFile to fail with the wrong key")
+	}
+	unchanged, err := os.ReadFile(kanukaPath)
+	if err != nil {
+		t.Fatalf("Failed to read .kanuka file: %v", err)
+	}
+	if !bytes.Equal(unchanged, after) {
+		t.Error("Expected a failed re-encrypt to leave the file unchanged")
+	}
+}
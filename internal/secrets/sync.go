@@ -1,17 +1,14 @@
 package secrets
 
 import (
-	"crypto/rand"
-	"crypto/rsa"
+	"errors"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
 	logger "github.com/PolarWolf314/kanuka/internal/logging"
-
-	"golang.org/x/crypto/nacl/secretbox"
+	"github.com/PolarWolf314/kanuka/internal/utils"
 )
 
 // SyncOptions configures the sync operation.
@@ -52,6 +49,8 @@ type SyncResult struct {
 type decryptedSecret struct {
 	originalPath string
 	plaintext    []byte
+	// mode is the source file's permissions recorded in the .kanuka file.
+	mode os.FileMode
 }
 
 // userKeyData holds an encrypted symmetric key for a user.
@@ -61,9 +60,15 @@ type userKeyData struct {
 }
 
 // SyncSecrets re-encrypts all secrets with a new symmetric key.
-// The privateKey is used to decrypt the current symmetric key.
+// The privateKey is used to decrypt the current symmetric key, unless the
+// user's key was wrapped for a GPG key, which is unwrapped with gpg.
 // Returns a SyncResult with details of the operation.
-func SyncSecrets(privateKey *rsa.PrivateKey, opts SyncOptions) (*SyncResult, error) {
+//
+// Every new user key and secret file is written to a temporary file first and
+// swapped in only once all of them were written. If any write or swap fails,
+// the files already swapped in are restored, so the project is never left
+// with some files on the new key and some on the old.
+func SyncSecrets(privateKey PrivateKey, opts SyncOptions) (*SyncResult, error) {
 	log := logger.Logger{Verbose: opts.Verbose, Debug: opts.Debug}
 
 	result := &SyncResult{
@@ -76,7 +81,6 @@ func SyncSecrets(privateKey *rsa.PrivateKey, opts SyncOptions) (*SyncResult, err
 	}
 
 	projectPath := configs.ProjectKanukaSettings.ProjectPath
-	projectPublicKeyPath := configs.ProjectKanukaSettings.ProjectPublicKeyPath
 	projectSecretsPath := configs.ProjectKanukaSettings.ProjectSecretsPath
 
 	// Load user config to get current user's UUID.
@@ -131,7 +135,9 @@ func SyncSecrets(privateKey *rsa.PrivateKey, opts SyncOptions) (*SyncResult, err
 	}
 
 	// Decrypt current symmetric key.
-	currentSymKey, err := DecryptWithPrivateKey(currentEncryptedSymKey, privateKey)
+	currentSymKey, err := UnwrapSymmetricKey(currentEncryptedSymKey, func() (PrivateKey, error) {
+		return privateKey, nil
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to decrypt current symmetric key: %w", err)
 	}
@@ -146,7 +152,8 @@ func SyncSecrets(privateKey *rsa.PrivateKey, opts SyncOptions) (*SyncResult, err
 	log.Infof("Decrypted current symmetric key")
 
 	// Find all .kanuka secret files in project (excluding .kanuka/secrets/ which has user keys).
-	kanukaFiles, err := FindEnvOrKanukaFiles(projectPath, []string{}, true)
+	// .kanuka/ignore is not applied: every existing secret must move to the new key.
+	kanukaFiles, err := FindEnvOrKanukaFiles(projectPath, []string{}, nil, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find .kanuka files: %w", err)
 	}
@@ -165,21 +172,15 @@ func SyncSecrets(privateKey *rsa.PrivateKey, opts SyncOptions) (*SyncResult, err
 			return nil, fmt.Errorf("failed to read .kanuka file %s: %w", kanukaFile, err)
 		}
 
-		if len(ciphertext) < 24 {
-			return nil, fmt.Errorf("invalid .kanuka file %s: too short", kanukaFile)
-		}
-
-		var decryptNonce [24]byte
-		copy(decryptNonce[:], ciphertext[:24])
-
-		plaintext, ok := secretbox.Open(nil, ciphertext[24:], &decryptNonce, &key)
-		if !ok {
-			return nil, fmt.Errorf("failed to decrypt file %s", kanukaFile)
+		plaintext, err := openBytes(&key, ciphertext)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt file %s: %w", kanukaFile, err)
 		}
 
 		decryptedSecrets = append(decryptedSecrets, decryptedSecret{
 			originalPath: kanukaFile,
 			plaintext:    plaintext,
+			mode:         recordedMode(ciphertext),
 		})
 
 		log.Debugf("Decrypted %s", kanukaFile)
@@ -204,13 +205,7 @@ func SyncSecrets(privateKey *rsa.PrivateKey, opts SyncOptions) (*SyncResult, err
 	var userKeys []userKeyData
 
 	for _, userUUID := range activeUserUUIDs {
-		publicKeyPath := filepath.Join(projectPublicKeyPath, userUUID+".pub")
-		publicKey, err := LoadPublicKey(publicKeyPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load public key for user %s: %w", userUUID, err)
-		}
-
-		encryptedSymKey, err := EncryptWithPublicKey(newSymKey, publicKey)
+		encryptedSymKey, err := WrapKeyForUser(newSymKey, userUUID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to encrypt symmetric key for user %s: %w", userUUID, err)
 		}
@@ -232,12 +227,10 @@ func SyncSecrets(privateKey *rsa.PrivateKey, opts SyncOptions) (*SyncResult, err
 	reencryptedSecrets := make(map[string][]byte)
 
 	for _, ds := range decryptedSecrets {
-		var nonce [24]byte
-		if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
-			return nil, fmt.Errorf("failed to generate nonce: %w", err)
+		ciphertext, err := sealBytes(&newKey, ds.plaintext, ds.mode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt %s: %w", ds.originalPath, err)
 		}
-
-		ciphertext := secretbox.Seal(nonce[:], ds.plaintext, &nonce, &newKey)
 		reencryptedSecrets[ds.originalPath] = ciphertext
 
 		log.Debugf("Re-encrypted %s", ds.originalPath)
@@ -251,23 +244,24 @@ func SyncSecrets(privateKey *rsa.PrivateKey, opts SyncOptions) (*SyncResult, err
 		return result, nil
 	}
 
-	// Write everything to disk atomically.
-	// First, write all user .kanuka files.
+	// Stage every new file beside its target first, and only swap them in
+	// once all of them were written, so a failure part way through never
+	// leaves some files on the new key and some on the old.
+	var writes []stagedWrite
 	for _, uk := range userKeys {
-		kanukaPath := filepath.Join(projectSecretsPath, uk.uuid+".kanuka")
-		if err := os.WriteFile(kanukaPath, uk.encryptedKey, 0600); err != nil {
-			return nil, fmt.Errorf("failed to save symmetric key for user %s: %w", uk.uuid, err)
-		}
-		log.Debugf("Wrote user key file %s", kanukaPath)
+		writes = append(writes, stagedWrite{
+			path: filepath.Join(projectSecretsPath, uk.uuid+".kanuka"),
+			data: uk.encryptedKey,
+		})
+	}
+	for _, ds := range decryptedSecrets {
+		writes = append(writes, stagedWrite{path: ds.originalPath, data: reencryptedSecrets[ds.originalPath]})
 	}
 
-	// Then, write all re-encrypted secret files.
-	for path, ciphertext := range reencryptedSecrets {
-		if err := os.WriteFile(path, ciphertext, 0600); err != nil {
-			return nil, fmt.Errorf("failed to write re-encrypted file %s: %w", path, err)
-		}
-		log.Debugf("Wrote secret file %s", path)
+	if err := commitStagedWrites(writes); err != nil {
+		return nil, err
 	}
+	log.Debugf("Wrote %d user key files and %d secret files", len(userKeys), len(reencryptedSecrets))
 
 	// Delete .kanuka files for excluded users (they should no longer have access).
 	for _, excludedUUID := range opts.ExcludeUsers {
@@ -288,9 +282,116 @@ func SyncSecrets(privateKey *rsa.PrivateKey, opts SyncOptions) (*SyncResult, err
 	return result, nil
 }
 
+// stagedWrite is a file SyncSecrets replaces once every new file is staged.
+type stagedWrite struct {
+	path    string
+	data    []byte
+	tmpPath string
+}
+
+// Variables so tests can inject failures part way through a sync.
+var (
+	syncStageFile   = stageFile
+	syncReplaceFile = os.Rename
+)
+
+// stageFile writes data with 0600 permissions to a temporary file in path's
+// directory and returns its name.
+func stageFile(path string, data []byte) (tmpPath string, err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".sync-*")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if err := tmp.Chmod(0600); err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		return "", err
+	}
+	if err := tmp.Sync(); err != nil {
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// commitStagedWrites stages every write, then renames each staged file over
+// its target. If staging fails nothing is touched; if a rename fails, the
+// files already replaced are restored. Either way every staged file is
+// removed and the error is returned.
+func commitStagedWrites(writes []stagedWrite) error {
+	removeStaged := func() {
+		for _, w := range writes {
+			if w.tmpPath != "" {
+				_ = os.Remove(w.tmpPath)
+			}
+		}
+	}
+
+	for i := range writes {
+		tmpPath, err := syncStageFile(writes[i].path, writes[i].data)
+		if err != nil {
+			removeStaged()
+			return fmt.Errorf("failed to stage %s: %w", writes[i].path, err)
+		}
+		writes[i].tmpPath = tmpPath
+	}
+
+	// A nil entry means the file didn't exist and is removed on rollback.
+	originals := make([][]byte, len(writes))
+	for i, w := range writes {
+		data, err := os.ReadFile(w.path)
+		if err != nil && !os.IsNotExist(err) {
+			removeStaged()
+			return fmt.Errorf("failed to read %s: %w", w.path, err)
+		}
+		originals[i] = data
+	}
+
+	for i, w := range writes {
+		if err := syncReplaceFile(w.tmpPath, w.path); err != nil {
+			cause := fmt.Errorf("failed to replace %s: %w", w.path, err)
+			if rollbackErr := restoreOriginals(writes[:i], originals[:i]); rollbackErr != nil {
+				cause = fmt.Errorf("%w (rollback also failed: %v)", cause, rollbackErr)
+			}
+			removeStaged()
+			return cause
+		}
+		writes[i].tmpPath = ""
+	}
+	return nil
+}
+
+// restoreOriginals puts back the contents files had before they were
+// replaced, removing those that didn't exist.
+func restoreOriginals(writes []stagedWrite, originals [][]byte) error {
+	var errs []error
+	for i, w := range writes {
+		if originals[i] == nil {
+			if err := os.Remove(w.path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("removing %s: %w", w.path, err))
+			}
+			continue
+		}
+		if err := utils.WriteFileAtomic(w.path, originals[i], 0600); err != nil {
+			errs = append(errs, fmt.Errorf("restoring %s: %w", w.path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // SyncSecretsSimple is a simplified version of SyncSecrets for backward compatibility.
 // It wraps the existing RotateSymmetricKey functionality.
-func SyncSecretsSimple(currentUserUUID string, privateKey *rsa.PrivateKey, verbose bool) error {
+func SyncSecretsSimple(currentUserUUID string, privateKey PrivateKey, verbose bool) error {
 	opts := SyncOptions{
 		Verbose: verbose,
 	}
@@ -5,7 +5,9 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
@@ -206,24 +208,9 @@ func createEncryptedSecretFile(t *testing.T, path string, plaintext []byte, symK
 func decryptSecretFile(t *testing.T, path string, symKey []byte) []byte {
 	t.Helper()
 
-	ciphertext, err := os.ReadFile(path)
+	plaintext, err := ReadEncryptedFile(symKey, path)
 	if err != nil {
-		t.Fatalf("Failed to read encrypted file: %v", err)
-	}
-
-	if len(ciphertext) < 24 {
-		t.Fatalf("Ciphertext too short")
-	}
-
-	var key [32]byte
-	copy(key[:], symKey)
-
-	var nonce [24]byte
-	copy(nonce[:], ciphertext[:24])
-
-	plaintext, ok := secretbox.Open(nil, ciphertext[24:], &nonce, &key)
-	if !ok {
-		t.Fatalf("Failed to decrypt file")
+		t.Fatalf("Failed to decrypt file: %v", err)
 	}
 
 	return plaintext
@@ -375,6 +362,102 @@ func TestSyncSecrets_MultipleSecretFiles(t *testing.T) {
 	}
 }
 
+// snapshotDir returns the contents of every file under dir, keyed by path.
+func snapshotDir(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[path] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to snapshot %s: %v", dir, err)
+	}
+	return files
+}
+
+// TestSyncSecrets_FailureLeavesProjectUnchanged tests that a failure on any
+// file, whether while staging or while swapping files in, leaves every file
+// in the project as it was.
+func TestSyncSecrets_FailureLeavesProjectUnchanged(t *testing.T) {
+	injected := errors.New("injected failure")
+
+	// One user key and three secret files are written, in that order.
+	tests := []struct {
+		name   string
+		stage  int
+		rename int
+	}{
+		{"StageFirstFile", 1, 0},
+		{"StageLastFile", 4, 0},
+		{"ReplaceSecondFile", 0, 2},
+		{"ReplaceLastFile", 0, 4},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tempDir, _, privateKey, cleanup := setupSyncTestEnvironment(t)
+			defer cleanup()
+
+			symKey := getSymmetricKeyForUser(t, testUserUUID, privateKey)
+			if err := os.MkdirAll(filepath.Join(tempDir, "config"), 0755); err != nil {
+				t.Fatalf("Failed to create config directory: %v", err)
+			}
+			for path, content := range map[string]string{
+				".env.kanuka":        "API_KEY=secret123",
+				".env.local.kanuka":  "LOCAL_VAR=localvalue",
+				"config/.env.kanuka": "CONFIG_VAR=configvalue",
+			} {
+				createEncryptedSecretFile(t, filepath.Join(tempDir, path), []byte(content), symKey)
+			}
+			before := snapshotDir(t, tempDir)
+
+			originalStage, originalReplace := syncStageFile, syncReplaceFile
+			defer func() { syncStageFile, syncReplaceFile = originalStage, originalReplace }()
+			staged, replaced := 0, 0
+			syncStageFile = func(path string, data []byte) (string, error) {
+				staged++
+				if staged == tc.stage {
+					return "", injected
+				}
+				return originalStage(path, data)
+			}
+			syncReplaceFile = func(oldpath, newpath string) error {
+				replaced++
+				if replaced == tc.rename {
+					return injected
+				}
+				return originalReplace(oldpath, newpath)
+			}
+
+			_, err := SyncSecrets(privateKey, SyncOptions{})
+			if !errors.Is(err, injected) {
+				t.Fatalf("Expected the injected failure, got %v", err)
+			}
+
+			after := snapshotDir(t, tempDir)
+			if len(after) != len(before) {
+				t.Errorf("Expected %d files after the failed sync, got %d", len(before), len(after))
+			}
+			for path, content := range before {
+				if after[path] != content {
+					t.Errorf("Expected %s to be unchanged", path)
+				}
+			}
+			if got := getSymmetricKeyForUser(t, testUserUUID, privateKey); string(got) != string(symKey) {
+				t.Error("Expected the user to keep the original symmetric key")
+			}
+		})
+	}
+}
+
 func TestSyncSecrets_DryRun(t *testing.T) {
 	tempDir, _, privateKey, cleanup := setupSyncTestEnvironment(t)
 	defer cleanup()
@@ -700,3 +783,73 @@ func TestSyncSecretsSimple(t *testing.T) {
 		t.Errorf("Decrypted content doesn't match original: got %q, want %q", decrypted, secretContent)
 	}
 }
+
+// useGPGKeyForUser registers a new GPG key for testUserUUID and rewraps
+// their copy of symKey for it, as register --gpg-key does.
+func useGPGKeyForUser(t *testing.T, tempDir string, symKey []byte) {
+	t.Helper()
+
+	keyPath := filepath.Join(tempDir, ".kanuka", "public_keys", testUserUUID+".gpg")
+	generateGPGKey(t, keyPath)
+
+	wrapped, err := EncryptWithGPG(symKey, keyPath)
+	if err != nil {
+		t.Fatalf("Failed to wrap symmetric key with GPG: %v", err)
+	}
+	if err := SaveKanukaKeyToProject(testUserUUID, wrapped); err != nil {
+		t.Fatalf("Failed to save GPG-wrapped key: %v", err)
+	}
+}
+
+// gpgSymmetricKeyForUser unwraps testUserUUID's GPG-wrapped symmetric key.
+func gpgSymmetricKeyForUser(t *testing.T) []byte {
+	t.Helper()
+
+	wrapped, err := GetProjectKanukaKey(testUserUUID)
+	if err != nil {
+		t.Fatalf("Failed to get encrypted symmetric key: %v", err)
+	}
+	symKey, err := DecryptWithGPG(wrapped)
+	if err != nil {
+		t.Fatalf("Failed to decrypt GPG-wrapped key: %v", err)
+	}
+	return symKey
+}
+
+func TestSyncSecrets_GPGWrappedKey(t *testing.T) {
+	tempDir, _, privateKey, cleanup := setupSyncTestEnvironment(t)
+	defer cleanup()
+
+	originalSymKey := getSymmetricKeyForUser(t, testUserUUID, privateKey)
+	useGPGKeyForUser(t, tempDir, originalSymKey)
+
+	secretContent := []byte("API_KEY=secret123")
+	secretPath := filepath.Join(tempDir, ".env.kanuka")
+	createEncryptedSecretFile(t, secretPath, secretContent, originalSymKey)
+
+	if _, err := SyncSecrets(nil, SyncOptions{}); err != nil {
+		t.Fatalf("SyncSecrets failed: %v", err)
+	}
+
+	newSymKey := gpgSymmetricKeyForUser(t)
+	if string(decryptSecretFile(t, secretPath, newSymKey)) != string(secretContent) {
+		t.Error("Secret content doesn't match after sync")
+	}
+}
+
+func TestRotateSymmetricKey_GPGWrappedKey(t *testing.T) {
+	tempDir, _, privateKey, cleanup := setupSyncTestEnvironment(t)
+	defer cleanup()
+
+	originalSymKey := getSymmetricKeyForUser(t, testUserUUID, privateKey)
+	useGPGKeyForUser(t, tempDir, originalSymKey)
+
+	if err := RotateSymmetricKey(testUserUUID, nil, false); err != nil {
+		t.Fatalf("RotateSymmetricKey failed: %v", err)
+	}
+
+	newSymKey := gpgSymmetricKeyForUser(t)
+	if len(newSymKey) != 32 || string(newSymKey) == string(originalSymKey) {
+		t.Error("Expected a new 32-byte symmetric key after rotation")
+	}
+}
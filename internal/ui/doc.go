@@ -25,6 +25,9 @@
 //   - NO_COLOR environment variable is set (any value)
 //   - Terminal doesn't support colors (TERM=dumb, not a TTY)
 //
+// SetColorMode overrides this detection: ColorAlways forces colors on, even
+// when output is piped, and ColorNever forces them off.
+//
 // When colors are disabled, formatters apply text decorations:
 //   - Code: `backticks`
 //   - Highlight: 'single quotes'
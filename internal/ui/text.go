@@ -16,22 +16,61 @@ type Formatter struct {
 
 // Sprint formats the arguments and returns the resulting string.
 func (f Formatter) Sprint(a ...interface{}) string {
-	text := fmt.Sprint(a...)
-	if noColor() {
-		return f.prefix + text + f.suffix
-	}
-	return f.color.Sprint(text)
+	return f.render(fmt.Sprint(a...))
 }
 
 // Sprintf formats according to a format specifier and returns the resulting string.
 func (f Formatter) Sprintf(format string, a ...interface{}) string {
-	text := fmt.Sprintf(format, a...)
+	return f.render(fmt.Sprintf(format, a...))
+}
+
+// render colors text, or decorates it if color output is disabled.
+func (f Formatter) render(text string) string {
 	if noColor() {
 		return f.prefix + text + f.suffix
 	}
+	if colorMode == ColorAlways {
+		// fatih/color disables itself when stdout isn't a terminal, so
+		// force it on for a copy of the color.
+		forced := *f.color
+		forced.EnableColor()
+		return forced.Sprint(text)
+	}
 	return f.color.Sprint(text)
 }
 
+// ColorMode selects when formatters use color.
+type ColorMode string
+
+const (
+	// ColorAuto uses color unless NO_COLOR is set or the terminal doesn't support it.
+	ColorAuto ColorMode = "auto"
+
+	// ColorAlways uses color even when output is piped or NO_COLOR is set.
+	ColorAlways ColorMode = "always"
+
+	// ColorNever never uses color, as if NO_COLOR were set.
+	ColorNever ColorMode = "never"
+)
+
+// colorMode is the mode set by SetColorMode.
+var colorMode = ColorAuto
+
+// SetColorMode overrides color detection for every formatter.
+func SetColorMode(mode ColorMode) {
+	colorMode = mode
+}
+
+// ParseColorMode parses "auto", "always", or "never".
+func ParseColorMode(s string) (ColorMode, error) {
+	switch mode := ColorMode(s); mode {
+	case ColorAuto, ColorAlways, ColorNever:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("color must be %q, %q, or %q, got %q", ColorAuto, ColorAlways, ColorNever, s)
+	}
+}
+
 // EnsureNewline ensures the string ends with a newline character.
 func EnsureNewline(s string) string {
 	if len(s) == 0 || s[len(s)-1] != '\n' {
@@ -42,6 +81,12 @@ func EnsureNewline(s string) string {
 
 // noColor returns true if color output should be disabled.
 func noColor() bool {
+	switch colorMode {
+	case ColorAlways:
+		return false
+	case ColorNever:
+		return true
+	}
 	// Check NO_COLOR environment variable (https://no-color.org/).
 	if _, exists := os.LookupEnv("NO_COLOR"); exists {
 		return true
@@ -147,3 +147,64 @@ func TestMultipleArguments(t *testing.T) {
 		t.Errorf("Code.Sprint with multiple args = %q, want %q", result, want)
 	}
 }
+
+func TestColorModeNeverUsesDecorations(t *testing.T) {
+	os.Unsetenv("NO_COLOR")
+	originalNoColor := color.NoColor
+	color.NoColor = false
+	SetColorMode(ColorNever)
+	defer func() {
+		SetColorMode(ColorAuto)
+		color.NoColor = originalNoColor
+	}()
+
+	tests := []struct {
+		name      string
+		formatter Formatter
+		want      string
+	}{
+		{"Code", Code, "`value`"},
+		{"Highlight", Highlight, "'value'"},
+		{"Muted", Muted, "(value)"},
+		{"Path", Path, "value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.formatter.Sprint("value"); got != tt.want {
+				t.Errorf("%s.Sprint under never = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorModeAlwaysOverridesDetection(t *testing.T) {
+	os.Setenv("NO_COLOR", "1")
+	originalNoColor := color.NoColor
+	color.NoColor = true
+	SetColorMode(ColorAlways)
+	defer func() {
+		SetColorMode(ColorAuto)
+		color.NoColor = originalNoColor
+		os.Unsetenv("NO_COLOR")
+	}()
+
+	result := Code.Sprint("kanuka secrets init")
+	if !strings.Contains(result, "\x1b[") {
+		t.Errorf("Code.Sprint under always should contain ANSI escape codes, got: %q", result)
+	}
+	if strings.Contains(result, "`") {
+		t.Errorf("Code.Sprint under always should not add backticks, got: %q", result)
+	}
+}
+
+func TestParseColorMode(t *testing.T) {
+	for _, valid := range []string{"auto", "always", "never"} {
+		if mode, err := ParseColorMode(valid); err != nil || string(mode) != valid {
+			t.Errorf("ParseColorMode(%q) = %q, %v", valid, mode, err)
+		}
+	}
+	if _, err := ParseColorMode("sometimes"); err == nil {
+		t.Error("ParseColorMode should reject an unknown mode")
+	}
+}
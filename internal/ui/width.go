@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"os"
+	"strconv"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+const (
+	// MinWidth is the narrowest width output is laid out for. Narrower
+	// terminals wrap, but layouts never shrink below this.
+	MinWidth = 40
+
+	// DefaultWidth is used when stdout is not a terminal and COLUMNS is unset.
+	DefaultWidth = 80
+)
+
+// Width returns the current width of the terminal attached to stdout.
+//
+// The width is queried on every call rather than cached, so callers that
+// redraw (spinners, progress lines, tables) pick up terminal resizes. When
+// stdout is not a terminal, COLUMNS is used if set, then DefaultWidth. The
+// result is never less than MinWidth.
+func Width() int {
+	width := DefaultWidth
+	if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+		width = w
+	} else if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		width = cols
+	}
+	if width < MinWidth {
+		width = MinWidth
+	}
+	return width
+}
+
+// VisibleWidth returns the number of columns s occupies, ignoring ANSI escape sequences.
+func VisibleWidth(s string) int {
+	width := 0
+	inEscape := false
+	for _, r := range s {
+		switch {
+		case inEscape:
+			if r >= '@' && r <= '~' && r != '[' {
+				inEscape = false
+			}
+		case r == '\x1b':
+			inEscape = true
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+// Truncate shortens s to at most width visible columns, ending with "…" if
+// anything was cut. ANSI escape sequences are preserved and don't count
+// towards the width; if s is cut inside colored text, the color is reset.
+func Truncate(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if VisibleWidth(s) <= width {
+		return s
+	}
+
+	var out []byte
+	visible := 0
+	inEscape := false
+	sawEscape := false
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case inEscape:
+			if r >= '@' && r <= '~' && r != '[' {
+				inEscape = false
+			}
+		case r == '\x1b':
+			inEscape = true
+			sawEscape = true
+		default:
+			if visible == width-1 {
+				out = append(out, "…"...)
+				if sawEscape {
+					out = append(out, "\x1b[0m"...)
+				}
+				return string(out)
+			}
+			visible++
+		}
+		out = append(out, s[i:i+size]...)
+		i += size
+	}
+	return string(out)
+}
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"testing"
+)
+
+func TestWidthClampsToMinimum(t *testing.T) {
+	t.Setenv("COLUMNS", "10")
+	if got := Width(); got < MinWidth {
+		t.Errorf("Width() = %d, want at least %d", got, MinWidth)
+	}
+}
+
+func TestWidthReadsColumnsEachCall(t *testing.T) {
+	// In tests stdout is not a terminal, so COLUMNS is used. Changing it
+	// between calls must be reflected, since the width is never cached.
+	t.Setenv("COLUMNS", "100")
+	first := Width()
+	t.Setenv("COLUMNS", "120")
+	second := Width()
+	if first == 100 && second != 120 {
+		t.Errorf("Width() did not pick up the new width: got %d then %d", first, second)
+	}
+}
+
+func TestVisibleWidth(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"hello", 5},
+		{"\x1b[32m✓\x1b[0m done", 6},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := VisibleWidth(tt.input); got != tt.want {
+			t.Errorf("VisibleWidth(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		width int
+		want  string
+	}{
+		{"fits", "hello", 10, "hello"},
+		{"exact", "hello", 5, "hello"},
+		{"cut", "hello world", 6, "hello…"},
+		{"colored", "\x1b[33mhello world\x1b[0m", 6, "\x1b[33mhello…\x1b[0m"},
+		{"zero", "hello", 0, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Truncate(tt.input, tt.width); got != tt.want {
+				t.Errorf("Truncate(%q, %d) = %q, want %q", tt.input, tt.width, got, tt.want)
+			}
+		})
+	}
+}
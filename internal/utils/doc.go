@@ -7,6 +7,7 @@
 //
 // Functions for working with the filesystem and project structure:
 //   - FindProjectKanukaRoot: walks up directories to find .kanuka
+//   - FindProjectKanukaRootWithOptions: the same walk, bounded by stop markers or a depth
 //   - FormatPaths: formats file paths for human-readable output
 //
 // # System Utilities
@@ -2,15 +2,50 @@ package utils
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 )
 
-// FindProjectKanukaRoot traverses up directories to find the project's Kanuka root.
+// DefaultRootStopMarkers are the names that mark a repository boundary when
+// the root search is told to stop at one.
+var DefaultRootStopMarkers = []string{".git", "package.json"}
+
+// ProjectRootOptions limits how far up FindProjectKanukaRootWithOptions walks.
+type ProjectRootOptions struct {
+	// StopMarkers are file or directory names that mark a boundary. A
+	// directory containing one is still checked for .kanuka, but the search
+	// doesn't climb past it.
+	StopMarkers []string
+
+	// MaxDepth is the number of parent directories to check above the
+	// working directory. Zero means no limit.
+	MaxDepth int
+}
+
+// ProjectRootSearch holds the options FindProjectKanukaRoot searches with.
+// The zero value finds the nearest .kanuka directory, however far up it is.
+var ProjectRootSearch ProjectRootOptions
+
+// FindProjectKanukaRoot traverses up directories to find the project's Kanuka root,
+// limited by ProjectRootSearch.
 // Returns the path to the project root if found, empty string otherwise.
 // Stops searching when it reaches the user's home directory.
+// If KANUKA_PROJECT_ROOT is set, it is used instead and no traversal happens.
 func FindProjectKanukaRoot() (string, error) {
+	return FindProjectKanukaRootWithOptions(ProjectRootSearch)
+}
+
+// FindProjectKanukaRootWithOptions is like FindProjectKanukaRoot, but stops
+// at the first directory containing one of opts.StopMarkers, or after
+// opts.MaxDepth parent directories, whichever comes first.
+func FindProjectKanukaRootWithOptions(opts ProjectRootOptions) (string, error) {
+	if root, ok, err := ProjectRootFromEnv(); ok {
+		return root, err
+	}
+
 	currentDir, err := os.Getwd()
 	if err != nil {
 		return "", fmt.Errorf("failed to get working directory: %w", err)
@@ -22,7 +57,7 @@ func FindProjectKanukaRoot() (string, error) {
 		return "", fmt.Errorf("failed to get user home directory: %w", err)
 	}
 
-	for {
+	for depth := 0; ; depth++ {
 		// Stop searching at one level above home directory
 		if currentDir == path.Join(homeDir, "..") {
 			return "", nil
@@ -40,6 +75,17 @@ func FindProjectKanukaRoot() (string, error) {
 			return "", fmt.Errorf("error checking for .kanuka directory at %s: %w", currentDir, err)
 		}
 
+		if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+			return "", nil
+		}
+		atBoundary, err := hasStopMarker(currentDir, opts.StopMarkers)
+		if err != nil {
+			return "", err
+		}
+		if atBoundary {
+			return "", nil
+		}
+
 		parentDir := filepath.Dir(currentDir)
 
 		// If we've reached the filesystem root and haven't found .kanuka
@@ -49,3 +95,79 @@ func FindProjectKanukaRoot() (string, error) {
 		currentDir = parentDir
 	}
 }
+
+// hasStopMarker reports whether dir contains any of markers.
+func hasStopMarker(dir string, markers []string) (bool, error) {
+	for _, marker := range markers {
+		_, err := os.Stat(filepath.Join(dir, marker))
+		if err == nil {
+			return true, nil
+		}
+		if !os.IsNotExist(err) {
+			return false, fmt.Errorf("error checking for %s at %s: %w", marker, dir, err)
+		}
+	}
+	return false, nil
+}
+
+// WriteFileAtomic writes data to path via a temporary file in the same
+// directory and a rename, so readers never see a partially written file and
+// a crash leaves either the old or the new contents. The file gets perm
+// regardless of the process umask.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return WriteFileAtomicFunc(path, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// atomicTempMarker separates the target's name from the random suffix in
+// the name of the temporary file WriteFileAtomicFunc writes.
+const atomicTempMarker = ".tmp-"
+
+// IsAtomicTempFile reports whether path is a temporary file written by
+// WriteFileAtomic, which may be left behind if the process was killed
+// before the rename.
+func IsAtomicTempFile(path string) bool {
+	base := filepath.Base(path)
+	i := strings.LastIndex(base, atomicTempMarker)
+	if !strings.HasPrefix(base, ".") || i < 0 {
+		return false
+	}
+	suffix := base[i+len(atomicTempMarker):]
+	return suffix != "" && strings.Trim(suffix, "0123456789") == ""
+}
+
+// WriteFileAtomicFunc is like WriteFileAtomic but lets write stream the
+// contents. If write returns an error, the temporary file is removed and
+// path is left untouched.
+func WriteFileAtomicFunc(path string, perm os.FileMode, write func(w io.Writer) error) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+atomicTempMarker+"*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %w", path, err)
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	if err := tmp.Chmod(perm); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", tmpPath, err)
+	}
+	if err := write(tmp); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmpPath, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", tmpPath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
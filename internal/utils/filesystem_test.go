@@ -0,0 +1,227 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "privkey")
+
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatalf("Failed to write initial file: %v", err)
+	}
+
+	if err := WriteFileAtomic(path, []byte("new"), 0600); err != nil {
+		t.Fatalf("WriteFileAtomic failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(data) != "new" {
+		t.Errorf("Expected contents %q, got %q", "new", data)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("Failed to stat file: %v", err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("Expected permissions 0600, got %o", info.Mode().Perm())
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected no leftover temporary files, found %d entries", len(entries))
+	}
+}
+
+func TestWriteFileAtomic_MissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "privkey")
+	if err := WriteFileAtomic(path, []byte("data"), 0600); err == nil {
+		t.Error("Expected error when the directory does not exist")
+	}
+}
+
+func TestWriteFileAtomicFunc_PartialWriteLeavesOriginal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env.kanuka")
+
+	original := []byte("original ciphertext")
+	if err := os.WriteFile(path, original, 0600); err != nil {
+		t.Fatalf("Failed to write initial file: %v", err)
+	}
+
+	// Simulate a crash mid-write: half the data lands, then the writer fails.
+	writeErr := errors.New("simulated failure")
+	err := WriteFileAtomicFunc(path, 0600, func(w io.Writer) error {
+		if _, err := w.Write([]byte("new cip")); err != nil {
+			return err
+		}
+		return writeErr
+	})
+	if !errors.Is(err, writeErr) {
+		t.Fatalf("Expected simulated failure, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if !bytes.Equal(data, original) {
+		t.Errorf("Expected original contents %q to be untouched, got %q", original, data)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected the temporary file to be removed, found %d entries", len(entries))
+	}
+}
+
+func TestIsAtomicTempFile(t *testing.T) {
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{".env.kanuka.tmp-123456", true},
+		{"api/..env.tmp-42", true},
+		{".env.kanuka", false},
+		{".env.tmp-notes", false},
+		{"config.tmp-1", false},
+		{".env.kanuka.tmp-", false},
+	}
+	for _, tt := range tests {
+		if got := IsAtomicTempFile(tt.path); got != tt.want {
+			t.Errorf("IsAtomicTempFile(%q) = %t, want %t", tt.path, got, tt.want)
+		}
+	}
+}
+
+// setupNestedProjects creates outer/.kanuka with an inner repository below it,
+// outer/inner/.git, and changes into outer/inner/pkg/app. It returns outer.
+func setupNestedProjects(t *testing.T) string {
+	t.Helper()
+	t.Setenv(ProjectRootEnvVar, "")
+
+	outer, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to resolve temp dir: %v", err)
+	}
+	workDir := filepath.Join(outer, "inner", "pkg", "app")
+	for _, dir := range []string{
+		filepath.Join(outer, ".kanuka"),
+		filepath.Join(outer, "inner", ".git"),
+		workDir,
+	} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(workDir); err != nil {
+		t.Fatalf("Failed to change to %s: %v", workDir, err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(originalWd)
+	})
+	return outer
+}
+
+func TestFindProjectKanukaRootWithOptions(t *testing.T) {
+	t.Run("default climbs to the nearest .kanuka", func(t *testing.T) {
+		outer := setupNestedProjects(t)
+
+		root, err := FindProjectKanukaRootWithOptions(ProjectRootOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if root != outer {
+			t.Errorf("Expected root %s, got %q", outer, root)
+		}
+	})
+
+	t.Run(".git boundary stops the search", func(t *testing.T) {
+		setupNestedProjects(t)
+
+		root, err := FindProjectKanukaRootWithOptions(ProjectRootOptions{StopMarkers: DefaultRootStopMarkers})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if root != "" {
+			t.Errorf("Expected no root past the .git boundary, got %s", root)
+		}
+	})
+
+	t.Run("project at the boundary is still found", func(t *testing.T) {
+		outer := setupNestedProjects(t)
+		inner := filepath.Join(outer, "inner")
+		if err := os.Mkdir(filepath.Join(inner, ".kanuka"), 0755); err != nil {
+			t.Fatalf("Failed to create .kanuka: %v", err)
+		}
+
+		root, err := FindProjectKanukaRootWithOptions(ProjectRootOptions{StopMarkers: DefaultRootStopMarkers})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if root != inner {
+			t.Errorf("Expected root %s, got %q", inner, root)
+		}
+	})
+
+	t.Run("max depth stops the search", func(t *testing.T) {
+		outer := setupNestedProjects(t)
+
+		// outer is three levels above outer/inner/pkg/app.
+		root, err := FindProjectKanukaRootWithOptions(ProjectRootOptions{MaxDepth: 2})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if root != "" {
+			t.Errorf("Expected no root within 2 levels, got %s", root)
+		}
+
+		root, err = FindProjectKanukaRootWithOptions(ProjectRootOptions{MaxDepth: 3})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if root != outer {
+			t.Errorf("Expected root %s within 3 levels, got %q", outer, root)
+		}
+	})
+}
+
+func TestFindProjectKanukaRoot_UsesProjectRootSearch(t *testing.T) {
+	setupNestedProjects(t)
+
+	original := ProjectRootSearch
+	ProjectRootSearch = ProjectRootOptions{StopMarkers: []string{".git"}}
+	t.Cleanup(func() { ProjectRootSearch = original })
+
+	root, err := FindProjectKanukaRoot()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if root != "" {
+		t.Errorf("Expected ProjectRootSearch to stop at the .git boundary, got %s", root)
+	}
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Environment variables that fully specify an operation for headless use
+// (for example in CI). When set, they take precedence over filesystem
+// discovery and the user's config, so no prompts or lookups are needed.
+const (
+	// ProjectRootEnvVar is the project root directory (the one containing .kanuka/).
+	// It replaces walking up from the working directory.
+	ProjectRootEnvVar = "KANUKA_PROJECT_ROOT"
+
+	// PrivateKeyEnvVar is the private key, either as PEM/OpenSSH key material
+	// or as a path to a key file. It replaces loading the key from the user's
+	// key directory.
+	PrivateKeyEnvVar = "KANUKA_PRIVATE_KEY"
+
+	// UserEmailEnvVar is the email of the user to act as. The user's UUID is
+	// looked up in the project config by this email.
+	UserEmailEnvVar = "KANUKA_USER_EMAIL"
+
+	// UserUUIDEnvVar is the UUID of the user to act as. It takes precedence
+	// over UserEmailEnvVar and is needed when an email has several devices.
+	UserUUIDEnvVar = "KANUKA_USER_UUID"
+
+	// ArchivePassphraseEnvVar is the passphrase for encrypted export archives.
+	// It replaces the passphrase prompt in export --encrypt-archive and import.
+	ArchivePassphraseEnvVar = "KANUKA_ARCHIVE_PASSPHRASE"
+)
+
+// ProjectRootFromEnv returns the project root from KANUKA_PROJECT_ROOT.
+// The second return value reports whether the variable was set. If it is set
+// but the directory has no .kanuka directory, the root is returned as empty,
+// matching FindProjectKanukaRoot's behaviour for an uninitialized project.
+func ProjectRootFromEnv() (string, bool, error) {
+	root := os.Getenv(ProjectRootEnvVar)
+	if root == "" {
+		return "", false, nil
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", true, fmt.Errorf("invalid %s %q: %w", ProjectRootEnvVar, root, err)
+	}
+
+	info, err := os.Stat(absRoot)
+	if err != nil {
+		return "", true, fmt.Errorf("invalid %s %q: %w", ProjectRootEnvVar, root, err)
+	}
+	if !info.IsDir() {
+		return "", true, fmt.Errorf("invalid %s %q: not a directory", ProjectRootEnvVar, root)
+	}
+
+	kanukaInfo, err := os.Stat(filepath.Join(absRoot, ".kanuka"))
+	if err != nil || !kanukaInfo.IsDir() {
+		return "", true, nil
+	}
+
+	return absRoot, true, nil
+}
+
+// PrivateKeyFromEnv returns the private key bytes from KANUKA_PRIVATE_KEY.
+// The value may be the key material itself or a path to a key file.
+// Returns nil if the variable is not set.
+func PrivateKeyFromEnv() ([]byte, error) {
+	value := os.Getenv(PrivateKeyEnvVar)
+	if value == "" {
+		return nil, nil
+	}
+
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+
+	data, err := os.ReadFile(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not key material and could not be read as a file: %w", PrivateKeyEnvVar, err)
+	}
+	return data, nil
+}
@@ -24,11 +24,19 @@ func FormatPaths(paths []string) string {
 }
 
 // IsValidEmail checks if the given string is a valid email address format.
+// Dots may not lead, trail, or repeat in either part, since those are almost
+// always typos.
 func IsValidEmail(email string) bool {
-	if email == "" {
+	if email == "" || !emailRegex.MatchString(email) {
 		return false
 	}
-	return emailRegex.MatchString(email)
+	local, domain, _ := strings.Cut(email, "@")
+	for _, part := range []string{local, domain} {
+		if strings.HasPrefix(part, ".") || strings.HasSuffix(part, ".") || strings.Contains(part, "..") {
+			return false
+		}
+	}
+	return true
 }
 
 // IsValidDeviceName checks if a device name is valid (alphanumeric, hyphens, underscores).
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestIsValidEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{"Simple", "alice@example.com", true},
+		{"PlusAddressing", "alice+kanuka@example.com", true},
+		{"DotInLocalPart", "alice.smith@example.com", true},
+		{"Subdomain", "alice@mail.example.co.uk", true},
+		{"HyphenInDomain", "alice@my-company.com", true},
+		{"UppercaseLetters", "Alice@Example.COM", true},
+		{"Empty", "", false},
+		{"MissingAt", "alice.example.com", false},
+		{"MissingLocalPart", "@example.com", false},
+		{"MissingDomain", "alice@", false},
+		{"MissingTLD", "alice@example", false},
+		{"SingleLetterTLD", "alice@example.c", false},
+		{"TwoAtSigns", "alice@@example.com", false},
+		{"Whitespace", "alice @example.com", false},
+		{"UnicodeLocalPart", "ålice@example.com", false},
+		{"UnicodeDomain", "alice@exämple.com", false},
+		{"TrailingDotOnDomain", "alice@example.com.", false},
+		{"LeadingDotOnDomain", "alice@.example.com", false},
+		{"ConsecutiveDotsInDomain", "alice@example..com", false},
+		{"TrailingDotOnLocalPart", "alice.@example.com", false},
+		{"LeadingDotOnLocalPart", ".alice@example.com", false},
+		{"ConsecutiveDotsInLocalPart", "alice..smith@example.com", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := IsValidEmail(tc.input)
+			if result != tc.expected {
+				t.Errorf("IsValidEmail(%q) = %v, expected %v", tc.input, result, tc.expected)
+			}
+		})
+	}
+}
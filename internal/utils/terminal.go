@@ -64,6 +64,11 @@ func IsTerminal() bool {
 	return term.IsTerminal(int(os.Stdin.Fd()))
 }
 
+// IsStdoutTerminal returns true if stdout is a terminal.
+func IsStdoutTerminal() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
 // IsTTYAvailable returns true if /dev/tty (or CON on Windows) is available for reading.
 func IsTTYAvailable() bool {
 	ttyPath := "/dev/tty"
@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares two semantic version strings such as "v1.2.3" or "1.2.3".
+// It returns -1 if a < b, 0 if a == b, and 1 if a > b.
+// A leading "v" is ignored, missing components are treated as zero, and a
+// pre-release suffix (e.g. "-rc.1") sorts before the corresponding release.
+func CompareVersions(a, b string) int {
+	aCore, aPre := splitVersion(a)
+	bCore, bPre := splitVersion(b)
+
+	for i := 0; i < len(aCore) || i < len(bCore); i++ {
+		var av, bv int
+		if i < len(aCore) {
+			av = aCore[i]
+		}
+		if i < len(bCore) {
+			bv = bCore[i]
+		}
+		if av != bv {
+			if av < bv {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	case aPre < bPre:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// IsReleaseVersion reports whether the version string looks like a tagged release
+// (as opposed to a development build such as "dev").
+func IsReleaseVersion(version string) bool {
+	core, _ := splitVersion(version)
+	return len(core) > 0
+}
+
+// splitVersion splits a version string into its numeric components and pre-release suffix.
+// Build metadata (after "+") is discarded.
+func splitVersion(version string) ([]int, string) {
+	version = strings.TrimSpace(version)
+	version = strings.TrimPrefix(version, "v")
+
+	if idx := strings.Index(version, "+"); idx >= 0 {
+		version = version[:idx]
+	}
+
+	pre := ""
+	if idx := strings.Index(version, "-"); idx >= 0 {
+		pre = version[idx+1:]
+		version = version[:idx]
+	}
+
+	if version == "" {
+		return nil, pre
+	}
+
+	parts := strings.Split(version, ".")
+	core := make([]int, 0, len(parts))
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, pre
+		}
+		core = append(core, n)
+	}
+	return core, pre
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected int
+	}{
+		{"Equal", "1.2.3", "1.2.3", 0},
+		{"EqualWithPrefix", "v1.2.3", "1.2.3", 0},
+		{"PatchLess", "v1.2.3", "v1.2.4", -1},
+		{"MinorGreater", "v1.3.0", "v1.2.9", 1},
+		{"MajorGreater", "v2.0.0", "v1.99.99", 1},
+		{"MissingComponent", "v1.2", "v1.2.0", 0},
+		{"NumericNotLexical", "v1.10.0", "v1.9.0", 1},
+		{"PreReleaseBeforeRelease", "v1.2.0-rc.1", "v1.2.0", -1},
+		{"ReleaseAfterPreRelease", "v1.2.0", "v1.2.0-beta", 1},
+		{"BuildMetadataIgnored", "v1.2.0+abc", "v1.2.0", 0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := CompareVersions(tc.a, tc.b)
+			if result != tc.expected {
+				t.Errorf("CompareVersions(%q, %q) = %d, expected %d", tc.a, tc.b, result, tc.expected)
+			}
+		})
+	}
+}
+
+func TestIsReleaseVersion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"v1.2.3", true},
+		{"1.0.0", true},
+		{"v1.2.3-rc.1", true},
+		{"dev", false},
+		{"", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			if result := IsReleaseVersion(tc.input); result != tc.expected {
+				t.Errorf("IsReleaseVersion(%q) = %t, expected %t", tc.input, result, tc.expected)
+			}
+		})
+	}
+}
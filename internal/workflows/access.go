@@ -129,8 +129,9 @@ func discoverUsers(projectConfig *configs.ProjectConfig) ([]UserAccessInfo, erro
 	// Read public keys directory.
 	if entries, err := os.ReadDir(publicKeysDir); err == nil {
 		for _, entry := range entries {
-			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".pub") {
-				uuid := strings.TrimSuffix(entry.Name(), ".pub")
+			ext := filepath.Ext(entry.Name())
+			if !entry.IsDir() && (ext == ".pub" || ext == ".gpg") {
+				uuid := strings.TrimSuffix(entry.Name(), ext)
 				uuidSet[uuid] = true
 			}
 		}
@@ -172,7 +173,7 @@ func determineUserStatus(uuid, publicKeysDir, secretsDir string) UserStatus {
 	publicKeyPath := filepath.Join(publicKeysDir, uuid+".pub")
 	kanukaPath := filepath.Join(secretsDir, uuid+".kanuka")
 
-	hasPublicKey := fileExistsCheck(publicKeyPath)
+	hasPublicKey := fileExistsCheck(publicKeyPath) || fileExistsCheck(filepath.Join(publicKeysDir, uuid+".gpg"))
 	hasKanukaFile := fileExistsCheck(kanukaPath)
 
 	switch {
@@ -0,0 +1,92 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+)
+
+// ExportAuditOptions configures the audit export workflow.
+type ExportAuditOptions struct {
+	// Format is the export format: "csv" or "json".
+	Format string
+
+	// OutputPath is the file to write. Ignored if Output is set.
+	OutputPath string
+
+	// Output, if set, receives the export instead of a file at OutputPath,
+	// for example to write it to stdout.
+	Output io.Writer
+}
+
+// ExportAuditResult contains the outcome of an audit export.
+type ExportAuditResult struct {
+	// Format is the format the entries were written in.
+	Format audit.ExportFormat
+
+	// OutputPath is the file the entries were written to, or empty if they
+	// were written to Output.
+	OutputPath string
+
+	// EntryCount is the number of entries written.
+	EntryCount int
+}
+
+// ExportAudit writes every audit log entry, oldest first, in a format other
+// tools can ingest. A missing or empty audit log exports no entries.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrInvalidArguments if Format is not a supported format, or if
+// neither OutputPath nor Output is set.
+func ExportAudit(ctx context.Context, opts ExportAuditOptions) (*ExportAuditResult, error) {
+	format, err := audit.ParseExportFormat(opts.Format)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrInvalidArguments, err)
+	}
+	if opts.Output == nil && opts.OutputPath == "" {
+		return nil, fmt.Errorf("%w: no output given", kerrors.ErrInvalidArguments)
+	}
+
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	if configs.ProjectKanukaSettings.ProjectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+
+	entries, err := audit.ReadEntries()
+	if err != nil {
+		return nil, fmt.Errorf("reading audit log: %w", err)
+	}
+	audit.SortByTime(entries)
+
+	result := &ExportAuditResult{Format: format, EntryCount: len(entries)}
+
+	if opts.Output != nil {
+		if err := audit.Export(opts.Output, entries, format); err != nil {
+			return nil, fmt.Errorf("writing audit export: %w", err)
+		}
+		return result, nil
+	}
+
+	file, err := os.OpenFile(opts.OutputPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s: %w", opts.OutputPath, err)
+	}
+	if err := audit.Export(file, entries, format); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("writing audit export: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("writing audit export: %w", err)
+	}
+
+	result.OutputPath = opts.OutputPath
+	return result, nil
+}
@@ -0,0 +1,103 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+)
+
+// QueryAuditOptions configures the audit query workflow.
+type QueryAuditOptions struct {
+	// User filters entries by user email.
+	User string
+
+	// Operations filters entries by operation types (comma-separated).
+	Operations string
+
+	// Since filters entries at or after this time: an RFC3339 timestamp,
+	// a YYYY-MM-DD date, or a duration before now such as "7d" or "24h".
+	Since string
+
+	// Until filters entries at or before this time, in the same formats as
+	// Since. A YYYY-MM-DD date includes the whole day.
+	Until string
+}
+
+// QueryAuditResult contains the outcome of an audit query.
+type QueryAuditResult struct {
+	// Entries are the matching entries, oldest first.
+	Entries []audit.Entry
+
+	// TotalEntries is the number of entries in the log before filtering.
+	TotalEntries int
+}
+
+// QueryAudit reads the audit log and returns the entries matching the filters.
+//
+// A missing or empty audit log is not an error; it returns no entries.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrInvalidDateFormat if --since or --until cannot be parsed.
+func QueryAudit(ctx context.Context, opts QueryAuditOptions) (*QueryAuditResult, error) {
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	if configs.ProjectKanukaSettings.ProjectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+
+	filter := audit.QueryFilter{User: opts.User}
+
+	if opts.Operations != "" {
+		filter.Operations = strings.Split(opts.Operations, ",")
+	}
+
+	if err := setAuditTimeRange(&filter, opts.Since, opts.Until); err != nil {
+		return nil, err
+	}
+
+	entries, err := audit.ReadEntries()
+	if err != nil {
+		return nil, fmt.Errorf("reading audit log: %w", err)
+	}
+
+	return &QueryAuditResult{
+		Entries:      audit.FilterEntries(entries, filter),
+		TotalEntries: len(entries),
+	}, nil
+}
+
+// setAuditTimeRange parses --since and --until values into filter. A
+// YYYY-MM-DD date given as until includes the whole day.
+//
+// Returns ErrInvalidDateFormat if either value cannot be parsed.
+func setAuditTimeRange(filter *audit.QueryFilter, since, until string) error {
+	now := time.Now().UTC()
+
+	if since != "" {
+		t, err := audit.ParseQueryTime(since, now)
+		if err != nil {
+			return fmt.Errorf("%w: --since %v", kerrors.ErrInvalidDateFormat, err)
+		}
+		filter.Since = t
+	}
+
+	if until != "" {
+		t, err := audit.ParseQueryTime(until, now)
+		if err != nil {
+			return fmt.Errorf("%w: --until %v", kerrors.ErrInvalidDateFormat, err)
+		}
+		if _, dateErr := time.Parse("2006-01-02", strings.TrimSpace(until)); dateErr == nil {
+			// Include the entire day by setting to end of day.
+			t = t.Add(24*time.Hour - time.Nanosecond)
+		}
+		filter.Until = t
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+)
+
+// maxAuditOperationLength caps custom operation names so log output stays aligned.
+const maxAuditOperationLength = 64
+
+var (
+	auditOperationPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._:-]*$`)
+	auditDetailKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+)
+
+// RecordAuditOptions configures the audit record workflow.
+type RecordAuditOptions struct {
+	// Operation is the custom operation name, e.g. "deploy-prod".
+	Operation string
+
+	// Details holds arbitrary key/value pairs to attach to the entry.
+	Details map[string]string
+}
+
+// RecordAuditResult contains the outcome of an audit record operation.
+type RecordAuditResult struct {
+	// Entry is the entry that was appended to the audit log.
+	Entry audit.Entry
+}
+
+// RecordAudit appends a custom entry to the project's audit log.
+//
+// This lets external tooling annotate the audit log with its own milestones,
+// such as deployments, so the log becomes a shared timeline. The entry is
+// attributed to the current user and written through the same path as
+// Kānuka's own entries.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrInvalidAuditOperation if the name is malformed or is a built-in operation.
+// Returns ErrInvalidAuditDetail if a detail key is empty or malformed.
+func RecordAudit(ctx context.Context, opts RecordAuditOptions) (*RecordAuditResult, error) {
+	if err := ValidateAuditOperation(opts.Operation); err != nil {
+		return nil, err
+	}
+
+	for key := range opts.Details {
+		if !auditDetailKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("%w: key %q must contain only letters, digits, '_', '.', or '-'", kerrors.ErrInvalidAuditDetail, key)
+		}
+	}
+
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	if configs.ProjectKanukaSettings.ProjectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+
+	entry := audit.LogWithUser(opts.Operation)
+	if len(opts.Details) > 0 {
+		entry.Details = opts.Details
+	}
+
+	if err := audit.Write(entry); err != nil {
+		return nil, fmt.Errorf("recording audit entry: %w", err)
+	}
+
+	return &RecordAuditResult{Entry: entry}, nil
+}
+
+// ValidateAuditOperation checks that op is usable as a custom operation name.
+// Names must be lowercase, start with a letter or digit, contain only letters,
+// digits, '.', '_', ':', or '-', and must not shadow a built-in operation.
+func ValidateAuditOperation(op string) error {
+	switch {
+	case op == "":
+		return fmt.Errorf("%w: operation name is required", kerrors.ErrInvalidAuditOperation)
+	case len(op) > maxAuditOperationLength:
+		return fmt.Errorf("%w: %q is longer than %d characters", kerrors.ErrInvalidAuditOperation, op, maxAuditOperationLength)
+	case !auditOperationPattern.MatchString(op):
+		return fmt.Errorf("%w: %q must be lowercase letters, digits, '.', '_', ':', or '-'", kerrors.ErrInvalidAuditOperation, op)
+	case audit.IsBuiltinOperation(op):
+		return fmt.Errorf("%w: %q is a built-in operation and can only be recorded by Kānuka", kerrors.ErrInvalidAuditOperation, op)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+)
+
+// SummarizeAuditOptions configures the audit summary workflow.
+type SummarizeAuditOptions struct {
+	// Since limits the summary to entries at or after this time, in the
+	// same formats as QueryAuditOptions.Since.
+	Since string
+
+	// Until limits the summary to entries at or before this time. A
+	// YYYY-MM-DD date includes the whole day.
+	Until string
+}
+
+// SummarizeAudit reads the audit log and aggregates the entries in the
+// requested time range into counts per operation, user, and file.
+//
+// A missing or empty audit log is not an error; it returns empty stats.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrInvalidDateFormat if --since or --until cannot be parsed.
+func SummarizeAudit(ctx context.Context, opts SummarizeAuditOptions) (*audit.Stats, error) {
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	if configs.ProjectKanukaSettings.ProjectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+
+	var filter audit.QueryFilter
+	if err := setAuditTimeRange(&filter, opts.Since, opts.Until); err != nil {
+		return nil, err
+	}
+
+	entries, err := audit.ReadEntries()
+	if err != nil {
+		return nil, fmt.Errorf("reading audit log: %w", err)
+	}
+
+	return audit.Summary(audit.FilterEntries(entries, filter)), nil
+}
@@ -106,6 +106,12 @@ func CIInit(ctx context.Context, opts CIInitOptions) (*CIInitResult, error) {
 		return nil, kerrors.ErrProjectNotInitialized
 	}
 
+	lock, err := configs.LockProject()
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
 	// Check if TTY is available.
 	if !utils.IsTTYAvailable() {
 		return nil, kerrors.ErrTTYRequired
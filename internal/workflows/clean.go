@@ -119,7 +119,9 @@ func findOrphanedEntries(projectPath string) ([]OrphanEntry, error) {
 		uuid := strings.TrimSuffix(entry.Name(), ".kanuka")
 		publicKeyPath := filepath.Join(publicKeysDir, uuid+".pub")
 
-		if !fileExistsCheck(publicKeyPath) {
+		gpgKeyPath := filepath.Join(publicKeysDir, uuid+".gpg")
+
+		if !fileExistsCheck(publicKeyPath) && !fileExistsCheck(gpgKeyPath) {
 			orphanPath := filepath.Join(secretsDir, entry.Name())
 			relPath, _ := filepath.Rel(projectPath, orphanPath)
 
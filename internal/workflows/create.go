@@ -23,8 +23,14 @@ type CreateOptions struct {
 	// DeviceName is a custom device name (auto-generated from hostname if empty).
 	DeviceName string
 
-	// Force overwrites existing keys if true.
+	// Force deletes the user's existing public key and wrapped symmetric key
+	// in the project and regenerates the key pair. Callers should confirm
+	// first; see CreatePreCheckResult.ExistingKeyPaths.
 	Force bool
+
+	// KeyType is the algorithm of the new key pair: "rsa2048", "rsa4096", or
+	// "ed25519". Empty uses secrets.DefaultKeyAlgorithm.
+	KeyType string
 }
 
 // CreateResult contains the outcome of a create operation.
@@ -41,11 +47,18 @@ type CreateResult struct {
 	// PublicKeyPath is where the public key was saved in the project.
 	PublicKeyPath string
 
+	// PublicKeyReplaced indicates if an existing public key was deleted and
+	// replaced.
+	PublicKeyReplaced bool
+
 	// KanukaKeyDeleted indicates if an existing .kanuka key was removed.
 	KanukaKeyDeleted bool
 
 	// DeletedKanukaKeyPath is the path of the deleted key (if any).
 	DeletedKanukaKeyPath string
+
+	// KeyType is the algorithm of the generated key pair.
+	KeyType secrets.KeyAlgorithm
 }
 
 // CreatePreCheckResult contains information needed before prompting for email.
@@ -55,6 +68,10 @@ type CreatePreCheckResult struct {
 
 	// ExistingEmail is the email from the user config (if any).
 	ExistingEmail string
+
+	// ExistingKeyPaths lists the user's public key and wrapped symmetric key
+	// in the project, if present. Create with Force deletes them.
+	ExistingKeyPaths []string
 }
 
 // CreatePreCheck validates the project state before prompting for user input.
@@ -85,28 +102,56 @@ func CreatePreCheck(ctx context.Context) (*CreatePreCheckResult, error) {
 		return nil, fmt.Errorf("ensuring user config: %w", err)
 	}
 
+	var existingKeyPaths []string
+	for _, path := range userProjectKeyPaths(userConfig.User.UUID) {
+		if _, err := os.Stat(path); err == nil {
+			existingKeyPaths = append(existingKeyPaths, path)
+		}
+	}
+
 	return &CreatePreCheckResult{
-		NeedsEmail:    userConfig.User.Email == "",
-		ExistingEmail: userConfig.User.Email,
+		NeedsEmail:       userConfig.User.Email == "",
+		ExistingEmail:    userConfig.User.Email,
+		ExistingKeyPaths: existingKeyPaths,
 	}, nil
 }
 
-// Create creates a new RSA key pair for accessing the project's encrypted secrets.
+// userProjectKeyPaths returns the paths of a user's public key and wrapped
+// symmetric key in the project.
+func userProjectKeyPaths(userUUID string) []string {
+	return []string{
+		filepath.Join(configs.ProjectKanukaSettings.ProjectPublicKeyPath, userUUID+".pub"),
+		filepath.Join(configs.ProjectKanukaSettings.ProjectSecretsPath, userUUID+".kanuka"),
+	}
+}
+
+// Create creates a new key pair for accessing the project's encrypted secrets.
 //
 // This command generates a unique cryptographic identity for the user on this device,
 // identified by their email address. Each device gets its own key pair.
 //
 // The workflow:
-//  1. Generates an RSA key pair (stored locally in ~/.local/share/kanuka/keys/)
+//  1. Generates a key pair of type KeyType (stored locally in ~/.local/share/kanuka/keys/)
 //  2. Copies the public key to the project's .kanuka/public_keys/ directory
 //  3. Registers the device in the project configuration
 //
 // Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrProjectLocked if another operation holds the project lock.
 // Returns ErrInvalidProjectConfig if the project config is malformed.
 // Returns ErrInvalidEmail if the email format is invalid.
 // Returns ErrDeviceNameTaken if the device name is already in use.
 // Returns ErrPublicKeyExists if a public key already exists (unless Force is true).
+// Returns ErrInvalidArguments if KeyType is not a supported key type.
+//
+// With Force, the user's existing public key and wrapped symmetric key are
+// deleted before the new key pair is generated, so the user loses access
+// until someone registers the new key.
 func Create(ctx context.Context, opts CreateOptions) (*CreateResult, error) {
+	keyType, err := secrets.ParseKeyAlgorithm(opts.KeyType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrInvalidArguments, err)
+	}
+
 	if err := configs.InitProjectSettings(); err != nil {
 		return nil, fmt.Errorf("initializing project settings: %w", err)
 	}
@@ -121,6 +166,12 @@ func Create(ctx context.Context, opts CreateOptions) (*CreateResult, error) {
 		return nil, kerrors.ErrProjectNotInitialized
 	}
 
+	lock, err := configs.LockProject()
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
 	if err := secrets.EnsureUserSettings(); err != nil {
 		return nil, fmt.Errorf("ensuring user settings: %w", err)
 	}
@@ -159,15 +210,21 @@ func Create(ctx context.Context, opts CreateOptions) (*CreateResult, error) {
 		return nil, fmt.Errorf("loading project config: %w", err)
 	}
 
-	// Determine device name.
+	// Determine device name. When forcing over this device's own
+	// registration, its current name is kept rather than treated as taken.
 	existingDeviceNames := projectConfig.GetDeviceNamesByEmail(userEmail)
+	ownDevice, hasOwnDevice := projectConfig.Devices[userUUID]
+	keepOwnName := opts.Force && hasOwnDevice && ownDevice.Email == userEmail && ownDevice.Name != ""
 	var deviceName string
 
 	if opts.DeviceName != "" {
 		deviceName = utils.SanitizeDeviceName(opts.DeviceName)
-		if projectConfig.IsDeviceNameTakenByEmail(userEmail, deviceName) {
+		ownName := keepOwnName && deviceName == ownDevice.Name
+		if !ownName && projectConfig.IsDeviceNameTakenByEmail(userEmail, deviceName) {
 			return nil, fmt.Errorf("%w: %s", kerrors.ErrDeviceNameTaken, deviceName)
 		}
+	} else if keepOwnName {
+		deviceName = ownDevice.Name
 	} else {
 		deviceName, err = utils.GenerateDeviceName(existingDeviceNames)
 		if err != nil {
@@ -175,20 +232,37 @@ func Create(ctx context.Context, opts CreateOptions) (*CreateResult, error) {
 		}
 	}
 
+	keyPaths := userProjectKeyPaths(userUUID)
+	userPublicKeyPath, userKanukaKeyPath := keyPaths[0], keyPaths[1]
+
 	// Check for existing public key (unless force is set).
 	if !opts.Force {
-		projectPublicKeyPath := configs.ProjectKanukaSettings.ProjectPublicKeyPath
-		userPublicKeyPath := filepath.Join(projectPublicKeyPath, userUUID+".pub")
 		userPublicKey, _ := secrets.LoadPublicKey(userPublicKeyPath)
 		if userPublicKey != nil {
 			return nil, kerrors.ErrPublicKeyExists
 		}
 	}
 
-	// Create and save RSA key pair.
+	// Delete the existing registration before regenerating.
+	publicKeyReplaced := false
+	kanukaKeyDeleted := false
+	if opts.Force {
+		if err := os.Remove(userPublicKeyPath); err == nil {
+			publicKeyReplaced = true
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("deleting existing public key: %w", err)
+		}
+		if err := os.Remove(userKanukaKeyPath); err == nil {
+			kanukaKeyDeleted = true
+		} else if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("deleting existing symmetric key: %w", err)
+		}
+	}
+
+	// Create and save the key pair.
 	// The verbose parameter is false since logging is handled at the cmd layer.
-	if err := secrets.CreateAndSaveRSAKeyPair(false); err != nil {
-		return nil, fmt.Errorf("creating RSA key pair: %w", err)
+	if err := secrets.CreateAndSaveKeyPair(false, keyType); err != nil {
+		return nil, fmt.Errorf("creating %s key pair: %w", keyType, err)
 	}
 
 	// Copy user public key to project.
@@ -221,11 +295,7 @@ func Create(ctx context.Context, opts CreateOptions) (*CreateResult, error) {
 		return nil, fmt.Errorf("updating user config with project: %w", err)
 	}
 
-	// Remove existing kanuka key if present.
-	projectSecretsPath := configs.ProjectKanukaSettings.ProjectSecretsPath
-	userKanukaKeyPath := filepath.Join(projectSecretsPath, userUUID+".kanuka")
-	kanukaKeyDeleted := false
-
+	// Remove a stale kanuka key left without a public key.
 	if err := os.Remove(userKanukaKeyPath); err == nil {
 		kanukaKeyDeleted = true
 	}
@@ -240,7 +310,9 @@ func Create(ctx context.Context, opts CreateOptions) (*CreateResult, error) {
 		DeviceName:           deviceName,
 		UserUUID:             userUUID,
 		PublicKeyPath:        destPath,
+		PublicKeyReplaced:    publicKeyReplaced,
 		KanukaKeyDeleted:     kanukaKeyDeleted,
 		DeletedKanukaKeyPath: userKanukaKeyPath,
+		KeyType:              keyType,
 	}, nil
 }
@@ -1,9 +1,11 @@
 package workflows
 
 import (
+	"bytes"
 	"context"
-	"crypto/rsa"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -24,24 +26,86 @@ type DecryptOptions struct {
 	// PrivateKeyData contains the private key bytes when reading from stdin.
 	// If nil, the private key is loaded from disk.
 	PrivateKeyData []byte
+
+	// Output, if set, receives the plaintext instead of .env files on disk.
+	// Every file is decrypted before anything is written, so a failure never
+	// leaves partial plaintext in Output. DryRun must be false.
+	Output io.Writer
+
+	// Check compares each file's plaintext with the .env file on disk instead
+	// of writing anything, to detect .env files that weren't re-encrypted.
+	// DryRun and Output must not be set.
+	Check bool
+
+	// OnlyChanged skips .kanuka files that haven't changed since they were
+	// last decrypted on this machine, as recorded in .kanuka/.decrypt-state,
+	// as long as their .env file still exists. Output and Check must not be
+	// set.
+	OnlyChanged bool
+}
+
+// DriftStatus describes how a .env file compares with its .kanuka file.
+type DriftStatus string
+
+const (
+	// DriftInSync means the .env file matches the decrypted .kanuka file.
+	DriftInSync DriftStatus = "in_sync"
+
+	// DriftChanged means the .env file differs from the decrypted .kanuka file.
+	DriftChanged DriftStatus = "drifted"
+
+	// DriftNoPlaintext means there is no .env file to compare with.
+	DriftNoPlaintext DriftStatus = "no_plaintext"
+)
+
+// DecryptCheck is the outcome of checking one .kanuka file for drift.
+type DecryptCheck struct {
+	// Path is the .env file that was compared.
+	Path string `json:"path"`
+
+	// Status is how the .env file compares with the decrypted .kanuka file.
+	Status DriftStatus `json:"status"`
 }
 
 // DecryptResult contains the outcome of a decrypt operation.
 type DecryptResult struct {
-	// DecryptedFiles lists the .env files that were created.
-	DecryptedFiles []string
+	// DecryptedFiles lists the .env files that were created. It is empty
+	// when the plaintext was written to DecryptOptions.Output.
+	DecryptedFiles []string `json:"decrypted_files"`
 
 	// SourceFiles lists the .kanuka files that were decrypted.
-	SourceFiles []string
+	SourceFiles []string `json:"source_files"`
 
 	// ProjectPath is the root path of the project.
-	ProjectPath string
+	ProjectPath string `json:"project_path"`
 
 	// DryRun indicates whether this was a dry-run (no files modified).
-	DryRun bool
+	DryRun bool `json:"dry_run"`
+
+	// ExistingFiles lists files that already existed and were (or, in a
+	// dry-run, would be) overwritten.
+	ExistingFiles []string `json:"existing_files"`
+
+	// Created lists the .env files that did not exist before decrypting.
+	Created []string `json:"created"`
+
+	// Updated lists the .env files that existed and were overwritten. Together
+	// with Created it covers every file in DecryptedFiles.
+	Updated []string `json:"updated"`
 
-	// ExistingFiles lists files that already exist and would be overwritten.
-	ExistingFiles []string
+	// IgnoredFiles lists files and directories skipped because they matched
+	// a .kanuka/ignore pattern.
+	IgnoredFiles []FileMatchInfo `json:"ignored_files,omitempty"`
+
+	// Checks lists one entry per .kanuka file when DecryptOptions.Check is set.
+	Checks []DecryptCheck `json:"checks,omitempty"`
+
+	// Drifted is the number of Checks with DriftChanged.
+	Drifted int `json:"drifted,omitempty"`
+
+	// SkippedFiles lists the .kanuka files that OnlyChanged skipped because
+	// they haven't changed since they were last decrypted.
+	SkippedFiles []string `json:"skipped_files,omitempty"`
 }
 
 // Decrypt decrypts .kanuka files back to .env files.
@@ -51,10 +115,22 @@ type DecryptResult struct {
 // secretbox. The decrypted files are written alongside the encrypted files
 // with the .kanuka extension removed.
 //
+// The hash of each decrypted .kanuka file is recorded in the local
+// .kanuka/.decrypt-state, which .kanuka/.gitignore keeps out of git, so that
+// OnlyChanged can skip it until it changes.
+//
 // Returns ErrProjectNotInitialized if the project has no .kanuka directory.
 // Returns ErrNoAccess if the user doesn't have a key file for this project.
 // Returns ErrKeyDecryptFailed if the private key cannot decrypt the symmetric key.
+// Returns ErrGPGNotFound if the symmetric key was wrapped for a GPG key and gpg is not on PATH.
 // Returns ErrNoFilesFound if no .kanuka files match the specified patterns.
+// Returns ErrInvalidArguments if Output is set with DryRun, or Check is set
+// with either, or OnlyChanged is set with Output or Check.
+// Returns ErrDecryptFailed if Output or Check is set and a file can't be decrypted.
+//
+// When Output is set, failing to decrypt the symmetric key returns ErrNoAccess
+// as well as the underlying error, since the caller only needs to know the
+// plaintext is unavailable.
 func Decrypt(ctx context.Context, opts DecryptOptions) (*DecryptResult, error) {
 	if err := configs.InitProjectSettings(); err != nil {
 		return nil, fmt.Errorf("initializing project settings: %w", err)
@@ -65,7 +141,17 @@ func Decrypt(ctx context.Context, opts DecryptOptions) (*DecryptResult, error) {
 		return nil, kerrors.ErrProjectNotInitialized
 	}
 
-	kanukaFiles, err := resolveKanukaFiles(opts.FilePatterns, projectPath)
+	if opts.Output != nil && opts.DryRun {
+		return nil, fmt.Errorf("%w: a dry-run can't write plaintext to an output", kerrors.ErrInvalidArguments)
+	}
+	if opts.Check && (opts.DryRun || opts.Output != nil) {
+		return nil, fmt.Errorf("%w: a check can't be combined with a dry-run or an output", kerrors.ErrInvalidArguments)
+	}
+	if opts.OnlyChanged && (opts.Check || opts.Output != nil) {
+		return nil, fmt.Errorf("%w: only-changed can't be combined with a check or an output", kerrors.ErrInvalidArguments)
+	}
+
+	kanukaFiles, ignoredFiles, err := resolveKanukaFiles(opts.FilePatterns, projectPath)
 	if err != nil {
 		return nil, err
 	}
@@ -91,20 +177,72 @@ func Decrypt(ctx context.Context, opts DecryptOptions) (*DecryptResult, error) {
 		return nil, fmt.Errorf("%w: %v", kerrors.ErrNoAccess, err)
 	}
 
-	privateKey, err := loadPrivateKeyForDecrypt(opts.PrivateKeyData, projectUUID)
+	symKey, err := unwrapSymmetricKey(encryptedSymKey, opts.PrivateKeyData, projectUUID, loadPrivateKeyForDecrypt)
 	if err != nil {
+		if opts.Output != nil && !errors.Is(err, kerrors.ErrNoAccess) {
+			return nil, fmt.Errorf("%w: %w", kerrors.ErrNoAccess, err)
+		}
 		return nil, err
 	}
 
-	symKey, err := secrets.DecryptWithPrivateKey(encryptedSymKey, privateKey)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %v", kerrors.ErrKeyDecryptFailed, err)
+	if opts.Check {
+		checks, err := checkDrift(symKey, kanukaFiles)
+		if err != nil {
+			return nil, err
+		}
+		result := &DecryptResult{
+			SourceFiles:  kanukaFiles,
+			ProjectPath:  projectPath,
+			IgnoredFiles: ignoredFiles,
+			Checks:       checks,
+		}
+		for _, check := range checks {
+			if check.Status == DriftChanged {
+				result.Drifted++
+			}
+		}
+		return result, nil
 	}
 
+	if opts.Output != nil {
+		if err := decryptToWriter(symKey, kanukaFiles, opts.Output); err != nil {
+			return nil, err
+		}
+
+		auditEntry := audit.LogWithUser("decrypt")
+		auditEntry.Files = kanukaFiles
+		audit.Log(auditEntry)
+
+		return &DecryptResult{
+			SourceFiles:  kanukaFiles,
+			ProjectPath:  projectPath,
+			IgnoredFiles: ignoredFiles,
+		}, nil
+	}
+
+	state := loadDecryptState(projectPath)
+	hashes := make(map[string]string, len(kanukaFiles))
+	var toDecrypt, skipped []string
+	for _, f := range kanukaFiles {
+		hash, err := hashCiphertext(f)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", kerrors.ErrDecryptFailed, err)
+		}
+		hashes[f] = hash
+		if opts.OnlyChanged && state.unchanged(projectPath, f, hash) {
+			skipped = append(skipped, f)
+			continue
+		}
+		toDecrypt = append(toDecrypt, f)
+	}
+	kanukaFiles = toDecrypt
+
 	result := &DecryptResult{
-		SourceFiles: kanukaFiles,
-		ProjectPath: projectPath,
-		DryRun:      opts.DryRun,
+		SourceFiles:  kanukaFiles,
+		ProjectPath:  projectPath,
+		DryRun:       opts.DryRun,
+		IgnoredFiles: ignoredFiles,
+		SkippedFiles: skipped,
 	}
 
 	result.DecryptedFiles = make([]string, len(kanukaFiles))
@@ -112,8 +250,10 @@ func Decrypt(ctx context.Context, opts DecryptOptions) (*DecryptResult, error) {
 		result.DecryptedFiles[i] = strings.TrimSuffix(f, ".kanuka")
 	}
 
-	if opts.DryRun {
-		result.ExistingFiles = findExistingFiles(result.DecryptedFiles)
+	result.ExistingFiles = findExistingFiles(result.DecryptedFiles)
+	result.Created, result.Updated = splitCreatedUpdated(result.DecryptedFiles, result.ExistingFiles)
+
+	if opts.DryRun || len(kanukaFiles) == 0 {
 		return result, nil
 	}
 
@@ -121,6 +261,13 @@ func Decrypt(ctx context.Context, opts DecryptOptions) (*DecryptResult, error) {
 		return nil, fmt.Errorf("%w: %v", kerrors.ErrDecryptFailed, err)
 	}
 
+	// The state only lets later runs skip work, and an out-of-date state
+	// just decrypts a file again, so failing to save it is not an error.
+	for _, f := range kanukaFiles {
+		state.record(projectPath, f, hashes[f])
+	}
+	_ = state.save(projectPath)
+
 	auditEntry := audit.LogWithUser("decrypt")
 	auditEntry.Files = kanukaFiles
 	audit.Log(auditEntry)
@@ -128,25 +275,79 @@ func Decrypt(ctx context.Context, opts DecryptOptions) (*DecryptResult, error) {
 	return result, nil
 }
 
-// resolveKanukaFiles finds .kanuka files based on patterns or defaults to all .kanuka files.
-func resolveKanukaFiles(patterns []string, projectPath string) ([]string, error) {
+// decryptToWriter decrypts every file in memory, then writes the plaintexts
+// to w in order.
+func decryptToWriter(symKey []byte, kanukaFiles []string, w io.Writer) error {
+	plaintexts := make([][]byte, len(kanukaFiles))
+	for i, path := range kanukaFiles {
+		plaintext, err := secrets.ReadEncryptedFile(symKey, path)
+		if err != nil {
+			return fmt.Errorf("%w: %v", kerrors.ErrDecryptFailed, err)
+		}
+		plaintexts[i] = plaintext
+	}
+
+	for _, plaintext := range plaintexts {
+		if _, err := w.Write(plaintext); err != nil {
+			return fmt.Errorf("writing plaintext: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkDrift decrypts each file in memory and compares the plaintext with
+// the .env file next to it. Nothing is written.
+func checkDrift(symKey []byte, kanukaFiles []string) ([]DecryptCheck, error) {
+	checks := make([]DecryptCheck, len(kanukaFiles))
+	for i, path := range kanukaFiles {
+		envPath := strings.TrimSuffix(path, ".kanuka")
+		checks[i].Path = envPath
+
+		plaintext, err := secrets.ReadEncryptedFile(symKey, path)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", kerrors.ErrDecryptFailed, err)
+		}
+
+		onDisk, err := os.ReadFile(envPath)
+		switch {
+		case os.IsNotExist(err):
+			checks[i].Status = DriftNoPlaintext
+		case err != nil:
+			return nil, fmt.Errorf("reading %s: %w", envPath, err)
+		case bytes.Equal(onDisk, plaintext):
+			checks[i].Status = DriftInSync
+		default:
+			checks[i].Status = DriftChanged
+		}
+	}
+	return checks, nil
+}
+
+// resolveKanukaFiles finds .kanuka files based on patterns or defaults to all
+// .kanuka files whose .env file is not matched by .kanuka/ignore. Files named
+// by patterns are never ignored.
+func resolveKanukaFiles(patterns []string, projectPath string) ([]string, []FileMatchInfo, error) {
 	if len(patterns) > 0 {
 		resolved, err := secrets.ResolveFiles(patterns, projectPath, false)
 		if err != nil {
-			return nil, fmt.Errorf("resolving file patterns: %w", err)
+			return nil, nil, fmt.Errorf("resolving file patterns: %w", err)
 		}
-		return resolved, nil
+		return resolved, nil, nil
 	}
 
-	found, err := secrets.FindEnvOrKanukaFiles(projectPath, []string{}, true)
+	found, ignored, err := discoverFiles(projectPath, true, secrets.DiscoverOptions{})
 	if err != nil {
-		return nil, fmt.Errorf("finding encrypted files: %w", err)
+		return nil, nil, fmt.Errorf("finding encrypted files: %w", err)
 	}
-	return found, nil
+	return found, ignored, nil
 }
 
-// loadPrivateKeyForDecrypt loads the private key from bytes or from disk.
-func loadPrivateKeyForDecrypt(keyData []byte, projectUUID string) (*rsa.PrivateKey, error) {
+// loadPrivateKeyForDecrypt loads the private key from bytes, KANUKA_PRIVATE_KEY, or disk.
+func loadPrivateKeyForDecrypt(keyData []byte, projectUUID string) (secrets.PrivateKey, error) {
+	keyData, err := privateKeyDataOrEnv(keyData)
+	if err != nil {
+		return nil, err
+	}
 	if len(keyData) > 0 {
 		key, err := secrets.LoadPrivateKeyFromBytesWithTTYPrompt(keyData)
 		if err != nil {
@@ -157,6 +358,9 @@ func loadPrivateKeyForDecrypt(keyData []byte, projectUUID string) (*rsa.PrivateK
 
 	privateKeyPath := configs.GetPrivateKeyPath(projectUUID)
 	key, err := secrets.LoadPrivateKey(privateKeyPath)
+	if errors.Is(err, kerrors.ErrInsecureKeyPermissions) {
+		return nil, err
+	}
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", kerrors.ErrPrivateKeyNotFound, err)
 	}
@@ -164,6 +368,36 @@ func loadPrivateKeyForDecrypt(keyData []byte, projectUUID string) (*rsa.PrivateK
 	return key, nil
 }
 
+// privateKeyLoader loads the user's private key from keyData,
+// KANUKA_PRIVATE_KEY, or disk, returning the errors its workflow reports.
+type privateKeyLoader func(keyData []byte, projectUUID string) (secrets.PrivateKey, error)
+
+// unwrapSymmetricKey decrypts the user's wrapped symmetric key with
+// secrets.UnwrapSymmetricKey. The private key is loaded with loadKey only if
+// the key wasn't wrapped for a GPG key.
+func unwrapSymmetricKey(encryptedSymKey, privateKeyData []byte, projectUUID string, loadKey privateKeyLoader) ([]byte, error) {
+	return secrets.UnwrapSymmetricKey(encryptedSymKey, func() (secrets.PrivateKey, error) {
+		return loadKey(privateKeyData, projectUUID)
+	})
+}
+
+// splitCreatedUpdated splits targets, in order, into those not in existing
+// and those that are.
+func splitCreatedUpdated(targets, existing []string) (created, updated []string) {
+	existed := make(map[string]bool, len(existing))
+	for _, path := range existing {
+		existed[path] = true
+	}
+	for _, path := range targets {
+		if existed[path] {
+			updated = append(updated, path)
+		} else {
+			created = append(created, path)
+		}
+	}
+	return created, updated
+}
+
 // findExistingFiles returns which of the given paths already exist on disk.
 func findExistingFiles(paths []string) []string {
 	var existing []string
@@ -0,0 +1,91 @@
+package workflows
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+)
+
+// decryptStateFileName is the file in .kanuka that records the hash of each
+// .kanuka file as it was when it was last decrypted on this machine, so
+// decrypt --only-changed can skip files that haven't changed since. It is
+// local to each checkout and is never committed.
+const decryptStateFileName = ".decrypt-state"
+
+// decryptState is the contents of .kanuka/.decrypt-state.
+type decryptState struct {
+	// Files maps a .kanuka file's path, relative to the project root and
+	// with forward slashes, to the SHA-256 of its contents in hex.
+	Files map[string]string `toml:"files"`
+}
+
+func decryptStatePath(projectPath string) string {
+	return filepath.Join(projectPath, ".kanuka", decryptStateFileName)
+}
+
+// loadDecryptState reads the project's decrypt state. A missing or
+// unreadable state file is treated as empty, so every file is decrypted.
+func loadDecryptState(projectPath string) *decryptState {
+	state := &decryptState{}
+	if err := configs.LoadTOML(decryptStatePath(projectPath), state); err != nil {
+		state = &decryptState{}
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]string)
+	}
+	return state
+}
+
+// unchanged reports whether the .kanuka file at path still has the hash it
+// had when it was last decrypted, and its .env file is still there.
+func (s *decryptState) unchanged(projectPath, path, hash string) bool {
+	if s.Files[decryptStateKey(projectPath, path)] != hash {
+		return false
+	}
+	_, err := os.Stat(strings.TrimSuffix(path, ".kanuka"))
+	return err == nil
+}
+
+// record sets the hash of the .kanuka file at path.
+func (s *decryptState) record(projectPath, path, hash string) {
+	s.Files[decryptStateKey(projectPath, path)] = hash
+}
+
+// save writes the state to .kanuka/.decrypt-state and makes sure git ignores
+// it.
+func (s *decryptState) save(projectPath string) error {
+	if err := configs.SaveTOML(decryptStatePath(projectPath), s); err != nil {
+		return fmt.Errorf("saving decrypt state: %w", err)
+	}
+	return configs.IgnoreInKanukaDir(projectPath, decryptStateFileName)
+}
+
+func decryptStateKey(projectPath, path string) string {
+	rel, err := filepath.Rel(projectPath, path)
+	if err != nil {
+		rel = path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// hashCiphertext returns the SHA-256 of the file at path in hex.
+func hashCiphertext(path string) (string, error) {
+	// #nosec G304 -- the path is a .kanuka file resolved by the caller.
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,139 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+)
+
+// DiffOptions configures the diff workflow.
+type DiffOptions struct {
+	// FilePatterns specifies .env files to compare. If empty, all .env files are compared.
+	FilePatterns []string
+
+	// PrivateKeyData contains the private key bytes when reading from stdin.
+	// If nil, the private key is loaded from disk.
+	PrivateKeyData []byte
+}
+
+// FileDiff describes how a plaintext .env file differs from its .kanuka file.
+type FileDiff struct {
+	// Path is the .env file, relative to the project root.
+	Path string
+
+	// Encrypted is false if the .env file has no .kanuka counterpart yet, in
+	// which case every key is reported as added.
+	Encrypted bool
+
+	// Changes lists the added, removed, and modified keys.
+	Changes secrets.DotenvDiff
+}
+
+// DiffResult contains the outcome of a diff operation.
+type DiffResult struct {
+	// Files lists one entry per compared .env file, including unchanged ones.
+	Files []FileDiff
+
+	// ProjectPath is the root path of the project.
+	ProjectPath string
+}
+
+// Diff compares each .env file with its .kanuka counterpart and reports
+// which keys encrypt would add, remove, or modify.
+//
+// The .kanuka files are decrypted in memory only. Nothing is written to disk
+// and no audit entry is recorded.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrNoFilesFound if no .env files match the specified patterns.
+// Returns ErrNoAccess if the user doesn't have a key file for this project.
+// Returns ErrKeyDecryptFailed if the private key cannot decrypt the symmetric key.
+// Returns ErrGPGNotFound if the symmetric key was wrapped for a GPG key and gpg is not on PATH.
+// Returns ErrDecryptFailed if a .kanuka file cannot be decrypted.
+func Diff(ctx context.Context, opts DiffOptions) (*DiffResult, error) {
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	projectPath := configs.ProjectKanukaSettings.ProjectPath
+	if projectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+
+	envFiles, _, err := resolveEnvFiles(opts.FilePatterns, projectPath, secrets.DiscoverOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(envFiles) == 0 {
+		return nil, kerrors.ErrNoFilesFound
+	}
+
+	userConfig, err := configs.EnsureUserConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading user config: %w", err)
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+
+	encryptedSymKey, err := secrets.GetProjectKanukaKey(userConfig.User.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrNoAccess, err)
+	}
+
+	symKey, err := unwrapSymmetricKey(encryptedSymKey, opts.PrivateKeyData, projectConfig.Project.UUID, loadPrivateKeyForDecrypt)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DiffResult{
+		Files:       make([]FileDiff, 0, len(envFiles)),
+		ProjectPath: projectPath,
+	}
+
+	for _, envFile := range envFiles {
+		name := envFile
+		if rel, err := filepath.Rel(projectPath, envFile); err == nil {
+			name = filepath.ToSlash(rel)
+		}
+
+		plaintext, err := os.ReadFile(envFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", name, err)
+		}
+		newValues, err := secrets.ParseDotenv(plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+
+		fileDiff := FileDiff{Path: name}
+
+		var oldValues map[string]string
+		kanukaFile := envFile + ".kanuka"
+		if _, err := os.Stat(kanukaFile); err == nil {
+			fileDiff.Encrypted = true
+
+			decrypted, err := secrets.ReadEncryptedFile(symKey, kanukaFile)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s.kanuka: %v", kerrors.ErrDecryptFailed, name, err)
+			}
+			oldValues, err = secrets.ParseDotenv(decrypted)
+			if err != nil {
+				return nil, fmt.Errorf("parsing %s.kanuka: %w", name, err)
+			}
+		}
+
+		fileDiff.Changes = secrets.DiffDotenv(oldValues, newValues)
+		result.Files = append(result.Files, fileDiff)
+	}
+
+	return result, nil
+}
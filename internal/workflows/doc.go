@@ -28,6 +28,7 @@
 //   - Register: Registers a new user with an existing project
 //   - Revoke: Removes a user's access to a project
 //   - Rotate: Rotates the project's symmetric key
+//   - Reencrypt: Re-encrypts every .kanuka file with fresh nonces under the same key
 //
 // # Error Handling
 //
@@ -7,7 +7,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/PolarWolf314/kanuka/internal/audit"
 	"github.com/PolarWolf314/kanuka/internal/configs"
 	"github.com/PolarWolf314/kanuka/internal/secrets"
 	"github.com/PolarWolf314/kanuka/internal/utils"
@@ -80,6 +82,8 @@ type DoctorOptions struct {
 //   - Public key and encrypted symmetric key consistency
 //   - Gitignore configuration for .env files
 //   - Unencrypted .env files
+//   - Device and audit timestamps dated in the future (clock skew)
+//   - Devices whose register --expiry date has passed
 func Doctor(ctx context.Context, opts DoctorOptions) (*DoctorResult, error) {
 	// Run all health checks.
 	checks := []func() CheckResult{
@@ -91,6 +95,8 @@ func Doctor(ctx context.Context, opts DoctorOptions) (*DoctorResult, error) {
 		checkKanukaFileConsistency,
 		checkGitignore,
 		checkUnencryptedFiles,
+		checkFutureDatedTimestamps,
+		checkExpiredDevices,
 	}
 
 	var results []CheckResult
@@ -335,10 +341,11 @@ func checkPublicKeyConsistency() CheckResult {
 	// Check each public key has a corresponding .kanuka file.
 	var missingKanukaFiles []string
 	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".pub") {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".pub" && ext != ".gpg") {
 			continue
 		}
-		uuid := strings.TrimSuffix(entry.Name(), ".pub")
+		uuid := strings.TrimSuffix(entry.Name(), ext)
 		kanukaPath := filepath.Join(secretsDir, uuid+".kanuka")
 		if _, err := os.Stat(kanukaPath); os.IsNotExist(err) {
 			missingKanukaFiles = append(missingKanukaFiles, uuid)
@@ -402,7 +409,8 @@ func checkKanukaFileConsistency() CheckResult {
 		}
 		uuid := strings.TrimSuffix(entry.Name(), ".kanuka")
 		publicKeyPath := filepath.Join(publicKeysDir, uuid+".pub")
-		if _, err := os.Stat(publicKeyPath); os.IsNotExist(err) {
+		gpgKeyPath := filepath.Join(publicKeysDir, uuid+".gpg")
+		if !fileExistsCheck(publicKeyPath) && !fileExistsCheck(gpgKeyPath) {
 			orphanedKanukaFiles = append(orphanedKanukaFiles, uuid)
 		}
 	}
@@ -499,8 +507,17 @@ func checkUnencryptedFiles() CheckResult {
 		}
 	}
 
-	// Find all .env files.
-	envFiles, err := secrets.FindEnvOrKanukaFiles(projectPath, []string{}, false)
+	// Find all .env files, skipping those in .kanuka/ignore.
+	rules, err := secrets.LoadIgnoreRules(projectPath)
+	if err != nil {
+		return CheckResult{
+			Name:       "Unencrypted files",
+			Status:     CheckError,
+			Message:    fmt.Sprintf("Failed to load ignore patterns: %v", err),
+			Suggestion: "Fix the invalid pattern in .kanuka/ignore",
+		}
+	}
+	envFiles, err := secrets.FindEnvOrKanukaFiles(projectPath, []string{}, rules, false)
 	if err != nil {
 		return CheckResult{
 			Name:       "Unencrypted files",
@@ -540,6 +557,111 @@ func checkUnencryptedFiles() CheckResult {
 	}
 }
 
+// checkFutureDatedTimestamps checks for device records and audit entries dated
+// implausibly far in the future, which indicates a machine with a wrong clock.
+func checkFutureDatedTimestamps() CheckResult {
+	projectPath, err := utils.FindProjectKanukaRoot()
+	if err != nil || projectPath == "" {
+		return CheckResult{
+			Name:       "Timestamps",
+			Status:     CheckError,
+			Message:    "Kanuka project not found",
+			Suggestion: "Run 'kanuka secrets init' to initialize a project",
+		}
+	}
+
+	now := time.Now()
+	var problems []string
+
+	projectConfig := &configs.ProjectConfig{
+		Users:   make(map[string]string),
+		Devices: make(map[string]configs.DeviceConfig),
+	}
+	configPath := filepath.Join(projectPath, ".kanuka", "config.toml")
+	if err := configs.LoadTOML(configPath, projectConfig); err == nil {
+		if n := len(projectConfig.FutureDatedDevices(now)); n > 0 {
+			problems = append(problems, fmt.Sprintf("%d device(s)", n))
+		}
+	}
+
+	// #nosec G304 -- path is derived from the project root.
+	if data, err := os.ReadFile(filepath.Join(projectPath, ".kanuka", "audit.jsonl")); err == nil {
+		entries, _ := audit.ParseEntries(data)
+		futureEntries := 0
+		for _, e := range entries {
+			if e.IsFutureDated(now) {
+				futureEntries++
+			}
+		}
+		if futureEntries > 0 {
+			problems = append(problems, fmt.Sprintf("%d audit log entries", futureEntries))
+		}
+	}
+
+	if len(problems) > 0 {
+		return CheckResult{
+			Name:       "Timestamps",
+			Status:     CheckWarning,
+			Message:    "Found records dated in the future: " + strings.Join(problems, ", "),
+			Suggestion: "Check the system clock on machines that recently ran Kanuka",
+		}
+	}
+
+	return CheckResult{
+		Name:    "Timestamps",
+		Status:  CheckPass,
+		Message: "No records are dated in the future",
+	}
+}
+
+// checkExpiredDevices checks for devices that still have access after the
+// expiry date they were registered with.
+func checkExpiredDevices() CheckResult {
+	projectPath, err := utils.FindProjectKanukaRoot()
+	if err != nil || projectPath == "" {
+		return CheckResult{
+			Name:       "Device expiry",
+			Status:     CheckError,
+			Message:    "Kanuka project not found",
+			Suggestion: "Run 'kanuka secrets init' to initialize a project",
+		}
+	}
+
+	projectConfig := &configs.ProjectConfig{
+		Users:   make(map[string]string),
+		Devices: make(map[string]configs.DeviceConfig),
+	}
+	configPath := filepath.Join(projectPath, ".kanuka", "config.toml")
+	if err := configs.LoadTOML(configPath, projectConfig); err != nil {
+		return CheckResult{
+			Name:    "Device expiry",
+			Status:  CheckWarning,
+			Message: "Could not read device expiry dates from the project config",
+		}
+	}
+
+	var expired []string
+	for _, uuid := range projectConfig.ExpiredDevices(time.Now()) {
+		device := projectConfig.Devices[uuid]
+		expired = append(expired, fmt.Sprintf("%s (%s, expired %s)", device.Email, device.Name, device.ExpiresAt.Format("2006-01-02")))
+	}
+
+	if len(expired) > 0 {
+		return CheckResult{
+			Name:       "Device expiry",
+			Status:     CheckWarning,
+			Message:    fmt.Sprintf("%d device(s) have expired but still have access: %s", len(expired), strings.Join(expired, ", ")),
+			Suggestion: "Run 'kanuka secrets revoke --expired' to revoke expired devices",
+		}
+	}
+
+	return CheckResult{
+		Name:    "Device expiry",
+		Status:  CheckPass,
+		Message: "No devices have expired",
+	}
+}
+
 // getProjectUUID returns the project UUID from the project config.
 func getProjectUUID() string {
 	projectPath, err := utils.FindProjectKanukaRoot()
@@ -1,14 +1,19 @@
 package workflows
 
 import (
+	"bytes"
 	"context"
-	"crypto/rsa"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/PolarWolf314/kanuka/internal/audit"
 	"github.com/PolarWolf314/kanuka/internal/configs"
 	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/internal/utils"
 )
 
 // EncryptOptions configures the encrypt workflow.
@@ -22,21 +27,69 @@ type EncryptOptions struct {
 	// PrivateKeyData contains the private key bytes when reading from stdin.
 	// If nil, the private key is loaded from disk.
 	PrivateKeyData []byte
+
+	// Plaintext, if non-nil, is encrypted instead of files on disk, so
+	// secrets generated in memory never touch disk unencrypted.
+	// FilePatterns must be empty and PlaintextName set.
+	Plaintext []byte
+
+	// PlaintextName is the .env file name Plaintext is encrypted as, relative
+	// to the project root. The output is PlaintextName with .kanuka appended.
+	PlaintextName string
+
+	// Jobs is how many files are encrypted at once. If zero, it defaults to
+	// runtime.GOMAXPROCS(0).
+	Jobs int
+
+	// Discover limits which .env files are found when no FilePatterns are
+	// given, e.g. to the project root only or to names matching a glob.
+	Discover secrets.DiscoverOptions
+
+	// Backup copies each existing .kanuka file to <name>.kanuka.bak before
+	// it is overwritten, replacing any earlier backup.
+	Backup bool
+
+	// DeletePlaintext removes each .env file once its new .kanuka file has
+	// been read back and decrypted to the same contents. It can't be used
+	// with Plaintext.
+	DeletePlaintext bool
 }
 
 // EncryptResult contains the outcome of an encrypt operation.
 type EncryptResult struct {
 	// EncryptedFiles lists the .kanuka files that were created.
-	EncryptedFiles []string
+	EncryptedFiles []string `json:"encrypted_files"`
 
 	// SourceFiles lists the .env files that were encrypted.
-	SourceFiles []string
+	SourceFiles []string `json:"source_files"`
 
 	// ProjectPath is the root path of the project.
-	ProjectPath string
+	ProjectPath string `json:"project_path"`
 
 	// DryRun indicates whether this was a dry-run (no files modified).
-	DryRun bool
+	DryRun bool `json:"dry_run"`
+
+	// ExistingFiles lists .kanuka files that already existed and were overwritten.
+	ExistingFiles []string `json:"existing_files"`
+
+	// Created lists the .kanuka files that did not exist before encrypting.
+	Created []string `json:"created"`
+
+	// Updated lists the .kanuka files that existed and were overwritten.
+	// Together with Created it covers every file in EncryptedFiles.
+	Updated []string `json:"updated"`
+
+	// IgnoredFiles lists files and directories skipped because they matched
+	// a .kanuka/ignore pattern.
+	IgnoredFiles []FileMatchInfo `json:"ignored_files,omitempty"`
+
+	// BackupFiles lists the backups of overwritten .kanuka files, or in a
+	// dry run the backups that would be written.
+	BackupFiles []string `json:"backup_files,omitempty"`
+
+	// DeletedFiles lists the .env files removed by DeletePlaintext, or in a
+	// dry run the files that would be removed.
+	DeletedFiles []string `json:"deleted_files,omitempty"`
 }
 
 // Encrypt encrypts environment files using the project's symmetric key.
@@ -49,7 +102,22 @@ type EncryptResult struct {
 // Returns ErrProjectNotInitialized if the project has no .kanuka directory.
 // Returns ErrNoAccess if the user doesn't have a key file for this project.
 // Returns ErrKeyDecryptFailed if the private key cannot decrypt the symmetric key.
+// Returns ErrGPGNotFound if the symmetric key was wrapped for a GPG key and gpg is not on PATH.
 // Returns ErrNoFilesFound if no .env files match the specified patterns.
+// Returns ErrEncryptFailed if any file fails to encrypt. The other files are
+// still encrypted, and the error lists each failure in file order.
+// Returns ErrEncryptFailed without encrypting anything if Backup is set and
+// an existing .kanuka file can't be backed up.
+// Returns ErrEncryptFailed if DeletePlaintext is set and any .kanuka file does
+// not decrypt back to its .env file. Those .env files are kept, and no .env
+// file is deleted if any file failed to encrypt.
+// Returns ErrInvalidArguments if Jobs is negative, if Plaintext is set
+// without a PlaintextName, with FilePatterns, or with DeletePlaintext, if
+// PlaintextName is outside
+// the project, or if Discover is set with FilePatterns or Plaintext or has an
+// invalid NameFilter.
+// Returns ErrInvalidFileType if PlaintextName is not a .env file name.
+// Returns ErrFileNotFound if PlaintextName's directory does not exist.
 func Encrypt(ctx context.Context, opts EncryptOptions) (*EncryptResult, error) {
 	if err := configs.InitProjectSettings(); err != nil {
 		return nil, fmt.Errorf("initializing project settings: %w", err)
@@ -60,9 +128,38 @@ func Encrypt(ctx context.Context, opts EncryptOptions) (*EncryptResult, error) {
 		return nil, kerrors.ErrProjectNotInitialized
 	}
 
-	envFiles, err := resolveEnvFiles(opts.FilePatterns, projectPath)
-	if err != nil {
-		return nil, err
+	if opts.Jobs < 0 {
+		return nil, fmt.Errorf("%w: jobs must be at least 1, got %d", kerrors.ErrInvalidArguments, opts.Jobs)
+	}
+	if opts.Discover != (secrets.DiscoverOptions{}) && (len(opts.FilePatterns) > 0 || opts.Plaintext != nil) {
+		return nil, fmt.Errorf("%w: discovery options only apply when no files are given", kerrors.ErrInvalidArguments)
+	}
+	if opts.Discover.NameFilter != "" {
+		if err := secrets.ValidateNameFilter(opts.Discover.NameFilter); err != nil {
+			return nil, fmt.Errorf("%w: %v", kerrors.ErrInvalidArguments, err)
+		}
+	}
+
+	var envFiles []string
+	var ignoredFiles []FileMatchInfo
+	if opts.Plaintext != nil {
+		if len(opts.FilePatterns) > 0 {
+			return nil, fmt.Errorf("%w: files can't be given with in-memory plaintext", kerrors.ErrInvalidArguments)
+		}
+		if opts.DeletePlaintext {
+			return nil, fmt.Errorf("%w: in-memory plaintext has no file to delete", kerrors.ErrInvalidArguments)
+		}
+		envPath, err := resolvePlaintextName(opts.PlaintextName, projectPath)
+		if err != nil {
+			return nil, err
+		}
+		envFiles = []string{envPath}
+	} else {
+		var err error
+		envFiles, ignoredFiles, err = resolveEnvFiles(opts.FilePatterns, projectPath, opts.Discover)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if len(envFiles) == 0 {
@@ -86,65 +183,174 @@ func Encrypt(ctx context.Context, opts EncryptOptions) (*EncryptResult, error) {
 		return nil, fmt.Errorf("%w: %v", kerrors.ErrNoAccess, err)
 	}
 
-	privateKey, err := loadPrivateKey(opts.PrivateKeyData, projectUUID)
+	symKey, err := unwrapSymmetricKey(encryptedSymKey, opts.PrivateKeyData, projectUUID, loadPrivateKeyForDecrypt)
 	if err != nil {
 		return nil, err
 	}
 
-	symKey, err := secrets.DecryptWithPrivateKey(encryptedSymKey, privateKey)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %v", kerrors.ErrKeyDecryptFailed, err)
+	result := &EncryptResult{
+		SourceFiles:  envFiles,
+		ProjectPath:  projectPath,
+		DryRun:       opts.DryRun,
+		IgnoredFiles: ignoredFiles,
 	}
 
-	result := &EncryptResult{
-		SourceFiles: envFiles,
-		ProjectPath: projectPath,
-		DryRun:      opts.DryRun,
+	result.EncryptedFiles = make([]string, len(envFiles))
+	for i, f := range envFiles {
+		result.EncryptedFiles[i] = f + ".kanuka"
 	}
+	result.ExistingFiles = findExistingFiles(result.EncryptedFiles)
+	result.Created, result.Updated = splitCreatedUpdated(result.EncryptedFiles, result.ExistingFiles)
 
 	if opts.DryRun {
-		result.EncryptedFiles = make([]string, len(envFiles))
-		for i, f := range envFiles {
-			result.EncryptedFiles[i] = f + ".kanuka"
+		if opts.Backup {
+			for _, f := range result.ExistingFiles {
+				result.BackupFiles = append(result.BackupFiles, f+secrets.BackupSuffix)
+			}
+		}
+		if opts.DeletePlaintext {
+			result.DeletedFiles = envFiles
 		}
 		return result, nil
 	}
 
-	if err := secrets.EncryptFiles(symKey, envFiles, false); err != nil {
+	// Back up before anything is written, so a failed backup leaves every
+	// .kanuka file as it was.
+	if opts.Backup {
+		for _, f := range result.ExistingFiles {
+			backupPath, err := secrets.BackupEncryptedFile(f)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", kerrors.ErrEncryptFailed, err)
+			}
+			result.BackupFiles = append(result.BackupFiles, backupPath)
+		}
+	}
+
+	if opts.Plaintext != nil {
+		err = secrets.EncryptReader(symKey, bytes.NewReader(opts.Plaintext), result.EncryptedFiles[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", kerrors.ErrEncryptFailed, err)
+		}
+		logEncrypt(result.EncryptedFiles)
+		return result, nil
+	}
+
+	fileErrs, err := secrets.EncryptFilesConcurrently(symKey, envFiles, opts.Jobs)
+	if err != nil {
 		return nil, fmt.Errorf("%w: %v", kerrors.ErrEncryptFailed, err)
 	}
 
-	result.EncryptedFiles = make([]string, len(envFiles))
-	for i, f := range envFiles {
-		result.EncryptedFiles[i] = f + ".kanuka"
+	var written []string
+	var failures []error
+	for i, fileErr := range fileErrs {
+		if fileErr != nil {
+			failures = append(failures, fileErr)
+			continue
+		}
+		written = append(written, result.EncryptedFiles[i])
 	}
 
-	auditEntry := audit.LogWithUser("encrypt")
-	auditEntry.Files = result.EncryptedFiles
-	audit.Log(auditEntry)
+	// Record the files that were written even if others failed, since they
+	// are already on disk.
+	if len(written) > 0 {
+		logEncrypt(written)
+	}
+	if len(failures) > 0 {
+		return nil, fmt.Errorf("%w: %d of %d files failed: %w", kerrors.ErrEncryptFailed, len(failures), len(envFiles), errors.Join(failures...))
+	}
+
+	if opts.DeletePlaintext {
+		if err := removeVerifiedPlaintext(symKey, result); err != nil {
+			return nil, err
+		}
+	}
 
 	return result, nil
 }
 
-// resolveEnvFiles finds .env files based on patterns or defaults to all .env files.
-func resolveEnvFiles(patterns []string, projectPath string) ([]string, error) {
+// removeVerifiedPlaintext deletes each source .env file whose .kanuka file
+// decrypts back to it, recording them in result.DeletedFiles. Files that
+// fail verification are kept and reported together.
+func removeVerifiedPlaintext(symKey []byte, result *EncryptResult) error {
+	var kept []error
+	for i, envFile := range result.SourceFiles {
+		if err := secrets.RemoveVerifiedPlaintext(symKey, envFile, result.EncryptedFiles[i]); err != nil {
+			kept = append(kept, err)
+			continue
+		}
+		result.DeletedFiles = append(result.DeletedFiles, envFile)
+	}
+	if len(kept) > 0 {
+		return fmt.Errorf("%w: kept the plaintext of %d of %d files: %w", kerrors.ErrEncryptFailed, len(kept), len(result.SourceFiles), errors.Join(kept...))
+	}
+	return nil
+}
+
+// logEncrypt records the encrypted files in the audit log.
+func logEncrypt(files []string) {
+	auditEntry := audit.LogWithUser("encrypt")
+	auditEntry.Files = files
+	audit.Log(auditEntry)
+}
+
+// resolveEnvFiles finds .env files based on patterns or defaults to all .env
+// files not matched by .kanuka/ignore and allowed by discover. Files named by
+// patterns are never ignored.
+func resolveEnvFiles(patterns []string, projectPath string, discover secrets.DiscoverOptions) ([]string, []FileMatchInfo, error) {
 	if len(patterns) > 0 {
 		resolved, err := secrets.ResolveFiles(patterns, projectPath, true)
 		if err != nil {
-			return nil, fmt.Errorf("resolving file patterns: %w", err)
+			return nil, nil, fmt.Errorf("resolving file patterns: %w", err)
 		}
-		return resolved, nil
+		return resolved, nil, nil
 	}
 
-	found, err := secrets.FindEnvOrKanukaFiles(projectPath, []string{}, false)
+	found, ignored, err := discoverFiles(projectPath, false, discover)
 	if err != nil {
-		return nil, fmt.Errorf("finding environment files: %w", err)
+		return nil, nil, fmt.Errorf("finding environment files: %w", err)
 	}
-	return found, nil
+	return found, ignored, nil
 }
 
-// loadPrivateKey loads the private key from bytes or from disk.
-func loadPrivateKey(keyData []byte, projectUUID string) (*rsa.PrivateKey, error) {
+// resolvePlaintextName returns the absolute path of the .env file that
+// in-memory plaintext is encrypted as. The file itself is never created.
+func resolvePlaintextName(name, projectPath string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("%w: a file name is required for in-memory plaintext", kerrors.ErrInvalidArguments)
+	}
+
+	envPath := name
+	if !filepath.IsAbs(envPath) {
+		envPath = filepath.Join(projectPath, envPath)
+	}
+	envPath = filepath.Clean(envPath)
+
+	rel, err := filepath.Rel(projectPath, envPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s is outside the project", kerrors.ErrInvalidArguments, name)
+	}
+	if rel == ".kanuka" || strings.HasPrefix(rel, ".kanuka"+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s is inside the .kanuka directory", kerrors.ErrInvalidArguments, name)
+	}
+
+	base := filepath.Base(envPath)
+	if !strings.Contains(base, ".env") || strings.HasSuffix(base, ".kanuka") {
+		return "", fmt.Errorf("%w: %s is not a .env file name", kerrors.ErrInvalidFileType, name)
+	}
+
+	if info, err := os.Stat(filepath.Dir(envPath)); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%w: directory %s does not exist", kerrors.ErrFileNotFound, filepath.Dir(rel))
+	}
+
+	return envPath, nil
+}
+
+// loadPrivateKey loads the private key from bytes, KANUKA_PRIVATE_KEY, or disk.
+func loadPrivateKey(keyData []byte, projectUUID string) (secrets.PrivateKey, error) {
+	keyData, err := privateKeyDataOrEnv(keyData)
+	if err != nil {
+		return nil, err
+	}
 	if len(keyData) > 0 {
 		key, err := secrets.LoadPrivateKeyFromBytesWithTTYPrompt(keyData)
 		if err != nil {
@@ -155,9 +361,26 @@ func loadPrivateKey(keyData []byte, projectUUID string) (*rsa.PrivateKey, error)
 
 	privateKeyPath := configs.GetPrivateKeyPath(projectUUID)
 	key, err := secrets.LoadPrivateKey(privateKeyPath)
+	if errors.Is(err, kerrors.ErrInsecureKeyPermissions) {
+		return nil, err
+	}
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", kerrors.ErrPrivateKeyNotFound, err)
 	}
 
 	return key, nil
 }
+
+// privateKeyDataOrEnv returns keyData if set, otherwise the key from
+// KANUKA_PRIVATE_KEY. It returns nil if neither is set, meaning the key should
+// be loaded from the user's key directory.
+func privateKeyDataOrEnv(keyData []byte) ([]byte, error) {
+	if len(keyData) > 0 {
+		return keyData, nil
+	}
+	envKey, err := utils.PrivateKeyFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrPrivateKeyNotFound, err)
+	}
+	return envKey, nil
+}
@@ -2,6 +2,7 @@ package workflows
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"context"
 	"fmt"
@@ -24,6 +25,16 @@ type ExportOptions struct {
 	// OutputPath is the path for the output archive.
 	// If empty, defaults to kanuka-secrets-YYYY-MM-DD.tar.gz.
 	OutputPath string
+
+	// Output, if set, receives the archive instead of a file at OutputPath,
+	// for example to stream it to stdout. OutputPath then only names the
+	// destination in the result and audit log.
+	Output io.Writer
+
+	// Passphrase, if set, encrypts the whole archive with a key derived from it.
+	// This protects the config, public keys, and audit log, not just the
+	// secret values. The default output name gains a .enc suffix.
+	Passphrase []byte
 }
 
 // ExportResult contains the outcome of an export operation.
@@ -45,6 +56,9 @@ type ExportResult struct {
 
 	// OutputPath is the path to the created archive.
 	OutputPath string
+
+	// Encrypted indicates the archive was encrypted with a passphrase.
+	Encrypted bool
 }
 
 // Export creates a tar.gz archive containing all encrypted secrets for backup.
@@ -87,8 +101,11 @@ func Export(ctx context.Context, opts ExportOptions) (*ExportResult, error) {
 
 	// Determine output path.
 	outputPath := opts.OutputPath
-	if outputPath == "" {
+	if outputPath == "" && opts.Output == nil {
 		outputPath = fmt.Sprintf("kanuka-secrets-%s.tar.gz", time.Now().Format("2006-01-02"))
+		if len(opts.Passphrase) > 0 {
+			outputPath += ".enc"
+		}
 	}
 
 	// Collect files to archive.
@@ -97,19 +114,41 @@ func Export(ctx context.Context, opts ExportOptions) (*ExportResult, error) {
 		return nil, fmt.Errorf("collecting files for export: %w", err)
 	}
 	result.OutputPath = outputPath
+	result.Encrypted = len(opts.Passphrase) > 0
 
 	if result.TotalFilesCount == 0 {
 		return nil, kerrors.ErrNoFilesFound
 	}
 
 	// Create the archive.
-	if err := createTarGzArchive(outputPath, projectPath, filesToArchive); err != nil {
+	var archive bytes.Buffer
+	if err := writeTarGzArchive(&archive, projectPath, filesToArchive); err != nil {
 		return nil, fmt.Errorf("creating archive: %w", err)
 	}
 
+	archiveData := archive.Bytes()
+	if result.Encrypted {
+		archiveData, err = secrets.EncryptArchive(archiveData, opts.Passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("encrypting archive: %w", err)
+		}
+	}
+
+	if opts.Output != nil {
+		if _, err := opts.Output.Write(archiveData); err != nil {
+			return nil, fmt.Errorf("writing archive: %w", err)
+		}
+	} else {
+		// #nosec G306 -- The archive contains only encrypted secrets and public metadata.
+		if err := os.WriteFile(outputPath, archiveData, 0644); err != nil {
+			return nil, fmt.Errorf("writing archive: %w", err)
+		}
+	}
+
 	// Log to audit trail.
 	auditEntry := audit.LogWithUser("export")
 	auditEntry.OutputPath = outputPath
+	auditEntry.Encrypted = result.Encrypted
 	audit.Log(auditEntry)
 
 	return result, nil
@@ -152,7 +191,8 @@ func collectFilesToExport(projectPath string) (*ExportResult, []string, error) {
 	publicKeysDir := filepath.Join(kanukaDir, "public_keys")
 	if entries, err := os.ReadDir(publicKeysDir); err == nil {
 		for _, entry := range entries {
-			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".pub") {
+			ext := filepath.Ext(entry.Name())
+			if !entry.IsDir() && (ext == ".pub" || ext == ".gpg") {
 				files = append(files, filepath.Join(publicKeysDir, entry.Name()))
 				result.PublicKeyCount++
 			}
@@ -171,7 +211,7 @@ func collectFilesToExport(projectPath string) (*ExportResult, []string, error) {
 	}
 
 	// 4. Include all encrypted .kanuka secret files in the project.
-	secretFiles, err := secrets.FindEnvOrKanukaFiles(projectPath, []string{}, true)
+	secretFiles, err := secrets.FindEnvOrKanukaFiles(projectPath, []string{}, nil, true)
 	if err != nil {
 		return nil, nil, fmt.Errorf("finding secret files: %w", err)
 	}
@@ -182,19 +222,10 @@ func collectFilesToExport(projectPath string) (*ExportResult, []string, error) {
 	return result, files, nil
 }
 
-// createTarGzArchive creates a gzip-compressed tar archive containing the specified files.
-func createTarGzArchive(outputPath, projectPath string, files []string) error {
-	outFile, err := os.Create(outputPath)
-	if err != nil {
-		return fmt.Errorf("creating output file: %w", err)
-	}
-	defer outFile.Close()
-
-	gzWriter := gzip.NewWriter(outFile)
-	defer gzWriter.Close()
-
+// writeTarGzArchive writes a gzip-compressed tar archive containing the specified files to w.
+func writeTarGzArchive(w io.Writer, projectPath string, files []string) error {
+	gzWriter := gzip.NewWriter(w)
 	tarWriter := tar.NewWriter(gzWriter)
-	defer tarWriter.Close()
 
 	for _, filePath := range files {
 		if err := addFileToTar(tarWriter, projectPath, filePath); err != nil {
@@ -202,6 +233,13 @@ func createTarGzArchive(outputPath, projectPath string, files []string) error {
 		}
 	}
 
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("closing tar writer: %w", err)
+	}
+	if err := gzWriter.Close(); err != nil {
+		return fmt.Errorf("closing gzip writer: %w", err)
+	}
+
 	return nil
 }
 
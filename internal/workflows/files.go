@@ -0,0 +1,178 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+)
+
+// FilesOptions configures the files workflow.
+type FilesOptions struct {
+	// FilePatterns restricts discovery to the given files, directories, or globs,
+	// exactly as they would be passed to encrypt or decrypt.
+	FilePatterns []string
+
+	// Encrypted lists the .kanuka files decrypt would act on instead of the
+	// .env files encrypt would act on.
+	Encrypted bool
+
+	// Discover limits which .env files are found when no FilePatterns are
+	// given, exactly as EncryptOptions.Discover does. It can't be used with
+	// Encrypted, since decrypt always finds every .kanuka file.
+	Discover secrets.DiscoverOptions
+}
+
+// FileMatchInfo describes a discovered path and why it was matched or excluded.
+type FileMatchInfo struct {
+	// Path is the path relative to the project root.
+	Path string `json:"path"`
+
+	// Reason explains why the path was matched or excluded.
+	Reason string `json:"reason"`
+}
+
+// FilesResult contains the outcome of a files operation.
+type FilesResult struct {
+	// ProjectPath is the root path of the project.
+	ProjectPath string
+
+	// Matched lists the files that would be acted on.
+	Matched []FileMatchInfo
+
+	// Excluded lists candidate files and directories that were skipped.
+	Excluded []FileMatchInfo
+}
+
+// Files lists the files that encrypt (or decrypt) would act on, annotated with
+// why each file matched and which candidates were excluded.
+//
+// This is read-only and uses the same discovery code as encrypt and decrypt,
+// so its output always reflects what those commands would do.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrInvalidArguments if Discover is set along with FilePatterns or
+// Encrypted, or its NameFilter is not a valid glob.
+func Files(ctx context.Context, opts FilesOptions) (*FilesResult, error) {
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	projectPath := configs.ProjectKanukaSettings.ProjectPath
+	if projectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+
+	if opts.Discover != (secrets.DiscoverOptions{}) && (len(opts.FilePatterns) > 0 || opts.Encrypted) {
+		return nil, fmt.Errorf("%w: discovery options only apply when listing .env files without file arguments", kerrors.ErrInvalidArguments)
+	}
+	if opts.Discover.NameFilter != "" {
+		if err := secrets.ValidateNameFilter(opts.Discover.NameFilter); err != nil {
+			return nil, fmt.Errorf("%w: %v", kerrors.ErrInvalidArguments, err)
+		}
+	}
+
+	result := &FilesResult{
+		ProjectPath: projectPath,
+	}
+
+	if len(opts.FilePatterns) > 0 {
+		explainPatterns(result, opts.FilePatterns, projectPath, !opts.Encrypted)
+		return result, nil
+	}
+
+	rules, err := secrets.LoadIgnoreRules(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := secrets.ExplainEnvOrKanukaFiles(projectPath, []string{}, rules, opts.Encrypted, opts.Discover)
+	if err != nil {
+		return nil, fmt.Errorf("finding environment files: %w", err)
+	}
+
+	for _, m := range matches {
+		info := FileMatchInfo{
+			Path:   relativeToProject(projectPath, m.Path),
+			Reason: m.Reason,
+		}
+		if m.Matched {
+			result.Matched = append(result.Matched, info)
+		} else {
+			result.Excluded = append(result.Excluded, info)
+		}
+	}
+
+	return result, nil
+}
+
+// explainPatterns resolves each pattern individually so every match can be
+// attributed to the pattern that produced it.
+func explainPatterns(result *FilesResult, patterns []string, projectPath string, forEncryption bool) {
+	seen := make(map[string]bool)
+
+	for _, pattern := range patterns {
+		resolved, err := secrets.ResolveFiles([]string{pattern}, projectPath, forEncryption)
+		if err != nil {
+			result.Excluded = append(result.Excluded, FileMatchInfo{
+				Path:   pattern,
+				Reason: err.Error(),
+			})
+			continue
+		}
+
+		for _, f := range resolved {
+			if seen[f] {
+				continue
+			}
+			seen[f] = true
+			result.Matched = append(result.Matched, FileMatchInfo{
+				Path:   relativeToProject(projectPath, f),
+				Reason: fmt.Sprintf("matched pattern %q", pattern),
+			})
+		}
+	}
+}
+
+// discoverFiles finds the .env (or, if isKanuka, .kanuka) files in the project,
+// skipping those matched by .kanuka/ignore or excluded by discover. It also
+// returns the files and directories that .kanuka/ignore skipped, with the
+// pattern that skipped each.
+func discoverFiles(projectPath string, isKanuka bool, discover secrets.DiscoverOptions) ([]string, []FileMatchInfo, error) {
+	rules, err := secrets.LoadIgnoreRules(projectPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	matches, err := secrets.ExplainEnvOrKanukaFiles(projectPath, []string{}, rules, isKanuka, discover)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var found []string
+	var ignored []FileMatchInfo
+	for _, m := range matches {
+		switch {
+		case m.Matched:
+			found = append(found, m.Path)
+		case m.IgnorePattern != "":
+			ignored = append(ignored, FileMatchInfo{
+				Path:   relativeToProject(projectPath, m.Path),
+				Reason: m.Reason,
+			})
+		}
+	}
+	return found, ignored, nil
+}
+
+// relativeToProject returns path relative to the project root, or path unchanged if that fails.
+func relativeToProject(projectPath, path string) string {
+	rel, err := filepath.Rel(projectPath, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
@@ -0,0 +1,63 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// StageFilesResult contains the outcome of staging files in git.
+type StageFilesResult struct {
+	// Staged lists the files that were staged, relative to the project root.
+	Staged []string
+
+	// NotARepository is true if the project is not inside a git work tree
+	// (or git is not installed), in which case nothing was staged.
+	NotARepository bool
+}
+
+// StageFiles runs `git add` for the given files in the project's repository.
+//
+// It never commits. If the project is not in a git work tree, it does nothing
+// and reports NotARepository rather than returning an error.
+func StageFiles(ctx context.Context, projectPath string, files []string) (*StageFilesResult, error) {
+	result := &StageFilesResult{}
+
+	if !isGitWorkTree(ctx, projectPath) {
+		result.NotARepository = true
+		return result, nil
+	}
+
+	if len(files) == 0 {
+		return result, nil
+	}
+
+	relPaths := make([]string, len(files))
+	for i, f := range files {
+		rel, err := filepath.Rel(projectPath, f)
+		if err != nil {
+			rel = f
+		}
+		relPaths[i] = rel
+	}
+
+	args := append([]string{"-C", projectPath, "add", "--"}, relPaths...)
+	// #nosec G204 -- paths are files Kānuka just wrote, passed after "--".
+	cmd := exec.CommandContext(ctx, "git", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("git add: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	result.Staged = relPaths
+	return result, nil
+}
+
+// isGitWorkTree reports whether dir is inside a git work tree.
+// It returns false if git is not installed.
+func isGitWorkTree(ctx context.Context, dir string) bool {
+	cmd := exec.CommandContext(ctx, "git", "-C", dir, "rev-parse", "--is-inside-work-tree")
+	output, err := cmd.Output()
+	return err == nil && strings.TrimSpace(string(output)) == "true"
+}
@@ -2,11 +2,13 @@ package workflows
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -14,6 +16,8 @@ import (
 	"github.com/PolarWolf314/kanuka/internal/audit"
 	"github.com/PolarWolf314/kanuka/internal/configs"
 	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/bmatcuk/doublestar/v4"
 )
 
 // ImportMode represents the import strategy.
@@ -40,6 +44,18 @@ type ImportOptions struct {
 
 	// DryRun previews the import without making changes.
 	DryRun bool
+
+	// Passphrase decrypts archives created with export --encrypt-archive.
+	// It is ignored for unencrypted archives.
+	Passphrase []byte
+
+	// Only limits the imported secret files to archive members matching one
+	// of these glob patterns. Empty means every secret file.
+	Only []string
+
+	// Exclude skips secret files matching any of these glob patterns. A file
+	// matching both Only and Exclude is skipped.
+	Exclude []string
 }
 
 // ImportResult contains the outcome of an import operation.
@@ -53,6 +69,12 @@ type ImportResult struct {
 	// FilesReplaced is the count of files extracted (replace mode).
 	FilesReplaced int
 
+	// FilesFiltered is the count of files left out by Only or Exclude.
+	FilesFiltered int
+
+	// Files lists the archive members that were (or would be) extracted.
+	Files []string
+
 	// TotalFiles is the total number of files in the archive.
 	TotalFiles int
 
@@ -73,14 +95,43 @@ type ImportPreCheckResult struct {
 
 	// ProjectPath is the resolved project path.
 	ProjectPath string
+
+	// Encrypted indicates the archive was encrypted with a passphrase.
+	Encrypted bool
+}
+
+// IsEncryptedArchive reports whether the archive at archivePath was created
+// with export --encrypt-archive, so callers know to ask for a passphrase.
+//
+// Returns ErrFileNotFound if the archive doesn't exist.
+func IsEncryptedArchive(archivePath string) (bool, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, fmt.Errorf("%w: %s", kerrors.ErrFileNotFound, archivePath)
+		}
+		return false, fmt.Errorf("opening archive: %w", err)
+	}
+	defer file.Close()
+
+	header := make([]byte, 64)
+	n, err := io.ReadFull(file, header)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false, fmt.Errorf("reading archive: %w", err)
+	}
+	return secrets.IsEncryptedArchive(header[:n]), nil
 }
 
 // ImportPreCheck validates the archive and checks the project state.
+// The passphrase is only used if the archive is encrypted.
 //
 // Returns ErrFileNotFound if the archive doesn't exist.
+// Returns ErrArchivePassphraseRequired if the archive is encrypted and no passphrase was given.
+// Returns ErrArchiveDecryptFailed if the archive could not be decrypted.
 // Returns ErrInvalidFileType if the archive is not a valid gzip file.
-// Returns ErrInvalidArchive if the archive structure is invalid.
-func ImportPreCheck(ctx context.Context, archivePath string) (*ImportPreCheckResult, error) {
+// Returns ErrArchiveMissingConfig if the archive has no .kanuka/config.toml.
+// Returns ErrArchiveInvalid if the archive has no encrypted content.
+func ImportPreCheck(ctx context.Context, archivePath string, passphrase []byte) (*ImportPreCheckResult, error) {
 	// Check archive exists.
 	if _, err := os.Stat(archivePath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("%w: %s", kerrors.ErrFileNotFound, archivePath)
@@ -92,8 +143,13 @@ func ImportPreCheck(ctx context.Context, archivePath string) (*ImportPreCheckRes
 		return nil, fmt.Errorf("getting current directory: %w", err)
 	}
 
+	archiveData, encrypted, err := readArchive(archivePath, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate archive structure.
-	archiveFiles, err := listArchiveContents(archivePath)
+	archiveFiles, err := listArchiveContents(archiveData)
 	if err != nil {
 		if strings.Contains(err.Error(), "gzip") || strings.Contains(err.Error(), "invalid header") {
 			return nil, fmt.Errorf("%w: not a valid gzip archive", kerrors.ErrInvalidFileType)
@@ -102,7 +158,7 @@ func ImportPreCheck(ctx context.Context, archivePath string) (*ImportPreCheckRes
 	}
 
 	if err := validateArchiveStructure(archiveFiles); err != nil {
-		return nil, fmt.Errorf("%w: %v", kerrors.ErrInvalidArchive, err)
+		return nil, err
 	}
 
 	kanukaDir := filepath.Join(projectPath, ".kanuka")
@@ -115,6 +171,7 @@ func ImportPreCheck(ctx context.Context, archivePath string) (*ImportPreCheckRes
 		ArchiveFiles: archiveFiles,
 		KanukaExists: kanukaExists,
 		ProjectPath:  projectPath,
+		Encrypted:    encrypted,
 	}, nil
 }
 
@@ -126,10 +183,31 @@ func ImportPreCheck(ctx context.Context, archivePath string) (*ImportPreCheckRes
 //   - .kanuka/secrets/*.kanuka (encrypted symmetric keys)
 //   - *.kanuka files (encrypted secret files)
 //
+// Encrypted archives are decrypted in memory with opts.Passphrase.
+//
+// opts.Only and opts.Exclude select which secret files are extracted. They
+// are matched against archive member names, and a pattern matching a
+// directory covers everything below it. The .kanuka directory is always
+// extracted in full, since the secrets can't be decrypted without it.
+//
+// Returns ErrInvalidArguments if an Only or Exclude pattern is malformed.
 // Returns ErrFileNotFound if the archive doesn't exist.
+// Returns ErrProjectLocked if the project's lock is held by another operation.
+// Returns ErrArchivePassphraseRequired if the archive is encrypted and no passphrase was given.
+// Returns ErrArchiveDecryptFailed if the archive could not be decrypted.
 // Returns ErrInvalidFileType if the archive is not a valid gzip file.
-// Returns ErrInvalidArchive if the archive structure is invalid.
+// Returns ErrArchiveMissingConfig if the archive has no .kanuka/config.toml.
+// Returns ErrArchiveInvalid if the archive has no encrypted content or a
+// member path outside the project.
+// Returns ErrArchiveEmptyConfig if the extracted config.toml is empty, and
+// ErrInvalidProjectConfig if it isn't valid TOML. The extracted .kanuka
+// directory is removed in both cases.
 func Import(ctx context.Context, opts ImportOptions) (*ImportResult, error) {
+	filter := importFilter{only: opts.Only, exclude: opts.Exclude}
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+
 	projectPath := opts.ProjectPath
 	if projectPath == "" {
 		var err error
@@ -144,8 +222,13 @@ func Import(ctx context.Context, opts ImportOptions) (*ImportResult, error) {
 		return nil, fmt.Errorf("%w: %s", kerrors.ErrFileNotFound, opts.ArchivePath)
 	}
 
+	archiveData, encrypted, err := readArchive(opts.ArchivePath, opts.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Validate archive structure.
-	archiveFiles, err := listArchiveContents(opts.ArchivePath)
+	archiveFiles, err := listArchiveContents(archiveData)
 	if err != nil {
 		if strings.Contains(err.Error(), "gzip") || strings.Contains(err.Error(), "invalid header") {
 			return nil, fmt.Errorf("%w: not a valid gzip archive", kerrors.ErrInvalidFileType)
@@ -154,15 +237,35 @@ func Import(ctx context.Context, opts ImportOptions) (*ImportResult, error) {
 	}
 
 	if err := validateArchiveStructure(archiveFiles); err != nil {
-		return nil, fmt.Errorf("%w: %v", kerrors.ErrInvalidArchive, err)
+		return nil, err
+	}
+
+	// Importing into an existing project rewrites its config, so hold its lock.
+	locked := false
+	if !opts.DryRun {
+		if info, err := os.Stat(filepath.Join(projectPath, ".kanuka")); err == nil && info.IsDir() {
+			lock, err := configs.LockProjectAt(projectPath)
+			if err != nil {
+				return nil, err
+			}
+			defer lock.Unlock()
+			locked = true
+		}
 	}
 
 	// Perform import.
-	result, err := performImport(opts.ArchivePath, projectPath, archiveFiles, opts.Mode, opts.DryRun)
+	result, err := performImport(archiveData, projectPath, archiveFiles, opts.Mode, opts.DryRun, filter)
 	if err != nil {
 		return nil, err
 	}
 
+	// A replace import drops .kanuka/.gitignore, which listed the lock file.
+	if locked {
+		if err := configs.IgnoreInKanukaDir(projectPath, configs.ProjectLockFileName); err != nil {
+			return nil, err
+		}
+	}
+
 	// Log to audit trail (only if not dry-run).
 	if !opts.DryRun {
 		modeStr := "merge"
@@ -172,6 +275,7 @@ func Import(ctx context.Context, opts ImportOptions) (*ImportResult, error) {
 		auditEntry := audit.LogWithUser("import")
 		auditEntry.Mode = modeStr
 		auditEntry.FilesCount = result.TotalFiles
+		auditEntry.Encrypted = encrypted
 		audit.Log(auditEntry)
 	}
 
@@ -179,6 +283,8 @@ func Import(ctx context.Context, opts ImportOptions) (*ImportResult, error) {
 		FilesAdded:    result.FilesAdded,
 		FilesSkipped:  result.FilesSkipped,
 		FilesReplaced: result.FilesReplaced,
+		FilesFiltered: result.FilesFiltered,
+		Files:         result.Files,
 		TotalFiles:    result.TotalFiles,
 		DryRun:        opts.DryRun,
 		Mode:          opts.Mode,
@@ -190,18 +296,88 @@ type importResultInternal struct {
 	FilesAdded    int
 	FilesSkipped  int
 	FilesReplaced int
+	FilesFiltered int
+	Files         []string
 	TotalFiles    int
 }
 
-// listArchiveContents returns a list of all file paths in the archive.
-func listArchiveContents(archivePath string) ([]string, error) {
-	file, err := os.Open(archivePath)
+// importFilter selects which secret files an import extracts.
+type importFilter struct {
+	only    []string
+	exclude []string
+}
+
+// validate checks that every pattern is a valid glob.
+func (f importFilter) validate() error {
+	for _, pattern := range append(append([]string{}, f.only...), f.exclude...) {
+		if !doublestar.ValidatePattern(pattern) {
+			return fmt.Errorf("%w: invalid pattern %q", kerrors.ErrInvalidArguments, pattern)
+		}
+	}
+	return nil
+}
+
+// includes reports whether the archive member name should be extracted.
+// Members of the .kanuka directory are always extracted.
+func (f importFilter) includes(name string) bool {
+	if strings.HasPrefix(name, ".kanuka/") {
+		return true
+	}
+	for _, pattern := range f.exclude {
+		if matchesArchiveMember(pattern, name) {
+			return false
+		}
+	}
+	if len(f.only) == 0 {
+		return true
+	}
+	for _, pattern := range f.only {
+		if matchesArchiveMember(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesArchiveMember reports whether pattern matches name or one of the
+// directories containing it.
+func matchesArchiveMember(pattern, name string) bool {
+	for name != "." && name != "/" && name != "" {
+		if matched, _ := doublestar.Match(pattern, name); matched {
+			return true
+		}
+		name = path.Dir(name)
+	}
+	return false
+}
+
+// readArchive reads the archive at archivePath, decrypting it first if it was
+// created with export --encrypt-archive. The second return value reports
+// whether the archive was encrypted.
+func readArchive(archivePath string, passphrase []byte) ([]byte, bool, error) {
+	data, err := os.ReadFile(archivePath)
 	if err != nil {
-		return nil, fmt.Errorf("opening archive: %w", err)
+		return nil, false, fmt.Errorf("reading archive: %w", err)
+	}
+
+	if !secrets.IsEncryptedArchive(data) {
+		return data, false, nil
+	}
+
+	if len(passphrase) == 0 {
+		return nil, true, kerrors.ErrArchivePassphraseRequired
 	}
-	defer file.Close()
 
-	gzReader, err := gzip.NewReader(file)
+	decrypted, err := secrets.DecryptArchive(data, passphrase)
+	if err != nil {
+		return nil, true, fmt.Errorf("%w: %v", kerrors.ErrArchiveDecryptFailed, err)
+	}
+	return decrypted, true, nil
+}
+
+// listArchiveContents returns a list of all file paths in the archive.
+func listArchiveContents(archiveData []byte) ([]string, error) {
+	gzReader, err := gzip.NewReader(bytes.NewReader(archiveData))
 	if err != nil {
 		return nil, fmt.Errorf("creating gzip reader: %w", err)
 	}
@@ -242,41 +418,42 @@ func validateArchiveStructure(files []string) error {
 	}
 
 	if !hasConfig {
-		return fmt.Errorf("archive missing .kanuka/config.toml")
+		return kerrors.ErrArchiveMissingConfig
 	}
 
 	if !hasContent {
-		return fmt.Errorf("archive contains no encrypted content")
+		return fmt.Errorf("%w: archive contains no encrypted content", kerrors.ErrArchiveInvalid)
 	}
 
 	return nil
 }
 
 // performImport extracts files from the archive to the project directory.
-func performImport(archivePath, projectPath string, archiveFiles []string, mode ImportMode, dryRun bool) (*importResultInternal, error) {
+func performImport(archiveData []byte, projectPath string, archiveFiles []string, mode ImportMode, dryRun bool, filter importFilter) (*importResultInternal, error) {
 	result := &importResultInternal{
 		TotalFiles: len(archiveFiles),
 	}
 
 	kanukaDir := filepath.Join(projectPath, ".kanuka")
 
-	// For replace mode, delete existing .kanuka directory first.
+	// For replace mode, clear the existing .kanuka directory first. The lock
+	// file is kept, since Import holds it.
 	if mode == ImportModeReplace && !dryRun {
-		if _, err := os.Stat(kanukaDir); err == nil {
-			if err := os.RemoveAll(kanukaDir); err != nil {
+		entries, err := os.ReadDir(kanukaDir)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("reading existing .kanuka directory: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.Name() == configs.ProjectLockFileName {
+				continue
+			}
+			if err := os.RemoveAll(filepath.Join(kanukaDir, entry.Name())); err != nil {
 				return nil, fmt.Errorf("removing existing .kanuka directory: %w", err)
 			}
 		}
 	}
 
-	// Open archive for extraction.
-	file, err := os.Open(archivePath)
-	if err != nil {
-		return nil, fmt.Errorf("opening archive: %w", err)
-	}
-	defer file.Close()
-
-	gzReader, err := gzip.NewReader(file)
+	gzReader, err := gzip.NewReader(bytes.NewReader(archiveData))
 	if err != nil {
 		return nil, fmt.Errorf("creating gzip reader: %w", err)
 	}
@@ -298,6 +475,11 @@ func performImport(archivePath, projectPath string, archiveFiles []string, mode
 			continue
 		}
 
+		if !filter.includes(header.Name) {
+			result.FilesFiltered++
+			continue
+		}
+
 		// Validate path to prevent directory traversal attacks.
 		// #nosec G305 -- We validate the path below before using it.
 		targetPath := filepath.Join(projectPath, header.Name)
@@ -305,7 +487,7 @@ func performImport(archivePath, projectPath string, archiveFiles []string, mode
 		// Ensure the target path is within the project directory.
 		if !strings.HasPrefix(filepath.Clean(targetPath), filepath.Clean(projectPath)+string(os.PathSeparator)) &&
 			filepath.Clean(targetPath) != filepath.Clean(projectPath) {
-			return nil, fmt.Errorf("invalid file path in archive (path traversal attempt): %s", header.Name)
+			return nil, fmt.Errorf("%w: file path outside the project (path traversal attempt): %s", kerrors.ErrArchiveInvalid, header.Name)
 		}
 
 		// Check if file already exists (for merge mode).
@@ -321,14 +503,11 @@ func performImport(archivePath, projectPath string, archiveFiles []string, mode
 
 		if dryRun {
 			if mode == ImportModeMerge {
-				if fileExists {
-					result.FilesSkipped++
-				} else {
-					result.FilesAdded++
-				}
+				result.FilesAdded++
 			} else {
 				result.FilesReplaced++
 			}
+			result.Files = append(result.Files, header.Name)
 			continue
 		}
 
@@ -349,6 +528,7 @@ func performImport(archivePath, projectPath string, archiveFiles []string, mode
 		} else {
 			result.FilesReplaced++
 		}
+		result.Files = append(result.Files, header.Name)
 	}
 
 	// Validate extracted config.toml if not in dry-run mode.
@@ -377,12 +557,12 @@ func validateExtractedConfig(projectPath string) error {
 	}
 
 	if len(configContent) == 0 {
-		return fmt.Errorf("config.toml is empty")
+		return kerrors.ErrArchiveEmptyConfig
 	}
 
 	var decoded map[string]interface{}
 	if _, err := toml.Decode(string(configContent), &decoded); err != nil {
-		return fmt.Errorf("config.toml is invalid: %w", err)
+		return fmt.Errorf("%w: config.toml is invalid: %v", kerrors.ErrInvalidProjectConfig, err)
 	}
 
 	return nil
@@ -19,6 +19,11 @@ type InitOptions struct {
 	// ProjectName is the name for the project. If empty, uses the directory name.
 	ProjectName string
 
+	// Bare creates the .kanuka structure and project config without
+	// generating or registering a key for the current user. The project
+	// then has no symmetric key until its first user registers.
+	Bare bool
+
 	// Verbose enables verbose logging output.
 	Verbose bool
 }
@@ -26,22 +31,28 @@ type InitOptions struct {
 // InitResult contains the outcome of an init operation.
 type InitResult struct {
 	// ProjectName is the name of the initialized project.
-	ProjectName string
+	ProjectName string `json:"project_name"`
 
 	// ProjectUUID is the unique identifier assigned to the project.
-	ProjectUUID string
+	ProjectUUID string `json:"project_uuid"`
 
-	// DeviceName is the name assigned to this device for the project.
-	DeviceName string
+	// DeviceName is the name assigned to this device for the project. Empty
+	// for a bare project.
+	DeviceName string `json:"device_name"`
 
 	// ProjectPath is the root path of the project.
-	ProjectPath string
+	ProjectPath string `json:"project_path"`
+
+	// Bare indicates the project was created without any user keys.
+	Bare bool `json:"bare"`
 }
 
 // Init initializes a new Kānuka secrets store in the current directory.
 //
 // It creates the .kanuka directory structure, generates cryptographic keys,
-// and registers the current user as the first project member.
+// and registers the current user as the first project member. With Bare, only
+// the directory structure and project config are created; the user config is
+// neither read nor changed.
 //
 // Returns ErrProjectAlreadyInitialized if a .kanuka directory already exists.
 // Returns errors from key generation or configuration if they fail.
@@ -54,6 +65,10 @@ func Init(ctx context.Context, opts InitOptions) (*InitResult, error) {
 		return nil, kerrors.ErrProjectAlreadyInitialized
 	}
 
+	if opts.Bare {
+		return initBare(opts)
+	}
+
 	if err := secrets.EnsureUserSettings(); err != nil {
 		return nil, fmt.Errorf("ensuring user settings: %w", err)
 	}
@@ -155,6 +170,64 @@ func Init(ctx context.Context, opts InitOptions) (*InitResult, error) {
 	}, nil
 }
 
+// initBare creates the .kanuka structure and a project config with no users.
+func initBare(opts InitOptions) (*InitResult, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("getting working directory: %w", err)
+	}
+
+	projectName := opts.ProjectName
+	if projectName == "" {
+		projectName = filepath.Base(wd)
+	}
+
+	kanukaDir := filepath.Join(wd, ".kanuka")
+	cleanupNeeded := false
+	defer func() {
+		if cleanupNeeded {
+			os.RemoveAll(kanukaDir)
+		}
+	}()
+
+	if err := secrets.EnsureKanukaSettings(); err != nil {
+		return nil, fmt.Errorf("creating .kanuka folders: %w", err)
+	}
+	cleanupNeeded = true
+
+	projectConfig := &configs.ProjectConfig{
+		Project: configs.Project{
+			UUID: configs.GenerateProjectUUID(),
+			Name: projectName,
+		},
+		Users:   make(map[string]string),
+		Devices: make(map[string]configs.DeviceConfig),
+	}
+
+	configs.ProjectKanukaSettings.ProjectPath = wd
+	if err := configs.SaveProjectConfig(projectConfig); err != nil {
+		return nil, fmt.Errorf("saving project config: %w", err)
+	}
+
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	auditEntry := audit.LogWithUser("init")
+	auditEntry.ProjectName = projectName
+	auditEntry.ProjectUUID = projectConfig.Project.UUID
+	audit.Log(auditEntry)
+
+	cleanupNeeded = false
+
+	return &InitResult{
+		ProjectName: projectName,
+		ProjectUUID: projectConfig.Project.UUID,
+		ProjectPath: wd,
+		Bare:        true,
+	}, nil
+}
+
 // CheckUserConfigComplete checks if the user configuration has email and UUID set.
 func CheckUserConfigComplete() (bool, error) {
 	userConfig, err := configs.LoadUserConfig()
@@ -0,0 +1,128 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+)
+
+// ListedDevice is one of a user's registered devices.
+type ListedDevice struct {
+	// UUID is the device's user UUID.
+	UUID string `json:"uuid"`
+
+	// Name is the device name. Empty for users registered before devices were tracked.
+	Name string `json:"name"`
+
+	// CreatedAt is when the device was registered. Zero if unknown.
+	CreatedAt time.Time `json:"created_at"`
+
+	// Fingerprint is the fingerprint of the device's public key. Empty if it
+	// has no .pub public key in the project.
+	Fingerprint string `json:"fingerprint,omitempty"`
+}
+
+// ListedUser is a user and their registered devices.
+type ListedUser struct {
+	// Email is the user's email address.
+	Email string `json:"email"`
+
+	// Devices lists the user's devices, sorted by name.
+	Devices []ListedDevice `json:"devices"`
+}
+
+// ListUsersResult contains the outcome of a list operation.
+type ListUsersResult struct {
+	// ProjectName is the name of the project.
+	ProjectName string `json:"project"`
+
+	// Users lists registered users, sorted by email.
+	Users []ListedUser `json:"users"`
+}
+
+// ListUsers lists the users and devices registered in the project config.
+//
+// It only reads .kanuka/config.toml and the public keys, so it works without
+// decrypt access.
+// Unlike Access, it doesn't check which users have key files.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrInvalidProjectConfig if the project config is malformed.
+func ListUsers(ctx context.Context) (*ListUsersResult, error) {
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	if configs.ProjectKanukaSettings.ProjectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		if strings.Contains(err.Error(), "toml:") {
+			return nil, fmt.Errorf("%w: .kanuka/config.toml is not valid TOML", kerrors.ErrInvalidProjectConfig)
+		}
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+
+	projectName := projectConfig.Project.Name
+	if projectName == "" {
+		projectName = configs.ProjectKanukaSettings.ProjectName
+	}
+
+	devicesByEmail := make(map[string][]ListedDevice)
+	for uuid, device := range projectConfig.Devices {
+		devicesByEmail[device.Email] = append(devicesByEmail[device.Email], ListedDevice{
+			UUID:        uuid,
+			Name:        device.Name,
+			CreatedAt:   device.CreatedAt,
+			Fingerprint: projectPublicKeyFingerprint(uuid),
+		})
+	}
+
+	// Users registered before devices were tracked only appear in the Users map.
+	for uuid, email := range projectConfig.Users {
+		if _, ok := projectConfig.Devices[uuid]; !ok {
+			devicesByEmail[email] = append(devicesByEmail[email], ListedDevice{
+				UUID:        uuid,
+				Fingerprint: projectPublicKeyFingerprint(uuid),
+			})
+		}
+	}
+
+	users := make([]ListedUser, 0, len(devicesByEmail))
+	for email, devices := range devicesByEmail {
+		sort.Slice(devices, func(i, j int) bool {
+			if devices[i].Name != devices[j].Name {
+				return devices[i].Name < devices[j].Name
+			}
+			return devices[i].UUID < devices[j].UUID
+		})
+		users = append(users, ListedUser{Email: email, Devices: devices})
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Email < users[j].Email
+	})
+
+	return &ListUsersResult{
+		ProjectName: projectName,
+		Users:       users,
+	}, nil
+}
+
+// projectPublicKeyFingerprint returns the fingerprint of uuid's public key in
+// the project, or an empty string if it has none or it can't be read.
+func projectPublicKeyFingerprint(uuid string) string {
+	publicKey, err := secrets.LoadPublicKey(filepath.Join(configs.ProjectKanukaSettings.ProjectPublicKeyPath, uuid+".pub"))
+	if err != nil {
+		return ""
+	}
+	return publicKeyFingerprint(publicKey)
+}
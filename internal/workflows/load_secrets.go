@@ -0,0 +1,110 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+)
+
+// LoadSecretsOptions configures the load secrets workflow.
+type LoadSecretsOptions struct {
+	// FilePatterns specifies files to load. If empty, all .kanuka files are loaded.
+	FilePatterns []string
+
+	// PrivateKeyData contains the private key bytes.
+	// If nil, KANUKA_PRIVATE_KEY or the private key on disk is used.
+	PrivateKeyData []byte
+}
+
+// LoadSecretsResult contains the decrypted secrets.
+type LoadSecretsResult struct {
+	// Secrets maps each decrypted file, relative to the project root and
+	// without the .kanuka extension (e.g. "services/api/.env"), to its
+	// key/value pairs.
+	Secrets map[string]map[string]string
+
+	// ProjectPath is the root path of the project.
+	ProjectPath string
+}
+
+// LoadSecrets decrypts .kanuka files in memory and parses them as dotenv.
+//
+// It is the read-only counterpart of Decrypt for programs that load their
+// configuration from Kanuka at startup: nothing is written to disk, so no
+// plaintext .env files are created and no audit entry is recorded.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrNoAccess if the user doesn't have a key file for this project.
+// Returns ErrKeyDecryptFailed if the private key cannot decrypt the symmetric key.
+// Returns ErrGPGNotFound if the symmetric key was wrapped for a GPG key and gpg is not on PATH.
+// Returns ErrNoFilesFound if no .kanuka files match the specified patterns.
+// Returns ErrDecryptFailed if a file cannot be decrypted.
+func LoadSecrets(ctx context.Context, opts LoadSecretsOptions) (*LoadSecretsResult, error) {
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	projectPath := configs.ProjectKanukaSettings.ProjectPath
+	if projectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+
+	kanukaFiles, _, err := resolveKanukaFiles(opts.FilePatterns, projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(kanukaFiles) == 0 {
+		return nil, kerrors.ErrNoFilesFound
+	}
+
+	userConfig, err := configs.LoadUserConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading user config: %w", err)
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+
+	encryptedSymKey, err := secrets.GetProjectKanukaKey(userConfig.User.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrNoAccess, err)
+	}
+
+	symKey, err := unwrapSymmetricKey(encryptedSymKey, opts.PrivateKeyData, projectConfig.Project.UUID, loadPrivateKeyForDecrypt)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LoadSecretsResult{
+		Secrets:     make(map[string]map[string]string, len(kanukaFiles)),
+		ProjectPath: projectPath,
+	}
+
+	for _, kanukaFile := range kanukaFiles {
+		name := strings.TrimSuffix(kanukaFile, ".kanuka")
+		if rel, err := filepath.Rel(projectPath, name); err == nil {
+			name = filepath.ToSlash(rel)
+		}
+
+		plaintext, err := secrets.ReadEncryptedFile(symKey, kanukaFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", kerrors.ErrDecryptFailed, name, err)
+		}
+
+		values, err := secrets.ParseDotenv(plaintext)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		result.Secrets[name] = values
+	}
+
+	return result, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -40,6 +41,10 @@ type LogResult struct {
 
 	// TotalEntriesBeforeFilter is the count of entries before filtering.
 	TotalEntriesBeforeFilter int
+
+	// FutureDatedEntries is the count of returned entries dated implausibly far
+	// in the future, which usually means they were written with a wrong clock.
+	FutureDatedEntries int
 }
 
 // Log reads and filters the audit log.
@@ -87,6 +92,10 @@ func Log(ctx context.Context, opts LogOptions) (*LogResult, error) {
 		return result, nil
 	}
 
+	// Entries from merged branches or machines with skewed clocks may be out of
+	// order in the file, so order them by time before filtering and limiting.
+	audit.SortByTime(entries)
+
 	// Apply filters.
 	filtered := entries
 
@@ -138,6 +147,13 @@ func Log(ctx context.Context, opts LogOptions) (*LogResult, error) {
 		}
 	}
 
+	now := time.Now()
+	for _, e := range filtered {
+		if e.IsFutureDated(now) {
+			result.FutureDatedEntries++
+		}
+	}
+
 	result.Entries = filtered
 	return result, nil
 }
@@ -173,11 +189,7 @@ func filterByOperations(entries []audit.Entry, ops []string) []audit.Entry {
 func filterSince(entries []audit.Entry, since time.Time) []audit.Entry {
 	var result []audit.Entry
 	for _, e := range entries {
-		t, err := time.Parse("2006-01-02T15:04:05.000000Z", e.Timestamp)
-		if err != nil {
-			// Try alternate format.
-			t, err = time.Parse(time.RFC3339, e.Timestamp)
-		}
+		t, err := audit.ParseTimestamp(e.Timestamp)
 		if err != nil {
 			continue
 		}
@@ -192,11 +204,7 @@ func filterSince(entries []audit.Entry, since time.Time) []audit.Entry {
 func filterUntil(entries []audit.Entry, until time.Time) []audit.Entry {
 	var result []audit.Entry
 	for _, e := range entries {
-		t, err := time.Parse("2006-01-02T15:04:05.000000Z", e.Timestamp)
-		if err != nil {
-			// Try alternate format.
-			t, err = time.Parse(time.RFC3339, e.Timestamp)
-		}
+		t, err := audit.ParseTimestamp(e.Timestamp)
 		if err != nil {
 			continue
 		}
@@ -209,10 +217,7 @@ func filterUntil(entries []audit.Entry, until time.Time) []audit.Entry {
 
 // FormatDate formats a timestamp string to YYYY-MM-DD format.
 func FormatDate(ts string) string {
-	t, err := time.Parse("2006-01-02T15:04:05.000000Z", ts)
-	if err != nil {
-		t, err = time.Parse(time.RFC3339, ts)
-	}
+	t, err := audit.ParseTimestamp(ts)
 	if err != nil {
 		if len(ts) >= 10 {
 			return ts[:10]
@@ -224,10 +229,7 @@ func FormatDate(ts string) string {
 
 // FormatDateTime formats a timestamp string to YYYY-MM-DD HH:MM:SS format.
 func FormatDateTime(ts string) string {
-	t, err := time.Parse("2006-01-02T15:04:05.000000Z", ts)
-	if err != nil {
-		t, err = time.Parse(time.RFC3339, ts)
-	}
+	t, err := audit.ParseTimestamp(ts)
 	if err != nil {
 		if len(ts) >= 19 {
 			return ts[:19]
@@ -248,29 +250,36 @@ func FormatDetails(e audit.Entry) string {
 			return fmt.Sprintf("%d files", len(e.Files))
 		}
 		return strings.Join(e.Files, ", ")
-	case "register":
+	case "register", "rekey":
 		return e.TargetUser
 	case "revoke":
 		if e.Device != "" {
 			return fmt.Sprintf("%s (%s)", e.TargetUser, e.Device)
 		}
 		return e.TargetUser
-	case "sync":
+	case "sync", "rekey-all":
 		return fmt.Sprintf("%d users, %d files", e.UsersCount, e.FilesCount)
 	case "rotate":
 		return ""
+	case "reencrypt":
+		return fmt.Sprintf("%d files", e.FilesCount)
 	case "clean":
 		return fmt.Sprintf("removed %d entries", e.RemovedCount)
+	case "prune":
+		return fmt.Sprintf("removed %d files", e.RemovedCount)
 	case "import":
 		return fmt.Sprintf("%s, %d files", e.Mode, e.FilesCount)
 	case "export":
+		if e.Encrypted {
+			return e.OutputPath + " (encrypted)"
+		}
 		return e.OutputPath
 	case "init":
 		return e.ProjectName
 	case "create":
 		return e.DeviceName
 	default:
-		return ""
+		return formatCustomDetails(e.Details, ", ")
 	}
 }
 
@@ -282,28 +291,48 @@ func FormatDetailsOneline(e audit.Entry) string {
 			return ""
 		}
 		return fmt.Sprintf("%d files", len(e.Files))
-	case "register":
+	case "register", "rekey":
 		return e.TargetUser
 	case "revoke":
 		if e.Device != "" {
 			return fmt.Sprintf("%s (%s)", e.TargetUser, e.Device)
 		}
 		return e.TargetUser
-	case "sync":
+	case "sync", "rekey-all":
 		return fmt.Sprintf("%d users, %d files", e.UsersCount, e.FilesCount)
 	case "rotate":
 		return ""
-	case "clean":
+	case "reencrypt":
+		return fmt.Sprintf("%d files", e.FilesCount)
+	case "clean", "prune":
 		return fmt.Sprintf("removed %d", e.RemovedCount)
 	case "import":
 		return fmt.Sprintf("%s %d files", e.Mode, e.FilesCount)
 	case "export":
+		if e.Encrypted {
+			return e.OutputPath + " (encrypted)"
+		}
 		return e.OutputPath
 	case "init":
 		return e.ProjectName
 	case "create":
 		return e.DeviceName
 	default:
-		return ""
+		return formatCustomDetails(e.Details, " ")
+	}
+}
+
+// formatCustomDetails formats the details of a custom entry as sorted key=value pairs.
+func formatCustomDetails(details map[string]string, sep string) string {
+	keys := make([]string, 0, len(details))
+	for k := range details {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + details[k]
 	}
+	return strings.Join(pairs, sep)
 }
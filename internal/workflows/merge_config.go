@@ -0,0 +1,225 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+)
+
+const (
+	// MergeDriverName is the git merge driver name registered for the project config.
+	MergeDriverName = "kanuka-config"
+
+	// mergeDriverCommand is the command git runs to merge the project config.
+	mergeDriverCommand = "kanuka secrets merge-config --base %O --ours %A --theirs %B"
+
+	// mergeDriverAttribute is the .gitattributes line that routes the config to the driver.
+	mergeDriverAttribute = ".kanuka/config.toml merge=" + MergeDriverName
+)
+
+// MergeConfigOptions configures the merge-config workflow.
+type MergeConfigOptions struct {
+	// BasePath is the common ancestor config. If empty, ours and theirs are unioned.
+	BasePath string
+
+	// OursPath is our side of the merge.
+	OursPath string
+
+	// TheirsPath is their side of the merge.
+	TheirsPath string
+
+	// OutputPath is where the merged config is written. Defaults to OursPath,
+	// which is what git expects from a merge driver.
+	OutputPath string
+}
+
+// MergeConfigResult contains the outcome of a merge-config operation.
+type MergeConfigResult struct {
+	// OutputPath is where the merged config was written.
+	OutputPath string
+
+	// UsersCount is the number of users in the merged config.
+	UsersCount int
+
+	// DevicesCount is the number of devices in the merged config.
+	DevicesCount int
+
+	// Conflicts lists keys changed incompatibly on both sides. Our value is
+	// kept for each conflicting key.
+	Conflicts []configs.MergeConflict
+}
+
+// MergeConfig merges two versions of .kanuka/config.toml structurally.
+//
+// Users and devices are merged per UUID so that concurrent registrations on
+// different branches are all preserved, while revocations on either branch
+// are honored. The merged config is written even when conflicts are found,
+// so it can be inspected and fixed by hand.
+//
+// Each input is migrated to the current schema before merging, and the merged
+// config is written with the current config_version.
+//
+// Returns ErrFileNotFound if any of the input files does not exist.
+// Returns ErrInvalidProjectConfig if any of the input files is not valid TOML.
+// Returns ErrUnsupportedConfigVersion if any of the input files was written by
+// a newer version of Kanuka.
+// Returns ErrProjectLocked if the output is a project config whose lock is
+// held by another operation.
+func MergeConfig(ctx context.Context, opts MergeConfigOptions) (*MergeConfigResult, error) {
+	outputPath := opts.OutputPath
+	if outputPath == "" {
+		outputPath = opts.OursPath
+	}
+
+	// Run by hand, the merge can rewrite a project's config in place, so it
+	// takes that project's lock before reading it. Run by git, the output is a
+	// temporary file and there is nothing to lock.
+	if projectPath, ok := projectOfConfigFile(outputPath); ok {
+		lock, err := configs.LockProjectAt(projectPath)
+		if err != nil {
+			return nil, err
+		}
+		defer lock.Unlock()
+	}
+
+	ours, err := loadProjectConfigFile(opts.OursPath)
+	if err != nil {
+		return nil, err
+	}
+
+	theirs, err := loadProjectConfigFile(opts.TheirsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var base *configs.ProjectConfig
+	if opts.BasePath != "" {
+		base, err = loadProjectConfigFile(opts.BasePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	merged, conflicts := configs.MergeProjectConfigs(base, ours, theirs)
+
+	if err := configs.SaveTOML(outputPath, merged); err != nil {
+		return nil, fmt.Errorf("writing merged config: %w", err)
+	}
+
+	return &MergeConfigResult{
+		OutputPath:   outputPath,
+		UsersCount:   len(merged.Users),
+		DevicesCount: len(merged.Devices),
+		Conflicts:    conflicts,
+	}, nil
+}
+
+// projectOfConfigFile returns the project directory if path is a project's
+// .kanuka/config.toml.
+func projectOfConfigFile(path string) (string, bool) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	kanukaDir := filepath.Dir(absPath)
+	if filepath.Base(absPath) != "config.toml" || filepath.Base(kanukaDir) != ".kanuka" {
+		return "", false
+	}
+	return filepath.Dir(kanukaDir), true
+}
+
+// loadProjectConfigFile loads a project config from an arbitrary path and
+// migrates it to the current schema.
+func loadProjectConfigFile(path string) (*configs.ProjectConfig, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", kerrors.ErrFileNotFound, path)
+	}
+
+	config := &configs.ProjectConfig{
+		Users:   make(map[string]string),
+		Devices: make(map[string]configs.DeviceConfig),
+	}
+	if err := configs.LoadTOML(path, config); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", kerrors.ErrInvalidProjectConfig, path, err)
+	}
+	if err := configs.MigrateConfig(config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return config, nil
+}
+
+// InstallMergeDriverResult contains the outcome of installing the merge driver.
+type InstallMergeDriverResult struct {
+	// GitattributesPath is the path of the .gitattributes file that was checked.
+	GitattributesPath string
+
+	// GitattributesUpdated is true if the merge attribute was added.
+	GitattributesUpdated bool
+}
+
+// InstallMergeDriver registers merge-config as a git merge driver for the
+// project's .kanuka/config.toml.
+//
+// It sets the driver in the repository's local git config (which is not
+// committed, so each clone must run this once) and adds the merge attribute
+// to the project's .gitattributes (which should be committed).
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+func InstallMergeDriver(ctx context.Context) (*InstallMergeDriverResult, error) {
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	projectPath := configs.ProjectKanukaSettings.ProjectPath
+	if projectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+
+	gitSettings := [][]string{
+		{"merge." + MergeDriverName + ".name", "Kanuka project config merge driver"},
+		{"merge." + MergeDriverName + ".driver", mergeDriverCommand},
+	}
+	for _, setting := range gitSettings {
+		// #nosec G204 -- arguments are fixed strings, not user input.
+		cmd := exec.CommandContext(ctx, "git", "-C", projectPath, "config", "--local", setting[0], setting[1])
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("setting git config %s: %v: %s", setting[0], err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	result := &InstallMergeDriverResult{
+		GitattributesPath: filepath.Join(projectPath, ".gitattributes"),
+	}
+
+	existing, err := os.ReadFile(result.GitattributesPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading .gitattributes: %w", err)
+	}
+
+	for _, line := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(line) == mergeDriverAttribute {
+			return result, nil
+		}
+	}
+
+	content := string(existing)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += mergeDriverAttribute + "\n"
+
+	// #nosec G306 -- .gitattributes is committed and not sensitive.
+	if err := os.WriteFile(result.GitattributesPath, []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("writing .gitattributes: %w", err)
+	}
+	result.GitattributesUpdated = true
+
+	return result, nil
+}
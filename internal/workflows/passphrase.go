@@ -0,0 +1,132 @@
+package workflows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/internal/utils"
+)
+
+// PassphraseStatusResult describes the current user's private key for this project.
+type PassphraseStatusResult struct {
+	// PrivateKeyPath is where the private key is stored.
+	PrivateKeyPath string
+
+	// Encrypted is true if the private key is passphrase-protected.
+	Encrypted bool
+}
+
+// PassphraseStatus reports whether the private key for this project is
+// passphrase-protected, so callers know whether to ask for the current one.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrPrivateKeyNotFound if the private key file cannot be read.
+// Returns ErrInvalidPrivateKey if the private key cannot be parsed.
+func PassphraseStatus(ctx context.Context) (*PassphraseStatusResult, error) {
+	privateKeyPath, keyData, err := readProjectPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = secrets.ParsePrivateKeyBytes(keyData)
+	if err != nil && !errors.Is(err, secrets.ErrPassphraseRequired) {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrInvalidPrivateKey, err)
+	}
+
+	return &PassphraseStatusResult{
+		PrivateKeyPath: privateKeyPath,
+		Encrypted:      errors.Is(err, secrets.ErrPassphraseRequired),
+	}, nil
+}
+
+// ChangePassphraseOptions configures the change passphrase workflow.
+type ChangePassphraseOptions struct {
+	// CurrentPassphrase unlocks the existing key. Ignored if the key is not encrypted.
+	CurrentPassphrase []byte
+
+	// NewPassphrase protects the rewritten key. Empty removes the passphrase.
+	NewPassphrase []byte
+}
+
+// ChangePassphraseResult contains the outcome of a change passphrase operation.
+type ChangePassphraseResult struct {
+	// PrivateKeyPath is where the private key was rewritten.
+	PrivateKeyPath string
+
+	// Removed is true if the key was written without a passphrase.
+	Removed bool
+}
+
+// ChangePassphrase adds, changes, or removes the passphrase on the current
+// user's private key for this project.
+//
+// The key is rewritten in OpenSSH format through a temporary file and a
+// rename, so a crash leaves either the old or the new key intact. The file
+// keeps 0600 permissions.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrPrivateKeyNotFound if the private key file cannot be read.
+// Returns ErrIncorrectPassphrase if the current passphrase is missing or wrong.
+// Returns ErrInvalidPrivateKey if the private key cannot be parsed.
+func ChangePassphrase(ctx context.Context, opts ChangePassphraseOptions) (*ChangePassphraseResult, error) {
+	privateKeyPath, keyData, err := readProjectPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse without the passphrase first: the ssh package rejects a passphrase
+	// for an unencrypted key.
+	privateKey, err := secrets.ParsePrivateKeyBytes(keyData)
+	if errors.Is(err, secrets.ErrPassphraseRequired) && len(opts.CurrentPassphrase) > 0 {
+		privateKey, err = secrets.ParsePrivateKeyBytesWithPassphrase(keyData, opts.CurrentPassphrase)
+	}
+	if err != nil {
+		if errors.Is(err, secrets.ErrPassphraseRequired) {
+			return nil, kerrors.ErrIncorrectPassphrase
+		}
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrInvalidPrivateKey, err)
+	}
+
+	encoded, err := secrets.MarshalPrivateKeyOpenSSH(privateKey, opts.NewPassphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := utils.WriteFileAtomic(privateKeyPath, encoded, 0600); err != nil {
+		return nil, fmt.Errorf("writing private key: %w", err)
+	}
+
+	return &ChangePassphraseResult{
+		PrivateKeyPath: privateKeyPath,
+		Removed:        len(opts.NewPassphrase) == 0,
+	}, nil
+}
+
+// readProjectPrivateKey reads the current user's private key file for this project.
+func readProjectPrivateKey() (string, []byte, error) {
+	if err := configs.InitProjectSettings(); err != nil {
+		return "", nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	if configs.ProjectKanukaSettings.ProjectPath == "" {
+		return "", nil, kerrors.ErrProjectNotInitialized
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return "", nil, fmt.Errorf("loading project config: %w", err)
+	}
+
+	privateKeyPath := configs.GetPrivateKeyPath(projectConfig.Project.UUID)
+	keyData, err := os.ReadFile(privateKeyPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", kerrors.ErrPrivateKeyNotFound, err)
+	}
+
+	return privateKeyPath, keyData, nil
+}
@@ -0,0 +1,288 @@
+package workflows
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/internal/utils"
+)
+
+// CreatePendingKeyOptions configures the create --no-register workflow.
+type CreatePendingKeyOptions struct {
+	// KeyType is the algorithm of the new key pair: "rsa2048", "rsa4096", or
+	// "ed25519". Empty uses secrets.DefaultKeyAlgorithm.
+	KeyType string
+
+	// Force replaces an existing pending key pair.
+	Force bool
+}
+
+// CreatePendingKeyResult contains the outcome of generating a pending key.
+type CreatePendingKeyResult struct {
+	// UserUUID is the user's unique identifier, which an admin registers the
+	// public key under.
+	UserUUID string
+
+	// Email is the email from the user config, if any.
+	Email string
+
+	// PrivateKeyPath is where the pending private key was saved.
+	PrivateKeyPath string
+
+	// PublicKeyPath is where the pending public key was saved.
+	PublicKeyPath string
+
+	// PublicKeyPEM is the PEM-encoded public key, to hand to an admin.
+	PublicKeyPEM []byte
+
+	// Fingerprint is the public key's fingerprint, for checking out of band
+	// that the admin registered the right key.
+	Fingerprint string
+
+	// KeyType is the algorithm of the generated key pair.
+	KeyType secrets.KeyAlgorithm
+}
+
+// CreatePendingKey generates a key pair that doesn't belong to any project
+// yet, so it can be made before the project is reachable. The key pair is
+// saved under ~/.local/share/kanuka/keys/pending/ and no project is needed.
+//
+// An admin registers the public key with register --file <uuid>.pub, and the
+// user then promotes it into the project by running register there with no
+// flags (RegisterModePending).
+//
+// Returns ErrInvalidArguments if KeyType is not a supported key type.
+// Returns ErrPublicKeyExists if a pending key already exists and Force is not
+// set.
+func CreatePendingKey(ctx context.Context, opts CreatePendingKeyOptions) (*CreatePendingKeyResult, error) {
+	keyType, err := secrets.ParseKeyAlgorithm(opts.KeyType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrInvalidArguments, err)
+	}
+
+	if err := secrets.EnsureUserSettings(); err != nil {
+		return nil, fmt.Errorf("ensuring user settings: %w", err)
+	}
+
+	userConfig, err := configs.EnsureUserConfig()
+	if err != nil {
+		return nil, fmt.Errorf("ensuring user config: %w", err)
+	}
+
+	privateKeyPath := configs.GetPendingPrivateKeyPath()
+	publicKeyPath := configs.GetPendingPublicKeyPath()
+	if !opts.Force && fileExistsForWorkflow(privateKeyPath) {
+		return nil, fmt.Errorf("%w: a pending key already exists at %s", kerrors.ErrPublicKeyExists, privateKeyPath)
+	}
+
+	if err := secrets.GenerateKeyPair(privateKeyPath, publicKeyPath, keyType); err != nil {
+		return nil, fmt.Errorf("creating %s key pair: %w", keyType, err)
+	}
+
+	// #nosec G304 -- the path is the pending key written above.
+	publicKeyPEM, err := os.ReadFile(publicKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading pending public key: %w", err)
+	}
+	publicKey, err := secrets.LoadPublicKey(publicKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading pending public key: %w", err)
+	}
+
+	return &CreatePendingKeyResult{
+		UserUUID:       userConfig.User.UUID,
+		Email:          userConfig.User.Email,
+		PrivateKeyPath: privateKeyPath,
+		PublicKeyPath:  publicKeyPath,
+		PublicKeyPEM:   publicKeyPEM,
+		Fingerprint:    publicKeyFingerprint(publicKey),
+		KeyType:        keyType,
+	}, nil
+}
+
+// HasPendingKey reports whether a key pair made with create --no-register is
+// waiting to be promoted into a project.
+func HasPendingKey() bool {
+	return fileExistsForWorkflow(configs.GetPendingPrivateKeyPath())
+}
+
+// registerPendingKey promotes the pending key pair into the current project:
+// it becomes the user's key for this project, its public key is added to
+// .kanuka/public_keys/ if an admin hasn't added it already, and this machine
+// is recorded as one of the user's devices. The user has access once someone
+// has wrapped the symmetric key for them.
+func registerPendingKey(ctx context.Context, opts RegisterOptions) (*RegisterResult, error) {
+	if err := secrets.EnsureUserSettings(); err != nil {
+		return nil, fmt.Errorf("ensuring user settings: %w", err)
+	}
+
+	userConfig, err := configs.EnsureUserConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading user config: %w", err)
+	}
+	userUUID := userConfig.User.UUID
+
+	pendingPrivateKeyPath := configs.GetPendingPrivateKeyPath()
+	pendingPublicKeyPath := configs.GetPendingPublicKeyPath()
+	if !fileExistsForWorkflow(pendingPrivateKeyPath) {
+		return nil, fmt.Errorf("%w: no pending key at %s; run kanuka secrets create --no-register first", kerrors.ErrPrivateKeyNotFound, pendingPrivateKeyPath)
+	}
+	pendingPublicKey, err := secrets.LoadPublicKey(pendingPublicKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("loading pending public key: %w", err)
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+	projectUUID := projectConfig.Project.UUID
+
+	userEmail := opts.UserEmail
+	if userEmail == "" {
+		userEmail = projectConfig.Users[userUUID]
+	}
+	if userEmail == "" {
+		userEmail = userConfig.User.Email
+	}
+	if !utils.IsValidEmail(userEmail) {
+		return nil, fmt.Errorf("%w: %q", kerrors.ErrInvalidEmail, userEmail)
+	}
+
+	if fileExistsForWorkflow(configs.GetPrivateKeyPath(projectUUID)) {
+		return nil, fmt.Errorf("%w: you already have a key for this project at %s", kerrors.ErrPublicKeyExists, configs.GetKeyDirPath(projectUUID))
+	}
+
+	pubKeyFilePath := filepath.Join(configs.ProjectKanukaSettings.ProjectPublicKeyPath, userUUID+".pub")
+	kanukaFilePath := filepath.Join(configs.ProjectKanukaSettings.ProjectSecretsPath, userUUID+".kanuka")
+
+	pubkeyExisted := fileExistsForWorkflow(pubKeyFilePath)
+	if pubkeyExisted {
+		projectPublicKey, err := secrets.LoadPublicKey(pubKeyFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("loading public key from project: %w", err)
+		}
+		same, err := samePublicKey(projectPublicKey, pendingPublicKey)
+		if err != nil {
+			return nil, err
+		}
+		if !same {
+			return nil, fmt.Errorf("%w: %s holds a different key than your pending key", kerrors.ErrPublicKeyExists, pubKeyFilePath)
+		}
+	}
+
+	deviceName := projectConfig.Devices[userUUID].Name
+	if deviceName == "" {
+		if opts.DeviceName != "" {
+			deviceName = utils.SanitizeDeviceName(opts.DeviceName)
+			if projectConfig.IsDeviceNameTakenByEmail(userEmail, deviceName) {
+				return nil, fmt.Errorf("%w: %s", kerrors.ErrDeviceNameTaken, deviceName)
+			}
+		} else {
+			deviceName, err = utils.GenerateDeviceName(projectConfig.GetDeviceNamesByEmail(userEmail))
+			if err != nil {
+				return nil, fmt.Errorf("generating device name: %w", err)
+			}
+		}
+	}
+
+	result := &RegisterResult{
+		DisplayName:          userEmail,
+		TargetUserUUID:       userUUID,
+		DryRun:               opts.DryRun,
+		UserAlreadyHadAccess: fileExistsForWorkflow(kanukaFilePath),
+		PubKeyPath:           pubKeyFilePath,
+		KanukaFilePath:       kanukaFilePath,
+		Fingerprint:          publicKeyFingerprint(pendingPublicKey),
+		Mode:                 RegisterModePending,
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	if !pubkeyExisted {
+		if err := secrets.SavePublicKeyToFile(pendingPublicKey, pubKeyFilePath); err != nil {
+			return nil, fmt.Errorf("saving public key to project: %w", err)
+		}
+		result.FilesCreated = append(result.FilesCreated, RegisteredFile{Type: "public_key", Path: pubKeyFilePath})
+	}
+
+	if err := os.MkdirAll(configs.GetKeyDirPath(projectUUID), 0700); err != nil {
+		return nil, fmt.Errorf("creating key directory: %w", err)
+	}
+	if err := os.Rename(pendingPrivateKeyPath, configs.GetPrivateKeyPath(projectUUID)); err != nil {
+		return nil, fmt.Errorf("moving pending private key: %w", err)
+	}
+	if err := os.Rename(pendingPublicKeyPath, configs.GetPublicKeyPath(projectUUID)); err != nil {
+		return nil, fmt.Errorf("moving pending public key: %w", err)
+	}
+	// Leave the directory if something else was put there.
+	_ = os.Remove(configs.GetPendingKeyDirPath())
+
+	now := time.Now()
+	metadata := &configs.KeyMetadata{
+		ProjectName:    projectConfig.Project.Name,
+		ProjectPath:    configs.ProjectKanukaSettings.ProjectPath,
+		CreatedAt:      now,
+		LastAccessedAt: now,
+	}
+	if err := configs.SaveKeyMetadata(projectUUID, metadata); err != nil {
+		return nil, fmt.Errorf("saving key metadata: %w", err)
+	}
+
+	projectConfig.Users[userUUID] = userEmail
+	device := projectConfig.Devices[userUUID]
+	device.Email = userEmail
+	device.Name = deviceName
+	if device.CreatedAt.IsZero() {
+		device.CreatedAt = now.UTC()
+	}
+	projectConfig.Devices[userUUID] = device
+	if err := configs.SaveProjectConfig(projectConfig); err != nil {
+		return nil, fmt.Errorf("saving project config: %w", err)
+	}
+
+	if userConfig.User.Email == "" {
+		userConfig.User.Email = userEmail
+	}
+	if userConfig.Projects == nil {
+		userConfig.Projects = make(map[string]configs.UserProjectEntry)
+	}
+	userConfig.Projects[projectUUID] = configs.UserProjectEntry{
+		DeviceName:  deviceName,
+		ProjectName: projectConfig.Project.Name,
+	}
+	if err := configs.SaveUserConfig(userConfig); err != nil {
+		return nil, fmt.Errorf("updating user config with project: %w", err)
+	}
+
+	auditEntry := audit.LogWithUser("register")
+	auditEntry.TargetUser = userEmail
+	auditEntry.TargetUUID = userUUID
+	auditEntry.DeviceName = deviceName
+	audit.Log(auditEntry)
+
+	return result, nil
+}
+
+// samePublicKey reports whether a and b are the same public key.
+func samePublicKey(a, b any) (bool, error) {
+	aDER, err := x509.MarshalPKIXPublicKey(a)
+	if err != nil {
+		return false, fmt.Errorf("encoding public key: %w", err)
+	}
+	bDER, err := x509.MarshalPKIXPublicKey(b)
+	if err != nil {
+		return false, fmt.Errorf("encoding public key: %w", err)
+	}
+	return string(aDER) == string(bDER), nil
+}
@@ -0,0 +1,130 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+)
+
+// PruneOptions configures the prune workflow.
+type PruneOptions struct {
+	// DryRun previews what would be removed without making changes.
+	DryRun bool
+}
+
+// PruneResult contains the outcome of a prune operation.
+type PruneResult struct {
+	// Orphans is the list of orphaned key files found.
+	Orphans []OrphanEntry
+
+	// RemovedCount is the number of files removed (0 if dry-run).
+	RemovedCount int
+
+	// DryRun indicates whether this was a dry-run.
+	DryRun bool
+}
+
+// Prune removes key files whose UUID is no longer in the project config.
+//
+// A file in .kanuka/public_keys/ or .kanuka/secrets/ is orphaned if the UUID
+// it is named after has no entry in either [users] or [devices], for example
+// after a revoke that updated the config but failed to delete the files.
+// Files for UUIDs still in the config are never removed. This differs from
+// Clean, which compares the two key directories with each other.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrInvalidProjectConfig if the project config can't be parsed.
+func Prune(ctx context.Context, opts PruneOptions) (*PruneResult, error) {
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	projectPath := configs.ProjectKanukaSettings.ProjectPath
+	if projectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", kerrors.ErrInvalidProjectConfig, err)
+	}
+
+	orphans, err := findUnregisteredKeyFiles(projectPath, projectConfig)
+	if err != nil {
+		return nil, fmt.Errorf("finding orphaned key files: %w", err)
+	}
+
+	result := &PruneResult{
+		Orphans: orphans,
+		DryRun:  opts.DryRun,
+	}
+
+	if len(orphans) == 0 || opts.DryRun {
+		return result, nil
+	}
+
+	// Log whatever was removed, even if a later removal fails.
+	var removed []string
+	defer func() {
+		if len(removed) == 0 {
+			return
+		}
+		auditEntry := audit.LogWithUser("prune")
+		auditEntry.Files = removed
+		auditEntry.RemovedCount = len(removed)
+		audit.Log(auditEntry)
+	}()
+
+	for _, orphan := range orphans {
+		if err := os.Remove(orphan.FilePath); err != nil {
+			return nil, fmt.Errorf("removing %s: %w", orphan.RelativePath, err)
+		}
+		removed = append(removed, orphan.RelativePath)
+		result.RemovedCount++
+	}
+
+	return result, nil
+}
+
+// findUnregisteredKeyFiles finds public key and encrypted key files named
+// after a UUID that the project config doesn't know.
+func findUnregisteredKeyFiles(projectPath string, projectConfig *configs.ProjectConfig) ([]OrphanEntry, error) {
+	var orphans []OrphanEntry
+
+	dirs := []struct {
+		path  string
+		files func() (map[string]string, error)
+	}{
+		{configs.ProjectKanukaSettings.ProjectPublicKeyPath, configs.PublicKeyFileUUIDs},
+		{configs.ProjectKanukaSettings.ProjectSecretsPath, configs.SecretKeyFileUUIDs},
+	}
+	for _, dir := range dirs {
+		files, err := dir.files()
+		if err != nil {
+			return nil, err
+		}
+		for name, uuid := range files {
+			if projectConfig.HasUUID(uuid) {
+				continue
+			}
+			filePath := filepath.Join(dir.path, name)
+			relPath, _ := filepath.Rel(projectPath, filePath)
+			orphans = append(orphans, OrphanEntry{
+				UUID:         uuid,
+				FilePath:     filePath,
+				RelativePath: relPath,
+			})
+		}
+	}
+
+	sort.Slice(orphans, func(i, j int) bool {
+		return orphans[i].RelativePath < orphans[j].RelativePath
+	})
+	return orphans, nil
+}
@@ -0,0 +1,116 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+)
+
+// ReencryptOptions configures the re-encrypt workflow.
+type ReencryptOptions struct {
+	// PrivateKeyData contains the private key bytes when reading from stdin.
+	// If nil, the private key is loaded from disk.
+	PrivateKeyData []byte
+
+	// DryRun checks that every file decrypts without rewriting any of them.
+	DryRun bool
+}
+
+// ReencryptResult contains the outcome of a re-encrypt operation.
+type ReencryptResult struct {
+	// Files lists the .kanuka files that were (or would be) re-encrypted,
+	// relative to the project root.
+	Files []string `json:"files"`
+
+	// DryRun indicates whether this was a dry-run (no changes made).
+	DryRun bool `json:"dry_run"`
+}
+
+// Reencrypt re-encrypts every .kanuka file in the project with fresh nonces,
+// using the project's current symmetric key. Unlike revoke or sync, no new
+// symmetric key is created, so the wrapped keys in .kanuka/secrets/ are left
+// untouched and nobody's access changes.
+//
+// Each file is decrypted in full before it is rewritten, so a file that fails
+// to decrypt is reported without being changed. Files rewritten before the
+// failure keep their new ciphertext, which still decrypts with the same key.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrNoAccess if the user doesn't have a key file for this project.
+// Returns ErrKeyDecryptFailed if the private key cannot decrypt the symmetric key.
+// Returns ErrGPGNotFound if the symmetric key was wrapped for a GPG key and gpg is not on PATH.
+// Returns ErrInvalidKeyLength if the symmetric key is not 32 bytes.
+// Returns ErrNoFilesFound if the project has no .kanuka files.
+// Returns ErrDecryptFailed if a .kanuka file doesn't decrypt with the symmetric key.
+func Reencrypt(ctx context.Context, opts ReencryptOptions) (*ReencryptResult, error) {
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	projectPath := configs.ProjectKanukaSettings.ProjectPath
+	if projectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+
+	userConfig, err := configs.EnsureUserConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading user config: %w", err)
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+
+	encryptedSymKey, err := secrets.GetProjectKanukaKey(userConfig.User.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrNoAccess, err)
+	}
+
+	symKey, err := unwrapSymmetricKey(encryptedSymKey, opts.PrivateKeyData, projectConfig.Project.UUID, loadPrivateKeyForDecrypt)
+	if err != nil {
+		return nil, err
+	}
+	if len(symKey) != 32 {
+		return nil, fmt.Errorf("%w: your wrapped key decrypts to %d bytes", kerrors.ErrInvalidKeyLength, len(symKey))
+	}
+
+	kanukaFiles, err := secrets.FindEnvOrKanukaFiles(projectPath, []string{}, nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("finding encrypted files: %w", err)
+	}
+	if len(kanukaFiles) == 0 {
+		return nil, kerrors.ErrNoFilesFound
+	}
+
+	result := &ReencryptResult{DryRun: opts.DryRun}
+	for _, path := range kanukaFiles {
+		if opts.DryRun {
+			err = secrets.VerifyEncryptedFile(symKey, path)
+		} else {
+			err = secrets.ReencryptFile(symKey, path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", kerrors.ErrDecryptFailed, err)
+		}
+
+		rel, relErr := filepath.Rel(projectPath, path)
+		if relErr != nil {
+			rel = path
+		}
+		result.Files = append(result.Files, filepath.ToSlash(rel))
+	}
+
+	if !opts.DryRun {
+		auditEntry := audit.LogWithUser("reencrypt")
+		auditEntry.FilesCount = len(result.Files)
+		audit.Log(auditEntry)
+	}
+
+	return result, nil
+}
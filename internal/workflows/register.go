@@ -2,17 +2,20 @@ package workflows
 
 import (
 	"context"
-	"crypto/rsa"
+	"crypto"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/PolarWolf314/kanuka/internal/audit"
 	"github.com/PolarWolf314/kanuka/internal/configs"
 	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/internal/utils"
 )
 
 // RegisterMode indicates how the user is being registered.
@@ -25,6 +28,16 @@ const (
 	RegisterModePubkeyText RegisterMode = "pubkey_text"
 	// RegisterModeFile registers a user from a public key file.
 	RegisterModeFile RegisterMode = "file"
+	// RegisterModeGPG registers a user with a GPG key exported from the local keyring.
+	RegisterModeGPG RegisterMode = "gpg"
+	// RegisterModePublicKeyFile registers a user from a PEM or OpenSSH public key file with any name.
+	RegisterModePublicKeyFile RegisterMode = "public_key_file"
+	// RegisterModeDevice registers the current machine as a new device of a
+	// user who already has access from another device.
+	RegisterModeDevice RegisterMode = "device"
+	// RegisterModePending promotes the key pair made with create
+	// --no-register into the project as the current user's key.
+	RegisterModePending RegisterMode = "pending"
 )
 
 // RegisterOptions configures the register workflow.
@@ -41,13 +54,33 @@ type RegisterOptions struct {
 	// FilePath is the path to the public key file (for file mode).
 	FilePath string
 
+	// PublicKeyPath is the path to a PEM or OpenSSH public key file (for public_key_file mode).
+	PublicKeyPath string
+
+	// GPGKeyID identifies the GPG key to export from the local keyring (for gpg mode).
+	GPGKeyID string
+
+	// DeviceName names the current machine (for device and pending modes).
+	// UserEmail defaults to the email in the user config.
+	DeviceName string
+
+	// KeyType is the algorithm of the key pair generated for the current
+	// machine in device mode: "rsa2048", "rsa4096", or "ed25519". Empty uses
+	// secrets.DefaultKeyAlgorithm.
+	KeyType string
+
+	// Expiry is the last day, as YYYY-MM-DD in UTC, that the registered
+	// device should have access. Empty means access doesn't expire.
+	Expiry string
+
 	// DryRun previews registration without making changes.
 	DryRun bool
 
 	// PrivateKeyData contains the private key bytes when reading from stdin.
 	PrivateKeyData []byte
 
-	// Force skips confirmation when updating existing user's access.
+	// Force skips confirmation when updating existing user's access. In
+	// public_key_file mode it also allows replacing an existing public key.
 	Force bool
 
 	// Verbose enables verbose output.
@@ -60,49 +93,75 @@ type RegisterOptions struct {
 // RegisterResult contains the outcome of a register operation.
 type RegisterResult struct {
 	// DisplayName is the user-friendly name of who was registered.
-	DisplayName string
+	DisplayName string `json:"display_name"`
 
 	// TargetUserUUID is the UUID of the registered user.
-	TargetUserUUID string
+	TargetUserUUID string `json:"target_user_uuid"`
 
 	// FilesCreated lists files that were created.
-	FilesCreated []RegisteredFile
+	FilesCreated []RegisteredFile `json:"files_created"`
 
 	// FilesUpdated lists files that were updated.
-	FilesUpdated []RegisteredFile
+	FilesUpdated []RegisteredFile `json:"files_updated"`
 
 	// DryRun indicates whether this was a dry-run (no changes made).
-	DryRun bool
+	DryRun bool `json:"dry_run"`
 
 	// UserAlreadyHadAccess indicates if user already had access before this registration.
-	UserAlreadyHadAccess bool
+	UserAlreadyHadAccess bool `json:"user_already_had_access"`
 
 	// PubKeyPath is the path where the public key is/would be stored.
-	PubKeyPath string
+	PubKeyPath string `json:"public_key_path"`
 
 	// KanukaFilePath is the path where the .kanuka key is/would be stored.
-	KanukaFilePath string
+	KanukaFilePath string `json:"kanuka_file_path"`
+
+	// Fingerprint is the registered public key's fingerprint, for checking
+	// out of band that it is the right key. Empty for GPG keys, and for
+	// device registrations in a dry run, since the key isn't generated yet.
+	Fingerprint string `json:"fingerprint,omitempty"`
 
 	// Mode indicates which registration mode was used.
-	Mode RegisterMode
+	Mode RegisterMode `json:"mode"`
+
+	// ExpiresAt is when the registered device's access expires, if Expiry
+	// was set.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 // RegisteredFile represents a file that was created or updated.
 type RegisteredFile struct {
-	Type string // "public_key" or "encrypted_key"
-	Path string
+	Type string `json:"type"` // "public_key" or "encrypted_key"
+	Path string `json:"path"`
 }
 
 // Register grants a user access to the project's encrypted secrets.
 //
 // It encrypts the project's symmetric key with the target user's public key,
 // allowing them to decrypt secrets. The caller must have access to the
-// project's secrets before they can grant access to others.
+// project's secrets before they can grant access to others, except in a
+// project created with init --bare: its first registration generates the
+// symmetric key.
 //
 // Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrProjectLocked if another operation holds the project lock.
 // Returns ErrUserNotFound if the specified user is not in the project config.
 // Returns ErrNoAccess if the current user doesn't have access to the project.
 // Returns ErrPublicKeyNotFound if the target user's public key cannot be found.
+// Returns ErrGPGNotFound if a GPG key is involved and gpg is not on PATH.
+// Returns ErrFileNotFound if the public key file for public_key_file mode is missing.
+// Returns ErrPublicKeyExists if the user already has a public key in
+// public_key_file mode and Force is not set, if the current machine is
+// already registered in device mode, or in pending mode if the user already
+// has a key for the project or the project holds a different public key.
+// Returns ErrDeviceNameTaken if the user already has a device named DeviceName.
+// Returns ErrInvalidEmail if UserEmail is malformed, or if device mode has no
+// valid email.
+// Returns ErrInvalidArguments if KeyType is set outside device mode or is not
+// a supported key type.
+// Returns ErrPrivateKeyNotFound in pending mode if there is no pending key.
+// Returns ErrInvalidDateFormat if Expiry isn't a YYYY-MM-DD date, and
+// ErrInvalidArguments if it has already passed.
 func Register(ctx context.Context, opts RegisterOptions) (*RegisterResult, error) {
 	if err := configs.InitProjectSettings(); err != nil {
 		return nil, fmt.Errorf("initializing project settings: %w", err)
@@ -113,14 +172,105 @@ func Register(ctx context.Context, opts RegisterOptions) (*RegisterResult, error
 		return nil, kerrors.ErrProjectNotInitialized
 	}
 
+	if !opts.DryRun {
+		lock, err := configs.LockProject()
+		if err != nil {
+			return nil, err
+		}
+		defer lock.Unlock()
+	}
+
+	if opts.KeyType != "" {
+		if opts.Mode != RegisterModeDevice {
+			return nil, fmt.Errorf("%w: a key type can only be chosen when registering this device", kerrors.ErrInvalidArguments)
+		}
+		if _, err := secrets.ParseKeyAlgorithm(opts.KeyType); err != nil {
+			return nil, fmt.Errorf("%w: %v", kerrors.ErrInvalidArguments, err)
+		}
+	}
+
+	// A malformed email would be written to the config as a user nobody can
+	// reach, so reject it before any mode touches the project.
+	if opts.UserEmail != "" && !utils.IsValidEmail(opts.UserEmail) {
+		return nil, fmt.Errorf("%w: %s", kerrors.ErrInvalidEmail, opts.UserEmail)
+	}
+
+	var expiresAt time.Time
+	if opts.Expiry != "" {
+		var err error
+		expiresAt, err = parseExpiry(opts.Expiry, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var result *RegisterResult
+	var err error
 	switch opts.Mode {
 	case RegisterModePubkeyText:
-		return registerWithPubkeyText(ctx, opts)
+		result, err = registerWithPubkeyText(ctx, opts)
 	case RegisterModeFile:
-		return registerWithFile(ctx, opts)
+		result, err = registerWithFile(ctx, opts)
+	case RegisterModeGPG:
+		result, err = registerWithGPGKey(ctx, opts)
+	case RegisterModePublicKeyFile:
+		result, err = registerWithPublicKeyFile(ctx, opts)
+	case RegisterModeDevice:
+		result, err = registerOwnDevice(ctx, opts)
+	case RegisterModePending:
+		result, err = registerPendingKey(ctx, opts)
 	default:
-		return registerByEmail(ctx, opts)
+		result, err = registerByEmail(ctx, opts)
+	}
+	if err != nil || expiresAt.IsZero() {
+		return result, err
+	}
+
+	result.ExpiresAt = &expiresAt
+	if !opts.DryRun {
+		if err := setDeviceExpiry(result.TargetUserUUID, expiresAt); err != nil {
+			return nil, err
+		}
 	}
+	return result, nil
+}
+
+// parseExpiry parses a YYYY-MM-DD expiry date. Access lasts until the end of
+// that day in UTC.
+func parseExpiry(expiry string, now time.Time) (time.Time, error) {
+	day, err := time.Parse("2006-01-02", expiry)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: --expiry date format invalid, use YYYY-MM-DD", kerrors.ErrInvalidDateFormat)
+	}
+	expiresAt := day.Add(24*time.Hour - time.Second)
+	if expiresAt.Before(now) {
+		return time.Time{}, fmt.Errorf("%w: expiry date %s has already passed", kerrors.ErrInvalidArguments, expiry)
+	}
+	return expiresAt, nil
+}
+
+// setDeviceExpiry records when the device with userUUID stops having access,
+// adding a device entry for it if the registration didn't create one.
+func setDeviceExpiry(userUUID string, expiresAt time.Time) error {
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("loading project config: %w", err)
+	}
+
+	device := projectConfig.Devices[userUUID]
+	if device.Email == "" {
+		device.Email = projectConfig.Users[userUUID]
+	}
+	if device.CreatedAt.IsZero() {
+		device.CreatedAt = time.Now().UTC()
+	}
+	device.ExpiresAt = expiresAt
+	projectConfig.Devices[userUUID] = device
+
+	if err := configs.SaveProjectConfig(projectConfig); err != nil {
+		return fmt.Errorf("saving project config: %w", err)
+	}
+	return nil
 }
 
 // registerByEmail handles registration when only user email is provided.
@@ -154,19 +304,9 @@ func registerByEmail(ctx context.Context, opts RegisterOptions) (*RegisterResult
 	}
 
 	// Verify current user has access.
-	encryptedSymKey, err := secrets.GetProjectKanukaKey(currentUserUUID)
-	if err != nil {
-		return nil, fmt.Errorf("%w: cannot get kanuka key", kerrors.ErrNoAccess)
-	}
-
-	privateKey, err := loadPrivateKeyForRegister(opts.PrivateKeyData, projectUUID)
-	if err != nil {
-		return nil, fmt.Errorf("%w: cannot load private key: %v", kerrors.ErrNoAccess, err)
-	}
-
-	symKey, err := secrets.DecryptWithPrivateKey(encryptedSymKey, privateKey)
+	symKey, err := projectSymmetricKeyForRegister(currentUserUUID, opts.PrivateKeyData, projectUUID)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", kerrors.ErrKeyDecryptFailed, err)
+		return nil, err
 	}
 
 	// Compute paths.
@@ -184,6 +324,7 @@ func registerByEmail(ctx context.Context, opts RegisterOptions) (*RegisterResult
 		UserAlreadyHadAccess: userAlreadyHasAccess,
 		PubKeyPath:           targetPubkeyPath,
 		KanukaFilePath:       targetKanukaFilePath,
+		Fingerprint:          publicKeyFingerprint(targetUserPublicKey),
 		Mode:                 RegisterModeEmail,
 	}
 
@@ -248,19 +389,9 @@ func registerWithPubkeyText(ctx context.Context, opts RegisterOptions) (*Registe
 	}
 
 	// Verify current user has access.
-	encryptedSymKey, err := secrets.GetProjectKanukaKey(currentUserUUID)
-	if err != nil {
-		return nil, fmt.Errorf("%w: cannot get kanuka key", kerrors.ErrNoAccess)
-	}
-
-	privateKey, err := loadPrivateKeyForRegister(opts.PrivateKeyData, projectUUID)
-	if err != nil {
-		return nil, fmt.Errorf("%w: cannot load private key: %v", kerrors.ErrNoAccess, err)
-	}
-
-	symKey, err := secrets.DecryptWithPrivateKey(encryptedSymKey, privateKey)
+	symKey, err := projectSymmetricKeyForRegister(currentUserUUID, opts.PrivateKeyData, projectUUID)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", kerrors.ErrKeyDecryptFailed, err)
+		return nil, err
 	}
 
 	// Compute paths.
@@ -279,6 +410,7 @@ func registerWithPubkeyText(ctx context.Context, opts RegisterOptions) (*Registe
 		UserAlreadyHadAccess: userAlreadyHasAccess,
 		PubKeyPath:           pubKeyFilePath,
 		KanukaFilePath:       kanukaFilePath,
+		Fingerprint:          publicKeyFingerprint(publicKey),
 		Mode:                 RegisterModePubkeyText,
 	}
 
@@ -323,6 +455,146 @@ func registerWithPubkeyText(ctx context.Context, opts RegisterOptions) (*Registe
 	return result, nil
 }
 
+// registerWithPublicKeyFile handles registration from a public key file that
+// doesn't need to be named after the user's UUID, such as one carried to an
+// air-gapped machine. Unlike --pubkey, it refuses to replace an existing
+// public key unless Force is set.
+func registerWithPublicKeyFile(ctx context.Context, opts RegisterOptions) (*RegisterResult, error) {
+	// #nosec G304 -- the path is supplied by the user running the command.
+	data, err := os.ReadFile(opts.PublicKeyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", kerrors.ErrFileNotFound, opts.PublicKeyPath)
+		}
+		return nil, fmt.Errorf("reading public key file: %w", err)
+	}
+
+	if _, err := secrets.ParsePublicKeyText(string(data)); err != nil {
+		return nil, fmt.Errorf("invalid public key format: %w", err)
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+	targetUserUUID, found := projectConfig.GetUserUUIDByEmail(opts.UserEmail)
+	if !found {
+		return nil, fmt.Errorf("%w: %s", kerrors.ErrUserNotFound, opts.UserEmail)
+	}
+
+	pubKeyFilePath := filepath.Join(configs.ProjectKanukaSettings.ProjectPublicKeyPath, targetUserUUID+".pub")
+	if !opts.Force && fileExistsForWorkflow(pubKeyFilePath) {
+		return nil, fmt.Errorf("%w: %s", kerrors.ErrPublicKeyExists, pubKeyFilePath)
+	}
+
+	opts.PublicKeyText = string(data)
+	result, err := registerWithPubkeyText(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.Mode = RegisterModePublicKeyFile
+	return result, nil
+}
+
+// registerWithGPGKey handles registration with a GPG key from the local keyring.
+// The exported key is stored as .kanuka/public_keys/<uuid>.gpg and the
+// symmetric key is wrapped to it with gpg.
+func registerWithGPGKey(ctx context.Context, opts RegisterOptions) (*RegisterResult, error) {
+	projectSecretsPath := configs.ProjectKanukaSettings.ProjectSecretsPath
+
+	if !secrets.GPGAvailable() {
+		return nil, kerrors.ErrGPGNotFound
+	}
+
+	userConfig, err := configs.EnsureUserConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading user config: %w", err)
+	}
+	currentUserUUID := userConfig.User.UUID
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+	projectUUID := projectConfig.Project.UUID
+
+	// Look up user UUID by email.
+	targetUserUUID, found := projectConfig.GetUserUUIDByEmail(opts.UserEmail)
+	if !found {
+		return nil, fmt.Errorf("%w: %s", kerrors.ErrUserNotFound, opts.UserEmail)
+	}
+
+	// Export the key before anything else, so a bad key ID fails early.
+	gpgPublicKey, err := secrets.ExportGPGPublicKey(opts.GPGKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrPublicKeyNotFound, err)
+	}
+
+	// Verify current user has access.
+	symKey, err := projectSymmetricKeyForRegister(currentUserUUID, opts.PrivateKeyData, projectUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compute paths.
+	gpgKeyPath := secrets.GPGPublicKeyPath(targetUserUUID)
+	kanukaFilePath := filepath.Join(projectSecretsPath, targetUserUUID+".kanuka")
+
+	// Check if files exist.
+	gpgKeyExisted := fileExistsForWorkflow(gpgKeyPath)
+	kanukaFileExisted := fileExistsForWorkflow(kanukaFilePath)
+
+	result := &RegisterResult{
+		DisplayName:          opts.UserEmail,
+		TargetUserUUID:       targetUserUUID,
+		DryRun:               opts.DryRun,
+		UserAlreadyHadAccess: gpgKeyExisted && kanukaFileExisted,
+		PubKeyPath:           gpgKeyPath,
+		KanukaFilePath:       kanukaFilePath,
+		Mode:                 RegisterModeGPG,
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	// Save the exported GPG key so others can re-wrap for this user on sync.
+	if err := os.WriteFile(gpgKeyPath, gpgPublicKey, 0644); err != nil {
+		return nil, fmt.Errorf("saving GPG public key: %w", err)
+	}
+
+	if !gpgKeyExisted {
+		result.FilesCreated = append(result.FilesCreated, RegisteredFile{Type: "public_key", Path: gpgKeyPath})
+	} else {
+		result.FilesUpdated = append(result.FilesUpdated, RegisteredFile{Type: "public_key", Path: gpgKeyPath})
+	}
+
+	// Wrap symmetric key with the target user's GPG key.
+	targetEncryptedSymKey, err := secrets.EncryptWithGPG(symKey, gpgKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting symmetric key: %w", err)
+	}
+
+	// Save encrypted symmetric key for target user.
+	if err := secrets.SaveKanukaKeyToProject(targetUserUUID, targetEncryptedSymKey); err != nil {
+		return nil, fmt.Errorf("saving encrypted key: %w", err)
+	}
+
+	if !kanukaFileExisted {
+		result.FilesCreated = append(result.FilesCreated, RegisteredFile{Type: "encrypted_key", Path: kanukaFilePath})
+	} else {
+		result.FilesUpdated = append(result.FilesUpdated, RegisteredFile{Type: "encrypted_key", Path: kanukaFilePath})
+	}
+
+	// Log to audit trail.
+	auditEntry := audit.LogWithUser("register")
+	auditEntry.TargetUser = opts.UserEmail
+	auditEntry.TargetUUID = targetUserUUID
+	audit.Log(auditEntry)
+
+	return result, nil
+}
+
 // registerWithFile handles registration from a public key file.
 func registerWithFile(ctx context.Context, opts RegisterOptions) (*RegisterResult, error) {
 	projectPublicKeyPath := configs.ProjectKanukaSettings.ProjectPublicKeyPath
@@ -360,19 +632,9 @@ func registerWithFile(ctx context.Context, opts RegisterOptions) (*RegisterResul
 	}
 
 	// Verify current user has access.
-	encryptedSymKey, err := secrets.GetProjectKanukaKey(currentUserUUID)
+	symKey, err := projectSymmetricKeyForRegister(currentUserUUID, opts.PrivateKeyData, projectUUID)
 	if err != nil {
-		return nil, fmt.Errorf("%w: cannot get kanuka key", kerrors.ErrNoAccess)
-	}
-
-	privateKey, err := loadPrivateKeyForRegister(opts.PrivateKeyData, projectUUID)
-	if err != nil {
-		return nil, fmt.Errorf("%w: cannot load private key: %v", kerrors.ErrNoAccess, err)
-	}
-
-	symKey, err := secrets.DecryptWithPrivateKey(encryptedSymKey, privateKey)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %v", kerrors.ErrKeyDecryptFailed, err)
+		return nil, err
 	}
 
 	// Try to find email for display purposes.
@@ -401,6 +663,7 @@ func registerWithFile(ctx context.Context, opts RegisterOptions) (*RegisterResul
 		UserAlreadyHadAccess: userAlreadyHasAccess,
 		PubKeyPath:           targetPubkeyPath,
 		KanukaFilePath:       targetKanukaFilePath,
+		Fingerprint:          publicKeyFingerprint(targetUserPublicKey),
 		Mode:                 RegisterModeFile,
 	}
 
@@ -450,13 +713,206 @@ func registerWithFile(ctx context.Context, opts RegisterOptions) (*RegisterResul
 	return result, nil
 }
 
-// loadPrivateKeyForRegister loads the private key from bytes or disk.
-func loadPrivateKeyForRegister(keyData []byte, projectUUID string) (*rsa.PrivateKey, error) {
+// registerOwnDevice registers the current machine as a new device of a user
+// who already has access. The symmetric key is unwrapped with the private key
+// of one of the user's registered devices, which must be given in
+// PrivateKeyData or KANUKA_PRIVATE_KEY, then wrapped for a new key pair
+// generated on this machine.
+func registerOwnDevice(ctx context.Context, opts RegisterOptions) (*RegisterResult, error) {
+	if err := secrets.EnsureUserSettings(); err != nil {
+		return nil, fmt.Errorf("ensuring user settings: %w", err)
+	}
+
+	userConfig, err := configs.EnsureUserConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading user config: %w", err)
+	}
+	currentUserUUID := userConfig.User.UUID
+
+	userEmail := opts.UserEmail
+	if userEmail == "" {
+		userEmail = userConfig.User.Email
+	}
+	if !utils.IsValidEmail(userEmail) {
+		return nil, fmt.Errorf("%w: %q", kerrors.ErrInvalidEmail, userEmail)
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+
+	deviceName := utils.SanitizeDeviceName(opts.DeviceName)
+	if _, taken := projectConfig.GetUserUUIDByEmailAndDevice(userEmail, deviceName); taken {
+		return nil, fmt.Errorf("%w: %s", kerrors.ErrDeviceNameTaken, deviceName)
+	}
+
+	existingDevices := projectConfig.GetDevicesByEmail(userEmail)
+	if len(existingDevices) == 0 {
+		return nil, fmt.Errorf("%w: %s", kerrors.ErrUserNotFound, userEmail)
+	}
+	if device, registered := projectConfig.Devices[currentUserUUID]; registered {
+		return nil, fmt.Errorf("%w: this machine is already registered as device %q", kerrors.ErrPublicKeyExists, device.Name)
+	}
+
+	keyData, err := privateKeyDataOrEnv(opts.PrivateKeyData)
+	if err != nil {
+		return nil, err
+	}
+	if len(keyData) == 0 {
+		return nil, fmt.Errorf("%w: registering a device needs the private key of one of your registered devices", kerrors.ErrNoAccess)
+	}
+	privateKey, err := secrets.LoadPrivateKeyFromBytesWithTTYPrompt(keyData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrInvalidPrivateKey, err)
+	}
+
+	symKey, err := unwrapForAnyDevice(existingDevices, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrNoAccess, err)
+	}
+
+	pubKeyFilePath := filepath.Join(configs.ProjectKanukaSettings.ProjectPublicKeyPath, currentUserUUID+".pub")
+	kanukaFilePath := filepath.Join(configs.ProjectKanukaSettings.ProjectSecretsPath, currentUserUUID+".kanuka")
+
+	result := &RegisterResult{
+		DisplayName:    userEmail + " (" + deviceName + ")",
+		TargetUserUUID: currentUserUUID,
+		DryRun:         opts.DryRun,
+		PubKeyPath:     pubKeyFilePath,
+		KanukaFilePath: kanukaFilePath,
+		Mode:           RegisterModeDevice,
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	keyType, err := secrets.ParseKeyAlgorithm(opts.KeyType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrInvalidArguments, err)
+	}
+	if err := secrets.CreateAndSaveKeyPair(false, keyType); err != nil {
+		return nil, fmt.Errorf("creating %s key pair: %w", keyType, err)
+	}
+	if _, err := secrets.CopyUserPublicKeyToProject(); err != nil {
+		return nil, fmt.Errorf("copying public key to project: %w", err)
+	}
+	result.FilesCreated = append(result.FilesCreated, RegisteredFile{Type: "public_key", Path: pubKeyFilePath})
+
+	publicKey, err := secrets.LoadPublicKey(pubKeyFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("loading new public key: %w", err)
+	}
+	result.Fingerprint = publicKeyFingerprint(publicKey)
+	encryptedSymKey, err := secrets.EncryptWithPublicKey(symKey, publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting symmetric key: %w", err)
+	}
+	if err := secrets.SaveKanukaKeyToProject(currentUserUUID, encryptedSymKey); err != nil {
+		return nil, fmt.Errorf("saving encrypted key: %w", err)
+	}
+	result.FilesCreated = append(result.FilesCreated, RegisteredFile{Type: "encrypted_key", Path: kanukaFilePath})
+
+	projectConfig.Users[currentUserUUID] = userEmail
+	projectConfig.Devices[currentUserUUID] = configs.DeviceConfig{
+		Email:     userEmail,
+		Name:      deviceName,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := configs.SaveProjectConfig(projectConfig); err != nil {
+		return nil, fmt.Errorf("saving project config: %w", err)
+	}
+
+	userConfig.User.Email = userEmail
+	if userConfig.Projects == nil {
+		userConfig.Projects = make(map[string]configs.UserProjectEntry)
+	}
+	userConfig.Projects[projectConfig.Project.UUID] = configs.UserProjectEntry{
+		DeviceName:  deviceName,
+		ProjectName: projectConfig.Project.Name,
+	}
+	if err := configs.SaveUserConfig(userConfig); err != nil {
+		return nil, fmt.Errorf("updating user config with project: %w", err)
+	}
+
+	auditEntry := audit.LogWithUser("register")
+	auditEntry.TargetUser = userEmail
+	auditEntry.TargetUUID = currentUserUUID
+	auditEntry.DeviceName = deviceName
+	audit.Log(auditEntry)
+
+	return result, nil
+}
+
+// unwrapForAnyDevice returns the symmetric key from the first of devices
+// whose wrapped key privateKey decrypts.
+func unwrapForAnyDevice(devices map[string]configs.DeviceConfig, privateKey secrets.PrivateKey) ([]byte, error) {
+	uuids := make([]string, 0, len(devices))
+	for uuid := range devices {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+
+	for _, uuid := range uuids {
+		wrapped, err := secrets.GetProjectKanukaKey(uuid)
+		if err != nil || secrets.IsGPGWrapped(wrapped) {
+			continue
+		}
+		if symKey, err := secrets.DecryptWithPrivateKey(wrapped, privateKey); err == nil {
+			return symKey, nil
+		}
+	}
+	return nil, fmt.Errorf("the private key doesn't decrypt the wrapped key of any of your registered devices")
+}
+
+// loadPrivateKeyForRegister loads the private key from bytes, KANUKA_PRIVATE_KEY, or disk.
+// Any failure is reported as ErrNoAccess, since the user can't share access
+// without their key.
+func loadPrivateKeyForRegister(keyData []byte, projectUUID string) (secrets.PrivateKey, error) {
+	keyData, err := privateKeyDataOrEnv(keyData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot load private key: %v", kerrors.ErrNoAccess, err)
+	}
+	var key secrets.PrivateKey
 	if len(keyData) > 0 {
-		return secrets.LoadPrivateKeyFromBytesWithTTYPrompt(keyData)
+		key, err = secrets.LoadPrivateKeyFromBytesWithTTYPrompt(keyData)
+	} else {
+		key, err = secrets.LoadPrivateKey(configs.GetPrivateKeyPath(projectUUID))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot load private key: %v", kerrors.ErrNoAccess, err)
+	}
+	return key, nil
+}
+
+// projectSymmetricKeyForRegister returns the project's symmetric key,
+// unwrapped with the current user's key. A bare project has no symmetric key
+// until its first user registers, so a new one is generated for them.
+func projectSymmetricKeyForRegister(currentUserUUID string, keyData []byte, projectUUID string) ([]byte, error) {
+	hasKeys, err := secrets.ProjectHasWrappedKeys()
+	if err != nil {
+		return nil, err
+	}
+	if !hasKeys {
+		return secrets.CreateSymmetricKey()
+	}
+
+	encryptedSymKey, err := secrets.GetProjectKanukaKey(currentUserUUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot get kanuka key", kerrors.ErrNoAccess)
+	}
+	return unwrapSymmetricKey(encryptedSymKey, keyData, projectUUID, loadPrivateKeyForRegister)
+}
+
+// publicKeyFingerprint returns publicKey's fingerprint, or an empty string if
+// it can't be computed.
+func publicKeyFingerprint(publicKey crypto.PublicKey) string {
+	fingerprint, err := secrets.PublicKeyFingerprint(publicKey)
+	if err != nil {
+		return ""
 	}
-	privateKeyPath := configs.GetPrivateKeyPath(projectUUID)
-	return secrets.LoadPrivateKey(privateKeyPath)
+	return fingerprint
 }
 
 // fileExistsForWorkflow checks if a file exists and is not a directory.
@@ -491,7 +947,7 @@ func CheckUserExistsForRegistration(userEmail string) (targetUUID string, alread
 	pubkeyPath := filepath.Join(projectPublicKeyPath, targetUserUUID+".pub")
 	kanukaPath := filepath.Join(projectSecretsPath, targetUserUUID+".kanuka")
 
-	pubkeyExists := fileExistsForWorkflow(pubkeyPath)
+	pubkeyExists := fileExistsForWorkflow(pubkeyPath) || fileExistsForWorkflow(secrets.GPGPublicKeyPath(targetUserUUID))
 	kanukaExists := fileExistsForWorkflow(kanukaPath)
 
 	return targetUserUUID, pubkeyExists && kanukaExists, nil
@@ -0,0 +1,208 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+)
+
+// RegisterAllPendingOptions configures the register --all-pending workflow.
+type RegisterAllPendingOptions struct {
+	// DryRun lists the pending keys without writing anything.
+	DryRun bool
+
+	// PrivateKeyData contains the private key bytes when reading from stdin.
+	// If nil, the private key is loaded from disk.
+	PrivateKeyData []byte
+}
+
+// PendingGrant is the outcome for one public key with no encrypted key.
+type PendingGrant struct {
+	// UUID is the device the public key belongs to.
+	UUID string `json:"uuid"`
+
+	// UserEmail is the device's email in the project config, empty if the
+	// UUID isn't in the config.
+	UserEmail string `json:"user_email,omitempty"`
+
+	// KanukaFilePath is the encrypted key file written (or that would be
+	// written) for the device.
+	KanukaFilePath string `json:"kanuka_file_path"`
+
+	// Granted is true once the encrypted key file has been written.
+	Granted bool `json:"granted"`
+
+	// Error describes why the device was not granted access.
+	Error string `json:"error,omitempty"`
+}
+
+// RegisterAllPendingResult contains the outcome of registering every pending
+// public key.
+type RegisterAllPendingResult struct {
+	// Grants lists one entry per pending public key, sorted by UUID.
+	Grants []PendingGrant `json:"grants"`
+
+	// DryRun indicates whether this was a dry-run.
+	DryRun bool `json:"dry_run"`
+}
+
+// Failed returns the number of pending keys that could not be granted.
+func (r *RegisterAllPendingResult) Failed() int {
+	failed := 0
+	for _, grant := range r.Grants {
+		if grant.Error != "" {
+			failed++
+		}
+	}
+	return failed
+}
+
+// RegisterAllPending grants access to every public key in the project that
+// has no matching encrypted key file.
+//
+// A public key is pending when .kanuka/public_keys/<uuid>.pub exists but
+// .kanuka/secrets/<uuid>.kanuka does not; keys that already have access are
+// skipped. Each pending device must be in the project config so that the
+// grant can be attributed to a user. A failure for one device is recorded on
+// its PendingGrant and doesn't stop the others.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrProjectLocked if another operation holds the project lock.
+// Returns ErrNoAccess if the current user doesn't have access to the project.
+// Returns ErrKeyDecryptFailed if the symmetric key cannot be decrypted.
+// Returns ErrGPGNotFound if the current user's key is GPG-wrapped and gpg is
+// not on PATH.
+func RegisterAllPending(ctx context.Context, opts RegisterAllPendingOptions) (*RegisterAllPendingResult, error) {
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	if configs.ProjectKanukaSettings.ProjectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+
+	if !opts.DryRun {
+		lock, err := configs.LockProject()
+		if err != nil {
+			return nil, err
+		}
+		defer lock.Unlock()
+	}
+
+	userConfig, err := configs.EnsureUserConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading user config: %w", err)
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+
+	pending, err := pendingPublicKeyUUIDs()
+	if err != nil {
+		return nil, fmt.Errorf("scanning public keys: %w", err)
+	}
+
+	encryptedSymKey, err := secrets.GetProjectKanukaKey(userConfig.User.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot get kanuka key", kerrors.ErrNoAccess)
+	}
+
+	symKey, err := unwrapSymmetricKey(encryptedSymKey, opts.PrivateKeyData, projectConfig.Project.UUID, loadPrivateKeyForRegister)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record every grant in one write to the audit log.
+	auditBatch := audit.NewBatch()
+	defer func() { _ = auditBatch.Close() }()
+
+	result := &RegisterAllPendingResult{DryRun: opts.DryRun}
+	for _, uuid := range pending {
+		grant := PendingGrant{
+			UUID:           uuid,
+			UserEmail:      projectConfig.Users[uuid],
+			KanukaFilePath: filepath.Join(configs.ProjectKanukaSettings.ProjectSecretsPath, uuid+".kanuka"),
+		}
+		if grant.UserEmail == "" {
+			grant.UserEmail = projectConfig.Devices[uuid].Email
+		}
+
+		if err := grantPendingKey(&grant, symKey, opts.DryRun); err != nil {
+			grant.Error = err.Error()
+		}
+		result.Grants = append(result.Grants, grant)
+
+		if grant.Granted {
+			auditEntry := audit.LogWithUser("register")
+			auditEntry.TargetUser = grant.UserEmail
+			auditEntry.TargetUUID = uuid
+			auditBatch.Add(auditEntry)
+		}
+	}
+
+	return result, nil
+}
+
+// grantPendingKey wraps symKey with the device's public key and saves it.
+// In a dry run it only checks that the public key can be loaded.
+func grantPendingKey(grant *PendingGrant, symKey []byte, dryRun bool) error {
+	if grant.UserEmail == "" {
+		return fmt.Errorf("%w: %s is not in the project config", kerrors.ErrUserNotFound, grant.UUID)
+	}
+
+	pubKeyPath := filepath.Join(configs.ProjectKanukaSettings.ProjectPublicKeyPath, grant.UUID+".pub")
+	publicKey, err := secrets.LoadPublicKey(pubKeyPath)
+	if err != nil {
+		return fmt.Errorf("loading public key: %w", err)
+	}
+	if dryRun {
+		return nil
+	}
+
+	wrapped, err := secrets.EncryptWithPublicKey(symKey, publicKey)
+	if err != nil {
+		return fmt.Errorf("encrypting symmetric key: %w", err)
+	}
+	if err := secrets.SaveKanukaKeyToProject(grant.UUID, wrapped); err != nil {
+		return fmt.Errorf("saving encrypted key: %w", err)
+	}
+
+	grant.Granted = true
+	return nil
+}
+
+// pendingPublicKeyUUIDs returns the sorted UUIDs with a .pub public key but
+// no .kanuka encrypted key.
+func pendingPublicKeyUUIDs() ([]string, error) {
+	publicKeys, err := configs.PublicKeyFileUUIDs()
+	if err != nil {
+		return nil, err
+	}
+	secretKeys, err := configs.SecretKeyFileUUIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	registered := make(map[string]bool, len(secretKeys))
+	for _, uuid := range secretKeys {
+		registered[uuid] = true
+	}
+
+	var pending []string
+	for name, uuid := range publicKeys {
+		if filepath.Ext(name) != ".pub" || registered[uuid] {
+			continue
+		}
+		pending = append(pending, uuid)
+	}
+	sort.Strings(pending)
+	return pending, nil
+}
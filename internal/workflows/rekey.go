@@ -0,0 +1,159 @@
+package workflows
+
+import (
+	"context"
+	"crypto"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/internal/utils"
+)
+
+// RekeyOptions configures the rekey workflow.
+type RekeyOptions struct {
+	// UserEmail is the email of the user whose encrypted key is re-wrapped.
+	UserEmail string
+
+	// PrivateKeyData contains the private key bytes when reading from stdin.
+	// If nil, the private key is loaded from disk.
+	PrivateKeyData []byte
+}
+
+// RekeyResult contains the outcome of a rekey operation.
+type RekeyResult struct {
+	// UserEmail is the email of the user whose key was re-wrapped.
+	UserEmail string
+
+	// FilesCreated lists the encrypted key files that didn't exist before.
+	FilesCreated []string
+
+	// FilesUpdated lists the encrypted key files that were overwritten.
+	FilesUpdated []string
+
+	// SkippedUUIDs lists the user's devices that have no public key.
+	SkippedUUIDs []string
+}
+
+// Rekey re-wraps the project's symmetric key for an existing user, writing
+// a .kanuka file for each of their devices that has a public key.
+//
+// The symmetric key is unwrapped with the current user's key and wrapped
+// again with the target's public key, so secret files are left untouched.
+// This repairs a user whose .kanuka file is missing while their .pub remains.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrProjectLocked if another operation holds the project lock.
+// Returns ErrInvalidEmail if UserEmail is malformed.
+// Returns ErrUserNotFound if no user in the project config has UserEmail.
+// Returns ErrPublicKeyNotFound if none of the user's devices has a public key.
+// Returns ErrNoAccess if the current user doesn't have access to the project.
+// Returns ErrKeyDecryptFailed if the symmetric key cannot be decrypted.
+func Rekey(ctx context.Context, opts RekeyOptions) (*RekeyResult, error) {
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	if configs.ProjectKanukaSettings.ProjectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+
+	lock, err := configs.LockProject()
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	if !utils.IsValidEmail(opts.UserEmail) {
+		return nil, fmt.Errorf("%w: %s", kerrors.ErrInvalidEmail, opts.UserEmail)
+	}
+
+	userConfig, err := configs.EnsureUserConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading user config: %w", err)
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+
+	result, err := rewrapKeyForUser(userConfig, projectConfig, opts.UserEmail, opts.PrivateKeyData)
+	if err != nil {
+		return nil, err
+	}
+
+	auditEntry := audit.LogWithUser("rekey")
+	auditEntry.TargetUser = opts.UserEmail
+	audit.Log(auditEntry)
+
+	return result, nil
+}
+
+// rewrapKeyForUser wraps the project's symmetric key with the public key of
+// each of email's devices, unwrapping it with the current user's key first.
+// Devices with no public key are skipped. The caller holds the project lock.
+func rewrapKeyForUser(userConfig *configs.UserConfig, projectConfig *configs.ProjectConfig, email string, privateKeyData []byte) (*RekeyResult, error) {
+	targetUUIDs := projectConfig.GetAllUserUUIDsByEmail(email)
+	if len(targetUUIDs) == 0 {
+		return nil, fmt.Errorf("%w: %s", kerrors.ErrUserNotFound, email)
+	}
+	sort.Strings(targetUUIDs)
+
+	result := &RekeyResult{UserEmail: email}
+	publicKeys := make(map[string]crypto.PublicKey)
+	for _, uuid := range targetUUIDs {
+		pubKeyPath := filepath.Join(configs.ProjectKanukaSettings.ProjectPublicKeyPath, uuid+".pub")
+		if !fileExistsForWorkflow(pubKeyPath) {
+			result.SkippedUUIDs = append(result.SkippedUUIDs, uuid)
+			continue
+		}
+		publicKey, err := secrets.LoadPublicKey(pubKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading public key for %s: %w", uuid, err)
+		}
+		publicKeys[uuid] = publicKey
+	}
+	if len(publicKeys) == 0 {
+		return nil, fmt.Errorf("%w: %s", kerrors.ErrPublicKeyNotFound, email)
+	}
+
+	encryptedSymKey, err := secrets.GetProjectKanukaKey(userConfig.User.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: cannot get kanuka key", kerrors.ErrNoAccess)
+	}
+
+	symKey, err := unwrapSymmetricKey(encryptedSymKey, privateKeyData, projectConfig.Project.UUID, loadPrivateKeyForRegister)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, uuid := range targetUUIDs {
+		publicKey, ok := publicKeys[uuid]
+		if !ok {
+			continue
+		}
+
+		wrapped, err := secrets.EncryptWithPublicKey(symKey, publicKey)
+		if err != nil {
+			return nil, fmt.Errorf("encrypting symmetric key for %s: %w", uuid, err)
+		}
+
+		kanukaFilePath := filepath.Join(configs.ProjectKanukaSettings.ProjectSecretsPath, uuid+".kanuka")
+		existed := fileExistsForWorkflow(kanukaFilePath)
+		if err := secrets.SaveKanukaKeyToProject(uuid, wrapped); err != nil {
+			return nil, fmt.Errorf("saving encrypted key for %s: %w", uuid, err)
+		}
+
+		if existed {
+			result.FilesUpdated = append(result.FilesUpdated, kanukaFilePath)
+		} else {
+			result.FilesCreated = append(result.FilesCreated, kanukaFilePath)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,262 @@
+package workflows
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+)
+
+// RekeyAllOptions configures the rekey-all workflow.
+type RekeyAllOptions struct {
+	// DryRun plans the rekey without making changes.
+	DryRun bool
+
+	// PrivateKeyData contains the private key bytes when reading from stdin.
+	// If nil, the private key is loaded from disk.
+	PrivateKeyData []byte
+}
+
+// RekeyAllResult contains the outcome of a rekey-all operation.
+type RekeyAllResult struct {
+	// UserUUIDs lists the users whose symmetric key was (or would be) re-wrapped.
+	UserUUIDs []string
+
+	// SecretFiles lists the .kanuka files that were (or would be) re-encrypted.
+	SecretFiles []string
+
+	// ProjectPath is the root path of the project.
+	ProjectPath string
+
+	// DryRun indicates whether this was a dry-run.
+	DryRun bool
+}
+
+// RekeyAll migrates every recipient and every secret file to the current key
+// format in one controlled operation.
+//
+// Every user's symmetric key is re-wrapped under the current wrapping scheme
+// and every secret file is re-encrypted under the current cipher. Afterwards a
+// verification pass checks that every user has a well-formed key, that the
+// current user's key unwraps, and that every file decrypts to its original
+// contents. If writing or verification fails, every touched file is restored.
+//
+// Symmetric keys are wrapped with RSA PKCS#1 v1.5 or an X25519 sealed box
+// depending on each user's key type, and files use NaCl secretbox, so this
+// upgrades projects written by older versions and is the place to select a
+// target once more formats exist.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrProjectLocked if another operation holds the project lock.
+// Returns ErrPrivateKeyNotFound if the private key cannot be loaded.
+// Returns ErrNoAccess if the user doesn't have a key file for this project.
+// Returns ErrKeyDecryptFailed if the symmetric key cannot be decrypted.
+// Returns ErrDecryptFailed if an existing secret file cannot be decrypted.
+// Returns ErrRekeyFailed if writing or verification failed and changes were rolled back.
+func RekeyAll(ctx context.Context, opts RekeyAllOptions) (*RekeyAllResult, error) {
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	projectPath := configs.ProjectKanukaSettings.ProjectPath
+	if projectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+
+	if !opts.DryRun {
+		lock, err := configs.LockProject()
+		if err != nil {
+			return nil, err
+		}
+		defer lock.Unlock()
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+
+	userConfig, err := configs.LoadUserConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading user config: %w", err)
+	}
+
+	privateKey, err := loadPrivateKey(opts.PrivateKeyData, projectConfig.Project.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedSymKey, err := secrets.GetProjectKanukaKey(userConfig.User.UUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrNoAccess, err)
+	}
+
+	oldSymKey, err := secrets.DecryptWithPrivateKey(encryptedSymKey, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrKeyDecryptFailed, err)
+	}
+
+	userUUIDs, err := secrets.GetAllUsersInProject()
+	if err != nil {
+		return nil, fmt.Errorf("listing users: %w", err)
+	}
+
+	secretFiles, err := secrets.FindEnvOrKanukaFiles(projectPath, []string{}, nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("finding encrypted files: %w", err)
+	}
+
+	// Decrypt everything up front: it proves the current state is readable
+	// before anything is touched, and gives verification something to compare.
+	plaintexts := make(map[string][]byte, len(secretFiles))
+	for _, f := range secretFiles {
+		plaintext, err := secrets.ReadEncryptedFile(oldSymKey, f)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", kerrors.ErrDecryptFailed, f, err)
+		}
+		plaintexts[f] = plaintext
+	}
+
+	result := &RekeyAllResult{
+		UserUUIDs:   userUUIDs,
+		SecretFiles: secretFiles,
+		ProjectPath: projectPath,
+		DryRun:      opts.DryRun,
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	secretsDir := configs.ProjectKanukaSettings.ProjectSecretsPath
+	snapshot, err := snapshotFiles(append(userKeyPaths(secretsDir, userUUIDs), secretFiles...))
+	if err != nil {
+		return nil, fmt.Errorf("backing up files before rekey: %w", err)
+	}
+
+	if _, err := secrets.SyncSecrets(privateKey, secrets.SyncOptions{}); err != nil {
+		return nil, rollbackRekey(snapshot, err)
+	}
+
+	if err := verifyRekey(privateKey, userConfig.User.UUID, userUUIDs, plaintexts); err != nil {
+		return nil, rollbackRekey(snapshot, err)
+	}
+
+	auditEntry := audit.LogWithUser("rekey-all")
+	auditEntry.UsersCount = len(userUUIDs)
+	auditEntry.FilesCount = len(secretFiles)
+	audit.Log(auditEntry)
+
+	return result, nil
+}
+
+// userKeyPaths returns the path of each user's encrypted symmetric key.
+func userKeyPaths(secretsDir string, userUUIDs []string) []string {
+	paths := make([]string, len(userUUIDs))
+	for i, uuid := range userUUIDs {
+		paths[i] = filepath.Join(secretsDir, uuid+".kanuka")
+	}
+	return paths
+}
+
+// fileSnapshot holds the contents of files before a change. A nil entry means
+// the file did not exist and should be removed on restore.
+type fileSnapshot map[string][]byte
+
+// snapshotFiles records the current contents of paths.
+func snapshotFiles(paths []string) (fileSnapshot, error) {
+	snapshot := make(fileSnapshot, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				snapshot[path] = nil
+				continue
+			}
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		snapshot[path] = data
+	}
+	return snapshot, nil
+}
+
+// restore writes every file back to its recorded contents.
+func (s fileSnapshot) restore() error {
+	var errs []error
+	for path, data := range s {
+		if data == nil {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("removing %s: %w", path, err))
+			}
+			continue
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			errs = append(errs, fmt.Errorf("restoring %s: %w", path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// rollbackRekey restores the snapshot after cause and returns an ErrRekeyFailed error.
+func rollbackRekey(snapshot fileSnapshot, cause error) error {
+	if err := snapshot.restore(); err != nil {
+		return fmt.Errorf("%w: %v (rollback also failed: %v)", kerrors.ErrRekeyFailed, cause, err)
+	}
+	return fmt.Errorf("%w: %v", kerrors.ErrRekeyFailed, cause)
+}
+
+// verifyRekey checks that every user retains access and every file decrypts
+// to its original contents under the new symmetric key.
+func verifyRekey(privateKey secrets.PrivateKey, currentUserUUID string, userUUIDs []string, plaintexts map[string][]byte) error {
+	publicKeysDir := configs.ProjectKanukaSettings.ProjectPublicKeyPath
+
+	// Other users' keys can't be unwrapped without their private keys, but the
+	// wrapped key must at least have the shape expected for their key type.
+	for _, uuid := range userUUIDs {
+		wrapped, err := secrets.GetProjectKanukaKey(uuid)
+		if err != nil {
+			return fmt.Errorf("verifying key for user %s: %w", uuid, err)
+		}
+		if _, err := os.Stat(secrets.GPGPublicKeyPath(uuid)); err == nil {
+			if !secrets.IsGPGWrapped(wrapped) {
+				return fmt.Errorf("verifying key for user %s: wrapped key is not wrapped for their GPG key", uuid)
+			}
+			continue
+		}
+		publicKey, err := secrets.LoadPublicKey(filepath.Join(publicKeysDir, uuid+".pub"))
+		if err != nil {
+			return fmt.Errorf("verifying key for user %s: %w", uuid, err)
+		}
+		if !secrets.WrappedKeyMatches(wrapped, publicKey) {
+			return fmt.Errorf("verifying key for user %s: wrapped key does not match their public key", uuid)
+		}
+	}
+
+	wrapped, err := secrets.GetProjectKanukaKey(currentUserUUID)
+	if err != nil {
+		return fmt.Errorf("verifying your key: %w", err)
+	}
+	newSymKey, err := secrets.DecryptWithPrivateKey(wrapped, privateKey)
+	if err != nil {
+		return fmt.Errorf("verifying your key: %w", err)
+	}
+
+	for path, want := range plaintexts {
+		got, err := secrets.ReadEncryptedFile(newSymKey, path)
+		if err != nil {
+			return fmt.Errorf("verifying %s: %w", path, err)
+		}
+		if !bytes.Equal(got, want) {
+			return fmt.Errorf("verifying %s: contents changed during rekey", path)
+		}
+	}
+
+	return nil
+}
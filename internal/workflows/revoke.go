@@ -2,10 +2,12 @@ package workflows
 
 import (
 	"context"
-	"crypto/rsa"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/PolarWolf314/kanuka/internal/audit"
 	"github.com/PolarWolf314/kanuka/internal/configs"
@@ -24,6 +26,17 @@ type RevokeOptions struct {
 	// DeviceName specifies a specific device to revoke (requires UserEmail).
 	DeviceName string
 
+	// AllExcept revokes every user in the project except these emails, with
+	// a single key rotation at the end. It can't be combined with UserEmail,
+	// FilePath, or DeviceName, and must include the current user's email.
+	AllExcept []string
+
+	// Expired revokes every device whose register --expiry date has passed,
+	// with a single key rotation at the end. The current user's own devices
+	// are left alone. It can't be combined with UserEmail, FilePath,
+	// DeviceName, or AllExcept.
+	Expired bool
+
 	// DryRun previews revocation without making changes.
 	DryRun bool
 
@@ -40,37 +53,41 @@ type RevokeOptions struct {
 // RevokeResult contains the outcome of a revoke operation.
 type RevokeResult struct {
 	// DisplayName is the user-friendly name of who was revoked.
-	DisplayName string
+	DisplayName string `json:"display_name"`
 
 	// RevokedFiles lists the files that were deleted.
-	RevokedFiles []string
+	RevokedFiles []string `json:"revoked_files"`
 
 	// UUIDsRevoked lists the UUIDs that were removed from config.
-	UUIDsRevoked []string
+	UUIDsRevoked []string `json:"uuids_revoked"`
+
+	// RevokedUsers lists, sorted, the emails of the users revoked by
+	// AllExcept, or in a dry run the users that would be revoked.
+	RevokedUsers []string `json:"revoked_users,omitempty"`
 
 	// RemainingUsers is the count of users still in the project.
-	RemainingUsers int
+	RemainingUsers int `json:"remaining_users"`
 
 	// SecretsReEncrypted is the count of secrets re-encrypted.
-	SecretsReEncrypted int
+	SecretsReEncrypted int `json:"secrets_reencrypted"`
 
 	// DryRun indicates whether this was a dry-run (no changes made).
-	DryRun bool
+	DryRun bool `json:"dry_run"`
 
 	// FilesToDelete lists files that would be deleted (for dry-run).
-	FilesToDelete []FileToRevoke
+	FilesToDelete []FileToRevoke `json:"files_to_delete"`
 
 	// AllUsers lists all users currently in the project (for dry-run info).
-	AllUsers []string
+	AllUsers []string `json:"all_users"`
 
 	// KanukaFilesCount is the number of .kanuka secret files (for dry-run info).
-	KanukaFilesCount int
+	KanukaFilesCount int `json:"kanuka_files_count"`
 }
 
 // FileToRevoke represents a file to be revoked.
 type FileToRevoke struct {
-	Path string
-	Name string
+	Path string `json:"path"`
+	Name string `json:"name"`
 }
 
 // revokeContext holds context for the revocation operation.
@@ -78,6 +95,10 @@ type revokeContext struct {
 	displayName  string
 	files        []FileToRevoke
 	uuidsRevoked []string
+
+	// emails maps each revoked UUID to its user's email when several users
+	// are revoked at once, so each gets its own audit entry.
+	emails map[string]string
 }
 
 // Revoke revokes a user's access to project secrets.
@@ -87,9 +108,14 @@ type revokeContext struct {
 // a new key so the revoked user cannot decrypt future secrets.
 //
 // Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrProjectLocked if another operation holds the project lock.
 // Returns ErrUserNotFound if the specified user is not in the project.
 // Returns ErrDeviceNotFound if the specified device is not found.
 // Returns ErrSelfRevoke if attempting to revoke the current user.
+// Returns ErrInvalidArguments if AllExcept is combined with another way of
+// choosing users, leaves out the current user, or leaves nobody to revoke,
+// or if Expired is combined with another way of choosing users.
+// Returns ErrDeviceNotFound if Expired is set and no other device has expired.
 func Revoke(ctx context.Context, opts RevokeOptions) (*RevokeResult, error) {
 	if err := configs.InitProjectSettings(); err != nil {
 		return nil, fmt.Errorf("initializing project settings: %w", err)
@@ -108,12 +134,20 @@ func Revoke(ctx context.Context, opts RevokeOptions) (*RevokeResult, error) {
 		return nil, kerrors.ErrProjectNotInitialized
 	}
 
+	if !opts.DryRun {
+		lock, err := configs.LockProject()
+		if err != nil {
+			return nil, err
+		}
+		defer lock.Unlock()
+	}
+
 	revokeCtx, err := getFilesToRevokeForWorkflow(opts)
 	if err != nil {
 		return nil, err
 	}
 
-	if revokeCtx == nil || len(revokeCtx.files) == 0 {
+	if revokeCtx == nil || (len(revokeCtx.files) == 0 && len(revokeCtx.uuidsRevoked) == 0) {
 		return nil, kerrors.ErrUserNotFound
 	}
 
@@ -126,6 +160,18 @@ func Revoke(ctx context.Context, opts RevokeOptions) (*RevokeResult, error) {
 
 // getFilesToRevokeForWorkflow determines which files to revoke based on options.
 func getFilesToRevokeForWorkflow(opts RevokeOptions) (*revokeContext, error) {
+	if opts.Expired {
+		if opts.UserEmail != "" || opts.FilePath != "" || opts.DeviceName != "" || len(opts.AllExcept) > 0 {
+			return nil, fmt.Errorf("%w: expired can't be combined with a user, file, device, or all-except", kerrors.ErrInvalidArguments)
+		}
+		return getFilesExpiredForWorkflow(time.Now())
+	}
+	if len(opts.AllExcept) > 0 {
+		if opts.UserEmail != "" || opts.FilePath != "" || opts.DeviceName != "" {
+			return nil, fmt.Errorf("%w: all-except can't be combined with a user, file, or device", kerrors.ErrInvalidArguments)
+		}
+		return getFilesAllExceptForWorkflow(opts.AllExcept)
+	}
 	if opts.UserEmail != "" {
 		return getFilesByUserEmailForWorkflow(opts)
 	}
@@ -134,9 +180,6 @@ func getFilesToRevokeForWorkflow(opts RevokeOptions) (*revokeContext, error) {
 
 // getFilesByUserEmailForWorkflow finds files to revoke by user email.
 func getFilesByUserEmailForWorkflow(opts RevokeOptions) (*revokeContext, error) {
-	projectPublicKeyPath := configs.ProjectKanukaSettings.ProjectPublicKeyPath
-	projectSecretsPath := configs.ProjectKanukaSettings.ProjectSecretsPath
-
 	projectConfig, err := configs.LoadProjectConfig()
 	if err != nil {
 		return nil, fmt.Errorf("loading project config: %w", err)
@@ -159,15 +202,7 @@ func getFilesByUserEmailForWorkflow(opts RevokeOptions) (*revokeContext, error)
 	var allUUIDs []string
 	for userUUID := range devices {
 		allUUIDs = append(allUUIDs, userUUID)
-		publicKeyPath := filepath.Join(projectPublicKeyPath, userUUID+".pub")
-		kanukaKeyPath := filepath.Join(projectSecretsPath, userUUID+".kanuka")
-
-		if _, err := os.Stat(publicKeyPath); err == nil {
-			allFiles = append(allFiles, FileToRevoke{Path: publicKeyPath, Name: userUUID + ".pub"})
-		}
-		if _, err := os.Stat(kanukaKeyPath); err == nil {
-			allFiles = append(allFiles, FileToRevoke{Path: kanukaKeyPath, Name: userUUID + ".kanuka"})
-		}
+		allFiles = append(allFiles, existingKeyFiles(userUUID)...)
 	}
 
 	if len(allFiles) == 0 {
@@ -181,35 +216,134 @@ func getFilesByUserEmailForWorkflow(opts RevokeOptions) (*revokeContext, error)
 	}, nil
 }
 
-// getFilesForUUIDForWorkflow finds files for a specific UUID.
-func getFilesForUUIDForWorkflow(userUUID, displayName string) (*revokeContext, error) {
-	projectPublicKeyPath := configs.ProjectKanukaSettings.ProjectPublicKeyPath
-	projectSecretsPath := configs.ProjectKanukaSettings.ProjectSecretsPath
+// getFilesAllExceptForWorkflow finds every user in the project config whose
+// email isn't in keep, and their key files. Each email in keep must be in
+// the project, so a typo can't revoke someone who was meant to keep access.
+func getFilesAllExceptForWorkflow(keep []string) (*revokeContext, error) {
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+	userConfig, err := configs.EnsureUserConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading user config: %w", err)
+	}
 
-	publicKeyPath := filepath.Join(projectPublicKeyPath, userUUID+".pub")
-	kanukaKeyPath := filepath.Join(projectSecretsPath, userUUID+".kanuka")
+	emails := make(map[string]string)
+	inProject := make(map[string]bool)
+	for userUUID, email := range projectConfig.Users {
+		emails[userUUID] = email
+	}
+	for userUUID, device := range projectConfig.Devices {
+		if emails[userUUID] == "" {
+			emails[userUUID] = device.Email
+		}
+	}
+	for _, email := range emails {
+		inProject[email] = true
+	}
 
-	publicKeyExists := false
-	kanukaKeyExists := false
+	keepSet := make(map[string]bool, len(keep))
+	for _, email := range keep {
+		if !inProject[email] {
+			return nil, fmt.Errorf("%w: %s", kerrors.ErrUserNotFound, email)
+		}
+		keepSet[email] = true
+	}
 
-	if _, err := os.Stat(publicKeyPath); err == nil {
-		publicKeyExists = true
+	if !keepSet[emails[userConfig.User.UUID]] {
+		return nil, fmt.Errorf("%w: all-except must include your own email, or no one could rotate the key", kerrors.ErrInvalidArguments)
 	}
-	if _, err := os.Stat(kanukaKeyPath); err == nil {
-		kanukaKeyExists = true
+
+	revoked := make(map[string]string)
+	for userUUID, email := range emails {
+		if !keepSet[email] {
+			revoked[userUUID] = email
+		}
 	}
+	if len(revoked) == 0 {
+		return nil, fmt.Errorf("%w: every user in the project is in all-except, so there is no one to revoke", kerrors.ErrInvalidArguments)
+	}
+	return newBatchRevokeContext("everyone except "+strings.Join(keep, ", "), revoked), nil
+}
 
-	if !publicKeyExists && !kanukaKeyExists {
-		return nil, fmt.Errorf("%w: %s", kerrors.ErrUserNotFound, displayName)
+// getFilesExpiredForWorkflow finds every device whose expiry has passed at
+// now, other than the current user's, and their key files.
+func getFilesExpiredForWorkflow(now time.Time) (*revokeContext, error) {
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+	userConfig, err := configs.EnsureUserConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading user config: %w", err)
+	}
+
+	revoked := make(map[string]string)
+	for _, userUUID := range projectConfig.ExpiredDevices(now) {
+		if userUUID != userConfig.User.UUID {
+			revoked[userUUID] = projectConfig.Devices[userUUID].Email
+		}
+	}
+	if len(revoked) == 0 {
+		return nil, fmt.Errorf("%w: no other devices have expired", kerrors.ErrDeviceNotFound)
+	}
+	return newBatchRevokeContext("expired devices", revoked), nil
+}
+
+// newBatchRevokeContext returns the context for revoking several users at
+// once, given each UUID's email.
+func newBatchRevokeContext(displayName string, emails map[string]string) *revokeContext {
+	revokeCtx := &revokeContext{displayName: displayName, emails: emails}
+	for userUUID := range emails {
+		revokeCtx.uuidsRevoked = append(revokeCtx.uuidsRevoked, userUUID)
+	}
+	sort.Strings(revokeCtx.uuidsRevoked)
+	for _, userUUID := range revokeCtx.uuidsRevoked {
+		revokeCtx.files = append(revokeCtx.files, existingKeyFiles(userUUID)...)
+	}
+	return revokeCtx
+}
+
+// revokedUsers returns the sorted, distinct emails in revokeCtx.emails.
+func (c *revokeContext) revokedUsers() []string {
+	seen := make(map[string]bool)
+	var users []string
+	for _, email := range c.emails {
+		if email != "" && !seen[email] {
+			seen[email] = true
+			users = append(users, email)
+		}
 	}
+	sort.Strings(users)
+	return users
+}
+
+// existingKeyFiles returns the public key, GPG key, and encrypted symmetric
+// key files that exist in the project for userUUID.
+func existingKeyFiles(userUUID string) []FileToRevoke {
+	publicKeyPath := filepath.Join(configs.ProjectKanukaSettings.ProjectPublicKeyPath, userUUID+".pub")
+	kanukaKeyPath := filepath.Join(configs.ProjectKanukaSettings.ProjectSecretsPath, userUUID+".kanuka")
 
 	var files []FileToRevoke
-	if publicKeyExists {
+	if _, err := os.Stat(publicKeyPath); err == nil {
 		files = append(files, FileToRevoke{Path: publicKeyPath, Name: userUUID + ".pub"})
 	}
-	if kanukaKeyExists {
+	if gpgKeyPath := secrets.GPGPublicKeyPath(userUUID); fileExistsForWorkflow(gpgKeyPath) {
+		files = append(files, FileToRevoke{Path: gpgKeyPath, Name: userUUID + ".gpg"})
+	}
+	if _, err := os.Stat(kanukaKeyPath); err == nil {
 		files = append(files, FileToRevoke{Path: kanukaKeyPath, Name: userUUID + ".kanuka"})
 	}
+	return files
+}
+
+// getFilesForUUIDForWorkflow finds files for a specific UUID.
+func getFilesForUUIDForWorkflow(userUUID, displayName string) (*revokeContext, error) {
+	files := existingKeyFiles(userUUID)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: %s", kerrors.ErrUserNotFound, displayName)
+	}
 
 	return &revokeContext{
 		displayName:  displayName,
@@ -271,6 +405,9 @@ func getFilesByPathForWorkflow(filePath string) (*revokeContext, error) {
 	if _, err := os.Stat(publicKeyPath); err == nil {
 		files = append(files, FileToRevoke{Path: publicKeyPath, Name: userUUID + ".pub"})
 	}
+	if gpgKeyPath := secrets.GPGPublicKeyPath(userUUID); fileExistsForWorkflow(gpgKeyPath) {
+		files = append(files, FileToRevoke{Path: gpgKeyPath, Name: userUUID + ".gpg"})
+	}
 
 	return &revokeContext{
 		displayName:  displayName,
@@ -286,7 +423,7 @@ func buildDryRunResult(revokeCtx *revokeContext) (*RevokeResult, error) {
 	kanukaFilesCount := 0
 	projectPath := configs.ProjectKanukaSettings.ProjectPath
 	if projectPath != "" {
-		kanukaFiles, err := secrets.FindEnvOrKanukaFiles(projectPath, []string{}, true)
+		kanukaFiles, err := secrets.FindEnvOrKanukaFiles(projectPath, []string{}, nil, true)
 		if err == nil {
 			kanukaFilesCount = len(kanukaFiles)
 		}
@@ -295,6 +432,7 @@ func buildDryRunResult(revokeCtx *revokeContext) (*RevokeResult, error) {
 	return &RevokeResult{
 		DisplayName:      revokeCtx.displayName,
 		UUIDsRevoked:     revokeCtx.uuidsRevoked,
+		RevokedUsers:     revokeCtx.revokedUsers(),
 		FilesToDelete:    revokeCtx.files,
 		DryRun:           true,
 		AllUsers:         allUsers,
@@ -348,6 +486,7 @@ func executeRevoke(revokeCtx *revokeContext, opts RevokeOptions) (*RevokeResult,
 		DisplayName:    revokeCtx.displayName,
 		RevokedFiles:   revokedFiles,
 		UUIDsRevoked:   revokeCtx.uuidsRevoked,
+		RevokedUsers:   revokeCtx.revokedUsers(),
 		RemainingUsers: len(allUsers),
 		DryRun:         false,
 	}
@@ -372,15 +511,28 @@ func executeRevoke(revokeCtx *revokeContext, opts RevokeOptions) (*RevokeResult,
 		result.SecretsReEncrypted = syncResult.SecretsProcessed
 	}
 
-	auditEntry := audit.LogWithUser("revoke")
-	auditEntry.TargetUser = revokeCtx.displayName
-	if len(revokeCtx.uuidsRevoked) > 0 {
-		auditEntry.TargetUUID = revokeCtx.uuidsRevoked[0]
-	}
-	if opts.DeviceName != "" {
-		auditEntry.Device = opts.DeviceName
+	if revokeCtx.emails != nil {
+		// Log each user revoked in a batch, so the log can be searched by user
+		// but is only written once.
+		auditBatch := audit.NewBatch()
+		for _, uuid := range revokeCtx.uuidsRevoked {
+			auditEntry := audit.LogWithUser("revoke")
+			auditEntry.TargetUser = revokeCtx.emails[uuid]
+			auditEntry.TargetUUID = uuid
+			auditBatch.Add(auditEntry)
+		}
+		_ = auditBatch.Close()
+	} else {
+		auditEntry := audit.LogWithUser("revoke")
+		auditEntry.TargetUser = revokeCtx.displayName
+		if len(revokeCtx.uuidsRevoked) > 0 {
+			auditEntry.TargetUUID = revokeCtx.uuidsRevoked[0]
+		}
+		if opts.DeviceName != "" {
+			auditEntry.Device = opts.DeviceName
+		}
+		audit.Log(auditEntry)
 	}
-	audit.Log(auditEntry)
 
 	// Check if user is revoking themselves.
 	for _, uuid := range revokeCtx.uuidsRevoked {
@@ -392,8 +544,12 @@ func executeRevoke(revokeCtx *revokeContext, opts RevokeOptions) (*RevokeResult,
 	return result, nil
 }
 
-// loadPrivateKeyForRevoke loads the private key from bytes or disk.
-func loadPrivateKeyForRevoke(keyData []byte, projectUUID string) (*rsa.PrivateKey, error) {
+// loadPrivateKeyForRevoke loads the private key from bytes, KANUKA_PRIVATE_KEY, or disk.
+func loadPrivateKeyForRevoke(keyData []byte, projectUUID string) (secrets.PrivateKey, error) {
+	keyData, err := privateKeyDataOrEnv(keyData)
+	if err != nil {
+		return nil, err
+	}
 	if len(keyData) > 0 {
 		return secrets.LoadPrivateKeyFromBytesWithTTYPrompt(keyData)
 	}
@@ -26,6 +26,9 @@ type RotateOptions struct {
 	// PrivateKeyData contains the private key bytes when reading from stdin.
 	// If nil, the private key is loaded from disk.
 	PrivateKeyData []byte
+
+	// DryRun checks that the keypair can be rotated without changing anything.
+	DryRun bool
 }
 
 // RotateResult contains the outcome of a rotate operation.
@@ -44,6 +47,20 @@ type RotateResult struct {
 
 	// ProjectPublicKeyPath is where the new public key was copied (project directory).
 	ProjectPublicKeyPath string
+
+	// KanukaKeyPath is the user's encrypted symmetric key file in the project.
+	KanukaKeyPath string
+
+	// DryRun indicates whether this was a dry-run (no changes made).
+	DryRun bool
+
+	// OtherUsers lists the emails (or UUIDs, if unknown) of the other users in
+	// the project, whose access is unaffected (for dry-run info).
+	OtherUsers []string
+
+	// KanukaFilesCount is the number of .kanuka secret files, which aren't
+	// re-encrypted (for dry-run info).
+	KanukaFilesCount int
 }
 
 // Rotate generates a new keypair and replaces the user's current keys for this project.
@@ -56,6 +73,9 @@ type RotateResult struct {
 //  4. Re-encrypts the symmetric key with the new public key
 //  5. Saves the new private key and updates the public key in both locations
 //
+// With DryRun, it stops after step 2, having checked that the current key
+// decrypts the symmetric key, and returns the paths that would be written.
+//
 // Returns ErrProjectNotInitialized if the project has no .kanuka directory.
 // Returns ErrNoAccess if the user doesn't have a key file for this project.
 // Returns ErrPrivateKeyNotFound if the old private key cannot be loaded.
@@ -106,6 +126,11 @@ func Rotate(ctx context.Context, opts RotateOptions) (*RotateResult, error) {
 		return nil, fmt.Errorf("%w: %v", kerrors.ErrKeyDecryptFailed, err)
 	}
 
+	projectPubKeyPath := filepath.Join(configs.ProjectKanukaSettings.ProjectPublicKeyPath, userUUID+".pub")
+	if opts.DryRun {
+		return buildRotateDryRunResult(projectConfig, userUUID, projectPubKeyPath, userKanukaKeyPath), nil
+	}
+
 	// Generate new keypair.
 	newPrivateKey, newPublicKey, err := generateNewKeypair()
 	if err != nil {
@@ -131,8 +156,6 @@ func Rotate(ctx context.Context, opts RotateOptions) (*RotateResult, error) {
 	}
 
 	// Copy new public key to project.
-	projectPublicKeyPath := configs.ProjectKanukaSettings.ProjectPublicKeyPath
-	projectPubKeyPath := filepath.Join(projectPublicKeyPath, userUUID+".pub")
 	if err := secrets.SavePublicKeyToFile(newPublicKey, projectPubKeyPath); err != nil {
 		return nil, fmt.Errorf("copying public key to project: %w", err)
 	}
@@ -142,12 +165,17 @@ func Rotate(ctx context.Context, opts RotateOptions) (*RotateResult, error) {
 		return nil, fmt.Errorf("saving new encrypted symmetric key: %w", err)
 	}
 
-	// Update key metadata.
+	// Update key metadata, keeping any rotation schedule.
+	now := time.Now()
 	metadata := &configs.KeyMetadata{
 		ProjectName:    projectConfig.Project.Name,
 		ProjectPath:    projectPath,
-		CreatedAt:      time.Now(),
-		LastAccessedAt: time.Now(),
+		CreatedAt:      now,
+		LastAccessedAt: now,
+		LastRotatedAt:  now,
+	}
+	if existing, err := configs.LoadKeyMetadata(projectUUID); err == nil {
+		metadata.RotationIntervalDays = existing.RotationIntervalDays
 	}
 	// Non-critical - just ignore errors.
 	_ = configs.SaveKeyMetadata(projectUUID, metadata)
@@ -162,9 +190,46 @@ func Rotate(ctx context.Context, opts RotateOptions) (*RotateResult, error) {
 		PrivateKeyPath:       privateKeyPath,
 		PublicKeyPath:        publicKeyPath,
 		ProjectPublicKeyPath: projectPubKeyPath,
+		KanukaKeyPath:        userKanukaKeyPath,
 	}, nil
 }
 
+// buildRotateDryRunResult describes what rotating userUUID's keypair would
+// write, and what it would leave alone.
+func buildRotateDryRunResult(projectConfig *configs.ProjectConfig, userUUID, projectPubKeyPath, kanukaKeyPath string) *RotateResult {
+	projectUUID := projectConfig.Project.UUID
+	allUsers, _ := secrets.GetAllUsersInProject()
+	var otherUsers []string
+	for _, uuid := range allUsers {
+		if uuid == userUUID {
+			continue
+		}
+		if email := projectConfig.Users[uuid]; email != "" {
+			otherUsers = append(otherUsers, email)
+		} else {
+			otherUsers = append(otherUsers, uuid)
+		}
+	}
+
+	kanukaFilesCount := 0
+	kanukaFiles, err := secrets.FindEnvOrKanukaFiles(configs.ProjectKanukaSettings.ProjectPath, []string{}, nil, true)
+	if err == nil {
+		kanukaFilesCount = len(kanukaFiles)
+	}
+
+	return &RotateResult{
+		UserUUID:             userUUID,
+		ProjectUUID:          projectUUID,
+		PrivateKeyPath:       configs.GetPrivateKeyPath(projectUUID),
+		PublicKeyPath:        configs.GetPublicKeyPath(projectUUID),
+		ProjectPublicKeyPath: projectPubKeyPath,
+		KanukaKeyPath:        kanukaKeyPath,
+		DryRun:               true,
+		OtherUsers:           otherUsers,
+		KanukaFilesCount:     kanukaFilesCount,
+	}
+}
+
 // generateNewKeypair generates a new RSA keypair.
 func generateNewKeypair() (*rsa.PrivateKey, *rsa.PublicKey, error) {
 	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
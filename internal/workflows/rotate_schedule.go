@@ -0,0 +1,110 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+)
+
+// RotationScheduleOptions configures the rotation schedule workflow.
+type RotationScheduleOptions struct {
+	// IntervalDays is how often the keypair should be rotated. Zero clears the schedule.
+	IntervalDays int
+}
+
+// RotationStatus describes when the user's keypair for this project was last
+// rotated and when the next rotation is due.
+type RotationStatus struct {
+	// ProjectUUID is the UUID of the project.
+	ProjectUUID string
+
+	// LastRotatedAt is when the keypair was last rotated, or created if it
+	// has never been rotated.
+	LastRotatedAt time.Time
+
+	// IntervalDays is the rotation interval. Zero means no schedule is set.
+	IntervalDays int
+
+	// DueAt is when the next rotation is due. Zero if no schedule is set.
+	DueAt time.Time
+
+	// Due is true if a schedule is set and DueAt has passed.
+	Due bool
+}
+
+// SetRotationSchedule records how often the user's keypair for this project
+// should be rotated.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrInvalidArguments if IntervalDays is negative.
+// Returns ErrNoAccess if the user has no key metadata for this project.
+func SetRotationSchedule(ctx context.Context, opts RotationScheduleOptions) (*RotationStatus, error) {
+	if opts.IntervalDays < 0 {
+		return nil, fmt.Errorf("%w: rotation interval must be zero or more days", kerrors.ErrInvalidArguments)
+	}
+
+	projectUUID, metadata, err := loadRotationMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	metadata.RotationIntervalDays = opts.IntervalDays
+	if err := configs.SaveKeyMetadata(projectUUID, metadata); err != nil {
+		return nil, fmt.Errorf("saving key metadata: %w", err)
+	}
+
+	return rotationStatus(projectUUID, metadata, time.Now()), nil
+}
+
+// CheckRotation reports whether the user's keypair for this project is due
+// for rotation. It only reads key metadata and does not need decrypt access.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrNoAccess if the user has no key metadata for this project.
+func CheckRotation(ctx context.Context) (*RotationStatus, error) {
+	projectUUID, metadata, err := loadRotationMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	return rotationStatus(projectUUID, metadata, time.Now()), nil
+}
+
+// loadRotationMetadata loads the current project's UUID and the user's key metadata for it.
+func loadRotationMetadata() (string, *configs.KeyMetadata, error) {
+	if err := configs.InitProjectSettings(); err != nil {
+		return "", nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	if configs.ProjectKanukaSettings.ProjectPath == "" {
+		return "", nil, kerrors.ErrProjectNotInitialized
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return "", nil, fmt.Errorf("loading project config: %w", err)
+	}
+	projectUUID := projectConfig.Project.UUID
+
+	metadata, err := configs.LoadKeyMetadata(projectUUID)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", kerrors.ErrNoAccess, err)
+	}
+
+	return projectUUID, metadata, nil
+}
+
+// rotationStatus builds a RotationStatus from key metadata as of now.
+func rotationStatus(projectUUID string, metadata *configs.KeyMetadata, now time.Time) *RotationStatus {
+	dueAt := metadata.RotationDueAt()
+	return &RotationStatus{
+		ProjectUUID:   projectUUID,
+		LastRotatedAt: metadata.LastRotation(),
+		IntervalDays:  metadata.RotationIntervalDays,
+		DueAt:         dueAt,
+		Due:           !dueAt.IsZero() && !now.Before(dueAt),
+	}
+}
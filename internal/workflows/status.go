@@ -2,6 +2,7 @@ package workflows
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -27,6 +28,23 @@ const (
 	StatusEncryptedOnly FileStatus = "encrypted_only"
 )
 
+// AccessStatus describes whether the current user can decrypt the project.
+type AccessStatus string
+
+const (
+	// AccessGranted means the user's private key decrypts the project's symmetric key.
+	AccessGranted AccessStatus = "granted"
+	// AccessNotRegistered means the project has no encrypted symmetric key for the user.
+	AccessNotRegistered AccessStatus = "not_registered"
+	// AccessPrivateKeyMissing means the user's private key could not be found or read.
+	AccessPrivateKeyMissing AccessStatus = "private_key_missing"
+	// AccessKeyMismatch means the private key does not decrypt the user's symmetric key.
+	AccessKeyMismatch AccessStatus = "key_mismatch"
+	// AccessUnchecked means the private key is passphrase-protected or the key
+	// is GPG-wrapped, so access was not checked to avoid prompting.
+	AccessUnchecked AccessStatus = "unchecked"
+)
+
 // FileStatusInfo holds information about a file's encryption status.
 type FileStatusInfo struct {
 	// Path is the relative path of the file.
@@ -72,6 +90,14 @@ type StatusResult struct {
 
 	// Summary contains counts of files by status.
 	Summary StatusSummary
+
+	// Access is whether the current user can decrypt the project.
+	Access AccessStatus
+
+	// AccessError explains why Access is not AccessGranted. It wraps
+	// ErrNoAccess, ErrPrivateKeyNotFound, or ErrKeyDecryptFailed, and is nil
+	// when access was granted or not checked.
+	AccessError error
 }
 
 // Status checks the encryption status of all secret files in the project.
@@ -82,6 +108,10 @@ type StatusResult struct {
 //   - unencrypted: plaintext exists with no encrypted version
 //   - encrypted_only: encrypted exists with no plaintext
 //
+// It also checks whether the current user's private key can decrypt the
+// project. Lack of access does not fail the workflow; it is reported in
+// Access and AccessError so the file statuses are still shown.
+//
 // Returns ErrProjectNotInitialized if the project has no .kanuka directory.
 // Returns ErrInvalidProjectConfig if the project config is malformed.
 func Status(ctx context.Context, opts StatusOptions) (*StatusResult, error) {
@@ -119,23 +149,76 @@ func Status(ctx context.Context, opts StatusOptions) (*StatusResult, error) {
 		return files[i].Path < files[j].Path
 	})
 
+	access, accessErr := checkAccess(projectConfig.Project.UUID)
+
 	return &StatusResult{
 		ProjectName: projectName,
 		Files:       files,
 		Summary:     calculateStatusSummary(files),
+		Access:      access,
+		AccessError: accessErr,
 	}, nil
 }
 
+// checkAccess reports whether the current user's private key decrypts their
+// copy of the project's symmetric key. It never prompts for a passphrase.
+func checkAccess(projectUUID string) (AccessStatus, error) {
+	userConfig, err := configs.LoadUserConfig()
+	if err != nil || userConfig.User.UUID == "" {
+		return AccessNotRegistered, fmt.Errorf("%w: no user identity configured", kerrors.ErrNoAccess)
+	}
+
+	encryptedSymKey, err := secrets.GetProjectKanukaKey(userConfig.User.UUID)
+	if err != nil {
+		return AccessNotRegistered, fmt.Errorf("%w: %v", kerrors.ErrNoAccess, err)
+	}
+
+	// Checking a GPG-wrapped key would need gpg and possibly a passphrase prompt.
+	if secrets.IsGPGWrapped(encryptedSymKey) {
+		return AccessUnchecked, nil
+	}
+
+	keyData, err := privateKeyDataOrEnv(nil)
+	if err != nil {
+		return AccessPrivateKeyMissing, fmt.Errorf("%w: %v", kerrors.ErrPrivateKeyNotFound, err)
+	}
+	if keyData == nil {
+		keyData, err = os.ReadFile(configs.GetPrivateKeyPath(projectUUID))
+		if err != nil {
+			return AccessPrivateKeyMissing, fmt.Errorf("%w: %v", kerrors.ErrPrivateKeyNotFound, err)
+		}
+	}
+
+	privateKey, err := secrets.ParsePrivateKeyBytes(keyData)
+	if err != nil {
+		if errors.Is(err, secrets.ErrPassphraseRequired) {
+			return AccessUnchecked, nil
+		}
+		return AccessPrivateKeyMissing, fmt.Errorf("%w: %v", kerrors.ErrPrivateKeyNotFound, err)
+	}
+
+	if _, err := secrets.DecryptWithPrivateKey(encryptedSymKey, privateKey); err != nil {
+		return AccessKeyMismatch, fmt.Errorf("%w: %v", kerrors.ErrKeyDecryptFailed, err)
+	}
+
+	return AccessGranted, nil
+}
+
 // discoverFileStatuses finds all .env and .kanuka files and determines their status.
 func discoverFileStatuses(projectPath string) ([]FileStatusInfo, error) {
+	rules, err := secrets.LoadIgnoreRules(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
 	// Find all plaintext .env files (excluding .kanuka directory).
-	envFiles, err := secrets.FindEnvOrKanukaFiles(projectPath, []string{}, false)
+	envFiles, err := secrets.FindEnvOrKanukaFiles(projectPath, []string{}, rules, false)
 	if err != nil {
 		return nil, fmt.Errorf("finding env files: %w", err)
 	}
 
 	// Find all encrypted .kanuka files.
-	kanukaFiles, err := secrets.FindEnvOrKanukaFiles(projectPath, []string{}, true)
+	kanukaFiles, err := secrets.FindEnvOrKanukaFiles(projectPath, []string{}, rules, true)
 	if err != nil {
 		return nil, fmt.Errorf("finding kanuka files: %w", err)
 	}
@@ -46,6 +46,7 @@ type SyncResult struct {
 // with their public key.
 //
 // Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrProjectLocked if another operation holds the project lock.
 // Returns ErrPrivateKeyNotFound if the private key cannot be loaded.
 // Returns ErrKeyDecryptFailed if the symmetric key cannot be decrypted.
 func Sync(ctx context.Context, opts SyncOptions) (*SyncResult, error) {
@@ -58,6 +59,14 @@ func Sync(ctx context.Context, opts SyncOptions) (*SyncResult, error) {
 		return nil, kerrors.ErrProjectNotInitialized
 	}
 
+	if !opts.DryRun {
+		lock, err := configs.LockProject()
+		if err != nil {
+			return nil, err
+		}
+		defer lock.Unlock()
+	}
+
 	// Load project config for project UUID.
 	projectConfig, err := configs.LoadProjectConfig()
 	if err != nil {
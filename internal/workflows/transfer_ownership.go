@@ -0,0 +1,130 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/utils"
+)
+
+// TransferOwnershipOptions configures the transfer-ownership workflow.
+type TransferOwnershipOptions struct {
+	// UserEmail is the email of the user who becomes an owner.
+	UserEmail string
+
+	// StepDown removes the current user from the owners once UserEmail has
+	// been added.
+	StepDown bool
+
+	// PrivateKeyData contains the private key bytes when reading from stdin.
+	// If nil, the private key is loaded from disk.
+	PrivateKeyData []byte
+}
+
+// TransferOwnershipResult contains the outcome of a transfer-ownership
+// operation.
+type TransferOwnershipResult struct {
+	// UserEmail is the email of the new owner.
+	UserEmail string
+
+	// Owners lists the project's owners after the transfer.
+	Owners []string
+
+	// FilesCreated lists the new owner's encrypted key files that didn't
+	// exist before.
+	FilesCreated []string
+
+	// FilesUpdated lists the new owner's encrypted key files that were
+	// overwritten.
+	FilesUpdated []string
+
+	// SkippedUUIDs lists the new owner's devices that have no public key.
+	SkippedUUIDs []string
+}
+
+// TransferOwnership makes an existing user a project owner, so they can
+// manage the project even after the current owners have left.
+//
+// The symmetric key is re-wrapped for each of the new owner's devices, as
+// with Rekey, and their email is added to project.owners in the project
+// config. If the project had no owners yet, the current user is recorded as
+// one too unless StepDown is set.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrProjectLocked if another operation holds the project lock.
+// Returns ErrInvalidEmail if UserEmail is malformed.
+// Returns ErrNoAccess if the current user doesn't have access to the project.
+// Returns ErrNotProjectOwner if the project has owners and the current user
+// isn't one of them.
+// Returns ErrUserNotFound if no user in the project config has UserEmail.
+// Returns ErrPublicKeyNotFound if none of the user's devices has a public key.
+// Returns ErrKeyDecryptFailed if the symmetric key cannot be decrypted.
+func TransferOwnership(ctx context.Context, opts TransferOwnershipOptions) (*TransferOwnershipResult, error) {
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	if configs.ProjectKanukaSettings.ProjectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+
+	lock, err := configs.LockProject()
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	if !utils.IsValidEmail(opts.UserEmail) {
+		return nil, fmt.Errorf("%w: %s", kerrors.ErrInvalidEmail, opts.UserEmail)
+	}
+
+	userConfig, err := configs.EnsureUserConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading user config: %w", err)
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+
+	currentEmail, ok := projectConfig.Users[userConfig.User.UUID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is not registered in this project", kerrors.ErrNoAccess, userConfig.User.UUID)
+	}
+	if !projectConfig.IsOwner(currentEmail) {
+		return nil, fmt.Errorf("%w: %s", kerrors.ErrNotProjectOwner, currentEmail)
+	}
+
+	rekeyed, err := rewrapKeyForUser(userConfig, projectConfig, opts.UserEmail, opts.PrivateKeyData)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(projectConfig.Project.Owners) == 0 && !opts.StepDown {
+		projectConfig.AddOwner(currentEmail)
+	}
+	projectConfig.AddOwner(opts.UserEmail)
+	if opts.StepDown && currentEmail != opts.UserEmail {
+		projectConfig.RemoveOwner(currentEmail)
+	}
+
+	if err := configs.SaveProjectConfig(projectConfig); err != nil {
+		return nil, fmt.Errorf("saving project config: %w", err)
+	}
+
+	auditEntry := audit.LogWithUser("transfer-ownership")
+	auditEntry.TargetUser = opts.UserEmail
+	audit.Log(auditEntry)
+
+	return &TransferOwnershipResult{
+		UserEmail:    opts.UserEmail,
+		Owners:       projectConfig.Project.Owners,
+		FilesCreated: rekeyed.FilesCreated,
+		FilesUpdated: rekeyed.FilesUpdated,
+		SkippedUUIDs: rekeyed.SkippedUUIDs,
+	}, nil
+}
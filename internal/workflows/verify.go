@@ -0,0 +1,238 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+)
+
+// VerifyOptions configures the verify workflow.
+type VerifyOptions struct {
+	// PrivateKeyData contains the private key bytes when reading from stdin.
+	// If nil, the private key is loaded from disk.
+	PrivateKeyData []byte
+}
+
+// VerifyStatus is the outcome of checking one encrypted file or wrapped key.
+type VerifyStatus string
+
+const (
+	// VerifyOK means the file or key was checked and is valid.
+	VerifyOK VerifyStatus = "ok"
+
+	// VerifyFailed means the file or key is invalid.
+	VerifyFailed VerifyStatus = "failed"
+
+	// VerifyUnchecked means a wrapped key has the shape expected for its
+	// owner's public key, but decrypting it needs their private key.
+	VerifyUnchecked VerifyStatus = "unchecked"
+)
+
+// VerifiedFile is the outcome of decrypting one .kanuka file.
+type VerifiedFile struct {
+	// Path is the path relative to the project root.
+	Path string `json:"path"`
+
+	// Status is ok or failed.
+	Status VerifyStatus `json:"status"`
+
+	// Error explains why the file failed to decrypt.
+	Error string `json:"error,omitempty"`
+}
+
+// VerifiedKey is the outcome of checking one user's wrapped symmetric key.
+type VerifiedKey struct {
+	// UUID is the user's unique identifier.
+	UUID string `json:"uuid"`
+
+	// Email is the user's email address, if known.
+	Email string `json:"email,omitempty"`
+
+	// Current is true for the key belonging to the user running verify.
+	Current bool `json:"current"`
+
+	// Status is ok, failed, or unchecked.
+	Status VerifyStatus `json:"status"`
+
+	// Error explains why the key failed the check.
+	Error string `json:"error,omitempty"`
+}
+
+// VerifyResult contains the outcome of a verify operation.
+type VerifyResult struct {
+	// ProjectPath is the root path of the project.
+	ProjectPath string `json:"project_path"`
+
+	// Files lists every .kanuka file and whether it decrypted.
+	Files []VerifiedFile `json:"files"`
+
+	// Keys lists every wrapped symmetric key in .kanuka/secrets/.
+	Keys []VerifiedKey `json:"keys"`
+
+	// Failed is the number of files and keys that failed.
+	Failed int `json:"failed"`
+}
+
+// Verify checks that the project's encrypted files and wrapped keys are valid
+// without writing any plaintext to disk.
+//
+// It decrypts the user's wrapped symmetric key, then decrypts every .kanuka
+// file with it, ignoring .kanuka/ignore. Other users' wrapped keys can't be
+// decrypted without their private keys, so they are checked for the shape
+// expected for their public key and reported as unchecked.
+//
+// A file or key that fails is recorded in the result, not returned as an
+// error; check VerifyResult.Failed.
+//
+// Returns ErrProjectNotInitialized if the project has no .kanuka directory.
+// Returns ErrNoAccess if the user doesn't have a key file for this project.
+// Returns ErrKeyDecryptFailed if the private key cannot decrypt the symmetric key.
+// Returns ErrGPGNotFound if the symmetric key was wrapped for a GPG key and gpg is not on PATH.
+// Returns ErrInvalidKeyLength if the symmetric key is not 32 bytes.
+// Returns ErrNoFilesFound if the project has no .kanuka files.
+func Verify(ctx context.Context, opts VerifyOptions) (*VerifyResult, error) {
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	projectPath := configs.ProjectKanukaSettings.ProjectPath
+	if projectPath == "" {
+		return nil, kerrors.ErrProjectNotInitialized
+	}
+
+	userConfig, err := configs.EnsureUserConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading user config: %w", err)
+	}
+	userUUID := userConfig.User.UUID
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+
+	encryptedSymKey, err := secrets.GetProjectKanukaKey(userUUID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrNoAccess, err)
+	}
+
+	symKey, err := unwrapSymmetricKey(encryptedSymKey, opts.PrivateKeyData, projectConfig.Project.UUID, loadPrivateKeyForDecrypt)
+	if err != nil {
+		return nil, err
+	}
+	if len(symKey) != 32 {
+		return nil, fmt.Errorf("%w: your wrapped key decrypts to %d bytes", kerrors.ErrInvalidKeyLength, len(symKey))
+	}
+
+	kanukaFiles, err := secrets.FindEnvOrKanukaFiles(projectPath, []string{}, nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("finding encrypted files: %w", err)
+	}
+	if len(kanukaFiles) == 0 {
+		return nil, kerrors.ErrNoFilesFound
+	}
+
+	result := &VerifyResult{
+		ProjectPath: projectPath,
+	}
+
+	for _, path := range kanukaFiles {
+		file := VerifiedFile{
+			Path:   relativeToProject(projectPath, path),
+			Status: VerifyOK,
+		}
+		if err := secrets.VerifyEncryptedFile(symKey, path); err != nil {
+			file.Status = VerifyFailed
+			file.Error = err.Error()
+			result.Failed++
+		}
+		result.Files = append(result.Files, file)
+	}
+
+	keys, err := verifyWrappedKeys(projectConfig, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if key.Status == VerifyFailed {
+			result.Failed++
+		}
+	}
+	result.Keys = keys
+
+	return result, nil
+}
+
+// verifyWrappedKeys checks every wrapped key in .kanuka/secrets/. The current
+// user's key has already been unwrapped by the caller, so it is reported ok.
+func verifyWrappedKeys(projectConfig *configs.ProjectConfig, currentUserUUID string) ([]VerifiedKey, error) {
+	secretsDir := configs.ProjectKanukaSettings.ProjectSecretsPath
+	publicKeysDir := configs.ProjectKanukaSettings.ProjectPublicKeyPath
+
+	entries, err := os.ReadDir(secretsDir)
+	if err != nil {
+		return nil, fmt.Errorf("reading secrets directory: %w", err)
+	}
+
+	var keys []VerifiedKey
+	for _, entry := range entries {
+		uuid, ok := strings.CutSuffix(entry.Name(), ".kanuka")
+		if entry.IsDir() || !ok {
+			continue
+		}
+
+		key := VerifiedKey{
+			UUID:    uuid,
+			Email:   projectConfig.Users[uuid],
+			Current: uuid == currentUserUUID,
+			Status:  VerifyOK,
+		}
+		if key.Email == "" {
+			key.Email = projectConfig.Devices[uuid].Email
+		}
+		if !key.Current {
+			key.Status, key.Error = checkWrappedKeyShape(uuid, publicKeysDir)
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Current != keys[j].Current {
+			return keys[i].Current
+		}
+		return keys[i].Email < keys[j].Email
+	})
+	return keys, nil
+}
+
+// checkWrappedKeyShape checks that another user's wrapped key matches their
+// public key's type.
+func checkWrappedKeyShape(uuid, publicKeysDir string) (VerifyStatus, string) {
+	wrapped, err := secrets.GetProjectKanukaKey(uuid)
+	if err != nil {
+		return VerifyFailed, err.Error()
+	}
+
+	if _, err := os.Stat(secrets.GPGPublicKeyPath(uuid)); err == nil {
+		if !secrets.IsGPGWrapped(wrapped) {
+			return VerifyFailed, "wrapped key is not wrapped for their GPG key"
+		}
+		return VerifyUnchecked, ""
+	}
+
+	publicKey, err := secrets.LoadPublicKey(filepath.Join(publicKeysDir, uuid+".pub"))
+	if err != nil {
+		return VerifyFailed, "no public key for this wrapped key"
+	}
+	if !secrets.WrappedKeyMatches(wrapped, publicKey) {
+		return VerifyFailed, "wrapped key does not match their public key"
+	}
+	return VerifyUnchecked, ""
+}
@@ -0,0 +1,179 @@
+package workflows
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/utils"
+)
+
+const (
+	// DefaultReleasesURL is the GitHub API endpoint for the latest Kanuka release.
+	DefaultReleasesURL = "https://api.github.com/repos/PolarWolf314/kanuka/releases/latest"
+
+	// DefaultUpdateCheckTimeout bounds how long the update check may wait on the network.
+	DefaultUpdateCheckTimeout = 3 * time.Second
+
+	// DefaultUpdateCheckCacheTTL is how long a successful update check is reused.
+	DefaultUpdateCheckCacheTTL = time.Hour
+
+	// NoUpdateCheckEnvVar disables the update check entirely when set to any non-empty value.
+	NoUpdateCheckEnvVar = "KANUKA_NO_UPDATE_CHECK"
+
+	updateCheckCacheFile = "update-check.toml"
+)
+
+// VersionCheckOptions configures the version check workflow.
+type VersionCheckOptions struct {
+	// CurrentVersion is the version of the running binary.
+	CurrentVersion string
+
+	// ReleasesURL overrides the releases API endpoint. Defaults to DefaultReleasesURL.
+	ReleasesURL string
+
+	// Timeout bounds the network request. Defaults to DefaultUpdateCheckTimeout.
+	Timeout time.Duration
+
+	// CacheTTL controls how long a cached result is reused. Defaults to DefaultUpdateCheckCacheTTL.
+	CacheTTL time.Duration
+
+	// NoCache bypasses reading the cached result (the fresh result is still cached).
+	NoCache bool
+}
+
+// VersionCheckResult contains the outcome of a version check.
+type VersionCheckResult struct {
+	// CurrentVersion is the version of the running binary.
+	CurrentVersion string
+
+	// LatestVersion is the tag of the latest published release.
+	LatestVersion string
+
+	// ReleaseURL is a link to the latest release page.
+	ReleaseURL string
+
+	// UpdateAvailable is true if the latest release is newer than the current version.
+	UpdateAvailable bool
+
+	// Skipped is true if the check was disabled via NoUpdateCheckEnvVar.
+	Skipped bool
+
+	// FromCache is true if the result came from the local cache rather than the network.
+	FromCache bool
+
+	// CheckedAt is when the latest release information was fetched.
+	CheckedAt time.Time
+}
+
+// updateCheckCache is the on-disk representation of a cached update check.
+type updateCheckCache struct {
+	LatestVersion string    `toml:"latest_version"`
+	ReleaseURL    string    `toml:"release_url"`
+	CheckedAt     time.Time `toml:"checked_at"`
+}
+
+// githubRelease is the subset of the GitHub release payload used by the check.
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+}
+
+// CheckVersion reports whether a newer Kanuka release is available.
+//
+// The check is best-effort: it uses a short timeout and caches successful
+// results in the user config directory so repeated invocations do not hit the
+// GitHub API rate limit. Setting KANUKA_NO_UPDATE_CHECK skips the check.
+//
+// Returns ErrUpdateCheckFailed if the latest release could not be determined.
+func CheckVersion(ctx context.Context, opts VersionCheckOptions) (*VersionCheckResult, error) {
+	result := &VersionCheckResult{
+		CurrentVersion: opts.CurrentVersion,
+	}
+
+	if os.Getenv(NoUpdateCheckEnvVar) != "" {
+		result.Skipped = true
+		return result, nil
+	}
+
+	releasesURL := opts.ReleasesURL
+	if releasesURL == "" {
+		releasesURL = DefaultReleasesURL
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultUpdateCheckTimeout
+	}
+	cacheTTL := opts.CacheTTL
+	if cacheTTL <= 0 {
+		cacheTTL = DefaultUpdateCheckCacheTTL
+	}
+
+	cachePath := filepath.Join(configs.UserKanukaSettings.UserConfigsPath, updateCheckCacheFile)
+
+	var cached updateCheckCache
+	if !opts.NoCache && configs.LoadTOML(cachePath, &cached) == nil &&
+		cached.LatestVersion != "" && time.Since(cached.CheckedAt) < cacheTTL {
+		result.FromCache = true
+	} else {
+		release, err := fetchLatestRelease(ctx, releasesURL, timeout)
+		if err != nil {
+			return nil, err
+		}
+		cached = updateCheckCache{
+			LatestVersion: release.TagName,
+			ReleaseURL:    release.HTMLURL,
+			CheckedAt:     time.Now().UTC(),
+		}
+		// Caching is best-effort; a failure here should not fail the check.
+		_ = configs.SaveTOML(cachePath, cached)
+	}
+
+	result.LatestVersion = cached.LatestVersion
+	result.ReleaseURL = cached.ReleaseURL
+	result.CheckedAt = cached.CheckedAt
+	result.UpdateAvailable = utils.IsReleaseVersion(opts.CurrentVersion) &&
+		utils.CompareVersions(opts.CurrentVersion, cached.LatestVersion) < 0
+
+	return result, nil
+}
+
+// fetchLatestRelease queries the releases API for the latest published release.
+func fetchLatestRelease(ctx context.Context, url string, timeout time.Duration) (*githubRelease, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrUpdateCheckFailed, err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "kanuka-cli")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrUpdateCheckFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected response status %s", kerrors.ErrUpdateCheckFailed, resp.Status)
+	}
+
+	var release githubRelease
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&release); err != nil {
+		return nil, fmt.Errorf("%w: %v", kerrors.ErrUpdateCheckFailed, err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("%w: release response did not include a tag", kerrors.ErrUpdateCheckFailed)
+	}
+
+	return &release, nil
+}
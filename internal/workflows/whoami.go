@@ -0,0 +1,99 @@
+package workflows
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+)
+
+// WhoamiOptions configures the whoami workflow.
+type WhoamiOptions struct {
+	// No options currently needed - included for consistency.
+}
+
+// WhoamiResult describes the current user's identity and, inside a project,
+// their access to it.
+type WhoamiResult struct {
+	// Email is the user's email from the user config.
+	Email string `json:"email"`
+
+	// UUID is the user's UUID from the user config.
+	UUID string `json:"uuid"`
+
+	// DefaultDevice is the device name used when registering with new projects.
+	DefaultDevice string `json:"default_device,omitempty"`
+
+	// Project is set when the command runs inside a project.
+	Project *WhoamiProject `json:"project,omitempty"`
+}
+
+// WhoamiProject describes the current user's access to a project.
+type WhoamiProject struct {
+	// Name is the project name.
+	Name string `json:"name"`
+
+	// Path is the project root.
+	Path string `json:"path"`
+
+	// DeviceName is the device name this identity has in the project config,
+	// or empty if it isn't registered.
+	DeviceName string `json:"device_name,omitempty"`
+
+	// HasAccess is true if the project has an encrypted symmetric key for
+	// this identity, i.e. .kanuka/secrets/<uuid>.kanuka exists.
+	HasAccess bool `json:"has_access"`
+}
+
+// Whoami reports which identity the user is operating as. Outside a project
+// only the user config identity is returned.
+//
+// Access is determined by the presence of the user's encrypted symmetric key;
+// the private key is not used, so this never prompts for a passphrase.
+//
+// Returns ErrInvalidProjectConfig if the project config is malformed.
+func Whoami(ctx context.Context, opts WhoamiOptions) (*WhoamiResult, error) {
+	userConfig, err := configs.EnsureUserConfig()
+	if err != nil {
+		return nil, fmt.Errorf("loading user config: %w", err)
+	}
+
+	result := &WhoamiResult{
+		Email:         userConfig.User.Email,
+		UUID:          userConfig.User.UUID,
+		DefaultDevice: userConfig.User.DefaultDeviceName,
+	}
+
+	if err := configs.InitProjectSettings(); err != nil {
+		return nil, fmt.Errorf("initializing project settings: %w", err)
+	}
+
+	projectPath := configs.ProjectKanukaSettings.ProjectPath
+	if projectPath == "" {
+		return result, nil
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", kerrors.ErrInvalidProjectConfig, err)
+	}
+
+	project := &WhoamiProject{
+		Name: projectConfig.Project.Name,
+		Path: projectPath,
+		HasAccess: fileExistsCheck(filepath.Join(
+			configs.ProjectKanukaSettings.ProjectSecretsPath, result.UUID+".kanuka")),
+	}
+	if project.Name == "" {
+		project.Name = configs.ProjectKanukaSettings.ProjectName
+	}
+
+	if device, ok := projectConfig.Devices[result.UUID]; ok {
+		project.DeviceName = device.Name
+	}
+
+	result.Project = project
+	return result, nil
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/PolarWolf314/kanuka/cmd"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 
 	"github.com/spf13/cobra"
 )
@@ -30,11 +31,16 @@ Features:
 }
 
 func main() {
+	cmd.SetVersion(version)
+	cmd.AddColorFlag(rootCmd)
+	cmd.AddUserDirFlags(rootCmd)
 	rootCmd.AddCommand(cmd.SecretsCmd)
 	rootCmd.AddCommand(cmd.ConfigCmd)
+	rootCmd.AddCommand(cmd.AuditCmd)
+	rootCmd.AddCommand(cmd.VersionCmd)
 
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		cmd.PrintError(err)
+		os.Exit(kerrors.ExitCode(err))
 	}
 }
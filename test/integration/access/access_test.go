@@ -2,6 +2,7 @@ package access
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -418,8 +420,8 @@ func TestAccess_NotInitialized(t *testing.T) {
 		testCmd := shared.CreateTestCLIWithArgs("access", []string{}, nil, nil, false, false)
 		return testCmd.Execute()
 	})
-	if err != nil {
-		t.Fatalf("Access command failed: %v", err)
+	if !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Fatalf("Expected ErrProjectNotInitialized, got: %v", err)
 	}
 
 	// Verify error message.
@@ -0,0 +1,158 @@
+package audit_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// TestAuditExportIntegration contains integration tests for the `kanuka audit export` command.
+func TestAuditExportIntegration(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get original working directory: %v", err)
+	}
+
+	originalUserSettings := configs.UserKanukaSettings
+
+	t.Run("ExportCSVToFile", func(t *testing.T) {
+		testExportCSVToFile(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("ExportCSVEscapesFilenames", func(t *testing.T) {
+		testExportCSVEscapesFilenames(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("ExportJSONToStdout", func(t *testing.T) {
+		testExportJSONToStdout(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("ExportUnknownFormat", func(t *testing.T) {
+		testExportUnknownFormat(t, originalWd, originalUserSettings)
+	})
+}
+
+func runAuditExport(t *testing.T, args ...string) (string, string) {
+	t.Helper()
+	stdout, stderr, err := shared.CaptureStdoutAndStderr(func() error {
+		return shared.CreateAuditTestCLIWithArgs("export", args, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v\nStdout: %s\nStderr: %s", err, stdout, stderr)
+	}
+	return stdout, stderr
+}
+
+func readCSV(t *testing.T, data string) [][]string {
+	t.Helper()
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("Export is not valid CSV: %v\n%s", err, data)
+	}
+	return records
+}
+
+func testExportCSVToFile(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+	writeQueryTestLog(t, tempDir)
+
+	outputPath := filepath.Join(t.TempDir(), "audit.csv")
+	stdout, _ := runAuditExport(t, "--format", "csv", "-o", outputPath)
+	if !strings.Contains(stdout, "Exported 3 audit entries") {
+		t.Errorf("Expected an export summary, got: %s", stdout)
+	}
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("Expected %s to be written: %v", outputPath, err)
+	}
+	records := readCSV(t, string(data))
+	if len(records) != 4 {
+		t.Fatalf("Expected a header and 3 rows, got %d", len(records))
+	}
+	if strings.Join(records[0], ",") != "timestamp,user,uuid,operation,device,target_user,files" {
+		t.Errorf("Unexpected header: %v", records[0])
+	}
+	if records[3][3] != "revoke" || records[3][5] != "bob@example.com" {
+		t.Errorf("Expected the revoke entry last, got %v", records[3])
+	}
+}
+
+func testExportCSVEscapesFilenames(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	entry := audit.Entry{
+		Timestamp: "2024-01-10T10:00:00.000000Z",
+		User:      "alice@example.com",
+		Operation: "encrypt",
+		Files:     []string{"services/a,b/.env", `say "hi"/.env`},
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("Failed to marshal entry: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ".kanuka", "audit.jsonl"), append(data, '\n'), 0600); err != nil {
+		t.Fatalf("Failed to write audit log: %v", err)
+	}
+
+	stdout, _ := runAuditExport(t)
+	records := readCSV(t, stdout)
+	if len(records) != 2 {
+		t.Fatalf("Expected a header and 1 row, got %d:\n%s", len(records), stdout)
+	}
+	if got := records[1][6]; got != `services/a,b/.env;say "hi"/.env` {
+		t.Errorf("Expected the files column to round-trip, got %q", got)
+	}
+}
+
+func testExportJSONToStdout(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+	writeQueryTestLog(t, tempDir)
+
+	stdout, _ := runAuditExport(t, "--format", "json")
+	var entries []audit.Entry
+	if err := json.Unmarshal([]byte(stdout), &entries); err != nil {
+		t.Fatalf("Expected stdout to be a JSON array: %v\n%s", err, stdout)
+	}
+	if len(entries) != 3 || entries[0].Operation != "encrypt" {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+}
+
+func testExportUnknownFormat(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	stdout, stderr, err := shared.CaptureStdoutAndStderr(func() error {
+		return shared.CreateAuditTestCLIWithArgs("export", []string{"--format", "xml"}, nil, nil, false, false).Execute()
+	})
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Errorf("Expected ErrInvalidArguments, got: %v", err)
+	}
+	if stdout != "" {
+		t.Errorf("Expected nothing on stdout, got: %s", stdout)
+	}
+	if !strings.Contains(stderr, "unknown export format") {
+		t.Errorf("Expected an unknown format error, got: %s", stderr)
+	}
+}
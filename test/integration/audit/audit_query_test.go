@@ -0,0 +1,154 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// TestAuditQueryIntegration contains integration tests for the `kanuka audit query` command.
+func TestAuditQueryIntegration(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get original working directory: %v", err)
+	}
+
+	originalUserSettings := configs.UserKanukaSettings
+
+	t.Run("QueryFiltersEntries", func(t *testing.T) {
+		testQueryFiltersEntries(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("QueryNoMatches", func(t *testing.T) {
+		testQueryNoMatches(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("QueryRelativeSince", func(t *testing.T) {
+		testQueryRelativeSince(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("QueryInvalidSince", func(t *testing.T) {
+		testQueryInvalidSince(t, originalWd, originalUserSettings)
+	})
+}
+
+func runAuditQuery(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateAuditTestCLIWithArgs("query", args, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+	return output
+}
+
+// runAuditQueryExpectingError runs audit query and fails the test unless it returns want.
+func runAuditQueryExpectingError(t *testing.T, want error, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateAuditTestCLIWithArgs("query", args, nil, nil, false, false).Execute()
+	})
+	if !errors.Is(err, want) {
+		t.Fatalf("Expected %v, got: %v\nOutput: %s", want, err, output)
+	}
+	return output
+}
+
+// writeQueryTestLog replaces the project's audit log with a fixed set of entries.
+func writeQueryTestLog(t *testing.T, projectDir string) {
+	t.Helper()
+	entries := []audit.Entry{
+		{Timestamp: "2024-01-10T10:00:00.000000Z", User: "alice@example.com", Operation: "encrypt", Files: []string{".env"}},
+		{Timestamp: "2024-01-11T10:00:00.000000Z", User: "bob@example.com", Operation: "decrypt", Files: []string{".env"}},
+		{Timestamp: "2024-01-12T10:00:00.000000Z", User: "alice@example.com", Operation: "revoke", TargetUser: "bob@example.com"},
+	}
+
+	var lines []string
+	for _, e := range entries {
+		data, err := json.Marshal(e)
+		if err != nil {
+			t.Fatalf("Failed to marshal entry: %v", err)
+		}
+		lines = append(lines, string(data))
+	}
+
+	logPath := filepath.Join(projectDir, ".kanuka", "audit.jsonl")
+	if err := os.WriteFile(logPath, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		t.Fatalf("Failed to write audit log: %v", err)
+	}
+}
+
+func testQueryFiltersEntries(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+	writeQueryTestLog(t, tempDir)
+
+	output := runAuditQuery(t, "--user", "alice@example.com", "--operation", "revoke,encrypt",
+		"--since", "2024-01-11T00:00:00Z", "--until", "2024-01-12")
+
+	if !strings.Contains(output, "OPERATION") {
+		t.Errorf("Expected table header, got: %s", output)
+	}
+	if !strings.Contains(output, "revoke") || !strings.Contains(output, "bob@example.com") {
+		t.Errorf("Expected the revoke entry, got: %s", output)
+	}
+	if strings.Contains(output, "encrypt ") || strings.Contains(output, "decrypt") {
+		t.Errorf("Expected only the revoke entry, got: %s", output)
+	}
+	if !strings.Contains(output, "1 of 3 entries matched") {
+		t.Errorf("Expected match count, got: %s", output)
+	}
+}
+
+func testQueryNoMatches(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+	writeQueryTestLog(t, tempDir)
+
+	output := runAuditQuery(t, "--user", "carol@example.com")
+	if !strings.Contains(output, "No matching entries") {
+		t.Errorf("Expected friendly no-match message, got: %s", output)
+	}
+}
+
+func testQueryRelativeSince(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+	writeQueryTestLog(t, tempDir)
+	runAuditRecord(t, "deploy-prod")
+
+	output := runAuditQuery(t, "--since", "1d")
+	if !strings.Contains(output, "deploy-prod") {
+		t.Errorf("Expected the recent entry, got: %s", output)
+	}
+	if strings.Contains(output, "2024-01-1") {
+		t.Errorf("Expected old entries to be filtered out, got: %s", output)
+	}
+}
+
+func testQueryInvalidSince(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	output := runAuditQueryExpectingError(t, kerrors.ErrInvalidDateFormat, "--since", "last-tuesday")
+	if !strings.Contains(output, "invalid date format") {
+		t.Errorf("Expected invalid date error, got: %s", output)
+	}
+}
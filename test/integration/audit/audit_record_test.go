@@ -0,0 +1,169 @@
+package audit_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// TestAuditRecordIntegration contains integration tests for the `kanuka audit record` command.
+func TestAuditRecordIntegration(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get original working directory: %v", err)
+	}
+
+	originalUserSettings := configs.UserKanukaSettings
+
+	t.Run("RecordWithDetails", func(t *testing.T) {
+		testRecordWithDetails(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("RecordShowsInLog", func(t *testing.T) {
+		testRecordShowsInLog(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("RecordRejectsBuiltinOperation", func(t *testing.T) {
+		testRecordRejectsBuiltinOperation(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("RecordRejectsMalformedDetail", func(t *testing.T) {
+		testRecordRejectsMalformedDetail(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("RecordInEmptyFolder", func(t *testing.T) {
+		testRecordInEmptyFolder(t, originalWd, originalUserSettings)
+	})
+}
+
+func runAuditRecord(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateAuditTestCLIWithArgs("record", args, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+	return output
+}
+
+// runAuditRecordExpectingError runs audit record and fails the test unless it returns want.
+func runAuditRecordExpectingError(t *testing.T, want error, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateAuditTestCLIWithArgs("record", args, nil, nil, false, false).Execute()
+	})
+	if !errors.Is(err, want) {
+		t.Fatalf("Expected %v, got: %v\nOutput: %s", want, err, output)
+	}
+	return output
+}
+
+func readAuditEntries(t *testing.T, projectDir string) []audit.Entry {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(projectDir, ".kanuka", "audit.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	entries, err := audit.ParseEntries(data)
+	if err != nil {
+		t.Fatalf("Failed to parse audit log: %v", err)
+	}
+	return entries
+}
+
+func testRecordWithDetails(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	output := runAuditRecord(t, "deploy-prod", "--detail", "env=production", "--detail", "sha=abc=123")
+	if !strings.Contains(output, "Recorded") {
+		t.Errorf("Expected success message, got: %s", output)
+	}
+
+	entries := readAuditEntries(t, tempDir)
+	last := entries[len(entries)-1]
+	if last.Operation != "deploy-prod" {
+		t.Fatalf("Expected deploy-prod entry, got %+v", last)
+	}
+	if last.User != shared.TestUserEmail || last.UserUUID == "" || last.Timestamp == "" {
+		t.Errorf("Expected entry attributed to current user with a timestamp, got %+v", last)
+	}
+	if last.Details["env"] != "production" || last.Details["sha"] != "abc=123" {
+		t.Errorf("Unexpected details: %v", last.Details)
+	}
+}
+
+func testRecordShowsInLog(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	runAuditRecord(t, "deploy-prod", "--detail", "env=production")
+
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs("log", []string{"--operation", "deploy-prod"}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Log command failed: %v", err)
+	}
+	if !strings.Contains(output, "deploy-prod") || !strings.Contains(output, "env=production") {
+		t.Errorf("Expected custom entry in log output, got: %s", output)
+	}
+}
+
+func testRecordRejectsBuiltinOperation(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	before := len(readAuditEntries(t, tempDir))
+
+	output := runAuditRecordExpectingError(t, kerrors.ErrInvalidAuditOperation, "revoke")
+	if !strings.Contains(output, "built-in operation") {
+		t.Errorf("Expected built-in rejection, got: %s", output)
+	}
+
+	output = runAuditRecordExpectingError(t, kerrors.ErrInvalidAuditOperation, "Deploy Prod")
+	if !strings.Contains(output, "invalid audit operation") {
+		t.Errorf("Expected malformed name rejection, got: %s", output)
+	}
+
+	if after := len(readAuditEntries(t, tempDir)); after != before {
+		t.Errorf("Expected no entries to be written, got %d new", after-before)
+	}
+}
+
+func testRecordRejectsMalformedDetail(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	output := runAuditRecordExpectingError(t, kerrors.ErrInvalidAuditDetail, "deploy-prod", "--detail", "novalue")
+	if !strings.Contains(output, "key=value") {
+		t.Errorf("Expected key=value error, got: %s", output)
+	}
+}
+
+func testRecordInEmptyFolder(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+
+	output := runAuditRecordExpectingError(t, kerrors.ErrProjectNotInitialized, "deploy-prod")
+	if !strings.Contains(output, "has not been initialized") {
+		t.Errorf("Expected not-initialized error, got: %s", output)
+	}
+}
@@ -0,0 +1,124 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// TestAuditSummaryIntegration contains integration tests for the `kanuka audit summary` command.
+func TestAuditSummaryIntegration(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get original working directory: %v", err)
+	}
+
+	originalUserSettings := configs.UserKanukaSettings
+
+	t.Run("SummaryCounts", func(t *testing.T) {
+		testSummaryCounts(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("SummaryJSONWithRange", func(t *testing.T) {
+		testSummaryJSONWithRange(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("SummaryInvalidSince", func(t *testing.T) {
+		testSummaryInvalidSince(t, originalWd, originalUserSettings)
+	})
+}
+
+func runAuditSummary(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateAuditTestCLIWithArgs("summary", args, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+	return output
+}
+
+// runAuditSummaryExpectingError runs audit summary and fails the test unless it returns want.
+func runAuditSummaryExpectingError(t *testing.T, want error, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateAuditTestCLIWithArgs("summary", args, nil, nil, false, false).Execute()
+	})
+	if !errors.Is(err, want) {
+		t.Fatalf("Expected %v, got: %v\nOutput: %s", want, err, output)
+	}
+	return output
+}
+
+func testSummaryCounts(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+	writeQueryTestLog(t, tempDir)
+
+	output := runAuditSummary(t)
+
+	for _, want := range []string{
+		"Audit summary: 3 entries",
+		"Period: 2024-01-10 10:00:00 to 2024-01-12 10:00:00 (2 days)",
+		"Operations:",
+		"Users:",
+		"alice@example.com  2",
+		"Most accessed files:",
+		".env  2",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %q in the summary, got: %s", want, output)
+		}
+	}
+}
+
+func testSummaryJSONWithRange(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+	writeQueryTestLog(t, tempDir)
+
+	stdout, stderr, err := shared.CaptureStdoutAndStderr(func() error {
+		return shared.CreateAuditTestCLIWithArgs("summary", []string{"--since", "2024-01-11", "--json"}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v\nStderr: %s", err, stderr)
+	}
+
+	var stats audit.Stats
+	if err := json.Unmarshal([]byte(stdout), &stats); err != nil {
+		t.Fatalf("Expected JSON stats, got %q: %v", stdout, err)
+	}
+	if stats.TotalEntries != 2 {
+		t.Errorf("Expected 2 entries since 2024-01-11, got %d", stats.TotalEntries)
+	}
+	if len(stats.Operations) != 2 {
+		t.Errorf("Expected decrypt and revoke, got %+v", stats.Operations)
+	}
+	if len(stats.Files) != 1 || stats.Files[0].Name != ".env" || stats.Files[0].Count != 1 {
+		t.Errorf("Expected .env once, got %+v", stats.Files)
+	}
+}
+
+func testSummaryInvalidSince(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	output := runAuditSummaryExpectingError(t, kerrors.ErrInvalidDateFormat, "--since", "last-tuesday")
+	if !strings.Contains(output, "invalid date format") {
+		t.Errorf("Expected invalid date error, got: %s", output)
+	}
+}
@@ -1,12 +1,14 @@
 package ci_init_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/internal/workflows"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
@@ -53,9 +55,8 @@ func testCIInitNotInitialized(t *testing.T, originalWd string, originalUserSetti
 		return cmd.Execute()
 	})
 
-	// Command should not return an error (expected errors are handled internally).
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
+	if !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Errorf("Expected ErrProjectNotInitialized, got: %v", err)
 	}
 
 	// Check output contains expected error message.
@@ -118,8 +119,8 @@ func testCIInitAlreadyConfigured(t *testing.T, originalWd string, originalUserSe
 		return cmd.Execute()
 	})
 
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
+	if !errors.Is(err, kerrors.ErrTTYRequired) {
+		t.Errorf("Expected ErrTTYRequired, got: %v", err)
 	}
 
 	// In non-TTY environment, we expect the TTY error.
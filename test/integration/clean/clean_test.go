@@ -1,12 +1,14 @@
 package clean
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -353,8 +355,8 @@ func TestClean_NotInitialized(t *testing.T) {
 		testCmd := shared.CreateTestCLIWithArgs("clean", []string{"--force"}, nil, nil, false, false)
 		return testCmd.Execute()
 	})
-	if err != nil {
-		t.Fatalf("Clean command failed: %v", err)
+	if !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Fatalf("Expected ErrProjectNotInitialized, got: %v", err)
 	}
 
 	// Verify error message.
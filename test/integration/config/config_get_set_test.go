@@ -0,0 +1,261 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// TestConfigGetSet contains tests for the `kanuka config get` and `kanuka config set` commands.
+func TestConfigGetSet(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get original working directory: %v", err)
+	}
+
+	originalUserSettings := configs.UserKanukaSettings
+
+	t.Run("GetProjectUUID", func(t *testing.T) {
+		testConfigGetProjectUUID(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("SetThenGetProjectName", func(t *testing.T) {
+		testConfigSetThenGetProjectName(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("GetDeviceKey", func(t *testing.T) {
+		testConfigGetDeviceKey(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("GetUnknownKey", func(t *testing.T) {
+		testConfigGetUnknownKey(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("SetUnknownKey", func(t *testing.T) {
+		testConfigSetUnknownKey(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("SetProjectUUIDRefused", func(t *testing.T) {
+		testConfigSetProjectUUIDRefused(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("SetEmptyProjectName", func(t *testing.T) {
+		testConfigSetEmptyProjectName(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("GetOutsideProject", func(t *testing.T) {
+		testConfigGetOutsideProject(t, originalWd, originalUserSettings)
+	})
+}
+
+// setupConfigGetSetProject creates a project for config get/set tests.
+func setupConfigGetSetProject(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir, err := os.MkdirTemp("", "kanuka-test-config-get-set-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tempDir) })
+
+	tempUserDir, err := os.MkdirTemp("", "kanuka-user-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp user directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tempUserDir) })
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
+}
+
+// runConfigCommand runs a config subcommand with args and returns its output.
+func runConfigCommand(t *testing.T, subcommand string, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		cmd := shared.CreateConfigTestCLIWithArgs(subcommand, args, nil, nil, false, false)
+		return cmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed unexpectedly: %v", err)
+	}
+	return output
+}
+
+// runConfigCommandExpectingError runs a config subcommand and fails the test
+// unless it returns want.
+func runConfigCommandExpectingError(t *testing.T, want error, subcommand string, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		cmd := shared.CreateConfigTestCLIWithArgs(subcommand, args, nil, nil, false, false)
+		return cmd.Execute()
+	})
+	if !errors.Is(err, want) {
+		t.Fatalf("Expected %v, got: %v\nOutput: %s", want, err, output)
+	}
+	return output
+}
+
+// Tests that get prints the project UUID on its own.
+func testConfigGetProjectUUID(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	setupConfigGetSetProject(t, originalWd, originalUserSettings)
+
+	output := runConfigCommand(t, "get", "project.uuid")
+	if strings.TrimSpace(output) != shared.TestProjectUUID {
+		t.Errorf("Expected %q, got: %q", shared.TestProjectUUID, output)
+	}
+}
+
+// Tests that a value set with set is saved and read back by get.
+func testConfigSetThenGetProjectName(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	setupConfigGetSetProject(t, originalWd, originalUserSettings)
+
+	output := runConfigCommand(t, "set", "project.name", "renamed-project")
+	if !strings.Contains(output, "Set") || !strings.Contains(output, "renamed-project") {
+		t.Errorf("Expected success message, got: %s", output)
+	}
+
+	output = runConfigCommand(t, "get", "project.name")
+	if strings.TrimSpace(output) != "renamed-project" {
+		t.Errorf("Expected 'renamed-project', got: %q", output)
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	if projectConfig.Project.Name != "renamed-project" {
+		t.Errorf("Expected project config name 'renamed-project', got %q", projectConfig.Project.Name)
+	}
+	if projectConfig.Project.UUID != shared.TestProjectUUID {
+		t.Errorf("Expected project UUID to be unchanged, got %q", projectConfig.Project.UUID)
+	}
+}
+
+// Tests reading a device's fields by UUID.
+func testConfigGetDeviceKey(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	setupConfigGetSetProject(t, originalWd, originalUserSettings)
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	projectConfig.Users[shared.TestUser2UUID] = shared.TestUser2Email
+	projectConfig.Devices[shared.TestUser2UUID] = configs.DeviceConfig{
+		Email:     shared.TestUser2Email,
+		Name:      "workstation",
+		CreatedAt: time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC),
+	}
+	if err := configs.SaveProjectConfig(projectConfig); err != nil {
+		t.Fatalf("Failed to save project config: %v", err)
+	}
+
+	output := runConfigCommand(t, "get", "devices."+shared.TestUser2UUID+".name")
+	if strings.TrimSpace(output) != "workstation" {
+		t.Errorf("Expected 'workstation', got: %q", output)
+	}
+
+	output = runConfigCommand(t, "get", "devices."+shared.TestUser2UUID+".created_at")
+	if strings.TrimSpace(output) != "2025-03-14T09:30:00Z" {
+		t.Errorf("Expected RFC 3339 timestamp, got: %q", output)
+	}
+
+	output = runConfigCommandExpectingError(t, kerrors.ErrReadOnlyConfigKey, "set", "devices."+shared.TestUser2UUID+".name", "laptop")
+	if !strings.Contains(output, "can't be set") {
+		t.Errorf("Expected device keys to be read-only, got: %s", output)
+	}
+
+	output = runConfigCommandExpectingError(t, kerrors.ErrDeviceNotFound, "get", "devices.no-such-uuid.name")
+	if !strings.Contains(output, "No device found") {
+		t.Errorf("Expected device not found message, got: %s", output)
+	}
+}
+
+// Tests that get lists the valid keys for an unknown key.
+func testConfigGetUnknownKey(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	setupConfigGetSetProject(t, originalWd, originalUserSettings)
+
+	output := runConfigCommandExpectingError(t, kerrors.ErrUnknownConfigKey, "get", "project.colour")
+	if !strings.Contains(output, "Unknown config key") {
+		t.Errorf("Expected unknown key message, got: %s", output)
+	}
+	if !strings.Contains(output, "project.name") || !strings.Contains(output, "project.uuid") {
+		t.Errorf("Expected valid keys to be listed, got: %s", output)
+	}
+}
+
+// Tests that set rejects an unknown key without changing the config.
+func testConfigSetUnknownKey(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	setupConfigGetSetProject(t, originalWd, originalUserSettings)
+
+	before, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+
+	output := runConfigCommandExpectingError(t, kerrors.ErrUnknownConfigKey, "set", "users", "someone")
+	if !strings.Contains(output, "Unknown config key") {
+		t.Errorf("Expected unknown key message, got: %s", output)
+	}
+
+	after, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	if !after.Project.Equal(before.Project) || len(after.Users) != len(before.Users) {
+		t.Errorf("Expected project config to be unchanged")
+	}
+}
+
+// Tests that the project UUID can't be changed.
+func testConfigSetProjectUUIDRefused(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	setupConfigGetSetProject(t, originalWd, originalUserSettings)
+
+	output := runConfigCommandExpectingError(t, kerrors.ErrReadOnlyConfigKey, "set", "project.uuid", "00000000-0000-0000-0000-000000000000")
+	if !strings.Contains(output, "can't be set") {
+		t.Errorf("Expected read-only message, got: %s", output)
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	if projectConfig.Project.UUID != shared.TestProjectUUID {
+		t.Errorf("Expected project UUID to be unchanged, got %q", projectConfig.Project.UUID)
+	}
+}
+
+// Tests that an empty project name is rejected.
+func testConfigSetEmptyProjectName(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	setupConfigGetSetProject(t, originalWd, originalUserSettings)
+
+	output := runConfigCommandExpectingError(t, kerrors.ErrInvalidConfigValue, "set", "project.name", "  ")
+	if !strings.Contains(output, "Invalid value") {
+		t.Errorf("Expected invalid value message, got: %s", output)
+	}
+}
+
+// Tests that get outside a project explains what went wrong.
+func testConfigGetOutsideProject(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir, err := os.MkdirTemp("", "kanuka-test-config-get-outside-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	tempUserDir, err := os.MkdirTemp("", "kanuka-user-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp user directory: %v", err)
+	}
+	defer os.RemoveAll(tempUserDir)
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+
+	output := runConfigCommandExpectingError(t, kerrors.ErrProjectNotInitialized, "get", "project.name")
+	if !strings.Contains(output, "Not in a Kānuka project directory") {
+		t.Errorf("Expected not-in-project message, got: %s", output)
+	}
+}
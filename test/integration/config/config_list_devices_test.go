@@ -1,12 +1,14 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -323,8 +325,8 @@ func testListDevicesWithNonExistentUserFilter(t *testing.T, originalWd string, o
 		cmd.SetArgs([]string{"config", "list-devices", "--user", "nonexistent@example.com"})
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
+	if !errors.Is(err, kerrors.ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got: %v", err)
 	}
 
 	if !strings.Contains(output, "not found") {
@@ -353,8 +355,8 @@ func testListDevicesOutsideProject(t *testing.T, originalWd string, originalUser
 		cmd := shared.CreateConfigTestCLI("list-devices", nil, nil, false, false)
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
+	if !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Errorf("Expected ErrProjectNotInitialized, got: %v", err)
 	}
 
 	// Should indicate not in a project directory.
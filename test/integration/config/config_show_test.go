@@ -1,11 +1,15 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -41,6 +45,18 @@ func TestConfigShow(t *testing.T) {
 	t.Run("ShowProjectConfigNotInProject", func(t *testing.T) {
 		testConfigShowProjectConfigNotInProject(t, originalWd, originalUserSettings)
 	})
+
+	t.Run("ShowMergedConfig", func(t *testing.T) {
+		testConfigShowMergedConfig(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("ShowMergedConfigJSON", func(t *testing.T) {
+		testConfigShowMergedConfigJSON(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("ShowMergedConfigNotInProject", func(t *testing.T) {
+		testConfigShowMergedConfigNotInProject(t, originalWd, originalUserSettings)
+	})
 }
 
 // testConfigShowUserConfig tests showing user configuration.
@@ -261,8 +277,8 @@ func testConfigShowProjectConfigNotInProject(t *testing.T, originalWd string, or
 		cmd := shared.CreateConfigTestCLIWithArgs("show", []string{"--project"}, nil, nil, false, false)
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Fatalf("Command failed: %v", err)
+	if !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Fatalf("Expected ErrProjectNotInitialized, got: %v", err)
 	}
 
 	// Verify output indicates not in a project.
@@ -273,3 +289,98 @@ func testConfigShowProjectConfigNotInProject(t *testing.T, originalWd string, or
 		t.Errorf("Expected suggestion to run 'secrets init', got: %s", output)
 	}
 }
+
+// testConfigShowMergedConfig tests showing user and project configuration together.
+func testConfigShowMergedConfig(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd := shared.CreateConfigTestCLIWithArgs("show", []string{"--merged"}, nil, nil, false, false)
+		return cmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+
+	userIndex := strings.Index(output, "User Configuration")
+	projectIndex := strings.Index(output, "Project Configuration")
+	if userIndex == -1 || projectIndex == -1 || projectIndex < userIndex {
+		t.Fatalf("Expected the user configuration followed by the project configuration, got: %s", output)
+	}
+	if !strings.Contains(output, filepath.Join(configs.UserKanukaSettings.UserConfigsPath, "config.toml")) {
+		t.Errorf("Expected the user config path in output, got: %s", output)
+	}
+	if !strings.Contains(output, filepath.Join(".kanuka", "config.toml")) {
+		t.Errorf("Expected the project config path in output, got: %s", output)
+	}
+	if !strings.Contains(output, "This Device:") {
+		t.Errorf("Expected this machine's device name in output, got: %s", output)
+	}
+}
+
+// testConfigShowMergedConfigJSON tests showing user and project configuration together in JSON format.
+func testConfigShowMergedConfigJSON(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd := shared.CreateConfigTestCLIWithArgs("show", []string{"--merged", "--json"}, nil, nil, false, false)
+		return cmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+
+	jsonEnd := strings.LastIndex(output, "}")
+	if jsonEnd == -1 {
+		t.Fatalf("Expected JSON output, got: %s", output)
+	}
+	var result struct {
+		UserConfigPath    string                 `json:"user_config_path"`
+		User              *configs.UserConfig    `json:"user"`
+		ProjectConfigPath string                 `json:"project_config_path"`
+		Project           *configs.ProjectConfig `json:"project"`
+	}
+	if err := json.Unmarshal([]byte(output[:jsonEnd+1]), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v\nOutput: %s", err, output)
+	}
+	if result.User == nil || result.User.User.Email != shared.TestUserEmail {
+		t.Errorf("Expected the user config with email %s, got: %+v", shared.TestUserEmail, result.User)
+	}
+	if result.Project == nil || result.Project.Users[shared.TestUserUUID] != shared.TestUserEmail {
+		t.Errorf("Expected the project config listing %s, got: %+v", shared.TestUserEmail, result.Project)
+	}
+	if result.UserConfigPath == "" || result.ProjectConfigPath == "" {
+		t.Errorf("Expected both config paths, got: %q and %q", result.UserConfigPath, result.ProjectConfigPath)
+	}
+}
+
+// testConfigShowMergedConfigNotInProject tests that only the user configuration is shown outside a project.
+func testConfigShowMergedConfigNotInProject(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd := shared.CreateConfigTestCLIWithArgs("show", []string{"--merged"}, nil, nil, false, false)
+		return cmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+
+	if !strings.Contains(output, shared.TestUserEmail) {
+		t.Errorf("Expected email '%s' in output, got: %s", shared.TestUserEmail, output)
+	}
+	if strings.Contains(output, "Project Configuration") {
+		t.Errorf("Expected no project configuration outside a project, got: %s", output)
+	}
+}
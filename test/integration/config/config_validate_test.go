@@ -0,0 +1,112 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupConfigValidateProject initializes a project with one registered user.
+func setupConfigValidateProject(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+	return tempDir
+}
+
+// runConfigValidate runs config validate and returns its output and the exit
+// code it requested, or 0 if it didn't exit.
+func runConfigValidate(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	exitCode := 0
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateConfigTestCLIWithArgs("validate", args, nil, nil, false, false)
+		cmd.SetConfigValidateExitFunc(func(code int) { exitCode = code })
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed unexpectedly: %v", err)
+	}
+	return output, exitCode
+}
+
+// TestConfigValidate_Valid tests that a freshly initialized project passes.
+func TestConfigValidate_Valid(t *testing.T) {
+	setupConfigValidateProject(t)
+
+	output, exitCode := runConfigValidate(t)
+	if exitCode != 0 {
+		t.Errorf("Expected no exit, got code %d\nOutput: %s", exitCode, output)
+	}
+	if !strings.Contains(output, "Config is valid") {
+		t.Errorf("Expected success message, got: %s", output)
+	}
+}
+
+// TestConfigValidate_ReportsOrphans tests that key files and config entries
+// with no matching UUID are reported, and that the command exits non-zero.
+func TestConfigValidate_ReportsOrphans(t *testing.T) {
+	tempDir := setupConfigValidateProject(t)
+
+	orphanUUID := "99999999-9999-9999-9999-999999999999"
+	orphanKey := filepath.Join(tempDir, ".kanuka", "secrets", orphanUUID+".kanuka")
+	if err := os.WriteFile(orphanKey, []byte("stale"), 0600); err != nil {
+		t.Fatalf("Failed to write orphan key: %v", err)
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	projectConfig.Users["88888888-8888-8888-8888-888888888888"] = "ghost@example.com"
+	if err := configs.SaveProjectConfig(projectConfig); err != nil {
+		t.Fatalf("Failed to save project config: %v", err)
+	}
+
+	output, exitCode := runConfigValidate(t)
+	if exitCode == 0 {
+		t.Errorf("Expected a non-zero exit code\nOutput: %s", output)
+	}
+	for _, want := range []string{
+		"user ghost@example.com has no entry in [devices]",
+		"encrypted key belongs to no user or device",
+		orphanUUID + ".kanuka",
+		"Found 2 problem(s)",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got: %s", want, output)
+		}
+	}
+}
+
+// TestConfigValidate_InvalidTOML tests that an unparseable config.toml shows
+// how to restore it from git.
+func TestConfigValidate_InvalidTOML(t *testing.T) {
+	tempDir := setupConfigValidateProject(t)
+
+	configPath := filepath.Join(tempDir, ".kanuka", "config.toml")
+	if err := os.WriteFile(configPath, []byte("[project\nname = "), 0600); err != nil {
+		t.Fatalf("Failed to write invalid config: %v", err)
+	}
+
+	output, exitCode := runConfigValidate(t)
+	if exitCode == 0 {
+		t.Errorf("Expected a non-zero exit code\nOutput: %s", output)
+	}
+	if !strings.Contains(output, "not valid TOML") || !strings.Contains(output, "git checkout .kanuka/config.toml") {
+		t.Errorf("Expected TOML restore hint, got: %s", output)
+	}
+}
@@ -57,7 +57,7 @@ func testWindowsPathHandling(t *testing.T, originalWd string, originalUserSettin
 	defer os.RemoveAll(tempUserDir)
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	// Get the project UUID after initialization
 	projectUUID := shared.GetProjectUUID(t)
@@ -108,7 +108,7 @@ func testUnixPathHandling(t *testing.T, originalWd string, originalUserSettings
 	defer os.RemoveAll(tempUserDir)
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	// Get the project UUID after initialization
 	projectUUID := shared.GetProjectUUID(t)
@@ -173,7 +173,7 @@ func testPathSeparatorHandling(t *testing.T, originalWd string, originalUserSett
 	defer os.RemoveAll(tempUserDir)
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	// Get UUIDs after initialization
 	projectUUID := shared.GetProjectUUID(t)
@@ -239,7 +239,7 @@ func testSpecialCharactersInPaths(t *testing.T, originalWd string, originalUserS
 			defer os.RemoveAll(tempUserDir)
 
 			shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-			shared.InitializeProject(t, tempDir, tempUserDir)
+			shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 			// Get UUIDs after initialization
 			projectUUID := shared.GetProjectUUID(t)
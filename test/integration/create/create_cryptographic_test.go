@@ -55,7 +55,7 @@ func testRSAKeyGeneration(t *testing.T, originalWd string, originalUserSettings
 	defer os.RemoveAll(tempUserDir)
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	// Get project UUID after initialization
 	projectUUID := shared.GetProjectUUID(t)
@@ -71,14 +71,15 @@ func testRSAKeyGeneration(t *testing.T, originalWd string, originalUserSettings
 	privateKeyPath := shared.GetPrivateKeyPath(filepath.Join(tempUserDir, "keys"), projectUUID)
 
 	// Load and validate the private key
-	privateKey, err := secrets.LoadPrivateKey(privateKeyPath)
+	loadedKey, err := secrets.LoadPrivateKey(privateKeyPath)
 	if err != nil {
 		t.Fatalf("Failed to load private key: %v", err)
 	}
 
 	// Verify it's an RSA key and not nil
-	if privateKey == nil { //nolint:staticcheck // This check is intentional to ensure privateKey is not nil
-		t.Fatalf("Private key is nil")
+	privateKey, ok := loadedKey.(*rsa.PrivateKey)
+	if !ok || privateKey == nil { //nolint:staticcheck // This check is intentional to ensure privateKey is not nil
+		t.Fatalf("Private key is not an RSA key: %T", loadedKey)
 	}
 
 	// Verify key size is 2048 bits (privateKey is guaranteed non-nil here)
@@ -119,7 +120,7 @@ func testPEMFormatValidation(t *testing.T, originalWd string, originalUserSettin
 	defer os.RemoveAll(tempUserDir)
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	// Get project UUID after initialization
 	projectUUID := shared.GetProjectUUID(t)
@@ -213,7 +214,7 @@ func testKeyPairMatching(t *testing.T, originalWd string, originalUserSettings *
 	defer os.RemoveAll(tempUserDir)
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	// Get UUIDs after initialization
 	projectUUID := shared.GetProjectUUID(t)
@@ -241,12 +242,21 @@ func testKeyPairMatching(t *testing.T, originalWd string, originalUserSettings *
 		t.Fatalf("Failed to load public key: %v", err)
 	}
 
+	rsaPrivateKey, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		t.Fatalf("Private key is not an RSA key: %T", privateKey)
+	}
+	rsaPublicKey, ok := publicKey.(*rsa.PublicKey)
+	if !ok {
+		t.Fatalf("Public key is not an RSA key: %T", publicKey)
+	}
+
 	// Verify the public key from the private key matches the loaded public key
-	if privateKey.N.Cmp(publicKey.N) != 0 {
+	if rsaPrivateKey.N.Cmp(rsaPublicKey.N) != 0 {
 		t.Errorf("Public key modulus doesn't match private key's public key modulus")
 	}
 
-	if privateKey.E != publicKey.E {
+	if rsaPrivateKey.E != rsaPublicKey.E {
 		t.Errorf("Public key exponent doesn't match private key's public key exponent")
 	}
 
@@ -276,7 +286,7 @@ func testKeyPairMatching(t *testing.T, originalWd string, originalUserSettings *
 	if err != nil {
 		t.Errorf("Failed to load project public key: %v", err)
 	} else {
-		if publicKey.N.Cmp(projectPublicKey.N) != 0 || publicKey.E != projectPublicKey.E {
+		if !rsaPublicKey.Equal(projectPublicKey) {
 			t.Errorf("Project public key doesn't match user public key")
 		}
 	}
@@ -302,7 +312,7 @@ func testKeyUniqueness(t *testing.T, originalWd string, originalUserSettings *co
 		defer os.RemoveAll(tempUserDir)
 
 		shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-		shared.InitializeProject(t, tempDir, tempUserDir)
+		shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 		// Get project UUID after initialization
 		projectUUID := shared.GetProjectUUID(t)
@@ -331,8 +341,8 @@ func testKeyUniqueness(t *testing.T, originalWd string, originalUserSettings *co
 			continue
 		}
 
-		privateKeys = append(privateKeys, privateKey)
-		publicKeys = append(publicKeys, publicKey)
+		privateKeys = append(privateKeys, privateKey.(*rsa.PrivateKey))
+		publicKeys = append(publicKeys, publicKey.(*rsa.PublicKey))
 	}
 
 	// Compare all keys to ensure they're unique
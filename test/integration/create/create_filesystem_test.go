@@ -55,7 +55,7 @@ func testKeyGenerationAndStorage(t *testing.T, originalWd string, originalUserSe
 	defer os.RemoveAll(tempUserDir)
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	_, err = shared.CaptureOutput(func() error {
 		cmd := shared.CreateTestCLI("create", nil, nil, true, false)
@@ -112,7 +112,7 @@ func testPublicKeyCopying(t *testing.T, originalWd string, originalUserSettings
 	defer os.RemoveAll(tempUserDir)
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	_, err = shared.CaptureOutput(func() error {
 		cmd := shared.CreateTestCLI("create", nil, nil, true, false)
@@ -162,7 +162,7 @@ func testDirectoryCreation(t *testing.T, originalWd string, originalUserSettings
 	}
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	_, err = shared.CaptureOutput(func() error {
 		cmd := shared.CreateTestCLI("create", nil, nil, true, false)
@@ -198,7 +198,7 @@ func testFilePermissions(t *testing.T, originalWd string, originalUserSettings *
 	defer os.RemoveAll(tempUserDir)
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	_, err = shared.CaptureOutput(func() error {
 		cmd := shared.CreateTestCLI("create", nil, nil, true, false)
@@ -254,7 +254,7 @@ func testCleanupOperations(t *testing.T, originalWd string, originalUserSettings
 	defer os.RemoveAll(tempUserDir)
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	userUUID := shared.GetUserUUID(t)
 	kanukaFilePath := filepath.Join(tempDir, ".kanuka", "secrets", userUUID+".kanuka")
@@ -265,7 +265,7 @@ func testCleanupOperations(t *testing.T, originalWd string, originalUserSettings
 
 	output, err := shared.CaptureOutput(func() error {
 		cmd := shared.CreateTestCLI("create", nil, nil, true, false)
-		cmd.SetArgs([]string{"secrets", "create", "--force"})
+		cmd.SetArgs([]string{"secrets", "create", "--force", "--yes"})
 		return cmd.Execute()
 	})
 	if err != nil {
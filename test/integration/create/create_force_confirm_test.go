@@ -0,0 +1,166 @@
+package create
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// TestSecretsCreateForceConfirmation tests that create --force warns and asks
+// for confirmation before deleting an existing key.
+func TestSecretsCreateForceConfirmation(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	originalUserSettings := configs.UserKanukaSettings
+
+	t.Run("ConfirmDeletesAndRegenerates", func(t *testing.T) {
+		tempDir, keyPaths := setupForceConfirmProject(t, originalWd, originalUserSettings)
+		originalPubKey := readFileOrFail(t, keyPaths[0])
+
+		output, err := shared.CaptureOutputWithStdin([]byte("y\n"), func() error {
+			cmd.ResetGlobalState()
+			testCmd := shared.CreateTestCLIWithArgs("create", []string{"--force"}, nil, nil, false, false)
+			return testCmd.Execute()
+		})
+		if err != nil {
+			t.Fatalf("Create --force failed: %v\nOutput: %s", err, output)
+		}
+
+		if !strings.Contains(output, "will destroy your current key") {
+			t.Errorf("Expected destructive warning, got: %s", output)
+		}
+		if !strings.Contains(output, "Do you want to continue?") {
+			t.Errorf("Expected confirmation prompt, got: %s", output)
+		}
+		if !strings.Contains(output, "replaced:") || !strings.Contains(output, "deleted:") {
+			t.Errorf("Expected replaced and deleted keys in output, got: %s", output)
+		}
+
+		if bytes.Equal(readFileOrFail(t, keyPaths[0]), originalPubKey) {
+			t.Error("Expected public key to be regenerated")
+		}
+		if _, err := os.Stat(keyPaths[1]); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be deleted", keyPaths[1])
+		}
+		assertDeviceCount(t, tempDir, 1)
+	})
+
+	t.Run("CancelKeepsExistingKey", func(t *testing.T) {
+		_, keyPaths := setupForceConfirmProject(t, originalWd, originalUserSettings)
+		originalPubKey := readFileOrFail(t, keyPaths[0])
+		originalKanukaKey := readFileOrFail(t, keyPaths[1])
+
+		output, err := shared.CaptureOutputWithStdin([]byte("n\n"), func() error {
+			cmd.ResetGlobalState()
+			testCmd := shared.CreateTestCLIWithArgs("create", []string{"--force"}, nil, nil, false, false)
+			return testCmd.Execute()
+		})
+		if err != nil {
+			t.Fatalf("Create --force failed: %v\nOutput: %s", err, output)
+		}
+
+		if !strings.Contains(output, "Key creation cancelled") {
+			t.Errorf("Expected cancellation message, got: %s", output)
+		}
+		if !bytes.Equal(readFileOrFail(t, keyPaths[0]), originalPubKey) {
+			t.Error("Expected public key to be unchanged after cancelling")
+		}
+		if !bytes.Equal(readFileOrFail(t, keyPaths[1]), originalKanukaKey) {
+			t.Error("Expected symmetric key to be unchanged after cancelling")
+		}
+	})
+
+	t.Run("YesSkipsPromptFromSubdirectory", func(t *testing.T) {
+		tempDir, keyPaths := setupForceConfirmProject(t, originalWd, originalUserSettings)
+		originalPubKey := readFileOrFail(t, keyPaths[0])
+
+		subDir := filepath.Join(tempDir, "services", "api")
+		if err := os.MkdirAll(subDir, 0755); err != nil {
+			t.Fatalf("Failed to create subdirectory: %v", err)
+		}
+		if err := os.Chdir(subDir); err != nil {
+			t.Fatalf("Failed to change to subdirectory: %v", err)
+		}
+
+		output, err := shared.CaptureOutput(func() error {
+			cmd.ResetGlobalState()
+			testCmd := shared.CreateTestCLIWithArgs("create", []string{"--force", "--yes"}, nil, nil, false, false)
+			return testCmd.Execute()
+		})
+		if err != nil {
+			t.Fatalf("Create --force --yes failed: %v\nOutput: %s", err, output)
+		}
+
+		if !strings.Contains(output, "will destroy your current key") {
+			t.Errorf("Expected destructive warning even with --yes, got: %s", output)
+		}
+		if strings.Contains(output, "Do you want to continue?") {
+			t.Errorf("Expected no prompt with --yes, got: %s", output)
+		}
+
+		if bytes.Equal(readFileOrFail(t, keyPaths[0]), originalPubKey) {
+			t.Error("Expected public key at the project root to be regenerated")
+		}
+		if _, err := os.Stat(keyPaths[1]); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be deleted", keyPaths[1])
+		}
+		if _, err := os.Stat(filepath.Join(subDir, ".kanuka")); !os.IsNotExist(err) {
+			t.Error("Expected no .kanuka directory to be created in the subdirectory")
+		}
+		assertDeviceCount(t, tempDir, 1)
+	})
+}
+
+// setupForceConfirmProject initializes a project with the current user
+// registered and returns the project path and the user's public and
+// symmetric key paths.
+func setupForceConfirmProject(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) (string, []string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	userUUID := shared.GetUserUUID(t)
+	keyPaths := []string{
+		filepath.Join(tempDir, ".kanuka", "public_keys", userUUID+".pub"),
+		filepath.Join(tempDir, ".kanuka", "secrets", userUUID+".kanuka"),
+	}
+	for _, path := range keyPaths {
+		if _, err := os.Stat(path); err != nil {
+			t.Fatalf("Expected %s to exist after init: %v", path, err)
+		}
+	}
+	return tempDir, keyPaths
+}
+
+func readFileOrFail(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return data
+}
+
+// assertDeviceCount checks how many devices the project config lists.
+func assertDeviceCount(t *testing.T, projectPath string, want int) {
+	t.Helper()
+	configs.ProjectKanukaSettings.ProjectPath = projectPath
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	if len(projectConfig.Devices) != want {
+		t.Errorf("Expected %d device(s), got %d: %+v", want, len(projectConfig.Devices), projectConfig.Devices)
+	}
+}
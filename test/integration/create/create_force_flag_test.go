@@ -51,7 +51,7 @@ func testForceWithExistingKeys(t *testing.T, originalWd string, originalUserSett
 	defer os.RemoveAll(tempUserDir)
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	// Create initial keys
 	_, err = shared.CaptureOutput(func() error {
@@ -87,7 +87,7 @@ func testForceWithExistingKeys(t *testing.T, originalWd string, originalUserSett
 	// Use force flag to recreate keys
 	output, err := shared.CaptureOutput(func() error {
 		cmd := shared.CreateTestCLI("create", nil, nil, true, false)
-		cmd.SetArgs([]string{"secrets", "create", "--force"})
+		cmd.SetArgs([]string{"secrets", "create", "--force", "--yes"})
 		return cmd.Execute()
 	})
 	if err != nil {
@@ -149,7 +149,7 @@ func testForceWithExistingAccess(t *testing.T, originalWd string, originalUserSe
 	defer os.RemoveAll(tempUserDir)
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	// Create initial keys
 	_, err = shared.CaptureOutput(func() error {
@@ -176,7 +176,7 @@ func testForceWithExistingAccess(t *testing.T, originalWd string, originalUserSe
 	// Use force flag
 	output, err := shared.CaptureOutput(func() error {
 		cmd := shared.CreateTestCLI("create", nil, nil, true, false)
-		cmd.SetArgs([]string{"secrets", "create", "--force"})
+		cmd.SetArgs([]string{"secrets", "create", "--force", "--yes"})
 		return cmd.Execute()
 	})
 	if err != nil {
@@ -210,7 +210,7 @@ func testForceWithoutExistingKeys(t *testing.T, originalWd string, originalUserS
 	defer os.RemoveAll(tempUserDir)
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	// Remove any existing keys from init to ensure clean state
 	projectUUID := shared.GetProjectUUID(t)
@@ -279,7 +279,7 @@ func testForceFlagWarnings(t *testing.T, originalWd string, originalUserSettings
 	defer os.RemoveAll(tempUserDir)
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	// Create initial keys
 	_, err = shared.CaptureOutput(func() error {
@@ -293,7 +293,7 @@ func testForceFlagWarnings(t *testing.T, originalWd string, originalUserSettings
 	// Use force flag and capture output
 	output, err := shared.CaptureOutput(func() error {
 		cmd := shared.CreateTestCLI("create", nil, nil, true, false)
-		cmd.SetArgs([]string{"secrets", "create", "--force"})
+		cmd.SetArgs([]string{"secrets", "create", "--force", "--yes"})
 		return cmd.Execute()
 	})
 	if err != nil {
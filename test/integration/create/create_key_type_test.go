@@ -0,0 +1,113 @@
+package create
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+func setupKeyTypeTest(t *testing.T) (string, string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
+	return tempDir, tempUserDir
+}
+
+func runCreateWithArgs(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("create", args, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Create command failed: %v\nOutput: %s", err, output)
+	}
+	return output
+}
+
+// TestCreateKeyType tests that --key-type picks the algorithm of the generated key pair.
+func TestCreateKeyType(t *testing.T) {
+	tests := []struct {
+		keyType string
+		want    secrets.KeyType
+	}{
+		{"", secrets.KeyTypeRSA},
+		{"rsa2048", secrets.KeyTypeRSA},
+		{"ed25519", secrets.KeyTypeEd25519},
+	}
+
+	for _, tt := range tests {
+		t.Run("key-type="+tt.keyType, func(t *testing.T) {
+			tempDir, tempUserDir := setupKeyTypeTest(t)
+
+			var args []string
+			if tt.keyType != "" {
+				args = []string{"--key-type", tt.keyType}
+			}
+			output := runCreateWithArgs(t, args...)
+			if !strings.Contains(output, "Keys created for") {
+				t.Fatalf("Expected keys to be created, got: %s", output)
+			}
+
+			publicKey, err := secrets.LoadPublicKey(filepath.Join(tempDir, ".kanuka", "public_keys", shared.GetUserUUID(t)+".pub"))
+			if err != nil {
+				t.Fatalf("Failed to load the project public key: %v", err)
+			}
+			if keyType, err := secrets.PublicKeyType(publicKey); err != nil || keyType != tt.want {
+				t.Errorf("Expected a %s public key, got %q (%v)", tt.want, keyType, err)
+			}
+
+			privateKeyPath := shared.GetPrivateKeyPath(filepath.Join(tempUserDir, "keys"), shared.GetProjectUUID(t))
+			privateKey, err := secrets.LoadPrivateKey(privateKeyPath)
+			if err != nil {
+				t.Fatalf("Failed to load the private key: %v", err)
+			}
+			symKey, err := secrets.CreateSymmetricKey()
+			if err != nil {
+				t.Fatalf("Failed to create symmetric key: %v", err)
+			}
+			wrapped, err := secrets.EncryptWithPublicKey(symKey, publicKey)
+			if err != nil {
+				t.Fatalf("Failed to wrap symmetric key: %v", err)
+			}
+			if _, err := secrets.DecryptWithPrivateKey(wrapped, privateKey); err != nil {
+				t.Errorf("Expected the private key to unwrap a key wrapped for its public key: %v", err)
+			}
+		})
+	}
+}
+
+// TestCreateKeyType_Unsupported tests that an unknown --key-type is rejected before any key is written.
+func TestCreateKeyType_Unsupported(t *testing.T) {
+	tempDir, _ := setupKeyTypeTest(t)
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("create", []string{"--key-type", "dsa"}, nil, nil, false, false).Execute()
+	})
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Errorf("Expected ErrInvalidArguments, got: %v", err)
+	}
+	if !strings.Contains(output, "Unsupported") || !strings.Contains(output, "ed25519") {
+		t.Errorf("Expected an unsupported key type error listing the choices, got: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".kanuka", "public_keys", shared.GetUserUUID(t)+".pub")); !os.IsNotExist(err) {
+		t.Error("Expected no public key to be created")
+	}
+}
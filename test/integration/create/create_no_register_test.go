@@ -0,0 +1,176 @@
+package create
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+const (
+	pendingUserEmail = "bob@example.com"
+	// pendingUserUUID is a well-formed UUID, which register --file needs to
+	// name the key file.
+	pendingUserUUID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+)
+
+// TestCreateNoRegister_PrintsPublicKey tests that create --no-register works
+// outside any project, keeps the private key under keys/pending/, and writes
+// only the public key to stdout.
+func TestCreateNoRegister_PrintsPublicKey(t *testing.T) {
+	workDir := t.TempDir()
+	userDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	shared.SetupTestEnvironment(t, workDir, userDir, originalWd, configs.UserKanukaSettings)
+
+	stdout, stderr, err := shared.CaptureStdoutAndStderr(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("create", []string{"--no-register"}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("create --no-register failed: %v\nStderr: %s", err, stderr)
+	}
+
+	if _, err := secrets.ParsePublicKeyText(stdout); err != nil {
+		t.Errorf("Expected stdout to be only the public key, got %q: %v", stdout, err)
+	}
+	if !strings.Contains(stderr, "Pending key pair created") {
+		t.Errorf("Expected success message on stderr, got: %s", stderr)
+	}
+
+	pendingDir := filepath.Join(userDir, "keys", configs.PendingKeyDirName)
+	if _, err := os.Stat(filepath.Join(pendingDir, "privkey")); err != nil {
+		t.Errorf("Expected pending private key in %s: %v", pendingDir, err)
+	}
+
+	// A second run must not silently replace the pending key.
+	_, stderr, err = shared.CaptureStdoutAndStderr(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("create", []string{"--no-register"}, nil, nil, false, false).Execute()
+	})
+	if !errors.Is(err, kerrors.ErrPublicKeyExists) {
+		t.Fatalf("Expected ErrPublicKeyExists, got: %v", err)
+	}
+	if !strings.Contains(stderr, "already have a pending key") {
+		t.Errorf("Expected existing pending key error, got: %s", stderr)
+	}
+}
+
+// TestCreateNoRegister_PromotedByRegister tests the whole offline flow: a
+// pending key is generated before the project is reachable, an admin
+// registers its public key, and running register in the project promotes it
+// so the user can decrypt.
+func TestCreateNoRegister_PromotedByRegister(t *testing.T) {
+	projectDir := t.TempDir()
+	adminDir := t.TempDir()
+	offlineDir := t.TempDir()
+	pendingUserDir := t.TempDir()
+	envContent := "API_KEY=secret123\n"
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	originalUserSettings := configs.UserKanukaSettings
+
+	// The admin sets up the project and encrypts a secret.
+	shared.SetupTestEnvironment(t, projectDir, adminDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, projectDir, adminDir)
+	if err := os.WriteFile(filepath.Join(projectDir, ".env"), []byte(envContent), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	if output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLI("encrypt", nil, nil, false, false).Execute()
+	}); err != nil {
+		t.Fatalf("Encrypt command failed: %v\nOutput: %s", err, output)
+	}
+	if err := os.Remove(filepath.Join(projectDir, ".env")); err != nil {
+		t.Fatalf("Failed to remove .env: %v", err)
+	}
+
+	// Bob generates a key pair before he has the project.
+	cmd.ResetGlobalState()
+	shared.SetupTestEnvironmentWithUUID(t, offlineDir, pendingUserDir, originalWd, originalUserSettings,
+		pendingUserUUID, "bob", pendingUserEmail)
+	publicKeyPath := filepath.Join(offlineDir, pendingUserUUID+".pub")
+	if output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("create", []string{"--no-register", "-o", publicKeyPath}, nil, nil, false, false).Execute()
+	}); err != nil {
+		t.Fatalf("create --no-register failed: %v\nOutput: %s", err, output)
+	}
+
+	// The admin registers the public key Bob sent.
+	cmd.ResetGlobalState()
+	shared.SetupTestEnvironment(t, projectDir, adminDir, originalWd, originalUserSettings)
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("register", []string{"--file", publicKeyPath, "--user", pendingUserEmail}, nil, nil, false, false).Execute()
+	})
+	if err != nil || !strings.Contains(output, "has been granted access") {
+		t.Fatalf("Register --file failed: %v\nOutput: %s", err, output)
+	}
+
+	// Bob clones the project and promotes his pending key.
+	cmd.ResetGlobalState()
+	shared.SetupTestEnvironmentWithUUID(t, projectDir, pendingUserDir, originalWd, originalUserSettings,
+		pendingUserUUID, "bob", pendingUserEmail)
+	output, err = shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLI("register", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Register command failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "pending key is now your key for this project") {
+		t.Errorf("Expected promotion message, got: %s", output)
+	}
+	if !strings.Contains(output, "You have access") {
+		t.Errorf("Expected promotion to report access, got: %s", output)
+	}
+
+	projectUUID := shared.GetProjectUUID(t)
+	keysDir := filepath.Join(pendingUserDir, "keys")
+	if _, err := os.Stat(shared.GetPrivateKeyPath(keysDir, projectUUID)); err != nil {
+		t.Errorf("Expected the pending key to become the project key: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(keysDir, configs.PendingKeyDirName)); !os.IsNotExist(err) {
+		t.Errorf("Expected the pending key directory to be removed")
+	}
+
+	configs.GlobalProjectConfig = nil
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	if device, ok := projectConfig.Devices[pendingUserUUID]; !ok || device.Email != pendingUserEmail {
+		t.Errorf("Expected a device for %s, got %+v (found: %v)", pendingUserEmail, device, ok)
+	}
+
+	output, err = shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLI("decrypt", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Decrypt command failed: %v\nOutput: %s", err, output)
+	}
+	decrypted, err := os.ReadFile(filepath.Join(projectDir, ".env"))
+	if err != nil {
+		t.Fatalf("Expected Bob to decrypt .env: %v\nOutput: %s", err, output)
+	}
+	if string(decrypted) != envContent {
+		t.Errorf("Expected decrypted content %q, got %q", envContent, string(decrypted))
+	}
+}
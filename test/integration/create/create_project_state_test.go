@@ -60,7 +60,7 @@ func testMultipleProjectSupport(t *testing.T, originalWd string, originalUserSet
 
 	// Setup and initialize first project
 	shared.SetupTestEnvironment(t, tempDir1, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir1, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir1, tempUserDir)
 
 	// Create keys for first project
 	_, err = shared.CaptureOutput(func() error {
@@ -84,19 +84,10 @@ func testMultipleProjectSupport(t *testing.T, originalWd string, originalUserSet
 		t.Errorf("Project 1 public key not created")
 	}
 
-	// Setup and initialize second project
+	// Setup and initialize second project, which creates its keys
 	shared.SetupTestEnvironment(t, tempDir2, tempUserDir, originalWd, originalUserSettings)
 	shared.InitializeProject(t, tempDir2, tempUserDir)
 
-	// Create keys for second project
-	_, err = shared.CaptureOutput(func() error {
-		cmd := shared.CreateTestCLI("create", nil, nil, true, false)
-		return cmd.Execute()
-	})
-	if err != nil {
-		t.Errorf("Failed to create keys for project 2: %v", err)
-	}
-
 	project2UUID := shared.GetProjectUUID(t)
 	project2PrivateKey := shared.GetPrivateKeyPath(keysDir, project2UUID)
 	project2PublicKey := shared.GetPublicKeyPath(keysDir, project2UUID)
@@ -153,7 +144,7 @@ func testProjectNameHandling(t *testing.T, originalWd string, originalUserSettin
 			defer os.RemoveAll(tempUserDir)
 
 			shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-			shared.InitializeProject(t, tempDir, tempUserDir)
+			shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 			_, err = shared.CaptureOutput(func() error {
 				cmd := shared.CreateTestCLI("create", nil, nil, true, false)
@@ -109,14 +109,8 @@ func testUsernameDetection(t *testing.T, originalWd string, originalUserSettings
 				t.Fatalf("Failed to save user config: %v", err)
 			}
 
-			// Initialize project with the custom username
-			_, err = shared.CaptureOutput(func() error {
-				cmd := shared.CreateTestCLI("init", nil, nil, false, false)
-				return cmd.Execute()
-			})
-			if err != nil {
-				t.Fatalf("Failed to initialize project with username %s: %v", tc.username, err)
-			}
+			// Initialize the project without keys so create generates them for the custom username
+			shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 			output, err := shared.CaptureOutput(func() error {
 				cmd := shared.CreateTestCLI("create", nil, nil, true, false)
@@ -163,7 +157,7 @@ func testCustomDataDirectories(t *testing.T, originalWd string, originalUserSett
 	defer os.RemoveAll(customDataDir)
 
 	shared.SetupTestEnvironment(t, tempDir, customDataDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, customDataDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, customDataDir)
 
 	_, err = shared.CaptureOutput(func() error {
 		cmd := shared.CreateTestCLI("create", nil, nil, true, false)
@@ -212,7 +206,7 @@ func testUserDirectoryPermissions(t *testing.T, originalWd string, originalUserS
 	defer os.RemoveAll(tempUserDir)
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	// Create keys directory with restricted permissions
 	keysDir := filepath.Join(tempUserDir, "keys")
@@ -1,12 +1,14 @@
 package create
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -57,8 +59,8 @@ func testCreateInUninitializedProject(t *testing.T, originalWd string, originalU
 		return cmd.Execute()
 	})
 
-	if err != nil {
-		t.Errorf("Expected command to succeed (error shown in output), but got error: %v", err)
+	if !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Errorf("Expected ErrProjectNotInitialized, got: %v", err)
 	}
 
 	if !strings.Contains(output, "Kānuka has not been initialized") {
@@ -160,7 +162,7 @@ func testCreateWhenUserAlreadyHasKeys(t *testing.T, originalWd string, originalU
 	defer os.RemoveAll(tempUserDir)
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	_, err = shared.CaptureOutput(func() error {
 		cmd := shared.CreateTestCLI("create", nil, nil, true, false)
@@ -174,13 +176,12 @@ func testCreateWhenUserAlreadyHasKeys(t *testing.T, originalWd string, originalU
 		cmd := shared.CreateTestCLI("create", nil, nil, true, false) // Use verbose to see the "already exists" message
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
+	if !errors.Is(err, kerrors.ErrPublicKeyExists) {
+		t.Errorf("Expected ErrPublicKeyExists, got: %v", err)
 	}
 
 	userUUID := shared.GetUserUUID(t)
-	// With the new implementation, the command should show "already exists" message
-	// but may succeed if it can still complete the operation
+	// The command should fail and show the "already exists" message
 	if !strings.Contains(output, userUUID+".pub already exists") && !strings.Contains(output, "already exists") {
 		t.Errorf("Expected 'already exists' message not found in output: %s", output)
 	}
@@ -205,7 +206,7 @@ func testCreateWithForceFlag(t *testing.T, originalWd string, originalUserSettin
 	defer os.RemoveAll(tempUserDir)
 
 	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
-	shared.InitializeProject(t, tempDir, tempUserDir)
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
 
 	_, err = shared.CaptureOutput(func() error {
 		cmd := shared.CreateTestCLI("create", nil, nil, true, false)
@@ -225,7 +226,7 @@ func testCreateWithForceFlag(t *testing.T, originalWd string, originalUserSettin
 
 	output, err := shared.CaptureOutput(func() error {
 		cmd := shared.CreateTestCLI("create", nil, nil, true, false)
-		cmd.SetArgs([]string{"secrets", "create", "--force"})
+		cmd.SetArgs([]string{"secrets", "create", "--force", "--yes"})
 		return cmd.Execute()
 	})
 	if err != nil {
@@ -0,0 +1,105 @@
+package decrypt_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// runDecryptCheck runs decrypt --check and returns its output and the exit
+// code it requested or that its error maps to, or 0 if it succeeded.
+func runDecryptCheck(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	exitCode := 0
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("decrypt", append([]string{"--check"}, args...), nil, nil, false, false)
+		cmd.SetDecryptExitFunc(func(code int) { exitCode = code })
+		return testCmd.Execute()
+	})
+	if err != nil {
+		return output, kerrors.ExitCode(err)
+	}
+	return output, exitCode
+}
+
+// TestDecryptCheckInSync tests that an unchanged .env passes the check and
+// is left untouched.
+func TestDecryptCheckInSync(t *testing.T) {
+	content := "API_KEY=secret123\n"
+	tempDir := setupEncryptedEnv(t, content)
+	envPath := filepath.Join(tempDir, ".env")
+	if err := os.WriteFile(envPath, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to restore .env: %v", err)
+	}
+
+	output, exitCode := runDecryptCheck(t)
+	if exitCode != 0 {
+		t.Errorf("Expected no exit, got code %d\nOutput: %s", exitCode, output)
+	}
+	if !strings.Contains(output, "1 file(s) match their encrypted version") {
+		t.Errorf("Expected an in-sync summary, got: %s", output)
+	}
+}
+
+// TestDecryptCheckDrift tests that an edited .env is reported, exits
+// non-zero, and isn't overwritten.
+func TestDecryptCheckDrift(t *testing.T) {
+	tempDir := setupEncryptedEnv(t, "API_KEY=secret123\n")
+	envPath := filepath.Join(tempDir, ".env")
+	edited := "API_KEY=changed\n"
+	if err := os.WriteFile(envPath, []byte(edited), 0600); err != nil {
+		t.Fatalf("Failed to write .env: %v", err)
+	}
+
+	output, exitCode := runDecryptCheck(t)
+	if exitCode == 0 {
+		t.Errorf("Expected a non-zero exit on drift\nOutput: %s", output)
+	}
+	if !strings.Contains(output, "differs from .env.kanuka") || !strings.Contains(output, "1 file(s) differ") {
+		t.Errorf("Expected the drifted file to be reported, got: %s", output)
+	}
+
+	data, err := os.ReadFile(envPath)
+	if err != nil {
+		t.Fatalf("Failed to read .env: %v", err)
+	}
+	if string(data) != edited {
+		t.Errorf("Expected --check not to write .env, got %q", data)
+	}
+}
+
+// TestDecryptCheckNoPlaintext tests that a .kanuka file with no .env is
+// skipped with a note and doesn't fail the check.
+func TestDecryptCheckNoPlaintext(t *testing.T) {
+	tempDir := setupEncryptedEnv(t, "API_KEY=secret123\n")
+
+	output, exitCode := runDecryptCheck(t)
+	if exitCode != 0 {
+		t.Errorf("Expected no exit, got code %d\nOutput: %s", exitCode, output)
+	}
+	if !strings.Contains(output, "no plaintext file, skipped") {
+		t.Errorf("Expected a skipped note, got: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".env")); !os.IsNotExist(err) {
+		t.Errorf("Expected --check not to create .env")
+	}
+}
+
+// TestDecryptCheckWithStdout tests that --check can't be combined with --stdout.
+func TestDecryptCheckWithStdout(t *testing.T) {
+	setupEncryptedEnv(t, "API_KEY=secret123\n")
+
+	output, exitCode := runDecryptCheck(t, "--stdout")
+	if exitCode == 0 {
+		t.Errorf("Expected a non-zero exit\nOutput: %s", output)
+	}
+	if !strings.Contains(output, "can't be used with") {
+		t.Errorf("Expected a flag conflict error, got: %s", output)
+	}
+}
@@ -0,0 +1,81 @@
+package decrypt_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+func runDecrypt(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("decrypt", args, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v\nOutput: %s", err, output)
+	}
+	return output
+}
+
+// TestDecryptReportsCreatedFiles tests that files that didn't exist before
+// decrypting are listed as created.
+func TestDecryptReportsCreatedFiles(t *testing.T) {
+	setupEncryptedEnv(t, "KEY=value\n")
+
+	output := runDecrypt(t)
+	if !strings.Contains(output, "Created:") || !strings.Contains(output, ".env") {
+		t.Errorf("Expected .env to be listed as created, got: %s", output)
+	}
+	if strings.Contains(output, "Updated:") {
+		t.Errorf("Expected no updated files, got: %s", output)
+	}
+}
+
+// TestDecryptReportsCreatedAndUpdatedFiles tests that an existing .env is
+// listed as updated while a missing one is listed as created.
+func TestDecryptReportsCreatedAndUpdatedFiles(t *testing.T) {
+	tempDir := setupEncryptedEnv(t, "KEY=value\n")
+
+	localPath := filepath.Join(tempDir, ".env.local")
+	if err := os.WriteFile(localPath, []byte("LOCAL=value\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env.local: %v", err)
+	}
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLI("encrypt", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v\nOutput: %s", err, output)
+	}
+	if err := os.Remove(localPath); err != nil {
+		t.Fatalf("Failed to remove .env.local: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("KEY=stale\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+
+	output = runDecrypt(t)
+	created, updated, found := strings.Cut(output, "Updated:")
+	if !found {
+		t.Fatalf("Expected an updated section, got: %s", output)
+	}
+	if !strings.Contains(created, "Created:") || !strings.Contains(created, ".env.local") {
+		t.Errorf("Expected .env.local to be listed as created, got: %s", output)
+	}
+	if strings.Contains(updated, ".env.local") || !strings.Contains(updated, ".env") {
+		t.Errorf("Expected only .env to be listed as updated, got: %s", output)
+	}
+
+	content, err := os.ReadFile(filepath.Join(tempDir, ".env"))
+	if err != nil {
+		t.Fatalf("Failed to read .env: %v", err)
+	}
+	if string(content) != "KEY=value\n" {
+		t.Errorf("Expected .env to be overwritten, got: %q", content)
+	}
+}
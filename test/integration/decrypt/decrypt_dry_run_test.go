@@ -1,6 +1,7 @@
 package decrypt_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/PolarWolf314/kanuka/cmd"
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -357,8 +359,8 @@ func TestDecryptDryRun_NotInitialized(t *testing.T) {
 		return testCmd.Execute()
 	})
 
-	if err != nil {
-		t.Errorf("Command should not return error: %v", err)
+	if !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Errorf("Expected ErrProjectNotInitialized, got: %v", err)
 	}
 
 	// Should show "not initialized" message.
@@ -397,8 +399,8 @@ func TestDecryptDryRun_NoKanukaFiles(t *testing.T) {
 		return testCmd.Execute()
 	})
 
-	if err != nil {
-		t.Errorf("Command should not return error: %v", err)
+	if !errors.Is(err, kerrors.ErrNoFilesFound) {
+		t.Errorf("Expected ErrNoFilesFound, got: %v", err)
 	}
 
 	// Should show "no .kanuka files found" message.
@@ -469,8 +471,8 @@ func TestDecryptDryRun_SymmetricKeyValidation(t *testing.T) {
 		return testCmd.Execute()
 	})
 
-	if err != nil {
-		t.Errorf("Command should not return error: %v", err)
+	if !errors.Is(err, kerrors.ErrKeyDecryptFailed) {
+		t.Errorf("Expected ErrKeyDecryptFailed, got: %v", err)
 	}
 
 	// Should show error about decrypting the kanuka file, not dry-run output.
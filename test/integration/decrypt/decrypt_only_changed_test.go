@@ -0,0 +1,115 @@
+package decrypt_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+func runSecretsCommand(t *testing.T, subcommand string, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs(subcommand, args, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("%s failed: %v\nOutput: %s", subcommand, err, output)
+	}
+	return output
+}
+
+func readEnv(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+// TestDecryptOnlyChanged tests that --only-changed skips a .kanuka file that
+// hasn't changed since the last decrypt, and decrypts it again once it has.
+func TestDecryptOnlyChanged(t *testing.T) {
+	tempDir := setupEncryptedEnv(t, "API_KEY=secret123\n")
+	envPath := filepath.Join(tempDir, ".env")
+
+	// With no state yet, everything is decrypted and recorded.
+	runSecretsCommand(t, "decrypt", "--only-changed")
+	if got := readEnv(t, envPath); got != "API_KEY=secret123\n" {
+		t.Fatalf("Expected the first run to decrypt .env, got %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".kanuka", ".decrypt-state")); err != nil {
+		t.Fatalf("Expected the decrypt state to be recorded: %v", err)
+	}
+	gitignore := readEnv(t, filepath.Join(tempDir, ".kanuka", ".gitignore"))
+	if !strings.Contains(gitignore, ".decrypt-state") {
+		t.Errorf("Expected .kanuka/.gitignore to ignore the decrypt state, got %q", gitignore)
+	}
+
+	// An unchanged file is skipped, keeping local edits.
+	if err := os.WriteFile(envPath, []byte("API_KEY=local\n"), 0600); err != nil {
+		t.Fatalf("Failed to edit .env: %v", err)
+	}
+	output := runSecretsCommand(t, "decrypt", "--only-changed")
+	if !strings.Contains(output, "unchanged since you last decrypted") {
+		t.Errorf("Expected the file to be reported as unchanged, got: %s", output)
+	}
+	if got := readEnv(t, envPath); got != "API_KEY=local\n" {
+		t.Errorf("Expected the unchanged file to be skipped, got %q", got)
+	}
+
+	// A re-encrypted file is decrypted again.
+	if err := os.WriteFile(envPath, []byte("API_KEY=rotated\n"), 0600); err != nil {
+		t.Fatalf("Failed to edit .env: %v", err)
+	}
+	runSecretsCommand(t, "encrypt")
+	if err := os.WriteFile(envPath, []byte("API_KEY=local\n"), 0600); err != nil {
+		t.Fatalf("Failed to edit .env: %v", err)
+	}
+	runSecretsCommand(t, "decrypt", "--only-changed")
+	if got := readEnv(t, envPath); got != "API_KEY=rotated\n" {
+		t.Errorf("Expected the changed file to be decrypted, got %q", got)
+	}
+}
+
+// TestDecryptOnlyChanged_MissingPlaintext tests that an unchanged file is
+// still decrypted if its .env file was deleted.
+func TestDecryptOnlyChanged_MissingPlaintext(t *testing.T) {
+	tempDir := setupEncryptedEnv(t, "API_KEY=secret123\n")
+	envPath := filepath.Join(tempDir, ".env")
+
+	runSecretsCommand(t, "decrypt")
+	if err := os.Remove(envPath); err != nil {
+		t.Fatalf("Failed to remove .env: %v", err)
+	}
+
+	runSecretsCommand(t, "decrypt", "--only-changed")
+	if got := readEnv(t, envPath); got != "API_KEY=secret123\n" {
+		t.Errorf("Expected the missing .env to be decrypted, got %q", got)
+	}
+}
+
+// TestDecryptOnlyChanged_RejectsCheck tests that --only-changed can't be
+// combined with --check.
+func TestDecryptOnlyChanged_RejectsCheck(t *testing.T) {
+	setupEncryptedEnv(t, "API_KEY=secret123\n")
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("decrypt", []string{"--only-changed", "--check"}, nil, nil, false, false)
+		cmd.SetDecryptExitFunc(func(int) {})
+		return testCmd.Execute()
+	})
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Fatalf("Expected ErrInvalidArguments, got: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "can't be used with") {
+		t.Errorf("Expected a flag error, got: %s", output)
+	}
+}
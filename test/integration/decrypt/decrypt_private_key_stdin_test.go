@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/internal/utils"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -34,6 +36,10 @@ func TestDecryptWithPrivateKeyStdin(t *testing.T) {
 	t.Run("DecryptWithInvalidKeyFromStdin", func(t *testing.T) {
 		testDecryptWithInvalidKeyFromStdin(t, originalWd, originalUserSettings)
 	})
+
+	t.Run("DecryptWithPassphraseProtectedKeyWithoutTTY", func(t *testing.T) {
+		testDecryptWithPassphraseProtectedKeyWithoutTTY(t, originalWd, originalUserSettings)
+	})
 }
 
 // testDecryptWithPKCS1KeyFromStdin tests decryption with a PKCS#1 format private key from stdin.
@@ -314,3 +320,53 @@ func testDecryptWithInvalidKeyFromStdin(t *testing.T, originalWd string, origina
 		t.Errorf("Expected 'Failed to parse private key from stdin' message in output, got: %s", output)
 	}
 }
+
+// testDecryptWithPassphraseProtectedKeyWithoutTTY tests that a passphrase-protected
+// key piped on stdin fails with a clear error when there is no terminal to
+// prompt for the passphrase on.
+func testDecryptWithPassphraseProtectedKeyWithoutTTY(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	if utils.IsTTYAvailable() {
+		t.Skip("a terminal is available, so the passphrase would be prompted for")
+	}
+
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	envPath := filepath.Join(tempDir, ".env")
+	if err := os.WriteFile(envPath, []byte("TEST_VAR=value\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env file: %v", err)
+	}
+
+	// Encrypt with the key on disk, then remove the plaintext.
+	if _, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("encrypt", nil, nil, true, false).Execute()
+	}); err != nil {
+		t.Fatalf("Failed to encrypt file for test setup: %v", err)
+	}
+	if err := os.Remove(envPath); err != nil {
+		t.Fatalf("Failed to remove .env file: %v", err)
+	}
+
+	// Re-encode the project key with a passphrase.
+	projectUUID := shared.GetProjectUUID(t)
+	privateKey, err := secrets.LoadPrivateKey(shared.GetPrivateKeyPath(filepath.Join(tempUserDir, "keys"), projectUUID))
+	if err != nil {
+		t.Fatalf("Failed to load private key: %v", err)
+	}
+	protectedKey, err := secrets.MarshalPrivateKeyOpenSSH(privateKey, []byte("correct horse"))
+	if err != nil {
+		t.Fatalf("Failed to encode passphrase-protected key: %v", err)
+	}
+
+	output, _ := shared.CaptureOutputWithStdin(protectedKey, func() error {
+		cmd := shared.CreateTestCLIWithArgs("decrypt", []string{"--private-key-stdin"}, nil, nil, true, false)
+		return cmd.Execute()
+	})
+
+	if !strings.Contains(output, "no TTY available") {
+		t.Errorf("Expected an error about the missing terminal, got: %s", output)
+	}
+}
@@ -0,0 +1,147 @@
+package decrypt_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupEncryptedEnv initializes a project and encrypts a .env file with the
+// given content, removing the plaintext afterwards.
+func setupEncryptedEnv(t *testing.T, content string) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	originalUserSettings := configs.UserKanukaSettings
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	envPath := filepath.Join(tempDir, ".env")
+	if err := os.WriteFile(envPath, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLI("encrypt", nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Encrypt command failed: %v\nOutput: %s", err, output)
+	}
+
+	if err := os.Remove(envPath); err != nil {
+		t.Fatalf("Failed to remove .env: %v", err)
+	}
+	return tempDir
+}
+
+// TestDecryptStdout tests that decrypt --stdout prints only the plaintext to
+// stdout and never writes a .env file.
+func TestDecryptStdout(t *testing.T) {
+	content := "DATABASE_URL=postgres://localhost/db\nAPI_KEY=secret123\n"
+	tempDir := setupEncryptedEnv(t, content)
+
+	stdout, stderr, err := shared.CaptureStdoutAndStderr(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("decrypt", []string{".env.kanuka", "--stdout"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Decrypt command failed: %v\nStderr: %s", err, stderr)
+	}
+
+	if stdout != content {
+		t.Errorf("Expected stdout to be exactly the plaintext %q, got %q (stderr: %s)", content, stdout, stderr)
+	}
+	if strings.Contains(stderr, "API_KEY") {
+		t.Errorf("Expected plaintext not to be printed to stderr, got: %s", stderr)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".env")); !os.IsNotExist(err) {
+		t.Errorf("Expected no .env file to be written")
+	}
+}
+
+// TestDecryptStdoutVerbose tests that verbose logging goes to stderr, keeping
+// stdout clean for piping.
+func TestDecryptStdoutVerbose(t *testing.T) {
+	content := "KEY=value\n"
+	setupEncryptedEnv(t, content)
+
+	stdout, stderr, err := shared.CaptureStdoutAndStderr(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("decrypt", []string{"--stdout"}, nil, nil, true, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Decrypt command failed: %v\nStderr: %s", err, stderr)
+	}
+
+	if stdout != content {
+		t.Errorf("Expected stdout to be exactly the plaintext %q, got %q", content, stdout)
+	}
+	if !strings.Contains(stderr, "[info]") {
+		t.Errorf("Expected verbose logs on stderr, got: %s", stderr)
+	}
+}
+
+// TestDecryptStdoutWithoutAccess tests that decrypt --stdout prints nothing to
+// stdout when the user's key can't decrypt the project.
+func TestDecryptStdoutWithoutAccess(t *testing.T) {
+	tempDir := setupEncryptedEnv(t, "KEY=value\n")
+
+	userUUID := shared.GetUserUUID(t)
+	if err := os.Remove(filepath.Join(tempDir, ".kanuka", "secrets", userUUID+".kanuka")); err != nil {
+		t.Fatalf("Failed to remove user's key: %v", err)
+	}
+
+	stdout, stderr, err := shared.CaptureStdoutAndStderr(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("decrypt", []string{"--stdout"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if !errors.Is(err, kerrors.ErrNoAccess) {
+		t.Fatalf("Expected ErrNoAccess, got: %v", err)
+	}
+
+	if stdout != "" {
+		t.Errorf("Expected nothing on stdout, got %q", stdout)
+	}
+	if !strings.Contains(stderr, "Are you sure you have access?") {
+		t.Errorf("Expected an access error on stderr, got: %s", stderr)
+	}
+}
+
+// TestDecryptStdoutInvalidFlags tests that --stdout is rejected with --dry-run.
+func TestDecryptStdoutInvalidFlags(t *testing.T) {
+	setupEncryptedEnv(t, "KEY=value\n")
+
+	stdout, stderr, err := shared.CaptureStdoutAndStderr(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("decrypt", []string{"--stdout", "--dry-run"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Fatalf("Expected ErrInvalidArguments, got: %v", err)
+	}
+
+	if stdout != "" {
+		t.Errorf("Expected nothing on stdout, got %q", stdout)
+	}
+	if !strings.Contains(stderr, "can't be used with") {
+		t.Errorf("Expected a flag error on stderr, got: %s", stderr)
+	}
+}
@@ -0,0 +1,62 @@
+package decrypt_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// loosenPrivateKey makes the current user's private key group and world readable.
+func loosenPrivateKey(t *testing.T) {
+	t.Helper()
+	privateKeyPath := configs.GetPrivateKeyPath(shared.GetProjectUUID(t))
+	if err := os.Chmod(privateKeyPath, 0644); err != nil {
+		t.Fatalf("Failed to chmod private key: %v", err)
+	}
+}
+
+// TestDecryptStrictPerms tests that --strict-perms refuses a private key that
+// others can read, and that the key is still used without the flag.
+func TestDecryptStrictPerms(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not enforced on Windows")
+	}
+
+	tempDir := setupEncryptedEnv(t, "API_KEY=secret123\n")
+	loosenPrivateKey(t)
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("decrypt", []string{"--strict-perms"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if !errors.Is(err, kerrors.ErrInsecureKeyPermissions) {
+		t.Fatalf("Expected ErrInsecureKeyPermissions, got: %v", err)
+	}
+	if !strings.Contains(output, "readable by other users") || !strings.Contains(output, "chmod 600") {
+		t.Errorf("Expected an insecure permissions error, got: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".env")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be decrypted with --strict-perms")
+	}
+
+	output, err = shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLI("decrypt", nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Decrypt command failed: %v\nOutput: %s", err, output)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".env")); err != nil {
+		t.Errorf("Expected decrypt to succeed without --strict-perms: %v\nOutput: %s", err, output)
+	}
+}
@@ -1,12 +1,14 @@
 package decrypt_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -56,8 +58,8 @@ func testDecryptInEmptyFolder(t *testing.T, originalWd string, originalUserSetti
 		cmd := shared.CreateTestCLI("decrypt", nil, nil, true, false)
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
+	if !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Errorf("Expected ErrProjectNotInitialized, got: %v", err)
 	}
 
 	if !strings.Contains(output, "Kānuka has not been initialized") {
@@ -86,8 +88,8 @@ func testDecryptInInitializedFolderWithNoKanukaFiles(t *testing.T, originalWd st
 		cmd := shared.CreateTestCLI("decrypt", nil, nil, true, false)
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
+	if !errors.Is(err, kerrors.ErrNoFilesFound) {
+		t.Errorf("Expected ErrNoFilesFound, got: %v", err)
 	}
 
 	if !strings.Contains(output, "No encrypted environment (.kanuka) files found") {
@@ -0,0 +1,187 @@
+package diff
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupDiffProject initializes a project, writes and encrypts the given .env
+// files, and returns the project directory.
+func setupDiffProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	for rel, content := range files {
+		writeFile(t, filepath.Join(tempDir, rel), content)
+	}
+	if len(files) == 0 {
+		return tempDir
+	}
+
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("encrypt", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v\nOutput: %s", err, output)
+	}
+
+	return tempDir
+}
+
+// writeFile creates a file (and its parent directories) with the given content.
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory for %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to create file %s: %v", path, err)
+	}
+}
+
+func TestDiff_ReportsAddedRemovedAndModifiedKeys(t *testing.T) {
+	projectDir := setupDiffProject(t, map[string]string{
+		".env": "KEEP=same\nCHANGE=old\nDROP=gone\n",
+	})
+	writeFile(t, filepath.Join(projectDir, ".env"), "KEEP=same\nCHANGE=new\nADD=fresh\n")
+
+	kanukaPath := filepath.Join(projectDir, ".env.kanuka")
+	before, err := os.ReadFile(kanukaPath)
+	if err != nil {
+		t.Fatalf("Failed to read .env.kanuka: %v", err)
+	}
+
+	result, err := workflows.Diff(context.Background(), workflows.DiffOptions{})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	if len(result.Files) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(result.Files))
+	}
+	f := result.Files[0]
+	if f.Path != ".env" || !f.Encrypted {
+		t.Errorf("Unexpected file entry: %+v", f)
+	}
+	if len(f.Changes.Added) != 1 || f.Changes.Added[0].Key != "ADD" || f.Changes.Added[0].NewValue != "fresh" {
+		t.Errorf("Added = %+v", f.Changes.Added)
+	}
+	if len(f.Changes.Removed) != 1 || f.Changes.Removed[0].Key != "DROP" || f.Changes.Removed[0].OldValue != "gone" {
+		t.Errorf("Removed = %+v", f.Changes.Removed)
+	}
+	if len(f.Changes.Modified) != 1 || f.Changes.Modified[0].OldValue != "old" || f.Changes.Modified[0].NewValue != "new" {
+		t.Errorf("Modified = %+v", f.Changes.Modified)
+	}
+
+	after, err := os.ReadFile(kanukaPath)
+	if err != nil {
+		t.Fatalf("Failed to read .env.kanuka: %v", err)
+	}
+	if string(before) != string(after) {
+		t.Error("Diff should not modify .env.kanuka")
+	}
+}
+
+func TestDiff_UnencryptedFileReportsAllKeysAdded(t *testing.T) {
+	projectDir := setupDiffProject(t, map[string]string{".env": "KEY=value\n"})
+	writeFile(t, filepath.Join(projectDir, "services", "api", ".env"), "PORT=8080\nHOST=localhost\n")
+
+	result, err := workflows.Diff(context.Background(), workflows.DiffOptions{})
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+
+	for _, f := range result.Files {
+		switch f.Path {
+		case ".env":
+			if !f.Changes.Empty() {
+				t.Errorf("Expected .env to be unchanged, got %+v", f.Changes)
+			}
+		case "services/api/.env":
+			if f.Encrypted {
+				t.Error("Expected services/api/.env to be reported as not yet encrypted")
+			}
+			if len(f.Changes.Added) != 2 || len(f.Changes.Removed) != 0 {
+				t.Errorf("Expected 2 added keys, got %+v", f.Changes)
+			}
+		default:
+			t.Errorf("Unexpected file %q", f.Path)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(projectDir, "services", "api", ".env.kanuka")); !os.IsNotExist(err) {
+		t.Error("Diff should not create .kanuka files")
+	}
+}
+
+func TestDiff_NoEnvFiles(t *testing.T) {
+	setupDiffProject(t, nil)
+
+	_, err := workflows.Diff(context.Background(), workflows.DiffOptions{})
+	if !errors.Is(err, kerrors.ErrNoFilesFound) {
+		t.Errorf("Expected ErrNoFilesFound, got %v", err)
+	}
+}
+
+func TestDiffCommand_MasksValuesByDefault(t *testing.T) {
+	projectDir := setupDiffProject(t, map[string]string{".env": "API_KEY=old-secret\n"})
+	writeFile(t, filepath.Join(projectDir, ".env"), "API_KEY=new-secret\nADDED=added-secret\n")
+
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("diff", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Diff command failed: %v\nOutput: %s", err, output)
+	}
+
+	for _, want := range []string{"+ ADDED", "~ API_KEY", "1 added, 0 removed, 1 changed"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got: %s", want, output)
+		}
+	}
+	if strings.Contains(output, "-secret") {
+		t.Errorf("Expected values to be masked, got: %s", output)
+	}
+
+	output, err = shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs("diff", []string{"--show-values"}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Diff command failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "API_KEY=old-secret → new-secret") {
+		t.Errorf("Expected --show-values to reveal values, got: %s", output)
+	}
+}
+
+func TestDiffCommand_NoChanges(t *testing.T) {
+	setupDiffProject(t, map[string]string{".env": "KEY=value\n"})
+
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("diff", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Diff command failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "No changes") {
+		t.Errorf("Expected no changes message, got: %s", output)
+	}
+}
@@ -0,0 +1,99 @@
+package encrypt_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// TestEncryptArbitraryFilesRoundTrip tests that files of any name can be
+// encrypted and decrypted when named explicitly.
+func TestEncryptArbitraryFilesRoundTrip(t *testing.T) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	contents := map[string]string{
+		"secrets.json":                         `{"api_key": "secret123"}`,
+		filepath.Join("deploy", "config.yaml"): "password: hunter2\n",
+	}
+	if err := os.MkdirAll(filepath.Join(tempDir, "deploy"), 0755); err != nil {
+		t.Fatalf("Failed to create deploy directory: %v", err)
+	}
+	for name, content := range contents {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("encrypt", []string{"secrets.json", "deploy/config.yaml"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Encrypt command failed: %v\nOutput: %s", err, output)
+	}
+
+	for name := range contents {
+		if err := os.Remove(filepath.Join(tempDir, name)); err != nil {
+			t.Fatalf("Expected %s to exist before removing it: %v", name, err)
+		}
+	}
+
+	output, err = shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("decrypt", []string{"secrets.json.kanuka", "deploy/config.yaml.kanuka"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Decrypt command failed: %v\nOutput: %s", err, output)
+	}
+
+	for name, want := range contents {
+		got, err := os.ReadFile(filepath.Join(tempDir, name))
+		if err != nil {
+			t.Errorf("Expected %s to be decrypted: %v\nOutput: %s", name, err, output)
+			continue
+		}
+		if string(got) != want {
+			t.Errorf("Expected %s to contain %q, got %q", name, want, got)
+		}
+	}
+}
+
+// TestEncryptRejectsKanukaFile tests that naming an already encrypted file is an error.
+func TestEncryptRejectsKanukaFile(t *testing.T) {
+	tempDir := setupManyEnvFiles(t, 0)
+	if err := os.WriteFile(filepath.Join(tempDir, "secrets.json.kanuka"), []byte("encrypted"), 0600); err != nil {
+		t.Fatalf("Failed to create secrets.json.kanuka: %v", err)
+	}
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("encrypt", []string{"secrets.json.kanuka"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err == nil {
+		t.Fatalf("Expected the command to fail")
+	}
+
+	if !strings.Contains(output, "file is already encrypted: secrets.json.kanuka") {
+		t.Errorf("Expected an already encrypted error, got: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "secrets.json.kanuka.kanuka")); !os.IsNotExist(err) {
+		t.Errorf("Expected the .kanuka file not to be encrypted again")
+	}
+}
@@ -0,0 +1,184 @@
+package encrypt_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+func runEncryptWithArgs(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("encrypt", args, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v\nOutput: %s", err, output)
+	}
+	return output
+}
+
+// runEncryptExpectingError runs encrypt with the given arguments and fails the
+// test unless it returns target.
+func runEncryptExpectingError(t *testing.T, target error, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("encrypt", args, nil, nil, false, false).Execute()
+	})
+	if !errors.Is(err, target) {
+		t.Fatalf("Expected %v, got: %v\nOutput: %s", target, err, output)
+	}
+	return output
+}
+
+// TestEncryptBackup_KeepsPreviousCiphertext tests that --backup copies the old
+// .kanuka file to .kanuka.bak before overwriting it.
+func TestEncryptBackup_KeepsPreviousCiphertext(t *testing.T) {
+	tempDir := setupGitAddTest(t)
+	kanukaFile := filepath.Join(tempDir, ".env.kanuka")
+	backupFile := kanukaFile + ".bak"
+
+	runEncryptWithArgs(t)
+	previous, err := os.ReadFile(kanukaFile)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", kanukaFile, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("KEY=changed\n"), 0600); err != nil {
+		t.Fatalf("Failed to update .env: %v", err)
+	}
+	output := runEncryptWithArgs(t, "--backup")
+	if !strings.Contains(output, "backed up") {
+		t.Errorf("Expected the backup to be reported, got: %s", output)
+	}
+
+	backup, err := os.ReadFile(backupFile)
+	if err != nil {
+		t.Fatalf("Expected %s to be created: %v", backupFile, err)
+	}
+	if !bytes.Equal(backup, previous) {
+		t.Error("Expected the backup to hold the previous ciphertext")
+	}
+
+	current, err := os.ReadFile(kanukaFile)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", kanukaFile, err)
+	}
+	if bytes.Equal(current, previous) {
+		t.Error("Expected the .kanuka file to be re-encrypted")
+	}
+
+	// Only one generation is kept, and the backup is never encrypted itself.
+	runEncryptWithArgs(t, "--backup")
+	backup, err = os.ReadFile(backupFile)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", backupFile, err)
+	}
+	if !bytes.Equal(backup, current) {
+		t.Error("Expected a second backup to replace the first")
+	}
+	if _, err := os.Stat(backupFile + ".kanuka"); !os.IsNotExist(err) {
+		t.Error("Expected the backup file not to be encrypted")
+	}
+}
+
+// TestEncryptBackup_NotCreatedByDefault tests that a normal encrypt writes no
+// backup.
+func TestEncryptBackup_NotCreatedByDefault(t *testing.T) {
+	tempDir := setupGitAddTest(t)
+
+	runEncryptWithArgs(t)
+	runEncryptWithArgs(t)
+
+	if _, err := os.Stat(filepath.Join(tempDir, ".env.kanuka.bak")); !os.IsNotExist(err) {
+		t.Error("Expected no backup without --backup")
+	}
+}
+
+// TestEncryptBackup_DryRunWritesNothing tests that --dry-run lists the backups
+// without writing them.
+func TestEncryptBackup_DryRunWritesNothing(t *testing.T) {
+	tempDir := setupGitAddTest(t)
+	runEncryptWithArgs(t)
+
+	output := runEncryptWithArgs(t, "--backup", "--dry-run")
+	if !strings.Contains(output, ".env.kanuka.bak") {
+		t.Errorf("Expected the dry run to list the backup, got: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".env.kanuka.bak")); !os.IsNotExist(err) {
+		t.Error("Expected dry run not to write a backup")
+	}
+}
+
+// TestEncryptBackup_DecryptLeavesBackupAlone tests that decrypt skips the
+// .kanuka.bak file and leftover atomic-write temporary files, rather than
+// writing plaintext over them.
+func TestEncryptBackup_DecryptLeavesBackupAlone(t *testing.T) {
+	tempDir := setupGitAddTest(t)
+	envFile := filepath.Join(tempDir, ".env")
+	backupFile := filepath.Join(tempDir, ".env.kanuka.bak")
+	tempFile := filepath.Join(tempDir, "..env.kanuka.tmp-123456")
+
+	runEncryptWithArgs(t)
+	runEncryptWithArgs(t, "--backup")
+	backup, err := os.ReadFile(backupFile)
+	if err != nil {
+		t.Fatalf("Expected %s to be created: %v", backupFile, err)
+	}
+	backupInfo, err := os.Stat(backupFile)
+	if err != nil {
+		t.Fatalf("Failed to stat %s: %v", backupFile, err)
+	}
+
+	runDecrypt := func() {
+		t.Helper()
+		if err := os.Remove(envFile); err != nil {
+			t.Fatalf("Failed to remove .env: %v", err)
+		}
+		output, err := shared.CaptureOutput(func() error {
+			cmd.ResetGlobalState()
+			return shared.CreateTestCLI("decrypt", nil, nil, false, false).Execute()
+		})
+		if err != nil {
+			t.Fatalf("Decrypt failed: %v\nOutput: %s", err, output)
+		}
+		if data, err := os.ReadFile(envFile); err != nil || string(data) != "KEY=value\n" {
+			t.Fatalf("Expected decrypt to restore .env, got %q: %v\nOutput: %s", data, err, output)
+		}
+		if strings.Contains(output, ".bak") || strings.Contains(output, ".tmp-") {
+			t.Errorf("Expected decrypt to skip the backup and temporary files, got: %s", output)
+		}
+	}
+
+	runDecrypt()
+	after, err := os.ReadFile(backupFile)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", backupFile, err)
+	}
+	if !bytes.Equal(after, backup) {
+		t.Errorf("Expected the backup to be unchanged, got %q", after)
+	}
+	afterInfo, err := os.Stat(backupFile)
+	if err != nil {
+		t.Fatalf("Failed to stat %s: %v", backupFile, err)
+	}
+	if afterInfo.Mode().Perm() != backupInfo.Mode().Perm() {
+		t.Errorf("Expected the backup to keep mode %v, got %v", backupInfo.Mode().Perm(), afterInfo.Mode().Perm())
+	}
+
+	// A temporary file left by an interrupted encrypt isn't decrypted either.
+	if err := os.WriteFile(tempFile, []byte("partial"), 0600); err != nil {
+		t.Fatalf("Failed to create %s: %v", tempFile, err)
+	}
+	runDecrypt()
+	if data, err := os.ReadFile(tempFile); err != nil || string(data) != "partial" {
+		t.Errorf("Expected the temporary file to be untouched, got %q: %v", data, err)
+	}
+}
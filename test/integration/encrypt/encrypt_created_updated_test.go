@@ -0,0 +1,38 @@
+package encrypt_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestEncryptReportsCreatedAndUpdatedFiles tests that re-encrypting lists the
+// existing .kanuka file as updated and a new one as created.
+func TestEncryptReportsCreatedAndUpdatedFiles(t *testing.T) {
+	tempDir := setupGitAddTest(t)
+
+	output := runEncryptWithArgs(t)
+	if !strings.Contains(output, "Created:") || !strings.Contains(output, ".env.kanuka") {
+		t.Errorf("Expected .env.kanuka to be listed as created, got: %s", output)
+	}
+	if strings.Contains(output, "Updated:") {
+		t.Errorf("Expected no updated files on the first run, got: %s", output)
+	}
+
+	if err := os.WriteFile(filepath.Join(tempDir, ".env.local"), []byte("LOCAL=value\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env.local: %v", err)
+	}
+
+	output = runEncryptWithArgs(t)
+	created, updated, found := strings.Cut(output, "Updated:")
+	if !found {
+		t.Fatalf("Expected an updated section, got: %s", output)
+	}
+	if !strings.Contains(created, "Created:") || !strings.Contains(created, ".env.local.kanuka") {
+		t.Errorf("Expected .env.local.kanuka to be listed as created, got: %s", output)
+	}
+	if strings.Contains(updated, ".env.local.kanuka") || !strings.Contains(updated, ".env.kanuka") {
+		t.Errorf("Expected only .env.kanuka to be listed as updated, got: %s", output)
+	}
+}
@@ -0,0 +1,68 @@
+package encrypt_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// TestEncryptDeletePlaintext_RemovesVerifiedPlaintext tests that
+// --delete-plaintext removes the .env file and leaves a .kanuka file that
+// decrypts back to it.
+func TestEncryptDeletePlaintext_RemovesVerifiedPlaintext(t *testing.T) {
+	tempDir := setupGitAddTest(t)
+	envFile := filepath.Join(tempDir, ".env")
+
+	output := runEncryptWithArgs(t, "--delete-plaintext")
+	if !strings.Contains(output, "deleted the plaintext") {
+		t.Errorf("Expected the deletion to be reported, got: %s", output)
+	}
+	if _, err := os.Stat(envFile); !os.IsNotExist(err) {
+		t.Fatalf("Expected %s to be deleted", envFile)
+	}
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLI("decrypt", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v\nOutput: %s", err, output)
+	}
+	data, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatalf("Expected decrypt to restore %s: %v", envFile, err)
+	}
+	if string(data) != "KEY=value\n" {
+		t.Errorf("Expected decrypted content %q, got %q", "KEY=value\n", string(data))
+	}
+}
+
+// TestEncryptDeletePlaintext_DryRunKeepsPlaintext tests that a dry run lists
+// the files it would delete without deleting them.
+func TestEncryptDeletePlaintext_DryRunKeepsPlaintext(t *testing.T) {
+	tempDir := setupGitAddTest(t)
+
+	output := runEncryptWithArgs(t, "--delete-plaintext", "--dry-run")
+	if !strings.Contains(output, "would be deleted") {
+		t.Errorf("Expected the dry run to list deletions, got: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".env")); err != nil {
+		t.Errorf("Expected .env to be kept in a dry run: %v", err)
+	}
+}
+
+// TestEncryptDeletePlaintext_RejectsStdin tests that --delete-plaintext can't
+// be combined with --stdin.
+func TestEncryptDeletePlaintext_RejectsStdin(t *testing.T) {
+	setupGitAddTest(t)
+
+	output := runEncryptExpectingError(t, kerrors.ErrInvalidArguments, "--delete-plaintext", "--stdin", "--name", ".env")
+	if !strings.Contains(output, "--delete-plaintext can't be combined with --stdin") {
+		t.Errorf("Expected a flag error, got: %s", output)
+	}
+}
@@ -0,0 +1,140 @@
+package encrypt_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupNestedEnvTree creates an initialized project with .env files at the
+// root and in nested directories.
+func setupNestedEnvTree(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	for _, name := range []string{".env", ".env.production", "services/api/.env", "services/api/.env.prod-eu"} {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte("KEY=value\n"), 0600); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	return tempDir
+}
+
+func assertEncrypted(t *testing.T, projectDir string, want map[string]bool) {
+	t.Helper()
+	for name, encrypted := range want {
+		_, err := os.Stat(filepath.Join(projectDir, name+".kanuka"))
+		if encrypted && err != nil {
+			t.Errorf("Expected %s to be encrypted: %v", name, err)
+		}
+		if !encrypted && !os.IsNotExist(err) {
+			t.Errorf("Expected %s not to be encrypted", name)
+		}
+	}
+}
+
+// TestEncryptDiscover_RecursiveByDefault tests that encrypt finds nested files by default.
+func TestEncryptDiscover_RecursiveByDefault(t *testing.T) {
+	projectDir := setupNestedEnvTree(t)
+
+	runEncryptWithArgs(t)
+
+	assertEncrypted(t, projectDir, map[string]bool{
+		".env":                      true,
+		".env.production":           true,
+		"services/api/.env":         true,
+		"services/api/.env.prod-eu": true,
+	})
+}
+
+// TestEncryptDiscover_NotRecursive tests that --recursive=false only encrypts files in the project root.
+func TestEncryptDiscover_NotRecursive(t *testing.T) {
+	projectDir := setupNestedEnvTree(t)
+
+	runEncryptWithArgs(t, "--recursive=false")
+
+	assertEncrypted(t, projectDir, map[string]bool{
+		".env":                      true,
+		".env.production":           true,
+		"services/api/.env":         false,
+		"services/api/.env.prod-eu": false,
+	})
+}
+
+// TestEncryptDiscover_NameFilter tests that --name-filter restricts which files are encrypted.
+func TestEncryptDiscover_NameFilter(t *testing.T) {
+	projectDir := setupNestedEnvTree(t)
+
+	runEncryptWithArgs(t, "--name-filter", ".env.prod*")
+
+	assertEncrypted(t, projectDir, map[string]bool{
+		".env":                      false,
+		".env.production":           true,
+		"services/api/.env":         false,
+		"services/api/.env.prod-eu": true,
+	})
+}
+
+// TestEncryptDiscover_NameFilterNotRecursive tests combining both flags.
+func TestEncryptDiscover_NameFilterNotRecursive(t *testing.T) {
+	projectDir := setupNestedEnvTree(t)
+
+	runEncryptWithArgs(t, "--recursive=false", "--name-filter", ".env.prod*")
+
+	assertEncrypted(t, projectDir, map[string]bool{
+		".env":                      false,
+		".env.production":           true,
+		"services/api/.env":         false,
+		"services/api/.env.prod-eu": false,
+	})
+}
+
+// TestEncryptDiscover_RejectsFileArguments tests that the discovery flags can't be used with explicit files.
+func TestEncryptDiscover_RejectsFileArguments(t *testing.T) {
+	projectDir := setupNestedEnvTree(t)
+
+	output := runEncryptExpectingError(t, kerrors.ErrInvalidArguments, "--name-filter", ".env.prod*", ".env")
+	if !strings.Contains(output, "can't be combined with file arguments") {
+		t.Errorf("Expected --name-filter with files to be rejected, got: %s", output)
+	}
+	assertEncrypted(t, projectDir, map[string]bool{".env": false})
+}
+
+// TestEncryptDiscover_RejectsInvalidFilter tests that a malformed glob is reported.
+func TestEncryptDiscover_RejectsInvalidFilter(t *testing.T) {
+	setupNestedEnvTree(t)
+
+	output := runEncryptExpectingError(t, kerrors.ErrInvalidArguments, "--name-filter", ".env.prod*[")
+	if !strings.Contains(output, "is not a valid glob") {
+		t.Errorf("Expected an invalid glob error, got: %s", output)
+	}
+}
+
+// TestEncryptDiscover_NoMatches tests that a filter matching nothing reports no files found.
+func TestEncryptDiscover_NoMatches(t *testing.T) {
+	projectDir := setupNestedEnvTree(t)
+
+	output := runEncryptExpectingError(t, kerrors.ErrNoFilesFound, "--name-filter", ".env.staging")
+	if !strings.Contains(output, "No environment files found") {
+		t.Errorf("Expected a no files found error, got: %s", output)
+	}
+	assertEncrypted(t, projectDir, map[string]bool{".env": false, ".env.production": false})
+}
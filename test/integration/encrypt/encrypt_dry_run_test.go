@@ -1,6 +1,7 @@
 package encrypt_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/PolarWolf314/kanuka/cmd"
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -236,8 +238,8 @@ func TestEncryptDryRun_NotInitialized(t *testing.T) {
 		return testCmd.Execute()
 	})
 
-	if err != nil {
-		t.Errorf("Command should not return error: %v", err)
+	if !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Errorf("Expected ErrProjectNotInitialized, got: %v", err)
 	}
 
 	// Should show "not initialized" message, not dry-run output.
@@ -276,8 +278,8 @@ func TestEncryptDryRun_NoEnvFiles(t *testing.T) {
 		return testCmd.Execute()
 	})
 
-	if err != nil {
-		t.Errorf("Command should not return error: %v", err)
+	if !errors.Is(err, kerrors.ErrNoFilesFound) {
+		t.Errorf("Expected ErrNoFilesFound, got: %v", err)
 	}
 
 	// Should show "no environment files" message.
@@ -412,8 +414,8 @@ func TestEncryptDryRun_SymmetricKeyValidation(t *testing.T) {
 		return testCmd.Execute()
 	})
 
-	if err != nil {
-		t.Errorf("Command should not return error: %v", err)
+	if !errors.Is(err, kerrors.ErrKeyDecryptFailed) {
+		t.Errorf("Expected ErrKeyDecryptFailed, got: %v", err)
 	}
 
 	// Should show error about decrypting the kanuka file, not dry-run output.
@@ -1,6 +1,7 @@
 package encrypt_test
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -8,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -164,9 +166,8 @@ func testEncryptWithEnvFileAsDirectory(t *testing.T, originalWd string, original
 		cmd := shared.CreateTestCLI("encrypt", nil, nil, true, false)
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
-		t.Errorf("Output: %s", output)
+	if !errors.Is(err, kerrors.ErrNoFilesFound) {
+		t.Errorf("Expected ErrNoFilesFound, got: %v", err)
 	}
 
 	if !strings.Contains(output, "No environment files found") {
@@ -207,9 +208,8 @@ func testEncryptWithEnvFileAsSymlink(t *testing.T, originalWd string, originalUs
 		cmd := shared.CreateTestCLI("encrypt", nil, nil, true, false)
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
-		t.Errorf("Output: %s", output)
+	if !errors.Is(err, kerrors.ErrNoFilesFound) {
+		t.Errorf("Expected ErrNoFilesFound, got: %v", err)
 	}
 
 	if !strings.Contains(output, "No environment files found") {
@@ -245,9 +245,8 @@ func testEncryptWithBrokenEnvSymlink(t *testing.T, originalWd string, originalUs
 		cmd := shared.CreateTestCLI("encrypt", nil, nil, true, false)
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
-		t.Errorf("Output: %s", output)
+	if !errors.Is(err, kerrors.ErrNoFilesFound) {
+		t.Errorf("Expected ErrNoFilesFound, got: %v", err)
 	}
 
 	if !strings.Contains(output, "No environment files found") {
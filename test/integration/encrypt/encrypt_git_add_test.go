@@ -0,0 +1,83 @@
+package encrypt_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupGitAddTest creates an initialized project with a .env file.
+func setupGitAddTest(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("KEY=value\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	return tempDir
+}
+
+func runEncryptGitAdd(t *testing.T) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs("encrypt", []string{"--git-add"}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v\nOutput: %s", err, output)
+	}
+	return output
+}
+
+// TestEncryptGitAdd_StagesKanukaFiles tests that --git-add stages the encrypted files only.
+func TestEncryptGitAdd_StagesKanukaFiles(t *testing.T) {
+	tempDir := setupGitAddTest(t)
+	if out, err := exec.Command("git", "-C", tempDir, "init", "-q").CombinedOutput(); err != nil {
+		t.Skipf("git not available: %v: %s", err, out)
+	}
+
+	output := runEncryptGitAdd(t)
+	if !strings.Contains(output, "Staged 1") {
+		t.Errorf("Expected staging message, got: %s", output)
+	}
+
+	staged, err := exec.Command("git", "-C", tempDir, "diff", "--cached", "--name-only").Output()
+	if err != nil {
+		t.Fatalf("Failed to list staged files: %v", err)
+	}
+	stagedFiles := strings.Fields(string(staged))
+	if len(stagedFiles) != 1 || stagedFiles[0] != ".env.kanuka" {
+		t.Errorf("Expected only .env.kanuka to be staged, got %v", stagedFiles)
+	}
+}
+
+// TestEncryptGitAdd_OutsideRepository tests that --git-add is a no-op outside git.
+func TestEncryptGitAdd_OutsideRepository(t *testing.T) {
+	tempDir := setupGitAddTest(t)
+	if _, err := exec.LookPath("git"); err == nil {
+		if out, err := exec.Command("git", "-C", tempDir, "rev-parse", "--is-inside-work-tree").Output(); err == nil && strings.TrimSpace(string(out)) == "true" {
+			t.Skip("temp directory is inside a git work tree")
+		}
+	}
+
+	output := runEncryptGitAdd(t)
+	if !strings.Contains(output, "encrypted successfully") {
+		t.Errorf("Expected encrypt to succeed, got: %s", output)
+	}
+	if !strings.Contains(output, "Not a git repository") {
+		t.Errorf("Expected not-a-repository notice, got: %s", output)
+	}
+}
@@ -0,0 +1,71 @@
+package encrypt_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// TestEncryptIgnoreFile tests that encrypt skips files matched by .kanuka/ignore
+// and logs why with --verbose.
+func TestEncryptIgnoreFile(t *testing.T) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	originalUserSettings := configs.UserKanukaSettings
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	files := []string{".env", ".env.example", "config/.env", "config/.env.local"}
+	for _, f := range files {
+		path := filepath.Join(tempDir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", f, err)
+		}
+		if err := os.WriteFile(path, []byte("KEY=value\n"), 0600); err != nil {
+			t.Fatalf("Failed to create %s: %v", f, err)
+		}
+	}
+
+	ignorePath := filepath.Join(tempDir, ".kanuka", "ignore")
+	if err := os.WriteFile(ignorePath, []byte("*.example\nconfig/*.local\n"), 0600); err != nil {
+		t.Fatalf("Failed to create ignore file: %v", err)
+	}
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("encrypt", nil, nil, nil, true, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Encrypt command failed: %v\nOutput: %s", err, output)
+	}
+
+	for _, f := range []string{".env", "config/.env"} {
+		if _, err := os.Stat(filepath.Join(tempDir, f+".kanuka")); err != nil {
+			t.Errorf("Expected %s.kanuka to be created: %v", f, err)
+		}
+	}
+	for _, f := range []string{".env.example", "config/.env.local"} {
+		if _, err := os.Stat(filepath.Join(tempDir, f+".kanuka")); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be skipped, but %s.kanuka exists", f, f)
+		}
+	}
+
+	if !strings.Contains(output, `Skipped .env.example: matches .kanuka/ignore pattern "*.example"`) {
+		t.Errorf("Expected verbose output to explain skipping .env.example, got: %s", output)
+	}
+	if !strings.Contains(output, `Skipped config/.env.local: matches .kanuka/ignore pattern "config/*.local"`) {
+		t.Errorf("Expected verbose output to explain skipping config/.env.local, got: %s", output)
+	}
+}
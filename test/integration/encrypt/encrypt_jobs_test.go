@@ -0,0 +1,121 @@
+package encrypt_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupManyEnvFiles initializes a project with count services, each with a .env file.
+func setupManyEnvFiles(t *testing.T, count int) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	for i := 0; i < count; i++ {
+		serviceDir := filepath.Join(tempDir, "services", fmt.Sprintf("svc-%02d", i))
+		if err := os.MkdirAll(serviceDir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", serviceDir, err)
+		}
+		content := fmt.Sprintf("API_KEY=key-%d\n", i)
+		if err := os.WriteFile(filepath.Join(serviceDir, ".env"), []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to create .env: %v", err)
+		}
+	}
+	return tempDir
+}
+
+// TestEncryptJobs tests that encrypt --jobs encrypts every file and lists
+// them in a stable order.
+func TestEncryptJobs(t *testing.T) {
+	tempDir := setupManyEnvFiles(t, 12)
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("encrypt", []string{"--jobs", "4"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Encrypt command failed: %v\nOutput: %s", err, output)
+	}
+
+	last := -1
+	for i := 0; i < 12; i++ {
+		name := fmt.Sprintf("svc-%02d", i)
+		if _, err := os.Stat(filepath.Join(tempDir, "services", name, ".env.kanuka")); err != nil {
+			t.Errorf("Expected %s/.env.kanuka to be created: %v", name, err)
+		}
+		pos := strings.Index(output, name)
+		if pos < last {
+			t.Errorf("Expected %s to be listed after the previous service, got: %s", name, output)
+		}
+		last = pos
+	}
+}
+
+// TestEncryptJobsPartialFailure tests that one file failing doesn't stop the
+// others from being encrypted, and that the failure is reported.
+func TestEncryptJobsPartialFailure(t *testing.T) {
+	tempDir := setupManyEnvFiles(t, 5)
+
+	// A directory where the .kanuka file should go makes that file fail.
+	if err := os.Mkdir(filepath.Join(tempDir, "services", "svc-02", ".env.kanuka"), 0755); err != nil {
+		t.Fatalf("Failed to create blocking directory: %v", err)
+	}
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("encrypt", []string{"--jobs", "2"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if !errors.Is(err, kerrors.ErrEncryptFailed) {
+		t.Fatalf("Expected ErrEncryptFailed, got: %v", err)
+	}
+
+	if !strings.Contains(output, "1 of 5 files failed") || !strings.Contains(output, filepath.Join("svc-02", ".env.kanuka")) {
+		t.Errorf("Expected the failed file to be reported, got: %s", output)
+	}
+	for _, name := range []string{"svc-00", "svc-01", "svc-03", "svc-04"} {
+		info, err := os.Stat(filepath.Join(tempDir, "services", name, ".env.kanuka"))
+		if err != nil || info.IsDir() {
+			t.Errorf("Expected %s/.env.kanuka to be encrypted despite the failure: %v", name, err)
+		}
+	}
+}
+
+// TestEncryptJobsInvalid tests that a negative --jobs is rejected.
+func TestEncryptJobsInvalid(t *testing.T) {
+	tempDir := setupManyEnvFiles(t, 1)
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("encrypt", []string{"--jobs", "-1"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Fatalf("Expected ErrInvalidArguments, got: %v", err)
+	}
+
+	if !strings.Contains(output, "--jobs must be at least 1") {
+		t.Errorf("Expected a --jobs error, got: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "services", "svc-00", ".env.kanuka")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be encrypted with an invalid --jobs")
+	}
+}
@@ -1,12 +1,14 @@
 package encrypt_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -223,8 +225,8 @@ func testEncryptWithoutAccess(t *testing.T, originalWd string, originalUserSetti
 		cmd := shared.CreateTestCLI("encrypt", nil, nil, true, false)
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
+	if !errors.Is(err, kerrors.ErrPrivateKeyNotFound) {
+		t.Errorf("Expected ErrPrivateKeyNotFound, got: %v", err)
 	}
 
 	if !strings.Contains(output, "Failed to get your private key file") {
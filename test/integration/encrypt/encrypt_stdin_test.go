@@ -0,0 +1,124 @@
+package encrypt_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// TestEncryptFromStdin tests that encrypt --stdin --name writes only the
+// .kanuka file, and that it decrypts to the piped plaintext.
+func TestEncryptFromStdin(t *testing.T) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	originalUserSettings := configs.UserKanukaSettings
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	if err := os.MkdirAll(filepath.Join(tempDir, "services", "api"), 0755); err != nil {
+		t.Fatalf("Failed to create services/api: %v", err)
+	}
+
+	plaintext := "DATABASE_URL=postgres://localhost/db\nAPI_KEY=piped-secret\n"
+	output, err := shared.CaptureOutputWithStdin([]byte(plaintext), func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("encrypt", []string{"--stdin", "--name", "services/api/.env"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Encrypt command failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "encrypted successfully") {
+		t.Errorf("Expected success message, got: %s", output)
+	}
+
+	envPath := filepath.Join(tempDir, "services", "api", ".env")
+	if _, err := os.Stat(envPath + ".kanuka"); err != nil {
+		t.Fatalf("Expected services/api/.env.kanuka to be created: %v", err)
+	}
+	if _, err := os.Stat(envPath); !os.IsNotExist(err) {
+		t.Fatalf("Expected no plaintext file to be written, but %s exists", envPath)
+	}
+
+	output, err = shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("decrypt", []string{"services/api/.env.kanuka"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Decrypt command failed: %v\nOutput: %s", err, output)
+	}
+
+	decrypted, err := os.ReadFile(envPath)
+	if err != nil {
+		t.Fatalf("Failed to read decrypted file: %v", err)
+	}
+	if string(decrypted) != plaintext {
+		t.Errorf("Expected decrypted content %q, got %q", plaintext, string(decrypted))
+	}
+}
+
+// TestEncryptFromStdinInvalidFlags tests that encrypt --stdin rejects
+// missing or invalid names without writing anything.
+func TestEncryptFromStdinInvalidFlags(t *testing.T) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	originalUserSettings := configs.UserKanukaSettings
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantErr  error
+		expected string
+	}{
+		{"missing name", []string{"--stdin"}, kerrors.ErrInvalidArguments, "--stdin requires --name"},
+		{"name without stdin", []string{"--name", ".env"}, kerrors.ErrInvalidArguments, "--name can only be used with --stdin"},
+		{"with file arguments", []string{"--stdin", "--name", ".env", ".env.local"}, kerrors.ErrInvalidArguments, "can't be combined with file arguments"},
+		{"not an env name", []string{"--stdin", "--name", "secrets.txt"}, kerrors.ErrInvalidFileType, "not a .env file name"},
+		{"outside project", []string{"--stdin", "--name", "../.env"}, kerrors.ErrInvalidArguments, "outside the project"},
+		{"missing directory", []string{"--stdin", "--name", "missing/.env"}, kerrors.ErrFileNotFound, "does not exist"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := shared.CaptureOutputWithStdin([]byte("KEY=value\n"), func() error {
+				cmd.ResetGlobalState()
+				testCmd := shared.CreateTestCLIWithArgs("encrypt", tt.args, nil, nil, false, false)
+				return testCmd.Execute()
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected %v, got: %v", tt.wantErr, err)
+			}
+			if !strings.Contains(output, tt.expected) {
+				t.Errorf("Expected output to contain %q, got: %s", tt.expected, output)
+			}
+		})
+	}
+
+	for _, f := range []string{".env.kanuka", ".env.local.kanuka", "secrets.txt.kanuka"} {
+		if _, err := os.Stat(filepath.Join(tempDir, f)); !os.IsNotExist(err) {
+			t.Errorf("Expected %s not to be written", f)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package exitcode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// buildKanuka builds the kanuka binary into a temporary directory and returns
+// its path, so tests can check the exit code of a real process.
+func buildKanuka(t *testing.T) string {
+	t.Helper()
+	binary := filepath.Join(t.TempDir(), "kanuka")
+	build := exec.Command("go", "build", "-o", binary, "github.com/PolarWolf314/kanuka")
+	if output, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to build kanuka: %v\n%s", err, output)
+	}
+	return binary
+}
+
+// setupProject builds kanuka and sets up a user in a temporary directory,
+// initializing a project there if initialize is true. Returns the binary,
+// the project directory and the user directory.
+func setupProject(t *testing.T, initialize bool) (string, string, string) {
+	t.Helper()
+	binary := buildKanuka(t)
+
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	if initialize {
+		shared.InitializeProject(t, tempDir, tempUserDir)
+	}
+	return binary, tempDir, tempUserDir
+}
+
+// runKanuka runs the kanuka binary in dir with the user directories in
+// userDir, and returns its stdout, stderr and exit code.
+func runKanuka(t *testing.T, binary, dir, userDir string, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	command := exec.Command(binary, args...)
+	command.Dir = dir
+	command.Env = append(os.Environ(),
+		"HOME="+userDir,
+		configs.ConfigDirEnvVar+"="+filepath.Join(userDir, "config"),
+		configs.KeysDirEnvVar+"="+filepath.Join(userDir, "keys"),
+	)
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+
+	err := command.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return stdout.String(), stderr.String(), exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatalf("Failed to run kanuka: %v", err)
+	}
+	return stdout.String(), stderr.String(), 0
+}
+
+func TestExitCode_DecryptNotInitialized(t *testing.T) {
+	binary, projectDir, userDir := setupProject(t, false)
+
+	stdout, stderr, code := runKanuka(t, binary, projectDir, userDir, "secrets", "decrypt")
+	if code != kerrors.ExitNotInitialized {
+		t.Errorf("Expected exit code %d, got %d\nstdout: %s\nstderr: %s", kerrors.ExitNotInitialized, code, stdout, stderr)
+	}
+
+	output := stdout + stderr
+	if !strings.Contains(output, "has not been initialized") {
+		t.Errorf("Expected a not-initialized message, got: %s", output)
+	}
+	if strings.Contains(output, "Usage:") || strings.Contains(output, "Error:") {
+		t.Errorf("Expected the error to be shown once without usage, got: %s", output)
+	}
+}
+
+// TestExitCode_CommandsNotInitialized tests that commands which report a
+// missing project exit with the not-initialized code instead of 0.
+func TestExitCode_CommandsNotInitialized(t *testing.T) {
+	binary, projectDir, userDir := setupProject(t, false)
+
+	commands := [][]string{
+		{"secrets", "status"},
+		{"secrets", "sync"},
+		{"secrets", "rotate", "--force"},
+		{"secrets", "access"},
+		{"secrets", "export"},
+		{"secrets", "clean", "--force"},
+		{"secrets", "log"},
+		{"secrets", "rekey", "--user", "someone@example.com"},
+		{"secrets", "rekey-all"},
+		{"secrets", "prune", "--force"},
+		{"secrets", "transfer-ownership", "--user", "someone@example.com"},
+		{"secrets", "diff"},
+	}
+	for _, args := range commands {
+		t.Run(strings.Join(args[1:], " "), func(t *testing.T) {
+			stdout, stderr, code := runKanuka(t, binary, projectDir, userDir, args...)
+			if code != kerrors.ExitNotInitialized {
+				t.Errorf("Expected exit code %d, got %d\nstdout: %s\nstderr: %s", kerrors.ExitNotInitialized, code, stdout, stderr)
+			}
+		})
+	}
+}
+
+func TestExitCode_ExportToS3(t *testing.T) {
+	binary, projectDir, userDir := setupProject(t, true)
+
+	stdout, stderr, code := runKanuka(t, binary, projectDir, userDir, "secrets", "export", "-o", "s3://backups/project.tar.gz")
+	if code != kerrors.ExitGeneral {
+		t.Errorf("Expected exit code %d, got %d\nstdout: %s\nstderr: %s", kerrors.ExitGeneral, code, stdout, stderr)
+	}
+}
+
+func TestExitCode_DecryptWithoutPrivateKey(t *testing.T) {
+	binary, projectDir, userDir := setupProject(t, true)
+
+	if err := os.WriteFile(filepath.Join(projectDir, ".env"), []byte("KEY=value\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	if stdout, stderr, code := runKanuka(t, binary, projectDir, userDir, "secrets", "encrypt"); code != 0 {
+		t.Fatalf("Expected encrypt to succeed, got exit code %d\nstdout: %s\nstderr: %s", code, stdout, stderr)
+	}
+
+	keyDir := shared.GetKeyDirPath(filepath.Join(userDir, "keys"), shared.GetProjectUUID(t))
+	if err := os.RemoveAll(keyDir); err != nil {
+		t.Fatalf("Failed to remove key directory: %v", err)
+	}
+
+	stdout, stderr, code := runKanuka(t, binary, projectDir, userDir, "secrets", "decrypt")
+	if code != kerrors.ExitKeyNotFound {
+		t.Errorf("Expected exit code %d, got %d\nstdout: %s\nstderr: %s", kerrors.ExitKeyNotFound, code, stdout, stderr)
+	}
+}
+
+func TestExitCode_JSONErrorIsOnlyOutput(t *testing.T) {
+	binary, projectDir, userDir := setupProject(t, true)
+
+	stdout, stderr, code := runKanuka(t, binary, projectDir, userDir, "secrets", "encrypt", "--output", "json")
+	if code != kerrors.ExitGeneral {
+		t.Errorf("Expected exit code %d, got %d\nstdout: %s\nstderr: %s", kerrors.ExitGeneral, code, stdout, stderr)
+	}
+	if stdout != "" {
+		t.Errorf("Expected nothing on stdout, got: %s", stdout)
+	}
+
+	var result struct {
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}
+	if err := json.Unmarshal([]byte(stderr), &result); err != nil {
+		t.Fatalf("Expected stderr to be a single JSON error: %v\n%s", err, stderr)
+	}
+	if result.Code != "no_files_found" {
+		t.Errorf("Expected code no_files_found, got %q", result.Code)
+	}
+}
@@ -0,0 +1,112 @@
+package export
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// TestExport_Stdout tests that export -o - writes only the archive to stdout
+// and creates no local file.
+func TestExport_Stdout(t *testing.T) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	originalUserSettings := configs.UserKanukaSettings
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	setupExportTestProject(t, tempDir, tempUserDir)
+	createEncryptedEnvFile(t, tempDir, ".env")
+
+	stdout, stderr, err := shared.CaptureStdoutAndStderr(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("export", []string{"-o", "-"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Export command failed: %v\nStderr: %s", err, stderr)
+	}
+	if !strings.Contains(stderr, "Exported secrets to") {
+		t.Errorf("Expected the success message on stderr, got: %s", stderr)
+	}
+
+	gzReader, err := gzip.NewReader(strings.NewReader(stdout))
+	if err != nil {
+		t.Fatalf("Expected stdout to be a gzip archive: %v", err)
+	}
+	defer gzReader.Close()
+
+	var files []string
+	tarReader := tar.NewReader(gzReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Failed to read tar header: %v", err)
+		}
+		files = append(files, header.Name)
+	}
+	for _, want := range []string{".kanuka/config.toml", ".env.kanuka"} {
+		if !slices.Contains(files, want) {
+			t.Errorf("Expected archive to contain %s, got %v", want, files)
+		}
+	}
+
+	matches, err := os.ReadDir(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to read project directory: %v", err)
+	}
+	for _, entry := range matches {
+		if strings.HasPrefix(entry.Name(), "kanuka-secrets-") || entry.Name() == "-" {
+			t.Errorf("Expected no local archive, found %s", entry.Name())
+		}
+	}
+}
+
+// TestExport_S3DestinationRejected tests that s3:// destinations fail with
+// a hint to stream to the AWS CLI, without writing a local file.
+func TestExport_S3DestinationRejected(t *testing.T) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	originalUserSettings := configs.UserKanukaSettings
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	setupExportTestProject(t, tempDir, tempUserDir)
+	createEncryptedEnvFile(t, tempDir, ".env")
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("export", []string{"-o", "s3://backups/project.tar.gz"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Fatalf("Expected ErrInvalidArguments, got: %v", err)
+	}
+	if !strings.Contains(output, "aws s3 cp - s3://backups/project.tar.gz") {
+		t.Errorf("Expected a hint to use the AWS CLI, got: %s", output)
+	}
+	if _, err := os.Stat("s3:"); !os.IsNotExist(err) {
+		t.Errorf("Expected no local s3: directory to be created")
+	}
+}
@@ -0,0 +1,293 @@
+package files
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// FilesResult mirrors the cmd.filesJSONResult struct for JSON parsing.
+type FilesResult struct {
+	Matched  []FileEntry `json:"matched"`
+	Excluded []FileEntry `json:"excluded"`
+}
+
+// FileEntry mirrors the cmd.filesJSONEntry struct for JSON parsing.
+type FileEntry struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// writeFile creates a file (and its parent directories) with the given content.
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory for %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to create file %s: %v", path, err)
+	}
+}
+
+// setupFilesTest creates an initialized project in a temp directory.
+func setupFilesTest(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+	return tempDir
+}
+
+// runFilesJSON runs the files command with --json and parses the output.
+func runFilesJSON(t *testing.T, args ...string) FilesResult {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLIWithArgs("files", append(args, "--json"), nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Files command failed: %v", err)
+	}
+
+	var result FilesResult
+	if err := json.Unmarshal([]byte(output[strings.Index(output, "{"):]), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v\nOutput: %s", err, output)
+	}
+	return result
+}
+
+func findEntry(entries []FileEntry, path string) *FileEntry {
+	for i := range entries {
+		if entries[i].Path == path {
+			return &entries[i]
+		}
+	}
+	return nil
+}
+
+func TestFiles_ListsEnvFilesWithReasons(t *testing.T) {
+	tempDir := setupFilesTest(t)
+
+	writeFile(t, filepath.Join(tempDir, ".env"), "A=1")
+	writeFile(t, filepath.Join(tempDir, "services", "api", ".env.production"), "B=2")
+	writeFile(t, filepath.Join(tempDir, "services", "api", ".env.production.kanuka"), "encrypted")
+	writeFile(t, filepath.Join(tempDir, "README.md"), "not a secret")
+
+	result := runFilesJSON(t)
+
+	if len(result.Matched) != 2 {
+		t.Fatalf("Expected 2 matched files, got %d: %+v", len(result.Matched), result.Matched)
+	}
+	if findEntry(result.Matched, ".env") == nil {
+		t.Errorf("Expected .env to be matched, got: %+v", result.Matched)
+	}
+	nested := findEntry(result.Matched, filepath.Join("services", "api", ".env.production"))
+	if nested == nil {
+		t.Fatalf("Expected nested .env.production to be matched, got: %+v", result.Matched)
+	}
+	if nested.Reason == "" {
+		t.Errorf("Expected a reason for matched file")
+	}
+
+	encrypted := findEntry(result.Excluded, filepath.Join("services", "api", ".env.production.kanuka"))
+	if encrypted == nil {
+		t.Fatalf("Expected .kanuka file to be listed as excluded, got: %+v", result.Excluded)
+	}
+	if !strings.Contains(encrypted.Reason, "already encrypted") {
+		t.Errorf("Expected 'already encrypted' reason, got: %s", encrypted.Reason)
+	}
+
+	if findEntry(result.Excluded, ".kanuka") == nil {
+		t.Errorf("Expected .kanuka directory to be listed as excluded, got: %+v", result.Excluded)
+	}
+	if findEntry(result.Excluded, "README.md") != nil || findEntry(result.Matched, "README.md") != nil {
+		t.Errorf("Non-candidate files should not be listed")
+	}
+}
+
+func TestFiles_EncryptedFlagListsKanukaFiles(t *testing.T) {
+	tempDir := setupFilesTest(t)
+
+	writeFile(t, filepath.Join(tempDir, ".env"), "A=1")
+	writeFile(t, filepath.Join(tempDir, ".env.kanuka"), "encrypted")
+
+	result := runFilesJSON(t, "--encrypted")
+
+	if len(result.Matched) != 1 || result.Matched[0].Path != ".env.kanuka" {
+		t.Fatalf("Expected only .env.kanuka to be matched, got: %+v", result.Matched)
+	}
+	if findEntry(result.Excluded, ".env") == nil {
+		t.Errorf("Expected plaintext .env to be listed as excluded, got: %+v", result.Excluded)
+	}
+}
+
+func TestFiles_WithPatterns(t *testing.T) {
+	tempDir := setupFilesTest(t)
+
+	writeFile(t, filepath.Join(tempDir, "services", "api", ".env"), "A=1")
+	writeFile(t, filepath.Join(tempDir, "services", "web", ".env"), "B=2")
+	writeFile(t, filepath.Join(tempDir, ".env"), "C=3")
+
+	result := runFilesJSON(t, "services/*/.env", "missing/.env")
+
+	if len(result.Matched) != 2 {
+		t.Fatalf("Expected 2 matched files, got %d: %+v", len(result.Matched), result.Matched)
+	}
+	for _, m := range result.Matched {
+		if !strings.Contains(m.Reason, "services/*/.env") {
+			t.Errorf("Expected reason to name the matching pattern, got: %s", m.Reason)
+		}
+	}
+
+	missing := findEntry(result.Excluded, "missing/.env")
+	if missing == nil {
+		t.Fatalf("Expected unmatched pattern to be reported, got: %+v", result.Excluded)
+	}
+	if !strings.Contains(missing.Reason, "not found") {
+		t.Errorf("Expected 'not found' reason, got: %s", missing.Reason)
+	}
+}
+
+func TestFiles_DiscoverFlags(t *testing.T) {
+	tempDir := setupFilesTest(t)
+
+	writeFile(t, filepath.Join(tempDir, ".env"), "A=1")
+	writeFile(t, filepath.Join(tempDir, ".env.production"), "B=2")
+	writeFile(t, filepath.Join(tempDir, "services", "api", ".env.production"), "C=3")
+
+	result := runFilesJSON(t, "--recursive=false", "--name-filter", ".env.prod*")
+
+	if len(result.Matched) != 1 || result.Matched[0].Path != ".env.production" {
+		t.Fatalf("Expected only the root .env.production to be matched, got: %+v", result.Matched)
+	}
+}
+
+func TestFiles_UsesEncryptSettings(t *testing.T) {
+	tempDir := setupFilesTest(t)
+
+	writeFile(t, filepath.Join(tempDir, ".env"), "A=1")
+	writeFile(t, filepath.Join(tempDir, "services", "api", ".env"), "B=2")
+	writeFile(t, filepath.Join(tempDir, ".kanuka", "settings.toml"), "[encrypt]\nrecursive = false\n")
+
+	result := runFilesJSON(t)
+	if len(result.Matched) != 1 || result.Matched[0].Path != ".env" {
+		t.Fatalf("Expected encrypt's recursive = false to apply, got: %+v", result.Matched)
+	}
+
+	// A flag given on the command line still wins.
+	result = runFilesJSON(t, "--recursive")
+	if len(result.Matched) != 2 {
+		t.Fatalf("Expected --recursive to override the setting, got: %+v", result.Matched)
+	}
+}
+
+func TestFiles_DiscoverFlagsRejectFileArguments(t *testing.T) {
+	tempDir := setupFilesTest(t)
+
+	writeFile(t, filepath.Join(tempDir, ".env"), "A=1")
+
+	for _, args := range [][]string{
+		{"--name-filter", ".env*", ".env"},
+		{"--recursive=false", "--encrypted"},
+		{"--name-filter", "a/b"},
+	} {
+		output, err := shared.CaptureOutput(func() error {
+			testCmd := shared.CreateTestCLIWithArgs("files", args, nil, nil, false, false)
+			return testCmd.Execute()
+		})
+		if !errors.Is(err, kerrors.ErrInvalidArguments) {
+			t.Errorf("files %v: expected ErrInvalidArguments, got: %v\nOutput: %s", args, err, output)
+		}
+	}
+}
+
+func TestFiles_HumanReadableOutput(t *testing.T) {
+	tempDir := setupFilesTest(t)
+
+	writeFile(t, filepath.Join(tempDir, ".env"), "A=1")
+
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLIWithArgs("files", []string{}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Files command failed: %v", err)
+	}
+
+	if !strings.Contains(output, "would act on (1)") {
+		t.Errorf("Expected matched count in output, got: %s", output)
+	}
+	if !strings.Contains(output, "Excluded") {
+		t.Errorf("Expected excluded section in output, got: %s", output)
+	}
+}
+
+func TestFiles_NotInitialized(t *testing.T) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLIWithArgs("files", []string{}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Fatalf("Expected ErrProjectNotInitialized, got: %v", err)
+	}
+
+	if !strings.Contains(output, "has not been initialized") {
+		t.Errorf("Expected not-initialized message, got: %s", output)
+	}
+}
+
+func TestFiles_NotInitializedJSON(t *testing.T) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+
+	stdout, stderr, err := shared.CaptureStdoutAndStderr(func() error {
+		testCmd := shared.CreateTestCLIWithArgs("files", []string{"--json"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Fatalf("Expected ErrProjectNotInitialized, got: %v", err)
+	}
+	if strings.TrimSpace(stdout) != "" {
+		t.Errorf("Expected nothing on stdout, got: %s", stdout)
+	}
+
+	var result struct {
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}
+	if err := json.Unmarshal([]byte(stderr), &result); err != nil {
+		t.Fatalf("Expected stderr to be a JSON error: %v\n%s", err, stderr)
+	}
+	if result.Code != "project_not_initialized" {
+		t.Errorf("Expected code project_not_initialized, got %q", result.Code)
+	}
+}
@@ -0,0 +1,150 @@
+package headless
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/utils"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupHeadlessProject creates an initialized project with an encrypted .env,
+// then removes the user's config and keys so only the environment can
+// identify the user. Returns the project directory and the private key bytes.
+func setupHeadlessProject(t *testing.T) (string, []byte) {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	envPath := filepath.Join(tempDir, ".env")
+	if err := os.WriteFile(envPath, []byte("API_KEY=secret\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+
+	_, err = shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("encrypt", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	projectUUID := shared.GetProjectUUID(t)
+	keyData, err := os.ReadFile(shared.GetPrivateKeyPath(filepath.Join(tempUserDir, "keys"), projectUUID))
+	if err != nil {
+		t.Fatalf("Failed to read private key: %v", err)
+	}
+
+	if err := os.RemoveAll(tempUserDir); err != nil {
+		t.Fatalf("Failed to remove user directory: %v", err)
+	}
+	if err := os.Remove(envPath); err != nil {
+		t.Fatalf("Failed to remove .env: %v", err)
+	}
+
+	// Run from outside the project so discovery can't find it.
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	return tempDir, keyData
+}
+
+func runHeadlessDecrypt(t *testing.T) (string, error) {
+	t.Helper()
+	return shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("decrypt", nil, nil, false, false).Execute()
+	})
+}
+
+func TestHeadless_DecryptWithKeyMaterial(t *testing.T) {
+	projectDir, keyData := setupHeadlessProject(t)
+
+	t.Setenv(utils.ProjectRootEnvVar, projectDir)
+	t.Setenv(utils.PrivateKeyEnvVar, string(keyData))
+	t.Setenv(utils.UserEmailEnvVar, shared.TestUserEmail)
+
+	output, err := runHeadlessDecrypt(t)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "decrypted successfully") {
+		t.Fatalf("Expected successful decrypt, got: %s", output)
+	}
+
+	data, err := os.ReadFile(filepath.Join(projectDir, ".env"))
+	if err != nil {
+		t.Fatalf("Expected .env to be decrypted into the project root: %v", err)
+	}
+	if string(data) != "API_KEY=secret\n" {
+		t.Errorf("Unexpected decrypted content: %q", data)
+	}
+
+	configPath := filepath.Join(configs.UserKanukaSettings.UserConfigsPath, "config.toml")
+	if _, err := os.Stat(configPath); err == nil {
+		t.Errorf("Expected headless run not to write a user config")
+	}
+}
+
+func TestHeadless_DecryptWithKeyPathAndUUID(t *testing.T) {
+	projectDir, keyData := setupHeadlessProject(t)
+
+	keyPath := filepath.Join(t.TempDir(), "ci.key")
+	if err := os.WriteFile(keyPath, keyData, 0600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+
+	t.Setenv(utils.ProjectRootEnvVar, projectDir)
+	t.Setenv(utils.PrivateKeyEnvVar, keyPath)
+	t.Setenv(utils.UserUUIDEnvVar, shared.TestUserUUID)
+
+	output, err := runHeadlessDecrypt(t)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "decrypted successfully") {
+		t.Fatalf("Expected successful decrypt, got: %s", output)
+	}
+}
+
+func TestHeadless_UnregisteredEmailHasNoAccess(t *testing.T) {
+	projectDir, keyData := setupHeadlessProject(t)
+
+	t.Setenv(utils.ProjectRootEnvVar, projectDir)
+	t.Setenv(utils.PrivateKeyEnvVar, string(keyData))
+	t.Setenv(utils.UserEmailEnvVar, "nobody@example.com")
+
+	output, err := runHeadlessDecrypt(t)
+	if !errors.Is(err, kerrors.ErrNoAccess) {
+		t.Errorf("Expected ErrNoAccess, got: %v", err)
+	}
+	if !strings.Contains(output, "Are you sure you have access?") {
+		t.Errorf("Expected no-access error, got: %s", output)
+	}
+}
+
+func TestHeadless_ProjectRootWithoutKanuka(t *testing.T) {
+	setupHeadlessProject(t)
+
+	t.Setenv(utils.ProjectRootEnvVar, t.TempDir())
+
+	output, err := runHeadlessDecrypt(t)
+	if !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Errorf("Expected ErrProjectNotInitialized, got: %v", err)
+	}
+	if !strings.Contains(output, "has not been initialized") {
+		t.Errorf("Expected not-initialized error, got: %s", output)
+	}
+}
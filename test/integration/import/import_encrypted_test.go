@@ -0,0 +1,132 @@
+package importtest
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/utils"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// exportEncryptedProject runs export --encrypt-archive and returns the archive path.
+func exportEncryptedProject(t *testing.T, tempDir string) string {
+	archivePath := filepath.Join(tempDir, "backup.tar.gz.enc")
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLIWithArgs("export", []string{"-o", archivePath, "--encrypt-archive"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Failed to export project: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "encrypted with your passphrase") {
+		t.Errorf("Expected encrypted archive note, got: %s", output)
+	}
+	return archivePath
+}
+
+// setupEncryptedImportTest exports an encrypted archive from a fresh project and
+// changes into an empty target directory. It returns the archive and target paths.
+func setupEncryptedImportTest(t *testing.T) (string, string) {
+	sourceDir := t.TempDir()
+	sourceUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	originalUserSettings := configs.UserKanukaSettings
+	shared.SetupTestEnvironment(t, sourceDir, sourceUserDir, originalWd, originalUserSettings)
+
+	setupImportTestProject(t, sourceDir, sourceUserDir)
+	createEncryptedEnvFile(t, sourceDir, ".env", "SECRET=value123\n")
+
+	archivePath := exportEncryptedProject(t, sourceDir)
+
+	targetDir := t.TempDir()
+	if err := os.Chdir(targetDir); err != nil {
+		t.Fatalf("Failed to change to target directory: %v", err)
+	}
+	return archivePath, targetDir
+}
+
+func TestExport_EncryptArchive_HidesMetadata(t *testing.T) {
+	t.Setenv(utils.ArchivePassphraseEnvVar, "correct horse battery staple")
+	archivePath, _ := setupEncryptedImportTest(t)
+
+	data, err := os.ReadFile(archivePath)
+	if err != nil {
+		t.Fatalf("Failed to read archive: %v", err)
+	}
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		t.Error("Encrypted archive should not be a plain gzip file")
+	}
+	if bytes.Contains(data, []byte("config.toml")) || bytes.Contains(data, []byte("public_keys")) {
+		t.Error("Encrypted archive leaks file names")
+	}
+}
+
+func TestImport_EncryptedArchive_RoundTrip(t *testing.T) {
+	t.Setenv(utils.ArchivePassphraseEnvVar, "correct horse battery staple")
+	archivePath, targetDir := setupEncryptedImportTest(t)
+
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLIWithArgs("import", []string{archivePath}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Import command failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "Imported secrets from") {
+		t.Errorf("Expected success message, got: %s", output)
+	}
+
+	for _, rel := range []string{".kanuka/config.toml", ".env.kanuka"} {
+		if _, err := os.Stat(filepath.Join(targetDir, rel)); err != nil {
+			t.Errorf("%s was not imported: %v", rel, err)
+		}
+	}
+}
+
+func TestImport_EncryptedArchive_WrongPassphrase(t *testing.T) {
+	t.Setenv(utils.ArchivePassphraseEnvVar, "correct horse battery staple")
+	archivePath, targetDir := setupEncryptedImportTest(t)
+
+	t.Setenv(utils.ArchivePassphraseEnvVar, "wrong passphrase")
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLIWithArgs("import", []string{archivePath}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if !errors.Is(err, kerrors.ErrArchiveDecryptFailed) {
+		t.Fatalf("Expected ErrArchiveDecryptFailed, got: %v", err)
+	}
+	if !strings.Contains(output, "Failed to decrypt archive") {
+		t.Errorf("Expected decrypt failure message, got: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(targetDir, ".kanuka")); !os.IsNotExist(err) {
+		t.Error(".kanuka should not be created when decryption fails")
+	}
+}
+
+func TestImport_EncryptedArchive_NoPassphrase(t *testing.T) {
+	t.Setenv(utils.ArchivePassphraseEnvVar, "correct horse battery staple")
+	archivePath, _ := setupEncryptedImportTest(t)
+
+	// Tests have no terminal, so without the env var there is no way to prompt.
+	t.Setenv(utils.ArchivePassphraseEnvVar, "")
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLIWithArgs("import", []string{archivePath}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if !errors.Is(err, kerrors.ErrTTYRequired) {
+		t.Fatalf("Expected ErrTTYRequired, got: %v", err)
+	}
+	if !strings.Contains(output, utils.ArchivePassphraseEnvVar) {
+		t.Errorf("Expected hint to set %s, got: %s", utils.ArchivePassphraseEnvVar, output)
+	}
+}
@@ -0,0 +1,121 @@
+package importtest
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// writeTestArchive writes a tar.gz archive holding members, in order, to path.
+func writeTestArchive(t *testing.T, path string, members [][2]string) {
+	t.Helper()
+	outFile, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create archive file: %v", err)
+	}
+	defer outFile.Close()
+	gzWriter := gzip.NewWriter(outFile)
+	tarWriter := tar.NewWriter(gzWriter)
+	for _, member := range members {
+		header := &tar.Header{Name: member[0], Mode: 0600, Size: int64(len(member[1]))}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write tar header: %v", err)
+		}
+		if _, err := tarWriter.Write([]byte(member[1])); err != nil {
+			t.Fatalf("Failed to write tar content: %v", err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("Failed to close tar writer: %v", err)
+	}
+	if err := gzWriter.Close(); err != nil {
+		t.Fatalf("Failed to close gzip writer: %v", err)
+	}
+}
+
+// TestImport_ArchiveValidationErrors tests that each way an archive can be
+// invalid is reported with its own error.
+func TestImport_ArchiveValidationErrors(t *testing.T) {
+	const validConfig = "[project]\nname = \"test\"\n"
+
+	tests := []struct {
+		name    string
+		members [][2]string
+		// preCheck is whether ImportPreCheck catches the problem, before
+		// anything is extracted.
+		preCheck bool
+		want     error
+	}{
+		{
+			name:     "missing config",
+			members:  [][2]string{{".env.kanuka", "ciphertext"}},
+			preCheck: true,
+			want:     kerrors.ErrArchiveMissingConfig,
+		},
+		{
+			name:     "no encrypted content",
+			members:  [][2]string{{".kanuka/config.toml", validConfig}},
+			preCheck: true,
+			want:     kerrors.ErrArchiveInvalid,
+		},
+		{
+			name:    "empty config",
+			members: [][2]string{{".kanuka/config.toml", ""}, {".env.kanuka", "ciphertext"}},
+			want:    kerrors.ErrArchiveEmptyConfig,
+		},
+		{
+			name:    "invalid config",
+			members: [][2]string{{".kanuka/config.toml", "[invalid toml [unclosed bracket"}, {".env.kanuka", "ciphertext"}},
+			want:    kerrors.ErrInvalidProjectConfig,
+		},
+		{
+			name:    "path traversal",
+			members: [][2]string{{"../outside.kanuka", "ciphertext"}, {".kanuka/config.toml", validConfig}},
+			want:    kerrors.ErrArchiveInvalid,
+		},
+	}
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			shared.SetupTestEnvironment(t, tempDir, t.TempDir(), originalWd, configs.UserKanukaSettings)
+
+			archivePath := filepath.Join(tempDir, "archive.tar.gz")
+			writeTestArchive(t, archivePath, tt.members)
+
+			_, err := workflows.ImportPreCheck(context.Background(), archivePath, nil)
+			if tt.preCheck {
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("Expected ImportPreCheck to return %v, got: %v", tt.want, err)
+				}
+			} else if err != nil {
+				t.Fatalf("Expected ImportPreCheck to pass, got: %v", err)
+			}
+
+			_, err = workflows.Import(context.Background(), workflows.ImportOptions{
+				ArchivePath: archivePath,
+				ProjectPath: tempDir,
+				Mode:        workflows.ImportModeMerge,
+			})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Expected Import to return %v, got: %v", tt.want, err)
+			}
+			if _, err := os.Stat(filepath.Join(tempDir, ".kanuka")); !os.IsNotExist(err) {
+				t.Errorf("Expected no .kanuka directory to be left behind")
+			}
+		})
+	}
+}
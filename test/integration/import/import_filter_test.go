@@ -0,0 +1,144 @@
+package importtest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+var filterTestFiles = []string{
+	filepath.Join("services", "api", ".env"),
+	filepath.Join("services", "api", ".env.test"),
+	filepath.Join("services", "web", ".env"),
+}
+
+// setupFilterImport exports a project with several encrypted files, then
+// removes the encrypted files so an import has something to restore.
+// Returns the project directory and the archive path.
+func setupFilterImport(t *testing.T) (string, string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	for _, f := range filterTestFiles {
+		path := filepath.Join(tempDir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", f, err)
+		}
+		if err := os.WriteFile(path, []byte("SECRET=value\n"), 0600); err != nil {
+			t.Fatalf("Failed to create %s: %v", f, err)
+		}
+	}
+	if _, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("encrypt", nil, nil, false, false).Execute()
+	}); err != nil {
+		t.Fatalf("Failed to encrypt files: %v", err)
+	}
+
+	archivePath := exportProject(t, tempDir)
+
+	for _, f := range filterTestFiles {
+		if err := os.Remove(filepath.Join(tempDir, f+".kanuka")); err != nil {
+			t.Fatalf("Failed to remove %s.kanuka: %v", f, err)
+		}
+	}
+	return tempDir, archivePath
+}
+
+func runImport(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs("import", args, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Import command failed: %v\nOutput: %s", err, output)
+	}
+	return output
+}
+
+func assertImported(t *testing.T, tempDir string, want map[string]bool) {
+	t.Helper()
+	for f, imported := range want {
+		_, err := os.Stat(filepath.Join(tempDir, f+".kanuka"))
+		if imported && err != nil {
+			t.Errorf("Expected %s.kanuka to be imported: %v", f, err)
+		}
+		if !imported && !os.IsNotExist(err) {
+			t.Errorf("Expected %s.kanuka not to be imported", f)
+		}
+	}
+}
+
+func TestImport_OnlyImportsMatchingFiles(t *testing.T) {
+	tempDir, archivePath := setupFilterImport(t)
+
+	output := runImport(t, archivePath, "--merge", "--only", "services/api")
+	if !strings.Contains(output, "Filtered out: 1") {
+		t.Errorf("Expected one file to be filtered out, got: %s", output)
+	}
+
+	assertImported(t, tempDir, map[string]bool{
+		filterTestFiles[0]: true,
+		filterTestFiles[1]: true,
+		filterTestFiles[2]: false,
+	})
+}
+
+func TestImport_ExcludeWinsOverOnly(t *testing.T) {
+	tempDir, archivePath := setupFilterImport(t)
+
+	runImport(t, archivePath, "--merge", "--only", "services/**", "--exclude", "**/.env.test.kanuka")
+
+	assertImported(t, tempDir, map[string]bool{
+		filterTestFiles[0]: true,
+		filterTestFiles[1]: false,
+		filterTestFiles[2]: true,
+	})
+}
+
+func TestImport_DryRunListsFilteredFiles(t *testing.T) {
+	tempDir, archivePath := setupFilterImport(t)
+
+	output := runImport(t, archivePath, "--merge", "--dry-run", "--only", "services/web/*")
+	if !strings.Contains(output, "Would import:") || !strings.Contains(output, "services/web/.env.kanuka") {
+		t.Errorf("Expected the dry run to list services/web/.env.kanuka, got: %s", output)
+	}
+	if strings.Contains(output, "services/api/") {
+		t.Errorf("Expected the dry run not to list filtered files, got: %s", output)
+	}
+
+	assertImported(t, tempDir, map[string]bool{
+		filterTestFiles[0]: false,
+		filterTestFiles[1]: false,
+		filterTestFiles[2]: false,
+	})
+}
+
+func TestImport_InvalidPattern(t *testing.T) {
+	tempDir, archivePath := setupFilterImport(t)
+
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs("import", []string{archivePath, "--merge", "--only", "services/[api"}, nil, nil, false, false).Execute()
+	})
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Errorf("Expected ErrInvalidArguments, got: %v", err)
+	}
+	if !strings.Contains(output, "Invalid") || !strings.Contains(output, "services/[api") {
+		t.Errorf("Expected an invalid pattern error, got: %s", output)
+	}
+	assertImported(t, tempDir, map[string]bool{filterTestFiles[0]: false})
+}
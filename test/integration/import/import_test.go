@@ -3,6 +3,7 @@ package importtest
 import (
 	"archive/tar"
 	"compress/gzip"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
@@ -10,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -454,6 +456,12 @@ func TestImport_ReplaceMode(t *testing.T) {
 		t.Errorf("Extra file should have been removed in replace mode")
 	}
 
+	// The import held the project lock, which git should still ignore.
+	gitignore, err := os.ReadFile(filepath.Join(targetDir, ".kanuka", ".gitignore"))
+	if err != nil || !strings.Contains(string(gitignore), configs.ProjectLockFileName) {
+		t.Errorf("Expected .kanuka/.gitignore to list the lock file, got %q (%v)", gitignore, err)
+	}
+
 	// Verify config.toml exists (from archive).
 	configPath := filepath.Join(targetDir, ".kanuka", "config.toml")
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
@@ -598,9 +606,8 @@ func TestImport_InvalidArchive(t *testing.T) {
 		return testCmd.Execute()
 	})
 
-	// Should not return error (we display custom message instead).
-	if err != nil {
-		t.Errorf("Expected no error for invalid archive (using custom message), got: %v", err)
+	if !errors.Is(err, kerrors.ErrInvalidFileType) {
+		t.Errorf("Expected ErrInvalidFileType, got: %v", err)
 	}
 
 	// Should NOT contain technical error details (like "gzip: invalid header").
@@ -673,9 +680,8 @@ func TestImport_MissingConfig(t *testing.T) {
 		return testCmd.Execute()
 	})
 
-	// Command returns nil for expected errors but shows error in output.
-	if err != nil {
-		t.Errorf("Command returned unexpected error: %v", err)
+	if !errors.Is(err, kerrors.ErrArchiveMissingConfig) {
+		t.Errorf("Expected ErrArchiveMissingConfig, got: %v", err)
 	}
 
 	// Should show validation error in output.
@@ -711,9 +717,8 @@ func TestImport_ArchiveNotFound(t *testing.T) {
 		return testCmd.Execute()
 	})
 
-	// Command returns nil for expected errors but shows error in output.
-	if err != nil {
-		t.Errorf("Command returned unexpected error: %v", err)
+	if !errors.Is(err, kerrors.ErrFileNotFound) {
+		t.Errorf("Expected ErrFileNotFound, got: %v", err)
 	}
 
 	// Should show file not found error in output.
@@ -755,9 +760,8 @@ func TestImport_ConflictingFlags(t *testing.T) {
 		return testCmd.Execute()
 	})
 
-	// Should not return an error.
-	if err != nil {
-		t.Errorf("Expected no error for conflicting flags, got: %v", err)
+	if !errors.Is(err, kerrors.ErrArchiveConflictingFlags) {
+		t.Errorf("Expected ErrArchiveConflictingFlags, got: %v", err)
 	}
 
 	// Verify error message is shown.
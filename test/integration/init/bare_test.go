@@ -0,0 +1,119 @@
+package init_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+func runSecretsCommand(t *testing.T, subcommand string, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs(subcommand, args, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("%s failed: %v\nOutput: %s", subcommand, err, output)
+	}
+	return output
+}
+
+func readDirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", dir, err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+// TestInitBare_CreatesNoKeys tests that --bare creates the project without
+// any user keys, leaving the user's key directory untouched.
+func TestInitBare_CreatesNoKeys(t *testing.T) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+
+	output := runSecretsCommand(t, "init", "--bare", "--name", "payments")
+	if !strings.Contains(output, "without any user keys") {
+		t.Errorf("Expected a bare init message, got: %s", output)
+	}
+
+	for _, dir := range []string{"public_keys", "secrets"} {
+		if names := readDirNames(t, filepath.Join(tempDir, ".kanuka", dir)); len(names) != 0 {
+			t.Errorf("Expected .kanuka/%s to be empty, got %v", dir, names)
+		}
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	if projectConfig.Project.Name != "payments" || projectConfig.Project.UUID == "" {
+		t.Errorf("Unexpected project: %+v", projectConfig.Project)
+	}
+	if len(projectConfig.Users) != 0 || len(projectConfig.Devices) != 0 {
+		t.Errorf("Expected no users, got %v and %v", projectConfig.Users, projectConfig.Devices)
+	}
+
+	keysDir := configs.UserKanukaSettings.UserKeysPath
+	if entries, err := os.ReadDir(keysDir); err == nil && len(entries) != 0 {
+		t.Errorf("Expected no key to be generated for the current user, found %d entries in %s", len(entries), keysDir)
+	}
+}
+
+// TestInitBare_FirstUserRegisters tests that the first user of a bare
+// project can create their key, register themselves, and then encrypt and
+// decrypt.
+func TestInitBare_FirstUserRegisters(t *testing.T) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+
+	runSecretsCommand(t, "init", "--bare", "--name", "payments")
+	runSecretsCommand(t, "create")
+
+	output := runSecretsCommand(t, "register", "--user", shared.TestUserEmail)
+	if !strings.Contains(output, "✓") {
+		t.Fatalf("Expected register to succeed, got: %s", output)
+	}
+	userKey := filepath.Join(tempDir, ".kanuka", "secrets", shared.GetUserUUID(t)+".kanuka")
+	if _, err := os.Stat(userKey); err != nil {
+		t.Fatalf("Expected %s to be created: %v", userKey, err)
+	}
+
+	envPath := filepath.Join(tempDir, ".env")
+	if err := os.WriteFile(envPath, []byte("API_KEY=secret\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	runSecretsCommand(t, "encrypt")
+	if err := os.Remove(envPath); err != nil {
+		t.Fatalf("Failed to remove .env: %v", err)
+	}
+	runSecretsCommand(t, "decrypt")
+
+	content, err := os.ReadFile(envPath)
+	if err != nil {
+		t.Fatalf("Expected .env to be decrypted: %v", err)
+	}
+	if string(content) != "API_KEY=secret\n" {
+		t.Errorf("Unexpected plaintext: %q", content)
+	}
+}
@@ -1,12 +1,14 @@
 package init_test
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -116,8 +118,8 @@ func testInitInAlreadyInitializedFolder(t *testing.T, originalWd string, origina
 		cmd := shared.CreateTestCLI("init", nil, nil, false, false)
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed: %v", err)
+	if !errors.Is(err, kerrors.ErrProjectAlreadyInitialized) {
+		t.Errorf("Expected ErrProjectAlreadyInitialized, got: %v", err)
 	}
 
 	if _, statErr := os.Stat(kanukaDir); os.IsNotExist(statErr) {
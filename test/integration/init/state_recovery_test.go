@@ -1,15 +1,29 @@
 package init_test
 
 import (
+	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
+// assertAlreadyInitialized checks that the init workflow refuses to run
+// because the project already has a .kanuka directory.
+func assertAlreadyInitialized(t *testing.T) {
+	t.Helper()
+	_, err := workflows.Init(context.Background(), workflows.InitOptions{})
+	if !errors.Is(err, kerrors.ErrProjectAlreadyInitialized) {
+		t.Errorf("Expected ErrProjectAlreadyInitialized, got: %v", err)
+	}
+}
+
 // TestSecretsInitStateRecovery contains state recovery tests for the `kanuka secrets init` command.
 func TestSecretsInitStateRecovery(t *testing.T) {
 	// Save original working directory and settings
@@ -69,14 +83,11 @@ func testInitWithPartialKanukaDirectory(t *testing.T, originalWd string, origina
 		cmd := shared.CreateTestCLI("init", nil, nil, true, false)
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
-		t.Errorf("Output: %s", output)
+	if !errors.Is(err, kerrors.ErrProjectAlreadyInitialized) {
+		t.Errorf("Expected ErrProjectAlreadyInitialized, got: %v\nOutput: %s", err, output)
 	}
 
-	if !strings.Contains(output, "already been initialized") {
-		t.Errorf("Expected message about already initialized, got: %s", output)
-	}
+	assertAlreadyInitialized(t)
 }
 
 func testInitAfterPartialFailure(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
@@ -107,12 +118,10 @@ func testInitAfterPartialFailure(t *testing.T, originalWd string, originalUserSe
 	})
 
 	// Should report already initialized
-	if err1 != nil {
-		t.Errorf("First init attempt failed unexpectedly: %v", err1)
-	}
-	if !strings.Contains(output1, "already been initialized") {
-		t.Errorf("Expected already initialized message, got: %s", output1)
+	if !errors.Is(err1, kerrors.ErrProjectAlreadyInitialized) {
+		t.Errorf("Expected ErrProjectAlreadyInitialized, got: %v\nOutput: %s", err1, output1)
 	}
+	assertAlreadyInitialized(t)
 
 	// Remove the .kanuka directory to simulate cleanup
 	if err := os.RemoveAll(kanukaDir); err != nil {
@@ -170,13 +179,10 @@ func testInitIdempotencyAfterFailure(t *testing.T, originalWd string, originalUs
 		return cmd.Execute()
 	})
 
-	if err2 != nil {
-		t.Errorf("Second init failed: %v", err2)
-	}
-
-	if !strings.Contains(output2, "already been initialized") {
-		t.Errorf("Expected already initialized message, got: %s", output2)
+	if !errors.Is(err2, kerrors.ErrProjectAlreadyInitialized) {
+		t.Errorf("Expected ErrProjectAlreadyInitialized, got: %v\nOutput: %s", err2, output2)
 	}
+	assertAlreadyInitialized(t)
 
 	// Project structure should still be intact
 	shared.VerifyProjectStructure(t, tempDir)
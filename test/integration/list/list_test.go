@@ -0,0 +1,231 @@
+package list
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupTestProject creates a minimal kanuka project structure for testing.
+func setupTestProject(t *testing.T, tempDir string) {
+	kanukaDir := filepath.Join(tempDir, ".kanuka")
+	publicKeysDir := filepath.Join(kanukaDir, "public_keys")
+	secretsDir := filepath.Join(kanukaDir, "secrets")
+
+	if err := os.MkdirAll(publicKeysDir, 0755); err != nil {
+		t.Fatalf("Failed to create public keys directory: %v", err)
+	}
+	if err := os.MkdirAll(secretsDir, 0755); err != nil {
+		t.Fatalf("Failed to create secrets directory: %v", err)
+	}
+
+	projectConfig := &configs.ProjectConfig{
+		Project: configs.Project{
+			UUID: shared.TestProjectUUID,
+			Name: "test-project",
+		},
+		Users:   make(map[string]string),
+		Devices: make(map[string]configs.DeviceConfig),
+	}
+
+	configs.ProjectKanukaSettings = &configs.ProjectSettings{
+		ProjectName:          "test-project",
+		ProjectPath:          tempDir,
+		ProjectPublicKeyPath: publicKeysDir,
+		ProjectSecretsPath:   secretsDir,
+	}
+
+	if err := configs.SaveProjectConfig(projectConfig); err != nil {
+		t.Fatalf("Failed to save project config: %v", err)
+	}
+}
+
+// addDevice adds a user device to the project config only. No key files are
+// created, since list only reads the config.
+func addDevice(t *testing.T, uuid, email, deviceName string, createdAt time.Time) {
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	projectConfig.Users[uuid] = email
+	projectConfig.Devices[uuid] = configs.DeviceConfig{
+		Email:     email,
+		Name:      deviceName,
+		CreatedAt: createdAt,
+	}
+	if err := configs.SaveProjectConfig(projectConfig); err != nil {
+		t.Fatalf("Failed to save project config: %v", err)
+	}
+}
+
+// setupTestDirs creates temp project and user directories and points the
+// test environment at them.
+func setupTestDirs(t *testing.T) string {
+	tempDir, err := os.MkdirTemp("", "kanuka-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tempDir) })
+
+	tempUserDir, err := os.MkdirTemp("", "kanuka-user-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp user directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tempUserDir) })
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	return tempDir
+}
+
+func TestList_GroupsDevicesByEmail(t *testing.T) {
+	tempDir := setupTestDirs(t)
+	setupTestProject(t, tempDir)
+
+	created := time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC)
+	addDevice(t, "uuid-bob-1", "bob@example.com", "workstation", created)
+	addDevice(t, "uuid-alice-2", "alice@example.com", "laptop", created)
+	addDevice(t, "uuid-alice-1", "alice@example.com", "desktop", created)
+
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLI("list", nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("List command failed: %v", err)
+	}
+
+	for _, want := range []string{"test-project", "alice@example.com", "bob@example.com", "desktop", "laptop", "workstation", "2025-03-14", "2 user(s), 3 device(s)"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Output should contain %q, got: %s", want, output)
+		}
+	}
+
+	if strings.Count(output, "alice@example.com") != 1 {
+		t.Errorf("alice@example.com should appear once with both devices grouped under it, got: %s", output)
+	}
+	if strings.Index(output, "desktop") > strings.Index(output, "laptop") {
+		t.Errorf("Devices should be sorted by name, got: %s", output)
+	}
+	if strings.Index(output, "alice@example.com") > strings.Index(output, "bob@example.com") {
+		t.Errorf("Users should be sorted by email, got: %s", output)
+	}
+}
+
+func TestList_JSONOutput(t *testing.T) {
+	tempDir := setupTestDirs(t)
+	setupTestProject(t, tempDir)
+
+	created := time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC)
+	addDevice(t, "uuid-alice-2", "alice@example.com", "laptop", created)
+	addDevice(t, "uuid-alice-1", "alice@example.com", "desktop", created)
+
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLIWithArgs("list", []string{"--json"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("List command failed: %v", err)
+	}
+
+	var result struct {
+		Project string `json:"project"`
+		Users   []struct {
+			Email   string `json:"email"`
+			Devices []struct {
+				UUID      string    `json:"uuid"`
+				Name      string    `json:"name"`
+				CreatedAt time.Time `json:"created_at"`
+			} `json:"devices"`
+		} `json:"users"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\nOutput: %s", err, output)
+	}
+
+	if result.Project != "test-project" {
+		t.Errorf("Expected project 'test-project', got %q", result.Project)
+	}
+	if len(result.Users) != 1 {
+		t.Fatalf("Expected 1 user, got %d", len(result.Users))
+	}
+	devices := result.Users[0].Devices
+	if len(devices) != 2 {
+		t.Fatalf("Expected 2 devices, got %d", len(devices))
+	}
+	if devices[0].Name != "desktop" || devices[0].UUID != "uuid-alice-1" {
+		t.Errorf("Expected first device to be desktop (uuid-alice-1), got %s (%s)", devices[0].Name, devices[0].UUID)
+	}
+	if !devices[0].CreatedAt.Equal(created) {
+		t.Errorf("Expected created_at %v, got %v", created, devices[0].CreatedAt)
+	}
+}
+
+func TestList_NotInitialized(t *testing.T) {
+	setupTestDirs(t)
+
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLI("list", nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Fatalf("Expected ErrProjectNotInitialized, got: %v", err)
+	}
+
+	if !strings.Contains(output, "has not been initialized") {
+		t.Errorf("Output should indicate project not initialized, got: %s", output)
+	}
+	if !strings.Contains(output, "kanuka secrets init") {
+		t.Errorf("Output should suggest running 'kanuka secrets init', got: %s", output)
+	}
+}
+
+func TestList_ShowsPublicKeyFingerprints(t *testing.T) {
+	tempDir := setupTestDirs(t)
+	setupTestProject(t, tempDir)
+
+	created := time.Date(2025, 3, 14, 9, 30, 0, 0, time.UTC)
+	addDevice(t, "uuid-alice-1", "alice@example.com", "desktop", created)
+	addDevice(t, "uuid-bob-1", "bob@example.com", "workstation", created)
+
+	publicKeyPath := filepath.Join(tempDir, ".kanuka", "public_keys", "uuid-alice-1.pub")
+	if err := shared.GenerateRSAKeyPair(filepath.Join(t.TempDir(), "alice"), publicKeyPath); err != nil {
+		t.Fatalf("Failed to generate key pair: %v", err)
+	}
+	publicKey, err := secrets.LoadPublicKey(publicKeyPath)
+	if err != nil {
+		t.Fatalf("Failed to load public key: %v", err)
+	}
+	fingerprint, err := secrets.PublicKeyFingerprint(publicKey)
+	if err != nil {
+		t.Fatalf("Failed to fingerprint public key: %v", err)
+	}
+
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLI("list", nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("List command failed: %v", err)
+	}
+
+	if !strings.Contains(output, "FINGERPRINT") {
+		t.Errorf("Output should have a FINGERPRINT column, got: %s", output)
+	}
+	if strings.Count(output, fingerprint) != 1 {
+		t.Errorf("Output should show alice's fingerprint %s once, got: %s", fingerprint, output)
+	}
+}
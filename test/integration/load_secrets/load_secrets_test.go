@@ -0,0 +1,168 @@
+package loadsecrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupLoadSecretsProject initializes a project, writes the given .env files,
+// encrypts them and removes the plaintext. Returns the project and user directories.
+func setupLoadSecretsProject(t *testing.T, files map[string]string) (string, string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	for rel, content := range files {
+		path := filepath.Join(tempDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Failed to create %s: %v", rel, err)
+		}
+	}
+
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("encrypt", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v\nOutput: %s", err, output)
+	}
+
+	for rel := range files {
+		if err := os.Remove(filepath.Join(tempDir, rel)); err != nil {
+			t.Fatalf("Failed to remove %s: %v", rel, err)
+		}
+	}
+
+	return tempDir, tempUserDir
+}
+
+func TestLoadSecrets_ReturnsPerFileValues(t *testing.T) {
+	projectDir, _ := setupLoadSecretsProject(t, map[string]string{
+		".env":              "API_KEY=abc123\n# comment\nDATABASE_URL=\"postgres://localhost/db\"\n",
+		"services/api/.env": "export PORT=8080\nDEBUG='true'\n",
+	})
+
+	result, err := workflows.LoadSecrets(context.Background(), workflows.LoadSecretsOptions{})
+	if err != nil {
+		t.Fatalf("LoadSecrets failed: %v", err)
+	}
+
+	want := map[string]map[string]string{
+		".env":              {"API_KEY": "abc123", "DATABASE_URL": "postgres://localhost/db"},
+		"services/api/.env": {"PORT": "8080", "DEBUG": "true"},
+	}
+	if !reflect.DeepEqual(result.Secrets, want) {
+		t.Errorf("Secrets = %#v, want %#v", result.Secrets, want)
+	}
+	if result.ProjectPath != projectDir {
+		t.Errorf("ProjectPath = %q, want %q", result.ProjectPath, projectDir)
+	}
+}
+
+func TestLoadSecrets_WritesNothingToDisk(t *testing.T) {
+	projectDir, _ := setupLoadSecretsProject(t, map[string]string{".env": "KEY=value\n"})
+
+	auditPath := filepath.Join(projectDir, ".kanuka", "audit.jsonl")
+	auditBefore, _ := os.ReadFile(auditPath)
+
+	if _, err := workflows.LoadSecrets(context.Background(), workflows.LoadSecretsOptions{}); err != nil {
+		t.Fatalf("LoadSecrets failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(projectDir, ".env")); !os.IsNotExist(err) {
+		t.Error("LoadSecrets should not write plaintext .env files")
+	}
+	auditAfter, _ := os.ReadFile(auditPath)
+	if string(auditBefore) != string(auditAfter) {
+		t.Error("LoadSecrets should not write audit entries")
+	}
+}
+
+func TestLoadSecrets_FilePatterns(t *testing.T) {
+	setupLoadSecretsProject(t, map[string]string{
+		".env":       "A=1\n",
+		".env.local": "B=2\n",
+	})
+
+	result, err := workflows.LoadSecrets(context.Background(), workflows.LoadSecretsOptions{
+		FilePatterns: []string{".env.local.kanuka"},
+	})
+	if err != nil {
+		t.Fatalf("LoadSecrets failed: %v", err)
+	}
+
+	want := map[string]map[string]string{".env.local": {"B": "2"}}
+	if !reflect.DeepEqual(result.Secrets, want) {
+		t.Errorf("Secrets = %#v, want %#v", result.Secrets, want)
+	}
+}
+
+func TestLoadSecrets_WithPrivateKeyData(t *testing.T) {
+	_, userDir := setupLoadSecretsProject(t, map[string]string{".env": "KEY=value\n"})
+
+	projectUUID := shared.GetProjectUUID(t)
+	keyPath := shared.GetPrivateKeyPath(filepath.Join(userDir, "keys"), projectUUID)
+	keyData, err := os.ReadFile(keyPath)
+	if err != nil {
+		t.Fatalf("Failed to read private key: %v", err)
+	}
+	if err := os.Remove(keyPath); err != nil {
+		t.Fatalf("Failed to remove private key: %v", err)
+	}
+
+	result, err := workflows.LoadSecrets(context.Background(), workflows.LoadSecretsOptions{PrivateKeyData: keyData})
+	if err != nil {
+		t.Fatalf("LoadSecrets failed: %v", err)
+	}
+	if result.Secrets[".env"]["KEY"] != "value" {
+		t.Errorf("Expected KEY=value, got %#v", result.Secrets)
+	}
+}
+
+func TestLoadSecrets_CorruptedFile(t *testing.T) {
+	projectDir, _ := setupLoadSecretsProject(t, map[string]string{".env": "KEY=value\n"})
+
+	if err := os.WriteFile(filepath.Join(projectDir, ".env.kanuka"), []byte("not encrypted data at all, definitely"), 0600); err != nil {
+		t.Fatalf("Failed to corrupt file: %v", err)
+	}
+
+	_, err := workflows.LoadSecrets(context.Background(), workflows.LoadSecretsOptions{})
+	if !errors.Is(err, kerrors.ErrDecryptFailed) {
+		t.Errorf("Expected ErrDecryptFailed, got %v", err)
+	}
+}
+
+func TestLoadSecrets_NotInitialized(t *testing.T) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+
+	_, err = workflows.LoadSecrets(context.Background(), workflows.LoadSecretsOptions{})
+	if !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Errorf("Expected ErrProjectNotInitialized, got %v", err)
+	}
+}
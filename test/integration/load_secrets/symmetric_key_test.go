@@ -0,0 +1,79 @@
+package loadsecrets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// TestLoadSymmetricKey_DecryptsProjectFiles tests that the key from
+// LoadSymmetricKey decrypts the project's .kanuka files with DecryptBytes,
+// and that EncryptBytes output is readable by the CLI's decrypt.
+func TestLoadSymmetricKey_DecryptsProjectFiles(t *testing.T) {
+	projectDir, userDir := setupLoadSecretsProject(t, map[string]string{
+		".env": "API_KEY=abc123\n",
+	})
+
+	privateKey, err := secrets.LoadPrivateKey(shared.GetPrivateKeyPath(filepath.Join(userDir, "keys"), shared.GetProjectUUID(t)))
+	if err != nil {
+		t.Fatalf("Failed to load private key: %v", err)
+	}
+
+	symKey, err := secrets.LoadSymmetricKey(privateKey)
+	if err != nil {
+		t.Fatalf("LoadSymmetricKey failed: %v", err)
+	}
+
+	ciphertext, err := os.ReadFile(filepath.Join(projectDir, ".env.kanuka"))
+	if err != nil {
+		t.Fatalf("Failed to read .env.kanuka: %v", err)
+	}
+	plaintext, err := secrets.DecryptBytes(symKey, ciphertext)
+	if err != nil {
+		t.Fatalf("DecryptBytes failed: %v", err)
+	}
+	if string(plaintext) != "API_KEY=abc123\n" {
+		t.Errorf("Expected %q, got %q", "API_KEY=abc123\n", string(plaintext))
+	}
+
+	encrypted, err := secrets.EncryptBytes(symKey, []byte("TOKEN=xyz\n"))
+	if err != nil {
+		t.Fatalf("EncryptBytes failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, ".env.local.kanuka"), encrypted, 0600); err != nil {
+		t.Fatalf("Failed to write .env.local.kanuka: %v", err)
+	}
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs("decrypt", []string{".env.local.kanuka"}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v\nOutput: %s", err, output)
+	}
+	decrypted, err := os.ReadFile(filepath.Join(projectDir, ".env.local"))
+	if err != nil || string(decrypted) != "TOKEN=xyz\n" {
+		t.Errorf("Expected decrypt to read EncryptBytes output, got %q: %v\nOutput: %s", decrypted, err, output)
+	}
+}
+
+// TestLoadSymmetricKey_WrongPrivateKey tests that another key pair can't
+// unwrap the project's symmetric key.
+func TestLoadSymmetricKey_WrongPrivateKey(t *testing.T) {
+	setupLoadSecretsProject(t, map[string]string{".env": "API_KEY=abc123\n"})
+
+	otherDir := t.TempDir()
+	privatePath := filepath.Join(otherDir, "privkey")
+	if err := secrets.GenerateKeyPair(privatePath, privatePath+".pub", secrets.DefaultKeyAlgorithm); err != nil {
+		t.Fatalf("Failed to generate key pair: %v", err)
+	}
+	otherKey, err := secrets.LoadPrivateKey(privatePath)
+	if err != nil {
+		t.Fatalf("Failed to load private key: %v", err)
+	}
+
+	if _, err := secrets.LoadSymmetricKey(otherKey); err == nil {
+		t.Error("Expected LoadSymmetricKey to fail with the wrong private key")
+	}
+}
@@ -0,0 +1,316 @@
+package merge_config_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// TestSecretsMergeConfigIntegration contains integration tests for the `kanuka secrets merge-config` command.
+func TestSecretsMergeConfigIntegration(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get original working directory: %v", err)
+	}
+
+	originalUserSettings := configs.UserKanukaSettings
+
+	t.Run("MergeConfigUnionsRegistrations", func(t *testing.T) {
+		testMergeConfigUnionsRegistrations(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("MergeConfigReportsConflicts", func(t *testing.T) {
+		testMergeConfigReportsConflicts(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("MergeConfigMigratesOlderSchema", func(t *testing.T) {
+		testMergeConfigMigratesOlderSchema(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("MergeConfigRejectsNewerSchema", func(t *testing.T) {
+		testMergeConfigRejectsNewerSchema(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("MergeConfigIntoProjectTakesLock", func(t *testing.T) {
+		testMergeConfigIntoProjectTakesLock(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("MergeConfigInstallDriver", func(t *testing.T) {
+		testMergeConfigInstallDriver(t, originalWd, originalUserSettings)
+	})
+}
+
+// Tests that merging into a project's own config.toml waits for the project
+// lock, and that the lock file is kept out of git.
+func testMergeConfigIntoProjectTakesLock(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+
+	projectConfig := filepath.Join(tempDir, ".kanuka", "config.toml")
+	theirs := filepath.Join(tempDir, "theirs.toml")
+	writeConfig(t, projectConfig, map[string]string{"u1": "alice@example.com"})
+	writeConfig(t, theirs, map[string]string{"u1": "alice@example.com", "u2": "bob@example.com"})
+
+	originalTimeout := configs.ProjectLockTimeout
+	configs.ProjectLockTimeout = 100 * time.Millisecond
+	defer func() { configs.ProjectLockTimeout = originalTimeout }()
+
+	runMerge := func() (string, int, error) {
+		exitCode := -1
+		output, err := shared.CaptureOutput(func() error {
+			testCmd := shared.CreateTestCLIWithArgs("merge-config",
+				[]string{"--ours", projectConfig, "--theirs", theirs}, nil, nil, false, false)
+			cmd.SetMergeConfigExitFunc(func(code int) { exitCode = code })
+			return testCmd.Execute()
+		})
+		return output, exitCode, err
+	}
+
+	lock, err := configs.LockProjectAt(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to take the project lock: %v", err)
+	}
+	output, exitCode, err := runMerge()
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	if exitCode != 2 || !strings.Contains(output, "in progress") {
+		t.Errorf("Expected the merge to fail while the lock is held, got exit code %d: %s", exitCode, output)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Failed to release the project lock: %v", err)
+	}
+
+	output, exitCode, err = runMerge()
+	if err != nil || exitCode != -1 {
+		t.Fatalf("Expected a clean merge, got exit code %d (%v): %s", exitCode, err, output)
+	}
+	gitignore, err := os.ReadFile(filepath.Join(tempDir, ".kanuka", ".gitignore"))
+	if err != nil || !strings.Contains(string(gitignore), configs.ProjectLockFileName) {
+		t.Errorf("Expected .kanuka/.gitignore to list the lock file, got %q (%v)", gitignore, err)
+	}
+}
+
+// writeConfig saves a project config with the given users to path.
+func writeConfig(t *testing.T, path string, users map[string]string) {
+	t.Helper()
+	pc := &configs.ProjectConfig{
+		Project: configs.Project{UUID: shared.TestProjectUUID, Name: "test-project"},
+		Users:   make(map[string]string),
+		Devices: make(map[string]configs.DeviceConfig),
+	}
+	for uuid, email := range users {
+		pc.Users[uuid] = email
+		pc.Devices[uuid] = configs.DeviceConfig{Email: email, Name: "laptop"}
+	}
+	if err := configs.SaveTOML(path, pc); err != nil {
+		t.Fatalf("Failed to write config %s: %v", path, err)
+	}
+}
+
+func testMergeConfigUnionsRegistrations(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+
+	base := filepath.Join(tempDir, "base.toml")
+	ours := filepath.Join(tempDir, "ours.toml")
+	theirs := filepath.Join(tempDir, "theirs.toml")
+	writeConfig(t, base, map[string]string{"u1": "alice@example.com", "u2": "bob@example.com"})
+	writeConfig(t, ours, map[string]string{"u1": "alice@example.com", "u2": "bob@example.com", "u3": "carol@example.com"})
+	writeConfig(t, theirs, map[string]string{"u1": "alice@example.com", "u4": "dave@example.com"})
+
+	exitCode := -1
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLIWithArgs("merge-config",
+			[]string{"--base", base, "--ours", ours, "--theirs", theirs}, nil, nil, false, false)
+		cmd.SetMergeConfigExitFunc(func(code int) { exitCode = code })
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	if exitCode != -1 {
+		t.Fatalf("Expected clean merge, got exit code %d: %s", exitCode, output)
+	}
+	if !strings.Contains(output, "Merged config written") {
+		t.Errorf("Expected success message, got: %s", output)
+	}
+
+	merged := &configs.ProjectConfig{}
+	if err := configs.LoadTOML(ours, merged); err != nil {
+		t.Fatalf("Failed to load merged config: %v", err)
+	}
+	for _, uuid := range []string{"u1", "u3", "u4"} {
+		if _, ok := merged.Users[uuid]; !ok {
+			t.Errorf("Expected user %s in merged config", uuid)
+		}
+	}
+	if _, ok := merged.Users["u2"]; ok {
+		t.Errorf("Expected user u2 revoked on their side to stay removed")
+	}
+}
+
+func testMergeConfigReportsConflicts(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+
+	ours := filepath.Join(tempDir, "ours.toml")
+	theirs := filepath.Join(tempDir, "theirs.toml")
+	output := filepath.Join(tempDir, "merged.toml")
+	writeConfig(t, ours, map[string]string{"u1": "alice@example.com"})
+	writeConfig(t, theirs, map[string]string{"u1": "mallory@example.com"})
+
+	exitCode := -1
+	out, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLIWithArgs("merge-config",
+			[]string{"--ours", ours, "--theirs", theirs, "--output", output}, nil, nil, false, false)
+		cmd.SetMergeConfigExitFunc(func(code int) { exitCode = code })
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+
+	if exitCode != 1 {
+		t.Errorf("Expected exit code 1 on conflict, got %d", exitCode)
+	}
+	if !strings.Contains(out, "users.u1") {
+		t.Errorf("Expected conflict to be listed, got: %s", out)
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("Expected merged config to be written to --output: %v", err)
+	}
+}
+
+func testMergeConfigMigratesOlderSchema(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+
+	// A config from before devices existed, with no config_version.
+	ours := filepath.Join(tempDir, "ours.toml")
+	legacy := "[project]\nproject_uuid = \"" + shared.TestProjectUUID + "\"\nname = \"test-project\"\n\n[users]\nu1 = \"alice@example.com\"\n"
+	if err := os.WriteFile(ours, []byte(legacy), 0600); err != nil {
+		t.Fatalf("Failed to write config %s: %v", ours, err)
+	}
+	theirs := filepath.Join(tempDir, "theirs.toml")
+	writeConfig(t, theirs, map[string]string{"u2": "bob@example.com"})
+
+	exitCode := -1
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLIWithArgs("merge-config", []string{"--ours", ours, "--theirs", theirs}, nil, nil, false, false)
+		cmd.SetMergeConfigExitFunc(func(code int) { exitCode = code })
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	if exitCode != -1 {
+		t.Fatalf("Expected clean merge, got exit code %d: %s", exitCode, output)
+	}
+
+	merged := &configs.ProjectConfig{}
+	if err := configs.LoadTOML(ours, merged); err != nil {
+		t.Fatalf("Failed to load merged config: %v", err)
+	}
+	if merged.ConfigVersion != configs.CurrentConfigVersion {
+		t.Errorf("Expected config_version %d, got %d", configs.CurrentConfigVersion, merged.ConfigVersion)
+	}
+	if device, ok := merged.Devices["u1"]; !ok || device.Name != configs.MigratedDeviceName {
+		t.Errorf("Expected u1 to get a migrated device, got %+v", merged.Devices)
+	}
+	if _, ok := merged.Users["u2"]; !ok {
+		t.Errorf("Expected user u2 in merged config")
+	}
+}
+
+func testMergeConfigRejectsNewerSchema(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+
+	ours := filepath.Join(tempDir, "ours.toml")
+	writeConfig(t, ours, map[string]string{"u1": "alice@example.com"})
+	before, err := os.ReadFile(ours)
+	if err != nil {
+		t.Fatalf("Failed to read config %s: %v", ours, err)
+	}
+
+	theirs := filepath.Join(tempDir, "theirs.toml")
+	newer := fmt.Sprintf("config_version = %d\n\n[project]\nproject_uuid = %q\nname = \"test-project\"\n", configs.CurrentConfigVersion+1, shared.TestProjectUUID)
+	if err := os.WriteFile(theirs, []byte(newer), 0600); err != nil {
+		t.Fatalf("Failed to write config %s: %v", theirs, err)
+	}
+
+	exitCode := -1
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLIWithArgs("merge-config", []string{"--ours", ours, "--theirs", theirs}, nil, nil, false, false)
+		cmd.SetMergeConfigExitFunc(func(code int) { exitCode = code })
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	if exitCode != 2 {
+		t.Errorf("Expected exit code 2 for a newer config, got %d", exitCode)
+	}
+	if !strings.Contains(output, "newer version") {
+		t.Errorf("Expected a newer version error, got: %s", output)
+	}
+
+	after, err := os.ReadFile(ours)
+	if err != nil {
+		t.Fatalf("Failed to read config %s: %v", ours, err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("Expected our config to be left untouched, got:\n%s", after)
+	}
+}
+
+func testMergeConfigInstallDriver(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	if out, err := exec.Command("git", "-C", tempDir, "init", "-q").CombinedOutput(); err != nil {
+		t.Skipf("git not available: %v: %s", err, out)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := shared.CaptureOutput(func() error {
+			testCmd := shared.CreateTestCLIWithArgs("merge-config", []string{"--install"}, nil, nil, false, false)
+			return testCmd.Execute()
+		})
+		if err != nil {
+			t.Fatalf("Command failed: %v", err)
+		}
+	}
+
+	driver, err := exec.Command("git", "-C", tempDir, "config", "merge.kanuka-config.driver").Output()
+	if err != nil {
+		t.Fatalf("Expected merge driver in git config: %v", err)
+	}
+	if !strings.Contains(string(driver), "merge-config --base %O --ours %A --theirs %B") {
+		t.Errorf("Unexpected merge driver command: %s", driver)
+	}
+
+	attrs, err := os.ReadFile(filepath.Join(tempDir, ".gitattributes"))
+	if err != nil {
+		t.Fatalf("Expected .gitattributes to be created: %v", err)
+	}
+	if strings.Count(string(attrs), ".kanuka/config.toml merge=kanuka-config") != 1 {
+		t.Errorf("Expected exactly one merge attribute line, got: %s", attrs)
+	}
+}
@@ -0,0 +1,52 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// runWithColor runs a secrets subcommand with --color set to mode.
+func runWithColor(t *testing.T, mode, subcommand string, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs(subcommand, append(args, "--color", mode), nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("%s --color %s failed: %v\nOutput: %s", subcommand, mode, err, output)
+	}
+	return output
+}
+
+func TestColor_NeverUsesDecorations(t *testing.T) {
+	setupProject(t)
+
+	output := runWithColor(t, "never", "whoami")
+	if strings.Contains(output, "\x1b[") {
+		t.Errorf("Expected no ANSI escape codes with --color never, got: %q", output)
+	}
+	if !strings.Contains(output, "'"+shared.TestUserEmail+"'") {
+		t.Errorf("Expected highlighted values to fall back to quotes, got: %q", output)
+	}
+}
+
+func TestColor_AlwaysColorsPipedOutput(t *testing.T) {
+	setupProject(t)
+
+	output := runWithColor(t, "always", "whoami")
+	if !strings.Contains(output, "\x1b[") {
+		t.Errorf("Expected ANSI escape codes with --color always, got: %q", output)
+	}
+}
+
+func TestColor_InvalidMode(t *testing.T) {
+	setupProject(t)
+
+	_, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs("whoami", []string{"--color", "sometimes"}, nil, nil, false, false).Execute()
+	})
+	if err == nil || !strings.Contains(err.Error(), "sometimes") {
+		t.Errorf("Expected an error naming the invalid mode, got: %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// captureStreams runs fn and returns what it wrote to stdout and stderr separately.
+func captureStreams(t *testing.T, fn func() error) (string, string, error) {
+	t.Helper()
+	originalStdout, originalStderr := os.Stdout, os.Stderr
+
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create stdout pipe: %v", err)
+	}
+	stderrReader, stderrWriter, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create stderr pipe: %v", err)
+	}
+	os.Stdout, os.Stderr = stdoutWriter, stderrWriter
+
+	read := func(r io.Reader, out chan<- string) {
+		var buf bytes.Buffer
+		_, _ = io.Copy(&buf, r)
+		out <- buf.String()
+	}
+	stdoutChan, stderrChan := make(chan string, 1), make(chan string, 1)
+	go read(stdoutReader, stdoutChan)
+	go read(stderrReader, stderrChan)
+
+	fnErr := fn()
+
+	stdoutWriter.Close()
+	stderrWriter.Close()
+	os.Stdout, os.Stderr = originalStdout, originalStderr
+
+	return <-stdoutChan, <-stderrChan, fnErr
+}
+
+// runJSON runs a secrets subcommand with --output json.
+func runJSON(t *testing.T, subcommand string, args ...string) (string, string, error) {
+	t.Helper()
+	return captureStreams(t, func() error {
+		return shared.CreateTestCLIWithArgs(subcommand, append(args, "--output", "json"), nil, nil, false, false).Execute()
+	})
+}
+
+// decodeJSON parses output as a single JSON object, failing if anything else was printed.
+func decodeJSON(t *testing.T, output string) map[string]any {
+	t.Helper()
+	var result map[string]any
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Output is not a single JSON object: %v\nOutput: %q", err, output)
+	}
+	return result
+}
+
+// setupProject creates an initialized project and returns its directory.
+func setupProject(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+	return tempDir
+}
+
+func TestOutputJSON_Init(t *testing.T) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+
+	// No --yes: --output json must never prompt for the project name.
+	stdout, stderr, err := runJSON(t, "init")
+	if err != nil {
+		t.Fatalf("init failed: %v\nstderr: %s", err, stderr)
+	}
+	if stderr != "" {
+		t.Errorf("Expected no stderr output, got: %q", stderr)
+	}
+
+	result := decodeJSON(t, stdout)
+	if result["project_name"] != filepath.Base(tempDir) {
+		t.Errorf("Expected project_name %q, got %v", filepath.Base(tempDir), result["project_name"])
+	}
+	if uuid, _ := result["project_uuid"].(string); uuid == "" {
+		t.Errorf("Expected a project_uuid, got %v", result["project_uuid"])
+	}
+}
+
+func TestOutputJSON_EncryptAndDecrypt(t *testing.T) {
+	tempDir := setupProject(t)
+	envPath := filepath.Join(tempDir, ".env")
+	if err := os.WriteFile(envPath, []byte("KEY=value\n"), 0600); err != nil {
+		t.Fatalf("Failed to write .env: %v", err)
+	}
+
+	stdout, stderr, err := runJSON(t, "encrypt")
+	if err != nil {
+		t.Fatalf("encrypt failed: %v\nstderr: %s", err, stderr)
+	}
+	result := decodeJSON(t, stdout)
+	files, _ := result["encrypted_files"].([]any)
+	if len(files) != 1 || !strings.HasSuffix(files[0].(string), ".env.kanuka") {
+		t.Errorf("Expected encrypted_files to list .env.kanuka, got %v", result["encrypted_files"])
+	}
+	if result["dry_run"] != false {
+		t.Errorf("Expected dry_run false, got %v", result["dry_run"])
+	}
+
+	if err := os.Remove(envPath); err != nil {
+		t.Fatalf("Failed to remove .env: %v", err)
+	}
+
+	stdout, stderr, err = runJSON(t, "decrypt")
+	if err != nil {
+		t.Fatalf("decrypt failed: %v\nstderr: %s", err, stderr)
+	}
+	if stderr != "" {
+		t.Errorf("Expected no stderr output, got: %q", stderr)
+	}
+	result = decodeJSON(t, stdout)
+	files, _ = result["decrypted_files"].([]any)
+	if len(files) != 1 || files[0] != envPath {
+		t.Errorf("Expected decrypted_files [%s], got %v", envPath, result["decrypted_files"])
+	}
+}
+
+func TestOutputJSON_Register(t *testing.T) {
+	tempDir := setupProject(t)
+
+	privateKeyPath := filepath.Join(tempDir, "other_key")
+	publicKeyPath := privateKeyPath + ".pub"
+	if err := secrets.GenerateRSAKeyPair(privateKeyPath, publicKeyPath); err != nil {
+		t.Fatalf("Failed to generate key pair: %v", err)
+	}
+	pubKey, err := os.ReadFile(publicKeyPath)
+	if err != nil {
+		t.Fatalf("Failed to read public key: %v", err)
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	projectConfig.Users[shared.TestUser2UUID] = shared.TestUser2Email
+	if err := configs.SaveProjectConfig(projectConfig); err != nil {
+		t.Fatalf("Failed to save project config: %v", err)
+	}
+
+	stdout, stderr, err := runJSON(t, "register", "--user", shared.TestUser2Email, "--pubkey", string(pubKey))
+	if err != nil {
+		t.Fatalf("register failed: %v\nstderr: %s", err, stderr)
+	}
+	result := decodeJSON(t, stdout)
+	if result["display_name"] != shared.TestUser2Email {
+		t.Errorf("Expected display_name %s, got %v", shared.TestUser2Email, result["display_name"])
+	}
+	if result["target_user_uuid"] != shared.TestUser2UUID {
+		t.Errorf("Expected target_user_uuid %s, got %v", shared.TestUser2UUID, result["target_user_uuid"])
+	}
+	if result["mode"] != "pubkey_text" {
+		t.Errorf("Expected mode pubkey_text, got %v", result["mode"])
+	}
+}
+
+func TestOutputJSON_ErrorsGoToStderr(t *testing.T) {
+	setupProject(t)
+
+	tests := []struct {
+		name       string
+		subcommand string
+		args       []string
+		code       string
+	}{
+		{"no files", "encrypt", nil, "no_files_found"},
+		{"missing flags", "revoke", nil, "invalid_arguments"},
+		{"invalid email", "register", []string{"--user", "not-an-email"}, "invalid_email"},
+		{"unknown user", "revoke", []string{"--user", "nobody@example.com"}, "user_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, _ := runJSON(t, tt.subcommand, tt.args...)
+			if stdout != "" {
+				t.Errorf("Expected no stdout output, got: %q", stdout)
+			}
+			result := decodeJSON(t, stderr)
+			if result["code"] != tt.code {
+				t.Errorf("Expected code %q, got %v", tt.code, result["code"])
+			}
+			if msg, _ := result["error"].(string); msg == "" {
+				t.Errorf("Expected an error message, got %v", result["error"])
+			}
+		})
+	}
+}
+
+func TestOutputJSON_InvalidFormat(t *testing.T) {
+	setupProject(t)
+
+	_, _, err := captureStreams(t, func() error {
+		return shared.CreateTestCLIWithArgs("encrypt", []string{"--output", "yaml"}, nil, nil, false, false).Execute()
+	})
+	if err == nil || !strings.Contains(err.Error(), "--output") {
+		t.Errorf("Expected an --output validation error, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// runPiped runs a secrets subcommand with stdout and stderr piped, as in a CI
+// log, and fails if either holds a carriage return.
+func runPiped(t *testing.T, verbose bool, subcommand string, args ...string) (string, string) {
+	t.Helper()
+	stdout, stderr, err := captureStreams(t, func() error {
+		return shared.CreateTestCLIWithArgs(subcommand, args, nil, nil, verbose, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("%s failed: %v\nstdout: %s\nstderr: %s", subcommand, err, stdout, stderr)
+	}
+	if strings.Contains(stdout, "\r") || strings.Contains(stderr, "\r") {
+		t.Errorf("Expected no carriage returns in piped output, got stdout %q, stderr %q", stdout, stderr)
+	}
+	return stdout, stderr
+}
+
+func TestNonTTY_SpinnerPrintsPlainLines(t *testing.T) {
+	tempDir := setupProject(t)
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("KEY=value\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+
+	stdout, stderr := runPiped(t, false, "encrypt")
+	if strings.Count(stderr, "Encrypting environment files...") != 1 {
+		t.Errorf("Expected one static progress line on stderr, got: %q", stderr)
+	}
+	if !strings.Contains(stdout, "✓") {
+		t.Errorf("Expected the final message on stdout, got: %q", stdout)
+	}
+}
+
+func TestNonTTY_VerboseShowsLogLines(t *testing.T) {
+	tempDir := setupProject(t)
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("KEY=value\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+
+	stdout, stderr := runPiped(t, true, "encrypt")
+	if !strings.Contains(stdout, "[info]") {
+		t.Errorf("Expected verbose log lines, got: %q", stdout)
+	}
+	if strings.Contains(stderr, "Encrypting environment files...") {
+		t.Errorf("Expected no progress line under --verbose, got: %q", stderr)
+	}
+}
+
+func TestNonTTY_ReportHasNoProgressLine(t *testing.T) {
+	setupProject(t)
+
+	_, stderr := runPiped(t, false, "status")
+	if strings.Contains(stderr, "Checking file statuses...") {
+		t.Errorf("Expected no progress line for a report command, got: %q", stderr)
+	}
+}
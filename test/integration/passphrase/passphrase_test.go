@@ -0,0 +1,184 @@
+package passphrase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupPassphraseProject initializes a project and returns the path to the user's private key.
+func setupPassphraseProject(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	return shared.GetPrivateKeyPath(filepath.Join(tempUserDir, "keys"), shared.GetProjectUUID(t))
+}
+
+// unwrapSymmetricKey decrypts the current user's symmetric key with the given private key.
+func unwrapSymmetricKey(t *testing.T, privateKey secrets.PrivateKey) []byte {
+	t.Helper()
+	wrapped, err := secrets.GetProjectKanukaKey(shared.GetUserUUID(t))
+	if err != nil {
+		t.Fatalf("Failed to read wrapped key: %v", err)
+	}
+	symKey, err := secrets.DecryptWithPrivateKey(wrapped, privateKey)
+	if err != nil {
+		t.Fatalf("Failed to unwrap symmetric key: %v", err)
+	}
+	return symKey
+}
+
+func TestChangePassphrase_AddChangeRemove(t *testing.T) {
+	keyPath := setupPassphraseProject(t)
+	ctx := context.Background()
+
+	original, err := secrets.LoadPrivateKey(keyPath)
+	if err != nil {
+		t.Fatalf("Failed to load private key: %v", err)
+	}
+	symKey := unwrapSymmetricKey(t, original)
+
+	// Add a passphrase.
+	if _, err := workflows.ChangePassphrase(ctx, workflows.ChangePassphraseOptions{NewPassphrase: []byte("first")}); err != nil {
+		t.Fatalf("Adding passphrase failed: %v", err)
+	}
+	status, err := workflows.PassphraseStatus(ctx)
+	if err != nil {
+		t.Fatalf("PassphraseStatus failed: %v", err)
+	}
+	if !status.Encrypted {
+		t.Fatal("Expected key to be passphrase-protected")
+	}
+
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		t.Fatalf("Failed to read key: %v", err)
+	}
+	if !strings.Contains(string(data), "OPENSSH PRIVATE KEY") {
+		t.Errorf("Expected key in OpenSSH format, got: %s", strings.SplitN(string(data), "\n", 2)[0])
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(keyPath)
+		if err != nil {
+			t.Fatalf("Failed to stat key: %v", err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("Expected permissions 0600, got %o", info.Mode().Perm())
+		}
+	}
+
+	withFirst, err := secrets.ParsePrivateKeyBytesWithPassphrase(data, []byte("first"))
+	if err != nil {
+		t.Fatalf("Failed to parse key with passphrase: %v", err)
+	}
+	if !bytes.Equal(unwrapSymmetricKey(t, withFirst), symKey) {
+		t.Error("Key no longer unwraps the same symmetric key")
+	}
+
+	// Change the passphrase.
+	if _, err := workflows.ChangePassphrase(ctx, workflows.ChangePassphraseOptions{
+		CurrentPassphrase: []byte("first"),
+		NewPassphrase:     []byte("second"),
+	}); err != nil {
+		t.Fatalf("Changing passphrase failed: %v", err)
+	}
+	data, err = os.ReadFile(keyPath)
+	if err != nil {
+		t.Fatalf("Failed to read key: %v", err)
+	}
+	if _, err := secrets.ParsePrivateKeyBytesWithPassphrase(data, []byte("second")); err != nil {
+		t.Fatalf("Failed to parse key with new passphrase: %v", err)
+	}
+
+	// Remove the passphrase.
+	result, err := workflows.ChangePassphrase(ctx, workflows.ChangePassphraseOptions{CurrentPassphrase: []byte("second")})
+	if err != nil {
+		t.Fatalf("Removing passphrase failed: %v", err)
+	}
+	if !result.Removed {
+		t.Error("Expected result to report the passphrase was removed")
+	}
+	unprotected, err := secrets.LoadPrivateKey(keyPath)
+	if err != nil {
+		t.Fatalf("Failed to load key without passphrase: %v", err)
+	}
+	if !bytes.Equal(unwrapSymmetricKey(t, unprotected), symKey) {
+		t.Error("Key no longer unwraps the same symmetric key")
+	}
+}
+
+func TestChangePassphrase_WrongPassphraseLeavesKeyUnchanged(t *testing.T) {
+	keyPath := setupPassphraseProject(t)
+	ctx := context.Background()
+
+	if _, err := workflows.ChangePassphrase(ctx, workflows.ChangePassphraseOptions{NewPassphrase: []byte("right")}); err != nil {
+		t.Fatalf("Adding passphrase failed: %v", err)
+	}
+	before, err := os.ReadFile(keyPath)
+	if err != nil {
+		t.Fatalf("Failed to read key: %v", err)
+	}
+
+	for _, current := range [][]byte{nil, []byte("wrong")} {
+		_, err := workflows.ChangePassphrase(ctx, workflows.ChangePassphraseOptions{CurrentPassphrase: current})
+		if !errors.Is(err, kerrors.ErrIncorrectPassphrase) {
+			t.Errorf("Expected ErrIncorrectPassphrase for %q, got %v", current, err)
+		}
+	}
+
+	after, err := os.ReadFile(keyPath)
+	if err != nil {
+		t.Fatalf("Failed to read key: %v", err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("Private key changed after a failed passphrase change")
+	}
+}
+
+func TestPassphraseCommand_RemoveWithoutPassphrase(t *testing.T) {
+	setupPassphraseProject(t)
+
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs("passphrase", []string{"--remove"}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	if !strings.Contains(output, "has no passphrase") {
+		t.Errorf("Expected no-passphrase message, got: %s", output)
+	}
+}
+
+func TestPassphraseCommand_RequiresTerminal(t *testing.T) {
+	setupPassphraseProject(t)
+
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("passphrase", nil, nil, false, false).Execute()
+	})
+	if !errors.Is(err, kerrors.ErrTTYRequired) {
+		t.Fatalf("Expected ErrTTYRequired, got: %v", err)
+	}
+	if !strings.Contains(output, "no terminal available") {
+		t.Errorf("Expected terminal required message, got: %s", output)
+	}
+}
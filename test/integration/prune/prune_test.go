@@ -0,0 +1,132 @@
+package prune
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+const orphanUUID = "99999999-9999-9999-9999-999999999999"
+
+// setupPruneProject initializes a project and adds a public key and an
+// encrypted key for a UUID that isn't in the config.
+func setupPruneProject(t *testing.T) (string, []string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	orphans := []string{
+		filepath.Join(tempDir, ".kanuka", "public_keys", orphanUUID+".pub"),
+		filepath.Join(tempDir, ".kanuka", "secrets", orphanUUID+".kanuka"),
+	}
+	for _, path := range orphans {
+		if err := os.WriteFile(path, []byte("stale"), 0600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	return tempDir, orphans
+}
+
+// registeredKeyFiles returns the current user's key files, which must survive a prune.
+func registeredKeyFiles(t *testing.T, tempDir string) []string {
+	userUUID := shared.GetUserUUID(t)
+	return []string{
+		filepath.Join(tempDir, ".kanuka", "public_keys", userUUID+".pub"),
+		filepath.Join(tempDir, ".kanuka", "secrets", userUUID+".kanuka"),
+	}
+}
+
+func TestPrune_DryRun(t *testing.T) {
+	tempDir, orphans := setupPruneProject(t)
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("prune", []string{"--dry-run"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Prune command failed: %v", err)
+	}
+
+	if !strings.Contains(output, "Would remove 2 orphaned file(s)") {
+		t.Errorf("Expected dry-run summary, got: %s", output)
+	}
+	if !strings.Contains(output, orphanUUID+".pub") || !strings.Contains(output, orphanUUID+".kanuka") {
+		t.Errorf("Expected both orphaned files to be listed, got: %s", output)
+	}
+	for _, path := range append(orphans, registeredKeyFiles(t, tempDir)...) {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be kept in dry-run: %v", path, err)
+		}
+	}
+}
+
+func TestPrune_RemovesOnlyUnregisteredFiles(t *testing.T) {
+	tempDir, orphans := setupPruneProject(t)
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("prune", []string{"--force"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Prune command failed: %v", err)
+	}
+
+	if !strings.Contains(output, "✓ Removed 2 orphaned file(s)") {
+		t.Errorf("Expected 2 files removed, got: %s", output)
+	}
+	for _, path := range orphans {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected %s to be removed", path)
+		}
+	}
+	for _, path := range registeredKeyFiles(t, tempDir) {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected registered key file %s to be kept: %v", path, err)
+		}
+	}
+
+	auditLog, err := os.ReadFile(filepath.Join(tempDir, ".kanuka", "audit.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	if !strings.Contains(string(auditLog), `"op":"prune"`) || !strings.Contains(string(auditLog), orphanUUID+".kanuka") {
+		t.Errorf("Expected a prune audit entry listing the removed files, got: %s", auditLog)
+	}
+}
+
+func TestPrune_NothingToPrune(t *testing.T) {
+	_, orphans := setupPruneProject(t)
+	for _, path := range orphans {
+		if err := os.Remove(path); err != nil {
+			t.Fatalf("Failed to remove %s: %v", path, err)
+		}
+	}
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("prune", []string{"--force"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Prune command failed: %v", err)
+	}
+
+	if !strings.Contains(output, "No orphaned key files found") {
+		t.Errorf("Expected nothing to prune, got: %s", output)
+	}
+}
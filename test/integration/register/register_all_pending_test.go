@@ -0,0 +1,161 @@
+package register
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+const (
+	pendingUserUUID  = "33333333-3333-3333-3333-333333333333"
+	pendingUserEmail = "pending@example.com"
+	orphanKeyUUID    = "44444444-4444-4444-4444-444444444444"
+)
+
+// setupPendingProject initializes a project with one user in the config who
+// has a public key but no encrypted key. Returns the project directory and
+// that user's private key path.
+func setupPendingProject(t *testing.T) (string, string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	privateKeyPath := filepath.Join(t.TempDir(), "pending")
+	publicKeyPath := filepath.Join(tempDir, ".kanuka", "public_keys", pendingUserUUID+".pub")
+	if err := shared.GenerateRSAKeyPair(privateKeyPath, publicKeyPath); err != nil {
+		t.Fatalf("Failed to generate pending user's key pair: %v", err)
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	projectConfig.Users[pendingUserUUID] = pendingUserEmail
+	projectConfig.Devices[pendingUserUUID] = configs.DeviceConfig{Email: pendingUserEmail, Name: "laptop"}
+	if err := configs.SaveProjectConfig(projectConfig); err != nil {
+		t.Fatalf("Failed to save project config: %v", err)
+	}
+
+	return tempDir, privateKeyPath
+}
+
+func runRegisterAllPending(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("register", append([]string{"--all-pending"}, args...), nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("register --all-pending failed: %v\nOutput: %s", err, output)
+	}
+	return output
+}
+
+func TestRegisterAllPending_GrantsPendingUser(t *testing.T) {
+	projectDir, privateKeyPath := setupPendingProject(t)
+	ownKey := filepath.Join(projectDir, ".kanuka", "secrets", shared.GetUserUUID(t)+".kanuka")
+	ownBefore, err := os.ReadFile(ownKey)
+	if err != nil {
+		t.Fatalf("Failed to read own key: %v", err)
+	}
+
+	output := runRegisterAllPending(t)
+	if !strings.Contains(output, pendingUserEmail) || !strings.Contains(output, "✓") {
+		t.Errorf("Expected %s to be reported as granted, got: %s", pendingUserEmail, output)
+	}
+
+	pendingKey := filepath.Join(projectDir, ".kanuka", "secrets", pendingUserUUID+".kanuka")
+	wrapped, err := os.ReadFile(pendingKey)
+	if err != nil {
+		t.Fatalf("Expected %s to be created: %v", pendingKey, err)
+	}
+	privateKey, err := secrets.LoadPrivateKey(privateKeyPath)
+	if err != nil {
+		t.Fatalf("Failed to load pending user's private key: %v", err)
+	}
+	if _, err := secrets.DecryptWithPrivateKey(wrapped, privateKey); err != nil {
+		t.Errorf("Pending user couldn't decrypt their new key: %v", err)
+	}
+
+	ownAfter, err := os.ReadFile(ownKey)
+	if err != nil {
+		t.Fatalf("Failed to read own key: %v", err)
+	}
+	if string(ownBefore) != string(ownAfter) {
+		t.Error("Expected the already registered user's key to be left alone")
+	}
+
+	output = runRegisterAllPending(t)
+	if !strings.Contains(output, "No pending public keys") {
+		t.Errorf("Expected nothing pending on a second run, got: %s", output)
+	}
+}
+
+func TestRegisterAllPending_DryRunWritesNothing(t *testing.T) {
+	projectDir, _ := setupPendingProject(t)
+
+	output := runRegisterAllPending(t, "--dry-run")
+	if !strings.Contains(output, "[dry-run]") || !strings.Contains(output, pendingUserEmail) {
+		t.Errorf("Expected a dry-run listing of %s, got: %s", pendingUserEmail, output)
+	}
+
+	pendingKey := filepath.Join(projectDir, ".kanuka", "secrets", pendingUserUUID+".kanuka")
+	if _, err := os.Stat(pendingKey); !os.IsNotExist(err) {
+		t.Errorf("Expected dry run not to create %s", pendingKey)
+	}
+}
+
+func TestRegisterAllPending_ReportsKeysNotInConfig(t *testing.T) {
+	projectDir, _ := setupPendingProject(t)
+
+	orphanPublicKey := filepath.Join(projectDir, ".kanuka", "public_keys", orphanKeyUUID+".pub")
+	if err := shared.GenerateRSAKeyPair(filepath.Join(t.TempDir(), "orphan"), orphanPublicKey); err != nil {
+		t.Fatalf("Failed to generate orphan key pair: %v", err)
+	}
+
+	output := runRegisterAllPending(t)
+	if !strings.Contains(output, "✗") || !strings.Contains(output, orphanKeyUUID) {
+		t.Errorf("Expected the orphan key to be reported as failed, got: %s", output)
+	}
+	if !strings.Contains(output, "1 of 2 pending key(s) could not be granted access") {
+		t.Errorf("Expected a partial failure summary, got: %s", output)
+	}
+
+	if _, err := os.Stat(filepath.Join(projectDir, ".kanuka", "secrets", pendingUserUUID+".kanuka")); err != nil {
+		t.Errorf("Expected the pending user to be granted despite the orphan key: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, ".kanuka", "secrets", orphanKeyUUID+".kanuka")); !os.IsNotExist(err) {
+		t.Error("Expected no encrypted key for a UUID that isn't in the project config")
+	}
+}
+
+func TestRegisterAllPending_RejectsOtherKeyFlags(t *testing.T) {
+	setupPendingProject(t)
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("register", []string{"--all-pending", "--user", pendingUserEmail}, nil, nil, false, false).Execute()
+	})
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Fatalf("Expected ErrInvalidArguments, got: %v", err)
+	}
+	if !strings.Contains(output, "cannot be used with") {
+		t.Errorf("Expected --all-pending with --user to be rejected, got: %s", output)
+	}
+}
@@ -0,0 +1,225 @@
+package register
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupSecondMachine initializes a project on a first machine, encrypts a
+// .env file there, then switches to a second machine with the same email and
+// no access. It returns the project dir and the first machine's private key.
+func setupSecondMachine(t *testing.T, envContent string) (string, []byte) {
+	t.Helper()
+	tempDir := t.TempDir()
+	firstUserDir := t.TempDir()
+	secondUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	originalUserSettings := configs.UserKanukaSettings
+
+	shared.SetupTestEnvironment(t, tempDir, firstUserDir, originalWd, originalUserSettings)
+	shared.InitializeProject(t, tempDir, firstUserDir)
+
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte(envContent), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	if _, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLI("encrypt", nil, nil, false, false).Execute()
+	}); err != nil {
+		t.Fatalf("Encrypt command failed: %v", err)
+	}
+	if err := os.Remove(filepath.Join(tempDir, ".env")); err != nil {
+		t.Fatalf("Failed to remove .env: %v", err)
+	}
+
+	projectUUID := shared.GetProjectUUID(t)
+	privateKeyData, err := os.ReadFile(shared.GetPrivateKeyPath(filepath.Join(firstUserDir, "keys"), projectUUID))
+	if err != nil {
+		t.Fatalf("Failed to read first machine's private key: %v", err)
+	}
+
+	cmd.ResetGlobalState()
+	shared.SetupTestEnvironmentWithUUID(t, tempDir, secondUserDir, originalWd, originalUserSettings,
+		shared.TestUser2UUID, "testuser", shared.TestUserEmail)
+	return tempDir, privateKeyData
+}
+
+// TestRegisterDevice tests that a user can register a second machine with
+// another device's private key, and decrypt from it.
+func TestRegisterDevice(t *testing.T) {
+	envContent := "API_KEY=secret123\n"
+	tempDir, privateKeyData := setupSecondMachine(t, envContent)
+
+	output, err := shared.CaptureOutputWithStdin(privateKeyData, func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("register", []string{"--device", "desktop", "--private-key-stdin"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Register command failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "This device now has access") {
+		t.Errorf("Expected device success message, got: %s", output)
+	}
+
+	for _, path := range []string{
+		filepath.Join(tempDir, ".kanuka", "public_keys", shared.TestUser2UUID+".pub"),
+		filepath.Join(tempDir, ".kanuka", "secrets", shared.TestUser2UUID+".kanuka"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be created: %v", path, err)
+		}
+	}
+
+	configs.GlobalProjectConfig = nil
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	uuid, found := projectConfig.GetUserUUIDByEmailAndDevice(shared.TestUserEmail, "desktop")
+	if !found || uuid != shared.TestUser2UUID {
+		t.Errorf("Expected device desktop to be registered as %s, got %q (found: %v)", shared.TestUser2UUID, uuid, found)
+	}
+	if len(projectConfig.GetDevicesByEmail(shared.TestUserEmail)) != 2 {
+		t.Errorf("Expected 2 devices for %s, got %v", shared.TestUserEmail, projectConfig.GetDevicesByEmail(shared.TestUserEmail))
+	}
+
+	output, err = shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLI("decrypt", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Decrypt command failed: %v\nOutput: %s", err, output)
+	}
+	decrypted, err := os.ReadFile(filepath.Join(tempDir, ".env"))
+	if err != nil {
+		t.Fatalf("Expected the second machine to decrypt .env: %v\nOutput: %s", err, output)
+	}
+	if string(decrypted) != envContent {
+		t.Errorf("Expected decrypted content %q, got %q", envContent, string(decrypted))
+	}
+}
+
+// TestRegisterDeviceDuplicateName tests that a device name already used by
+// the same email is refused.
+func TestRegisterDeviceDuplicateName(t *testing.T) {
+	tempDir, privateKeyData := setupSecondMachine(t, "KEY=value\n")
+
+	configs.GlobalProjectConfig = nil
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	existingName := projectConfig.Devices[shared.TestUserUUID].Name
+
+	output, err := shared.CaptureOutputWithStdin(privateKeyData, func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("register", []string{"--device", existingName, "--private-key-stdin"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if !errors.Is(err, kerrors.ErrDeviceNameTaken) {
+		t.Fatalf("Expected ErrDeviceNameTaken, got: %v", err)
+	}
+	if !strings.Contains(output, "already have a device named") {
+		t.Errorf("Expected duplicate device error, got: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".kanuka", "secrets", shared.TestUser2UUID+".kanuka")); !os.IsNotExist(err) {
+		t.Errorf("Expected no key to be created for a duplicate device name")
+	}
+}
+
+// TestRegisterDeviceWithoutAccess tests that a private key that doesn't
+// belong to one of the user's devices is refused.
+func TestRegisterDeviceWithoutAccess(t *testing.T) {
+	tempDir, _ := setupSecondMachine(t, "KEY=value\n")
+
+	otherKeyDir := t.TempDir()
+	otherPrivateKey := filepath.Join(otherKeyDir, "privkey")
+	if err := shared.GenerateRSAKeyPair(otherPrivateKey, filepath.Join(otherKeyDir, "pubkey.pub")); err != nil {
+		t.Fatalf("Failed to generate key pair: %v", err)
+	}
+	otherKeyData, err := os.ReadFile(otherPrivateKey)
+	if err != nil {
+		t.Fatalf("Failed to read private key: %v", err)
+	}
+
+	output, err := shared.CaptureOutputWithStdin(otherKeyData, func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("register", []string{"--device", "desktop", "--private-key-stdin"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if !errors.Is(err, kerrors.ErrNoAccess) {
+		t.Fatalf("Expected ErrNoAccess, got: %v", err)
+	}
+	if !strings.Contains(output, "Couldn't unlock the project") {
+		t.Errorf("Expected access error, got: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".kanuka", "public_keys", shared.TestUser2UUID+".pub")); !os.IsNotExist(err) {
+		t.Errorf("Expected no public key to be created without access")
+	}
+}
+
+// TestRegisterDeviceKeyType tests that --key-type picks the algorithm of the
+// key pair generated for the new device.
+func TestRegisterDeviceKeyType(t *testing.T) {
+	envContent := "API_KEY=secret123\n"
+	tempDir, privateKeyData := setupSecondMachine(t, envContent)
+
+	output, err := shared.CaptureOutputWithStdin(privateKeyData, func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("register", []string{"--device", "desktop", "--key-type", "ed25519", "--private-key-stdin"}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Register command failed: %v\nOutput: %s", err, output)
+	}
+
+	publicKey, err := secrets.LoadPublicKey(filepath.Join(tempDir, ".kanuka", "public_keys", shared.TestUser2UUID+".pub"))
+	if err != nil {
+		t.Fatalf("Failed to load the new device's public key: %v", err)
+	}
+	if keyType, err := secrets.PublicKeyType(publicKey); err != nil || keyType != secrets.KeyTypeEd25519 {
+		t.Errorf("Expected an Ed25519 public key, got %q (%v)", keyType, err)
+	}
+
+	output, err = shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLI("decrypt", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Decrypt command failed: %v\nOutput: %s", err, output)
+	}
+	decrypted, err := os.ReadFile(filepath.Join(tempDir, ".env"))
+	if err != nil || string(decrypted) != envContent {
+		t.Errorf("Expected the Ed25519 device to decrypt %q, got %q (%v)\nOutput: %s", envContent, decrypted, err, output)
+	}
+}
+
+// TestRegisterKeyTypeRequiresDevice tests that --key-type is refused without --device.
+func TestRegisterKeyTypeRequiresDevice(t *testing.T) {
+	setupSecondMachine(t, "KEY=value\n")
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("register", []string{"--user", shared.TestUserEmail, "--key-type", "ed25519"}, nil, nil, false, false).Execute()
+	})
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Fatalf("Expected ErrInvalidArguments, got: %v", err)
+	}
+	if !strings.Contains(output, "can only be used with") {
+		t.Errorf("Expected --key-type without --device to be rejected, got: %s", output)
+	}
+}
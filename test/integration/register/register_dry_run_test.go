@@ -5,6 +5,7 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/PolarWolf314/kanuka/cmd"
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/internal/secrets"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
@@ -282,8 +284,8 @@ func TestRegisterDryRun_ValidationStillRuns(t *testing.T) {
 		return testCmd.Execute()
 	})
 
-	if err != nil {
-		t.Errorf("Command should not return error: %v", err)
+	if !errors.Is(err, kerrors.ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got: %v", err)
 	}
 
 	// Should show "user not found" error, not dry-run output.
@@ -325,8 +327,8 @@ func TestRegisterDryRun_NotInitialized(t *testing.T) {
 		return testCmd.Execute()
 	})
 
-	if err != nil {
-		t.Errorf("Command should not return error: %v", err)
+	if !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Errorf("Expected ErrProjectNotInitialized, got: %v", err)
 	}
 
 	// Should show "not initialized" message, not dry-run output.
@@ -389,8 +391,8 @@ func TestRegisterDryRun_SymmetricKeyValidation(t *testing.T) {
 		return testCmd.Execute()
 	})
 
-	if err != nil {
-		t.Errorf("Command should not return error: %v", err)
+	if !errors.Is(err, kerrors.ErrKeyDecryptFailed) {
+		t.Errorf("Expected ErrKeyDecryptFailed, got: %v", err)
 	}
 
 	// Should show error about decrypting the kanuka file, not dry-run output.
@@ -432,8 +434,8 @@ func generateDryRunTestKeyPair(t *testing.T) *dryRunTestKeyPair {
 	}
 
 	return &dryRunTestKeyPair{
-		publicKey:  publicKey,
-		privateKey: privateKey,
+		publicKey:  publicKey.(*rsa.PublicKey),
+		privateKey: privateKey.(*rsa.PrivateKey),
 	}
 }
 
@@ -0,0 +1,95 @@
+package register
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// TestRegisterEd25519 tests registering a user whose public key is an Ed25519 SSH key.
+func TestRegisterEd25519(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get original working directory: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	sshKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		t.Fatalf("Failed to create SSH public key: %v", err)
+	}
+	pubkeyText := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshKey)))
+
+	targetUserUUID := shared.TestUser2UUID
+	targetUserEmail := shared.TestUser2Email
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	projectConfig.Users[targetUserUUID] = targetUserEmail
+	if err := configs.SaveProjectConfig(projectConfig); err != nil {
+		t.Fatalf("Failed to save project config: %v", err)
+	}
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd := shared.CreateTestCLI("register", nil, nil, false, false)
+		cmd.SetArgs([]string{"secrets", "register", "--pubkey", pubkeyText, "--user", targetUserEmail})
+		return cmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "✓") {
+		t.Fatalf("Expected success message not found in output: %s", output)
+	}
+
+	verifyEd25519UserCanDecrypt(t, tempDir, targetUserUUID, privateKey)
+
+	// Syncing re-wraps every user's key and must keep the Ed25519 scheme.
+	output, err = shared.CaptureOutput(func() error {
+		cmd := shared.CreateTestCLI("sync", nil, nil, false, false)
+		return cmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Sync failed: %v\nOutput: %s", err, output)
+	}
+
+	verifyEd25519UserCanDecrypt(t, tempDir, targetUserUUID, privateKey)
+}
+
+// verifyEd25519UserCanDecrypt checks that the user's wrapped key opens with their Ed25519 key.
+func verifyEd25519UserCanDecrypt(t *testing.T, projectDir, userUUID string, privateKey ed25519.PrivateKey) {
+	t.Helper()
+
+	wrapped, err := os.ReadFile(filepath.Join(projectDir, ".kanuka", "secrets", userUUID+".kanuka"))
+	if err != nil {
+		t.Fatalf("Failed to read wrapped key: %v", err)
+	}
+
+	symKey, err := secrets.DecryptWithPrivateKey(wrapped, privateKey)
+	if err != nil {
+		t.Fatalf("Ed25519 user cannot decrypt symmetric key: %v", err)
+	}
+	if len(symKey) != 32 {
+		t.Errorf("Expected 32-byte symmetric key, got %d bytes", len(symKey))
+	}
+}
@@ -0,0 +1,91 @@
+package register
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// TestRegisterExpiry tests that --expiry records the end of the given day on
+// the registered device, and that invalid or past dates are rejected.
+func TestRegisterExpiry(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get original working directory: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+	addUserToProjectConfig(t, shared.TestUser2UUID, shared.TestUser2Email)
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	pubASN1, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("Failed to marshal public key: %v", err)
+	}
+	pubKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubASN1}))
+
+	for _, tt := range []struct {
+		expiry  string
+		wantErr error
+		want    string
+	}{
+		{expiry: "31/12/2099", wantErr: kerrors.ErrInvalidDateFormat, want: "YYYY-MM-DD"},
+		{expiry: "2000-01-01", wantErr: kerrors.ErrInvalidArguments, want: "already passed"},
+	} {
+		output, err := shared.CaptureOutput(func() error {
+			cmd.ResetGlobalState()
+			return shared.CreateTestCLIWithArgs("register",
+				[]string{"--pubkey", pubKey, "--user", shared.TestUser2Email, "--expiry", tt.expiry}, nil, nil, false, false).Execute()
+		})
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("Expected %v for %s, got: %v", tt.wantErr, tt.expiry, err)
+		}
+		if !strings.Contains(output, tt.want) {
+			t.Errorf("Expected %q in the output for --expiry %s, got: %s", tt.want, tt.expiry, output)
+		}
+		if _, err := os.Stat(filepath.Join(configs.ProjectKanukaSettings.ProjectSecretsPath, shared.TestUser2UUID+".kanuka")); !os.IsNotExist(err) {
+			t.Fatalf("Expected --expiry %s to register nothing", tt.expiry)
+		}
+	}
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("register",
+			[]string{"--pubkey", pubKey, "--user", shared.TestUser2Email, "--expiry", "2099-12-31"}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "Dec 31, 2099") {
+		t.Errorf("Expected the expiry date in the output, got: %s", output)
+	}
+
+	configs.GlobalProjectConfig = nil
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	want := time.Date(2099, 12, 31, 23, 59, 59, 0, time.UTC)
+	if got := projectConfig.Devices[shared.TestUser2UUID].ExpiresAt; !got.Equal(want) {
+		t.Errorf("Expected the device to expire at %v, got %v", want, got)
+	}
+}
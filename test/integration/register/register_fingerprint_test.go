@@ -0,0 +1,49 @@
+package register
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// TestRegisterShowsKeyFingerprint tests that register reports the fingerprint
+// of the key it registered, so it can be checked with the key's owner.
+func TestRegisterShowsKeyFingerprint(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get original working directory: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+	addUserToProjectConfig(t, shared.TestUser2UUID, shared.TestUser2Email)
+
+	keyPath, privateKey := writeEd25519PublicKeyFile(t, t.TempDir())
+	want, err := secrets.PublicKeyFingerprint(privateKey.Public())
+	if err != nil {
+		t.Fatalf("Failed to fingerprint public key: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"--dry-run"},
+		{},
+	} {
+		output, err := shared.CaptureOutput(func() error {
+			cmd := shared.CreateTestCLIWithArgs("register", append([]string{"--public-key", keyPath, "--user", shared.TestUser2Email}, args...), nil, nil, false, false)
+			return cmd.Execute()
+		})
+		if err != nil {
+			t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+		}
+		if !strings.Contains(output, "Key fingerprint: ") || !strings.Contains(output, want) {
+			t.Errorf("Expected fingerprint %s with args %v, got: %s", want, args, output)
+		}
+	}
+}
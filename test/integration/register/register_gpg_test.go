@@ -0,0 +1,180 @@
+package register
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupGPGKey points gpg at a temporary keyring and generates an unprotected key for email.
+func setupGPGKey(t *testing.T, email string) {
+	t.Helper()
+	if !secrets.GPGAvailable() {
+		t.Skip("gpg not on PATH")
+	}
+
+	t.Setenv("GNUPGHOME", t.TempDir())
+	t.Cleanup(func() {
+		_ = exec.Command("gpgconf", "--kill", "gpg-agent").Run()
+	})
+
+	out, err := exec.Command("gpg", "--batch", "--passphrase", "", "--quick-gen-key", email, "default", "default", "never").CombinedOutput()
+	if err != nil {
+		t.Fatalf("Failed to generate GPG key: %v\n%s", err, out)
+	}
+}
+
+// TestRegisterGPG tests registering a user with a GPG key, syncing, and
+// decrypting as that user.
+func TestRegisterGPG(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get original working directory: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	targetUserUUID := shared.TestUser2UUID
+	targetUserEmail := shared.TestUser2Email
+	setupGPGKey(t, targetUserEmail)
+	addUserToProjectConfig(t, targetUserUUID, targetUserEmail)
+
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("API_KEY=gpg-secret\n"), 0600); err != nil {
+		t.Fatalf("Failed to write .env: %v", err)
+	}
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("encrypt", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v\nOutput: %s", err, output)
+	}
+
+	output, err = shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs("register", []string{"--user", targetUserEmail, "--gpg-key", targetUserEmail}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "✓") {
+		t.Fatalf("Expected success message not found in output: %s", output)
+	}
+
+	gpgKeyPath := filepath.Join(tempDir, ".kanuka", "public_keys", targetUserUUID+".gpg")
+	if _, err := os.Stat(gpgKeyPath); err != nil {
+		t.Fatalf("Expected GPG public key at %s: %v", gpgKeyPath, err)
+	}
+	verifyGPGUserCanDecrypt(t, tempDir, targetUserUUID)
+
+	// A GPG key counts as the user's public key, so they are active rather than orphaned.
+	accessResult, err := workflows.Access(context.Background(), workflows.AccessOptions{})
+	if err != nil {
+		t.Fatalf("Access failed: %v", err)
+	}
+	for _, u := range accessResult.Users {
+		if u.UUID == targetUserUUID && u.Status != workflows.UserStatusActive {
+			t.Errorf("Expected GPG user to be active, got %s", u.Status)
+		}
+	}
+	cleanResult, err := workflows.Clean(context.Background(), workflows.CleanOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Clean failed: %v", err)
+	}
+	if len(cleanResult.Orphans) != 0 {
+		t.Errorf("Expected no orphans, got %+v", cleanResult.Orphans)
+	}
+
+	// Syncing re-wraps every user's key and must keep the GPG scheme.
+	output, err = shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("sync", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Sync failed: %v\nOutput: %s", err, output)
+	}
+	verifyGPGUserCanDecrypt(t, tempDir, targetUserUUID)
+
+	// Decrypt as the GPG user, which has no Kānuka private key.
+	userConfig, err := configs.LoadUserConfig()
+	if err != nil {
+		t.Fatalf("Failed to load user config: %v", err)
+	}
+	userConfig.User.UUID = targetUserUUID
+	if err := configs.SaveUserConfig(userConfig); err != nil {
+		t.Fatalf("Failed to save user config: %v", err)
+	}
+	if err := os.Remove(filepath.Join(tempDir, ".env")); err != nil {
+		t.Fatalf("Failed to remove .env: %v", err)
+	}
+
+	output, err = shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("decrypt", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v\nOutput: %s", err, output)
+	}
+	content, err := os.ReadFile(filepath.Join(tempDir, ".env"))
+	if err != nil {
+		t.Fatalf("Expected .env to be decrypted: %v\nOutput: %s", err, output)
+	}
+	if string(content) != "API_KEY=gpg-secret\n" {
+		t.Errorf("Unexpected decrypted content: %q", content)
+	}
+}
+
+// TestRegisterGPG_RequiresUser tests that --gpg-key needs --user.
+func TestRegisterGPG_RequiresUser(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get original working directory: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs("register", []string{"--gpg-key", "ABCDEF"}, nil, nil, false, false).Execute()
+	})
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Fatalf("Expected ErrInvalidArguments, got: %v", err)
+	}
+	if !strings.Contains(output, "--user") {
+		t.Errorf("Expected --user requirement in output, got: %s", output)
+	}
+}
+
+// verifyGPGUserCanDecrypt checks that the user's wrapped key is GPG-wrapped and opens with gpg.
+func verifyGPGUserCanDecrypt(t *testing.T, projectDir, userUUID string) {
+	t.Helper()
+
+	wrapped, err := os.ReadFile(filepath.Join(projectDir, ".kanuka", "secrets", userUUID+".kanuka"))
+	if err != nil {
+		t.Fatalf("Failed to read wrapped key: %v", err)
+	}
+	if !secrets.IsGPGWrapped(wrapped) {
+		t.Fatal("Expected wrapped key to be GPG-wrapped")
+	}
+
+	symKey, err := secrets.DecryptWithGPG(wrapped)
+	if err != nil {
+		t.Fatalf("GPG user cannot decrypt symmetric key: %v", err)
+	}
+	if len(symKey) != 32 {
+		t.Errorf("Expected 32-byte symmetric key, got %d bytes", len(symKey))
+	}
+}
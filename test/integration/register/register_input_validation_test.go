@@ -1,12 +1,14 @@
 package register
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -73,8 +75,8 @@ func testRegisterWithNoFlags(t *testing.T, originalWd string, originalUserSettin
 		cmd := shared.CreateTestCLI("register", nil, nil, true, false)
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Errorf("Expected ErrInvalidArguments, got: %v", err)
 	}
 
 	if !strings.Contains(output, "✗") {
@@ -120,8 +122,8 @@ func testRegisterWithPubkeyButNoUser(t *testing.T, originalWd string, originalUs
 		cmd.SetArgs([]string{"secrets", "register", "--pubkey", pubkeyText})
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Errorf("Expected ErrInvalidArguments, got: %v", err)
 	}
 
 	if !strings.Contains(output, "✗") {
@@ -166,8 +168,8 @@ func testRegisterWithInvalidPubkeyFormat(t *testing.T, originalWd string, origin
 		cmd.SetArgs([]string{"secrets", "register", "--pubkey", invalidPubkeyText, "--user", targetUserEmail})
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
+	if err == nil {
+		t.Errorf("Expected the command to fail")
 	}
 
 	if !strings.Contains(output, "✗") {
@@ -207,8 +209,8 @@ func testRegisterWithInvalidFileExtension(t *testing.T, originalWd string, origi
 		cmd.SetArgs([]string{"secrets", "register", "--file", invalidFile})
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
+	if !errors.Is(err, kerrors.ErrInvalidFileType) {
+		t.Errorf("Expected ErrInvalidFileType, got: %v", err)
 	}
 
 	if !strings.Contains(output, "✗") {
@@ -244,8 +246,8 @@ func testRegisterWithEmptyPubkeyText(t *testing.T, originalWd string, originalUs
 		cmd.SetArgs([]string{"secrets", "register", "--pubkey", "", "--user", targetUserEmail})
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Errorf("Expected ErrInvalidArguments, got: %v", err)
 	}
 
 	if !strings.Contains(output, "✗") {
@@ -279,8 +281,8 @@ func testRegisterWithEmptyUsername(t *testing.T, originalWd string, originalUser
 		cmd.SetArgs([]string{"secrets", "register", "--user", ""})
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Errorf("Expected ErrInvalidArguments, got: %v", err)
 	}
 
 	if !strings.Contains(output, "✗") {
@@ -317,8 +319,8 @@ func testRegisterWithSpecialCharactersInUsername(t *testing.T, originalWd string
 		cmd.SetArgs([]string{"secrets", "register", "--user", invalidEmail})
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
+	if !errors.Is(err, kerrors.ErrInvalidEmail) {
+		t.Errorf("Expected ErrInvalidEmail, got: %v", err)
 	}
 
 	if !strings.Contains(output, "✗") {
@@ -355,8 +357,8 @@ func testRegisterWithVeryLongUsername(t *testing.T, originalWd string, originalU
 		cmd.SetArgs([]string{"secrets", "register", "--user", invalidEmail})
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
+	if !errors.Is(err, kerrors.ErrInvalidEmail) {
+		t.Errorf("Expected ErrInvalidEmail, got: %v", err)
 	}
 
 	if !strings.Contains(output, "✗") {
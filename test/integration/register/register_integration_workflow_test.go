@@ -5,12 +5,14 @@ import (
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -67,18 +69,13 @@ func testInitCreateRegisterWorkflow(t *testing.T, originalWd string, originalUse
 		t.Fatalf("Init command failed: %v", err)
 	}
 
-	secretsFile := filepath.Join(tempDir, "test.env")
-	if err := os.WriteFile(secretsFile, []byte("TEST_VAR=test_value\n"), 0600); err != nil {
-		t.Fatalf("Failed to create test secrets file: %v", err)
-	}
-
+	// Init creates the user's keys, so there is nothing left for create to do.
 	_, err = shared.CaptureOutput(func() error {
 		cmd := shared.CreateTestCLI("create", nil, nil, false, false)
-		cmd.SetArgs([]string{"secrets", "create", secretsFile})
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Fatalf("Create command failed: %v", err)
+	if !errors.Is(err, kerrors.ErrPublicKeyExists) {
+		t.Fatalf("Expected ErrPublicKeyExists, got: %v", err)
 	}
 
 	targetUserUUID := "new-user-uuid-1234"
@@ -1,11 +1,13 @@
 package register
 
 import (
+	"errors"
 	"os"
 	"strings"
 	"testing"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -48,8 +50,8 @@ func testRegisterErrorMessageFormat(t *testing.T, originalWd string, originalUse
 		cmd.SetArgs([]string{"secrets", "register", "--user", invalidEmail})
 		return cmd.Execute()
 	})
-	if err != nil {
-		t.Errorf("Command failed unexpectedly: %v", err)
+	if !errors.Is(err, kerrors.ErrInvalidEmail) {
+		t.Errorf("Expected ErrInvalidEmail, got: %v", err)
 	}
 
 	// Verify error symbol
@@ -0,0 +1,160 @@
+package register
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+// writeEd25519PublicKeyFile writes a new OpenSSH Ed25519 public key to a file
+// in dir with a name that isn't a UUID, and returns its path and private key.
+func writeEd25519PublicKeyFile(t *testing.T, dir string) (string, ed25519.PrivateKey) {
+	t.Helper()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	sshKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		t.Fatalf("Failed to create SSH public key: %v", err)
+	}
+
+	path := filepath.Join(dir, "alice-laptop.pub")
+	if err := os.WriteFile(path, ssh.MarshalAuthorizedKey(sshKey), 0644); err != nil {
+		t.Fatalf("Failed to write public key file: %v", err)
+	}
+	return path, privateKey
+}
+
+// TestRegisterPublicKeyFile tests registering a user from a public key file with any name.
+func TestRegisterPublicKeyFile(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get original working directory: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+	addUserToProjectConfig(t, shared.TestUser2UUID, shared.TestUser2Email)
+
+	keyPath, privateKey := writeEd25519PublicKeyFile(t, t.TempDir())
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd := shared.CreateTestCLIWithArgs("register", []string{"--public-key", keyPath, "--user", shared.TestUser2Email}, nil, nil, false, false)
+		return cmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "✓") {
+		t.Fatalf("Expected success message not found in output: %s", output)
+	}
+
+	pubKeyPath := filepath.Join(tempDir, ".kanuka", "public_keys", shared.TestUser2UUID+".pub")
+	if _, err := os.Stat(pubKeyPath); err != nil {
+		t.Errorf("Expected public key to be copied to %s: %v", pubKeyPath, err)
+	}
+	verifyEd25519UserCanDecrypt(t, tempDir, shared.TestUser2UUID, privateKey)
+
+	// Registering again must not silently replace the key.
+	otherKeyPath, _ := writeEd25519PublicKeyFile(t, t.TempDir())
+	before, err := os.ReadFile(pubKeyPath)
+	if err != nil {
+		t.Fatalf("Failed to read public key: %v", err)
+	}
+
+	output, err = shared.CaptureOutput(func() error {
+		cmd := shared.CreateTestCLIWithArgs("register", []string{"--public-key", otherKeyPath, "--user", shared.TestUser2Email}, nil, nil, false, false)
+		return cmd.Execute()
+	})
+	if !errors.Is(err, kerrors.ErrPublicKeyExists) {
+		t.Fatalf("Expected ErrPublicKeyExists, got: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "already has a public key") || !strings.Contains(output, "--force") {
+		t.Errorf("Expected existing key to be rejected, got: %s", output)
+	}
+	after, err := os.ReadFile(pubKeyPath)
+	if err != nil {
+		t.Fatalf("Failed to read public key: %v", err)
+	}
+	if string(before) != string(after) {
+		t.Error("Expected existing public key to be unchanged without --force")
+	}
+
+	// With --force the key is replaced.
+	output, err = shared.CaptureOutput(func() error {
+		cmd := shared.CreateTestCLIWithArgs("register", []string{"--public-key", otherKeyPath, "--user", shared.TestUser2Email, "--force"}, nil, nil, false, false)
+		return cmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "access has been updated") {
+		t.Errorf("Expected key to be replaced with --force, got: %s", output)
+	}
+}
+
+// TestRegisterPublicKeyFile_Errors tests --public-key input validation.
+func TestRegisterPublicKeyFile_Errors(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get original working directory: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+	addUserToProjectConfig(t, shared.TestUser2UUID, shared.TestUser2Email)
+
+	invalidKeyPath := filepath.Join(t.TempDir(), "garbage.pub")
+	if err := os.WriteFile(invalidKeyPath, []byte("not a public key"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	validKeyPath, _ := writeEd25519PublicKeyFile(t, t.TempDir())
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"requires user", []string{"--public-key", validKeyPath}, "--user"},
+		{"missing file", []string{"--public-key", filepath.Join(t.TempDir(), "missing.pub"), "--user", shared.TestUser2Email}, "not found"},
+		{"invalid key", []string{"--public-key", invalidKeyPath, "--user", shared.TestUser2Email}, "Invalid public key format"},
+		{"conflicts with pubkey", []string{"--public-key", validKeyPath, "--pubkey", "ssh-ed25519 AAAA", "--user", shared.TestUser2Email}, "cannot be used with"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := shared.CaptureOutput(func() error {
+				cmd := shared.CreateTestCLIWithArgs("register", tt.args, nil, nil, false, false)
+				return cmd.Execute()
+			})
+			if err == nil {
+				t.Fatalf("Expected the command to fail, got output: %s", output)
+			}
+			if !strings.Contains(output, tt.want) {
+				t.Errorf("Expected %q in output, got: %s", tt.want, output)
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, ".kanuka", "secrets", shared.TestUser2UUID+".kanuka")); !os.IsNotExist(err) {
+		t.Error("Expected no key to be registered after failed attempts")
+	}
+}
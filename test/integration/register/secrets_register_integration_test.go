@@ -308,8 +308,8 @@ func generateTestKeyPair(t *testing.T) *testKeyPair {
 	}
 
 	return &testKeyPair{
-		publicKey:  publicKey,
-		privateKey: privateKey,
+		publicKey:  publicKey.(*rsa.PublicKey),
+		privateKey: privateKey.(*rsa.PrivateKey),
 	}
 }
 
@@ -0,0 +1,172 @@
+package rekey
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+const (
+	secondUserUUID  = "22222222-2222-2222-2222-222222222222"
+	secondUserEmail = "second@example.com"
+)
+
+// setupRekeyProject initializes a project with an encrypted .env and a second
+// user who is in the config and has a public key but no encrypted key.
+// Returns the project directory and the second user's private key path.
+func setupRekeyProject(t *testing.T) (string, string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	secondPrivateKey := filepath.Join(t.TempDir(), "second")
+	secondPublicKey := filepath.Join(tempDir, ".kanuka", "public_keys", secondUserUUID+".pub")
+	if err := shared.GenerateRSAKeyPair(secondPrivateKey, secondPublicKey); err != nil {
+		t.Fatalf("Failed to generate second user's key pair: %v", err)
+	}
+
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	projectConfig.Users[secondUserUUID] = secondUserEmail
+	projectConfig.Devices[secondUserUUID] = configs.DeviceConfig{Email: secondUserEmail, Name: "laptop"}
+	if err := configs.SaveProjectConfig(projectConfig); err != nil {
+		t.Fatalf("Failed to save project config: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("API_KEY=secret\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("encrypt", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v\nOutput: %s", err, output)
+	}
+
+	return tempDir, secondPrivateKey
+}
+
+func runRekey(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs("rekey", args, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("rekey failed: %v\nOutput: %s", err, output)
+	}
+	return output
+}
+
+// runRekeyExpectingError runs rekey and fails the test unless it returns want.
+func runRekeyExpectingError(t *testing.T, want error, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs("rekey", args, nil, nil, false, false).Execute()
+	})
+	if !errors.Is(err, want) {
+		t.Fatalf("Expected rekey to fail with %v, got: %v\nOutput: %s", want, err, output)
+	}
+	return output
+}
+
+func readFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return data
+}
+
+func TestRekey_RestoresMissingKeyWithoutTouchingSecrets(t *testing.T) {
+	projectDir, secondPrivateKey := setupRekeyProject(t)
+	envKanuka := filepath.Join(projectDir, ".env.kanuka")
+	ownKey := filepath.Join(projectDir, ".kanuka", "secrets", shared.GetUserUUID(t)+".kanuka")
+	beforeEnv := readFile(t, envKanuka)
+	beforeOwn := readFile(t, ownKey)
+
+	output := runRekey(t, "--user", secondUserEmail)
+	if !strings.Contains(output, "Rewrote the encrypted key for") || !strings.Contains(output, "created:") {
+		t.Errorf("Expected success message, got: %s", output)
+	}
+
+	if !bytes.Equal(beforeEnv, readFile(t, envKanuka)) {
+		t.Error("Expected .env.kanuka to be left untouched")
+	}
+	if !bytes.Equal(beforeOwn, readFile(t, ownKey)) {
+		t.Error("Expected the current user's key to be left untouched")
+	}
+
+	privateKey, err := secrets.LoadPrivateKey(secondPrivateKey)
+	if err != nil {
+		t.Fatalf("Failed to load second user's private key: %v", err)
+	}
+	wrapped := readFile(t, filepath.Join(projectDir, ".kanuka", "secrets", secondUserUUID+".kanuka"))
+	symKey, err := secrets.DecryptWithPrivateKey(wrapped, privateKey)
+	if err != nil {
+		t.Fatalf("Second user cannot unwrap the key: %v", err)
+	}
+	plaintext, err := secrets.ReadEncryptedFile(symKey, envKanuka)
+	if err != nil {
+		t.Fatalf("Second user cannot decrypt .env.kanuka: %v", err)
+	}
+	if string(plaintext) != "API_KEY=secret\n" {
+		t.Errorf("Unexpected plaintext: %q", plaintext)
+	}
+
+	auditLog := readFile(t, filepath.Join(projectDir, ".kanuka", "audit.jsonl"))
+	if !strings.Contains(string(auditLog), `"op":"rekey"`) || !strings.Contains(string(auditLog), secondUserEmail) {
+		t.Errorf("Expected a rekey audit entry, got: %s", auditLog)
+	}
+}
+
+func TestRekey_MissingPublicKey(t *testing.T) {
+	projectDir, _ := setupRekeyProject(t)
+	if err := os.Remove(filepath.Join(projectDir, ".kanuka", "public_keys", secondUserUUID+".pub")); err != nil {
+		t.Fatalf("Failed to remove public key: %v", err)
+	}
+
+	output := runRekeyExpectingError(t, kerrors.ErrPublicKeyNotFound, "--user", secondUserEmail)
+	if !strings.Contains(output, "No public key found") {
+		t.Errorf("Expected a missing public key error, got: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, ".kanuka", "secrets", secondUserUUID+".kanuka")); !os.IsNotExist(err) {
+		t.Error("Expected no encrypted key to be written")
+	}
+}
+
+func TestRekey_UnknownUser(t *testing.T) {
+	setupRekeyProject(t)
+
+	output := runRekeyExpectingError(t, kerrors.ErrUserNotFound, "--user", "nobody@example.com")
+	if !strings.Contains(output, "not found in project") {
+		t.Errorf("Expected a user not found error, got: %s", output)
+	}
+}
+
+func TestRekey_RequiresUser(t *testing.T) {
+	setupRekeyProject(t)
+
+	output := runRekeyExpectingError(t, kerrors.ErrInvalidArguments)
+	if !strings.Contains(output, "--user") {
+		t.Errorf("Expected a missing --user error, got: %s", output)
+	}
+}
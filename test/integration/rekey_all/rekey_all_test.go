@@ -0,0 +1,206 @@
+package rekeyall
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+const secondUserUUID = "22222222-2222-2222-2222-222222222222"
+
+// setupRekeyProject initializes a project with an encrypted .env and a second
+// user whose public key is in the project. Returns the project directory and
+// the second user's private key path.
+func setupRekeyProject(t *testing.T) (string, string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	secondPrivateKey := filepath.Join(t.TempDir(), "second")
+	secondPublicKey := filepath.Join(tempDir, ".kanuka", "public_keys", secondUserUUID+".pub")
+	if err := shared.GenerateRSAKeyPair(secondPrivateKey, secondPublicKey); err != nil {
+		t.Fatalf("Failed to generate second user's key pair: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("API_KEY=secret\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("encrypt", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v\nOutput: %s", err, output)
+	}
+
+	return tempDir, secondPrivateKey
+}
+
+func runRekeyAll(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs("rekey-all", args, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("rekey-all failed: %v\nOutput: %s", err, output)
+	}
+	return output
+}
+
+// runRekeyAllExpectingError runs rekey-all and fails the test unless it returns want.
+func runRekeyAllExpectingError(t *testing.T, want error, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs("rekey-all", args, nil, nil, false, false).Execute()
+	})
+	if !errors.Is(err, want) {
+		t.Fatalf("Expected rekey-all to fail with %v, got: %v\nOutput: %s", want, err, output)
+	}
+	return output
+}
+
+func readFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return data
+}
+
+func TestRekeyAll_ReencryptsAndEveryUserKeepsAccess(t *testing.T) {
+	projectDir, secondPrivateKey := setupRekeyProject(t)
+	envKanuka := filepath.Join(projectDir, ".env.kanuka")
+	before := readFile(t, envKanuka)
+
+	output := runRekeyAll(t)
+	if !strings.Contains(output, "rekeyed and verified") {
+		t.Errorf("Expected success message, got: %s", output)
+	}
+
+	if bytes.Equal(before, readFile(t, envKanuka)) {
+		t.Error("Expected .env.kanuka to be re-encrypted")
+	}
+
+	// The second user, who never had a key, can now decrypt with their own private key.
+	privateKey, err := secrets.LoadPrivateKey(secondPrivateKey)
+	if err != nil {
+		t.Fatalf("Failed to load second user's private key: %v", err)
+	}
+	wrapped := readFile(t, filepath.Join(projectDir, ".kanuka", "secrets", secondUserUUID+".kanuka"))
+	symKey, err := secrets.DecryptWithPrivateKey(wrapped, privateKey)
+	if err != nil {
+		t.Fatalf("Second user cannot unwrap the new key: %v", err)
+	}
+	plaintext, err := secrets.ReadEncryptedFile(symKey, envKanuka)
+	if err != nil {
+		t.Fatalf("Second user cannot decrypt .env.kanuka: %v", err)
+	}
+	if string(plaintext) != "API_KEY=secret\n" {
+		t.Errorf("Unexpected plaintext: %q", plaintext)
+	}
+
+	// The current user can still decrypt through the CLI.
+	if err := os.Remove(filepath.Join(projectDir, ".env")); err != nil {
+		t.Fatalf("Failed to remove .env: %v", err)
+	}
+	if _, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("decrypt", nil, nil, false, false).Execute()
+	}); err != nil {
+		t.Fatalf("Decrypt after rekey failed: %v", err)
+	}
+	if got := readFile(t, filepath.Join(projectDir, ".env")); string(got) != "API_KEY=secret\n" {
+		t.Errorf("Unexpected decrypted contents: %q", got)
+	}
+
+	auditLog := readFile(t, filepath.Join(projectDir, ".kanuka", "audit.jsonl"))
+	if !strings.Contains(string(auditLog), `"op":"rekey-all"`) {
+		t.Error("Expected a rekey-all audit entry")
+	}
+}
+
+func TestRekeyAll_DryRunShowsPlanWithoutChanges(t *testing.T) {
+	projectDir, _ := setupRekeyProject(t)
+	envKanuka := filepath.Join(projectDir, ".env.kanuka")
+	before := readFile(t, envKanuka)
+
+	output := runRekeyAll(t, "--dry-run")
+
+	for _, want := range []string{"[dry-run]", shared.TestUserEmail, secondUserUUID, ".env.kanuka", "No changes made"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected plan to contain %q, got: %s", want, output)
+		}
+	}
+	if !bytes.Equal(before, readFile(t, envKanuka)) {
+		t.Error("Dry run should not modify .env.kanuka")
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, ".kanuka", "secrets", secondUserUUID+".kanuka")); !os.IsNotExist(err) {
+		t.Error("Dry run should not create user keys")
+	}
+}
+
+func TestRekeyAll_RollsBackOnFailure(t *testing.T) {
+	projectDir, _ := setupRekeyProject(t)
+	envKanuka := filepath.Join(projectDir, ".env.kanuka")
+	secretsDir := filepath.Join(projectDir, ".kanuka", "secrets")
+	before := readFile(t, envKanuka)
+
+	userKey := filepath.Join(secretsDir, shared.GetUserUUID(t)+".kanuka")
+	userKeyBefore := readFile(t, userKey)
+
+	// A malformed public key makes the rekey fail partway through.
+	badPublicKey := filepath.Join(projectDir, ".kanuka", "public_keys", "bad-user.pub")
+	if err := os.WriteFile(badPublicKey, []byte("not a public key"), 0600); err != nil {
+		t.Fatalf("Failed to write bad public key: %v", err)
+	}
+
+	output, _ := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("rekey-all", nil, nil, false, false).Execute()
+	})
+	if !strings.Contains(output, "rolled back") {
+		t.Errorf("Expected rollback message, got: %s", output)
+	}
+
+	if !bytes.Equal(before, readFile(t, envKanuka)) {
+		t.Error("Expected .env.kanuka to be restored")
+	}
+	if !bytes.Equal(userKeyBefore, readFile(t, userKey)) {
+		t.Error("Expected the user's key to be restored")
+	}
+	for _, uuid := range []string{secondUserUUID, "bad-user"} {
+		if _, err := os.Stat(filepath.Join(secretsDir, uuid+".kanuka")); !os.IsNotExist(err) {
+			t.Errorf("Expected no key to be left behind for %s", uuid)
+		}
+	}
+}
+
+func TestRekeyAll_NotInitialized(t *testing.T) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+
+	output := runRekeyAllExpectingError(t, kerrors.ErrProjectNotInitialized)
+	if !strings.Contains(output, "has not been initialized") {
+		t.Errorf("Expected not initialized message, got: %s", output)
+	}
+}
@@ -0,0 +1,145 @@
+package report
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// Report mirrors the cmd.commandReport struct for JSON parsing.
+type Report struct {
+	Command       string   `json:"command"`
+	Success       bool     `json:"success"`
+	DryRun        bool     `json:"dry_run"`
+	StartedAt     string   `json:"started_at"`
+	DurationMs    int64    `json:"duration_ms"`
+	Created       []string `json:"created"`
+	Updated       []string `json:"updated"`
+	Deleted       []string `json:"deleted"`
+	AffectedUsers []string `json:"affected_users"`
+	Error         string   `json:"error"`
+}
+
+// setupReportTest creates an initialized project in a temp directory.
+func setupReportTest(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+	return tempDir
+}
+
+// runWithReport runs a secrets subcommand with --report and parses the report.
+func runWithReport(t *testing.T, reportPath string, subcommand string, args ...string) (string, Report) {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLIWithArgs(subcommand, append(args, "--report", reportPath), nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	cmdErr := err
+
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("Expected report at %s: %v", reportPath, err)
+	}
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Failed to parse report: %v\n%s", err, data)
+	}
+	if (cmdErr == nil) != report.Success {
+		t.Errorf("Expected the command error to match success=%v, got: %v\nOutput: %s", report.Success, cmdErr, output)
+	}
+	return output, report
+}
+
+func TestReport_EncryptCreatedThenUpdated(t *testing.T) {
+	tempDir := setupReportTest(t)
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("KEY=value\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+
+	output, report := runWithReport(t, reportPath, "encrypt")
+	if !strings.Contains(output, "encrypted successfully") {
+		t.Errorf("Expected human-readable output to be kept, got: %s", output)
+	}
+	if report.Command != "encrypt" || !report.Success {
+		t.Errorf("Expected successful encrypt report, got %+v", report)
+	}
+	if len(report.Created) != 1 || report.Created[0] != ".env.kanuka" {
+		t.Errorf("Expected .env.kanuka to be created, got %v", report.Created)
+	}
+	if report.StartedAt == "" {
+		t.Errorf("Expected started_at to be set")
+	}
+
+	_, report = runWithReport(t, reportPath, "encrypt")
+	if len(report.Updated) != 1 || len(report.Created) != 0 {
+		t.Errorf("Expected .env.kanuka to be reported as updated, got created=%v updated=%v", report.Created, report.Updated)
+	}
+}
+
+func TestReport_DecryptFailureRecorded(t *testing.T) {
+	setupReportTest(t)
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+
+	_, report := runWithReport(t, reportPath, "decrypt")
+	if report.Success {
+		t.Errorf("Expected decrypt with no .kanuka files to fail")
+	}
+	if report.Error == "" {
+		t.Errorf("Expected error to be recorded in report")
+	}
+}
+
+func TestReport_DecryptUpdatesExistingFile(t *testing.T) {
+	tempDir := setupReportTest(t)
+	envPath := filepath.Join(tempDir, ".env")
+	if err := os.WriteFile(envPath, []byte("KEY=value\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+
+	runWithReport(t, reportPath, "encrypt")
+	_, report := runWithReport(t, reportPath, "decrypt")
+	if report.Command != "decrypt" || !report.Success {
+		t.Errorf("Expected successful decrypt report, got %+v", report)
+	}
+	if len(report.Updated) != 1 || report.Updated[0] != ".env" {
+		t.Errorf("Expected .env to be reported as updated, got %v", report.Updated)
+	}
+}
+
+func TestReport_UnwritablePathWarns(t *testing.T) {
+	tempDir := setupReportTest(t)
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("KEY=value\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	reportPath := filepath.Join(tempDir, "missing-dir", "report.json")
+
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLIWithArgs("encrypt", []string{"--report", reportPath}, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Expected command to succeed even if report fails: %v", err)
+	}
+	if !strings.Contains(output, "Failed to write report") {
+		t.Errorf("Expected report warning, got: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".env.kanuka")); err != nil {
+		t.Errorf("Expected encryption to still succeed: %v", err)
+	}
+}
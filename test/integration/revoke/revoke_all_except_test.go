@@ -0,0 +1,232 @@
+package revoke
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/audit"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// allExceptUser is a user registered by setupAllExceptProject, with the
+// private key to check their access.
+type allExceptUser struct {
+	uuid       string
+	email      string
+	privateKey *rsa.PrivateKey
+}
+
+// setupAllExceptProject creates a project owned by the test user with an
+// encrypted .env, and registers three more users.
+func setupAllExceptProject(t *testing.T) []allExceptUser {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("API_KEY=secret123\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	if output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLI("encrypt", nil, nil, false, false).Execute()
+	}); err != nil {
+		t.Fatalf("Encrypt command failed: %v\nOutput: %s", err, output)
+	}
+
+	users := []allExceptUser{
+		{uuid: "user1-uuid-1234", email: "user1@example.com"},
+		{uuid: "user2-uuid-1234", email: "user2@example.com"},
+		{uuid: "user3-uuid-1234", email: "user3@example.com"},
+	}
+	for i := range users {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("Failed to generate RSA key: %v", err)
+		}
+		users[i].privateKey = privateKey
+		pubASN1, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+		if err != nil {
+			t.Fatalf("Failed to marshal public key: %v", err)
+		}
+		pubKey := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubASN1}))
+
+		configs.GlobalProjectConfig = nil
+		projectConfig, err := configs.LoadProjectConfig()
+		if err != nil {
+			t.Fatalf("Failed to load project config: %v", err)
+		}
+		projectConfig.Users[users[i].uuid] = users[i].email
+		if err := configs.SaveProjectConfig(projectConfig); err != nil {
+			t.Fatalf("Failed to save project config: %v", err)
+		}
+
+		if output, err := shared.CaptureOutput(func() error {
+			cmd.ResetGlobalState()
+			return shared.CreateTestCLIWithArgs("register", []string{"--pubkey", pubKey, "--user", users[i].email}, nil, nil, false, false).Execute()
+		}); err != nil {
+			t.Fatalf("Failed to register %s: %v\nOutput: %s", users[i].email, err, output)
+		}
+	}
+	return users
+}
+
+func unwrapSymmetricKey(t *testing.T, userUUID string, privateKey *rsa.PrivateKey) []byte {
+	t.Helper()
+	wrapped, err := secrets.GetProjectKanukaKey(userUUID)
+	if err != nil {
+		t.Fatalf("Failed to read the wrapped key for %s: %v", userUUID, err)
+	}
+	symKey, err := secrets.DecryptWithPrivateKey(wrapped, privateKey)
+	if err != nil {
+		t.Fatalf("Failed to unwrap the key for %s: %v", userUUID, err)
+	}
+	return symKey
+}
+
+// TestRevokeAllExcept_RevokesEveryoneElseWithOneRotation tests that the
+// excepted users keep access under a single new key, and everyone else is
+// removed from the key files and the project config.
+func TestRevokeAllExcept_RevokesEveryoneElseWithOneRotation(t *testing.T) {
+	users := setupAllExceptProject(t)
+	kept, revoked := users[0], users[1:]
+	oldKey := unwrapSymmetricKey(t, kept.uuid, kept.privateKey)
+
+	stdout, stderr, err := shared.CaptureStdoutAndStderr(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("revoke",
+			[]string{"--all-except", shared.TestUserEmail + "," + kept.email, "--yes", "--output", "json"},
+			nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Revoke --all-except failed: %v\nStderr: %s", err, stderr)
+	}
+
+	var result workflows.RevokeResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("Expected a JSON result, got %q: %v\nStderr: %s", stdout, err, stderr)
+	}
+	wantRevoked := []string{revoked[0].email, revoked[1].email}
+	if strings.Join(result.RevokedUsers, ",") != strings.Join(wantRevoked, ",") {
+		t.Errorf("Expected revoked users %v, got %v", wantRevoked, result.RevokedUsers)
+	}
+	if result.SecretsReEncrypted != 1 {
+		t.Errorf("Expected .env.kanuka to be re-encrypted once, got %d", result.SecretsReEncrypted)
+	}
+
+	newKey := unwrapSymmetricKey(t, kept.uuid, kept.privateKey)
+	if bytes.Equal(oldKey, newKey) {
+		t.Error("Expected the symmetric key to be rotated")
+	}
+	ciphertext, err := os.ReadFile(filepath.Join(configs.ProjectKanukaSettings.ProjectPath, ".env.kanuka"))
+	if err != nil {
+		t.Fatalf("Failed to read .env.kanuka: %v", err)
+	}
+	if plaintext, err := secrets.DecryptBytes(newKey, ciphertext); err != nil || string(plaintext) != "API_KEY=secret123\n" {
+		t.Errorf("Expected the kept user to decrypt .env.kanuka with the new key, got %q: %v", plaintext, err)
+	}
+
+	configs.GlobalProjectConfig = nil
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	for _, user := range revoked {
+		if _, err := os.Stat(filepath.Join(configs.ProjectKanukaSettings.ProjectSecretsPath, user.uuid+".kanuka")); !os.IsNotExist(err) {
+			t.Errorf("Expected the key file for %s to be removed", user.email)
+		}
+		if _, ok := projectConfig.Users[user.uuid]; ok {
+			t.Errorf("Expected %s to be removed from the project config", user.email)
+		}
+	}
+	if _, ok := projectConfig.Users[kept.uuid]; !ok {
+		t.Errorf("Expected %s to stay in the project config", kept.email)
+	}
+
+	entries, err := audit.ReadEntries()
+	if err != nil {
+		t.Fatalf("Failed to read the audit log: %v", err)
+	}
+	var revokeEntries []string
+	for _, entry := range entries {
+		if entry.Operation == "revoke" {
+			revokeEntries = append(revokeEntries, entry.TargetUser)
+		}
+	}
+	if len(revokeEntries) != len(revoked) {
+		t.Errorf("Expected one audit entry per revoked user, got %v", revokeEntries)
+	}
+	if chainBreak := audit.VerifyEntries(entries); chainBreak != nil {
+		t.Errorf("Expected the revoke entries to be chained, broken at entry %d", chainBreak.Index)
+	}
+}
+
+// TestRevokeAllExcept_DryRunPreviewsWholeSet tests that --dry-run lists every
+// user that would be revoked and changes nothing.
+func TestRevokeAllExcept_DryRunPreviewsWholeSet(t *testing.T) {
+	users := setupAllExceptProject(t)
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("revoke",
+			[]string{"--all-except", shared.TestUserEmail, "--dry-run"}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Revoke --all-except --dry-run failed: %v\nOutput: %s", err, output)
+	}
+
+	if !strings.Contains(output, "Users that would be revoked:") {
+		t.Errorf("Expected the dry run to list the users, got: %s", output)
+	}
+	for _, user := range users {
+		if !strings.Contains(output, user.email) {
+			t.Errorf("Expected the dry run to list %s, got: %s", user.email, output)
+		}
+		if _, err := os.Stat(filepath.Join(configs.ProjectKanukaSettings.ProjectSecretsPath, user.uuid+".kanuka")); err != nil {
+			t.Errorf("Expected the dry run to keep the key file for %s: %v", user.email, err)
+		}
+	}
+}
+
+// TestRevokeAllExcept_RequiresOwnEmail tests that --all-except refuses to
+// revoke the user running it.
+func TestRevokeAllExcept_RequiresOwnEmail(t *testing.T) {
+	users := setupAllExceptProject(t)
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("revoke",
+			[]string{"--all-except", users[0].email, "--yes"}, nil, nil, false, false).Execute()
+	})
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Fatalf("Expected ErrInvalidArguments, got: %v", err)
+	}
+	if !strings.Contains(output, "must include your own email") {
+		t.Errorf("Expected an own-email error, got: %s", output)
+	}
+	for _, user := range users {
+		if _, err := os.Stat(filepath.Join(configs.ProjectKanukaSettings.ProjectSecretsPath, user.uuid+".kanuka")); err != nil {
+			t.Errorf("Expected the key file for %s to be kept: %v", user.email, err)
+		}
+	}
+}
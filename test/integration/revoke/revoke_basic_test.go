@@ -1,12 +1,15 @@
 package revoke
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/PolarWolf314/kanuka/cmd"
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
 func TestRevokeCommand_RequiresUserFlag(t *testing.T) {
@@ -64,12 +67,11 @@ func TestRevokeCommand_RequiresUserFlag(t *testing.T) {
 
 	// Test revoke command without --user flag
 	cmd.ResetGlobalState()
-	secretsCmd := cmd.GetSecretsCmd()
-	secretsCmd.SetArgs([]string{"revoke"})
+	testCmd := shared.CreateTestCLI("revoke", nil, nil, false, false)
 
-	err = secretsCmd.Execute()
-	if err != nil {
-		t.Errorf("Command should not return error, but should show help message: %v", err)
+	err = testCmd.Execute()
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Errorf("Expected ErrInvalidArguments, got: %v", err)
 	}
 }
 
@@ -128,12 +130,11 @@ func TestRevokeCommand_UserNotFound(t *testing.T) {
 
 	// Test revoke command for non-existent user (using valid email format)
 	cmd.ResetGlobalState()
-	secretsCmd := cmd.GetSecretsCmd()
-	secretsCmd.SetArgs([]string{"revoke", "--user", "nonexistent@example.com"})
+	testCmd := shared.CreateTestCLIWithArgs("revoke", []string{"--user", "nonexistent@example.com"}, nil, nil, false, false)
 
-	err = secretsCmd.Execute()
-	if err != nil {
-		t.Errorf("Command should not return error, but should show user not found message: %v", err)
+	err = testCmd.Execute()
+	if !errors.Is(err, kerrors.ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got: %v", err)
 	}
 }
 
@@ -178,17 +179,7 @@ func TestRevokeCommand_SuccessfulRemoval(t *testing.T) {
 		Username:        "testuser",
 	}
 
-	// Create kanuka directories
-	kanukaDir := filepath.Join(tempDir, ".kanuka")
-	publicKeysDir := filepath.Join(kanukaDir, "public_keys")
-	secretsDir := filepath.Join(kanukaDir, "secrets")
-
-	if err := os.MkdirAll(publicKeysDir, 0755); err != nil {
-		t.Fatalf("Failed to create public keys directory: %v", err)
-	}
-	if err := os.MkdirAll(secretsDir, 0755); err != nil {
-		t.Fatalf("Failed to create secrets directory: %v", err)
-	}
+	publicKeysDir, secretsDir := setupKeyFileProject(t, tempDir, tempUserDir)
 
 	// Create test user files (using UUID-like identifier since --file uses filename as user ID)
 	testUserUUID := "testuser2-uuid"
@@ -219,10 +210,9 @@ func TestRevokeCommand_SuccessfulRemoval(t *testing.T) {
 	// Use relative path as revoke command expects paths relative to project root
 	relativeKanukaKeyPath := filepath.Join(".kanuka", "secrets", testUserUUID+".kanuka")
 	cmd.ResetGlobalState()
-	secretsCmd := cmd.GetSecretsCmd()
-	secretsCmd.SetArgs([]string{"revoke", "--file", relativeKanukaKeyPath})
+	testCmd := shared.CreateTestCLIWithArgs("revoke", []string{"--file", relativeKanukaKeyPath}, nil, nil, false, false)
 
-	err = secretsCmd.Execute()
+	err = testCmd.Execute()
 	if err != nil {
 		t.Errorf("Remove command should succeed: %v", err)
 	}
@@ -291,11 +281,10 @@ func TestRevokeCommand_DeviceRequiresUser(t *testing.T) {
 
 	// Test revoke command with --device flag but no --user flag
 	cmd.ResetGlobalState()
-	secretsCmd := cmd.GetSecretsCmd()
-	secretsCmd.SetArgs([]string{"revoke", "--device", "test-device"})
+	testCmd := shared.CreateTestCLIWithArgs("revoke", []string{"--device", "test-device"}, nil, nil, false, false)
 
-	err = secretsCmd.Execute()
-	if err != nil {
-		t.Errorf("Command should not return error, but should show help message: %v", err)
+	err = testCmd.Execute()
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Errorf("Expected ErrInvalidArguments, got: %v", err)
 	}
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/PolarWolf314/kanuka/cmd"
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
 func TestRevokeCommand_ConcurrentAccess(t *testing.T) {
@@ -62,17 +63,7 @@ func testRevokeWithFileBeingAccessed(t *testing.T, originalWd string, originalUs
 		configs.UserKanukaSettings = originalUserSettings
 	}()
 
-	// Create kanuka directories
-	kanukaDir := filepath.Join(tempDir, ".kanuka")
-	publicKeysDir := filepath.Join(kanukaDir, "public_keys")
-	secretsDir := filepath.Join(kanukaDir, "secrets")
-
-	if err := os.MkdirAll(publicKeysDir, 0755); err != nil {
-		t.Fatalf("Failed to create public keys directory: %v", err)
-	}
-	if err := os.MkdirAll(secretsDir, 0755); err != nil {
-		t.Fatalf("Failed to create secrets directory: %v", err)
-	}
+	publicKeysDir, secretsDir := setupKeyFileProject(t, tempDir, tempUserDir)
 
 	// Create test user files
 	testUserUUID := "testuser2-uuid"
@@ -104,9 +95,8 @@ func testRevokeWithFileBeingAccessed(t *testing.T, originalWd string, originalUs
 	relativeKanukaKeyPath := filepath.Join(".kanuka", "secrets", testUserUUID+".kanuka")
 	go func() {
 		cmd.ResetGlobalState()
-		secretsCmd := cmd.GetSecretsCmd()
-		secretsCmd.SetArgs([]string{"revoke", "--file", relativeKanukaKeyPath})
-		err := secretsCmd.Execute()
+		testCmd := shared.CreateTestCLIWithArgs("revoke", []string{"--file", relativeKanukaKeyPath}, nil, nil, false, false)
+		err := testCmd.Execute()
 		if err != nil {
 			t.Errorf("Remove command should not return error even with concurrent access: %v", err)
 		}
@@ -1,6 +1,7 @@
 package revoke
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/PolarWolf314/kanuka/cmd"
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -509,8 +511,8 @@ func TestRevokeDryRun_ValidationStillRuns(t *testing.T) {
 		return testCmd.Execute()
 	})
 
-	if err != nil {
-		t.Errorf("Command should not return error: %v", err)
+	if !errors.Is(err, kerrors.ErrInvalidEmail) {
+		t.Errorf("Expected ErrInvalidEmail, got: %v", err)
 	}
 
 	// Verify original user's files are NOT touched (validation prevented action).
@@ -525,8 +527,8 @@ func TestRevokeDryRun_ValidationStillRuns(t *testing.T) {
 		return testCmd.Execute()
 	})
 
-	if err != nil {
-		t.Errorf("Command should not return error: %v", err)
+	if !errors.Is(err, kerrors.ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound, got: %v", err)
 	}
 
 	// Verify original user's files are still NOT touched.
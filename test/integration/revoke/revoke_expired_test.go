@@ -0,0 +1,120 @@
+package revoke
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setDeviceExpiry sets the expiry of user's device in the project config.
+func setDeviceExpiry(t *testing.T, user allExceptUser, expiresAt time.Time) {
+	t.Helper()
+	configs.GlobalProjectConfig = nil
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	device := projectConfig.Devices[user.uuid]
+	device.Email = user.email
+	if device.Name == "" {
+		device.Name = "laptop"
+	}
+	device.ExpiresAt = expiresAt
+	projectConfig.Devices[user.uuid] = device
+	if err := configs.SaveProjectConfig(projectConfig); err != nil {
+		t.Fatalf("Failed to save project config: %v", err)
+	}
+}
+
+// findCheck returns the doctor check called name.
+func findCheck(t *testing.T, name string) workflows.CheckResult {
+	t.Helper()
+	configs.GlobalProjectConfig = nil
+	result, err := workflows.Doctor(context.Background(), workflows.DoctorOptions{})
+	if err != nil {
+		t.Fatalf("Doctor failed: %v", err)
+	}
+	for _, check := range result.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("Expected a %q check, got %v", name, result.Checks)
+	return workflows.CheckResult{}
+}
+
+// TestRevokeExpired_RevokesOnlyExpiredDevices tests that doctor warns about
+// devices past their expiry, and revoke --expired removes exactly those.
+func TestRevokeExpired_RevokesOnlyExpiredDevices(t *testing.T) {
+	users := setupAllExceptProject(t)
+	expired, current := users[1], users[2]
+	setDeviceExpiry(t, expired, time.Now().Add(-24*time.Hour))
+	setDeviceExpiry(t, current, time.Now().Add(30*24*time.Hour))
+
+	check := findCheck(t, "Device expiry")
+	if check.Status != workflows.CheckWarning || !strings.Contains(check.Message, expired.email) {
+		t.Errorf("Expected doctor to warn about %s, got: %+v", expired.email, check)
+	}
+	if strings.Contains(check.Message, current.email) {
+		t.Errorf("Expected doctor not to list %s, got: %s", current.email, check.Message)
+	}
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("revoke", []string{"--expired", "--yes"}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Revoke --expired failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, expired.email) {
+		t.Errorf("Expected the output to name %s, got: %s", expired.email, output)
+	}
+
+	secretsPath := configs.ProjectKanukaSettings.ProjectSecretsPath
+	if _, err := os.Stat(filepath.Join(secretsPath, expired.uuid+".kanuka")); !os.IsNotExist(err) {
+		t.Errorf("Expected the key file for %s to be removed", expired.email)
+	}
+	for _, user := range []allExceptUser{users[0], current} {
+		if _, err := os.Stat(filepath.Join(secretsPath, user.uuid+".kanuka")); err != nil {
+			t.Errorf("Expected the key file for %s to be kept: %v", user.email, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(secretsPath, shared.TestUserUUID+".kanuka")); err != nil {
+		t.Errorf("Expected your own key file to be kept: %v", err)
+	}
+
+	if check := findCheck(t, "Device expiry"); check.Status != workflows.CheckPass {
+		t.Errorf("Expected the expiry check to pass after revoking, got: %+v", check)
+	}
+}
+
+// TestRevokeExpired_NothingExpired tests that revoke --expired changes
+// nothing when no device has expired.
+func TestRevokeExpired_NothingExpired(t *testing.T) {
+	users := setupAllExceptProject(t)
+	setDeviceExpiry(t, users[0], time.Now().Add(24*time.Hour))
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("revoke", []string{"--expired", "--yes"}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Expected the command to report the error, got: %v", err)
+	}
+	if !strings.Contains(output, "No devices have expired") {
+		t.Errorf("Expected a no-expired-devices message, got: %s", output)
+	}
+	for _, user := range users {
+		if _, err := os.Stat(filepath.Join(configs.ProjectKanukaSettings.ProjectSecretsPath, user.uuid+".kanuka")); err != nil {
+			t.Errorf("Expected the key file for %s to be kept: %v", user.email, err)
+		}
+	}
+}
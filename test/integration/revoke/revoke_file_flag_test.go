@@ -1,12 +1,14 @@
 package revoke
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/PolarWolf314/kanuka/cmd"
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -23,6 +25,19 @@ func resetConfigState() {
 	configs.GlobalProjectConfig = nil
 }
 
+// setupKeyFileProject initializes a project in tempDir without any users and
+// returns its public_keys and secrets directories, for tests that write key
+// files into them directly. The project must have a config: without one, the
+// key files make it a legacy project, which gets migrated and renamed before
+// the revoke runs.
+func setupKeyFileProject(t *testing.T, tempDir, tempUserDir string) (string, string) {
+	t.Helper()
+	shared.InitializeProjectStructureOnly(t, tempDir, tempUserDir)
+
+	kanukaDir := filepath.Join(tempDir, ".kanuka")
+	return filepath.Join(kanukaDir, "public_keys"), filepath.Join(kanukaDir, "secrets")
+}
+
 func TestRevokeCommand_FileFlag(t *testing.T) {
 	originalWd, err := os.Getwd()
 	if err != nil {
@@ -115,16 +130,7 @@ func testRevokeFileWithBothFilesPresent(t *testing.T, originalWd string, origina
 		Username:        "testuser",
 	}
 
-	kanukaDir := filepath.Join(tempDir, ".kanuka")
-	publicKeysDir := filepath.Join(kanukaDir, "public_keys")
-	secretsDir := filepath.Join(kanukaDir, "secrets")
-
-	if err := os.MkdirAll(publicKeysDir, 0755); err != nil {
-		t.Fatalf("Failed to create public keys directory: %v", err)
-	}
-	if err := os.MkdirAll(secretsDir, 0755); err != nil {
-		t.Fatalf("Failed to create secrets directory: %v", err)
-	}
+	publicKeysDir, secretsDir := setupKeyFileProject(t, tempDir, tempUserDir)
 
 	testUser := "testuser2"
 	publicKeyPath := filepath.Join(publicKeysDir, testUser+".pub")
@@ -208,16 +214,7 @@ func testRevokeFileWithRelativePath(t *testing.T, originalWd string, originalUse
 		Username:        "testuser",
 	}
 
-	kanukaDir := filepath.Join(tempDir, ".kanuka")
-	publicKeysDir := filepath.Join(kanukaDir, "public_keys")
-	secretsDir := filepath.Join(kanukaDir, "secrets")
-
-	if err := os.MkdirAll(publicKeysDir, 0755); err != nil {
-		t.Fatalf("Failed to create public keys directory: %v", err)
-	}
-	if err := os.MkdirAll(secretsDir, 0755); err != nil {
-		t.Fatalf("Failed to create secrets directory: %v", err)
-	}
+	publicKeysDir, secretsDir := setupKeyFileProject(t, tempDir, tempUserDir)
 
 	testUser := "testuser2"
 	publicKeyPath := filepath.Join(publicKeysDir, testUser+".pub")
@@ -398,8 +395,8 @@ func testRevokeNonExistentFile(t *testing.T, originalWd string, originalUserSett
 	testCmd := shared.CreateTestCLIWithArgs("revoke", []string{"--file", nonExistentPath}, nil, nil, false, false)
 
 	err = testCmd.Execute()
-	if err != nil {
-		t.Errorf("Remove command should not return error even when file doesn't exist: %v", err)
+	if !errors.Is(err, kerrors.ErrFileNotFound) {
+		t.Errorf("Expected ErrFileNotFound, got: %v", err)
 	}
 }
 
@@ -461,8 +458,8 @@ func testRevokeDirectoryPath(t *testing.T, originalWd string, originalUserSettin
 	testCmd := shared.CreateTestCLIWithArgs("revoke", []string{"--file", directoryPath}, nil, nil, false, false)
 
 	err = testCmd.Execute()
-	if err != nil {
-		t.Errorf("Remove command should not return error even when path is a directory: %v", err)
+	if !errors.Is(err, kerrors.ErrInvalidFileType) {
+		t.Errorf("Expected ErrInvalidFileType, got: %v", err)
 	}
 }
 
@@ -528,8 +525,8 @@ func testRevokeFileOutsideSecretsDir(t *testing.T, originalWd string, originalUs
 	testCmd := shared.CreateTestCLIWithArgs("revoke", []string{"--file", tempFile.Name()}, nil, nil, false, false)
 
 	err = testCmd.Execute()
-	if err != nil {
-		t.Errorf("Remove command should not return error even when file is outside secrets dir: %v", err)
+	if !errors.Is(err, kerrors.ErrInvalidFileType) {
+		t.Errorf("Expected ErrInvalidFileType, got: %v", err)
 	}
 
 	if _, err := os.Stat(tempFile.Name()); os.IsNotExist(err) {
@@ -607,8 +604,8 @@ func testRevokeNonKanukaExtension(t *testing.T, originalWd string, originalUserS
 	testCmd := shared.CreateTestCLIWithArgs("revoke", []string{"--file", testFilePathRelative}, nil, nil, false, false)
 
 	err = testCmd.Execute()
-	if err != nil {
-		t.Errorf("Remove command should not return error even when file has wrong extension: %v", err)
+	if !errors.Is(err, kerrors.ErrInvalidFileType) {
+		t.Errorf("Expected ErrInvalidFileType, got: %v", err)
 	}
 
 	if _, err := os.Stat(testFilePath); os.IsNotExist(err) {
@@ -688,8 +685,8 @@ func testBothUserAndFileFlags(t *testing.T, originalWd string, originalUserSetti
 	testCmd := shared.CreateTestCLIWithArgs("revoke", []string{"--user", testUser, "--file", relativeFilePath}, nil, nil, false, false)
 
 	err = testCmd.Execute()
-	if err != nil {
-		t.Errorf("Remove command should not return error even when both flags are specified: %v", err)
+	if !errors.Is(err, kerrors.ErrInvalidArguments) {
+		t.Errorf("Expected ErrInvalidArguments, got: %v", err)
 	}
 
 	if _, err := os.Stat(publicKeyPath); os.IsNotExist(err) {
@@ -742,16 +739,7 @@ func testRevokeFileWithDotsInUsername(t *testing.T, originalWd string, originalU
 		Username:        "testuser",
 	}
 
-	kanukaDir := filepath.Join(tempDir, ".kanuka")
-	publicKeysDir := filepath.Join(kanukaDir, "public_keys")
-	secretsDir := filepath.Join(kanukaDir, "secrets")
-
-	if err := os.MkdirAll(publicKeysDir, 0755); err != nil {
-		t.Fatalf("Failed to create public keys directory: %v", err)
-	}
-	if err := os.MkdirAll(secretsDir, 0755); err != nil {
-		t.Fatalf("Failed to create secrets directory: %v", err)
-	}
+	publicKeysDir, secretsDir := setupKeyFileProject(t, tempDir, tempUserDir)
 
 	testUser := "user.name"
 	publicKeyPath := filepath.Join(publicKeysDir, testUser+".pub")
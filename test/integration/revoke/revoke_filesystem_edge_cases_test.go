@@ -68,17 +68,7 @@ func testRevokeWithOnlyPublicKeyFile(t *testing.T, originalWd string, originalUs
 		configs.UserKanukaSettings = originalUserSettings
 	}()
 
-	// Create kanuka directories
-	kanukaDir := filepath.Join(tempDir, ".kanuka")
-	publicKeysDir := filepath.Join(kanukaDir, "public_keys")
-	secretsDir := filepath.Join(kanukaDir, "secrets")
-
-	if err := os.MkdirAll(publicKeysDir, 0755); err != nil {
-		t.Fatalf("Failed to create public keys directory: %v", err)
-	}
-	if err := os.MkdirAll(secretsDir, 0755); err != nil {
-		t.Fatalf("Failed to create secrets directory: %v", err)
-	}
+	publicKeysDir, secretsDir := setupKeyFileProject(t, tempDir, tempUserDir)
 
 	// Create test user files - only public key (creating a .kanuka file to use --file)
 	testUserUUID := "testuser2-uuid"
@@ -229,17 +219,7 @@ func testRevokeWithReadOnlyPublicKeyFile(t *testing.T, originalWd string, origin
 		configs.UserKanukaSettings = originalUserSettings
 	}()
 
-	// Create kanuka directories
-	kanukaDir := filepath.Join(tempDir, ".kanuka")
-	publicKeysDir := filepath.Join(kanukaDir, "public_keys")
-	secretsDir := filepath.Join(kanukaDir, "secrets")
-
-	if err := os.MkdirAll(publicKeysDir, 0755); err != nil {
-		t.Fatalf("Failed to create public keys directory: %v", err)
-	}
-	if err := os.MkdirAll(secretsDir, 0755); err != nil {
-		t.Fatalf("Failed to create secrets directory: %v", err)
-	}
+	publicKeysDir, secretsDir := setupKeyFileProject(t, tempDir, tempUserDir)
 
 	// Create test user files
 	testUserUUID := "testuser2-uuid"
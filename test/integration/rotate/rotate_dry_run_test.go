@@ -0,0 +1,73 @@
+package rotate
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+func TestRotate_DryRunMakesNoChanges(t *testing.T) {
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	setupRotateTestProject(t, tempDir, tempUserDir)
+
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("API_KEY=secret\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	if _, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLI("encrypt", nil, nil, false, false).Execute()
+	}); err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+
+	projectUUID := shared.GetProjectUUID(t)
+	userUUID := shared.GetUserUUID(t)
+	originalPrivateKey := getPrivateKeyBytes(t, projectUUID)
+	originalPublicKey := getPublicKeyBytes(t, tempDir, userUUID)
+	originalKanukaKey := getKanukaKeyBytes(t, tempDir, userUUID)
+
+	// No --force: a dry run must not prompt.
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("rotate", []string{"--dry-run"}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("rotate --dry-run failed: %v\nOutput: %s", err, output)
+	}
+
+	for _, want := range []string{
+		"[dry-run]",
+		"Generate a new RSA keypair",
+		userUUID + ".pub",
+		"0 other user(s) keep their access",
+		"1 secret file(s) are not re-encrypted",
+		"No changes made.",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got: %s", want, output)
+		}
+	}
+
+	if !bytes.Equal(getPrivateKeyBytes(t, projectUUID), originalPrivateKey) {
+		t.Error("Expected the private key to be unchanged")
+	}
+	if !bytes.Equal(getPublicKeyBytes(t, tempDir, userUUID), originalPublicKey) {
+		t.Error("Expected the public key to be unchanged")
+	}
+	if !bytes.Equal(getKanukaKeyBytes(t, tempDir, userUUID), originalKanukaKey) {
+		t.Error("Expected the encrypted symmetric key to be unchanged")
+	}
+}
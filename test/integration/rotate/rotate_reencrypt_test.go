@@ -0,0 +1,115 @@
+package rotate
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupReencryptTest creates a project with an encrypted .env and returns
+// the project directory.
+func setupReencryptTest(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	setupRotateTestProject(t, tempDir, tempUserDir)
+
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("API_KEY=secret\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	if _, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLI("encrypt", nil, nil, false, false).Execute()
+	}); err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	return tempDir
+}
+
+func readFileBytes(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return data
+}
+
+func TestRotate_ReencryptOnlyKeepsKeys(t *testing.T) {
+	tempDir := setupReencryptTest(t)
+
+	projectUUID := shared.GetProjectUUID(t)
+	userUUID := shared.GetUserUUID(t)
+	originalPrivateKey := getPrivateKeyBytes(t, projectUUID)
+	originalKanukaKey := getKanukaKeyBytes(t, tempDir, userUUID)
+	kanukaPath := filepath.Join(tempDir, ".env.kanuka")
+	originalCiphertext := readFileBytes(t, kanukaPath)
+
+	// No --force: re-encrypting doesn't prompt.
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("rotate", []string{"--reencrypt-only"}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("rotate --reencrypt-only failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "Re-encrypted 1 secret file(s)") {
+		t.Errorf("Expected a success message, got: %s", output)
+	}
+
+	if !bytes.Equal(getKanukaKeyBytes(t, tempDir, userUUID), originalKanukaKey) {
+		t.Error("Expected the wrapped symmetric key to be unchanged")
+	}
+	if !bytes.Equal(getPrivateKeyBytes(t, projectUUID), originalPrivateKey) {
+		t.Error("Expected the private key to be unchanged")
+	}
+	if bytes.Equal(readFileBytes(t, kanukaPath), originalCiphertext) {
+		t.Error("Expected .env.kanuka to be re-encrypted with fresh nonces")
+	}
+
+	// The new ciphertext still decrypts with the same key.
+	if err := os.Remove(filepath.Join(tempDir, ".env")); err != nil {
+		t.Fatalf("Failed to remove .env: %v", err)
+	}
+	if output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLI("decrypt", nil, nil, false, false).Execute()
+	}); err != nil {
+		t.Fatalf("Failed to decrypt: %v\nOutput: %s", err, output)
+	}
+	if got := string(readFileBytes(t, filepath.Join(tempDir, ".env"))); got != "API_KEY=secret\n" {
+		t.Errorf("Expected the decrypted .env to be unchanged, got %q", got)
+	}
+}
+
+func TestRotate_ReencryptOnlyDryRun(t *testing.T) {
+	tempDir := setupReencryptTest(t)
+	kanukaPath := filepath.Join(tempDir, ".env.kanuka")
+	originalCiphertext := readFileBytes(t, kanukaPath)
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("rotate", []string{"--reencrypt-only", "--dry-run"}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("rotate --reencrypt-only --dry-run failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, ".env.kanuka") || !strings.Contains(output, "No changes made.") {
+		t.Errorf("Expected the dry run to list .env.kanuka, got: %s", output)
+	}
+	if !bytes.Equal(readFileBytes(t, kanukaPath), originalCiphertext) {
+		t.Error("Expected the dry run to leave .env.kanuka unchanged")
+	}
+}
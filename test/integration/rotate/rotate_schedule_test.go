@@ -0,0 +1,137 @@
+package rotate
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupScheduleTest initializes a project and returns its UUID.
+func setupScheduleTest(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	setupRotateTestProject(t, tempDir, tempUserDir)
+	return shared.GetProjectUUID(t)
+}
+
+// runRotateCheck runs rotate --check and returns its output and the exit code it requested.
+func runRotateCheck(t *testing.T) (string, int) {
+	t.Helper()
+	exitCode := 0
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLIWithArgs("rotate", []string{"--check"}, nil, nil, false, false)
+		cmd.SetRotateExitFunc(func(code int) { exitCode = code }) // Set mock after ResetGlobalState is called
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("rotate --check failed: %v\nOutput: %s", err, output)
+	}
+	return output, exitCode
+}
+
+func TestRotate_ScheduleIsKeptAcrossRotation(t *testing.T) {
+	projectUUID := setupScheduleTest(t)
+
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs("rotate", []string{"--schedule", "90"}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("rotate --schedule failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, "every 90 days") {
+		t.Errorf("Expected schedule confirmation, got: %s", output)
+	}
+
+	before := time.Now()
+	output, err = shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs("rotate", []string{"--force"}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("rotate failed: %v\nOutput: %s", err, output)
+	}
+
+	metadata, err := configs.LoadKeyMetadata(projectUUID)
+	if err != nil {
+		t.Fatalf("Failed to load metadata: %v", err)
+	}
+	if metadata.RotationIntervalDays != 90 {
+		t.Errorf("Expected rotation interval to be kept, got %d", metadata.RotationIntervalDays)
+	}
+	if metadata.LastRotatedAt.Before(before.Truncate(time.Second)) {
+		t.Errorf("Expected LastRotatedAt to be updated, got %v", metadata.LastRotatedAt)
+	}
+}
+
+func TestRotate_CheckNotDue(t *testing.T) {
+	projectUUID := setupScheduleTest(t)
+
+	metadata, err := configs.LoadKeyMetadata(projectUUID)
+	if err != nil {
+		t.Fatalf("Failed to load metadata: %v", err)
+	}
+	metadata.RotationIntervalDays = 30
+	metadata.LastRotatedAt = time.Now()
+	if err := configs.SaveKeyMetadata(projectUUID, metadata); err != nil {
+		t.Fatalf("Failed to save metadata: %v", err)
+	}
+
+	output, exitCode := runRotateCheck(t)
+	if exitCode != 0 {
+		t.Errorf("Expected exit code 0, got %d\nOutput: %s", exitCode, output)
+	}
+	if !strings.Contains(output, "not due") {
+		t.Errorf("Expected not due message, got: %s", output)
+	}
+}
+
+func TestRotate_CheckOverdueWithoutPrivateKey(t *testing.T) {
+	projectUUID := setupScheduleTest(t)
+
+	metadata, err := configs.LoadKeyMetadata(projectUUID)
+	if err != nil {
+		t.Fatalf("Failed to load metadata: %v", err)
+	}
+	metadata.RotationIntervalDays = 30
+	metadata.LastRotatedAt = time.Now().AddDate(0, 0, -31)
+	if err := configs.SaveKeyMetadata(projectUUID, metadata); err != nil {
+		t.Fatalf("Failed to save metadata: %v", err)
+	}
+
+	// --check must only read metadata.
+	if err := os.Remove(configs.GetPrivateKeyPath(projectUUID)); err != nil {
+		t.Fatalf("Failed to remove private key: %v", err)
+	}
+
+	output, exitCode := runRotateCheck(t)
+	if exitCode != 1 {
+		t.Errorf("Expected exit code 1, got %d\nOutput: %s", exitCode, output)
+	}
+	if !strings.Contains(output, "overdue") {
+		t.Errorf("Expected overdue warning, got: %s", output)
+	}
+}
+
+func TestRotate_CheckWithoutSchedule(t *testing.T) {
+	setupScheduleTest(t)
+
+	output, exitCode := runRotateCheck(t)
+	if exitCode != 0 {
+		t.Errorf("Expected exit code 0, got %d\nOutput: %s", exitCode, output)
+	}
+	if !strings.Contains(output, "No rotation schedule set") {
+		t.Errorf("Expected no schedule message, got: %s", output)
+	}
+}
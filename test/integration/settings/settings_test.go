@@ -0,0 +1,126 @@
+package settings_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupSettingsTest creates a project with a .env file and writes settings
+// to .kanuka/settings.toml.
+func setupSettingsTest(t *testing.T, settings string) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("KEY=value\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	if err := os.WriteFile(configs.GetFlagDefaultsPath(tempDir), []byte(settings), 0600); err != nil {
+		t.Fatalf("Failed to write settings.toml: %v", err)
+	}
+	return tempDir
+}
+
+func runCommand(t *testing.T, subcommand string, args ...string) (string, string) {
+	t.Helper()
+	stdout, stderr, err := shared.CaptureStdoutAndStderr(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs(subcommand, args, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("%s failed: %v\nStdout: %s\nStderr: %s", subcommand, err, stdout, stderr)
+	}
+	return stdout, stderr
+}
+
+// TestSettings_CommandDefaultAppliedWhenFlagAbsent tests that a value in a
+// command's table is used when the flag isn't given.
+func TestSettings_CommandDefaultAppliedWhenFlagAbsent(t *testing.T) {
+	tempDir := setupSettingsTest(t, "[encrypt]\nbackup = true\n")
+	backupFile := filepath.Join(tempDir, ".env.kanuka.bak")
+
+	runCommand(t, "encrypt")
+	runCommand(t, "encrypt")
+
+	if _, err := os.Stat(backupFile); err != nil {
+		t.Errorf("Expected backup = true to back up the previous .kanuka file: %v", err)
+	}
+}
+
+// TestSettings_CommandDefaultOverriddenByFlag tests that an explicit flag
+// wins over the value in settings.toml.
+func TestSettings_CommandDefaultOverriddenByFlag(t *testing.T) {
+	tempDir := setupSettingsTest(t, "[encrypt]\nbackup = true\n")
+	backupFile := filepath.Join(tempDir, ".env.kanuka.bak")
+
+	runCommand(t, "encrypt", "--backup=false")
+	runCommand(t, "encrypt", "--backup=false")
+
+	if _, err := os.Stat(backupFile); !os.IsNotExist(err) {
+		t.Errorf("Expected --backup=false to override the setting")
+	}
+}
+
+// TestSettings_GlobalDefault tests that top-level keys set the persistent
+// flags of every command, and are overridden by explicit flags.
+func TestSettings_GlobalDefault(t *testing.T) {
+	setupSettingsTest(t, "output = \"json\"\n")
+
+	stdout, _ := runCommand(t, "encrypt")
+	if !strings.HasPrefix(strings.TrimSpace(stdout), "{") {
+		t.Errorf("Expected output = \"json\" to print JSON, got: %s", stdout)
+	}
+
+	stdout, _ = runCommand(t, "encrypt", "--output", "text")
+	if !strings.Contains(stdout, "encrypted successfully") {
+		t.Errorf("Expected --output text to override the setting, got: %s", stdout)
+	}
+}
+
+// TestSettings_UnknownKeysWarn tests that keys which don't name a command or
+// flag are reported instead of silently ignored.
+func TestSettings_UnknownKeysWarn(t *testing.T) {
+	setupSettingsTest(t, "verbos = true\n\n[encrypt]\nbackups = true\n\n[encrpyt]\nbackup = true\n")
+
+	_, stderr := runCommand(t, "encrypt")
+	for _, want := range []string{
+		`Unknown setting "verbos"`,
+		`Unknown setting "backups" for [encrypt]`,
+		"Unknown command [encrpyt]",
+	} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("Expected warning %q, got: %s", want, stderr)
+		}
+	}
+}
+
+// TestSettings_InvalidValue tests that a value its flag can't parse fails
+// the command.
+func TestSettings_InvalidValue(t *testing.T) {
+	tempDir := setupSettingsTest(t, "[encrypt]\njobs = \"many\"\n")
+
+	_, _, err := shared.CaptureStdoutAndStderr(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLI("encrypt", nil, nil, false, false).Execute()
+	})
+	if err == nil || !strings.Contains(err.Error(), "settings.toml") {
+		t.Errorf("Expected an invalid setting error, got: %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(tempDir, ".env.kanuka")); !os.IsNotExist(statErr) {
+		t.Error("Expected nothing to be encrypted")
+	}
+}
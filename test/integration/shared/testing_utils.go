@@ -217,6 +217,8 @@ handling project packages using a nix shell environment, and securely storing en
 	cmd.ResetGlobalState()
 
 	// Use the actual SecretsCmd but with reset state
+	cmd.AddColorFlag(rootCmd)
+	cmd.AddUserDirFlags(rootCmd)
 	rootCmd.AddCommand(cmd.GetSecretsCmd())
 
 	// Set output streams
@@ -545,6 +547,44 @@ func CreateConfigTestCLIWithArgs(subcommand string, extraArgs []string, stdout,
 	return rootCmd
 }
 
+// CreateAuditTestCLIWithArgs creates a CLI instance for testing audit commands with extra args.
+func CreateAuditTestCLIWithArgs(subcommand string, extraArgs []string, stdout, stderr io.Writer, verboseFlag, debugFlag bool) *cobra.Command {
+	// Reset audit command state
+	cmd.ResetAuditState()
+
+	// Create a fresh root command for this test
+	rootCmd := &cobra.Command{
+		Use:   "kanuka",
+		Short: "Kanuka - A CLI for package management, cloud provisioning, and secrets management.",
+	}
+
+	// Add the audit command
+	rootCmd.AddCommand(cmd.GetAuditCmd())
+
+	// Set output streams
+	if stdout != nil {
+		rootCmd.SetOut(stdout)
+	}
+	if stderr != nil {
+		rootCmd.SetErr(stderr)
+	}
+
+	// Build args: audit <subcommand> [extraArgs...]
+	args := []string{"audit", subcommand}
+	args = append(args, extraArgs...)
+	rootCmd.SetArgs(args)
+
+	// Set the flags on the audit command
+	if err := cmd.GetAuditCmd().PersistentFlags().Set("verbose", fmt.Sprintf("%t", verboseFlag)); err != nil {
+		log.Fatalf("Failed to set verbose flag for testing: %s", err)
+	}
+	if err := cmd.GetAuditCmd().PersistentFlags().Set("debug", fmt.Sprintf("%t", debugFlag)); err != nil {
+		log.Fatalf("Failed to set debug flag for testing: %s", err)
+	}
+
+	return rootCmd
+}
+
 // GetKeyDirPath returns the path to the key directory for a given project UUID.
 // This follows the new directory structure: {keysDir}/{projectUUID}/.
 func GetKeyDirPath(keysDir, projectUUID string) string {
@@ -617,6 +657,49 @@ func GenerateOpenSSHKeyPairWithPassphrase(privatePath, publicPath string, passph
 	return nil
 }
 
+// CaptureStdoutAndStderr captures stdout and stderr separately during function
+// execution. This is useful for testing that a command keeps stdout clean for piping.
+func CaptureStdoutAndStderr(fn func() error) (string, string, error) {
+	originalStdout := os.Stdout
+	originalStderr := os.Stderr
+
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	stderrReader, stderrWriter, err := os.Pipe()
+	if err != nil {
+		stdoutReader.Close()
+		stdoutWriter.Close()
+		return "", "", fmt.Errorf("failed to create stderr pipe: %w", err)
+	}
+
+	os.Stdout = stdoutWriter
+	os.Stderr = stderrWriter
+
+	readPipe := func(r *os.File, out chan<- string) {
+		var buf bytes.Buffer
+		if _, copyErr := io.Copy(&buf, r); copyErr != nil {
+			log.Printf("Failed to copy output: %s", copyErr)
+		}
+		out <- buf.String()
+	}
+	stdoutChan := make(chan string, 1)
+	stderrChan := make(chan string, 1)
+	go readPipe(stdoutReader, stdoutChan)
+	go readPipe(stderrReader, stderrChan)
+
+	fnErr := fn()
+
+	stdoutWriter.Close()
+	stderrWriter.Close()
+
+	os.Stdout = originalStdout
+	os.Stderr = originalStderr
+
+	return <-stdoutChan, <-stderrChan, fnErr
+}
+
 // CaptureOutputWithStdin captures both stdout and stderr during function execution,
 // while also providing data on stdin. This is useful for testing commands that read from stdin.
 func CaptureOutputWithStdin(stdinData []byte, fn func() error) (string, error) {
@@ -0,0 +1,114 @@
+package status
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupAccessTestProject initializes a real project so the user has access.
+// Returns the project and user directories.
+func setupAccessTestProject(t *testing.T) (string, string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+	return tempDir, tempUserDir
+}
+
+func runStatusAccessJSON(t *testing.T) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLIWithArgs("status", []string{"--json"}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Status command failed: %v", err)
+	}
+
+	var result struct {
+		Access string `json:"access"`
+	}
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v\nOutput: %s", err, output)
+	}
+	return result.Access
+}
+
+func TestStatus_AccessGranted(t *testing.T) {
+	setupAccessTestProject(t)
+
+	if access := runStatusAccessJSON(t); access != "granted" {
+		t.Errorf("Expected access granted, got %q", access)
+	}
+
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("status", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Status command failed: %v", err)
+	}
+	if !strings.Contains(output, "your private key can decrypt this project") {
+		t.Errorf("Expected access line, got: %s", output)
+	}
+}
+
+func TestStatus_AccessNotRegistered(t *testing.T) {
+	projectDir, _ := setupAccessTestProject(t)
+
+	userKey := filepath.Join(projectDir, ".kanuka", "secrets", shared.GetUserUUID(t)+".kanuka")
+	if err := os.Remove(userKey); err != nil {
+		t.Fatalf("Failed to remove user key: %v", err)
+	}
+
+	if access := runStatusAccessJSON(t); access != "not_registered" {
+		t.Errorf("Expected not_registered, got %q", access)
+	}
+
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("status", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Lack of access should not fail status: %v", err)
+	}
+	if !strings.Contains(output, "kanuka secrets register") {
+		t.Errorf("Expected register hint, got: %s", output)
+	}
+}
+
+func TestStatus_AccessPrivateKeyMissing(t *testing.T) {
+	_, userDir := setupAccessTestProject(t)
+
+	keyPath := shared.GetPrivateKeyPath(filepath.Join(userDir, "keys"), shared.GetProjectUUID(t))
+	if err := os.Remove(keyPath); err != nil {
+		t.Fatalf("Failed to remove private key: %v", err)
+	}
+
+	if access := runStatusAccessJSON(t); access != "private_key_missing" {
+		t.Errorf("Expected private_key_missing, got %q", access)
+	}
+}
+
+func TestStatus_AccessKeyMismatch(t *testing.T) {
+	_, userDir := setupAccessTestProject(t)
+
+	keyPath := shared.GetPrivateKeyPath(filepath.Join(userDir, "keys"), shared.GetProjectUUID(t))
+	if err := shared.GenerateRSAKeyPair(keyPath, filepath.Join(t.TempDir(), "other.pub")); err != nil {
+		t.Fatalf("Failed to replace private key: %v", err)
+	}
+
+	if access := runStatusAccessJSON(t); access != "key_mismatch" {
+		t.Errorf("Expected key_mismatch, got %q", access)
+	}
+}
@@ -2,6 +2,7 @@ package status
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
 	"github.com/PolarWolf314/kanuka/test/integration/shared"
 )
 
@@ -594,8 +596,8 @@ func TestStatus_NotInitialized(t *testing.T) {
 		testCmd := shared.CreateTestCLIWithArgs("status", []string{}, nil, nil, false, false)
 		return testCmd.Execute()
 	})
-	if err != nil {
-		t.Fatalf("Status command failed: %v", err)
+	if !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Fatalf("Expected ErrProjectNotInitialized, got: %v", err)
 	}
 
 	// Verify error message.
@@ -635,8 +637,8 @@ func TestStatus_NotInitializedJsonOutput(t *testing.T) {
 		testCmd := shared.CreateTestCLIWithArgs("status", []string{"--json"}, nil, nil, false, false)
 		return testCmd.Execute()
 	})
-	if err != nil {
-		t.Fatalf("Status command failed: %v", err)
+	if !errors.Is(err, kerrors.ErrProjectNotInitialized) {
+		t.Fatalf("Expected ErrProjectNotInitialized, got: %v", err)
 	}
 
 	// Verify JSON error output.
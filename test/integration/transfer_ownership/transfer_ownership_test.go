@@ -0,0 +1,193 @@
+package transfer_ownership
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/secrets"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+const (
+	secondUserUUID  = "22222222-2222-2222-2222-222222222222"
+	secondUserEmail = "second@example.com"
+)
+
+// setupTransferProject initializes a project with an encrypted .env and a
+// second user who has a public key but no encrypted key yet. Returns the
+// project directory and the second user's private key path.
+func setupTransferProject(t *testing.T) (string, string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	secondPrivateKey := filepath.Join(t.TempDir(), "second")
+	secondPublicKey := filepath.Join(tempDir, ".kanuka", "public_keys", secondUserUUID+".pub")
+	if err := shared.GenerateRSAKeyPair(secondPrivateKey, secondPublicKey); err != nil {
+		t.Fatalf("Failed to generate second user's key pair: %v", err)
+	}
+
+	projectConfig := loadProjectConfig(t)
+	projectConfig.Users[secondUserUUID] = secondUserEmail
+	projectConfig.Devices[secondUserUUID] = configs.DeviceConfig{Email: secondUserEmail, Name: "laptop"}
+	if err := configs.SaveProjectConfig(projectConfig); err != nil {
+		t.Fatalf("Failed to save project config: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(tempDir, ".env"), []byte("API_KEY=secret\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLI("encrypt", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v\nOutput: %s", err, output)
+	}
+
+	return tempDir, secondPrivateKey
+}
+
+func runTransferOwnership(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("transfer-ownership", args, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("transfer-ownership failed: %v\nOutput: %s", err, output)
+	}
+	return output
+}
+
+// runTransferOwnershipExpectingError runs transfer-ownership and fails the test unless it returns want.
+func runTransferOwnershipExpectingError(t *testing.T, want error, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("transfer-ownership", args, nil, nil, false, false).Execute()
+	})
+	if !errors.Is(err, want) {
+		t.Fatalf("Expected transfer-ownership to fail with %v, got: %v\nOutput: %s", want, err, output)
+	}
+	return output
+}
+
+func loadProjectConfig(t *testing.T) *configs.ProjectConfig {
+	t.Helper()
+	projectConfig, err := configs.LoadProjectConfig()
+	if err != nil {
+		t.Fatalf("Failed to load project config: %v", err)
+	}
+	return projectConfig
+}
+
+// TestTransferOwnership_AddsOwnerAndGrantsAccess tests that the first
+// transfer records both users as owners and lets the new owner decrypt.
+func TestTransferOwnership_AddsOwnerAndGrantsAccess(t *testing.T) {
+	projectDir, secondPrivateKey := setupTransferProject(t)
+
+	output := runTransferOwnership(t, "--user", secondUserEmail)
+	if !strings.Contains(output, "is now an owner") {
+		t.Errorf("Expected success message, got: %s", output)
+	}
+
+	owners := loadProjectConfig(t).Project.Owners
+	want := []string{secondUserEmail, shared.TestUserEmail}
+	slices.Sort(want)
+	if !slices.Equal(owners, want) {
+		t.Errorf("Expected owners %v, got %v", want, owners)
+	}
+
+	privateKey, err := secrets.LoadPrivateKey(secondPrivateKey)
+	if err != nil {
+		t.Fatalf("Failed to load second user's private key: %v", err)
+	}
+	wrapped, err := os.ReadFile(filepath.Join(projectDir, ".kanuka", "secrets", secondUserUUID+".kanuka"))
+	if err != nil {
+		t.Fatalf("Expected the new owner's key to be written: %v", err)
+	}
+	symKey, err := secrets.DecryptWithPrivateKey(wrapped, privateKey)
+	if err != nil {
+		t.Fatalf("New owner cannot unwrap the key: %v", err)
+	}
+	plaintext, err := secrets.ReadEncryptedFile(symKey, filepath.Join(projectDir, ".env.kanuka"))
+	if err != nil {
+		t.Fatalf("New owner cannot decrypt .env.kanuka: %v", err)
+	}
+	if string(plaintext) != "API_KEY=secret\n" {
+		t.Errorf("Unexpected plaintext: %q", plaintext)
+	}
+}
+
+// TestTransferOwnership_StepDown tests that --step-down leaves the new owner
+// as the only owner.
+func TestTransferOwnership_StepDown(t *testing.T) {
+	setupTransferProject(t)
+
+	runTransferOwnership(t, "--user", secondUserEmail, "--step-down")
+
+	owners := loadProjectConfig(t).Project.Owners
+	if !slices.Equal(owners, []string{secondUserEmail}) {
+		t.Errorf("Expected only %s to be an owner, got %v", secondUserEmail, owners)
+	}
+}
+
+// TestTransferOwnership_RejectsNonOwner tests that once the project has
+// owners, a user who isn't one can't transfer ownership.
+func TestTransferOwnership_RejectsNonOwner(t *testing.T) {
+	projectDir, _ := setupTransferProject(t)
+	runTransferOwnership(t, "--user", secondUserEmail, "--step-down")
+
+	output := runTransferOwnershipExpectingError(t, kerrors.ErrNotProjectOwner, "--user", shared.TestUserEmail)
+	if !strings.Contains(output, "Only a project owner can transfer ownership") {
+		t.Errorf("Expected a non-owner rejection, got: %s", output)
+	}
+
+	owners := loadProjectConfig(t).Project.Owners
+	if !slices.Equal(owners, []string{secondUserEmail}) {
+		t.Errorf("Expected owners to be unchanged, got %v", owners)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, ".kanuka", "secrets", secondUserUUID+".kanuka")); err != nil {
+		t.Errorf("Expected the new owner's key to remain: %v", err)
+	}
+}
+
+// TestTransferOwnership_UnknownUser tests that ownership can only be given to
+// a registered user.
+func TestTransferOwnership_UnknownUser(t *testing.T) {
+	setupTransferProject(t)
+
+	output := runTransferOwnershipExpectingError(t, kerrors.ErrUserNotFound, "--user", "nobody@example.com")
+	if !strings.Contains(output, "not found in project") {
+		t.Errorf("Expected a not found message, got: %s", output)
+	}
+	if owners := loadProjectConfig(t).Project.Owners; len(owners) != 0 {
+		t.Errorf("Expected no owners to be recorded, got %v", owners)
+	}
+}
+
+// TestTransferOwnership_RequiresUser tests that --user is required.
+func TestTransferOwnership_RequiresUser(t *testing.T) {
+	setupTransferProject(t)
+
+	output := runTransferOwnershipExpectingError(t, kerrors.ErrInvalidArguments)
+	if !strings.Contains(output, "--user") {
+		t.Errorf("Expected a missing flag message, got: %s", output)
+	}
+}
@@ -0,0 +1,139 @@
+package userdirs
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupMovedUserDirs creates an initialized project with an encrypted .env,
+// removes the .env, and moves the user's config and keys out of the
+// directories the user settings point at. Returns the project directory and
+// the new config and key directories.
+func setupMovedUserDirs(t *testing.T) (projectDir, configDir, keysDir string) {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	envPath := filepath.Join(tempDir, ".env")
+	if err := os.WriteFile(envPath, []byte("API_KEY=secret\n"), 0600); err != nil {
+		t.Fatalf("Failed to create .env: %v", err)
+	}
+	if output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLI("encrypt", nil, nil, false, false).Execute()
+	}); err != nil {
+		t.Fatalf("Encrypt command failed: %v\nOutput: %s", err, output)
+	}
+	if err := os.Remove(envPath); err != nil {
+		t.Fatalf("Failed to remove .env: %v", err)
+	}
+
+	profileDir := t.TempDir()
+	configDir = filepath.Join(profileDir, "config")
+	keysDir = filepath.Join(profileDir, "keys")
+	if err := os.Rename(configs.UserKanukaSettings.UserConfigsPath, configDir); err != nil {
+		t.Fatalf("Failed to move the user config: %v", err)
+	}
+	if err := os.Rename(configs.UserKanukaSettings.UserKeysPath, keysDir); err != nil {
+		t.Fatalf("Failed to move the user keys: %v", err)
+	}
+	configs.GlobalUserConfig = nil
+	return tempDir, configDir, keysDir
+}
+
+// TestUserDirs_KeysDirFlag tests that decrypt finds the private key in the
+// directory given by --keys-dir, resolved from the working directory.
+func TestUserDirs_KeysDirFlag(t *testing.T) {
+	projectDir, configDir, keysDir := setupMovedUserDirs(t)
+	if err := os.Rename(configDir, configs.UserKanukaSettings.UserConfigsPath); err != nil {
+		t.Fatalf("Failed to restore the user config: %v", err)
+	}
+	envPath := filepath.Join(projectDir, ".env")
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLI("decrypt", nil, nil, false, false).Execute()
+	})
+	if !errors.Is(err, kerrors.ErrPrivateKeyNotFound) {
+		t.Fatalf("Expected ErrPrivateKeyNotFound, got: %v\nOutput: %s", err, output)
+	}
+	if _, err := os.Stat(envPath); !os.IsNotExist(err) {
+		t.Fatalf("Expected decrypt to fail without the moved keys, got: %s", output)
+	}
+
+	relKeysDir, err := filepath.Rel(projectDir, keysDir)
+	if err != nil {
+		t.Fatalf("Failed to make the keys directory relative: %v", err)
+	}
+	output, err = shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("decrypt", []string{"--keys-dir", relKeysDir}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Decrypt --keys-dir failed: %v\nOutput: %s", err, output)
+	}
+	content, err := os.ReadFile(envPath)
+	if err != nil {
+		t.Fatalf("Expected decrypt --keys-dir to restore .env: %v\nOutput: %s", err, output)
+	}
+	if string(content) != "API_KEY=secret\n" {
+		t.Errorf("Expected the decrypted .env to match, got %q", content)
+	}
+	if configs.UserKanukaSettings.UserKeysPath != keysDir {
+		t.Errorf("Expected the keys path to resolve to %s, got %s", keysDir, configs.UserKanukaSettings.UserKeysPath)
+	}
+}
+
+// TestUserDirs_ConfigAndKeysDirFlags tests that --config-dir and --keys-dir
+// together let a command run as an identity kept entirely outside the
+// default directories.
+func TestUserDirs_ConfigAndKeysDirFlags(t *testing.T) {
+	projectDir, configDir, keysDir := setupMovedUserDirs(t)
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("decrypt",
+			[]string{"--config-dir", configDir, "--keys-dir", keysDir}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v\nOutput: %s", err, output)
+	}
+	if _, err := os.Stat(filepath.Join(projectDir, ".env")); err != nil {
+		t.Fatalf("Expected decrypt to restore .env: %v\nOutput: %s", err, output)
+	}
+	if configs.UserKanukaSettings.UserConfigsPath != configDir {
+		t.Errorf("Expected the config path to be %s, got %s", configDir, configs.UserKanukaSettings.UserConfigsPath)
+	}
+}
+
+// TestUserDirs_ConfigDirFlagForWhoami tests that whoami reads the user
+// config from --config-dir.
+func TestUserDirs_ConfigDirFlagForWhoami(t *testing.T) {
+	_, configDir, _ := setupMovedUserDirs(t)
+
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		return shared.CreateTestCLIWithArgs("whoami", []string{"--config-dir", configDir}, nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Whoami failed: %v\nOutput: %s", err, output)
+	}
+	if !strings.Contains(output, shared.TestUserEmail) {
+		t.Errorf("Expected whoami to show %s from the moved config, got: %s", shared.TestUserEmail, output)
+	}
+}
@@ -0,0 +1,157 @@
+package verify
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	kerrors "github.com/PolarWolf314/kanuka/internal/errors"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupVerifyTest initializes a project with two encrypted files and removes
+// their plaintext, returning the project path.
+func setupVerifyTest(t *testing.T) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	shared.InitializeProject(t, tempDir, tempUserDir)
+
+	for _, f := range []string{".env", "services/api/.env"} {
+		path := filepath.Join(tempDir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", f, err)
+		}
+		if err := os.WriteFile(path, []byte("API_KEY=secret\n"), 0600); err != nil {
+			t.Fatalf("Failed to create %s: %v", f, err)
+		}
+	}
+
+	output, err := shared.CaptureOutput(func() error {
+		return shared.CreateTestCLI("encrypt", nil, nil, false, false).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v\nOutput: %s", err, output)
+	}
+
+	for _, f := range []string{".env", "services/api/.env"} {
+		if err := os.Remove(filepath.Join(tempDir, f)); err != nil {
+			t.Fatalf("Failed to remove %s: %v", f, err)
+		}
+	}
+	return tempDir
+}
+
+// runVerify runs verify and returns its output and the exit code it requested
+// or that its error maps to.
+func runVerify(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	exitCode := 0
+	output, err := shared.CaptureOutput(func() error {
+		testCmd := shared.CreateTestCLIWithArgs("verify", args, nil, nil, false, false)
+		cmd.SetVerifyExitFunc(func(code int) { exitCode = code }) // Set mock after ResetGlobalState is called
+		return testCmd.Execute()
+	})
+	if err != nil {
+		return output, kerrors.ExitCode(err)
+	}
+	return output, exitCode
+}
+
+func TestVerify_AllValid(t *testing.T) {
+	tempDir := setupVerifyTest(t)
+
+	output, exitCode := runVerify(t)
+	if exitCode != 0 {
+		t.Errorf("Expected no exit code, got %d\nOutput: %s", exitCode, output)
+	}
+	for _, want := range []string{".env.kanuka", filepath.Join("services", "api", ".env.kanuka"), "(you)", "All 3 checks passed"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got: %s", want, output)
+		}
+	}
+
+	for _, f := range []string{".env", "services/api/.env"} {
+		if _, err := os.Stat(filepath.Join(tempDir, f)); !os.IsNotExist(err) {
+			t.Errorf("Expected verify not to write plaintext %s", f)
+		}
+	}
+}
+
+func TestVerify_CorruptFile(t *testing.T) {
+	tempDir := setupVerifyTest(t)
+
+	corrupted := filepath.Join(tempDir, "services", "api", ".env.kanuka")
+	data, err := os.ReadFile(corrupted)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", corrupted, err)
+	}
+	data[len(data)-1] ^= 0xff
+	if err := os.WriteFile(corrupted, data, 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", corrupted, err)
+	}
+
+	output, exitCode := runVerify(t)
+	if exitCode != kerrors.ExitCrypto {
+		t.Errorf("Expected exit code %d, got %d\nOutput: %s", kerrors.ExitCrypto, exitCode, output)
+	}
+	if !strings.Contains(output, "1 of 3 checks failed") {
+		t.Errorf("Expected failure count, got: %s", output)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "services", "api", ".env")); !os.IsNotExist(err) {
+		t.Error("Expected verify not to write plaintext for a corrupt file")
+	}
+}
+
+func TestVerify_OrphanedWrappedKey(t *testing.T) {
+	tempDir := setupVerifyTest(t)
+
+	orphan := filepath.Join(tempDir, ".kanuka", "secrets", shared.TestUser2UUID+".kanuka")
+	if err := os.WriteFile(orphan, []byte("not a wrapped key"), 0600); err != nil {
+		t.Fatalf("Failed to write orphaned key: %v", err)
+	}
+
+	output, exitCode := runVerify(t, "--output", "json")
+	if exitCode != kerrors.ExitCrypto {
+		t.Errorf("Expected exit code %d, got %d\nOutput: %s", kerrors.ExitCrypto, exitCode, output)
+	}
+
+	var result workflows.VerifyResult
+	if err := json.Unmarshal([]byte(output[strings.Index(output, "{"):]), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v\nOutput: %s", err, output)
+	}
+	if result.Failed != 1 || len(result.Keys) != 2 {
+		t.Fatalf("Expected 1 failure among 2 keys, got %+v", result)
+	}
+	for _, key := range result.Keys {
+		if key.UUID == shared.TestUser2UUID && key.Status != workflows.VerifyFailed {
+			t.Errorf("Expected orphaned key to fail, got %+v", key)
+		}
+	}
+}
+
+func TestVerify_NoAccessExitsNonZero(t *testing.T) {
+	tempDir := setupVerifyTest(t)
+
+	userUUID := shared.GetUserUUID(t)
+	if err := os.Remove(filepath.Join(tempDir, ".kanuka", "secrets", userUUID+".kanuka")); err != nil {
+		t.Fatalf("Failed to remove user key: %v", err)
+	}
+
+	output, exitCode := runVerify(t)
+	if exitCode != kerrors.ExitNoAccess {
+		t.Errorf("Expected exit code %d, got %d\nOutput: %s", kerrors.ExitNoAccess, exitCode, output)
+	}
+}
@@ -0,0 +1,259 @@
+package version_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+
+	"github.com/spf13/cobra"
+)
+
+// TestVersionIntegration contains integration tests for the `kanuka version` command.
+func TestVersionIntegration(t *testing.T) {
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get original working directory: %v", err)
+	}
+
+	originalUserSettings := configs.UserKanukaSettings
+
+	t.Run("VersionPrintsCurrentVersion", func(t *testing.T) {
+		testVersionPrintsCurrentVersion(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("VersionCheckReportsUpdate", func(t *testing.T) {
+		testVersionCheckReportsUpdate(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("VersionCheckUpToDate", func(t *testing.T) {
+		testVersionCheckUpToDate(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("VersionCheckUsesCache", func(t *testing.T) {
+		testVersionCheckUsesCache(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("VersionFailIfOutdated", func(t *testing.T) {
+		testVersionFailIfOutdated(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("VersionCheckSkippedByEnv", func(t *testing.T) {
+		testVersionCheckSkippedByEnv(t, originalWd, originalUserSettings)
+	})
+
+	t.Run("VersionCheckNetworkFailure", func(t *testing.T) {
+		testVersionCheckNetworkFailure(t, originalWd, originalUserSettings)
+	})
+}
+
+// newReleaseServer starts a fake releases API that reports the given tag and counts requests.
+func newReleaseServer(t *testing.T, tag string, hits *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"tag_name": %q, "html_url": "https://github.com/PolarWolf314/kanuka/releases/tag/%s"}`, tag, tag)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// createVersionTestCLI creates a CLI instance for testing the version command.
+func createVersionTestCLI(currentVersion, releasesURL string, args ...string) *cobra.Command {
+	cmd.ResetVersionState()
+	cmd.SetVersion(currentVersion)
+	cmd.SetVersionReleasesURL(releasesURL)
+
+	rootCmd := &cobra.Command{Use: "kanuka"}
+	rootCmd.AddCommand(cmd.VersionCmd)
+	rootCmd.SetArgs(append([]string{"version"}, args...))
+	return rootCmd
+}
+
+// setupVersionTest isolates the user config directory so cached results do not leak between tests.
+func setupVersionTest(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, originalUserSettings)
+	t.Cleanup(func() {
+		cmd.ResetVersionState()
+		cmd.SetVersion("dev")
+	})
+}
+
+func testVersionPrintsCurrentVersion(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	setupVersionTest(t, originalWd, originalUserSettings)
+
+	var hits int32
+	server := newReleaseServer(t, "v9.9.9", &hits)
+
+	output, err := shared.CaptureOutput(func() error {
+		return createVersionTestCLI("v1.2.3", server.URL).Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+
+	if !strings.Contains(output, "kanuka v1.2.3") {
+		t.Errorf("Expected version in output, got: %s", output)
+	}
+	if atomic.LoadInt32(&hits) != 0 {
+		t.Errorf("Expected no network request without --check, got %d", hits)
+	}
+}
+
+func testVersionCheckReportsUpdate(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	setupVersionTest(t, originalWd, originalUserSettings)
+
+	var hits int32
+	server := newReleaseServer(t, "v1.3.0", &hits)
+
+	output, err := shared.CaptureOutput(func() error {
+		return createVersionTestCLI("v1.2.3", server.URL, "--check").Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+
+	if !strings.Contains(output, "newer version") {
+		t.Errorf("Expected update notice, got: %s", output)
+	}
+	if !strings.Contains(output, "v1.3.0") {
+		t.Errorf("Expected latest version in output, got: %s", output)
+	}
+	if !strings.Contains(output, "releases/tag/v1.3.0") {
+		t.Errorf("Expected release link in output, got: %s", output)
+	}
+}
+
+func testVersionCheckUpToDate(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	setupVersionTest(t, originalWd, originalUserSettings)
+
+	var hits int32
+	server := newReleaseServer(t, "v1.2.3", &hits)
+
+	output, err := shared.CaptureOutput(func() error {
+		return createVersionTestCLI("v1.2.3", server.URL, "--check").Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+
+	if !strings.Contains(output, "latest version") {
+		t.Errorf("Expected up-to-date message, got: %s", output)
+	}
+}
+
+func testVersionCheckUsesCache(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	setupVersionTest(t, originalWd, originalUserSettings)
+
+	var hits int32
+	server := newReleaseServer(t, "v1.3.0", &hits)
+
+	for i := 0; i < 2; i++ {
+		_, err := shared.CaptureOutput(func() error {
+			return createVersionTestCLI("v1.2.3", server.URL, "--check").Execute()
+		})
+		if err != nil {
+			t.Fatalf("Command failed: %v", err)
+		}
+	}
+
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Errorf("Expected 1 network request with caching, got %d", got)
+	}
+
+	cachePath := filepath.Join(configs.UserKanukaSettings.UserConfigsPath, "update-check.toml")
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Errorf("Expected cache file at %s: %v", cachePath, err)
+	}
+
+	_, err := shared.CaptureOutput(func() error {
+		return createVersionTestCLI("v1.2.3", server.URL, "--check", "--no-cache").Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Errorf("Expected --no-cache to bypass the cache, got %d requests", got)
+	}
+}
+
+func testVersionFailIfOutdated(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	setupVersionTest(t, originalWd, originalUserSettings)
+
+	var hits int32
+	server := newReleaseServer(t, "v2.0.0", &hits)
+
+	exitCode := -1
+	_, err := shared.CaptureOutput(func() error {
+		rootCmd := createVersionTestCLI("v1.2.3", server.URL, "--fail-if-outdated")
+		cmd.SetVersionExitFunc(func(code int) { exitCode = code })
+		return rootCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+
+	if exitCode != 1 {
+		t.Errorf("Expected exit code 1 when outdated, got %d", exitCode)
+	}
+}
+
+func testVersionCheckSkippedByEnv(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	setupVersionTest(t, originalWd, originalUserSettings)
+	t.Setenv("KANUKA_NO_UPDATE_CHECK", "1")
+
+	var hits int32
+	server := newReleaseServer(t, "v2.0.0", &hits)
+
+	exitCode := -1
+	output, err := shared.CaptureOutput(func() error {
+		rootCmd := createVersionTestCLI("v1.2.3", server.URL, "--fail-if-outdated")
+		cmd.SetVersionExitFunc(func(code int) { exitCode = code })
+		return rootCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+
+	if !strings.Contains(output, "skipped") {
+		t.Errorf("Expected skipped message, got: %s", output)
+	}
+	if atomic.LoadInt32(&hits) != 0 {
+		t.Errorf("Expected no network request when skipped, got %d", hits)
+	}
+	if exitCode != -1 {
+		t.Errorf("Expected no exit when skipped, got %d", exitCode)
+	}
+}
+
+func testVersionCheckNetworkFailure(t *testing.T, originalWd string, originalUserSettings *configs.UserSettings) {
+	setupVersionTest(t, originalWd, originalUserSettings)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	output, err := shared.CaptureOutput(func() error {
+		return createVersionTestCLI("v1.2.3", server.URL, "--check").Execute()
+	})
+	if err != nil {
+		t.Fatalf("Expected network failure not to fail the command, got: %v", err)
+	}
+
+	if !strings.Contains(output, "Could not check for updates") {
+		t.Errorf("Expected warning about failed check, got: %s", output)
+	}
+}
@@ -0,0 +1,111 @@
+package whoami
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PolarWolf314/kanuka/cmd"
+	"github.com/PolarWolf314/kanuka/internal/configs"
+	"github.com/PolarWolf314/kanuka/internal/workflows"
+	"github.com/PolarWolf314/kanuka/test/integration/shared"
+)
+
+// setupWhoami sets up a test user and, if withProject is true, initializes a
+// project in the working directory.
+func setupWhoami(t *testing.T, withProject bool) string {
+	t.Helper()
+	tempDir := t.TempDir()
+	tempUserDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+
+	shared.SetupTestEnvironment(t, tempDir, tempUserDir, originalWd, configs.UserKanukaSettings)
+	if withProject {
+		shared.InitializeProject(t, tempDir, tempUserDir)
+	}
+	return tempDir
+}
+
+func runWhoami(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := shared.CaptureOutput(func() error {
+		cmd.ResetGlobalState()
+		testCmd := shared.CreateTestCLIWithArgs("whoami", args, nil, nil, false, false)
+		return testCmd.Execute()
+	})
+	if err != nil {
+		t.Fatalf("Whoami command failed: %v\nOutput: %s", err, output)
+	}
+	return output
+}
+
+func TestWhoami_InProject(t *testing.T) {
+	setupWhoami(t, true)
+
+	output := runWhoami(t)
+
+	for _, want := range []string{"testuser@example.com", shared.GetUserUUID(t), "can decrypt"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got: %s", want, output)
+		}
+	}
+	if strings.Contains(output, "not registered") {
+		t.Errorf("Expected a device name for a registered user, got: %s", output)
+	}
+}
+
+func TestWhoami_NoAccess(t *testing.T) {
+	tempDir := setupWhoami(t, true)
+	keyPath := filepath.Join(tempDir, ".kanuka", "secrets", shared.GetUserUUID(t)+".kanuka")
+	if err := os.Remove(keyPath); err != nil {
+		t.Fatalf("Failed to remove encrypted key: %v", err)
+	}
+
+	output := runWhoami(t)
+
+	if !strings.Contains(output, "no access") {
+		t.Errorf("Expected no access to be reported, got: %s", output)
+	}
+}
+
+func TestWhoami_OutsideProject(t *testing.T) {
+	setupWhoami(t, false)
+
+	output := runWhoami(t)
+
+	if !strings.Contains(output, "testuser@example.com") {
+		t.Errorf("Expected the global identity outside a project, got: %s", output)
+	}
+	if !strings.Contains(output, "Not in a Kānuka project") {
+		t.Errorf("Expected a note about not being in a project, got: %s", output)
+	}
+}
+
+func TestWhoami_JSON(t *testing.T) {
+	tempDir := setupWhoami(t, true)
+
+	output := runWhoami(t, "--json")
+
+	var result workflows.WhoamiResult
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v\nOutput: %s", err, output)
+	}
+	if result.UUID != shared.GetUserUUID(t) || result.Email != "testuser@example.com" {
+		t.Errorf("Unexpected identity in JSON: %+v", result)
+	}
+	if result.Project == nil {
+		t.Fatalf("Expected project in JSON output, got: %s", output)
+	}
+	if !result.Project.HasAccess || result.Project.DeviceName == "" {
+		t.Errorf("Expected access and a device name, got: %+v", result.Project)
+	}
+	if resolved, _ := filepath.EvalSymlinks(tempDir); result.Project.Path != tempDir && result.Project.Path != resolved {
+		t.Errorf("Expected project path %s, got %s", tempDir, result.Project.Path)
+	}
+}